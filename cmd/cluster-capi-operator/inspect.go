@@ -0,0 +1,91 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/inspect"
+)
+
+const (
+	// inspectCommand is the name of the subcommand dumping the paired MAPI and CAPI resources state.
+	inspectCommand = "inspect"
+
+	inspectTimeout = 2 * time.Minute
+)
+
+// runInspect runs the inspect subcommand with the given arguments and returns the process exit code.
+func runInspect(scheme *runtime.Scheme, args []string) int {
+	fs := flag.NewFlagSet(inspectCommand, flag.ContinueOnError)
+
+	kubeconfig := fs.String(
+		"kubeconfig",
+		"",
+		"Path to a kubeconfig. Defaults to the KUBECONFIG environment variable or the in-cluster configuration.",
+	)
+	capiNamespace := fs.String(
+		"capi-namespace",
+		controllers.DefaultManagedNamespace,
+		"The namespace of the CAPI resources.",
+	)
+	mapiNamespace := fs.String(
+		"mapi-namespace",
+		controllers.DefaultMAPIManagedNamespace,
+		"The namespace of the MAPI resources.",
+	)
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := ctrl.GetConfig()
+	if *kubeconfig != "" {
+		cfg, err = clientcmd.BuildConfigFromFlags("", *kubeconfig)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to load kubeconfig: %v\n", err)
+		return 1
+	}
+
+	cl, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to create client: %v\n", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), inspectTimeout)
+	defer cancel()
+
+	if err := inspect.Run(ctx, cl, inspect.Options{
+		MAPINamespace: *mapiNamespace,
+		CAPINamespace: *capiNamespace,
+	}, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "unable to inspect resources: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
@@ -16,17 +16,15 @@ package main
 import (
 	"context"
 	"flag"
-	"fmt"
 	"os"
 	"time"
 
 	"github.com/spf13/pflag"
 	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
-	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/component-base/config"
 	"k8s.io/component-base/config/options"
@@ -51,14 +49,24 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	mapiv1 "github.com/openshift/api/machine/v1"
 	mapiv1beta1 "github.com/openshift/api/machine/v1beta1"
+	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
+	configv1alpha1 "github.com/openshift/cluster-capi-operator/pkg/apis/config/v1alpha1"
+	"github.com/openshift/cluster-capi-operator/pkg/commoncmdoptions"
 	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/admissionpolicy"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/bootstrapsecret"
 	"github.com/openshift/cluster-capi-operator/pkg/controllers/capiinstaller"
 	"github.com/openshift/cluster-capi-operator/pkg/controllers/clusteroperator"
 	"github.com/openshift/cluster-capi-operator/pkg/controllers/corecluster"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/crdgate"
 	"github.com/openshift/cluster-capi-operator/pkg/controllers/infracluster"
 	"github.com/openshift/cluster-capi-operator/pkg/controllers/kubeconfig"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/managednamespace"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/operatorconfig"
 	"github.com/openshift/cluster-capi-operator/pkg/controllers/secretsync"
+	"github.com/openshift/cluster-capi-operator/pkg/metrics"
 	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
+	"github.com/openshift/cluster-capi-operator/pkg/tracing"
 	"github.com/openshift/cluster-capi-operator/pkg/util"
 	"github.com/openshift/cluster-capi-operator/pkg/webhook"
 )
@@ -67,6 +75,40 @@ const (
 	defaultImagesLocation = "./dev-images.json"
 )
 
+// The names of the controllers of the operator, as selected by the command line flags.
+const (
+	coreClusterControllerName            = "CoreCluster"
+	userDataSecretControllerName         = "UserDataSecret"
+	bootstrapSecretControllerName        = "BootstrapSecret"
+	kubeconfigControllerName             = "Kubeconfig"
+	capiInstallerControllerName          = "CAPIInstaller"
+	infraClusterControllerName           = "InfraCluster"
+	clusterOperatorControllerName        = "ClusterOperator"
+	operatorConfigControllerName         = "OperatorConfig"
+	machinePoolPolicyControllerName      = "MachinePoolPolicy"
+	managedResourcesPolicyControllerName = "ManagedResourcesPolicy"
+	crdProtectionPolicyControllerName    = "CRDProtectionPolicy"
+	managedNamespaceControllerName       = "ManagedNamespace"
+)
+
+// knownControllers are the controllers the --enable-controllers and --disable-controllers flags can select.
+//
+//nolint:gochecknoglobals
+var knownControllers = []string{
+	coreClusterControllerName,
+	userDataSecretControllerName,
+	bootstrapSecretControllerName,
+	kubeconfigControllerName,
+	capiInstallerControllerName,
+	infraClusterControllerName,
+	clusterOperatorControllerName,
+	operatorConfigControllerName,
+	machinePoolPolicyControllerName,
+	managedResourcesPolicyControllerName,
+	crdProtectionPolicyControllerName,
+	managedNamespaceControllerName,
+}
+
 func initScheme(scheme *runtime.Scheme) {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(configv1.AddToScheme(scheme))
@@ -82,6 +124,8 @@ func initScheme(scheme *runtime.Scheme) {
 	utilruntime.Must(vspherev1.AddToScheme(scheme))
 	utilruntime.Must(mapiv1.AddToScheme(scheme))
 	utilruntime.Must(mapiv1beta1.AddToScheme(scheme))
+	utilruntime.Must(operatorv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(configv1alpha1.AddToScheme(scheme))
 }
 
 //nolint:funlen
@@ -89,13 +133,17 @@ func main() {
 	scheme := runtime.NewScheme()
 	initScheme(scheme)
 
+	// The inspect subcommand is a diagnostics tool for support engineers, it does not start the operator.
+	if len(os.Args) > 1 && os.Args[1] == inspectCommand {
+		os.Exit(runInspect(scheme, os.Args[2:]))
+	}
+
 	leaderElectionConfig := config.LeaderElectionConfiguration{
-		LeaderElect:       true,
-		LeaseDuration:     util.LeaseDuration,
-		RenewDeadline:     util.RenewDeadline,
-		RetryPeriod:       util.RetryPeriod,
-		ResourceName:      "cluster-capi-operator-leader",
-		ResourceNamespace: "openshift-cluster-api",
+		LeaderElect:   true,
+		LeaseDuration: util.LeaseDuration,
+		RenewDeadline: util.RenewDeadline,
+		RetryPeriod:   util.RetryPeriod,
+		ResourceName:  "cluster-capi-operator-leader",
 	}
 	capiManagerOptions := capiflags.ManagerOptions{}
 
@@ -106,8 +154,13 @@ func main() {
 	)
 	managedNamespace := flag.String(
 		"namespace",
-		controllers.DefaultManagedNamespace,
-		"The namespace where CAPI components will run.",
+		util.DiscoverOperatorNamespace(controllers.DefaultManagedNamespace),
+		"The namespace where CAPI components will run, defaults to the namespace of the operator.",
+	)
+	mapiNamespace := flag.String(
+		"mapi-namespace",
+		controllers.DefaultMAPIManagedNamespace,
+		"The namespace of the MAPI resources, such as the user data secrets and the MachineSets the InfraCluster is derived from.",
 	)
 	imagesFile := flag.String(
 		"images-json",
@@ -125,12 +178,33 @@ func main() {
 		"Webhook cert dir, only used when webhook-port is specified.",
 	)
 
+	bootstrapHostNetwork := flag.Bool(
+		"bootstrap-host-network",
+		false,
+		"Run the CAPI provider deployments on the host network, tolerating the nodes that are not ready yet, until the cluster installation completes. "+
+			"Used by the installs needing the providers before the pod network is ready, such as bare metal ones.",
+	)
+
+	userDataRollout := flag.Bool(
+		"user-data-rollout",
+		false,
+		"Roll out the CAPI MachineDeployments and MachineSets bootstrapped from a user data secret when its user data changes, "+
+			"by bumping the user data checksum annotation of their machine template.",
+	)
+
 	logToStderr := flag.Bool(
 		"logtostderr",
 		true,
 		"log to standard error instead of files",
 	)
 
+	tracingOpts := tracing.Options{}
+	tracingOpts.AddFlags(flag.CommandLine)
+
+	diagnosticsFlags := metrics.DiagnosticsOptions{}
+	controllerOpts := commoncmdoptions.ControllerOptions{}
+	clientOpts := commoncmdoptions.ClientOptions{UserAgent: "cluster-capi-operator"}
+
 	textLoggerConfig := textlogger.NewConfig()
 	textLoggerConfig.AddFlags(flag.CommandLine)
 	ctrl.SetLogger(textlogger.NewLogger(textLoggerConfig))
@@ -140,15 +214,38 @@ func main() {
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 	options.BindLeaderElectionFlags(&leaderElectionConfig, pflag.CommandLine)
 	capiflags.AddManagerOptions(pflag.CommandLine, &capiManagerOptions)
+	diagnosticsFlags.AddFlags(pflag.CommandLine)
+	controllerOpts.AddFlags(pflag.CommandLine)
+	clientOpts.AddFlags(pflag.CommandLine)
 	pflag.Parse()
 
-	if err := setFeatureGatesEnvVars(); err != nil {
-		klog.Error(err, "unable to set feature gates environment variables")
+	if logToStderr != nil {
+		klog.LogToStderr(*logToStderr)
+	}
+
+	// The leader election lease lives in the managed namespace, unless set by the command line flags.
+	if leaderElectionConfig.ResourceNamespace == "" {
+		leaderElectionConfig.ResourceNamespace = *managedNamespace
+	}
+
+	if err := controllerOpts.Validate(knownControllers); err != nil {
+		klog.Error(err, "invalid controller selection")
 		os.Exit(1)
 	}
 
-	if logToStderr != nil {
-		klog.LogToStderr(*logToStderr)
+	if disabled := controllerOpts.Disabled(knownControllers); len(disabled) > 0 {
+		klog.Infof("Controllers disabled by the command line flags: %v", disabled)
+	}
+
+	if err := clientOpts.Validate(); err != nil {
+		klog.Error(err, "invalid API client options")
+		os.Exit(1)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracingOpts, "cluster-capi-operator")
+	if err != nil {
+		klog.Error(err, "unable to set up tracing")
+		os.Exit(1)
 	}
 
 	_, diagnosticsOpts, err := capiflags.GetManagerOptions(capiManagerOptions)
@@ -157,18 +254,13 @@ func main() {
 		os.Exit(1)
 	}
 
-	syncPeriod := 10 * time.Minute
-
-	cacheOpts := cache.Options{
-		DefaultNamespaces: map[string]cache.Config{
-			*managedNamespace:                {},
-			secretsync.SecretSourceNamespace: {},
-			"kube-system":                    {}, // For fetching cloud credentials.
-		},
-		SyncPeriod: &syncPeriod,
+	if err := diagnosticsFlags.Apply(diagnosticsOpts); err != nil {
+		klog.Error(err, "unable to configure the diagnostics endpoint")
+		os.Exit(1)
 	}
 
-	cfg := ctrl.GetConfigOrDie()
+	// The installer has its own client, the config of the manager is shared by the other controllers.
+	cfg := clientOpts.RestConfig(ctrl.GetConfigOrDie(), "")
 
 	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
 		Scheme:                  scheme,
@@ -180,7 +272,8 @@ func main() {
 		LeaderElectionID:        leaderElectionConfig.ResourceName,
 		RetryPeriod:             &leaderElectionConfig.RetryPeriod.Duration,
 		RenewDeadline:           &leaderElectionConfig.RenewDeadline.Duration,
-		Cache:                   cacheOpts,
+		Cache:                   getDefaultCacheOptions(*managedNamespace, *mapiNamespace),
+		Client:                  getDefaultClientOptions(),
 		WebhookServer: crwebhook.NewServer(crwebhook.Options{
 			Port:    *webhookPort,
 			CertDir: *webhookCertDir,
@@ -191,23 +284,21 @@ func main() {
 		os.Exit(1)
 	}
 
-	applyClient, err := kubernetes.NewForConfig(cfg)
+	containerImages, err := util.ReadImagesFile(*imagesFile)
 	if err != nil {
-		klog.Error(err, "unable to set up apply client")
+		klog.Error(err, "unable to get images from file", "name", *imagesFile)
 		os.Exit(1)
 	}
 
-	apiextensionsClient, err := apiextensionsclient.NewForConfig(cfg)
+	// The operator configuration overrides the command line flags, it is read once as the manager is restarted
+	// when it changes.
+	operatorConfig, err := operatorconfig.Get(context.Background(), mgr.GetAPIReader(), *managedNamespace)
 	if err != nil {
-		klog.Error(err, "unable to set up apply client")
+		klog.Error(err, "unable to get operator config")
 		os.Exit(1)
 	}
 
-	containerImages, err := util.ReadImagesFile(*imagesFile)
-	if err != nil {
-		klog.Error(err, "unable to get images from file", "name", *imagesFile)
-		os.Exit(1)
-	}
+	containerImages = operatorconfig.ImagesWithOverrides(containerImages, operatorConfig)
 
 	infra, err := util.GetInfra(context.Background(), mgr.GetAPIReader())
 	if err != nil {
@@ -221,7 +312,62 @@ func main() {
 		os.Exit(1)
 	}
 
-	setupPlatformReconcilers(mgr, infra, platform, containerImages, applyClient, apiextensionsClient, *managedNamespace)
+	setupPlatformReconcilers(mgr, controllerOpts, clientOpts, infra, platform, containerImages, operatorConfig, *managedNamespace, *mapiNamespace, *bootstrapHostNetwork, *userDataRollout)
+
+	// The manager is stopped to be restarted by its container when the operator configuration changes.
+	ctx, restart := context.WithCancel(ctrl.SetupSignalHandler())
+
+	if controllerOpts.Enabled(operatorConfigControllerName) {
+		if err := (&operatorconfig.OperatorConfigReconciler{
+			Namespace:        *managedNamespace,
+			Initial:          operatorConfig,
+			Verbosity:        textLoggerConfig.Verbosity(),
+			DefaultVerbosity: textLoggerConfig.Verbosity().String(),
+			Restart:          restart,
+		}).SetupWithManager(mgr); err != nil {
+			klog.Error(err, "unable to create controller", "controller", operatorConfigControllerName)
+			os.Exit(1)
+		}
+	}
+
+	if controllerOpts.Enabled(machinePoolPolicyControllerName) {
+		if err := (&admissionpolicy.MachinePoolPolicyReconciler{
+			CAPINamespace: *managedNamespace,
+			Block:         operatorConfig.FeatureEnabled(configv1alpha1.FeatureBlockMachinePools),
+		}).SetupWithManager(mgr); err != nil {
+			klog.Error(err, "unable to create controller", "controller", machinePoolPolicyControllerName)
+			os.Exit(1)
+		}
+	}
+
+	if controllerOpts.Enabled(managedResourcesPolicyControllerName) {
+		if err := (&admissionpolicy.ManagedResourcesPolicyReconciler{
+			CAPINamespace: *managedNamespace,
+			Protect:       operatorConfig.FeatureEnabled(configv1alpha1.FeatureProtectManagedResources),
+		}).SetupWithManager(mgr); err != nil {
+			klog.Error(err, "unable to create controller", "controller", managedResourcesPolicyControllerName)
+			os.Exit(1)
+		}
+	}
+
+	if controllerOpts.Enabled(crdProtectionPolicyControllerName) {
+		if err := (&admissionpolicy.CRDProtectionPolicyReconciler{
+			CAPINamespace: *managedNamespace,
+		}).SetupWithManager(mgr); err != nil {
+			klog.Error(err, "unable to create controller", "controller", crdProtectionPolicyControllerName)
+			os.Exit(1)
+		}
+	}
+
+	if controllerOpts.Enabled(managedNamespaceControllerName) {
+		if err := (&managednamespace.ManagedNamespaceReconciler{
+			Namespace:       *managedNamespace,
+			NetworkPolicies: operatorConfig.FeatureEnabled(configv1alpha1.FeatureNamespaceNetworkPolicies),
+		}).SetupWithManager(mgr); err != nil {
+			klog.Error(err, "unable to create controller", "controller", managedNamespaceControllerName)
+			os.Exit(1)
+		}
+	}
 
 	// +kubebuilder:scaffold:builder
 
@@ -237,12 +383,47 @@ func main() {
 
 	klog.Info("Starting manager")
 
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	err = mgr.Start(ctx)
+
+	// Flush the pending spans before exiting.
+	if shutdownErr := shutdownTracing(context.Background()); shutdownErr != nil {
+		klog.Error(shutdownErr, "unable to shut down tracing")
+	}
+
+	if err != nil {
 		klog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
 
+// getDefaultCacheOptions returns the cache options for the manager.
+// Secrets are only ever watched through metadata only informers, so full Secret objects are never cached.
+// The managedFields are stripped from all cached objects as the operator never reads them.
+func getDefaultCacheOptions(managedNamespace, mapiNamespace string) cache.Options {
+	syncPeriod := 10 * time.Minute
+
+	return cache.Options{
+		DefaultNamespaces: map[string]cache.Config{
+			managedNamespace: {},
+			mapiNamespace:    {},
+		},
+		DefaultTransform: cache.TransformStripManagedFields(),
+		SyncPeriod:       &syncPeriod,
+	}
+}
+
+// getDefaultClientOptions returns the client options for the manager.
+// Secrets are read directly from the API server, as the operator only needs a handful of them
+// (user data, kubeconfig token and cloud credentials, some of which live in kube-system)
+// and caching every Secret in the watched namespaces is memory heavy on large clusters.
+func getDefaultClientOptions() client.Options {
+	return client.Options{
+		Cache: &client.CacheOptions{
+			DisableFor: []client.Object{&corev1.Secret{}},
+		},
+	}
+}
+
 func getClusterOperatorStatusClient(mgr manager.Manager, controller string, managedNamespace string) operatorstatus.ClusterOperatorStatusClient {
 	return operatorstatus.ClusterOperatorStatusClient{
 		Client:           mgr.GetClient(),
@@ -252,18 +433,18 @@ func getClusterOperatorStatusClient(mgr manager.Manager, controller string, mana
 	}
 }
 
-func setupPlatformReconcilers(mgr manager.Manager, infra *configv1.Infrastructure, platform configv1.PlatformType, containerImages map[string]string, applyClient *kubernetes.Clientset, apiextensionsClient *apiextensionsclient.Clientset, managedNamespace string) {
+func setupPlatformReconcilers(mgr manager.Manager, controllerOpts commoncmdoptions.ControllerOptions, clientOpts commoncmdoptions.ClientOptions, infra *configv1.Infrastructure, platform configv1.PlatformType, containerImages map[string]string, operatorConfig configv1alpha1.ClusterAPIOperatorConfigSpec, managedNamespace, mapiNamespace string, bootstrapHostNetwork, userDataRollout bool) {
 	// Only setup reconcile controllers and webhooks when the platform is supported.
 	// This avoids unnecessary CAPI providers discovery, installs and reconciles when the platform is not supported.
 	isUnsupportedPlatform := false
 
 	switch platform {
 	case configv1.AWSPlatformType:
-		setupReconcilers(mgr, infra, platform, &awsv1.AWSCluster{}, containerImages, applyClient, apiextensionsClient, managedNamespace)
-		setupWebhooks(mgr)
+		setupReconcilers(mgr, controllerOpts, clientOpts, infra, platform, &awsv1.AWSCluster{}, containerImages, operatorConfig, managedNamespace, mapiNamespace, bootstrapHostNetwork, userDataRollout)
+		setupWebhooks(mgr, managedNamespace, mapiNamespace)
 	case configv1.GCPPlatformType:
-		setupReconcilers(mgr, infra, platform, &gcpv1.GCPCluster{}, containerImages, applyClient, apiextensionsClient, managedNamespace)
-		setupWebhooks(mgr)
+		setupReconcilers(mgr, controllerOpts, clientOpts, infra, platform, &gcpv1.GCPCluster{}, containerImages, operatorConfig, managedNamespace, mapiNamespace, bootstrapHostNetwork, userDataRollout)
+		setupWebhooks(mgr, managedNamespace, mapiNamespace)
 	case configv1.AzurePlatformType:
 		azureCloudEnvironment := getAzureCloudEnvironment(infra.Status.PlatformStatus)
 		if azureCloudEnvironment == configv1.AzureStackCloud {
@@ -272,18 +453,18 @@ func setupPlatformReconcilers(mgr manager.Manager, infra *configv1.Infrastructur
 			isUnsupportedPlatform = true
 		} else {
 			// The ClusterOperator Controller must run in all cases.
-			setupReconcilers(mgr, infra, platform, &azurev1.AzureCluster{}, containerImages, applyClient, apiextensionsClient, managedNamespace)
-			setupWebhooks(mgr)
+			setupReconcilers(mgr, controllerOpts, clientOpts, infra, platform, &azurev1.AzureCluster{}, containerImages, operatorConfig, managedNamespace, mapiNamespace, bootstrapHostNetwork, userDataRollout)
+			setupWebhooks(mgr, managedNamespace, mapiNamespace)
 		}
 	case configv1.PowerVSPlatformType:
-		setupReconcilers(mgr, infra, platform, &ibmpowervsv1.IBMPowerVSCluster{}, containerImages, applyClient, apiextensionsClient, managedNamespace)
-		setupWebhooks(mgr)
+		setupReconcilers(mgr, controllerOpts, clientOpts, infra, platform, &ibmpowervsv1.IBMPowerVSCluster{}, containerImages, operatorConfig, managedNamespace, mapiNamespace, bootstrapHostNetwork, userDataRollout)
+		setupWebhooks(mgr, managedNamespace, mapiNamespace)
 	case configv1.VSpherePlatformType:
-		setupReconcilers(mgr, infra, platform, &vspherev1.VSphereCluster{}, containerImages, applyClient, apiextensionsClient, managedNamespace)
-		setupWebhooks(mgr)
+		setupReconcilers(mgr, controllerOpts, clientOpts, infra, platform, &vspherev1.VSphereCluster{}, containerImages, operatorConfig, managedNamespace, mapiNamespace, bootstrapHostNetwork, userDataRollout)
+		setupWebhooks(mgr, managedNamespace, mapiNamespace)
 	case configv1.OpenStackPlatformType:
-		setupReconcilers(mgr, infra, platform, &openstackv1.OpenStackCluster{}, containerImages, applyClient, apiextensionsClient, managedNamespace)
-		setupWebhooks(mgr)
+		setupReconcilers(mgr, controllerOpts, clientOpts, infra, platform, &openstackv1.OpenStackCluster{}, containerImages, operatorConfig, managedNamespace, mapiNamespace, bootstrapHostNetwork, userDataRollout)
+		setupWebhooks(mgr, managedNamespace, mapiNamespace)
 	default:
 		klog.Infof("Detected platform %q is not supported, skipping capi controllers setup", platform)
 
@@ -291,84 +472,149 @@ func setupPlatformReconcilers(mgr manager.Manager, infra *configv1.Infrastructur
 	}
 
 	// The ClusterOperator Controller must run under all circumstances as it manages the ClusterOperator object for this operator.
-	setupClusterOperatorController(mgr, managedNamespace, isUnsupportedPlatform)
+	setupClusterOperatorController(mgr, controllerOpts, managedNamespace, isUnsupportedPlatform)
 }
 
-func setupReconcilers(mgr manager.Manager, infra *configv1.Infrastructure, platform configv1.PlatformType, infraClusterObject client.Object, containerImages map[string]string, applyClient *kubernetes.Clientset, apiextensionsClient *apiextensionsclient.Clientset, managedNamespace string) {
-	if err := (&corecluster.CoreClusterController{
-		ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, "cluster-capi-operator-cluster-resource-controller", managedNamespace),
-		Cluster:                     &clusterv1.Cluster{},
-		Platform:                    platform,
-		Infra:                       infra,
-	}).SetupWithManager(mgr); err != nil {
-		klog.Error(err, "unable to create controller", "controller", "CoreCluster")
-		os.Exit(1)
+//nolint:funlen
+func setupReconcilers(mgr manager.Manager, controllerOpts commoncmdoptions.ControllerOptions, clientOpts commoncmdoptions.ClientOptions, infra *configv1.Infrastructure, platform configv1.PlatformType, infraClusterObject client.Object, containerImages map[string]string, operatorConfig configv1alpha1.ClusterAPIOperatorConfigSpec, managedNamespace, mapiNamespace string, bootstrapHostNetwork, userDataRollout bool) {
+	// Controllers watching CAPI resources are gated on their CRDs, as these can be removed and recreated by the CAPI installer.
+	if controllerOpts.Enabled(coreClusterControllerName) {
+		coreClusterController := &corecluster.CoreClusterController{
+			ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, "cluster-capi-operator-cluster-resource-controller", managedNamespace),
+			Cluster:                     &clusterv1.Cluster{},
+			Platform:                    platform,
+			Infra:                       infra,
+		}
+		if err := (&crdgate.CRDGate{
+			ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, "cluster-capi-operator-cluster-resource-controller", managedNamespace),
+			Name:                        "CoreClusterController",
+			Objects:                     []client.Object{&clusterv1.Cluster{}},
+			Setup:                       coreClusterController.SetupWithManager,
+		}).SetupWithManager(mgr); err != nil {
+			klog.Error(err, "unable to create controller", "controller", coreClusterControllerName)
+			os.Exit(1)
+		}
 	}
 
-	if err := (&secretsync.UserDataSecretController{
-		ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, "cluster-capi-operator-user-data-secret-controller", managedNamespace),
-		Scheme:                      mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
-		klog.Error(err, "unable to create user-data-secret controller", "controller", "UserDataSecret")
-		os.Exit(1)
+	if controllerOpts.Enabled(userDataSecretControllerName) {
+		if err := (&secretsync.UserDataSecretController{
+			ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, "cluster-capi-operator-user-data-secret-controller", managedNamespace),
+			Scheme:                      mgr.GetScheme(),
+			SourceNamespace:             mapiNamespace,
+			RolloutOnUserDataChange:     userDataRollout,
+		}).SetupWithManager(mgr); err != nil {
+			klog.Error(err, "unable to create user-data-secret controller", "controller", userDataSecretControllerName)
+			os.Exit(1)
+		}
 	}
 
-	if err := (&kubeconfig.KubeconfigReconciler{
-		ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, "cluster-capi-operator-kubeconfig-controller", managedNamespace),
-		Scheme:                      mgr.GetScheme(),
-		RestCfg:                     mgr.GetConfig(),
-	}).SetupWithManager(mgr); err != nil {
-		klog.Error(err, "unable to create controller", "controller", "Kubeconfig")
-		os.Exit(1)
+	if controllerOpts.Enabled(bootstrapSecretControllerName) {
+		bootstrapSecretController := &bootstrapsecret.BootstrapSecretController{
+			ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, "cluster-capi-operator-bootstrap-secret-controller", managedNamespace),
+			Scheme:                      mgr.GetScheme(),
+		}
+		if err := (&crdgate.CRDGate{
+			ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, "cluster-capi-operator-bootstrap-secret-controller", managedNamespace),
+			Name:                        "BootstrapSecretController",
+			Objects:                     []client.Object{&clusterv1.MachineSet{}},
+			Setup:                       bootstrapSecretController.SetupWithManager,
+		}).SetupWithManager(mgr); err != nil {
+			klog.Error(err, "unable to create bootstrap-secret controller", "controller", bootstrapSecretControllerName)
+			os.Exit(1)
+		}
 	}
 
-	if err := (&capiinstaller.CapiInstallerController{
-		ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, "cluster-capi-operator-capi-installer-controller", managedNamespace),
-		Scheme:                      mgr.GetScheme(),
-		Images:                      containerImages,
-		RestCfg:                     mgr.GetConfig(),
-		Platform:                    platform,
-		ApplyClient:                 applyClient,
-		APIExtensionsClient:         apiextensionsClient,
-	}).SetupWithManager(mgr); err != nil {
-		klog.Error(err, "unable to create capi installer controller", "controller", "CAPIInstaller")
-		os.Exit(1)
+	if controllerOpts.Enabled(kubeconfigControllerName) {
+		if err := (&kubeconfig.KubeconfigReconciler{
+			ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, "cluster-capi-operator-kubeconfig-controller", managedNamespace),
+			Scheme:                      mgr.GetScheme(),
+			RestCfg:                     mgr.GetConfig(),
+		}).SetupWithManager(mgr); err != nil {
+			klog.Error(err, "unable to create controller", "controller", kubeconfigControllerName)
+			os.Exit(1)
+		}
 	}
 
-	if err := (&infracluster.InfraClusterController{
-		ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, "cluster-capi-operator-infracluster-controller", managedNamespace),
-		Scheme:                      mgr.GetScheme(),
-		Images:                      containerImages,
-		RestCfg:                     mgr.GetConfig(),
-		Platform:                    platform,
-		Infra:                       infra,
-	}).SetupWithManager(mgr, infraClusterObject); err != nil {
-		klog.Error(err, "unable to create infracluster controller", "controller", "InfraCluster")
-		os.Exit(1)
+	if controllerOpts.Enabled(capiInstallerControllerName) {
+		// The installer applies all the provider manifests at once, its requests are rate limited apart so that
+		// they do not starve the other controllers.
+		installerClient, err := clientOpts.NewClient(mgr, "capi-installer", getDefaultClientOptions())
+		if err != nil {
+			klog.Error(err, "unable to create capi installer client", "controller", capiInstallerControllerName)
+			os.Exit(1)
+		}
+
+		installerStatusClient := getClusterOperatorStatusClient(mgr, "cluster-capi-operator-capi-installer-controller", managedNamespace)
+		installerStatusClient.Client = installerClient
+
+		if err := (&capiinstaller.CapiInstallerController{
+			ClusterOperatorStatusClient: installerStatusClient,
+			Scheme:                      mgr.GetScheme(),
+			Images:                      containerImages,
+			RestCfg:                     mgr.GetConfig(),
+			Platform:                    platform,
+			BootstrapHostNetwork:        bootstrapHostNetwork,
+			PriorityClassName:           operatorConfig.ProviderDeployments.PriorityClassName,
+			DisablePodDisruptionBudgets: !operatorConfig.FeatureEnabled(configv1alpha1.FeatureProviderDisruptionBudgets),
+		}).SetupWithManager(mgr); err != nil {
+			klog.Error(err, "unable to create capi installer controller", "controller", capiInstallerControllerName)
+			os.Exit(1)
+		}
+	}
+
+	if controllerOpts.Enabled(infraClusterControllerName) {
+		infraClusterController := &infracluster.InfraClusterController{
+			ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, "cluster-capi-operator-infracluster-controller", managedNamespace),
+			Scheme:                      mgr.GetScheme(),
+			Images:                      containerImages,
+			RestCfg:                     mgr.GetConfig(),
+			Platform:                    platform,
+			Infra:                       infra,
+			MAPINamespace:               mapiNamespace,
+		}
+		if err := (&crdgate.CRDGate{
+			ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, "cluster-capi-operator-infracluster-controller", managedNamespace),
+			Name:                        "InfraClusterController",
+			Objects:                     []client.Object{infraClusterObject},
+			Setup: func(mgr ctrl.Manager) error {
+				return infraClusterController.SetupWithManager(mgr, infraClusterObject)
+			},
+		}).SetupWithManager(mgr); err != nil {
+			klog.Error(err, "unable to create infracluster controller", "controller", infraClusterControllerName)
+			os.Exit(1)
+		}
 	}
 }
 
-func setupWebhooks(mgr ctrl.Manager) {
+func setupWebhooks(mgr ctrl.Manager, managedNamespace, mapiNamespace string) {
 	if err := (&webhook.ClusterWebhook{}).SetupWebhookWithManager(mgr); err != nil {
 		klog.Error(err, "unable to create webhook", "webhook", "Cluster")
 		os.Exit(1)
 	}
-}
 
-// setFeatureGatesEnvVars sets the explicit values for the listed feature gates in the environment.
-// These will then be loaded by envsubst and templated into the applied CAPI manifests.
-func setFeatureGatesEnvVars() error {
-	featureGates := map[string]string{
-		"EXP_BOOTSTRAP_FORMAT_IGNITION": "true",
+	if err := (&webhook.MachineSetWebhook{}).SetupWebhookWithManager(mgr); err != nil {
+		klog.Error(err, "unable to create webhook", "webhook", "MachineSet")
+		os.Exit(1)
 	}
 
-	for k, v := range featureGates {
-		if err := os.Setenv(k, v); err != nil {
-			return fmt.Errorf("error setting environment variable: %s: %w", k, err)
-		}
+	if err := (&webhook.MachineWebhook{}).SetupWebhookWithManager(mgr); err != nil {
+		klog.Error(err, "unable to create webhook", "webhook", "Machine")
+		os.Exit(1)
 	}
 
-	return nil
+	if err := (&webhook.MachineDeploymentWebhook{}).SetupWebhookWithManager(mgr); err != nil {
+		klog.Error(err, "unable to create webhook", "webhook", "MachineDeployment")
+		os.Exit(1)
+	}
+
+	if err := (&webhook.MAPIAuthorityWebhook{
+		OperatorNamespace: managedNamespace,
+		MAPINamespace:     mapiNamespace,
+		ReleaseVersion:    util.GetReleaseVersion(),
+	}).SetupWebhookWithManager(mgr); err != nil {
+		klog.Error(err, "unable to create webhook", "webhook", "MAPIAuthority")
+		os.Exit(1)
+	}
 }
 
 // getAzureCloudEnvironment returns the current AzureCloudEnvironment.
@@ -380,14 +626,18 @@ func getAzureCloudEnvironment(ps *configv1.PlatformStatus) configv1.AzureCloudEn
 	return ps.Azure.CloudName
 }
 
-func setupClusterOperatorController(mgr manager.Manager, ns string, isUnsupportedPlatform bool) {
+func setupClusterOperatorController(mgr manager.Manager, controllerOpts commoncmdoptions.ControllerOptions, ns string, isUnsupportedPlatform bool) {
+	if !controllerOpts.Enabled(clusterOperatorControllerName) {
+		return
+	}
+
 	// ClusterOperator watches and keeps the cluster-api ClusterObject up to date.
 	if err := (&clusteroperator.ClusterOperatorController{
 		ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, "cluster-capi-operator-clusteroperator-controller", ns),
 		Scheme:                      mgr.GetScheme(),
 		IsUnsupportedPlatform:       isUnsupportedPlatform,
 	}).SetupWithManager(mgr); err != nil {
-		klog.Error(err, "unable to create clusteroperator controller", "controller", "ClusterOperator")
+		klog.Error(err, "unable to create clusteroperator controller", "controller", clusterOperatorControllerName)
 		os.Exit(1)
 	}
 }
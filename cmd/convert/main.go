@@ -0,0 +1,107 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The convert command prints the Cluster API objects a migration would create for exported Machine API Machines and
+// MachineSets, without cluster access, so that administrators can review them before migrating.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/conversion/offline"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run runs the command with the given arguments and returns the process exit code.
+func run(args []string) int {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: convert [flags] [file...]\n\n"+
+			"Converts the Machine API Machines and MachineSets of the files, or of the standard input, as exported by\n"+
+			"`oc get -o yaml`, and prints the Cluster API objects a migration would create. The Infrastructure of the\n"+
+			"cluster, exported by `oc get infrastructure cluster -o yaml`, must be one of the inputs.\n\n")
+		fs.PrintDefaults()
+	}
+
+	capiNamespace := fs.String(
+		"capi-namespace",
+		controllers.DefaultManagedNamespace,
+		"The namespace of the CAPI resources.",
+	)
+	platform := fs.String(
+		"platform",
+		"",
+		"Overrides the platform of the Infrastructure, e.g. AWS.",
+	)
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	inputs, closeInputs, err := openInputs(fs.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to open input: %v\n", err)
+		return 1
+	}
+	defer closeInputs()
+
+	if err := offline.Run(inputs, offline.Options{
+		Platform:      configv1.PlatformType(*platform),
+		CAPINamespace: *capiNamespace,
+	}, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "unable to convert resources: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// openInputs opens the given files, or returns the standard input when there are none, and a function closing them.
+func openInputs(paths []string) ([]io.Reader, func(), error) {
+	if len(paths) == 0 {
+		return []io.Reader{os.Stdin}, func() {}, nil
+	}
+
+	var files []*os.File
+
+	closeFiles := func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}
+
+	inputs := []io.Reader{}
+
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			closeFiles()
+			return nil, nil, err //nolint:wrapcheck
+		}
+
+		files = append(files, file)
+		inputs = append(inputs, file)
+	}
+
+	return inputs, closeFiles, nil
+}
@@ -25,17 +25,36 @@ import (
 	mapiv1beta1 "github.com/openshift/api/machine/v1beta1"
 	configv1client "github.com/openshift/client-go/config/clientset/versioned"
 	configinformers "github.com/openshift/client-go/config/informers/externalversions"
+	configv1alpha1 "github.com/openshift/cluster-capi-operator/pkg/apis/config/v1alpha1"
+	"github.com/openshift/cluster-capi-operator/pkg/commoncmdoptions"
 	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/admissionpolicy"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/adoption"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/conversionreport"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/featuregate"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/machinehealthchecksync"
 	"github.com/openshift/cluster-capi-operator/pkg/controllers/machinesetsync"
 	"github.com/openshift/cluster-capi-operator/pkg/controllers/machinesync"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/migrationsummary"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/mirrorcleanup"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/operatorconfig"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/synccommon"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/upgradeguard"
+	"github.com/openshift/cluster-capi-operator/pkg/metrics"
+	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
+	"github.com/openshift/cluster-capi-operator/pkg/tracing"
 	"github.com/openshift/cluster-capi-operator/pkg/util"
 	capav1beta2 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
+	capibmv1 "sigs.k8s.io/cluster-api-provider-ibmcloud/api/v1beta2"
+	capov1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1beta1"
 	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
 
 	"github.com/openshift/api/features"
 	featuregates "github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/spf13/pflag"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/component-base/config"
@@ -57,12 +76,51 @@ var (
 	errPlatformNotFound = errors.New("no platform provider found on install config")
 )
 
+// The names of the controllers of the binary, as selected by the command line flags.
+const (
+	operatorConfigControllerName         = "OperatorConfig"
+	machineSyncControllerName            = "MachineSync"
+	machineSetSyncControllerName         = "MachineSetSync"
+	machineHealthCheckSyncControllerName = "MachineHealthCheckSync"
+	mirrorCleanupControllerName          = "MirrorCleanup"
+	adoptionControllerName               = "Adoption"
+	upgradeGuardControllerName           = "UpgradeGuard"
+	conversionReportControllerName       = "ConversionReport"
+	migrationSummaryControllerName       = "MigrationSummary"
+	admissionPolicyControllerName        = "AdmissionPolicy"
+)
+
+// migrationName prefixes the conditions of the ClusterOperator reported by the binary as a whole, such as the mode
+// its sync controllers run in for the control plane topology.
+const migrationName = "MachineAPIMigration"
+
+// knownControllers are the controllers the --enable-controllers and --disable-controllers flags can select.
+//
+//nolint:gochecknoglobals
+var knownControllers = []string{
+	operatorConfigControllerName,
+	machineSyncControllerName,
+	machineSetSyncControllerName,
+	machineHealthCheckSyncControllerName,
+	mirrorCleanupControllerName,
+	adoptionControllerName,
+	upgradeGuardControllerName,
+	conversionReportControllerName,
+	migrationSummaryControllerName,
+	admissionPolicyControllerName,
+}
+
 func initScheme(scheme *runtime.Scheme) {
 	// TODO(joelspeed): Add additional schemes here once we work out exactly which will be needed.
 	utilruntime.Must(mapiv1beta1.AddToScheme(scheme))
 	utilruntime.Must(configv1.AddToScheme(scheme))
 	utilruntime.Must(capav1beta2.AddToScheme(scheme))
+	utilruntime.Must(capibmv1.AddToScheme(scheme))
+	utilruntime.Must(capov1.AddToScheme(scheme))
 	utilruntime.Must(capiv1beta1.AddToScheme(scheme))
+	utilruntime.Must(admissionregistrationv1beta1.AddToScheme(scheme))
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(configv1alpha1.AddToScheme(scheme))
 }
 
 //nolint:funlen
@@ -71,12 +129,11 @@ func main() {
 	initScheme(scheme)
 
 	leaderElectionConfig := config.LeaderElectionConfiguration{
-		LeaderElect:       true,
-		LeaseDuration:     util.LeaseDuration,
-		RenewDeadline:     util.RenewDeadline,
-		RetryPeriod:       util.RetryPeriod,
-		ResourceName:      "machine-api-migration-leader",
-		ResourceNamespace: "openshift-cluster-api",
+		LeaderElect:   true,
+		LeaseDuration: util.LeaseDuration,
+		RenewDeadline: util.RenewDeadline,
+		RetryPeriod:   util.RetryPeriod,
+		ResourceName:  "machine-api-migration-leader",
 	}
 
 	healthAddr := flag.String(
@@ -86,8 +143,8 @@ func main() {
 	)
 	capiManagedNamespace := flag.String(
 		"capi-namespace",
-		controllers.DefaultManagedNamespace,
-		"The namespace where CAPI components will run.",
+		util.DiscoverOperatorNamespace(controllers.DefaultManagedNamespace),
+		"The namespace where CAPI components will run, defaults to the namespace of the operator.",
 	)
 	mapiManagedNamespace := flag.String(
 		"mapi-namespace",
@@ -95,109 +152,271 @@ func main() {
 		"The namespace to watch for MAPI resources.",
 	)
 
+	machineSyncConcurrency := flag.Int(
+		"machine-sync-concurrency",
+		1,
+		"The maximum number of Machines the machine sync controller reconciles concurrently.",
+	)
+	machineSetSyncConcurrency := flag.Int(
+		"machineset-sync-concurrency",
+		1,
+		"The maximum number of MachineSets the machineset sync controller reconciles concurrently.",
+	)
+	shardCount := flag.Int(
+		"shard-count",
+		0,
+		"The number of shards the Machines and MachineSets are split into by name hash, so that several replicas can share the work. Sharding is disabled when lower than 2.",
+	)
+	shardIndex := flag.Int(
+		"shard-index",
+		0,
+		"The shard reconciled by this replica, in the range [0, shard-count). Only used when sharding is enabled.",
+	)
+	maxWorkQueueDepth := flag.Int(
+		"health-max-work-queue-depth",
+		0,
+		"The number of items queued in a sync controller above which the health check fails. Disabled when 0.",
+	)
+	maxReconcileDuration := flag.Duration(
+		"health-max-reconcile-duration",
+		10*time.Minute,
+		"The time a sync controller can spend reconciling a single item before the health check fails. Disabled when 0.",
+	)
+
+	syncRetryBaseDelay := flag.Duration(
+		"sync-retry-base-delay",
+		util.DefaultBackoffConfig.BaseDelay,
+		"The delay before retrying to synchronize a resource that failed to synchronize, it doubles on each consecutive failure.",
+	)
+	syncRetryMaxDelay := flag.Duration(
+		"sync-retry-max-delay",
+		util.DefaultBackoffConfig.MaxDelay,
+		"The maximum delay between two retries to synchronize a resource.",
+	)
+	syncRetryBudget := flag.Int(
+		"sync-retry-budget",
+		util.DefaultBackoffConfig.RetryBudget,
+		"The number of consecutive retries after which a resource that fails to synchronize is reported as Degraded and is no longer retried until it changes. Retries are unlimited when 0.",
+	)
+
+	orphanedMirrorGracePeriod := flag.Duration(
+		"orphaned-mirror-grace-period",
+		mirrorcleanup.DefaultGracePeriod,
+		"How long a paused CAPI machine mirroring a MAPI machine that no longer exists is kept before it is deleted.",
+	)
+
+	machineSetDriftThreshold := flag.Duration(
+		"machineset-drift-threshold",
+		machinesetsync.DefaultDriftThreshold,
+		"How long the MAPI and CAPI copies of a MachineSet may differ in their replicas, selector or template before the MachineSet is reported as Drifted.",
+	)
+
+	drainSettleTimeout := flag.Duration(
+		"migration-drain-timeout",
+		machinesync.DefaultDrainSettleTimeout,
+		"How long the completion of the migration of a machine waits for the drain of its node to settle, after which the migration proceeds regardless.",
+	)
+
 	logToStderr := flag.Bool(
 		"logtostderr",
 		true,
 		"log to standard error instead of files",
 	)
 
+	tracingOpts := tracing.Options{}
+	tracingOpts.AddFlags(flag.CommandLine)
+
 	textLoggerConfig := textlogger.NewConfig()
 	textLoggerConfig.AddFlags(flag.CommandLine)
 	ctrl.SetLogger(textlogger.NewLogger(textLoggerConfig))
 
 	capiManagerOptions := capiflags.ManagerOptions{}
+	diagnosticsFlags := metrics.DiagnosticsOptions{}
+	controllerOpts := commoncmdoptions.ControllerOptions{}
+	clientOpts := commoncmdoptions.ClientOptions{UserAgent: "machine-api-migration"}
 
 	// Once all the flags are registered, switch to pflag
 	// to allow leader lection flags to be bound.
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 	options.BindLeaderElectionFlags(&leaderElectionConfig, pflag.CommandLine)
 	capiflags.AddManagerOptions(pflag.CommandLine, &capiManagerOptions)
+	diagnosticsFlags.AddFlags(pflag.CommandLine)
+	controllerOpts.AddFlags(pflag.CommandLine)
+	clientOpts.AddFlags(pflag.CommandLine)
 	pflag.Parse()
 
 	if logToStderr != nil {
 		klog.LogToStderr(*logToStderr)
 	}
 
-	_, diagnosticsOpts, err := capiflags.GetManagerOptions(capiManagerOptions)
+	// The leader election lease lives in the CAPI namespace, unless set by the command line flags.
+	if leaderElectionConfig.ResourceNamespace == "" {
+		leaderElectionConfig.ResourceNamespace = *capiManagedNamespace
+	}
+
+	if err := controllerOpts.Validate(knownControllers); err != nil {
+		klog.Error(err, "invalid controller selection")
+		os.Exit(1)
+	}
+
+	if disabled := controllerOpts.Disabled(knownControllers); len(disabled) > 0 {
+		klog.Infof("Controllers disabled by the command line flags: %v", disabled)
+	}
+
+	if err := clientOpts.Validate(); err != nil {
+		klog.Error(err, "invalid API client options")
+		os.Exit(1)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracingOpts, "machine-api-migration")
 	if err != nil {
-		klog.Error(err, "unable to get manager options")
+		klog.Error(err, "unable to set up tracing")
 		os.Exit(1)
 	}
 
-	syncPeriod := 10 * time.Minute
+	shard := util.Shard{Index: *shardIndex, Count: *shardCount}
+	if err := shard.Validate(); err != nil {
+		klog.Error(err, "invalid shard configuration")
+		os.Exit(1)
+	}
 
-	cacheOpts := cache.Options{
-		DefaultNamespaces: map[string]cache.Config{
-			*capiManagedNamespace: {},
-			*mapiManagedNamespace: {},
-		},
-		SyncPeriod: &syncPeriod,
+	if shard.Enabled() {
+		// Each shard is leader elected independently, so that one replica per shard can be active at once.
+		leaderElectionConfig.ResourceName = fmt.Sprintf("%s-%s", leaderElectionConfig.ResourceName, shard)
 	}
 
-	cfg := ctrl.GetConfigOrDie()
+	backoffConfig := util.BackoffConfig{
+		BaseDelay:   *syncRetryBaseDelay,
+		MaxDelay:    *syncRetryMaxDelay,
+		Jitter:      util.DefaultBackoffConfig.Jitter,
+		RetryBudget: *syncRetryBudget,
+	}
 
-	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
-		Scheme:                  scheme,
-		Metrics:                 *diagnosticsOpts,
-		HealthProbeBindAddress:  *healthAddr,
-		LeaderElectionNamespace: leaderElectionConfig.ResourceNamespace,
-		LeaderElection:          leaderElectionConfig.LeaderElect,
-		LeaseDuration:           &leaderElectionConfig.LeaseDuration.Duration,
-		LeaderElectionID:        leaderElectionConfig.ResourceName,
-		RetryPeriod:             &leaderElectionConfig.RetryPeriod.Duration,
-		RenewDeadline:           &leaderElectionConfig.RenewDeadline.Duration,
-		Cache:                   cacheOpts,
-	})
+	_, diagnosticsOpts, err := capiflags.GetManagerOptions(capiManagerOptions)
 	if err != nil {
-		klog.Error(err, "unable to create manager")
+		klog.Error(err, "unable to get manager options")
 		os.Exit(1)
 	}
 
+	if err := diagnosticsFlags.Apply(diagnosticsOpts); err != nil {
+		klog.Error(err, "unable to configure the diagnostics endpoint")
+		os.Exit(1)
+	}
+
+	// The sync controllers have their own client, the config of the manager is shared by the other controllers.
+	cfg := clientOpts.RestConfig(ctrl.GetConfigOrDie(), "")
+
 	// This will catch signals from the OS and shutdown the manager gracefully.
-	// Set it up here as we may need to branch early if the feature gate is not enabled.
+	// Set it up here as we may need to branch early if the platform is not supported.
 	stop := ctrl.SetupSignalHandler()
 
-	featureGateAccessor, err := getFeatureGates(mgr)
+	infraClient, err := client.New(cfg, client.Options{Scheme: scheme})
 	if err != nil {
-		klog.Error(err, "unable to get feature gates")
+		klog.Error(err, "unable to set up infra client")
+		os.Exit(1)
+	}
+
+	infra := &configv1.Infrastructure{}
+	if err := infraClient.Get(stop, client.ObjectKey{Name: controllers.InfrastructureResourceName}, infra); err != nil {
+		klog.Errorf("failed to fetch infrastructure: %s", err)
 		os.Exit(1)
 	}
 
-	currentFeatureGates, err := featureGateAccessor.CurrentFeatureGates()
+	provider, err := getProviderFromInfrastructure(infra)
 	if err != nil {
-		klog.Error(err, "unable to get current feature gates")
+		klog.Errorf("failed to fetch infrastructure: %s", err)
 		os.Exit(1)
 	}
 
-	if !currentFeatureGates.Enabled(features.FeatureGateMachineAPIMigration) {
-		klog.Info("MachineAPIMigration feature gate is not enabled, nothing to do. Waiting for termination signal.")
+	// Clusters with an external control plane have no Machine API, there is nothing to synchronize.
+	externalControlPlane := util.IsExternalControlPlaneTopology(infra)
+	topologyStatusClient := operatorstatus.ClusterOperatorStatusClient{Client: infraClient, ManagedNamespace: *capiManagedNamespace}
+
+	if err := topologyStatusClient.SetControlPlaneTopologyMode(stop, migrationName, externalControlPlane, migrationTopologyMessage(externalControlPlane)); err != nil {
+		klog.Error(err, "unable to report the control plane topology mode")
+		os.Exit(1)
+	}
+
+	if externalControlPlane {
+		klog.Info("MachineAPIMigration: the control plane is external, the sync controllers are not started. Waiting for termination signal.")
 		<-stop.Done()
 		os.Exit(0)
 	}
 
-	infraClient, err := client.New(cfg, client.Options{Scheme: scheme})
+	// The operator configuration overrides the command line flags, it is read once as the manager is restarted
+	// when it changes.
+	operatorConfig, err := operatorconfig.Get(stop, infraClient, *capiManagedNamespace)
 	if err != nil {
-		klog.Error(err, "unable to set up infra client")
+		klog.Error(err, "unable to get operator config")
 		os.Exit(1)
 	}
 
-	infra := &configv1.Infrastructure{}
-	if err := infraClient.Get(stop, client.ObjectKey{Name: controllers.InfrastructureResourceName}, infra); err != nil {
-		klog.Errorf("failed to fetch infrastructure: %s", err)
+	operatorconfig.SetInt(machineSyncConcurrency, operatorConfig.Sync.MachineConcurrency)
+	operatorconfig.SetInt(machineSetSyncConcurrency, operatorConfig.Sync.MachineSetConcurrency)
+	operatorconfig.SetInt(&backoffConfig.RetryBudget, operatorConfig.Migration.SyncRetryBudget)
+	operatorconfig.SetDuration(machineSetDriftThreshold, operatorConfig.Migration.MachineSetDriftThreshold)
+	operatorconfig.SetDuration(orphanedMirrorGracePeriod, operatorConfig.Migration.OrphanedMirrorGracePeriod)
+	operatorconfig.SetDuration(drainSettleTimeout, operatorConfig.Migration.DrainSettleTimeout)
+
+	namespacePairs, err := operatorconfig.NamespacePairs(*mapiManagedNamespace, *capiManagedNamespace, operatorConfig)
+	if err != nil {
+		klog.Error(err, "invalid namespace pairs")
 		os.Exit(1)
 	}
 
-	provider, err := getProviderFromInfrastructure(infra)
+	syncPeriod := 10 * time.Minute
+
+	// The cache covers the namespaces of all the synchronized pairs, the first pair is the command line one.
+	defaultNamespaces := map[string]cache.Config{}
+	for _, pair := range namespacePairs {
+		defaultNamespaces[pair.MAPINamespace] = cache.Config{}
+		defaultNamespaces[pair.CAPINamespace] = cache.Config{}
+	}
+
+	cacheOpts := cache.Options{
+		DefaultNamespaces: defaultNamespaces,
+		// The managedFields are never read by the sync controllers, strip them to reduce the cache memory usage.
+		DefaultTransform: cache.TransformStripManagedFields(),
+		SyncPeriod:       &syncPeriod,
+	}
+
+	// The credentials secrets copied for the identities of the machine sets are read directly, caching every
+	// Secret of the synchronized namespaces is memory heavy.
+	clientOptions := client.Options{
+		Cache: &client.CacheOptions{
+			DisableFor: []client.Object{&corev1.Secret{}},
+		},
+	}
+
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme:                  scheme,
+		Metrics:                 *diagnosticsOpts,
+		HealthProbeBindAddress:  *healthAddr,
+		LeaderElectionNamespace: leaderElectionConfig.ResourceNamespace,
+		LeaderElection:          leaderElectionConfig.LeaderElect,
+		LeaseDuration:           &leaderElectionConfig.LeaseDuration.Duration,
+		LeaderElectionID:        leaderElectionConfig.ResourceName,
+		RetryPeriod:             &leaderElectionConfig.RetryPeriod.Duration,
+		RenewDeadline:           &leaderElectionConfig.RenewDeadline.Duration,
+		Cache:                   cacheOpts,
+		Client:                  clientOptions,
+	})
 	if err != nil {
-		klog.Errorf("failed to fetch infrastructure: %s", err)
+		klog.Error(err, "unable to create manager")
 		os.Exit(1)
 	}
 
-	// Currently we only plan to support AWS, so all others are a noop until they're implemented.
+	// Currently we only plan to support AWS, PowerVS and OpenStack, so all others are a noop until they're implemented.
 	switch provider {
 	case configv1.AWSPlatformType:
 		klog.Info("MachineAPIMigration: starting AWS controllers")
 
+	case configv1.PowerVSPlatformType:
+		klog.Info("MachineAPIMigration: starting PowerVS controllers")
+
+	case configv1.OpenStackPlatformType:
+		klog.Info("MachineAPIMigration: starting OpenStack controllers")
+
 	default:
 		klog.Infof("MachineAPIMigration not implemented for platform %s, nothing to do. Waiting for termination signal.", provider)
 		<-stop.Done()
@@ -211,48 +430,244 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Fail the health check when a sync controller silently stalls, so that the container is restarted.
+	if err := mgr.AddHealthzCheck("workqueues", metrics.WorkQueueHealthCheck(metrics.WorkQueueThresholds{
+		MaxDepth:          *maxWorkQueueDepth,
+		MaxProcessingTime: *maxReconcileDuration,
+	})); err != nil {
+		klog.Error(err, "unable to set up work queues health check")
+		os.Exit(1)
+	}
+
 	if err := mgr.AddReadyzCheck("check", healthz.Ping); err != nil {
 		klog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
 
-	machineSyncReconciler := machinesync.MachineSyncReconciler{
-		Infra:    infra,
-		Platform: provider,
+	// The manager is stopped to be restarted by its container when the operator configuration changes.
+	ctx, restart := context.WithCancel(stop)
 
-		MAPINamespace: *mapiManagedNamespace,
-		CAPINamespace: *capiManagedNamespace,
+	operatorConfigReconciler := operatorconfig.OperatorConfigReconciler{
+		Namespace:        *capiManagedNamespace,
+		Initial:          operatorConfig,
+		Verbosity:        textLoggerConfig.Verbosity(),
+		DefaultVerbosity: textLoggerConfig.Verbosity().String(),
+		Restart:          restart,
 	}
 
-	if err := machineSyncReconciler.SetupWithManager(mgr); err != nil {
-		klog.Error(err, "failed to set up machine sync reconciler with manager")
-		os.Exit(1)
+	if controllerOpts.Enabled(operatorConfigControllerName) {
+		if err := operatorConfigReconciler.SetupWithManager(mgr); err != nil {
+			klog.Error(err, "failed to set up operator config reconciler with manager")
+			os.Exit(1)
+		}
 	}
 
-	machineSetSyncReconciler := machinesetsync.MachineSetSyncReconciler{
-		Platform: provider,
-		Infra:    infra,
+	setupMigrationControllers := func(mgr ctrl.Manager) error {
+		// The sync controllers of all the namespace pairs share a client, rate limited apart from the other
+		// controllers so that a resync of many Machines does not starve them.
+		syncClient, err := clientOpts.NewClient(mgr, "sync", clientOptions)
+		if err != nil {
+			return fmt.Errorf("failed to set up sync controllers client: %w", err)
+		}
+
+		// The resources of each namespace pair are synchronized, cleaned up, adopted and reported on by their own
+		// controllers.
+		for _, pair := range namespacePairs {
+			machineSyncReconciler := machinesync.MachineSyncReconciler{
+				Client: syncClient,
+
+				Infra:    infra,
+				Platform: provider,
+
+				MAPINamespace: pair.MAPINamespace,
+				CAPINamespace: pair.CAPINamespace,
+
+				MaxConcurrentReconciles: *machineSyncConcurrency,
+				Shard:                   shard,
+				Backoff:                 util.NewBackoff(backoffConfig),
+				DrainSettleTimeout:      *drainSettleTimeout,
+			}
+
+			if controllerOpts.Enabled(machineSyncControllerName) {
+				if err := machineSyncReconciler.SetupWithManager(mgr); err != nil {
+					return fmt.Errorf("failed to set up machine sync reconciler for namespace %s with manager: %w", pair.MAPINamespace, err)
+				}
+			}
+
+			machineSetSyncReconciler := machinesetsync.MachineSetSyncReconciler{
+				Client: syncClient,
+
+				Platform: provider,
+				Infra:    infra,
+
+				MAPINamespace: pair.MAPINamespace,
+				CAPINamespace: pair.CAPINamespace,
+
+				MaxConcurrentReconciles: *machineSetSyncConcurrency,
+				Shard:                   shard,
+				Backoff:                 util.NewBackoff(backoffConfig),
+				DriftThreshold:          *machineSetDriftThreshold,
+			}
+
+			if controllerOpts.Enabled(machineSetSyncControllerName) {
+				if err := machineSetSyncReconciler.SetupWithManager(mgr); err != nil {
+					return fmt.Errorf("failed to set up machineset sync reconciler for namespace %s with manager: %w", pair.MAPINamespace, err)
+				}
+			}
+
+			if controllerOpts.Enabled(machineHealthCheckSyncControllerName) {
+				machineHealthCheckSyncReconciler := machinehealthchecksync.MachineHealthCheckSyncReconciler{
+					Mirror: synccommon.Mirror[*mapiv1beta1.MachineHealthCheck, *capiv1beta1.MachineHealthCheck]{
+						Client: syncClient,
+
+						MAPINamespace: pair.MAPINamespace,
+						CAPINamespace: pair.CAPINamespace,
+
+						Shard:   shard,
+						Backoff: util.NewBackoff(backoffConfig),
+					},
+					Infra: infra,
+				}
+
+				if err := machineHealthCheckSyncReconciler.SetupWithManager(mgr); err != nil {
+					return fmt.Errorf("failed to set up machinehealthcheck sync reconciler for namespace %s with manager: %w", pair.MAPINamespace, err)
+				}
+			}
+
+			if operatorConfig.FeatureEnabled(configv1alpha1.FeatureMirrorCleanup) && controllerOpts.Enabled(mirrorCleanupControllerName) {
+				mirrorCleanupReconciler := mirrorcleanup.MirrorCleanupReconciler{
+					MAPINamespace: pair.MAPINamespace,
+					CAPINamespace: pair.CAPINamespace,
+
+					GracePeriod: *orphanedMirrorGracePeriod,
+					Shard:       shard,
+				}
+
+				if err := mirrorCleanupReconciler.SetupWithManager(mgr); err != nil {
+					return fmt.Errorf("failed to set up mirror cleanup reconciler for namespace %s with manager: %w", pair.MAPINamespace, err)
+				}
+			}
+
+			if operatorConfig.FeatureEnabled(configv1alpha1.FeatureAdoption) && controllerOpts.Enabled(adoptionControllerName) {
+				adoptionReconciler := adoption.AdoptionReconciler{
+					Infra:         infra,
+					MAPINamespace: pair.MAPINamespace,
+					CAPINamespace: pair.CAPINamespace,
+
+					Shard: shard,
+				}
+
+				if err := adoptionReconciler.SetupWithManager(mgr); err != nil {
+					return fmt.Errorf("failed to set up adoption reconciler for namespace %s with manager: %w", pair.MAPINamespace, err)
+				}
+			}
+
+			// The conversion report and the migration summary consider all the resources of the pair, so they only
+			// run alongside the first shard.
+			if shard.Index != 0 {
+				continue
+			}
+
+			if operatorConfig.FeatureEnabled(configv1alpha1.FeatureConversionReport) && controllerOpts.Enabled(conversionReportControllerName) {
+				conversionReportReconciler := conversionreport.ConversionReportReconciler{
+					MAPINamespace: pair.MAPINamespace,
+					CAPINamespace: pair.CAPINamespace,
+				}
+
+				if err := conversionReportReconciler.SetupWithManager(mgr); err != nil {
+					return fmt.Errorf("failed to set up conversion report reconciler for namespace %s with manager: %w", pair.MAPINamespace, err)
+				}
+			}
+
+			if operatorConfig.FeatureEnabled(configv1alpha1.FeatureMigrationSummary) && controllerOpts.Enabled(migrationSummaryControllerName) {
+				migrationSummaryReconciler := migrationsummary.MigrationSummaryReconciler{
+					MAPINamespace: pair.MAPINamespace,
+					CAPINamespace: pair.CAPINamespace,
+				}
+
+				if err := migrationSummaryReconciler.SetupWithManager(mgr); err != nil {
+					return fmt.Errorf("failed to set up migration summary reconciler for namespace %s with manager: %w", pair.MAPINamespace, err)
+				}
+			}
+		}
+
+		// The upgrade guard and the admission policies consider the resources of all the namespace pairs, so they
+		// only run once, alongside the first shard.
+		if shard.Index == 0 {
+			upgradeGuardReconciler := upgradeguard.UpgradeGuardReconciler{
+				ClusterOperatorStatusClient: operatorstatus.ClusterOperatorStatusClient{
+					Client:           mgr.GetClient(),
+					Recorder:         mgr.GetEventRecorderFor("machine-api-migration-upgrade-guard"),
+					ManagedNamespace: *capiManagedNamespace,
+				},
+
+				NamespacePairs: namespacePairs,
+			}
+
+			if controllerOpts.Enabled(upgradeGuardControllerName) {
+				if err := upgradeGuardReconciler.SetupWithManager(mgr); err != nil {
+					return fmt.Errorf("failed to set up upgrade guard reconciler with manager: %w", err)
+				}
+			}
+
+			admissionPolicyReconciler := admissionpolicy.AdmissionPolicyReconciler{
+				NamespacePairs: namespacePairs,
+			}
+
+			if controllerOpts.Enabled(admissionPolicyControllerName) {
+				if err := admissionPolicyReconciler.SetupWithManager(mgr); err != nil {
+					return fmt.Errorf("failed to set up admission policy reconciler with manager: %w", err)
+				}
+			}
+		}
+
+		return nil
+	}
+
+	// The migration controllers run while the MachineAPIMigration feature gate is enabled. They are started and
+	// stopped when it is toggled, without restarting the manager.
+	migrationGate := &featuregate.Gate{
+		Name:     "MachineAPIMigration",
+		Feature:  features.FeatureGateMachineAPIMigration,
+		Recorder: mgr.GetEventRecorderFor("machine-api-migration"),
+		Setup:    setupMigrationControllers,
+		// The admission policies left behind while the feature gate was enabled would still enforce the migration.
+		Disabled: func(ctx context.Context) error {
+			return admissionpolicy.RemovePolicies(ctx, mgr.GetClient())
+		},
+	}
 
-		MAPINamespace: *mapiManagedNamespace,
-		CAPINamespace: *capiManagedNamespace,
+	featureGateAccessor, err := getFeatureGates(mgr, migrationGate.FeatureGatesChanged)
+	if err != nil {
+		klog.Error(err, "unable to get feature gates")
+		os.Exit(1)
 	}
 
-	if err := machineSetSyncReconciler.SetupWithManager(mgr); err != nil {
-		klog.Error(err, "failed to set up machineset sync reconciler with manager")
+	migrationGate.FeatureGates = featureGateAccessor.CurrentFeatureGates
+
+	if err := migrationGate.SetupWithManager(mgr); err != nil {
+		klog.Error(err, "failed to set up MachineAPIMigration feature gate with manager")
 		os.Exit(1)
 	}
 
 	klog.Info("Starting manager")
 
-	if err := mgr.Start(stop); err != nil {
+	err = mgr.Start(ctx)
+
+	// Flush the pending spans before exiting.
+	if shutdownErr := shutdownTracing(context.Background()); shutdownErr != nil {
+		klog.Error(shutdownErr, "unable to shut down tracing")
+	}
+
+	if err != nil {
 		klog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
 
 // getFeatureGates is used to fetch the current feature gates from the cluster.
-// We use this to check if the machine api migration is actually enabled or not.
-func getFeatureGates(mgr ctrl.Manager) (featuregates.FeatureGateAccess, error) {
+// The changeHandler is called when they change, rather than exiting the process.
+func getFeatureGates(mgr ctrl.Manager, changeHandler featuregates.FeatureGateChangeHandlerFunc) (featuregates.FeatureGateAccess, error) {
 	desiredVersion := util.GetReleaseVersion()
 	missingVersion := "0.0.1-snapshot"
 
@@ -263,13 +678,14 @@ func getFeatureGates(mgr ctrl.Manager) (featuregates.FeatureGateAccess, error) {
 
 	configInformers := configinformers.NewSharedInformerFactory(configClient, 10*time.Minute)
 
-	// By default, this will exit(0) if the featuregates change.
 	featureGateAccessor := featuregates.NewFeatureGateAccess(
 		desiredVersion, missingVersion,
 		configInformers.Config().V1().ClusterVersions(),
 		configInformers.Config().V1().FeatureGates(),
 		events.NewLoggingEventRecorder("machineapimigration"),
 	)
+	// By default, this would exit(0) when the featuregates change.
+	featureGateAccessor.SetChangeHandler(changeHandler)
 	go featureGateAccessor.Run(context.Background())
 	go configInformers.Start(context.Background().Done())
 
@@ -292,3 +708,12 @@ func getProviderFromInfrastructure(infra *configv1.Infrastructure) (configv1.Pla
 
 	return "", errPlatformNotFound
 }
+
+// migrationTopologyMessage describes the mode the sync controllers run in for the control plane topology.
+func migrationTopologyMessage(externalControlPlane bool) string {
+	if externalControlPlane {
+		return "The control plane is external, there is no Machine API to synchronize with Cluster API, the sync controllers are not started"
+	}
+
+	return "The sync controllers synchronize the Machine API resources with Cluster API"
+}
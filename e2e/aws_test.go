@@ -27,7 +27,22 @@ const (
 	awsMachineTemplateName = "aws-machine-template"
 )
 
-var _ = Describe("Cluster API AWS MachineSet", Ordered, func() {
+func init() {
+	framework.RegisterPlatformFixture(configv1.AWSPlatformType, framework.PlatformFixture{
+		CreateMachineTemplate: func(cl client.Client) (client.Object, string) {
+			_, mapiProviderSpec := getDefaultAWSMAPIProviderSpec(cl)
+
+			awsMachineTemplate := newAWSMachineTemplate(mapiProviderSpec)
+			if err := cl.Create(ctx, awsMachineTemplate); err != nil && !apierrors.IsAlreadyExists(err) {
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			return awsMachineTemplate, ""
+		},
+	})
+}
+
+var _ = Describe("Cluster API AWS MachineSet", Ordered, Label(framework.PlatformLabel(configv1.AWSPlatformType)), func() {
 	var (
 		awsMachineTemplate      *awsv1.AWSMachineTemplate
 		machineSet              *clusterv1.MachineSet
@@ -37,19 +52,17 @@ var _ = Describe("Cluster API AWS MachineSet", Ordered, func() {
 	)
 
 	BeforeAll(func() {
-		if platform != configv1.AWSPlatformType {
-			Skip("Skipping AWS E2E tests")
-		}
+		framework.SkipUnlessPlatform(platform, configv1.AWSPlatformType)
+
 		mapiDefaultMS, mapiDefaultProviderSpec = getDefaultAWSMAPIProviderSpec(cl)
 		awsClient = createAWSClient(mapiDefaultProviderSpec.Placement.Region)
 	})
 
 	AfterEach(func() {
-		if platform != configv1.AWSPlatformType {
-			// Because AfterEach always runs, even when tests are skipped, we have to
-			// explicitly skip it here for other platforms.
-			Skip("Skipping AWS E2E tests")
-		}
+		// Because AfterEach always runs, even when tests are skipped, we have to
+		// explicitly skip it here for other platforms.
+		framework.SkipUnlessPlatform(platform, configv1.AWSPlatformType)
+
 		framework.DeleteMachineSets(cl, machineSet)
 		framework.WaitForMachineSetsDeleted(cl, machineSet)
 		framework.DeleteObjects(cl, awsMachineTemplate)
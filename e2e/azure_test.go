@@ -15,7 +15,6 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	ptr "k8s.io/utils/ptr"
 	azurev1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
-	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	yaml "sigs.k8s.io/yaml"
 )
@@ -26,48 +25,13 @@ const (
 	capzManagerBootstrapCredentials = "capz-manager-bootstrap-credentials"
 )
 
-var _ = Describe("Cluster API Azure MachineSet", Ordered, func() {
-	var azureMachineTemplate *azurev1.AzureMachineTemplate
-	var machineSet *clusterv1.MachineSet
-	var mapiMachineSpec *mapiv1.AzureMachineProviderSpec
-
-	BeforeAll(func() {
-		if platform != configv1.AzurePlatformType {
-			Skip("Skipping Azure E2E tests")
-		}
-		mapiMachineSpec = getAzureMAPIProviderSpec(cl)
-	})
-
-	AfterEach(func() {
-		if platform != configv1.AzurePlatformType {
-			// Because AfterEach always runs, even when tests are skipped, we have to
-			// explicitly skip it here for other platforms.
-			Skip("Skipping Azure E2E tests")
-		}
-		framework.DeleteMachineSets(cl, machineSet)
-		framework.WaitForMachineSetsDeleted(cl, machineSet)
-		framework.DeleteObjects(cl, azureMachineTemplate)
-	})
-
-	It("should be able to run a machine", func() {
-		azureMachineTemplate = createAzureMachineTemplate(cl, mapiMachineSpec)
-
-		machineSet = framework.CreateMachineSet(cl, framework.NewMachineSetParams(
-			"azure-machineset",
-			clusterName,
-			"",
-			1,
-			corev1.ObjectReference{
-				Kind:       "AzureMachineTemplate",
-				APIVersion: infraAPIVersion,
-				Name:       azureMachineTemplateName,
-			},
-		))
-
-		framework.WaitForMachineSet(cl, machineSet.Name)
+func init() {
+	framework.RegisterPlatformFixture(configv1.AzurePlatformType, framework.PlatformFixture{
+		CreateMachineTemplate: func(cl client.Client) (client.Object, string) {
+			return createAzureMachineTemplate(cl, getAzureMAPIProviderSpec(cl)), ""
+		},
 	})
-
-})
+}
 
 func getAzureMAPIProviderSpec(cl client.Client) *mapiv1.AzureMachineProviderSpec {
 	machineSetList := &mapiv1.MachineSetList{}
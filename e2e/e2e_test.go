@@ -19,6 +19,8 @@ import (
 
 	configv1 "github.com/openshift/api/config/v1"
 	mapiv1 "github.com/openshift/api/machine/v1beta1"
+
+	"github.com/openshift/cluster-capi-operator/e2e/framework"
 )
 
 const (
@@ -33,6 +35,7 @@ var (
 	platform           configv1.PlatformType
 	clusterName        string
 	mapiInfrastructure *configv1.Infrastructure
+	capture            *framework.EnvironmentCapture
 )
 
 func init() {
@@ -58,6 +61,9 @@ var _ = BeforeSuite(func() {
 	cl, err = runtimeclient.New(cfg, runtimeclient.Options{})
 	Expect(err).ToNot(HaveOccurred())
 
+	capture, err = framework.NewEnvironmentCapture(cfg, cl)
+	Expect(err).ToNot(HaveOccurred())
+
 	infra := &configv1.Infrastructure{}
 	infraName := runtimeclient.ObjectKey{
 		Name: infrastructureName,
@@ -68,3 +74,8 @@ var _ = BeforeSuite(func() {
 	clusterName = infra.Status.InfrastructureName
 	platform = infra.Status.PlatformStatus.Type
 })
+
+// The environment is captured before the AfterEach of the failed spec cleans up its resources.
+var _ = JustAfterEach(func() {
+	capture.CaptureOnFailure()
+})
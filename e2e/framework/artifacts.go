@@ -0,0 +1,231 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// ArtifactDirEnv is the environment variable CI sets to the directory whose content is kept after the job.
+const ArtifactDirEnv = "ARTIFACT_DIR"
+
+// maxSpecDirLength bounds the length of the directory the artifacts of a spec are written to.
+const maxSpecDirLength = 128
+
+// capturedNamespaces are the namespaces whose resources, events and pod logs are captured when a spec fails.
+var capturedNamespaces = []string{MAPINamespace, CAPINamespace}
+
+// capturedKinds are the resources captured when a spec fails. The Machine API resources and their Cluster API mirrors
+// share their names, so that they are found side by side. Kinds that are not served by the cluster, such as the
+// infrastructure resources of other platforms, are skipped.
+var capturedKinds = []schema.GroupVersionKind{
+	{Group: "machine.openshift.io", Version: "v1beta1", Kind: "Machine"},
+	{Group: "machine.openshift.io", Version: "v1beta1", Kind: "MachineSet"},
+	{Group: "cluster.x-k8s.io", Version: "v1beta1", Kind: "Cluster"},
+	{Group: "cluster.x-k8s.io", Version: "v1beta1", Kind: "Machine"},
+	{Group: "cluster.x-k8s.io", Version: "v1beta1", Kind: "MachineSet"},
+	{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta2", Kind: "AWSMachine"},
+	{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta2", Kind: "AWSMachineTemplate"},
+	{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: "AzureMachine"},
+	{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: "AzureMachineTemplate"},
+	{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: "GCPMachine"},
+	{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: "GCPMachineTemplate"},
+	{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta2", Kind: "IBMPowerVSMachine"},
+	{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta2", Kind: "IBMPowerVSMachineTemplate"},
+	{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: "VSphereMachine"},
+	{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: "VSphereMachineTemplate"},
+	{Group: "admissionregistration.k8s.io", Version: "v1beta1", Kind: "ValidatingAdmissionPolicy"},
+	{Group: "admissionregistration.k8s.io", Version: "v1beta1", Kind: "ValidatingAdmissionPolicyBinding"},
+	{Group: "config.openshift.io", Version: "v1", Kind: "ClusterOperator"},
+}
+
+var specDirReplacer = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// EnvironmentCapture writes the state of the cluster to the artifacts directory when a spec fails, so that CI failures
+// can be debugged without running the job again. The artifacts of a spec are laid out as:
+//
+//	<ARTIFACT_DIR>/e2e-failures/<spec>/failure.txt
+//	<ARTIFACT_DIR>/e2e-failures/<spec>/resources/<group>/<kind>/[<namespace>/]<name>.yaml
+//	<ARTIFACT_DIR>/e2e-failures/<spec>/events/<namespace>.yaml
+//	<ARTIFACT_DIR>/e2e-failures/<spec>/logs/<namespace>/<pod>/<container>.log
+type EnvironmentCapture struct {
+	cl        client.Client
+	clientset kubernetes.Interface
+	dir       string
+}
+
+// NewEnvironmentCapture returns an EnvironmentCapture writing to the directory set in ArtifactDirEnv.
+// Nothing is captured when it is not set.
+func NewEnvironmentCapture(cfg *rest.Config, cl client.Client) (*EnvironmentCapture, error) {
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error creating clientset: %w", err)
+	}
+
+	return &EnvironmentCapture{
+		cl:        cl,
+		clientset: clientset,
+		dir:       os.Getenv(ArtifactDirEnv),
+	}, nil
+}
+
+// CaptureOnFailure captures the state of the cluster if the current spec failed.
+// It should be called from a JustAfterEach, so that it runs before the AfterEach of the spec cleans up its resources.
+// Capture errors are reported to the GinkgoWriter and never fail the spec.
+func (e *EnvironmentCapture) CaptureOnFailure() {
+	report := CurrentSpecReport()
+	if !report.Failed() {
+		return
+	}
+
+	if e.dir == "" {
+		fmt.Fprintf(GinkgoWriter, "%s is not set, not capturing the environment of the failed spec\n", ArtifactDirEnv)
+		return
+	}
+
+	dir := filepath.Join(e.dir, "e2e-failures", specDir(report.FullText()))
+
+	By(fmt.Sprintf("Capturing the environment of the failed spec to %s", dir))
+
+	failure := fmt.Sprintf("%s\n\n%s\n%s\n", report.FullText(), report.Failure.Location, report.Failure.Message)
+	e.report(writeFile(filepath.Join(dir, "failure.txt"), []byte(failure)))
+
+	for _, gvk := range capturedKinds {
+		e.report(e.captureResources(filepath.Join(dir, "resources"), gvk))
+	}
+
+	for _, namespace := range capturedNamespaces {
+		e.report(e.captureEvents(filepath.Join(dir, "events"), namespace))
+		e.report(e.captureLogs(filepath.Join(dir, "logs"), namespace))
+	}
+}
+
+// captureResources writes every resource of the given kind, in the captured namespaces for namespaced kinds.
+func (e *EnvironmentCapture) captureResources(dir string, gvk schema.GroupVersionKind) error {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+
+	if err := e.cl.List(ctx, list); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil
+		}
+
+		return fmt.Errorf("error listing %s: %w", gvk.Kind, err)
+	}
+
+	group := gvk.Group
+	if group == "" {
+		group = "core"
+	}
+
+	for _, obj := range list.Items {
+		namespace := obj.GetNamespace()
+		if namespace != "" && !slices.Contains(capturedNamespaces, namespace) {
+			continue
+		}
+
+		obj.SetManagedFields(nil)
+
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return fmt.Errorf("error marshalling %s %s: %w", gvk.Kind, client.ObjectKeyFromObject(&obj), err)
+		}
+
+		if err := writeFile(filepath.Join(dir, group, gvk.Kind, namespace, obj.GetName()+".yaml"), data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// captureEvents writes the events of the given namespace.
+func (e *EnvironmentCapture) captureEvents(dir, namespace string) error {
+	events := &corev1.EventList{}
+	if err := e.cl.List(ctx, events, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("error listing events in %s: %w", namespace, err)
+	}
+
+	data, err := yaml.Marshal(events)
+	if err != nil {
+		return fmt.Errorf("error marshalling events in %s: %w", namespace, err)
+	}
+
+	return writeFile(filepath.Join(dir, namespace+".yaml"), data)
+}
+
+// captureLogs writes the logs of every container of the pods in the given namespace.
+func (e *EnvironmentCapture) captureLogs(dir, namespace string) error {
+	pods := &corev1.PodList{}
+	if err := e.cl.List(ctx, pods, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("error listing pods in %s: %w", namespace, err)
+	}
+
+	for _, pod := range pods.Items {
+		for _, container := range pod.Spec.Containers {
+			e.report(e.captureContainerLogs(filepath.Join(dir, namespace, pod.Name, container.Name+".log"), pod, container.Name))
+		}
+	}
+
+	return nil
+}
+
+// captureContainerLogs writes the logs of the given container.
+func (e *EnvironmentCapture) captureContainerLogs(path string, pod corev1.Pod, container string) error {
+	logs, err := e.clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Container: container}).Stream(context.Background())
+	if err != nil {
+		return fmt.Errorf("error getting the logs of %s/%s: %w", pod.Name, container, err)
+	}
+	defer logs.Close()
+
+	data, err := io.ReadAll(logs)
+	if err != nil {
+		return fmt.Errorf("error reading the logs of %s/%s: %w", pod.Name, container, err)
+	}
+
+	return writeFile(path, data)
+}
+
+// report reports the given capture error, if any, to the GinkgoWriter.
+func (e *EnvironmentCapture) report(err error) {
+	if err != nil {
+		fmt.Fprintf(GinkgoWriter, "Failed to capture the environment: %v\n", err)
+	}
+}
+
+// specDir returns a directory name for the spec with the given full text.
+func specDir(fullText string) string {
+	dir := strings.Trim(specDirReplacer.ReplaceAllString(fullText, "_"), "_")
+	if len(dir) > maxSpecDirLength {
+		dir = dir[:maxSpecDirLength]
+	}
+
+	return dir
+}
+
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("error creating directory for %s: %w", path, err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+
+	return nil
+}
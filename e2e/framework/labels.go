@@ -0,0 +1,57 @@
+package framework
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// The specs are grouped in suites and tiers by their labels, so that a scheduler can select them with a label filter
+// without running them:
+//   - the capio/migration suite runs every spec labelled with LabelMigration, periodically,
+//   - the capio/migration/blocking suite only runs the ones also in the blocking tier, on every pull request.
+//
+// Ginkgo labels cannot contain a slash, so the suite names are not labels themselves.
+const (
+	// LabelMigration marks the specs exercising the Machine API migration.
+	LabelMigration = "Migration"
+
+	// SuiteMigration is the suite of every migration spec.
+	SuiteMigration = "capio/migration"
+
+	// SuiteMigrationBlocking is the suite of the migration specs whose failure blocks a pull request.
+	SuiteMigrationBlocking = "capio/migration/blocking"
+
+	tierLabelPrefix     = "tier:"
+	platformLabelPrefix = "platform:"
+	timeoutLabelPrefix  = "timeout:"
+)
+
+var (
+	// TierBlocking marks the specs whose failure blocks a pull request. They should be quick and reliable.
+	TierBlocking = Label(tierLabelPrefix + "blocking")
+
+	// TierInforming marks the specs whose failure is only reported, such as the slow or disruptive ones.
+	TierInforming = Label(tierLabelPrefix + "informing")
+)
+
+// SuiteLabelFilters maps the suite names to the label filter selecting their specs.
+var SuiteLabelFilters = map[string]string{
+	SuiteMigration:         LabelMigration,
+	SuiteMigrationBlocking: LabelMigration + "&&" + tierLabelPrefix + "blocking",
+}
+
+// PlatformLabel returns the label of the specs that only run on the platform, so that they can be skipped before
+// they are scheduled. The specs still skip themselves on the other platforms, e.g. with SkipUnlessPlatform.
+func PlatformLabel(platform configv1.PlatformType) string {
+	return platformLabelPrefix + strings.ToLower(string(platform))
+}
+
+// TimeoutLabel returns the label recording how long a spec may run, so that a scheduler running it alone can
+// time it out.
+func TimeoutLabel(timeout time.Duration) string {
+	return timeoutLabelPrefix + timeout.String()
+}
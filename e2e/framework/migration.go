@@ -0,0 +1,386 @@
+package framework
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mapiv1 "github.com/openshift/api/machine/v1beta1"
+)
+
+// The machine authorities, and the conditions and finalizer of the sync controllers.
+// The vendored Machine API types predate the migration, so the fields are accessed through unstructured objects.
+const (
+	MachineAuthorityMachineAPI = "MachineAPI"
+	MachineAuthorityClusterAPI = "ClusterAPI"
+	MachineAuthorityMigrating  = "Migrating"
+
+	SynchronizedCondition = "Synchronized"
+	SyncFinalizer         = "sync.machine.openshift.io/finalizer"
+
+	// operatorPodLabel selects the pods running the operator, and the machine API migration controllers alongside it.
+	operatorPodLabel = "k8s-app"
+	operatorPodName  = "cluster-capi-operator"
+
+	featureGateMachineAPIMigration = "MachineAPIMigration"
+
+	mapiMachineSetLabel = "machine.openshift.io/cluster-api-machineset"
+)
+
+var mapiMachineGVK = schema.GroupVersionKind{Group: "machine.openshift.io", Version: "v1beta1", Kind: "Machine"}
+
+// SkipUnlessMachineAPIMigration skips the current spec unless the MachineAPIMigration feature gate is enabled.
+func SkipUnlessMachineAPIMigration(cl client.Client) {
+	featureGate := &unstructured.Unstructured{}
+	featureGate.SetGroupVersionKind(schema.GroupVersionKind{Group: "config.openshift.io", Version: "v1", Kind: "FeatureGate"})
+	Expect(cl.Get(ctx, client.ObjectKey{Name: "cluster"}, featureGate)).To(Succeed())
+
+	versions, _, err := unstructured.NestedSlice(featureGate.Object, "status", "featureGates")
+	Expect(err).ToNot(HaveOccurred())
+
+	for _, v := range versions {
+		enabled, _, _ := unstructured.NestedSlice(v.(map[string]interface{}), "enabled")
+		for _, e := range enabled {
+			if name, _, _ := unstructured.NestedString(e.(map[string]interface{}), "name"); name == featureGateMachineAPIMigration {
+				return
+			}
+		}
+	}
+
+	Skip(fmt.Sprintf("Skipping E2E tests: the %s feature gate is not enabled", featureGateMachineAPIMigration))
+}
+
+// GetMAPIMachine gets the MAPI machine with the given name, as an unstructured object.
+func GetMAPIMachine(cl client.Client, name string) (*unstructured.Unstructured, error) {
+	machine := &unstructured.Unstructured{}
+	machine.SetGroupVersionKind(mapiMachineGVK)
+
+	if err := cl.Get(ctx, client.ObjectKey{Namespace: MAPINamespace, Name: name}, machine); err != nil {
+		return nil, fmt.Errorf("error getting MAPI machine %q: %w", name, err)
+	}
+
+	return machine, nil
+}
+
+// SetAuthoritativeAPI sets the spec.authoritativeAPI of the MAPI machine with the given name.
+func SetAuthoritativeAPI(cl client.Client, name, authority string) error {
+	machine := &unstructured.Unstructured{}
+	machine.SetGroupVersionKind(mapiMachineGVK)
+	machine.SetNamespace(MAPINamespace)
+	machine.SetName(name)
+
+	patch := client.RawPatch(types.MergePatchType, []byte(fmt.Sprintf(`{"spec":{"authoritativeAPI":%q}}`, authority)))
+	if err := cl.Patch(ctx, machine, patch); err != nil {
+		return fmt.Errorf("error setting the authoritative API of MAPI machine %q to %s: %w", name, authority, err)
+	}
+
+	return nil
+}
+
+// FlapAuthoritativeAPI flips the authoritative API of the MAPI machines between MachineAPI and ClusterAPI the given
+// number of times, starting from MachineAPI. It waits for the interval between each flip but never for the migration
+// to complete.
+// It returns the authority the machines are left with.
+func FlapAuthoritativeAPI(cl client.Client, names []string, flips int, interval time.Duration) string {
+	By(fmt.Sprintf("Flipping the authoritative API of %d machines %d times", len(names), flips))
+
+	authority := MachineAuthorityMachineAPI
+
+	for range flips {
+		if authority == MachineAuthorityMachineAPI {
+			authority = MachineAuthorityClusterAPI
+		} else {
+			authority = MachineAuthorityMachineAPI
+		}
+
+		for _, name := range names {
+			// A conflicting write by the controllers only delays the flip, the next one is retried as usual.
+			Eventually(func() error {
+				return SetAuthoritativeAPI(cl, name, authority)
+			}, WaitShort, RetryShort).Should(Succeed())
+		}
+
+		time.Sleep(interval)
+	}
+
+	return authority
+}
+
+// KillMigrationControllers deletes the pods running the machine API migration controllers, as a crash would,
+// and waits for their replacements to be ready.
+func KillMigrationControllers(cl client.Client) {
+	By("Killing the machine API migration controllers")
+
+	pods := &corev1.PodList{}
+	Expect(cl.List(ctx, pods, client.InNamespace(CAPINamespace), client.MatchingLabels{operatorPodLabel: operatorPodName})).To(Succeed())
+	Expect(pods.Items).ToNot(BeEmpty(), "should find the pods running the machine API migration controllers")
+
+	killed := map[types.UID]bool{}
+
+	for i := range pods.Items {
+		killed[pods.Items[i].UID] = true
+		Expect(client.IgnoreNotFound(cl.Delete(ctx, &pods.Items[i], client.GracePeriodSeconds(0)))).To(Succeed())
+	}
+
+	Eventually(func() error {
+		pods := &corev1.PodList{}
+		if err := cl.List(ctx, pods, client.InNamespace(CAPINamespace), client.MatchingLabels{operatorPodLabel: operatorPodName}); err != nil {
+			return err
+		}
+
+		for _, pod := range pods.Items {
+			if !killed[pod.UID] && isPodReady(&pod) {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("no replacement pod for the machine API migration controllers is ready")
+	}, WaitMedium, RetryMedium).Should(Succeed())
+}
+
+// DeleteMirror deletes the CAPI machine mirroring the MAPI machine with the given name, without waiting for it to go.
+func DeleteMirror(cl client.Client, name string) {
+	By(fmt.Sprintf("Deleting the CAPI mirror of machine %q", name))
+
+	mirror := &clusterv1.Machine{}
+	mirror.SetNamespace(CAPINamespace)
+	mirror.SetName(name)
+
+	Expect(client.IgnoreNotFound(cl.Delete(ctx, mirror))).To(Succeed())
+}
+
+// WaitForMigrationToConverge waits for the MAPI machines to report the given authority, with a synchronized counterpart.
+func WaitForMigrationToConverge(cl client.Client, names []string, authority string) {
+	for _, name := range names {
+		By(fmt.Sprintf("Waiting for machine %q to converge to the %s authority", name, authority))
+
+		Eventually(func() error {
+			machine, err := GetMAPIMachine(cl, name)
+			if err != nil {
+				return err
+			}
+
+			if status, _, _ := unstructured.NestedString(machine.Object, "status", "authoritativeAPI"); status != authority {
+				return fmt.Errorf("machine %q has authority %q", name, status)
+			}
+
+			if status := getMAPIConditionStatus(machine, SynchronizedCondition); status != string(corev1.ConditionTrue) {
+				return fmt.Errorf("machine %q has its %s condition %q", name, SynchronizedCondition, status)
+			}
+
+			return nil
+		}, WaitLong, RetryMedium).Should(Succeed())
+	}
+}
+
+// ExpectNoDuplicateInstances checks that no instance is backed by two machines of the same API, and that a MAPI machine
+// and its CAPI mirror never point to different instances.
+func ExpectNoDuplicateInstances(cl client.Client) {
+	By("Checking that no instance is duplicated")
+
+	mapiMachines := &unstructured.UnstructuredList{}
+	mapiMachines.SetGroupVersionKind(mapiMachineGVK.GroupVersion().WithKind("MachineList"))
+	Expect(cl.List(ctx, mapiMachines, client.InNamespace(MAPINamespace))).To(Succeed())
+
+	capiMachines, err := GetMachines(cl)
+	Expect(err).ToNot(HaveOccurred())
+
+	mapiProviderIDs := map[string]string{}
+
+	for _, machine := range mapiMachines.Items {
+		providerID, _, _ := unstructured.NestedString(machine.Object, "spec", "providerID")
+		if providerID == "" {
+			continue
+		}
+
+		Expect(mapiProviderIDs).ToNot(HaveKey(providerID), "instance %s backs MAPI machines %q and %q", providerID, mapiProviderIDs[providerID], machine.GetName())
+		mapiProviderIDs[providerID] = machine.GetName()
+	}
+
+	capiProviderIDs := map[string]string{}
+
+	for _, machine := range capiMachines {
+		if machine.Spec.ProviderID == nil || *machine.Spec.ProviderID == "" {
+			continue
+		}
+
+		providerID := *machine.Spec.ProviderID
+
+		Expect(capiProviderIDs).ToNot(HaveKey(providerID), "instance %s backs CAPI machines %q and %q", providerID, capiProviderIDs[providerID], machine.GetName())
+		capiProviderIDs[providerID] = machine.GetName()
+
+		if mapiName, ok := mapiProviderIDs[providerID]; ok {
+			Expect(mapiName).To(Equal(machine.GetName()), "instance %s backs MAPI machine %q and unrelated CAPI machine %q", providerID, mapiName, machine.GetName())
+		}
+	}
+}
+
+// ExpectNoOrphanFinalizers checks that no MAPI or CAPI machine stays stuck in deletion behind the sync finalizer.
+func ExpectNoOrphanFinalizers(cl client.Client) {
+	By("Checking that no machine is left behind with the sync finalizer")
+
+	Eventually(func() error {
+		mapiMachines := &unstructured.UnstructuredList{}
+		mapiMachines.SetGroupVersionKind(mapiMachineGVK.GroupVersion().WithKind("MachineList"))
+
+		if err := cl.List(ctx, mapiMachines, client.InNamespace(MAPINamespace)); err != nil {
+			return err
+		}
+
+		for _, machine := range mapiMachines.Items {
+			if isStuckBehindSyncFinalizer(&machine) {
+				return fmt.Errorf("MAPI machine %q is being deleted but still has the sync finalizer", machine.GetName())
+			}
+		}
+
+		capiMachines, err := GetMachines(cl)
+		if err != nil {
+			return err
+		}
+
+		for _, machine := range capiMachines {
+			if isStuckBehindSyncFinalizer(machine) {
+				return fmt.Errorf("CAPI machine %q is being deleted but still has the sync finalizer", machine.GetName())
+			}
+		}
+
+		return nil
+	}, WaitMedium, RetryMedium).Should(Succeed())
+}
+
+// WaitForMachinesDeleted waits for the MAPI machines with the given names, and their CAPI mirrors, to be deleted.
+func WaitForMachinesDeleted(cl client.Client, names []string) {
+	for _, name := range names {
+		By(fmt.Sprintf("Waiting for machine %q and its mirror to be deleted", name))
+
+		Eventually(func() bool {
+			_, err := GetMAPIMachine(cl, name)
+			if !apierrors.IsNotFound(err) {
+				return false
+			}
+
+			err = cl.Get(ctx, client.ObjectKey{Namespace: CAPINamespace, Name: name}, &clusterv1.Machine{})
+
+			return apierrors.IsNotFound(err)
+		}, WaitLong, RetryMedium).Should(BeTrue())
+	}
+}
+
+func isStuckBehindSyncFinalizer(obj client.Object) bool {
+	if obj.GetDeletionTimestamp().IsZero() {
+		return false
+	}
+
+	for _, finalizer := range obj.GetFinalizers() {
+		if finalizer == SyncFinalizer {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+func getMAPIConditionStatus(machine *unstructured.Unstructured, conditionType string) string {
+	conditions, _, _ := unstructured.NestedSlice(machine.Object, "status", "conditions")
+
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if condition["type"] == conditionType {
+			status, _ := condition["status"].(string)
+			return status
+		}
+	}
+
+	return ""
+}
+
+// CreateMAPIMachineSet creates a MAPI MachineSet with the given name and replicas, copied from the first MAPI MachineSet
+// of the cluster, so that the migration can be exercised on machines that are not part of the cluster workload.
+func CreateMAPIMachineSet(cl client.Client, name string, replicas int32) *mapiv1.MachineSet {
+	By(fmt.Sprintf("Creating MAPI MachineSet %q", name))
+
+	machineSets := &mapiv1.MachineSetList{}
+	Expect(cl.List(ctx, machineSets, client.InNamespace(MAPINamespace))).To(Succeed())
+	Expect(machineSets.Items).ToNot(BeEmpty(), "should find a MAPI MachineSet to copy")
+
+	template := machineSets.Items[0]
+
+	machineSet := &mapiv1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: MAPINamespace,
+		},
+		Spec: *template.Spec.DeepCopy(),
+	}
+
+	machineSet.Spec.Replicas = &replicas
+	machineSet.Spec.Selector.MatchLabels[mapiMachineSetLabel] = name
+	machineSet.Spec.Template.ObjectMeta.Labels[mapiMachineSetLabel] = name
+
+	Expect(cl.Create(ctx, machineSet)).To(Succeed())
+
+	return machineSet
+}
+
+// WaitForMAPIMachineSet waits for all the replicas of the MAPI MachineSet to be running, and returns their names.
+func WaitForMAPIMachineSet(cl client.Client, machineSet *mapiv1.MachineSet) []string {
+	By(fmt.Sprintf("Waiting for MAPI MachineSet machines %q to enter Running phase", machineSet.Name))
+
+	var names []string
+
+	Eventually(func() error {
+		machines := &mapiv1.MachineList{}
+		if err := cl.List(ctx, machines, client.InNamespace(MAPINamespace), client.MatchingLabels{mapiMachineSetLabel: machineSet.Name}); err != nil {
+			return err
+		}
+
+		replicas := ptr.Deref(machineSet.Spec.Replicas, 0)
+
+		names = []string{}
+
+		for _, machine := range machines.Items {
+			if !machine.GetDeletionTimestamp().IsZero() {
+				continue
+			}
+
+			if ptr.Deref(machine.Status.Phase, "") != "Running" {
+				return fmt.Errorf("%q: machine %q is not running", machineSet.Name, machine.Name)
+			}
+
+			names = append(names, machine.Name)
+		}
+
+		if len(names) != int(replicas) {
+			return fmt.Errorf("%q: found %d running Machines, but MachineSet has %d replicas", machineSet.Name, len(names), replicas)
+		}
+
+		return nil
+	}, WaitOverLong, RetryMedium).Should(Succeed())
+
+	return names
+}
@@ -0,0 +1,85 @@
+package framework
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// TestProviderEnvVar restricts the platform specific specs to a single provider, e.g. TEST_PROVIDER=gcp.
+// When unset, the specs run for the platform of the cluster under test.
+const TestProviderEnvVar = "TEST_PROVIDER"
+
+// PlatformFixture builds the provider specific resources needed by the provider agnostic specs,
+// so that the same specs can run against every supported platform.
+type PlatformFixture struct {
+	// Setup creates the resources the machine templates depend on, such as credentials. It is optional.
+	Setup func(cl client.Client)
+
+	// CreateMachineTemplate creates the infrastructure machine template for the MachineSets created by the specs,
+	// built from the default MAPI MachineSet, and returns it together with the failure domain of these MachineSets.
+	CreateMachineTemplate func(cl client.Client) (client.Object, string)
+}
+
+var platformFixtures = map[configv1.PlatformType]PlatformFixture{}
+
+// RegisterPlatformFixture registers the fixture used by the provider agnostic specs on the platform.
+func RegisterPlatformFixture(platform configv1.PlatformType, fixture PlatformFixture) {
+	if _, ok := platformFixtures[platform]; ok {
+		panic(fmt.Sprintf("platform fixture for %s registered twice", platform))
+	}
+
+	platformFixtures[platform] = fixture
+}
+
+// GetPlatformFixture returns the fixture registered for the platform, skipping the current spec
+// when there is none or when the platform is excluded by TEST_PROVIDER.
+func GetPlatformFixture(platform configv1.PlatformType) PlatformFixture {
+	SkipUnlessPlatform(platform, platform)
+
+	fixture, ok := platformFixtures[platform]
+	if !ok {
+		Skip(fmt.Sprintf("Skipping E2E tests: no platform fixture for %s", platform))
+	}
+
+	return fixture
+}
+
+// SkipUnlessPlatform skips the current spec unless the cluster platform is one of the supported platforms,
+// and is the one selected by TEST_PROVIDER, when set.
+// Because AfterEach always runs, even when tests are skipped, it should be called there too.
+func SkipUnlessPlatform(platform configv1.PlatformType, supported ...configv1.PlatformType) {
+	if testProvider := os.Getenv(TestProviderEnvVar); testProvider != "" && !strings.EqualFold(testProvider, string(platform)) {
+		Skip(fmt.Sprintf("Skipping %s E2E tests: %s is set to %s", platform, TestProviderEnvVar, testProvider))
+	}
+
+	for _, p := range supported {
+		if p == platform {
+			return
+		}
+	}
+
+	Skip(fmt.Sprintf("Skipping E2E tests for %v on %s", supported, platform))
+}
+
+// MachineTemplateRef returns a reference to the machine template, for the MachineSets using it.
+func MachineTemplateRef(cl client.Client, template client.Object) corev1.ObjectReference {
+	gvk, err := apiutil.GVKForObject(template, cl.Scheme())
+	Expect(err).ToNot(HaveOccurred(), "should be able to get the machine template kind")
+
+	apiVersion, kind := gvk.ToAPIVersionAndKind()
+
+	return corev1.ObjectReference{
+		Kind:       kind,
+		APIVersion: apiVersion,
+		Name:       template.GetName(),
+	}
+}
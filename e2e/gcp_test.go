@@ -5,11 +5,9 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
-	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	gcpv1 "sigs.k8s.io/cluster-api-provider-gcp/api/v1beta1"
-	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	yaml "sigs.k8s.io/yaml"
 
@@ -22,47 +20,15 @@ const (
 	gcpMachineTemplateName = "gcp-machine-template"
 )
 
-var _ = Describe("Cluster API GCP MachineSet", Ordered, func() {
-	var gcpMachineTemplate *gcpv1.GCPMachineTemplate
-	var machineSet *clusterv1.MachineSet
-	var mapiMachineSpec *mapiv1.GCPMachineProviderSpec
+func init() {
+	framework.RegisterPlatformFixture(configv1.GCPPlatformType, framework.PlatformFixture{
+		CreateMachineTemplate: func(cl client.Client) (client.Object, string) {
+			mapiProviderSpec := getGCPMAPIProviderSpec(cl)
 
-	BeforeAll(func() {
-		if platform != configv1.GCPPlatformType {
-			Skip("Skipping GCP E2E tests")
-		}
-		mapiMachineSpec = getGCPMAPIProviderSpec(cl)
-	})
-
-	AfterEach(func() {
-		if platform != configv1.GCPPlatformType {
-			// Because AfterEach always runs, even when tests are skipped, we have to
-			// explicitly skip it here for other platforms.
-			Skip("Skipping GCP E2E tests")
-		}
-		framework.DeleteMachineSets(cl, machineSet)
-		framework.WaitForMachineSetsDeleted(cl, machineSet)
-		framework.DeleteObjects(cl, gcpMachineTemplate)
-	})
-
-	It("should be able to run a machine", func() {
-		gcpMachineTemplate = createGCPMachineTemplate(cl, mapiMachineSpec)
-
-		machineSet = framework.CreateMachineSet(cl, framework.NewMachineSetParams(
-			"gcp-machineset",
-			clusterName,
-			mapiMachineSpec.Zone,
-			1,
-			corev1.ObjectReference{
-				Kind:       "GCPMachineTemplate",
-				APIVersion: infraAPIVersion,
-				Name:       gcpMachineTemplateName,
-			},
-		))
-
-		framework.WaitForMachineSet(cl, machineSet.Name)
+			return createGCPMachineTemplate(cl, mapiProviderSpec), mapiProviderSpec.Zone
+		},
 	})
-})
+}
 
 func getGCPMAPIProviderSpec(cl client.Client) *mapiv1.GCPMachineProviderSpec {
 	machineSetList := &mapiv1.MachineSetList{}
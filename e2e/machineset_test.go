@@ -0,0 +1,53 @@
+package e2e
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/cluster-capi-operator/e2e/framework"
+)
+
+// The specs below are provider agnostic, the provider specific resources are built by the
+// platform fixture registered for the platform of the cluster under test.
+var _ = Describe("Cluster API MachineSet", Ordered, func() {
+	var fixture framework.PlatformFixture
+	var machineTemplate client.Object
+	var machineSet *clusterv1.MachineSet
+
+	BeforeAll(func() {
+		fixture = framework.GetPlatformFixture(platform)
+
+		if fixture.Setup != nil {
+			fixture.Setup(cl)
+		}
+	})
+
+	AfterEach(func() {
+		// Because AfterEach always runs, even when tests are skipped, we have to
+		// explicitly skip it here for platforms without a fixture.
+		framework.GetPlatformFixture(platform)
+
+		framework.DeleteMachineSets(cl, machineSet)
+		framework.WaitForMachineSetsDeleted(cl, machineSet)
+		framework.DeleteObjects(cl, machineTemplate)
+	})
+
+	It("should be able to run a machine", func() {
+		var failureDomain string
+		machineTemplate, failureDomain = fixture.CreateMachineTemplate(cl)
+
+		machineSet = framework.CreateMachineSet(cl, framework.NewMachineSetParams(
+			fmt.Sprintf("%s-machineset", strings.ToLower(string(platform))),
+			clusterName,
+			failureDomain,
+			1,
+			framework.MachineTemplateRef(cl, machineTemplate),
+		))
+
+		framework.WaitForMachineSet(cl, machineSet.Name)
+	})
+})
@@ -0,0 +1,119 @@
+package e2e
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	mapiv1 "github.com/openshift/api/machine/v1beta1"
+
+	"github.com/openshift/cluster-capi-operator/e2e/framework"
+)
+
+const (
+	chaosMachineSetName = "migration-chaos"
+	chaosReplicas       = 3
+
+	// authorityFlips is even, so that the machines are left with the authority they started with.
+	authorityFlips        = 10
+	authorityFlipInterval = 5 * time.Second
+)
+
+// The specs below inject faults into the machine API migration and check that its invariants hold once they stop:
+// no instance is backed by two machines, no machine is stuck behind the sync finalizer,
+// and the authority and synchronized conditions converge.
+// They are Serial as they kill the operator pods, which would disrupt any other spec running alongside.
+// They are all informing: the Machine sync controller does not mirror Machines nor set their Synchronized condition
+// yet, so the migration cannot converge. The authority flapping should block pull requests once it does.
+var _ = Describe("Machine API migration under faults", Serial, Ordered, Label(
+	framework.LabelMigration, framework.PlatformLabel(configv1.AWSPlatformType), framework.TimeoutLabel(time.Hour),
+), func() {
+	var mapiMachineSet *mapiv1.MachineSet
+	var machineNames []string
+
+	BeforeAll(func() {
+		framework.SkipUnlessPlatform(platform, configv1.AWSPlatformType)
+		framework.SkipUnlessMachineAPIMigration(cl)
+
+		mapiMachineSet = framework.CreateMAPIMachineSet(cl, chaosMachineSetName, chaosReplicas)
+		machineNames = framework.WaitForMAPIMachineSet(cl, mapiMachineSet)
+		framework.WaitForMigrationToConverge(cl, machineNames, framework.MachineAuthorityMachineAPI)
+	})
+
+	AfterEach(func() {
+		// Because AfterEach always runs, even when tests are skipped, we have to
+		// explicitly skip it here for other platforms.
+		framework.SkipUnlessPlatform(platform, configv1.AWSPlatformType)
+
+		framework.ExpectNoOrphanFinalizers(cl)
+		framework.ExpectNoDuplicateInstances(cl)
+	})
+
+	AfterAll(func() {
+		framework.SkipUnlessPlatform(platform, configv1.AWSPlatformType)
+
+		if mapiMachineSet == nil {
+			return
+		}
+
+		framework.DeleteObjects(cl, mapiMachineSet)
+		framework.WaitForMachinesDeleted(cl, machineNames)
+		framework.ExpectNoOrphanFinalizers(cl)
+	})
+
+	It("should converge when the authority flaps", framework.TierInforming, func() {
+		authority := framework.FlapAuthoritativeAPI(cl, machineNames, authorityFlips, authorityFlipInterval)
+		framework.WaitForMigrationToConverge(cl, machineNames, authority)
+
+		// An odd number of flips leaves the machines with the other authority.
+		authority = framework.FlapAuthoritativeAPI(cl, machineNames, authorityFlips+1, authorityFlipInterval)
+		framework.WaitForMigrationToConverge(cl, machineNames, authority)
+
+		// Leave the machines with Machine API authority for the next specs.
+		for _, name := range machineNames {
+			Expect(framework.SetAuthoritativeAPI(cl, name, framework.MachineAuthorityMachineAPI)).To(Succeed())
+		}
+
+		framework.WaitForMigrationToConverge(cl, machineNames, framework.MachineAuthorityMachineAPI)
+	})
+
+	It("should converge when the sync controllers are killed mid-migration", framework.TierInforming, func() {
+		for _, authority := range []string{framework.MachineAuthorityClusterAPI, framework.MachineAuthorityMachineAPI} {
+			for _, name := range machineNames {
+				Expect(framework.SetAuthoritativeAPI(cl, name, authority)).To(Succeed())
+			}
+
+			framework.KillMigrationControllers(cl)
+			framework.WaitForMigrationToConverge(cl, machineNames, authority)
+		}
+	})
+
+	It("should converge when mirrors are deleted mid-migration", framework.TierInforming, func() {
+		// The mirror of the first machine is deleted as it becomes authoritative, and the mirror of the second one
+		// as it stops being authoritative. Either deletion may be propagated to the MAPI machine, which is then
+		// replaced by the MachineSet, so the machines are listed again before waiting for them to converge.
+		for _, name := range machineNames {
+			Expect(framework.SetAuthoritativeAPI(cl, name, framework.MachineAuthorityClusterAPI)).To(Succeed())
+		}
+
+		framework.DeleteMirror(cl, machineNames[0])
+		framework.WaitForMigrationToConverge(cl, machineNames[1:], framework.MachineAuthorityClusterAPI)
+
+		for _, name := range machineNames[1:] {
+			Expect(framework.SetAuthoritativeAPI(cl, name, framework.MachineAuthorityMachineAPI)).To(Succeed())
+		}
+
+		framework.DeleteMirror(cl, machineNames[1])
+
+		machineNames = framework.WaitForMAPIMachineSet(cl, mapiMachineSet)
+
+		for _, name := range machineNames {
+			Eventually(func() error {
+				return framework.SetAuthoritativeAPI(cl, name, framework.MachineAuthorityMachineAPI)
+			}, framework.WaitShort, framework.RetryShort).Should(Succeed())
+		}
+
+		framework.WaitForMigrationToConverge(cl, machineNames, framework.MachineAuthorityMachineAPI)
+	})
+})
@@ -0,0 +1,162 @@
+package e2e
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	yaml "sigs.k8s.io/yaml"
+
+	configv1 "github.com/openshift/api/config/v1"
+	mapiv1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-capi-operator/e2e/framework"
+)
+
+const (
+	openStackMachineTemplateName = "openstack-machine-template"
+)
+
+// openStackMachineTemplateGVK is the kind of the CAPO machine templates. The Cluster API provider for OpenStack is
+// not a dependency of the e2e module, so its machine templates are built as unstructured objects.
+var openStackMachineTemplateGVK = schema.GroupVersionKind{
+	Group:   "infrastructure.cluster.x-k8s.io",
+	Version: "v1beta1",
+	Kind:    "OpenStackMachineTemplate",
+}
+
+// openStackMAPIProviderSpec holds the fields of the MAPI OpenstackProviderSpec used to build the machine template.
+// The machine.openshift.io/v1alpha1 API is not vendored in the e2e module.
+type openStackMAPIProviderSpec struct {
+	Flavor           string `json:"flavor"`
+	Image            string `json:"image"`
+	AvailabilityZone string `json:"availabilityZone,omitempty"`
+	Networks         []struct {
+		UUID   string `json:"uuid,omitempty"`
+		Filter struct {
+			Name string `json:"name,omitempty"`
+		} `json:"filter,omitempty"`
+		Subnets []struct {
+			UUID   string `json:"uuid,omitempty"`
+			Filter struct {
+				Name string `json:"name,omitempty"`
+			} `json:"filter,omitempty"`
+		} `json:"subnets,omitempty"`
+	} `json:"networks,omitempty"`
+	SecurityGroups []struct {
+		UUID   string `json:"uuid,omitempty"`
+		Name   string `json:"name,omitempty"`
+		Filter struct {
+			Name string `json:"name,omitempty"`
+		} `json:"filter,omitempty"`
+	} `json:"securityGroups,omitempty"`
+	ServerGroupID   string   `json:"serverGroupID,omitempty"`
+	ServerGroupName string   `json:"serverGroupName,omitempty"`
+	Tags            []string `json:"tags,omitempty"`
+}
+
+func init() {
+	framework.RegisterPlatformFixture(configv1.OpenStackPlatformType, framework.PlatformFixture{
+		CreateMachineTemplate: func(cl client.Client) (client.Object, string) {
+			mapiProviderSpec := getOpenStackMAPIProviderSpec(cl)
+
+			return createOpenStackMachineTemplate(cl, mapiProviderSpec), mapiProviderSpec.AvailabilityZone
+		},
+	})
+}
+
+func getOpenStackMAPIProviderSpec(cl client.Client) *openStackMAPIProviderSpec {
+	machineSetList := &mapiv1.MachineSetList{}
+	Expect(cl.List(ctx, machineSetList, client.InNamespace(framework.MAPINamespace))).To(Succeed())
+
+	Expect(machineSetList.Items).ToNot(HaveLen(0))
+	machineSet := machineSetList.Items[0]
+	Expect(machineSet.Spec.Template.Spec.ProviderSpec.Value).ToNot(BeNil())
+
+	providerSpec := &openStackMAPIProviderSpec{}
+	Expect(yaml.Unmarshal(machineSet.Spec.Template.Spec.ProviderSpec.Value.Raw, providerSpec)).To(Succeed())
+
+	return providerSpec
+}
+
+// openStackResourceParam references an OpenStack resource by ID, or by name when it has none.
+func openStackResourceParam(id, name string) map[string]interface{} {
+	if id != "" {
+		return map[string]interface{}{"id": id}
+	}
+
+	return map[string]interface{}{"filter": map[string]interface{}{"name": name}}
+}
+
+func createOpenStackMachineTemplate(cl client.Client, mapiProviderSpec *openStackMAPIProviderSpec) *unstructured.Unstructured {
+	By("Creating OpenStack machine template")
+
+	Expect(mapiProviderSpec).ToNot(BeNil())
+	Expect(mapiProviderSpec.Flavor).ToNot(BeEmpty())
+	Expect(mapiProviderSpec.Image).ToNot(BeEmpty())
+
+	// The machines use the credentials of the OpenStackCluster, so the template has no identityRef.
+	spec := map[string]interface{}{
+		"flavor": mapiProviderSpec.Flavor,
+		"image":  map[string]interface{}{"filter": map[string]interface{}{"name": mapiProviderSpec.Image}},
+	}
+
+	ports := []interface{}{}
+
+	for _, network := range mapiProviderSpec.Networks {
+		fixedIPs := []interface{}{}
+		for _, subnet := range network.Subnets {
+			fixedIPs = append(fixedIPs, map[string]interface{}{"subnet": openStackResourceParam(subnet.UUID, subnet.Filter.Name)})
+		}
+
+		ports = append(ports, map[string]interface{}{
+			"network":  openStackResourceParam(network.UUID, network.Filter.Name),
+			"fixedIPs": fixedIPs,
+		})
+	}
+
+	if len(ports) > 0 {
+		spec["ports"] = ports
+	}
+
+	securityGroups := []interface{}{}
+
+	for _, securityGroup := range mapiProviderSpec.SecurityGroups {
+		name := securityGroup.Name
+		if name == "" {
+			name = securityGroup.Filter.Name
+		}
+
+		securityGroups = append(securityGroups, openStackResourceParam(securityGroup.UUID, name))
+	}
+
+	if len(securityGroups) > 0 {
+		spec["securityGroups"] = securityGroups
+	}
+
+	if mapiProviderSpec.ServerGroupID != "" || mapiProviderSpec.ServerGroupName != "" {
+		spec["serverGroup"] = openStackResourceParam(mapiProviderSpec.ServerGroupID, mapiProviderSpec.ServerGroupName)
+	}
+
+	if len(mapiProviderSpec.Tags) > 0 {
+		tags := []interface{}{}
+		for _, tag := range mapiProviderSpec.Tags {
+			tags = append(tags, tag)
+		}
+
+		spec["tags"] = tags
+	}
+
+	openStackMachineTemplate := &unstructured.Unstructured{}
+	openStackMachineTemplate.SetGroupVersionKind(openStackMachineTemplateGVK)
+	openStackMachineTemplate.SetName(openStackMachineTemplateName)
+	openStackMachineTemplate.SetNamespace(framework.CAPINamespace)
+	Expect(unstructured.SetNestedMap(openStackMachineTemplate.Object, spec, "spec", "template", "spec")).To(Succeed())
+
+	if err := cl.Create(ctx, openStackMachineTemplate); err != nil && !apierrors.IsAlreadyExists(err) {
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	return openStackMachineTemplate
+}
@@ -3,11 +3,9 @@ package e2e
 import (
 	"fmt"
 
-	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ibmpowervsv1 "sigs.k8s.io/cluster-api-provider-ibmcloud/api/v1beta2"
-	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 
@@ -21,51 +19,16 @@ import (
 )
 
 const (
-	powerVSMachineTemplateName    = "powervs-machine-template"
-	powerVSMachineTemplateVersion = "infrastructure.cluster.x-k8s.io/v1beta2"
+	powerVSMachineTemplateName = "powervs-machine-template"
 )
 
-var _ = Describe("Cluster API IBMPowerVS MachineSet", Ordered, func() {
-	var powerVSMachineTemplate *ibmpowervsv1.IBMPowerVSMachineTemplate
-	var machineSet *clusterv1.MachineSet
-	var mapiMachineSpec *mapiv1.PowerVSMachineProviderConfig
-
-	BeforeAll(func() {
-		if platform != configv1.PowerVSPlatformType {
-			Skip("Skipping PowerVS E2E tests")
-		}
-		mapiMachineSpec = getPowerVSMAPIProviderSpec(cl)
-	})
-
-	AfterEach(func() {
-		if platform != configv1.PowerVSPlatformType {
-			// Because AfterEach always runs, even when tests are skipped, we have to
-			// explicitly skip it here for other platforms.
-			Skip("Skipping PowerVS E2E tests")
-		}
-		framework.DeleteMachineSets(cl, machineSet)
-		framework.WaitForMachineSetsDeleted(cl, machineSet)
-		framework.DeleteObjects(cl, powerVSMachineTemplate)
-	})
-
-	It("should be able to run a machine", func() {
-		powerVSMachineTemplate = createIBMPowerVSMachineTemplate(cl, mapiMachineSpec)
-
-		machineSet = framework.CreateMachineSet(cl, framework.NewMachineSetParams(
-			"ibmpowervs-machineset",
-			clusterName,
-			"",
-			1,
-			corev1.ObjectReference{
-				Kind:       "IBMPowerVSMachineTemplate",
-				APIVersion: powerVSMachineTemplateVersion,
-				Name:       powerVSMachineTemplateName,
-			},
-		))
-		framework.WaitForMachineSet(cl, machineSet.Name)
+func init() {
+	framework.RegisterPlatformFixture(configv1.PowerVSPlatformType, framework.PlatformFixture{
+		CreateMachineTemplate: func(cl client.Client) (client.Object, string) {
+			return createIBMPowerVSMachineTemplate(cl, getPowerVSMAPIProviderSpec(cl)), ""
+		},
 	})
-
-})
+}
 
 func getPowerVSMAPIProviderSpec(cl client.Client) *mapiv1.PowerVSMachineProviderConfig {
 	machineSetList := &mapiv1beta1.MachineSetList{}
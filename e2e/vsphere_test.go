@@ -25,48 +25,16 @@ const (
 	vSphereCredentialsName     = "vsphere-creds"
 )
 
-var _ = Describe("Cluster API vSphere MachineSet", Ordered, func() {
-	var vSphereMachineTemplate *vspherev1.VSphereMachineTemplate
-	var machineSet *clusterv1.MachineSet
-	var mapiMachineSpec *mapiv1.VSphereMachineProviderSpec
-
-	BeforeAll(func() {
-		if platform != configv1.VSpherePlatformType {
-			Skip("Skipping vSphere E2E tests")
-		}
-		mapiMachineSpec = getVSphereMAPIProviderSpec(cl)
-		createVSphereSecret(cl, mapiMachineSpec)
-	})
-
-	AfterEach(func() {
-		if platform != configv1.VSpherePlatformType {
-			// Because AfterEach always runs, even when tests are skipped, we have to
-			// explicitly skip it here for other platforms.
-			Skip("Skipping vSphere E2E tests")
-		}
-		framework.DeleteMachineSets(cl, machineSet)
-		framework.WaitForMachineSetsDeleted(cl, machineSet)
-		framework.DeleteObjects(cl, vSphereMachineTemplate)
-	})
-
-	It("should be able to run a machine", func() {
-		vSphereMachineTemplate = createVSphereMachineTemplate(cl, mapiMachineSpec)
-
-		machineSet = framework.CreateMachineSet(cl, framework.NewMachineSetParams(
-			"vsphere-machineset",
-			clusterName,
-			"",
-			1,
-			corev1.ObjectReference{
-				Kind:       "VSphereMachineTemplate",
-				APIVersion: infraAPIVersion,
-				Name:       vSphereMachineTemplateName,
-			},
-		))
-
-		framework.WaitForMachineSet(cl, machineSet.Name)
+func init() {
+	framework.RegisterPlatformFixture(configv1.VSpherePlatformType, framework.PlatformFixture{
+		Setup: func(cl client.Client) {
+			createVSphereSecret(cl, getVSphereMAPIProviderSpec(cl))
+		},
+		CreateMachineTemplate: func(cl client.Client) (client.Object, string) {
+			return createVSphereMachineTemplate(cl, getVSphereMAPIProviderSpec(cl)), ""
+		},
 	})
-})
+}
 
 func getVSphereMAPIProviderSpec(cl client.Client) *mapiv1.VSphereMachineProviderSpec {
 	machineSetList := &mapiv1.MachineSetList{}
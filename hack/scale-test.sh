@@ -0,0 +1,69 @@
+#!/bin/bash
+
+# Measures the Machine and MachineSet sync controllers at scale against envtest, with the Scale benchmarks of their
+# packages, see pkg/test/scale. The results are written in the Go benchmark format, to the artifacts when run in CI,
+# and compared with the results of a previous run when SCALE_TEST_BASELINE is set.
+
+set -o nounset
+set -o pipefail
+
+REPO_ROOT=$(dirname "${BASH_SOURCE}")/..
+
+# The number of resources the controllers are measured with, and how long their requests are measured once they
+# synchronized them.
+export SCALE_TEST_SIZE=${SCALE_TEST_SIZE:-1000}
+export SCALE_TEST_STEADY_STATE=${SCALE_TEST_STEADY_STATE:-1m}
+
+# The results of a previous run to compare with, and the regression tolerated for each metric, in percent.
+SCALE_TEST_BASELINE=${SCALE_TEST_BASELINE:-""}
+SCALE_TEST_TOLERANCE=${SCALE_TEST_TOLERANCE:-20}
+
+ARTIFACT_DIR=${ARTIFACT_DIR:-""}
+RESULTS_DIR=${ARTIFACT_DIR:-${REPO_ROOT}/bin}
+RESULTS=${RESULTS_DIR}/scale-test-results.txt
+
+# Ensure that some home var is set and that it's not the root.
+# This is required for the kubebuilder cache.
+export HOME=${HOME:=/tmp/kubebuilder-testing}
+if [ $HOME == "/" ]; then
+  export HOME=/tmp/kubebuilder-testing
+fi
+
+mkdir -p "${RESULTS_DIR}"
+
+echo "Measuring the sync controllers with ${SCALE_TEST_SIZE} resources, results in ${RESULTS}"
+go test ./pkg/controllers/machinesync ./pkg/controllers/machinesetsync -run '^$' -bench 'Scale$' -benchtime 1x -timeout 2h | tee "${RESULTS}" || exit $?
+
+if [ -z "${SCALE_TEST_BASELINE}" ]; then
+  exit 0
+fi
+
+# Every metric is better when lower, but resources/s.
+awk -v tolerance="${SCALE_TEST_TOLERANCE}" '
+  !/^Benchmark/ { next }
+  {
+    name = $1
+    sub(/-[0-9]+$/, "", name)
+    for (i = 3; i < NF; i += 2) {
+      key = name " " $(i + 1)
+      if (FNR == NR) {
+        baseline[key] = $i
+        continue
+      }
+      if (!(key in baseline)) {
+        continue
+      }
+      base = baseline[key]
+      if ($(i + 1) == "resources/s") {
+        regressed = $i < base * (1 - tolerance / 100)
+      } else {
+        regressed = $i > base * (1 + tolerance / 100)
+      }
+      if (regressed) {
+        printf "REGRESSION %s: %s, baseline %s\n", key, $i, base
+        failed = 1
+      }
+    }
+  }
+  END { exit failed }
+' "${SCALE_TEST_BASELINE}" "${RESULTS}"
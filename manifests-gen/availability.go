@@ -0,0 +1,99 @@
+package main
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// antiAffinityTopologyKey spreads the replicas of the provider Deployments across nodes.
+	antiAffinityTopologyKey = "kubernetes.io/hostname"
+
+	// antiAffinityWeight is the weight of the preferred anti-affinity of the provider Deployments. The anti-affinity
+	// is only preferred, so that the replicas can still be scheduled on clusters with fewer nodes than replicas.
+	antiAffinityWeight = 100
+)
+
+// addPodDisruptionBudgets adds a PodDisruptionBudget for each provider Deployment, so that the voluntary disruptions,
+// such as the node drains of an upgrade, do not evict all of its replicas at once.
+func addPodDisruptionBudgets(objs []unstructured.Unstructured) []unstructured.Unstructured {
+	budgets := []unstructured.Unstructured{}
+
+	for _, obj := range objs {
+		if obj.GetKind() != "Deployment" {
+			continue
+		}
+
+		deployment := &appsv1.Deployment{}
+		if err := scheme.Convert(&obj, deployment, nil); err != nil {
+			panic(err)
+		}
+
+		budget := &policyv1.PodDisruptionBudget{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: policyv1.SchemeGroupVersion.String(),
+				Kind:       "PodDisruptionBudget",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      deployment.Name,
+				Namespace: deployment.Namespace,
+				// Keep the clusterctl provider label, so that the operator watches the budget along with the Deployment.
+				Labels: deployment.Labels,
+			},
+			Spec: podDisruptionBudgetSpec(deployment),
+		}
+
+		u := unstructured.Unstructured{}
+		if err := scheme.Convert(budget, &u, nil); err != nil {
+			panic(err)
+		}
+
+		budgets = append(budgets, u)
+	}
+
+	return append(objs, budgets...)
+}
+
+// podDisruptionBudgetSpec tunes the budget to the replicas of the Deployment. A single replica can always be evicted,
+// as requiring it to stay available would block the drain of its node forever, while a majority of the replicas is
+// kept available when there are more.
+func podDisruptionBudgetSpec(deployment *appsv1.Deployment) policyv1.PodDisruptionBudgetSpec {
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
+	maxUnavailable := intstr.FromInt32(max(1, (replicas-1)/2))
+
+	return policyv1.PodDisruptionBudgetSpec{
+		Selector:       deployment.Spec.Selector,
+		MaxUnavailable: &maxUnavailable,
+	}
+}
+
+// setPodAntiAffinity prefers scheduling the replicas of the provider Deployment on different nodes, so that a node
+// drain only evicts one of them. The affinity shipped by the provider, if any, is kept.
+func setPodAntiAffinity(deployment *appsv1.Deployment) {
+	podSpec := &deployment.Spec.Template.Spec
+	if podSpec.Affinity != nil && podSpec.Affinity.PodAntiAffinity != nil {
+		return
+	}
+
+	if podSpec.Affinity == nil {
+		podSpec.Affinity = &corev1.Affinity{}
+	}
+
+	podSpec.Affinity.PodAntiAffinity = &corev1.PodAntiAffinity{
+		PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{{
+			Weight: antiAffinityWeight,
+			PodAffinityTerm: corev1.PodAffinityTerm{
+				LabelSelector: deployment.Spec.Selector,
+				TopologyKey:   antiAffinityTopologyKey,
+			},
+		}},
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const deploymentComponents = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: capa-controller-manager
+  namespace: openshift-cluster-api
+  labels:
+    cluster.x-k8s.io/provider: infrastructure-aws
+spec:
+  replicas: %d
+  selector:
+    matchLabels:
+      control-plane: capa-controller-manager
+  template:
+    metadata:
+      labels:
+        control-plane: capa-controller-manager
+    spec:
+      containers:
+      - name: manager
+        image: registry.k8s.io/cluster-api-aws/cluster-api-aws-controller:v2.6.1
+`
+
+func TestProcessObjectsAddsPodDisruptionBudgets(t *testing.T) {
+	for _, tc := range []struct {
+		replicas       int
+		maxUnavailable int
+	}{
+		{replicas: 1, maxUnavailable: 1},
+		{replicas: 2, maxUnavailable: 1},
+		{replicas: 3, maxUnavailable: 1},
+		{replicas: 5, maxUnavailable: 2},
+	} {
+		resourceMap := processObjects(mustParseComponents(t, fmt.Sprintf(deploymentComponents, tc.replicas)), "aws")
+
+		obj := findObject(t, resourceMap[otherKey], "PodDisruptionBudget", "capa-controller-manager")
+		if obj.GetNamespace() != targetNamespace {
+			t.Errorf("expected the PodDisruptionBudget in namespace %s, got %q", targetNamespace, obj.GetNamespace())
+		}
+
+		if obj.GetLabels()["cluster.x-k8s.io/provider"] != "infrastructure-aws" {
+			t.Errorf("expected the PodDisruptionBudget to have the Deployment labels, got %v", obj.GetLabels())
+		}
+
+		budget := &policyv1.PodDisruptionBudget{}
+		if err := scheme.Convert(obj, budget, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		if expected := intstr.FromInt32(int32(tc.maxUnavailable)); budget.Spec.MaxUnavailable == nil || *budget.Spec.MaxUnavailable != expected {
+			t.Errorf("expected %d unavailable replicas out of %d, got %v", tc.maxUnavailable, tc.replicas, budget.Spec.MaxUnavailable)
+		}
+
+		if budget.Spec.Selector.MatchLabels["control-plane"] != "capa-controller-manager" {
+			t.Errorf("expected the PodDisruptionBudget to select the Deployment pods, got %v", budget.Spec.Selector)
+		}
+	}
+}
+
+func TestCustomizeDeploymentsSetsPodAntiAffinity(t *testing.T) {
+	resourceMap := processObjects(mustParseComponents(t, fmt.Sprintf(deploymentComponents, 2)), "aws")
+
+	deployment := &appsv1.Deployment{}
+	if err := scheme.Convert(findObject(t, resourceMap[otherKey], "Deployment", "capa-controller-manager"), deployment, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if deployment.Spec.Template.Spec.PriorityClassName != "system-cluster-critical" {
+		t.Errorf("expected the system-cluster-critical priority class, got %q", deployment.Spec.Template.Spec.PriorityClassName)
+	}
+
+	affinity := deployment.Spec.Template.Spec.Affinity
+	if affinity == nil || affinity.PodAntiAffinity == nil || len(affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution) != 1 {
+		t.Fatalf("expected a preferred pod anti-affinity, got %v", affinity)
+	}
+
+	term := affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0].PodAffinityTerm
+	if term.TopologyKey != antiAffinityTopologyKey || term.LabelSelector.MatchLabels["control-plane"] != "capa-controller-manager" {
+		t.Errorf("expected the replicas to be spread across nodes, got %v", term)
+	}
+}
+
+func TestSetPodAntiAffinityKeepsProviderAntiAffinity(t *testing.T) {
+	providerAntiAffinity := &corev1.PodAntiAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{TopologyKey: "topology.kubernetes.io/zone"}},
+	}
+
+	deployment := &appsv1.Deployment{}
+	deployment.Spec.Template.Spec.Affinity = &corev1.Affinity{PodAntiAffinity: providerAntiAffinity}
+
+	setPodAntiAffinity(deployment)
+
+	if deployment.Spec.Template.Spec.Affinity.PodAntiAffinity != providerAntiAffinity {
+		t.Errorf("expected the provider pod anti-affinity to be kept, got %v", deployment.Spec.Template.Spec.Affinity.PodAntiAffinity)
+	}
+}
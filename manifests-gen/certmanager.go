@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	certmangerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	admissionregistration "k8s.io/api/admissionregistration/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// injectCAFromAnnotation asks the cert-manager CA injector to inject the CA of a Certificate, by namespace/name.
+	injectCAFromAnnotation = "cert-manager.io/inject-ca-from"
+	// injectCAFromSecretAnnotation asks the cert-manager CA injector to inject the CA of a Secret, by namespace/name.
+	injectCAFromSecretAnnotation = "cert-manager.io/inject-ca-from-secret"
+)
+
+// replaceCertManager replaces the cert-manager resources and annotations shipped by the provider with their
+// OpenShift service CA counterparts:
+//   - the cert-manager resources, such as Certificates and Issuers, are dropped,
+//   - the CA injection annotations of the webhook configurations and CRDs are replaced by the inject-cabundle annotation,
+//   - the services serving these webhooks, or named in the DNS names of a Certificate, get the serving-cert-secret-name
+//     annotation, so that the service CA issues the certificate in the secret the provider mounts.
+//
+// Any other cert-manager annotation is dropped, as nothing would act on it.
+func replaceCertManager(objs []unstructured.Unstructured) []unstructured.Unstructured {
+	certSecretNames := findCertificateSecretNames(objs)
+	serviceSecretNames := findCertificateServiceSecretNames(objs)
+
+	for _, obj := range objs {
+		secretName := ""
+
+		if certNN, ok := obj.GetAnnotations()[injectCAFromAnnotation]; ok {
+			if secretName, ok = certSecretNames[mustParseNamespacedName(certNN)]; !ok {
+				panic(fmt.Sprintf("can't find the secret of certificate %s injected in %s %s", certNN, obj.GetKind(), obj.GetName()))
+			}
+		} else if secretNN, ok := obj.GetAnnotations()[injectCAFromSecretAnnotation]; ok {
+			secretName = mustParseNamespacedName(secretNN).Name
+		} else {
+			continue
+		}
+
+		for _, service := range injectedServices(obj) {
+			serviceSecretNames[service] = secretName
+		}
+	}
+
+	replaced := []unstructured.Unstructured{}
+
+	for _, obj := range objs {
+		if obj.GroupVersionKind().Group == certManagerGroup {
+			continue
+		}
+
+		replaceCertManagerAnnotations(&obj)
+
+		if obj.GetKind() == "Service" {
+			setServingCertSecretName(&obj, serviceSecretNames)
+		}
+
+		replaced = append(replaced, obj)
+	}
+
+	return replaced
+}
+
+// findCertificateSecretNames returns the names of the secrets the Certificates are issued in, by Certificate.
+func findCertificateSecretNames(objs []unstructured.Unstructured) map[types.NamespacedName]string {
+	certSecretNames := map[types.NamespacedName]string{}
+
+	for _, cert := range certificates(objs) {
+		certSecretNames[types.NamespacedName{Namespace: cert.Namespace, Name: cert.Name}] = cert.Spec.SecretName
+	}
+
+	return certSecretNames
+}
+
+// findCertificateServiceSecretNames returns the names of the secrets the Certificates are issued in, by the services
+// found in their DNS names, so that certificates not injected anywhere, such as metrics serving certificates, are
+// still issued by the service CA.
+func findCertificateServiceSecretNames(objs []unstructured.Unstructured) map[types.NamespacedName]string {
+	serviceSecretNames := map[types.NamespacedName]string{}
+
+	for _, cert := range certificates(objs) {
+		for _, dnsName := range cert.Spec.DNSNames {
+			labels := strings.Split(strings.TrimSuffix(dnsName, ".cluster.local"), ".")
+			if len(labels) != 3 || labels[2] != "svc" {
+				continue
+			}
+
+			serviceSecretNames[types.NamespacedName{Namespace: labels[1], Name: labels[0]}] = cert.Spec.SecretName
+		}
+	}
+
+	return serviceSecretNames
+}
+
+func certificates(objs []unstructured.Unstructured) []certmangerv1.Certificate {
+	certs := []certmangerv1.Certificate{}
+
+	for i := range objs {
+		if objs[i].GetKind() != "Certificate" || objs[i].GroupVersionKind().Group != certManagerGroup {
+			continue
+		}
+
+		cert := certmangerv1.Certificate{}
+		if err := scheme.Convert(&objs[i], &cert, nil); err != nil {
+			panic(err)
+		}
+
+		certs = append(certs, cert)
+	}
+
+	return certs
+}
+
+// injectedServices returns the services serving the webhooks of the webhook configurations, or the conversion
+// webhook of the CRDs.
+func injectedServices(obj unstructured.Unstructured) []types.NamespacedName {
+	services := []types.NamespacedName{}
+
+	switch obj.GetKind() {
+	case "CustomResourceDefinition":
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := scheme.Convert(&obj, crd, nil); err != nil {
+			panic(err)
+		}
+
+		if crd.Spec.Conversion != nil && crd.Spec.Conversion.Webhook != nil && crd.Spec.Conversion.Webhook.ClientConfig != nil {
+			if service := crd.Spec.Conversion.Webhook.ClientConfig.Service; service != nil {
+				services = append(services, types.NamespacedName{Namespace: service.Namespace, Name: service.Name})
+			}
+		}
+	case "MutatingWebhookConfiguration":
+		mwc := &admissionregistration.MutatingWebhookConfiguration{}
+		if err := scheme.Convert(&obj, mwc, nil); err != nil {
+			panic(err)
+		}
+
+		for _, webhook := range mwc.Webhooks {
+			if service := webhook.ClientConfig.Service; service != nil {
+				services = append(services, types.NamespacedName{Namespace: service.Namespace, Name: service.Name})
+			}
+		}
+	case "ValidatingWebhookConfiguration":
+		vwc := &admissionregistration.ValidatingWebhookConfiguration{}
+		if err := scheme.Convert(&obj, vwc, nil); err != nil {
+			panic(err)
+		}
+
+		for _, webhook := range vwc.Webhooks {
+			if service := webhook.ClientConfig.Service; service != nil {
+				services = append(services, types.NamespacedName{Namespace: service.Namespace, Name: service.Name})
+			}
+		}
+	}
+
+	return services
+}
+
+// replaceCertManagerAnnotations replaces the CA injection annotations by the inject-cabundle annotation,
+// and drops any other cert-manager annotation.
+func replaceCertManagerAnnotations(obj *unstructured.Unstructured) {
+	anns := obj.GetAnnotations()
+	replaced := false
+
+	for key := range anns {
+		if !strings.HasPrefix(key, certManagerGroup+"/") {
+			continue
+		}
+
+		if key == injectCAFromAnnotation || key == injectCAFromSecretAnnotation {
+			anns[injectCABundleAnnotation] = "true"
+		}
+
+		delete(anns, key)
+
+		replaced = true
+	}
+
+	if replaced {
+		obj.SetAnnotations(anns)
+	}
+}
+
+func setServingCertSecretName(obj *unstructured.Unstructured, serviceSecretNames map[types.NamespacedName]string) {
+	name, ok := serviceSecretNames[types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}]
+	if !ok {
+		return
+	}
+
+	anns := obj.GetAnnotations()
+	if anns == nil {
+		anns = map[string]string{}
+	}
+
+	anns[servingCertSecretAnnotation] = name
+	obj.SetAnnotations(anns)
+}
+
+func mustParseNamespacedName(nn string) types.NamespacedName {
+	namespace, name, ok := strings.Cut(nn, "/")
+	if !ok {
+		panic("expected a namespace/name reference, got: " + nn)
+	}
+
+	return types.NamespacedName{Namespace: namespace, Name: name}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const certManagerProviderComponents = `
+apiVersion: cert-manager.io/v1
+kind: Issuer
+metadata:
+  name: capa-selfsigned-issuer
+  namespace: openshift-cluster-api
+spec:
+  selfSigned: {}
+---
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: capa-serving-cert
+  namespace: openshift-cluster-api
+spec:
+  secretName: capa-webhook-service-cert
+  issuerRef:
+    name: capa-selfsigned-issuer
+---
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: capa-metrics-cert
+  namespace: openshift-cluster-api
+spec:
+  secretName: capa-metrics-cert
+  dnsNames:
+  - capa-metrics-service.openshift-cluster-api.svc
+  - capa-metrics-service.openshift-cluster-api.svc.cluster.local
+  issuerRef:
+    name: capa-selfsigned-issuer
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: capa-webhook-service
+  namespace: openshift-cluster-api
+spec:
+  ports:
+  - port: 443
+    targetPort: 9443
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: capa-defaulting-webhook-service
+  namespace: openshift-cluster-api
+spec:
+  ports:
+  - port: 443
+    targetPort: 9444
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: capa-metrics-service
+  namespace: openshift-cluster-api
+spec:
+  ports:
+  - port: 8443
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: MutatingWebhookConfiguration
+metadata:
+  name: capa-mutating-webhook-configuration
+  annotations:
+    cert-manager.io/inject-ca-from: openshift-cluster-api/capa-serving-cert
+webhooks:
+- name: default.awsmachine.infrastructure.cluster.x-k8s.io
+  admissionReviewVersions: ["v1"]
+  sideEffects: None
+  clientConfig:
+    service:
+      name: capa-webhook-service
+      namespace: openshift-cluster-api
+      path: /mutate-infrastructure-cluster-x-k8s-io-v1beta2-awsmachine
+- name: default.awscluster.infrastructure.cluster.x-k8s.io
+  admissionReviewVersions: ["v1"]
+  sideEffects: None
+  clientConfig:
+    service:
+      name: capa-defaulting-webhook-service
+      namespace: openshift-cluster-api
+      path: /mutate-infrastructure-cluster-x-k8s-io-v1beta2-awscluster
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: capa-validating-webhook-configuration
+  annotations:
+    cert-manager.io/inject-ca-from-secret: openshift-cluster-api/capa-validating-webhook-cert
+    cert-manager.io/allow-direct-injection: "true"
+webhooks:
+- name: validation.awsmachine.infrastructure.cluster.x-k8s.io
+  admissionReviewVersions: ["v1"]
+  sideEffects: None
+  clientConfig:
+    service:
+      name: capa-webhook-service
+      namespace: openshift-cluster-api
+      path: /validate-infrastructure-cluster-x-k8s-io-v1beta2-awsmachine
+`
+
+func TestReplaceCertManager(t *testing.T) {
+	objs := replaceCertManager(mustParseComponents(t, certManagerProviderComponents))
+
+	for _, obj := range objs {
+		if obj.GroupVersionKind().Group == certManagerGroup {
+			t.Errorf("expected the cert-manager resources to be dropped, found %s %s", obj.GetKind(), obj.GetName())
+		}
+	}
+
+	mwc := findObject(t, objs, "MutatingWebhookConfiguration", "capa-mutating-webhook-configuration")
+	assertAnnotations(t, mwc, map[string]string{injectCABundleAnnotation: "true"})
+
+	vwc := findObject(t, objs, "ValidatingWebhookConfiguration", "capa-validating-webhook-configuration")
+	assertAnnotations(t, vwc, map[string]string{injectCABundleAnnotation: "true"})
+
+	// Both webhook configurations use capa-webhook-service, the validating one is processed last.
+	assertAnnotations(t, findObject(t, objs, "Service", "capa-webhook-service"), map[string]string{servingCertSecretAnnotation: "capa-validating-webhook-cert"})
+	assertAnnotations(t, findObject(t, objs, "Service", "capa-defaulting-webhook-service"), map[string]string{servingCertSecretAnnotation: "capa-webhook-service-cert"})
+	assertAnnotations(t, findObject(t, objs, "Service", "capa-metrics-service"), map[string]string{servingCertSecretAnnotation: "capa-metrics-cert"})
+}
+
+func TestProcessObjectsShipsNoCertManagerResources(t *testing.T) {
+	resourceMap := processObjects(mustParseComponents(t, certManagerProviderComponents), "aws")
+
+	assertProblems(t, validateObjects(resourceMap[otherKey]))
+}
+
+func TestReplaceCertManagerRequiresInjectedCertificate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic when the injected certificate is not shipped")
+		}
+	}()
+
+	replaceCertManager(mustParseComponents(t, `
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: capa-validating-webhook-configuration
+  annotations:
+    cert-manager.io/inject-ca-from: openshift-cluster-api/capa-serving-cert
+webhooks: []
+`))
+}
+
+func assertAnnotations(t *testing.T, obj *unstructured.Unstructured, expected map[string]string) {
+	t.Helper()
+
+	annotations := obj.GetAnnotations()
+	if len(annotations) != len(expected) {
+		t.Fatalf("expected annotations %v, got %v", expected, annotations)
+	}
+
+	for key, value := range expected {
+		if annotations[key] != value {
+			t.Errorf("expected annotation %s to be %q, got %q", key, value, annotations[key])
+		}
+	}
+}
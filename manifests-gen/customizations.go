@@ -4,14 +4,13 @@ import (
 	"bytes"
 	"strings"
 
-	certmangerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
-	admissionregistration "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/kustomize/api/krusty"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
@@ -51,8 +50,9 @@ func processObjects(objs []unstructured.Unstructured, providerName string) map[r
 	crdObjs := []unstructured.Unstructured{}
 
 	objs = addInfraClusterProtectionPolicy(objs, providerName)
-
-	serviceSecretNames := findWebhookServiceSecretName(objs)
+	objs = narrowRBAC(objs)
+	objs = replaceCertManager(objs)
+	objs = addPodDisruptionBudgets(objs)
 
 	for _, obj := range objs {
 		providerCustomizations(&obj, providerName)
@@ -67,14 +67,11 @@ func processObjects(objs []unstructured.Unstructured, providerName string) map[r
 			// we have a custom controller for reconciling it.
 			// For more information: https://issues.redhat.com/browse/OCPCLOUD-1506
 			removeClusterDefaultingWebhooks(&obj)
-			replaceCertManagerAnnotations(&obj)
 			providerConfigMapObjs = append(providerConfigMapObjs, obj)
 		case "ValidatingWebhookConfiguration":
 			removeClusterValidatingWebhooks(&obj)
-			replaceCertManagerAnnotations(&obj)
 			providerConfigMapObjs = append(providerConfigMapObjs, obj)
 		case "CustomResourceDefinition":
-			replaceCertManagerAnnotations(&obj)
 			removeConversionWebhook(&obj)
 			setOpenShiftAnnotations(obj, true)
 			// Apply NoUpgrade annotations unless IPAM CRDs,
@@ -91,7 +88,6 @@ func processObjects(objs []unstructured.Unstructured, providerName string) map[r
 				providerConfigMapObjs = append(providerConfigMapObjs, obj)
 			}
 		case "Service":
-			replaceCertMangerServiceSecret(&obj, serviceSecretNames)
 			setOpenShiftAnnotations(obj, true)
 			setNoUpgradeAnnotations(obj)
 			providerConfigMapObjs = append(providerConfigMapObjs, obj)
@@ -102,13 +98,15 @@ func processObjects(objs []unstructured.Unstructured, providerName string) map[r
 				setNoUpgradeAnnotations(obj)
 			}
 			providerConfigMapObjs = append(providerConfigMapObjs, obj)
+		case "PodDisruptionBudget":
+			providerConfigMapObjs = append(providerConfigMapObjs, obj)
 		case "ValidatingAdmissionPolicy":
 			providerConfigMapObjs = append(providerConfigMapObjs, obj)
 		case "ValidatingAdmissionPolicyBinding":
 			providerConfigMapObjs = append(providerConfigMapObjs, obj)
 		case "ConfigMap":
 			providerConfigMapObjs = append(providerConfigMapObjs, obj)
-		case "Certificate", "Issuer", "Namespace", "Secret": // skip
+		case "Namespace", "Secret": // skip
 		}
 	}
 
@@ -144,86 +142,13 @@ func setNoUpgradeAnnotations(obj unstructured.Unstructured) {
 	obj.SetAnnotations(anno)
 }
 
-func findWebhookServiceSecretName(objs []unstructured.Unstructured) map[string]string {
-	serviceSecretNames := map[string]string{}
-	certSecretNames := map[string]string{}
-
-	secretFromCertNN := func(certNN string) (string, bool) {
-		if len(certNN) == 0 {
-			return "", false
-		}
-		certName := strings.Split(certNN, "/")[1]
-		secretName, ok := certSecretNames[certName]
-		if !ok || secretName == "" {
-			return "", false
-		}
-		return secretName, true
-	}
-	// find service, then cert, then secret
-	// return map[certName] = secretName
-	for i, obj := range objs {
-		switch obj.GetKind() {
-		case "Certificate":
-			cert := &certmangerv1.Certificate{}
-			if err := scheme.Convert(&objs[i], cert, nil); err != nil {
-				panic(err)
-			}
-			certSecretNames[cert.Name] = cert.Spec.SecretName
-		}
-	}
-	for _, obj := range objs {
-		switch obj.GetKind() {
-		case "CustomResourceDefinition":
-			crd := &apiextensionsv1.CustomResourceDefinition{}
-			if err := scheme.Convert(&obj, crd, nil); err != nil {
-				panic(err)
-			}
-			if certNN, ok := crd.Annotations["cert-manager.io/inject-ca-from"]; ok {
-				secretName, ok := secretFromCertNN(certNN)
-				if !ok {
-					panic("can't find secret from cert: " + certNN)
-				}
-				if crd.Spec.Conversion != nil {
-					serviceSecretNames[crd.Spec.Conversion.Webhook.ClientConfig.Service.Name] = secretName
-				}
-			}
-
-		case "MutatingWebhookConfiguration":
-			mwc := &admissionregistration.MutatingWebhookConfiguration{}
-			if err := scheme.Convert(&obj, mwc, nil); err != nil {
-				panic(err)
-			}
-			if certNN, ok := mwc.Annotations["cert-manager.io/inject-ca-from"]; ok {
-				secretName, ok := secretFromCertNN(certNN)
-				if !ok {
-					panic("can't find secret from cert: " + certNN)
-				}
-				serviceSecretNames[mwc.Webhooks[0].ClientConfig.Service.Name] = secretName
-			}
-
-		case "ValidatingWebhookConfiguration":
-			vwc := &admissionregistration.ValidatingWebhookConfiguration{}
-			if err := scheme.Convert(&obj, vwc, nil); err != nil {
-				panic(err)
-			}
-			if certNN, ok := vwc.Annotations["cert-manager.io/inject-ca-from"]; ok {
-				secretName, ok := secretFromCertNN(certNN)
-				if !ok {
-					panic("can't find secret from cert:CustomResourceDefinition " + certNN)
-				}
-				serviceSecretNames[vwc.Webhooks[0].ClientConfig.Service.Name] = secretName
-			}
-		}
-	}
-	return serviceSecretNames
-}
-
 func customizeDeployments(obj *unstructured.Unstructured) {
 	deployment := &appsv1.Deployment{}
 	if err := scheme.Convert(obj, deployment, nil); err != nil {
 		panic(err)
 	}
 	deployment.Spec.Template.Spec.PriorityClassName = "system-cluster-critical"
+	setPodAntiAffinity(deployment)
 
 	deployment.Spec.Template.Annotations = mergeMaps(deployment.Spec.Template.Annotations, openshiftWorkloadAnnotation)
 
@@ -253,29 +178,6 @@ func customizeDeployments(obj *unstructured.Unstructured) {
 	}
 }
 
-func replaceCertManagerAnnotations(obj *unstructured.Unstructured) {
-	anns := obj.GetAnnotations()
-	if anns == nil {
-		anns = map[string]string{}
-	}
-	if _, ok := anns["cert-manager.io/inject-ca-from"]; ok {
-		anns["service.beta.openshift.io/inject-cabundle"] = "true"
-		delete(anns, "cert-manager.io/inject-ca-from")
-		obj.SetAnnotations(anns)
-	}
-}
-
-func replaceCertMangerServiceSecret(obj *unstructured.Unstructured, serviceSecretNames map[string]string) {
-	anns := obj.GetAnnotations()
-	if anns == nil {
-		anns = map[string]string{}
-	}
-	if name, ok := serviceSecretNames[obj.GetName()]; ok {
-		anns["service.beta.openshift.io/serving-cert-secret-name"] = name
-		obj.SetAnnotations(anns)
-	}
-}
-
 func removeConversionWebhook(obj *unstructured.Unstructured) {
 	crd := &apiextensionsv1.CustomResourceDefinition{}
 	if err := scheme.Convert(obj, crd, nil); err != nil {
@@ -397,5 +299,23 @@ func addInfraClusterProtectionPolicy(objs []unstructured.Unstructured, providerN
 		},
 	}
 
+	// The policy is added after clusterctl has labelled the provider components,
+	// label it the same way so the operator watches it and restores it when it drifts or is deleted.
+	if providerLabel := getProviderLabel(objs); providerLabel != "" {
+		policy.SetLabels(map[string]string{clusterv1.ProviderNameLabel: providerLabel})
+		binding.SetLabels(map[string]string{clusterv1.ProviderNameLabel: providerLabel})
+	}
+
 	return append(objs, *policy, *binding)
 }
+
+// getProviderLabel returns the provider name label set by clusterctl on the provider components.
+func getProviderLabel(objs []unstructured.Unstructured) string {
+	for _, obj := range objs {
+		if providerLabel, ok := obj.GetLabels()[clusterv1.ProviderNameLabel]; ok {
+			return providerLabel
+		}
+	}
+
+	return ""
+}
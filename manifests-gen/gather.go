@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// gatherCommand switches manifests-gen to rendering the must-gather collection script of the providers found in
+	// the manifests path, rather than generating their manifests.
+	gatherCommand = "gather"
+
+	// managedByLabel and managedByLabelValue are set by the operator on the resources it manages.
+	managedByLabel      = "cluster-api.openshift.io/managed-by"
+	managedByLabelValue = "cluster-capi-operator"
+)
+
+var gatherOutput = flag.String("output", "", "path of the rendered must-gather collection script, written to the standard output when not set")
+
+// gatherScriptTemplate collects the resources needed by support cases. The resources of the operator, including the
+// provider Deployments, CRDs and transport ConfigMaps, are collected through the related objects of the ClusterOperator,
+// the custom resources of the providers and the mirrored Machine API resources in every namespace, and the other
+// resources labeled as managed by the operator, except Secrets, are listed.
+var gatherScriptTemplate = template.Must(template.New("gather").Parse(`#!/bin/bash
+# Code generated by manifests-gen gather. DO NOT EDIT.
+
+# Gathers the Cluster API resources managed by the cluster-capi-operator.
+
+BASE_COLLECTION_PATH="${BASE_COLLECTION_PATH:-/must-gather}"
+CLUSTER_API_PATH="${BASE_COLLECTION_PATH}/cluster-api"
+
+mkdir -p "${CLUSTER_API_PATH}"
+
+# The operator and provider resources reported in the ClusterOperator related objects.
+oc adm inspect --dest-dir "${BASE_COLLECTION_PATH}" clusteroperator/{{ .ClusterOperator }}
+
+# The custom resources of the providers.
+oc adm inspect --dest-dir "${BASE_COLLECTION_PATH}" --all-namespaces {{ .Resources }}
+
+# The Machine API resources, mirrored to and from Cluster API.
+oc adm inspect --dest-dir "${BASE_COLLECTION_PATH}" --all-namespaces machines.machine.openshift.io,machinesets.machine.openshift.io
+
+# The other resources managed by the operator.
+oc get validatingadmissionpolicies,validatingadmissionpolicybindings -l {{ .Selector }} -o yaml > "${CLUSTER_API_PATH}/admission-policies.yaml"
+oc get configmaps --all-namespaces -l {{ .Selector }} -o yaml > "${CLUSTER_API_PATH}/configmaps.yaml"
+
+# force disk flush to ensure that all data gathered is accessible in the copy container
+sync
+`))
+
+// gather renders the must-gather collection script of the providers found in the manifests path.
+func gather() {
+	// The flags follow the command, which the flag package would otherwise consider as the first argument.
+	if err := flag.CommandLine.Parse(os.Args[2:]); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if *manifestsPath == "" {
+		fmt.Println("error mandatory flag manifests-path must be specified")
+		os.Exit(1)
+	}
+
+	script, err := renderGatherScript(*manifestsPath)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if *gatherOutput == "" {
+		fmt.Print(string(script))
+		return
+	}
+
+	if err := os.WriteFile(*gatherOutput, script, 0755); err != nil { //nolint:gosec
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// renderGatherScript renders the must-gather collection script, collecting the custom resources of the CRDs of the
+// providers found in the manifests path.
+func renderGatherScript(manifestsDir string) ([]byte, error) {
+	resources, err := providerCRDResources(manifestsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resources) == 0 {
+		return nil, fmt.Errorf("error no provider CRDs found in %q", manifestsDir)
+	}
+
+	var script bytes.Buffer
+	if err := gatherScriptTemplate.Execute(&script, map[string]string{
+		"ClusterOperator": "cluster-api",
+		"Resources":       strings.Join(resources, ","),
+		"Selector":        managedByLabel + "=" + managedByLabelValue,
+	}); err != nil {
+		return nil, fmt.Errorf("error rendering the gather script: %w", err)
+	}
+
+	return script.Bytes(), nil
+}
+
+// providerCRDResources returns the sorted resource names, e.g. awsmachines.infrastructure.cluster.x-k8s.io, of the CRDs
+// shipped in the provider ConfigMap and CRD manifests of the manifests path.
+func providerCRDResources(manifestsDir string) ([]string, error) {
+	configMapFiles, crdFiles, err := providerManifestFiles(manifestsDir, "")
+	if err != nil {
+		return nil, err
+	}
+
+	objs := []unstructured.Unstructured{}
+
+	for _, fileName := range configMapFiles {
+		data, err := os.ReadFile(fileName)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", fileName, err)
+		}
+
+		cm := &corev1.ConfigMap{}
+		if err := yaml.Unmarshal(data, cm); err != nil {
+			return nil, fmt.Errorf("error parsing provider ConfigMap %s: %w", fileName, err)
+		}
+
+		components, err := configMapComponents(cm)
+		if err != nil {
+			return nil, fmt.Errorf("error reading the components of %s: %w", fileName, err)
+		}
+
+		substituted, _, err := simulateSubstitution(components)
+		if err != nil {
+			return nil, fmt.Errorf("error substituting the components of %s: %w", fileName, err)
+		}
+
+		componentObjs, err := utilyaml.ToUnstructured(substituted)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing the components of %s: %w", fileName, err)
+		}
+
+		objs = append(objs, componentObjs...)
+	}
+
+	for _, fileName := range crdFiles {
+		data, err := os.ReadFile(fileName)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", fileName, err)
+		}
+
+		crdObjs, err := utilyaml.ToUnstructured(data)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", fileName, err)
+		}
+
+		objs = append(objs, crdObjs...)
+	}
+
+	seen := map[string]bool{}
+	resources := []string{}
+
+	for _, obj := range objs {
+		if obj.GetKind() != "CustomResourceDefinition" {
+			continue
+		}
+
+		plural, _, _ := unstructured.NestedString(obj.Object, "spec", "names", "plural")
+		group, _, _ := unstructured.NestedString(obj.Object, "spec", "group")
+
+		if resource := plural + "." + group; plural != "" && group != "" && !seen[resource] {
+			seen[resource] = true
+			resources = append(resources, resource)
+		}
+	}
+
+	sort.Strings(resources)
+
+	return resources, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+)
+
+const coreCRDManifest = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: machines.cluster.x-k8s.io
+spec:
+  group: cluster.x-k8s.io
+  names:
+    kind: Machine
+    plural: machines
+  scope: Namespaced
+  versions:
+  - name: v1beta1
+    served: true
+    storage: true
+`
+
+func TestRenderGatherScript(t *testing.T) {
+	dir := t.TempDir()
+
+	previousManifestsPath := *manifestsPath
+	*manifestsPath = dir
+
+	t.Cleanup(func() { *manifestsPath = previousManifestsPath })
+
+	p := provider{Name: "aws", Type: clusterctlv1.InfrastructureProviderType, Version: "v2.6.1", metadata: []byte(providerMetadata)}
+	resourceMap := processObjects(mustParseComponents(t, webhookProviderComponents), p.Name)
+
+	if err := p.writeProviderComponentsConfigmap(manifestPrefix+"04_cm.infrastructure-aws.yaml", resourceMap[otherKey]); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path.Join(dir, manifestPrefix+"04_crd.core-cluster-api.yaml"), []byte(coreCRDManifest), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	script, err := renderGatherScript(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"oc adm inspect --dest-dir \"${BASE_COLLECTION_PATH}\" clusteroperator/cluster-api\n",
+		"--all-namespaces awsmachines.infrastructure.cluster.x-k8s.io,machines.cluster.x-k8s.io\n",
+		"-l cluster-api.openshift.io/managed-by=cluster-capi-operator",
+	} {
+		if !strings.Contains(string(script), expected) {
+			t.Errorf("expected the gather script to contain %q, got:\n%s", expected, script)
+		}
+	}
+}
+
+func TestRenderGatherScriptRequiresCRDs(t *testing.T) {
+	if _, err := renderGatherScript(t.TempDir()); err == nil {
+		t.Error("expected an error when no provider manifests are found")
+	}
+}
@@ -38,6 +38,16 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == validateCommand {
+		validate()
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == gatherCommand {
+		gather()
+		return
+	}
+
 	flag.Parse()
 
 	if err := validateFlags(); err != nil {
@@ -71,3 +81,23 @@ func validateFlags() error {
 
 	return nil
 }
+
+// validate validates the provider manifests previously generated in the manifests path,
+// so that provider repositories can check them in CI before the operator consumes them.
+func validate() {
+	// The flags follow the command, which the flag package would otherwise consider as the first argument.
+	if err := flag.CommandLine.Parse(os.Args[2:]); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if *manifestsPath == "" {
+		fmt.Println("error mandatory flag manifests-path must be specified")
+		os.Exit(1)
+	}
+
+	if err := validateGeneratedManifests(*manifestsPath, *providerName); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
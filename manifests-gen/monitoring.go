@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// providerPrometheusRule builds a PrometheusRule alerting when any of the provider Deployments
+// has had unavailable replicas for a prolonged period of time.
+// It returns nil when the provider ships no Deployments.
+func providerPrometheusRule(p provider, objs []unstructured.Unstructured) *unstructured.Unstructured {
+	deploymentNames := []string{}
+
+	for _, obj := range objs {
+		if obj.GetKind() == "Deployment" {
+			deploymentNames = append(deploymentNames, obj.GetName())
+		}
+	}
+
+	if len(deploymentNames) == 0 {
+		return nil
+	}
+
+	name := fmt.Sprintf("%s-%s", p.providerTypeName(), p.Name)
+	selector := fmt.Sprintf(`namespace="%s",deployment=~"%s"`, targetNamespace, strings.Join(deploymentNames, "|"))
+
+	rule := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "monitoring.coreos.com/v1",
+			"kind":       "PrometheusRule",
+			"metadata": map[string]interface{}{
+				"name":      "cluster-api-" + name,
+				"namespace": targetNamespace,
+			},
+			"spec": map[string]interface{}{
+				"groups": []interface{}{
+					map[string]interface{}{
+						"name": "cluster-api-" + name,
+						"rules": []interface{}{
+							map[string]interface{}{
+								"alert": "CAPIProviderDegraded",
+								"expr":  fmt.Sprintf("kube_deployment_status_replicas_unavailable{%s} > 0", selector),
+								"for":   "15m",
+								"labels": map[string]interface{}{
+									"namespace": targetNamespace,
+									"provider":  p.Name,
+									"severity":  "warning",
+								},
+								"annotations": map[string]interface{}{
+									"summary": fmt.Sprintf("Cluster API %s provider Deployment {{ $labels.deployment }} has unavailable replicas", p.Name),
+									"description": fmt.Sprintf("The {{ $labels.deployment }} Deployment of the %s Cluster API provider has had unavailable replicas "+
+										"for more than 15 minutes. Check the Deployment status and its Pods logs in the %s namespace.", p.Name, targetNamespace),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	setOpenShiftAnnotations(*rule, false)
+	setNoUpgradeAnnotations(*rule)
+
+	return rule
+}
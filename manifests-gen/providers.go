@@ -255,6 +255,14 @@ func importProvider(p provider) error {
 		return fmt.Errorf("error writing provider ConfigMap: %w", err)
 	}
 
+	// Write the provider alerting rules, these are applied directly by CVO.
+	if rule := providerPrometheusRule(p, resourceMap[otherKey]); rule != nil {
+		ruleFileName := fmt.Sprintf("0000_90_cluster-api_03_prometheusrule.%s-%s.yaml", strings.ToLower(p.providerTypeName()), p.Name)
+		if err := p.writeProviderComponentsToManifest(ruleFileName, []unstructured.Unstructured{*rule}); err != nil {
+			return fmt.Errorf("error writing provider PrometheusRule: %w", err)
+		}
+	}
+
 	// Optionally write a separate CRD manifest file,
 	// to apply CRDs directly via CVO rather than through the cluster-capi-operator,
 	// useful in cases where the platform is not supported but some CRDs are needed
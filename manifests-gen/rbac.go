@@ -0,0 +1,283 @@
+package main
+
+import (
+	"slices"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// unrestrictedRBACAnnotation opts a ClusterRole, or a Role, out of the RBAC narrowing when set to "true".
+	// It is meant to be set by a kustomize patch in the provider repository, when a provider
+	// legitimately needs the permissions it ships with.
+	unrestrictedRBACAnnotation = "cluster-api.openshift.io/unrestricted-rbac"
+)
+
+var (
+	// explicitVerbs replace the wildcard verb, deletecollection and escalating verbs such as bind are not granted.
+	explicitVerbs = []string{"create", "delete", "get", "list", "patch", "update", "watch"}
+
+	// readVerbs are the verbs kept cluster wide for namespacedResources, so that the provider caches keep working.
+	readVerbs = []string{"get", "list", "watch"}
+
+	// namespacedResources are the resources, by API group, the providers only ever write in the namespace they run in.
+	namespacedResources = map[string][]string{
+		"":                    {"configmaps", "secrets"},
+		"coordination.k8s.io": {"leases"},
+	}
+)
+
+// narrowRBAC narrows the ClusterRole and Role rules shipped by the provider to the resources and namespaces OpenShift uses:
+//   - wildcard verbs are replaced by explicit verbs,
+//   - wildcard resources in the API groups of the provider CRDs are replaced by the CRD resources,
+//   - write verbs on namespacedResources are moved to a Role in the target namespace, bound to the subjects of the ClusterRole.
+//
+// ClusterRoles and Roles with the unrestrictedRBACAnnotation set to "true" are left untouched.
+func narrowRBAC(objs []unstructured.Unstructured) []unstructured.Unstructured {
+	crdResources := findCRDResources(objs)
+	bindings := findClusterRoleBindings(objs)
+
+	narrowed := []unstructured.Unstructured{}
+
+	for _, obj := range objs {
+		if obj.GetAnnotations()[unrestrictedRBACAnnotation] == "true" {
+			narrowed = append(narrowed, obj)
+			continue
+		}
+
+		switch obj.GetKind() {
+		case "ClusterRole":
+			narrowed = append(narrowed, narrowClusterRole(obj, crdResources, bindings)...)
+		case "Role":
+			narrowed = append(narrowed, narrowRole(obj, crdResources))
+		default:
+			narrowed = append(narrowed, obj)
+		}
+	}
+
+	return narrowed
+}
+
+// narrowClusterRole narrows the rules of the ClusterRole, and returns it along with the Role and RoleBindings,
+// if any, the writes on namespacedResources were moved to.
+func narrowClusterRole(obj unstructured.Unstructured, crdResources map[string][]string, bindings map[string][]rbacv1.ClusterRoleBinding) []unstructured.Unstructured {
+
+	clusterRole := &rbacv1.ClusterRole{}
+	if err := scheme.Convert(&obj, clusterRole, nil); err != nil {
+		panic(err)
+	}
+
+	clusterRoleRules := []rbacv1.PolicyRule{}
+	roleRules := []rbacv1.PolicyRule{}
+
+	for _, rule := range clusterRole.Rules {
+		rule = narrowPolicyRule(rule, crdResources)
+
+		// The writes can only be moved to a Role when there is a binding to move along with them.
+		if len(bindings[clusterRole.Name]) > 0 && isNamespacedResourcesRule(rule) {
+			if read, write := splitReadWriteVerbs(rule); len(write.Verbs) > 0 {
+				roleRules = append(roleRules, write)
+
+				if len(read.Verbs) > 0 {
+					clusterRoleRules = append(clusterRoleRules, read)
+				}
+
+				continue
+			}
+		}
+
+		clusterRoleRules = append(clusterRoleRules, rule)
+	}
+
+	clusterRole.Rules = clusterRoleRules
+
+	if err := scheme.Convert(clusterRole, &obj, nil); err != nil {
+		panic(err)
+	}
+
+	if len(roleRules) == 0 {
+		return []unstructured.Unstructured{obj}
+	}
+
+	return append([]unstructured.Unstructured{obj}, namespacedRoleAndBindings(clusterRole, roleRules, bindings[clusterRole.Name])...)
+}
+
+// narrowRole narrows the rules of the Role, Roles are already restricted to their namespace.
+func narrowRole(obj unstructured.Unstructured, crdResources map[string][]string) unstructured.Unstructured {
+	role := &rbacv1.Role{}
+	if err := scheme.Convert(&obj, role, nil); err != nil {
+		panic(err)
+	}
+
+	for i := range role.Rules {
+		role.Rules[i] = narrowPolicyRule(role.Rules[i], crdResources)
+	}
+
+	if err := scheme.Convert(role, &obj, nil); err != nil {
+		panic(err)
+	}
+
+	return obj
+}
+
+// narrowPolicyRule replaces the wildcard verbs and resources of the rule.
+// Rules on non resource URLs are left untouched.
+func narrowPolicyRule(rule rbacv1.PolicyRule, crdResources map[string][]string) rbacv1.PolicyRule {
+	if len(rule.NonResourceURLs) > 0 {
+		return rule
+	}
+
+	if slices.Contains(rule.Verbs, rbacv1.VerbAll) {
+		rule.Verbs = slices.Clone(explicitVerbs)
+	}
+
+	if slices.Contains(rule.Resources, rbacv1.ResourceAll) && len(rule.APIGroups) > 0 {
+		resources := []string{}
+
+		for _, group := range rule.APIGroups {
+			groupResources, ok := crdResources[group]
+			if !ok {
+				// The resources of groups not defined by the provider are unknown, keep the wildcard.
+				return rule
+			}
+
+			resources = append(resources, groupResources...)
+		}
+
+		rule.Resources = resources
+	}
+
+	return rule
+}
+
+// isNamespacedResourcesRule returns whether the rule only grants access to namespacedResources of a single API group.
+func isNamespacedResourcesRule(rule rbacv1.PolicyRule) bool {
+	if len(rule.APIGroups) != 1 || len(rule.Resources) == 0 {
+		return false
+	}
+
+	namespaced, ok := namespacedResources[rule.APIGroups[0]]
+	if !ok {
+		return false
+	}
+
+	for _, resource := range rule.Resources {
+		if !slices.Contains(namespaced, resource) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// splitReadWriteVerbs splits the rule into a rule with its read verbs and a rule with its other verbs.
+func splitReadWriteVerbs(rule rbacv1.PolicyRule) (rbacv1.PolicyRule, rbacv1.PolicyRule) {
+	read := *rule.DeepCopy()
+	read.Verbs = []string{}
+
+	write := *rule.DeepCopy()
+	write.Verbs = []string{}
+
+	for _, verb := range rule.Verbs {
+		if slices.Contains(readVerbs, verb) {
+			read.Verbs = append(read.Verbs, verb)
+		} else {
+			write.Verbs = append(write.Verbs, verb)
+		}
+	}
+
+	return read, write
+}
+
+// namespacedRoleAndBindings returns a Role in the target namespace with the given rules, named after the ClusterRole,
+// and a RoleBinding to it for each of the ClusterRoleBindings of the ClusterRole.
+func namespacedRoleAndBindings(clusterRole *rbacv1.ClusterRole, rules []rbacv1.PolicyRule, clusterRoleBindings []rbacv1.ClusterRoleBinding) []unstructured.Unstructured {
+	role := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterRole.Name,
+			Namespace: targetNamespace,
+			Labels:    clusterRole.Labels,
+		},
+		Rules: rules,
+	}
+
+	objs := []unstructured.Unstructured{toUnstructured(role)}
+
+	for _, clusterRoleBinding := range clusterRoleBindings {
+		roleBinding := &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterRoleBinding.Name,
+				Namespace: targetNamespace,
+				Labels:    clusterRoleBinding.Labels,
+			},
+			Subjects: clusterRoleBinding.Subjects,
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "Role",
+				Name:     role.Name,
+			},
+		}
+
+		objs = append(objs, toUnstructured(roleBinding))
+	}
+
+	return objs
+}
+
+// toUnstructured converts a typed object built by the tool to an unstructured object.
+func toUnstructured(obj runtime.Object) unstructured.Unstructured {
+	u := unstructured.Unstructured{}
+	if err := scheme.Convert(obj, &u, nil); err != nil {
+		panic(err)
+	}
+
+	return u
+}
+
+// findCRDResources returns the resources, including their status and finalizers subresources,
+// of the CRDs shipped by the provider by API group.
+func findCRDResources(objs []unstructured.Unstructured) map[string][]string {
+	crdResources := map[string][]string{}
+
+	for i := range objs {
+		if objs[i].GetKind() != "CustomResourceDefinition" {
+			continue
+		}
+
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := scheme.Convert(&objs[i], crd, nil); err != nil {
+			panic(err)
+		}
+
+		plural := crd.Spec.Names.Plural
+		crdResources[crd.Spec.Group] = append(crdResources[crd.Spec.Group], plural, plural+"/status", plural+"/finalizers")
+	}
+
+	return crdResources
+}
+
+// findClusterRoleBindings returns the ClusterRoleBindings shipped by the provider by the name of the ClusterRole they bind.
+func findClusterRoleBindings(objs []unstructured.Unstructured) map[string][]rbacv1.ClusterRoleBinding {
+	bindings := map[string][]rbacv1.ClusterRoleBinding{}
+
+	for i := range objs {
+		if objs[i].GetKind() != "ClusterRoleBinding" {
+			continue
+		}
+
+		binding := rbacv1.ClusterRoleBinding{}
+		if err := scheme.Convert(&objs[i], &binding, nil); err != nil {
+			panic(err)
+		}
+
+		if binding.RoleRef.Kind == "ClusterRole" {
+			bindings[binding.RoleRef.Name] = append(bindings[binding.RoleRef.Name], binding)
+		}
+	}
+
+	return bindings
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+)
+
+const infraProviderComponents = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: awsmachines.infrastructure.cluster.x-k8s.io
+spec:
+  group: infrastructure.cluster.x-k8s.io
+  names:
+    kind: AWSMachine
+    plural: awsmachines
+  scope: Namespaced
+  versions:
+  - name: v1beta2
+    served: true
+    storage: true
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: capa-manager-role
+  labels:
+    cluster.x-k8s.io/provider: infrastructure-aws
+rules:
+- apiGroups: ["infrastructure.cluster.x-k8s.io"]
+  resources: ["*"]
+  verbs: ["*"]
+- apiGroups: [""]
+  resources: ["secrets"]
+  verbs: ["*"]
+- apiGroups: [""]
+  resources: ["events"]
+  verbs: ["create", "patch"]
+- apiGroups: ["cluster.x-k8s.io"]
+  resources: ["*"]
+  verbs: ["get", "list", "watch"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: capa-manager-rolebinding
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: capa-manager-role
+subjects:
+- kind: ServiceAccount
+  name: capa-controller-manager
+  namespace: openshift-cluster-api
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: capa-leader-elect-role
+  namespace: openshift-cluster-api
+rules:
+- apiGroups: ["coordination.k8s.io"]
+  resources: ["leases"]
+  verbs: ["*"]
+`
+
+const unrestrictedClusterRole = `
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: capa-unrestricted-role
+  annotations:
+    cluster-api.openshift.io/unrestricted-rbac: "true"
+rules:
+- apiGroups: ["*"]
+  resources: ["*"]
+  verbs: ["*"]
+`
+
+func TestProcessObjectsShipsNoWildcardVerbsForInfraProviders(t *testing.T) {
+	resourceMap := processObjects(mustParseComponents(t, infraProviderComponents), "aws")
+
+	for _, obj := range resourceMap[otherKey] {
+		for _, rule := range policyRules(t, obj) {
+			if slices.Contains(rule.Verbs, rbacv1.VerbAll) {
+				t.Errorf("%s %s grants wildcard verbs on %v", obj.GetKind(), obj.GetName(), rule.Resources)
+			}
+		}
+	}
+}
+
+func TestNarrowRBAC(t *testing.T) {
+	objs := narrowRBAC(mustParseComponents(t, infraProviderComponents))
+
+	clusterRole := findObject(t, objs, "ClusterRole", "capa-manager-role")
+	expectedClusterRoleRules := []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{"infrastructure.cluster.x-k8s.io"},
+			Resources: []string{"awsmachines", "awsmachines/status", "awsmachines/finalizers"},
+			Verbs:     explicitVerbs,
+		},
+		{
+			APIGroups: []string{""},
+			Resources: []string{"secrets"},
+			Verbs:     []string{"get", "list", "watch"},
+		},
+		{
+			APIGroups: []string{""},
+			Resources: []string{"events"},
+			Verbs:     []string{"create", "patch"},
+		},
+		{
+			// The resources of groups not defined by the provider are left as they are.
+			APIGroups: []string{"cluster.x-k8s.io"},
+			Resources: []string{"*"},
+			Verbs:     []string{"get", "list", "watch"},
+		},
+	}
+	assertRules(t, clusterRole, expectedClusterRoleRules)
+
+	role := findObject(t, objs, "Role", "capa-manager-role")
+	if role.GetNamespace() != targetNamespace {
+		t.Errorf("expected Role capa-manager-role in namespace %s, got %q", targetNamespace, role.GetNamespace())
+	}
+
+	if role.GetLabels()["cluster.x-k8s.io/provider"] != "infrastructure-aws" {
+		t.Errorf("expected Role capa-manager-role to have the ClusterRole labels, got %v", role.GetLabels())
+	}
+
+	assertRules(t, role, []rbacv1.PolicyRule{{
+		APIGroups: []string{""},
+		Resources: []string{"secrets"},
+		Verbs:     []string{"create", "delete", "patch", "update"},
+	}})
+
+	roleBinding := &rbacv1.RoleBinding{}
+	if err := scheme.Convert(findObject(t, objs, "RoleBinding", "capa-manager-rolebinding"), roleBinding, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if roleBinding.Namespace != targetNamespace || roleBinding.RoleRef.Kind != "Role" || roleBinding.RoleRef.Name != "capa-manager-role" {
+		t.Errorf("expected RoleBinding capa-manager-rolebinding to bind Role capa-manager-role in namespace %s, got %+v", targetNamespace, roleBinding)
+	}
+
+	if len(roleBinding.Subjects) != 1 || roleBinding.Subjects[0].Name != "capa-controller-manager" {
+		t.Errorf("expected RoleBinding capa-manager-rolebinding to have the ClusterRoleBinding subjects, got %v", roleBinding.Subjects)
+	}
+
+	leaderElectionRole := findObject(t, objs, "Role", "capa-leader-elect-role")
+	assertRules(t, leaderElectionRole, []rbacv1.PolicyRule{{
+		APIGroups: []string{"coordination.k8s.io"},
+		Resources: []string{"leases"},
+		Verbs:     explicitVerbs,
+	}})
+}
+
+func TestNarrowRBACKeepsUnboundClusterRoleWrites(t *testing.T) {
+	objs := narrowRBAC(mustParseComponents(t, `
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: unbound-role
+rules:
+- apiGroups: [""]
+  resources: ["configmaps"]
+  verbs: ["get", "create"]
+`))
+
+	if len(objs) != 1 {
+		t.Fatalf("expected no Role to be created for an unbound ClusterRole, got %d objects", len(objs))
+	}
+
+	assertRules(t, &objs[0], []rbacv1.PolicyRule{{
+		APIGroups: []string{""},
+		Resources: []string{"configmaps"},
+		Verbs:     []string{"get", "create"},
+	}})
+}
+
+func TestNarrowRBACEscapeHatch(t *testing.T) {
+	objs := narrowRBAC(mustParseComponents(t, unrestrictedClusterRole))
+
+	assertRules(t, findObject(t, objs, "ClusterRole", "capa-unrestricted-role"), []rbacv1.PolicyRule{{
+		APIGroups: []string{"*"},
+		Resources: []string{"*"},
+		Verbs:     []string{"*"},
+	}})
+}
+
+func mustParseComponents(t *testing.T, components string) []unstructured.Unstructured {
+	t.Helper()
+
+	objs, err := utilyaml.ToUnstructured([]byte(components))
+	if err != nil {
+		t.Fatalf("failed to parse components: %v", err)
+	}
+
+	return objs
+}
+
+func findObject(t *testing.T, objs []unstructured.Unstructured, kind, name string) *unstructured.Unstructured {
+	t.Helper()
+
+	for i := range objs {
+		if objs[i].GetKind() == kind && objs[i].GetName() == name {
+			return &objs[i]
+		}
+	}
+
+	t.Fatalf("%s %s not found", kind, name)
+
+	return nil
+}
+
+func policyRules(t *testing.T, obj unstructured.Unstructured) []rbacv1.PolicyRule {
+	t.Helper()
+
+	switch obj.GetKind() {
+	case "ClusterRole":
+		clusterRole := &rbacv1.ClusterRole{}
+		if err := scheme.Convert(&obj, clusterRole, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		return clusterRole.Rules
+	case "Role":
+		role := &rbacv1.Role{}
+		if err := scheme.Convert(&obj, role, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		return role.Rules
+	default:
+		return nil
+	}
+}
+
+func assertRules(t *testing.T, obj *unstructured.Unstructured, expected []rbacv1.PolicyRule) {
+	t.Helper()
+
+	rules := policyRules(t, *obj)
+	if len(rules) != len(expected) {
+		t.Fatalf("expected %s %s to have %d rules, got %+v", obj.GetKind(), obj.GetName(), len(expected), rules)
+	}
+
+	for i := range expected {
+		if !slices.Equal(rules[i].APIGroups, expected[i].APIGroups) ||
+			!slices.Equal(rules[i].Resources, expected[i].Resources) ||
+			!slices.Equal(rules[i].Verbs, expected[i].Verbs) {
+			t.Errorf("expected %s %s rule %d to be %+v, got %+v", obj.GetKind(), obj.GetName(), i, expected[i], rules[i])
+		}
+	}
+}
@@ -0,0 +1,484 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	admissionregistration "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// validateCommand switches manifests-gen to validating the manifests it generated in the manifests path,
+	// rather than generating them.
+	validateCommand = "validate"
+
+	// ignitionBootstrapFormatVariable is contained in the names of the variables the operator sets to "true"
+	// when substituting the provider components, to enable the ignition bootstrap format.
+	ignitionBootstrapFormatVariable = "BOOTSTRAP_FORMAT_IGNITION"
+
+	certManagerGroup = "cert-manager.io"
+
+	injectCABundleAnnotation      = "service.beta.openshift.io/inject-cabundle"
+	servingCertSecretAnnotation   = "service.beta.openshift.io/serving-cert-secret-name"
+	providerConfigMapNameLabel    = "provider.cluster.x-k8s.io/name"
+	providerConfigMapTypeLabel    = "provider.cluster.x-k8s.io/type"
+	providerConfigMapVersionLabel = "provider.cluster.x-k8s.io/version"
+)
+
+// clusterScopedKinds are the kinds of the cluster scoped resources shipped in the provider manifests.
+var clusterScopedKinds = []string{
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"CustomResourceDefinition",
+	"MutatingWebhookConfiguration",
+	"Namespace",
+	"ValidatingAdmissionPolicy",
+	"ValidatingAdmissionPolicyBinding",
+	"ValidatingWebhookConfiguration",
+}
+
+// validateGeneratedManifests validates the provider manifests found in the manifests path, and returns an error
+// when any of them breaks the conventions the operator relies on. The manifests are restricted to the ones of the
+// given provider when its name is set.
+func validateGeneratedManifests(manifestsDir, name string) error {
+	configMapFiles, crdFiles, err := providerManifestFiles(manifestsDir, name)
+	if err != nil {
+		return err
+	}
+
+	problemCount := 0
+
+	report := func(fileName string, problems []string) {
+		fmt.Printf("> Validating %s\n", fileName)
+
+		for _, problem := range problems {
+			fmt.Printf("  - %s\n", problem)
+		}
+
+		problemCount += len(problems)
+	}
+
+	for _, fileName := range configMapFiles {
+		data, err := os.ReadFile(fileName)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", fileName, err)
+		}
+
+		report(fileName, validateProviderConfigMap(data))
+	}
+
+	for _, fileName := range crdFiles {
+		data, err := os.ReadFile(fileName)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", fileName, err)
+		}
+
+		objs, err := utilyaml.ToUnstructured(data)
+		if err != nil {
+			report(fileName, []string{fmt.Sprintf("invalid YAML: %v", err)})
+			continue
+		}
+
+		report(fileName, validateObjects(objs))
+	}
+
+	if problemCount > 0 {
+		return fmt.Errorf("error found %d problems in the provider manifests", problemCount)
+	}
+
+	return nil
+}
+
+// providerManifestFiles returns the provider ConfigMap and CRD manifests found in the manifests path, restricted to
+// the ones of the given provider when its name is set. It returns an error when there are none.
+func providerManifestFiles(manifestsDir, name string) ([]string, []string, error) {
+	if name == powerVSProvider {
+		name = ibmCloudProvider
+	}
+
+	suffix := "*.yaml"
+	if name != "" {
+		suffix = "*-" + name + ".yaml"
+	}
+
+	configMapFiles, err := filepath.Glob(path.Join(manifestsDir, manifestPrefix+"04_cm."+suffix))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error listing provider ConfigMap manifests: %w", err)
+	}
+
+	crdFiles, err := filepath.Glob(path.Join(manifestsDir, manifestPrefix+"04_crd."+suffix))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error listing provider CRD manifests: %w", err)
+	}
+
+	if len(configMapFiles) == 0 && len(crdFiles) == 0 {
+		return nil, nil, fmt.Errorf("error no provider manifests found in %q", manifestsDir)
+	}
+
+	return configMapFiles, crdFiles, nil
+}
+
+// validateProviderConfigMap validates the provider ConfigMap and the components it transports,
+// once substituted the way the operator does.
+func validateProviderConfigMap(data []byte) []string {
+	cm := &corev1.ConfigMap{}
+	if err := yaml.UnmarshalStrict(data, cm); err != nil {
+		return []string{fmt.Sprintf("invalid provider ConfigMap: %v", err)}
+	}
+
+	problems := []string{}
+
+	if cm.Namespace != targetNamespace {
+		problems = append(problems, fmt.Sprintf("ConfigMap %s is in namespace %q, expected %q", cm.Name, cm.Namespace, targetNamespace))
+	}
+
+	for _, label := range []string{providerConfigMapNameLabel, providerConfigMapTypeLabel, providerConfigMapVersionLabel} {
+		if cm.Labels[label] == "" {
+			problems = append(problems, fmt.Sprintf("ConfigMap %s is missing the %s label", cm.Name, label))
+		}
+	}
+
+	metadata := &clusterctlv1.Metadata{}
+	if err := yaml.Unmarshal([]byte(cm.Data["metadata"]), metadata); err != nil {
+		problems = append(problems, fmt.Sprintf("ConfigMap %s has invalid provider metadata: %v", cm.Name, err))
+	} else if len(metadata.ReleaseSeries) == 0 {
+		problems = append(problems, fmt.Sprintf("ConfigMap %s provider metadata has no release series", cm.Name))
+	}
+
+	components, err := configMapComponents(cm)
+	if err != nil {
+		return append(problems, fmt.Sprintf("ConfigMap %s: %v", cm.Name, err))
+	}
+
+	substituted, substitutionProblems, err := simulateSubstitution(components)
+	problems = append(problems, substitutionProblems...)
+
+	if err != nil {
+		return append(problems, fmt.Sprintf("failed to substitute the environment variables of the components: %v", err))
+	}
+
+	objs, err := utilyaml.ToUnstructured(substituted)
+	if err != nil {
+		return append(problems, fmt.Sprintf("components are not valid YAML once substituted: %v", err))
+	}
+
+	return append(problems, validateObjects(objs)...)
+}
+
+// configMapComponents returns the components transported by the provider ConfigMap, decompressing them when needed.
+func configMapComponents(cm *corev1.ConfigMap) ([]byte, error) {
+	if compressed, ok := cm.BinaryData["components-zstd"]; ok {
+		decoder, err := zstd.NewReader(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize zstd reader: %w", err)
+		}
+		defer decoder.Close()
+
+		decoded, err := decoder.DecodeAll(compressed, []byte{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress components: %w", err)
+		}
+
+		return decoded, nil
+	}
+
+	components, ok := cm.Data["components"]
+	if !ok {
+		return nil, fmt.Errorf("no components found")
+	}
+
+	return []byte(components), nil
+}
+
+// simulateSubstitution substitutes the environment variables of the components the way the operator does:
+// the ignition bootstrap format variables are set to "true", and the other ones fall back to their default value,
+// as they are not set in the operator environment. Variables without a default value are reported,
+// as they are substituted with an empty string.
+func simulateSubstitution(components []byte) ([]byte, []string, error) {
+	processor := yamlprocessor.NewSimpleProcessor()
+
+	variables, err := processor.GetVariableMap(components)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list variables: %w", err)
+	}
+
+	problems := []string{}
+
+	for name, defaultValue := range variables {
+		if defaultValue == nil && !strings.Contains(name, ignitionBootstrapFormatVariable) {
+			problems = append(problems, fmt.Sprintf("variable %s has no default value and is not set by the operator, it is substituted with an empty string", name))
+		}
+	}
+
+	sort.Strings(problems)
+
+	substituted, err := processor.Process(components, func(name string) (string, error) {
+		if strings.Contains(name, ignitionBootstrapFormatVariable) {
+			return "true", nil
+		}
+
+		return "", nil
+	})
+	if err != nil {
+		return nil, problems, fmt.Errorf("failed to process components: %w", err)
+	}
+
+	return substituted, problems, nil
+}
+
+// validateObjects validates the objects against their schema and the namespace, RBAC and cert-manager conventions
+// applied when generating the manifests.
+func validateObjects(objs []unstructured.Unstructured) []string {
+	problems := []string{}
+	crdResources := findCRDResources(objs)
+	bindings := findClusterRoleBindings(objs)
+
+	for i := range objs {
+		obj := &objs[i]
+		ref := fmt.Sprintf("%s %s", obj.GetKind(), obj.GetName())
+
+		typed, err := validateSchema(obj)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", ref, err))
+			continue
+		}
+
+		for _, problem := range slices.Concat(
+			validateNamespace(obj, typed),
+			validateRBAC(obj, typed, crdResources, bindings),
+			validateCertManager(obj, typed, objs),
+		) {
+			problems = append(problems, fmt.Sprintf("%s: %s", ref, problem))
+		}
+	}
+
+	return problems
+}
+
+// validateSchema converts the object to its typed counterpart, failing on unknown kinds and fields,
+// and checks the fields the API server would require.
+func validateSchema(obj *unstructured.Unstructured) (runtime.Object, error) {
+	if obj.GetName() == "" {
+		return nil, fmt.Errorf("missing name")
+	}
+
+	typed, err := scheme.New(obj.GroupVersionKind())
+	if err != nil {
+		return nil, fmt.Errorf("unknown kind %s: %w", obj.GroupVersionKind(), err)
+	}
+
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructuredWithValidation(obj.Object, typed, true); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", obj.GroupVersionKind(), err)
+	}
+
+	if crd, ok := typed.(*apiextensionsv1.CustomResourceDefinition); ok {
+		if err := validateCRD(crd); err != nil {
+			return nil, err
+		}
+	}
+
+	return typed, nil
+}
+
+// validateCRD checks the CRD has exactly one storage version and a schema for each of its versions,
+// and no conversion webhook, as the conversion webhooks are removed when generating the manifests.
+func validateCRD(crd *apiextensionsv1.CustomResourceDefinition) error {
+	if crd.Spec.Conversion != nil && crd.Spec.Conversion.Strategy == apiextensionsv1.WebhookConverter {
+		return fmt.Errorf("conversion webhook must be removed")
+	}
+
+	storageVersions := 0
+
+	for _, version := range crd.Spec.Versions {
+		if version.Storage {
+			storageVersions++
+		}
+
+		if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+			return fmt.Errorf("version %s has no schema", version.Name)
+		}
+	}
+
+	if storageVersions != 1 {
+		return fmt.Errorf("expected exactly one storage version, got %d", storageVersions)
+	}
+
+	return nil
+}
+
+// validateNamespace checks the namespaced objects, and the services and service accounts they refer to,
+// are in the target namespace. Namespaces are not shipped.
+func validateNamespace(obj *unstructured.Unstructured, typed runtime.Object) []string {
+	problems := []string{}
+
+	switch {
+	case obj.GetKind() == "Namespace":
+		return []string{"namespaces must not be shipped, the components are installed in " + targetNamespace}
+	case slices.Contains(clusterScopedKinds, obj.GetKind()):
+		if obj.GetNamespace() != "" {
+			problems = append(problems, fmt.Sprintf("cluster scoped resource has namespace %q", obj.GetNamespace()))
+		}
+	case obj.GetNamespace() != targetNamespace:
+		problems = append(problems, fmt.Sprintf("namespace is %q, expected %q", obj.GetNamespace(), targetNamespace))
+	}
+
+	for _, service := range webhookServices(typed) {
+		if service.Namespace != targetNamespace {
+			problems = append(problems, fmt.Sprintf("webhook service %s is in namespace %q, expected %q", service.Name, service.Namespace, targetNamespace))
+		}
+	}
+
+	var subjects []rbacv1.Subject
+
+	switch o := typed.(type) {
+	case *rbacv1.ClusterRoleBinding:
+		subjects = o.Subjects
+	case *rbacv1.RoleBinding:
+		subjects = o.Subjects
+	}
+
+	for _, subject := range subjects {
+		if subject.Kind == rbacv1.ServiceAccountKind && subject.Namespace != targetNamespace {
+			problems = append(problems, fmt.Sprintf("service account subject %s is in namespace %q, expected %q", subject.Name, subject.Namespace, targetNamespace))
+		}
+	}
+
+	return problems
+}
+
+// validateRBAC checks the ClusterRoles and Roles were narrowed, unless they opted out with the unrestrictedRBACAnnotation.
+func validateRBAC(obj *unstructured.Unstructured, typed runtime.Object, crdResources map[string][]string, bindings map[string][]rbacv1.ClusterRoleBinding) []string {
+	if obj.GetAnnotations()[unrestrictedRBACAnnotation] == "true" {
+		return nil
+	}
+
+	var rules []rbacv1.PolicyRule
+
+	isBoundClusterRole := false
+
+	switch o := typed.(type) {
+	case *rbacv1.ClusterRole:
+		rules = o.Rules
+		isBoundClusterRole = len(bindings[o.Name]) > 0
+	case *rbacv1.Role:
+		rules = o.Rules
+	default:
+		return nil
+	}
+
+	problems := []string{}
+
+	for _, rule := range rules {
+		if len(rule.NonResourceURLs) > 0 {
+			continue
+		}
+
+		if slices.Contains(rule.Verbs, rbacv1.VerbAll) {
+			problems = append(problems, fmt.Sprintf("rule on %v grants the wildcard verb", rule.Resources))
+		}
+
+		if slices.Contains(rule.Resources, rbacv1.ResourceAll) {
+			for _, group := range rule.APIGroups {
+				if _, ok := crdResources[group]; ok {
+					problems = append(problems, fmt.Sprintf("rule grants all the resources of the %s provider API group", group))
+				}
+			}
+		}
+
+		if isBoundClusterRole && isNamespacedResourcesRule(rule) {
+			if _, write := splitReadWriteVerbs(rule); len(write.Verbs) > 0 {
+				problems = append(problems, fmt.Sprintf("rule grants %v on %v cluster wide, writes must be granted by a Role in %s", write.Verbs, rule.Resources, targetNamespace))
+			}
+		}
+	}
+
+	return problems
+}
+
+// validateCertManager checks the cert-manager resources and annotations were replaced by their OpenShift service CA
+// counterparts: the webhook configurations and CRDs get their CA bundle injected, and the webhook services get their
+// serving certificate.
+func validateCertManager(obj *unstructured.Unstructured, typed runtime.Object, objs []unstructured.Unstructured) []string {
+	if obj.GroupVersionKind().Group == certManagerGroup {
+		return []string{"cert-manager resources must not be shipped, certificates are provided by the OpenShift service CA"}
+	}
+
+	problems := []string{}
+
+	for key := range obj.GetAnnotations() {
+		if strings.HasPrefix(key, certManagerGroup+"/") {
+			problems = append(problems, fmt.Sprintf("cert-manager annotation %s must be replaced by its OpenShift service CA counterpart", key))
+		}
+	}
+
+	services := webhookServices(typed)
+	if len(services) > 0 && obj.GetAnnotations()[injectCABundleAnnotation] != "true" {
+		problems = append(problems, fmt.Sprintf("webhooks are served by a service but the %s annotation is not set", injectCABundleAnnotation))
+	}
+
+	for _, service := range services {
+		if !hasServingCert(objs, service.Name) {
+			problems = append(problems, fmt.Sprintf("webhook service %s is not shipped with the %s annotation", service.Name, servingCertSecretAnnotation))
+		}
+	}
+
+	return problems
+}
+
+// webhookService references the service serving a webhook.
+type webhookService struct {
+	Name      string
+	Namespace string
+}
+
+// webhookServices returns the services serving the webhooks of the given webhook configuration.
+func webhookServices(typed runtime.Object) []webhookService {
+	clientConfigs := []admissionregistration.WebhookClientConfig{}
+
+	switch o := typed.(type) {
+	case *admissionregistration.MutatingWebhookConfiguration:
+		for _, webhook := range o.Webhooks {
+			clientConfigs = append(clientConfigs, webhook.ClientConfig)
+		}
+	case *admissionregistration.ValidatingWebhookConfiguration:
+		for _, webhook := range o.Webhooks {
+			clientConfigs = append(clientConfigs, webhook.ClientConfig)
+		}
+	}
+
+	services := []webhookService{}
+
+	for _, clientConfig := range clientConfigs {
+		if clientConfig.Service != nil {
+			services = append(services, webhookService{Name: clientConfig.Service.Name, Namespace: clientConfig.Service.Namespace})
+		}
+	}
+
+	return services
+}
+
+// hasServingCert returns whether the service with the given name is shipped with a serving certificate.
+func hasServingCert(objs []unstructured.Unstructured, serviceName string) bool {
+	for _, obj := range objs {
+		if obj.GetKind() == "Service" && obj.GetName() == serviceName {
+			return obj.GetAnnotations()[servingCertSecretAnnotation] != ""
+		}
+	}
+
+	return false
+}
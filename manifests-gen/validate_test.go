@@ -0,0 +1,267 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+)
+
+const webhookProviderComponents = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: awsmachines.infrastructure.cluster.x-k8s.io
+  annotations:
+    cert-manager.io/inject-ca-from: openshift-cluster-api/capa-serving-cert
+spec:
+  group: infrastructure.cluster.x-k8s.io
+  names:
+    kind: AWSMachine
+    plural: awsmachines
+  scope: Namespaced
+  conversion:
+    strategy: Webhook
+    webhook:
+      conversionReviewVersions: ["v1"]
+      clientConfig:
+        service:
+          name: capa-webhook-service
+          namespace: openshift-cluster-api
+          path: /convert
+  versions:
+  - name: v1beta2
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+---
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: capa-serving-cert
+  namespace: openshift-cluster-api
+spec:
+  secretName: capa-webhook-service-cert
+  issuerRef:
+    name: capa-selfsigned-issuer
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: capa-webhook-service
+  namespace: openshift-cluster-api
+spec:
+  ports:
+  - port: 443
+    targetPort: 9443
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: capa-validating-webhook-configuration
+  annotations:
+    cert-manager.io/inject-ca-from: openshift-cluster-api/capa-serving-cert
+webhooks:
+- name: validation.awsmachine.infrastructure.cluster.x-k8s.io
+  admissionReviewVersions: ["v1"]
+  sideEffects: None
+  clientConfig:
+    service:
+      name: capa-webhook-service
+      namespace: openshift-cluster-api
+      path: /validate-infrastructure-cluster-x-k8s-io-v1beta2-awsmachine
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: capa-controller-manager
+  namespace: openshift-cluster-api
+spec:
+  selector:
+    matchLabels:
+      control-plane: capa-controller-manager
+  template:
+    metadata:
+      labels:
+        control-plane: capa-controller-manager
+    spec:
+      containers:
+      - name: manager
+        image: registry.ci.openshift.org/openshift:aws-cluster-api-controllers
+        args:
+        - --feature-gates=BootstrapFormatIgnition=${EXP_BOOTSTRAP_FORMAT_IGNITION},EKS=${CAPA_EKS:=false}
+`
+
+const providerMetadata = `
+apiVersion: clusterctl.cluster.x-k8s.io/v1alpha3
+kind: Metadata
+releaseSeries:
+- major: 2
+  minor: 6
+  contract: v1beta1
+`
+
+func TestValidateGeneratedManifestsAcceptsGeneratedManifests(t *testing.T) {
+	dir := t.TempDir()
+
+	previousManifestsPath := *manifestsPath
+	*manifestsPath = dir
+
+	t.Cleanup(func() { *manifestsPath = previousManifestsPath })
+
+	p := provider{Name: "aws", Type: clusterctlv1.InfrastructureProviderType, Version: "v2.6.1", metadata: []byte(providerMetadata)}
+	resourceMap := processObjects(mustParseComponents(t, webhookProviderComponents), p.Name)
+
+	if err := p.writeProviderComponentsConfigmap(manifestPrefix+"04_cm.infrastructure-aws.yaml", resourceMap[otherKey]); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := validateGeneratedManifests(dir, "aws"); err != nil {
+		t.Errorf("expected the generated manifests to be valid, got %v", err)
+	}
+}
+
+func TestValidateGeneratedManifestsRequiresManifests(t *testing.T) {
+	if err := validateGeneratedManifests(t.TempDir(), "aws"); err == nil {
+		t.Error("expected an error when no provider manifests are found")
+	}
+}
+
+func TestValidateProviderConfigMap(t *testing.T) {
+	problems := validateProviderConfigMap([]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: aws
+  namespace: default
+data:
+  components: |
+    apiVersion: v1
+    kind: ServiceAccount
+    metadata:
+      name: capa-controller-manager
+      namespace: ${NAMESPACE}
+`))
+
+	assertProblems(t, problems,
+		`ConfigMap aws is in namespace "default"`,
+		"ConfigMap aws is missing the provider.cluster.x-k8s.io/name label",
+		"ConfigMap aws is missing the provider.cluster.x-k8s.io/type label",
+		"ConfigMap aws is missing the provider.cluster.x-k8s.io/version label",
+		"ConfigMap aws provider metadata has no release series",
+		"variable NAMESPACE has no default value",
+		`ServiceAccount capa-controller-manager: namespace is ""`,
+	)
+}
+
+func TestSimulateSubstitution(t *testing.T) {
+	substituted, problems, err := simulateSubstitution([]byte(
+		"ignition: ${EXP_BOOTSTRAP_FORMAT_IGNITION}\ndefaulted: ${CAPA_EKS:=false}\nmissing: \"${CAPA_REGION}\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "ignition: true\ndefaulted: false\nmissing: \"\"\n"; string(substituted) != expected {
+		t.Errorf("expected the components to be substituted to %q, got %q", expected, substituted)
+	}
+
+	assertProblems(t, problems, "variable CAPA_REGION has no default value")
+}
+
+func TestValidateObjects(t *testing.T) {
+	testCases := []struct {
+		name             string
+		components       string
+		expectedProblems []string
+	}{
+		{
+			name: "with an unknown field",
+			components: `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: capa-controller-manager
+  namespace: openshift-cluster-api
+secret: capa-token
+`,
+			expectedProblems: []string{`ServiceAccount capa-controller-manager: invalid /v1, Kind=ServiceAccount: strict decoding error: unknown field "secret"`},
+		},
+		{
+			name: "with a resource outside of the target namespace",
+			components: `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: capa-controller-manager
+  namespace: capa-system
+`,
+			expectedProblems: []string{`ServiceAccount capa-controller-manager: namespace is "capa-system", expected "openshift-cluster-api"`},
+		},
+		{
+			name: "with a namespace",
+			components: `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: capa-system
+`,
+			expectedProblems: []string{"Namespace capa-system: namespaces must not be shipped"},
+		},
+		{
+			name:       "with RBAC that was not narrowed",
+			components: infraProviderComponents,
+			expectedProblems: []string{
+				"CustomResourceDefinition awsmachines.infrastructure.cluster.x-k8s.io: version v1beta2 has no schema",
+				"ClusterRole capa-manager-role: rule on [*] grants the wildcard verb",
+				"ClusterRole capa-manager-role: rule grants all the resources of the infrastructure.cluster.x-k8s.io provider API group",
+				"ClusterRole capa-manager-role: rule on [secrets] grants the wildcard verb",
+				"ClusterRole capa-manager-role: rule grants [*] on [secrets] cluster wide",
+				"Role capa-leader-elect-role: rule on [leases] grants the wildcard verb",
+			},
+		},
+		{
+			name:       "with unrestricted RBAC",
+			components: unrestrictedClusterRole,
+		},
+		{
+			name:       "with cert-manager resources",
+			components: webhookProviderComponents,
+			expectedProblems: []string{
+				"CustomResourceDefinition awsmachines.infrastructure.cluster.x-k8s.io: conversion webhook must be removed",
+				"Certificate capa-serving-cert: cert-manager resources must not be shipped",
+				"ValidatingWebhookConfiguration capa-validating-webhook-configuration: cert-manager annotation cert-manager.io/inject-ca-from",
+				"ValidatingWebhookConfiguration capa-validating-webhook-configuration: webhooks are served by a service but the service.beta.openshift.io/inject-cabundle annotation is not set",
+				"ValidatingWebhookConfiguration capa-validating-webhook-configuration: webhook service capa-webhook-service is not shipped with the service.beta.openshift.io/serving-cert-secret-name annotation",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			objs, err := utilyaml.ToUnstructured([]byte(tc.components))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assertProblems(t, validateObjects(objs), tc.expectedProblems...)
+		})
+	}
+}
+
+// assertProblems checks each of the problems starts with the corresponding expected prefix.
+func assertProblems(t *testing.T, problems []string, expectedPrefixes ...string) {
+	t.Helper()
+
+	if len(problems) != len(expectedPrefixes) {
+		t.Fatalf("expected %d problems, got %d: %q", len(expectedPrefixes), len(problems), problems)
+	}
+
+	for i := range expectedPrefixes {
+		if !strings.HasPrefix(problems[i], expectedPrefixes[i]) {
+			t.Errorf("expected problem %d to start with %q, got %q", i, expectedPrefixes[i], problems[i])
+		}
+	}
+}
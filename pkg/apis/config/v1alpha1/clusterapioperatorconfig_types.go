@@ -0,0 +1,239 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mapiv1beta1 "github.com/openshift/api/machine/v1beta1"
+)
+
+const (
+	// ClusterAPIOperatorConfigName is the name of the ClusterAPIOperatorConfig singleton, in the namespace of the
+	// operator. ClusterAPIOperatorConfigs with other names are ignored.
+	ClusterAPIOperatorConfigName = "cluster"
+)
+
+// FeatureName names a feature of the operator that can be toggled.
+// +kubebuilder:validation:Enum=MirrorCleanup;Adoption;ConversionReport;MigrationSummary;BlockMachinePools;ProtectManagedResources;ProviderDisruptionBudgets;NamespaceNetworkPolicies
+type FeatureName string
+
+const (
+	// FeatureMirrorCleanup deletes the CAPI machines mirroring MAPI machines that no longer exist.
+	FeatureMirrorCleanup FeatureName = "MirrorCleanup"
+
+	// FeatureAdoption adopts the CAPI machines created outside of the MachineSets into the Machine API.
+	FeatureAdoption FeatureName = "Adoption"
+
+	// FeatureConversionReport aggregates the fields lost by the MachineSet conversions in a ConfigMap.
+	FeatureConversionReport FeatureName = "ConversionReport"
+
+	// FeatureMigrationSummary summarizes the migration of the MachineSets and Machines in a ConfigMap.
+	FeatureMigrationSummary FeatureName = "MigrationSummary"
+
+	// FeatureBlockMachinePools denies the creation of CAPI MachinePools, which OpenShift does not support yet, in the
+	// managed namespace.
+	FeatureBlockMachinePools FeatureName = "BlockMachinePools"
+
+	// FeatureProtectManagedResources denies the manual changes of the provider Deployments, the Cluster and the
+	// InfraCluster managed by the operator in the managed namespace.
+	FeatureProtectManagedResources FeatureName = "ProtectManagedResources"
+
+	// FeatureProviderDisruptionBudgets installs the PodDisruptionBudgets shipped with the provider Deployments, which
+	// are removed when disabled.
+	FeatureProviderDisruptionBudgets FeatureName = "ProviderDisruptionBudgets"
+
+	// FeatureNamespaceNetworkPolicies applies the baseline NetworkPolicies of the pods of the managed namespace, which
+	// are removed when disabled.
+	FeatureNamespaceNetworkPolicies FeatureName = "NamespaceNetworkPolicies"
+)
+
+// ClusterAPIOperatorConfigSpec configures the managers of the operator. The unset fields keep the values passed on
+// the command line of the managers.
+type ClusterAPIOperatorConfigSpec struct {
+	// logVerbosity is the verbosity of the logs of the managers, as their -v flag. It is applied without restarting
+	// the managers, when unset the verbosity passed on their command line is restored.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	// +optional
+	LogVerbosity *int32 `json:"logVerbosity,omitempty"`
+
+	// sync configures the Machine and MachineSet sync controllers.
+	// +optional
+	Sync SyncConfig `json:"sync,omitempty"`
+
+	// providerOverrides replace the images of the Cluster API providers installed by the operator.
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=32
+	// +optional
+	ProviderOverrides []ProviderOverride `json:"providerOverrides,omitempty"`
+
+	// features toggles the optional features of the operator, which are all enabled by default.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Features []FeatureToggle `json:"features,omitempty"`
+
+	// migration configures how the Machine API resources are migrated to Cluster API.
+	// +optional
+	Migration MigrationPolicy `json:"migration,omitempty"`
+
+	// providerDeployments configures the Deployments of the Cluster API providers installed by the operator.
+	// +optional
+	ProviderDeployments ProviderDeploymentsConfig `json:"providerDeployments,omitempty"`
+}
+
+// ProviderDeploymentsConfig configures the Deployments of the Cluster API providers installed by the operator.
+type ProviderDeploymentsConfig struct {
+	// priorityClassName is the priority class of the pods of the provider Deployments, replacing the
+	// system-cluster-critical priority class they are shipped with.
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+}
+
+// SyncConfig configures the Machine and MachineSet sync controllers.
+type SyncConfig struct {
+	// machineConcurrency is the maximum number of Machines the machine sync controller reconciles concurrently.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MachineConcurrency *int32 `json:"machineConcurrency,omitempty"`
+
+	// machineSetConcurrency is the maximum number of MachineSets the machineset sync controller reconciles
+	// concurrently.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MachineSetConcurrency *int32 `json:"machineSetConcurrency,omitempty"`
+
+	// namespacePairs are additional pairs of namespaces whose Machines and MachineSets are synchronized, alongside
+	// the pair passed on the command line, such as the machine namespaces of a management cluster.
+	// A namespace may only be part of a single pair.
+	// +listType=map
+	// +listMapKey=mapiNamespace
+	// +kubebuilder:validation:MaxItems=32
+	// +kubebuilder:validation:XValidation:rule="self.all(p, self.exists_one(q, q.capiNamespace == p.capiNamespace))",message="a CAPI namespace may only be part of a single pair"
+	// +optional
+	NamespacePairs []NamespacePair `json:"namespacePairs,omitempty"`
+}
+
+// NamespacePair is a Machine API namespace and the Cluster API namespace its resources are synchronized with.
+type NamespacePair struct {
+	// mapiNamespace is the namespace of the Machine API resources.
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	MAPINamespace string `json:"mapiNamespace"`
+
+	// capiNamespace is the namespace of the Cluster API resources.
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	CAPINamespace string `json:"capiNamespace"`
+}
+
+// ProviderOverride replaces the image of a Cluster API provider.
+type ProviderOverride struct {
+	// name is the name of the image in the images ConfigMap of the operator, e.g. aws-cluster-api-controllers.
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Name string `json:"name"`
+
+	// image is the pull spec of the image replacing the one of the release payload.
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Image string `json:"image"`
+}
+
+// FeatureToggle enables or disables a feature of the operator.
+type FeatureToggle struct {
+	// name is the name of the feature.
+	// +required
+	Name FeatureName `json:"name"`
+
+	// enabled is whether the feature is enabled.
+	// +required
+	Enabled bool `json:"enabled"`
+}
+
+// MigrationPolicy configures how the Machine API resources are migrated to Cluster API.
+type MigrationPolicy struct {
+	// syncRetryBudget is the number of consecutive retries after which a resource that fails to synchronize is
+	// reported as Degraded and is no longer retried until it changes. Retries are unlimited when 0.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	SyncRetryBudget *int32 `json:"syncRetryBudget,omitempty"`
+
+	// machineSetDriftThreshold is how long the MAPI and CAPI copies of a MachineSet may differ before the MachineSet
+	// is reported as Drifted.
+	// +optional
+	MachineSetDriftThreshold *metav1.Duration `json:"machineSetDriftThreshold,omitempty"`
+
+	// orphanedMirrorGracePeriod is how long a paused CAPI machine mirroring a MAPI machine that no longer exists is
+	// kept before it is deleted.
+	// +optional
+	OrphanedMirrorGracePeriod *metav1.Duration `json:"orphanedMirrorGracePeriod,omitempty"`
+
+	// drainSettleTimeout is how long the completion of the migration of a Machine waits for the drain of its Node to
+	// settle, after which the migration proceeds regardless.
+	// +optional
+	DrainSettleTimeout *metav1.Duration `json:"drainSettleTimeout,omitempty"`
+
+	// defaultAuthoritativeAPI is the authoritative API of the Machine API Machines and MachineSets created from then
+	// on. ClusterAPI is only applied while the MachineAPIMigration feature gate is enabled. Machines controlled by a
+	// MachineSet follow its template instead.
+	// +kubebuilder:validation:Enum=MachineAPI;ClusterAPI
+	// +optional
+	DefaultAuthoritativeAPI mapiv1beta1.MachineAuthority `json:"defaultAuthoritativeAPI,omitempty"`
+}
+
+// ClusterAPIOperatorConfig configures the managers of the Cluster CAPI Operator, so that they can be tuned without
+// editing their Deployment. Only the ClusterAPIOperatorConfig named cluster, in the namespace of the operator, is
+// read.
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=clusterapioperatorconfigs,scope=Namespaced
+// +kubebuilder:validation:XValidation:rule="self.metadata.name == 'cluster'",message="the ClusterAPIOperatorConfig must be named cluster"
+type ClusterAPIOperatorConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// spec configures the managers.
+	// +optional
+	Spec ClusterAPIOperatorConfigSpec `json:"spec,omitempty"`
+}
+
+// ClusterAPIOperatorConfigList contains a list of ClusterAPIOperatorConfig.
+// +kubebuilder:object:root=true
+type ClusterAPIOperatorConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ClusterAPIOperatorConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterAPIOperatorConfig{}, &ClusterAPIOperatorConfigList{})
+}
+
+// FeatureEnabled returns whether the feature is enabled by the configuration, features are enabled by default.
+func (s ClusterAPIOperatorConfigSpec) FeatureEnabled(name FeatureName) bool {
+	for _, feature := range s.Features {
+		if feature.Name == name {
+			return feature.Enabled
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package commoncmdoptions
+
+import (
+	"errors"
+	"fmt"
+
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	// defaultQPS and defaultBurst are the controller-runtime defaults.
+	defaultQPS   = 20
+	defaultBurst = 30
+)
+
+var errInvalidRateLimit = errors.New("invalid API client rate limit")
+
+// ClientOptions configure the API clients of a manager. Each group of controllers gets its own copy of the
+// rest.Config, and so its own rate limiter, so that the bursts of a group, such as the installer applying the
+// provider manifests, do not throttle the other controllers.
+type ClientOptions struct {
+	// UserAgent is the user agent of the manager. The requests of a group are sent as <UserAgent>/<group>, so that
+	// the groups can be told apart in the audit logs when tuning the API priority and fairness of the operator.
+	UserAgent string
+
+	// QPS is the sustained rate of the requests of each group to the API server.
+	QPS float32
+
+	// Burst is the number of requests each group may send at once, above the QPS.
+	Burst int
+}
+
+// AddFlags adds the API client rate limit flags to the flag set.
+func (o *ClientOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.Float32Var(&o.QPS, "kube-api-qps", defaultQPS,
+		"Maximum queries per second of each group of controllers to the API server.")
+
+	fs.IntVar(&o.Burst, "kube-api-burst", defaultBurst,
+		"Maximum burst of queries of each group of controllers to the API server.")
+}
+
+// Validate returns an error when the rate limits are not positive.
+func (o ClientOptions) Validate() error {
+	if o.QPS <= 0 {
+		return fmt.Errorf("%w: --kube-api-qps must be positive, got %v", errInvalidRateLimit, o.QPS)
+	}
+
+	if o.Burst <= 0 {
+		return fmt.Errorf("%w: --kube-api-burst must be positive, got %d", errInvalidRateLimit, o.Burst)
+	}
+
+	return nil
+}
+
+// RestConfig returns a copy of the config for the group of controllers, with its own rate limiter and user agent.
+// The manager config is the one of the empty group.
+func (o ClientOptions) RestConfig(cfg *rest.Config, group string) *rest.Config {
+	groupCfg := rest.CopyConfig(cfg)
+
+	// A rate limiter set on the config would be shared by the copies.
+	groupCfg.RateLimiter = nil
+	groupCfg.QPS = o.QPS
+	groupCfg.Burst = o.Burst
+	groupCfg.UserAgent = o.userAgent(group)
+
+	return groupCfg
+}
+
+// NewClient returns a client of the group of controllers. It reads from the cache of the manager, but writes, and
+// reads the objects the cache is disabled for, through its own rate limiter.
+func (o ClientOptions) NewClient(mgr manager.Manager, group string, options client.Options) (client.Client, error) {
+	options.Scheme = mgr.GetScheme()
+	options.Mapper = mgr.GetRESTMapper()
+
+	cacheOptions := client.CacheOptions{}
+	if options.Cache != nil {
+		cacheOptions = *options.Cache
+	}
+
+	cacheOptions.Reader = mgr.GetCache()
+	options.Cache = &cacheOptions
+
+	cl, err := client.New(o.RestConfig(mgr.GetConfig(), group), options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the API client of the %s controllers: %w", group, err)
+	}
+
+	return cl, nil
+}
+
+func (o ClientOptions) userAgent(group string) string {
+	if group == "" {
+		return o.UserAgent
+	}
+
+	return o.UserAgent + "/" + group
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package commoncmdoptions
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+var _ = Describe("Client options", func() {
+	parse := func(args ...string) ClientOptions {
+		opts := ClientOptions{UserAgent: "cluster-capi-operator"}
+
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		opts.AddFlags(fs)
+		Expect(fs.Parse(args)).To(Succeed())
+
+		return opts
+	}
+
+	It("should default to the controller-runtime rate limits", func() {
+		opts := parse()
+
+		Expect(opts.Validate()).To(Succeed())
+		Expect(opts.QPS).To(BeEquivalentTo(20))
+		Expect(opts.Burst).To(Equal(30))
+	})
+
+	It("should reject the rate limits which are not positive", func() {
+		Expect(parse("--kube-api-qps=0").Validate()).To(MatchError(errInvalidRateLimit))
+		Expect(parse("--kube-api-burst=-1").Validate()).To(MatchError(ContainSubstring("--kube-api-burst")))
+	})
+
+	It("should copy the config of each group with its own rate limits and user agent", func() {
+		opts := parse("--kube-api-qps=50", "--kube-api-burst=100")
+
+		cfg := &rest.Config{
+			Host:        "https://api.example.com:6443",
+			UserAgent:   "default",
+			RateLimiter: flowcontrol.NewTokenBucketRateLimiter(1, 1),
+		}
+
+		installerCfg := opts.RestConfig(cfg, "capi-installer")
+		Expect(installerCfg.Host).To(Equal(cfg.Host))
+		Expect(installerCfg.QPS).To(BeEquivalentTo(50))
+		Expect(installerCfg.Burst).To(Equal(100))
+		Expect(installerCfg.RateLimiter).To(BeNil(), "the groups should not share the rate limiter of the config")
+		Expect(installerCfg.UserAgent).To(Equal("cluster-capi-operator/capi-installer"))
+
+		Expect(opts.RestConfig(cfg, "").UserAgent).To(Equal("cluster-capi-operator"))
+
+		Expect(cfg.UserAgent).To(Equal("default"), "the config should not be modified")
+		Expect(cfg.RateLimiter).NotTo(BeNil())
+	})
+})
@@ -0,0 +1,80 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Package commoncmdoptions holds the command line options shared by the manager binaries.
+package commoncmdoptions
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+var errUnknownController = errors.New("unknown controller")
+
+// ControllerOptions select the controllers a manager runs, so that a controller can be debugged in isolation.
+// All the controllers run by default.
+type ControllerOptions struct {
+	// Enable lists the only controllers to run. All the controllers run when empty.
+	Enable []string
+
+	// Disable lists the controllers not to run, it takes precedence over Enable.
+	Disable []string
+}
+
+// AddFlags adds the controller selection flags to the flag set.
+func (o *ControllerOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringSliceVar(&o.Enable, "enable-controllers", nil,
+		"Comma-separated list of the only controllers to run, e.g. ClusterOperator. All the controllers run when empty. Intended for debugging.")
+
+	fs.StringSliceVar(&o.Disable, "disable-controllers", nil,
+		"Comma-separated list of the controllers not to run. Takes precedence over --enable-controllers. Intended for debugging.")
+}
+
+// Validate returns an error when the options name a controller that is not in the known controllers of the manager.
+func (o ControllerOptions) Validate(known []string) error {
+	for _, name := range slices.Concat(o.Enable, o.Disable) {
+		if !slices.Contains(known, name) {
+			return fmt.Errorf("%w %q, expected one of %s", errUnknownController, name, strings.Join(known, ", "))
+		}
+	}
+
+	return nil
+}
+
+// Enabled returns true if the named controller should run.
+func (o ControllerOptions) Enabled(name string) bool {
+	if slices.Contains(o.Disable, name) {
+		return false
+	}
+
+	return len(o.Enable) == 0 || slices.Contains(o.Enable, name)
+}
+
+// Disabled returns the known controllers that should not run, e.g. to log them at startup.
+func (o ControllerOptions) Disabled(known []string) []string {
+	disabled := []string{}
+
+	for _, name := range known {
+		if !o.Enabled(name) {
+			disabled = append(disabled, name)
+		}
+	}
+
+	return disabled
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package commoncmdoptions
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
+)
+
+var _ = Describe("Controller options", func() {
+	known := []string{"ClusterOperator", "CAPIInstaller", "InfraCluster"}
+
+	parse := func(args ...string) ControllerOptions {
+		opts := ControllerOptions{}
+
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		opts.AddFlags(fs)
+		Expect(fs.Parse(args)).To(Succeed())
+
+		return opts
+	}
+
+	It("should enable all the controllers by default", func() {
+		opts := parse()
+
+		Expect(opts.Validate(known)).To(Succeed())
+		Expect(opts.Disabled(known)).To(BeEmpty())
+	})
+
+	It("should only enable the listed controllers", func() {
+		opts := parse("--enable-controllers=ClusterOperator,InfraCluster")
+
+		Expect(opts.Validate(known)).To(Succeed())
+		Expect(opts.Enabled("ClusterOperator")).To(BeTrue())
+		Expect(opts.Disabled(known)).To(ConsistOf("CAPIInstaller"))
+	})
+
+	It("should disable the listed controllers", func() {
+		opts := parse("--disable-controllers=CAPIInstaller")
+
+		Expect(opts.Validate(known)).To(Succeed())
+		Expect(opts.Disabled(known)).To(ConsistOf("CAPIInstaller"))
+	})
+
+	It("should disable the controllers both enabled and disabled", func() {
+		opts := parse("--enable-controllers=ClusterOperator,CAPIInstaller", "--disable-controllers=CAPIInstaller")
+
+		Expect(opts.Disabled(known)).To(ConsistOf("CAPIInstaller", "InfraCluster"))
+	})
+
+	It("should reject unknown controllers", func() {
+		err := parse("--enable-controllers=ClusterOperator,MachineSync").Validate(known)
+		Expect(err).To(MatchError(errUnknownController))
+		Expect(err).To(MatchError(ContainSubstring(`"MachineSync"`)))
+		Expect(parse("--disable-controllers=clusteroperator").Validate(known)).To(MatchError(errUnknownController))
+	})
+})
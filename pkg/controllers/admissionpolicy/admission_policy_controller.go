@@ -0,0 +1,235 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package admissionpolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	configv1alpha1 "github.com/openshift/cluster-capi-operator/pkg/apis/config/v1alpha1"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+)
+
+const controllerName = "AdmissionPolicyController"
+
+// AdmissionPolicyReconciler owns the ValidatingAdmissionPolicies, and their bindings, protecting the resources
+// synchronized between the Machine API and Cluster API. The policies are templated with the MAPI and CAPI namespaces
+// of all the namespace pairs, each pair has its own bindings, and they are restored when they are modified or deleted.
+// It only runs while the MachineAPIMigration feature gate is enabled, RemovePolicies removes them otherwise.
+type AdmissionPolicyReconciler struct {
+	client.Client
+
+	// NamespacePairs are the synchronized MAPI and CAPI namespaces, the first pair being the one of the operator.
+	NamespacePairs []configv1alpha1.NamespacePair
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *AdmissionPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// All the policies are reconciled at once, under the CAPI namespace of the operator.
+	operatorNamespace := r.NamespacePairs[0].CAPINamespace
+	toCAPINamespace := handler.EnqueueRequestsFromMapFunc(func(context.Context, client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: client.ObjectKey{Name: operatorNamespace}}}
+	})
+
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named(controllerName).
+		// The CAPI namespace is watched so that the policies are created as soon as the controller starts.
+		For(&corev1.Namespace{}, builder.WithPredicates(namePredicate(operatorNamespace))).
+		Watches(&admissionregistrationv1beta1.ValidatingAdmissionPolicy{}, toCAPINamespace, builder.WithPredicates(namePredicate(PolicyNames...))).
+		Watches(&admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding{}, toCAPINamespace, builder.WithPredicates(bindingPredicate(PolicyNames...))).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create controller: %w", err)
+	}
+
+	r.Client = mgr.GetClient()
+
+	return nil
+}
+
+// Reconcile creates the policies and their bindings, or restores them when they drifted. The bindings of the namespace
+// pairs which are no longer synchronized are removed.
+func (r *AdmissionPolicyReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (ctrl.Result, error) {
+	logger := ctrl.LoggerFrom(ctx).WithName(controllerName)
+	ctx = ctrl.LoggerInto(ctx, logger)
+
+	for _, policy := range desiredPolicies(r.NamespacePairs) {
+		if err := ensurePolicy(ctx, r.Client, policy); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	desiredBindingNames := []string{}
+
+	for _, binding := range desiredMigrationBindings(r.NamespacePairs) {
+		if err := ensureBinding(ctx, r.Client, binding); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		desiredBindingNames = append(desiredBindingNames, binding.GetName())
+	}
+
+	if err := removeBindings(ctx, r.Client, PolicyNames, desiredBindingNames); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// ensurePolicy creates the policy, or restores its spec and labels when they differ.
+func ensurePolicy(ctx context.Context, cl client.Client, desired *admissionregistrationv1beta1.ValidatingAdmissionPolicy) error {
+	logger := ctrl.LoggerFrom(ctx)
+
+	existing := &admissionregistrationv1beta1.ValidatingAdmissionPolicy{}
+	if err := cl.Get(ctx, client.ObjectKeyFromObject(desired), existing); apierrors.IsNotFound(err) {
+		if err := cl.Create(ctx, desired); err != nil {
+			return fmt.Errorf("failed to create ValidatingAdmissionPolicy %s: %w", desired.GetName(), err)
+		}
+
+		logger.Info("Created ValidatingAdmissionPolicy", "name", desired.GetName())
+
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get ValidatingAdmissionPolicy %s: %w", desired.GetName(), err)
+	}
+
+	if equality.Semantic.DeepEqual(existing.Spec, desired.Spec) && util.HasManagedByLabel(existing) {
+		return nil
+	}
+
+	existing.Spec = desired.Spec
+	util.SetManagedByLabel(existing)
+	if err := cl.Update(ctx, existing); err != nil {
+		return fmt.Errorf("failed to update ValidatingAdmissionPolicy %s: %w", desired.GetName(), err)
+	}
+
+	logger.Info("Restored drifted ValidatingAdmissionPolicy", "name", desired.GetName())
+
+	return nil
+}
+
+// ensureBinding creates the binding, or restores its spec and labels when they differ.
+func ensureBinding(ctx context.Context, cl client.Client, desired *admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding) error {
+	logger := ctrl.LoggerFrom(ctx)
+
+	existing := &admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding{}
+	if err := cl.Get(ctx, client.ObjectKeyFromObject(desired), existing); apierrors.IsNotFound(err) {
+		if err := cl.Create(ctx, desired); err != nil {
+			return fmt.Errorf("failed to create ValidatingAdmissionPolicyBinding %s: %w", desired.GetName(), err)
+		}
+
+		logger.Info("Created ValidatingAdmissionPolicyBinding", "name", desired.GetName())
+
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get ValidatingAdmissionPolicyBinding %s: %w", desired.GetName(), err)
+	}
+
+	if equality.Semantic.DeepEqual(existing.Spec, desired.Spec) && util.HasManagedByLabel(existing) {
+		return nil
+	}
+
+	existing.Spec = desired.Spec
+	util.SetManagedByLabel(existing)
+	if err := cl.Update(ctx, existing); err != nil {
+		return fmt.Errorf("failed to update ValidatingAdmissionPolicyBinding %s: %w", desired.GetName(), err)
+	}
+
+	logger.Info("Restored drifted ValidatingAdmissionPolicyBinding", "name", desired.GetName())
+
+	return nil
+}
+
+// RemovePolicies deletes the policies and their bindings, so that the migration is not enforced once the
+// MachineAPIMigration feature gate is disabled. The bindings are deleted first, so that no binding is left
+// referencing a missing policy.
+func RemovePolicies(ctx context.Context, cl client.Client) error {
+	return removePolicies(ctx, cl, PolicyNames...)
+}
+
+// removePolicies deletes the named policies and their bindings, the bindings first.
+func removePolicies(ctx context.Context, cl client.Client, names ...string) error {
+	if err := removeBindings(ctx, cl, names, nil); err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for _, name := range names {
+		policy := &admissionregistrationv1beta1.ValidatingAdmissionPolicy{}
+		policy.SetName(name)
+
+		if err := cl.Delete(ctx, policy); err != nil && !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+			errs = append(errs, fmt.Errorf("failed to delete ValidatingAdmissionPolicy %s: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// removeBindings deletes the bindings of the named policies, except for the kept ones.
+func removeBindings(ctx context.Context, cl client.Client, policyNames, keep []string) error {
+	bindings := &admissionregistrationv1beta1.ValidatingAdmissionPolicyBindingList{}
+	if err := cl.List(ctx, bindings); meta.IsNoMatchError(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to list ValidatingAdmissionPolicyBindings: %w", err)
+	}
+
+	var errs []error
+
+	for i := range bindings.Items {
+		binding := &bindings.Items[i]
+		if !slices.Contains(policyNames, binding.Spec.PolicyName) || slices.Contains(keep, binding.GetName()) {
+			continue
+		}
+
+		if err := cl.Delete(ctx, binding); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete ValidatingAdmissionPolicyBinding %s: %w", binding.GetName(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// bindingPredicate filters the events of the bindings of the named policies.
+func bindingPredicate(policyNames ...string) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		binding, ok := obj.(*admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding)
+
+		return ok && slices.Contains(policyNames, binding.Spec.PolicyName)
+	})
+}
+
+// namePredicate filters the events of the objects with one of the given names.
+func namePredicate(names ...string) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return slices.Contains(names, obj.GetName())
+	})
+}
@@ -0,0 +1,574 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package admissionpolicy
+
+import (
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	capiv1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/core/v1beta1"
+	capav1builder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/infrastructure/v1beta2"
+	corev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/core/v1"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+	capav1 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest/komega"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openshift/cluster-api-actuator-pkg/testutils"
+	configv1alpha1 "github.com/openshift/cluster-capi-operator/pkg/apis/config/v1alpha1"
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+)
+
+// namespacePairs are the namespace pairs the policies are templated for in the unit tests.
+var namespacePairs = []configv1alpha1.NamespacePair{{MAPINamespace: "mapi-namespace", CAPINamespace: "capi-namespace"}} //nolint:gochecknoglobals
+
+var _ = Describe("desiredPolicies", func() {
+	It("should template the namespaces of the policies", func() {
+		policies := desiredPolicies(namespacePairs)
+
+		Expect(policies).To(HaveLen(len(PolicyNames)))
+		Expect(policies[0].Spec.MatchConstraints.NamespaceSelector.MatchLabels).To(HaveKeyWithValue(corev1.LabelMetadataName, "mapi-namespace"))
+		Expect(policies[1].Spec.MatchConstraints.NamespaceSelector.MatchLabels).To(HaveKeyWithValue(corev1.LabelMetadataName, "capi-namespace"))
+		Expect(policies[1].Spec.MatchConditions[0].Expression).To(ContainSubstring("system:serviceaccount:capi-namespace:cluster-capi-operator"))
+	})
+
+	It("should match the CAPI MachineSets and the InfraMachineTemplates of the supported platforms", func() {
+		policy := desiredCAPIMachineSetAuthorityPolicy(namespacePairs)
+
+		Expect(policy.Spec.MatchConstraints.NamespaceSelector.MatchLabels).To(HaveKeyWithValue(corev1.LabelMetadataName, "capi-namespace"))
+		Expect(policy.Spec.MatchConstraints.ResourceRules).To(ConsistOf(
+			HaveField("Rule.Resources", ConsistOf("machinesets")),
+			HaveField("Rule.Resources", ConsistOf("awsmachinetemplates", "ibmpowervsmachinetemplates", "openstackmachinetemplates")),
+		))
+	})
+
+	It("should not protect the CAPI MachineSets owned by a MachineDeployment", func() {
+		policies := desiredPolicies(namespacePairs)
+
+		Expect(policies[1].Spec.MatchConditions).To(ContainElement(notOwnedByMachineDeploymentCondition))
+		Expect(desiredCAPIMachineSetAuthorityPolicy(namespacePairs).Spec.MatchConditions).To(ContainElement(notOwnedByMachineDeploymentCondition))
+	})
+
+	It("should match the namespaces of all the pairs, and exempt the operator of the first one", func() {
+		pairs := append(namespacePairs, configv1alpha1.NamespacePair{MAPINamespace: "mapi-namespace-2", CAPINamespace: "capi-namespace-2"})
+		policies := desiredPolicies(pairs)
+
+		Expect(policies[0].Spec.MatchConstraints.NamespaceSelector.MatchExpressions).To(ConsistOf(
+			HaveField("Values", ConsistOf("mapi-namespace", "mapi-namespace-2")),
+		))
+		Expect(policies[1].Spec.MatchConstraints.NamespaceSelector.MatchExpressions).To(ConsistOf(
+			HaveField("Values", ConsistOf("capi-namespace", "capi-namespace-2")),
+		))
+		Expect(policies[1].Spec.MatchConditions[0].Expression).To(ContainSubstring("system:serviceaccount:capi-namespace:cluster-capi-operator"))
+	})
+})
+
+var _ = Describe("Machine deletion policies", func() {
+	It("should only deny the deletions requested by the users, not by the controllers", func() {
+		for _, policy := range []*admissionregistrationv1beta1.ValidatingAdmissionPolicy{
+			desiredMAPIMachineDeletionPolicy(namespacePairs),
+			desiredCAPIMachineDeletionPolicy(namespacePairs),
+		} {
+			Expect(policy.Spec.MatchConditions).To(ConsistOf(SatisfyAll(
+				HaveField("Expression", ContainSubstring("'system:serviceaccount:mapi-namespace:'")),
+				HaveField("Expression", ContainSubstring("'system:serviceaccount:capi-namespace:'")),
+			)))
+		}
+	})
+})
+
+var _ = Describe("desiredMigrationBindings", func() {
+	It("should evaluate the CAPI MachineSet policies against the MachineSets of the namespaces", func() {
+		bindings := desiredMigrationBindings(namespacePairs)
+
+		Expect(bindings).To(HaveLen(len(PolicyNames)))
+		Expect(bindings).To(ContainElements(
+			SatisfyAll(
+				HaveField("Spec.PolicyName", CAPIMachineSetAuthorityPolicyName),
+				HaveField("Spec.ParamRef.Namespace", "mapi-namespace"),
+				HaveField("Spec.ValidationActions", ConsistOf(admissionregistrationv1beta1.Deny)),
+			),
+			SatisfyAll(
+				HaveField("Spec.PolicyName", CAPIMachineSetSyncWarningPolicyName),
+				HaveField("Spec.ParamRef.Namespace", "mapi-namespace"),
+				HaveField("Spec.ValidationActions", ConsistOf(admissionregistrationv1beta1.Warn)),
+			),
+			SatisfyAll(
+				HaveField("Spec.PolicyName", InfraMachineTemplateDeletionPolicyName),
+				HaveField("Spec.ParamRef.Namespace", "capi-namespace"),
+				HaveField("Spec.ValidationActions", ConsistOf(admissionregistrationv1beta1.Deny)),
+			),
+			SatisfyAll(
+				HaveField("Spec.PolicyName", CAPIMachineDeletionPolicyName),
+				HaveField("Spec.ParamRef.Namespace", "mapi-namespace"),
+				HaveField("Spec.ValidationActions", ConsistOf(admissionregistrationv1beta1.Deny)),
+			),
+		))
+	})
+
+	It("should bind the policies for each namespace pair, restricted to its namespaces", func() {
+		pairs := append(namespacePairs, configv1alpha1.NamespacePair{MAPINamespace: "mapi-namespace-2", CAPINamespace: "capi-namespace-2"})
+		bindings := desiredMigrationBindings(pairs)
+
+		Expect(bindings).To(HaveLen(2 * len(PolicyNames)))
+		Expect(bindings).To(ContainElements(
+			SatisfyAll(
+				HaveField("ObjectMeta.Name", CAPIMachineSetAuthorityPolicyName),
+				HaveField("Spec.ParamRef.Namespace", "mapi-namespace"),
+				HaveField("Spec.MatchResources.NamespaceSelector.MatchExpressions", ConsistOf(
+					HaveField("Values", ConsistOf("mapi-namespace", "capi-namespace")),
+				)),
+			),
+			SatisfyAll(
+				HaveField("ObjectMeta.Name", CAPIMachineSetAuthorityPolicyName+"-mapi-namespace-2"),
+				HaveField("Spec.PolicyName", CAPIMachineSetAuthorityPolicyName),
+				HaveField("Spec.ParamRef.Namespace", "mapi-namespace-2"),
+				HaveField("Spec.MatchResources.NamespaceSelector.MatchExpressions", ConsistOf(
+					HaveField("Values", ConsistOf("mapi-namespace-2", "capi-namespace-2")),
+				)),
+			),
+		))
+	})
+})
+
+var _ = Describe("Admission policy controller", func() {
+	const (
+		mapiNamespace = "openshift-machine-api"
+		capiNamespace = "openshift-cluster-api"
+	)
+
+	var k komega.Komega
+	var reconciler *AdmissionPolicyReconciler
+
+	reconcilePolicies := func() {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKey{Name: capiNamespace}})
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	policy := func(name string) *admissionregistrationv1beta1.ValidatingAdmissionPolicy {
+		p := &admissionregistrationv1beta1.ValidatingAdmissionPolicy{}
+		p.SetName(name)
+
+		return p
+	}
+
+	binding := func(name string) *admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding {
+		b := &admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding{}
+		b.SetName(name)
+
+		return b
+	}
+
+	BeforeEach(func() {
+		k = komega.New(cl)
+
+		reconciler = &AdmissionPolicyReconciler{
+			Client:         cl,
+			NamespacePairs: []configv1alpha1.NamespacePair{{MAPINamespace: mapiNamespace, CAPINamespace: capiNamespace}},
+		}
+	})
+
+	AfterEach(func() {
+		Expect(RemovePolicies(ctx, cl)).To(Succeed())
+	})
+
+	It("should create the policies and their bindings", func() {
+		reconcilePolicies()
+
+		for _, name := range PolicyNames {
+			Eventually(k.Get(policy(name))).Should(Succeed())
+			Eventually(k.Object(binding(name))).Should(HaveField("Spec.PolicyName", name))
+		}
+	})
+
+	It("should restore a modified policy", func() {
+		reconcilePolicies()
+
+		modified := policy(CAPIMirrorPolicyName)
+		Eventually(k.Update(modified, func() {
+			modified.Spec.Validations[0].Expression = "true"
+		})).Should(Succeed())
+
+		reconcilePolicies()
+
+		Eventually(k.Object(modified)).Should(HaveField("Spec.Validations", Equal(desiredPolicies(reconciler.NamespacePairs)[1].Spec.Validations)))
+	})
+
+	It("should restore the managed-by label of a policy", func() {
+		reconcilePolicies()
+
+		unlabeled := policy(AuthoritativeAPIPolicyName)
+		Eventually(k.Update(unlabeled, func() {
+			unlabeled.SetLabels(nil)
+		})).Should(Succeed())
+
+		reconcilePolicies()
+
+		Eventually(k.Object(unlabeled)).Should(HaveField("ObjectMeta.Labels", HaveKeyWithValue(consts.ManagedByLabel, consts.ManagedByLabelValue)))
+	})
+
+	It("should restore a deleted binding", func() {
+		reconcilePolicies()
+
+		deleted := binding(AuthoritativeAPIPolicyName)
+		Expect(cl.Delete(ctx, deleted)).To(Succeed())
+		Eventually(k.Get(deleted)).Should(WithTransform(apierrors.IsNotFound, BeTrue()))
+
+		reconcilePolicies()
+
+		Eventually(k.Get(deleted)).Should(Succeed())
+	})
+
+	It("should remove the bindings of a namespace pair no longer synchronized", func() {
+		reconciler.NamespacePairs = append(reconciler.NamespacePairs, configv1alpha1.NamespacePair{
+			MAPINamespace: "openshift-machine-api-2",
+			CAPINamespace: "openshift-cluster-api-2",
+		})
+		reconcilePolicies()
+
+		additional := binding(AuthoritativeAPIPolicyName + "-openshift-machine-api-2")
+		Eventually(k.Get(additional)).Should(Succeed())
+
+		reconciler.NamespacePairs = reconciler.NamespacePairs[:1]
+		reconcilePolicies()
+
+		Eventually(k.Get(additional)).Should(WithTransform(apierrors.IsNotFound, BeTrue()))
+		Eventually(k.Get(binding(AuthoritativeAPIPolicyName))).Should(Succeed())
+	})
+
+	It("should remove the policies and their bindings", func() {
+		reconcilePolicies()
+
+		Expect(RemovePolicies(ctx, cl)).To(Succeed())
+
+		for _, name := range PolicyNames {
+			Eventually(k.Get(policy(name))).Should(WithTransform(apierrors.IsNotFound, BeTrue()))
+			Eventually(k.Get(binding(name))).Should(WithTransform(apierrors.IsNotFound, BeTrue()))
+		}
+	})
+})
+
+// warningRecorder records the warnings returned by the API server.
+type warningRecorder struct {
+	sync.Mutex
+	warnings []string
+}
+
+// HandleWarningHeader implements rest.WarningHandler.
+func (w *warningRecorder) HandleWarningHeader(_ int, _ string, message string) {
+	w.Lock()
+	defer w.Unlock()
+
+	w.warnings = append(w.warnings, message)
+}
+
+// Warnings returns the recorded warnings.
+func (w *warningRecorder) Warnings() []string {
+	w.Lock()
+	defer w.Unlock()
+
+	return append([]string{}, w.warnings...)
+}
+
+var _ = Describe("CAPI MachineSet policies", func() {
+	var k komega.Komega
+	var mapiNamespace, capiNamespace *corev1.Namespace
+	var mapiMachineSet *machinev1beta1.MachineSet
+	var capiMachineSet *capiv1beta1.MachineSet
+
+	setMAPIStatus := func(authority machinev1beta1.MachineAuthority, synchronized corev1.ConditionStatus) {
+		Eventually(k.UpdateStatus(mapiMachineSet, func() {
+			mapiMachineSet.Status.AuthoritativeAPI = authority
+			mapiMachineSet.Status.Conditions = []machinev1beta1.Condition{{
+				Type:   consts.SynchronizedCondition,
+				Status: synchronized,
+				Reason: "Test",
+			}}
+		})).Should(Succeed())
+	}
+
+	scaleCAPIMachineSet := func(c client.Client, replicas int32) func() error {
+		return func() error {
+			updated := capiMachineSet.DeepCopy()
+			if err := c.Get(ctx, client.ObjectKeyFromObject(updated), updated); err != nil {
+				return err
+			}
+
+			updated.Spec.Replicas = ptr.To(replicas)
+
+			return c.Update(ctx, updated)
+		}
+	}
+
+	BeforeEach(func() {
+		k = komega.New(cl)
+
+		mapiNamespace = corev1resourcebuilder.Namespace().WithGenerateName("openshift-machine-api-").Build()
+		Expect(cl.Create(ctx, mapiNamespace)).To(Succeed())
+
+		capiNamespace = corev1resourcebuilder.Namespace().WithGenerateName("openshift-cluster-api-").Build()
+		Expect(cl.Create(ctx, capiNamespace)).To(Succeed())
+
+		reconciler := &AdmissionPolicyReconciler{
+			Client:         cl,
+			NamespacePairs: []configv1alpha1.NamespacePair{{MAPINamespace: mapiNamespace.Name, CAPINamespace: capiNamespace.Name}},
+		}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKey{Name: capiNamespace.Name}})
+		Expect(err).ToNot(HaveOccurred())
+
+		mapiMachineSet = machinev1resourcebuilder.MachineSet().
+			WithNamespace(mapiNamespace.Name).
+			WithName("foo").
+			WithAuthoritativeAPI(machinev1beta1.MachineAuthorityMachineAPI).
+			Build()
+		Expect(cl.Create(ctx, mapiMachineSet)).To(Succeed())
+
+		capiMachineSet = capiv1resourcebuilder.MachineSet().WithNamespace(capiNamespace.Name).WithName("foo").WithReplicas(1).Build()
+		Expect(cl.Create(ctx, capiMachineSet)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(RemovePolicies(ctx, cl)).To(Succeed())
+
+		// The policies are removed first, so that they do not deny the deletion of the InfraMachineTemplates.
+		testutils.CleanupResources(Default, ctx, cfg, cl, mapiNamespace.GetName(), &machinev1beta1.MachineSet{})
+		testutils.CleanupResources(Default, ctx, cfg, cl, capiNamespace.GetName(), &capiv1beta1.MachineSet{}, &capav1.AWSMachineTemplate{})
+	})
+
+	It("should deny the changes of the CAPI MachineSet while its MAPI MachineSet is authoritative", func() {
+		setMAPIStatus(machinev1beta1.MachineAuthorityMachineAPI, corev1.ConditionTrue)
+
+		Eventually(scaleCAPIMachineSet(cl, 2)).Should(MatchError(ContainSubstring("change the Machine API MachineSet instead")))
+
+		setMAPIStatus(machinev1beta1.MachineAuthorityClusterAPI, corev1.ConditionTrue)
+
+		Eventually(scaleCAPIMachineSet(cl, 2)).Should(Succeed())
+	})
+
+	It("should allow the changes of the CAPI MachineSet while its MAPI MachineSet has no status", func() {
+		// A MAPI MachineSet which was never reconciled has no status, which the policies must not fail to evaluate.
+		Expect(k.Object(mapiMachineSet)()).To(HaveField("Status.AuthoritativeAPI", BeEmpty()))
+
+		// Wait for the policies to be enforced, with a MachineSet whose MAPI MachineSet is authoritative.
+		authoritativeMAPIMachineSet := machinev1resourcebuilder.MachineSet().
+			WithNamespace(mapiNamespace.Name).
+			WithName("bar").
+			WithAuthoritativeAPI(machinev1beta1.MachineAuthorityMachineAPI).
+			Build()
+		Expect(cl.Create(ctx, authoritativeMAPIMachineSet)).To(Succeed())
+		Eventually(k.UpdateStatus(authoritativeMAPIMachineSet, func() {
+			authoritativeMAPIMachineSet.Status.AuthoritativeAPI = machinev1beta1.MachineAuthorityMachineAPI
+		})).Should(Succeed())
+
+		authoritativeCAPIMachineSet := capiv1resourcebuilder.MachineSet().WithNamespace(capiNamespace.Name).WithName("bar").WithReplicas(1).Build()
+		Expect(cl.Create(ctx, authoritativeCAPIMachineSet)).To(Succeed())
+
+		Eventually(k.Update(authoritativeCAPIMachineSet, func() {
+			authoritativeCAPIMachineSet.Spec.Replicas = ptr.To[int32](2)
+		})).Should(MatchError(ContainSubstring("change the Machine API MachineSet instead")))
+
+		recorder := &warningRecorder{}
+		warningCfg := rest.CopyConfig(cfg)
+		warningCfg.WarningHandler = recorder
+
+		warningClient, err := client.New(warningCfg, client.Options{Scheme: cl.Scheme()})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(scaleCAPIMachineSet(warningClient, 2)()).To(Succeed())
+		Expect(recorder.Warnings()).To(BeEmpty())
+	})
+
+	It("should allow the changes of a CAPI MachineSet owned by a MachineDeployment", func() {
+		setMAPIStatus(machinev1beta1.MachineAuthorityMachineAPI, corev1.ConditionTrue)
+
+		Eventually(k.Update(capiMachineSet, func() {
+			capiMachineSet.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: capiv1beta1.GroupVersion.String(),
+				Kind:       "MachineDeployment",
+				Name:       "foo",
+				UID:        "foo-uid",
+			}}
+		})).Should(Succeed())
+
+		Eventually(scaleCAPIMachineSet(cl, 2)).Should(Succeed())
+	})
+
+	It("should warn about the changes of a CAPI MachineSet whose MAPI MachineSet is not synchronized", func() {
+		setMAPIStatus(machinev1beta1.MachineAuthorityClusterAPI, corev1.ConditionFalse)
+
+		recorder := &warningRecorder{}
+		warningCfg := rest.CopyConfig(cfg)
+		warningCfg.WarningHandler = recorder
+
+		warningClient, err := client.New(warningCfg, client.Options{Scheme: cl.Scheme()})
+		Expect(err).NotTo(HaveOccurred())
+
+		replicas := int32(1)
+		Eventually(func() []string {
+			replicas++
+			Expect(scaleCAPIMachineSet(warningClient, replicas)()).To(Succeed())
+
+			return recorder.Warnings()
+		}).Should(ContainElement(ContainSubstring("is not synchronized with Cluster API")))
+	})
+
+	It("should deny the deletion of a mirrored InfraMachineTemplate referenced by a CAPI MachineSet", func() {
+		template := capav1builder.AWSMachineTemplate().
+			WithNamespace(capiNamespace.Name).
+			WithName("foo-1234").
+			WithAnnotations(map[string]string{consts.InfraMachineTemplateMachineSetAnnotation: mapiMachineSet.Name}).
+			Build()
+		Expect(cl.Create(ctx, template)).To(Succeed())
+
+		Eventually(k.Update(capiMachineSet, func() {
+			capiMachineSet.Spec.Template.Spec.InfrastructureRef.Name = template.Name
+		})).Should(Succeed())
+
+		Eventually(func() error {
+			return cl.Delete(ctx, template.DeepCopy())
+		}).Should(MatchError(ContainSubstring("referenced by a Cluster API MachineSet")))
+
+		Eventually(k.Update(capiMachineSet, func() {
+			capiMachineSet.Spec.Template.Spec.InfrastructureRef.Name = "foo-5678"
+		})).Should(Succeed())
+
+		Eventually(func() error {
+			return cl.Delete(ctx, template.DeepCopy())
+		}).Should(Succeed())
+	})
+})
+
+var _ = Describe("Machine deletion policies", func() {
+	var k komega.Komega
+	var mapiNamespace, capiNamespace *corev1.Namespace
+	var mapiMachine *machinev1beta1.Machine
+	var capiMachine *capiv1beta1.Machine
+
+	setMAPIStatus := func(authority machinev1beta1.MachineAuthority, synchronizedGeneration int64) {
+		Eventually(k.UpdateStatus(mapiMachine, func() {
+			mapiMachine.Status.AuthoritativeAPI = authority
+			mapiMachine.Status.SynchronizedGeneration = synchronizedGeneration
+		})).Should(Succeed())
+	}
+
+	deleteMachine := func(obj client.Object) func() error {
+		return func() error {
+			return cl.Delete(ctx, obj.DeepCopyObject().(client.Object)) //nolint:forcetypeassert
+		}
+	}
+
+	forceDelete := func(obj client.Object) {
+		Eventually(k.Update(obj, func() {
+			obj.SetAnnotations(map[string]string{consts.ForceDeleteAnnotation: "true"})
+		})).Should(Succeed())
+	}
+
+	BeforeEach(func() {
+		k = komega.New(cl)
+
+		mapiNamespace = corev1resourcebuilder.Namespace().WithGenerateName("openshift-machine-api-").Build()
+		Expect(cl.Create(ctx, mapiNamespace)).To(Succeed())
+
+		capiNamespace = corev1resourcebuilder.Namespace().WithGenerateName("openshift-cluster-api-").Build()
+		Expect(cl.Create(ctx, capiNamespace)).To(Succeed())
+
+		reconciler := &AdmissionPolicyReconciler{
+			Client:         cl,
+			NamespacePairs: []configv1alpha1.NamespacePair{{MAPINamespace: mapiNamespace.Name, CAPINamespace: capiNamespace.Name}},
+		}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKey{Name: capiNamespace.Name}})
+		Expect(err).ToNot(HaveOccurred())
+
+		mapiMachine = machinev1resourcebuilder.Machine().
+			WithNamespace(mapiNamespace.Name).
+			WithName("foo").
+			WithAuthoritativeAPI(machinev1beta1.MachineAuthorityMachineAPI).
+			Build()
+		Expect(cl.Create(ctx, mapiMachine)).To(Succeed())
+
+		capiMachine = capiv1resourcebuilder.Machine().
+			WithNamespace(capiNamespace.Name).
+			WithName("foo").
+			WithClusterName("cluster-foo").
+			Build()
+		Expect(cl.Create(ctx, capiMachine)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(RemovePolicies(ctx, cl)).To(Succeed())
+
+		// The policies are removed first, so that they do not deny the deletion of the machines.
+		testutils.CleanupResources(Default, ctx, cfg, cl, mapiNamespace.GetName(), &machinev1beta1.Machine{})
+		testutils.CleanupResources(Default, ctx, cfg, cl, capiNamespace.GetName(), &capiv1beta1.Machine{})
+	})
+
+	It("should deny the deletion of a migrating machine unless it is forced", func() {
+		setMAPIStatus(machinev1beta1.MachineAuthorityMigrating, mapiMachine.Generation)
+
+		Eventually(deleteMachine(mapiMachine)).Should(MatchError(ContainSubstring("the Machine is migrating")))
+		Eventually(deleteMachine(capiMachine)).Should(MatchError(ContainSubstring("the Machine is migrating")))
+
+		forceDelete(capiMachine)
+		Eventually(deleteMachine(capiMachine)).Should(Succeed())
+
+		forceDelete(mapiMachine)
+		Eventually(deleteMachine(mapiMachine)).Should(Succeed())
+	})
+
+	It("should deny the deletion of an authoritative MAPI machine whose mirror is being synchronized", func() {
+		setMAPIStatus(machinev1beta1.MachineAuthorityMachineAPI, mapiMachine.Generation)
+
+		Eventually(k.Update(mapiMachine, func() {
+			mapiMachine.Spec.ProviderID = ptr.To("aws:///us-east-1a/i-foo")
+		})).Should(Succeed())
+
+		Eventually(deleteMachine(mapiMachine)).Should(MatchError(ContainSubstring("the Cluster API mirror of the Machine is being synchronized")))
+
+		setMAPIStatus(machinev1beta1.MachineAuthorityMachineAPI, mapiMachine.Generation)
+
+		Eventually(deleteMachine(mapiMachine)).Should(Succeed())
+	})
+
+	It("should deny the deletion of an authoritative CAPI machine whose mirror is being synchronized", func() {
+		setMAPIStatus(machinev1beta1.MachineAuthorityClusterAPI, capiMachine.Generation)
+
+		Eventually(k.Update(capiMachine, func() {
+			capiMachine.Spec.ProviderID = ptr.To("aws:///us-east-1a/i-foo")
+		})).Should(Succeed())
+
+		Eventually(deleteMachine(capiMachine)).Should(MatchError(ContainSubstring("the Machine API mirror of the Machine is being synchronized")))
+
+		By("Allowing the deletion of the non-authoritative MAPI mirror")
+		Eventually(deleteMachine(mapiMachine)).Should(Succeed())
+		Eventually(deleteMachine(capiMachine)).Should(Succeed())
+	})
+
+	It("should allow the deletion of a machine which was never synchronized", func() {
+		setMAPIStatus(machinev1beta1.MachineAuthorityMachineAPI, 0)
+
+		Eventually(deleteMachine(mapiMachine)).Should(Succeed())
+	})
+})
@@ -0,0 +1,144 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package admissionpolicy
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest/komega"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+)
+
+var _ = Describe("desiredCRDProtectionPolicy", func() {
+	It("should only deny the deletion of the labeled CRDs", func() {
+		policy := desiredCRDProtectionPolicy()
+
+		Expect(policy.Spec.MatchConstraints.ObjectSelector.MatchLabels).To(HaveKeyWithValue(consts.ManagedByLabel, consts.ManagedByLabelValue))
+		Expect(policy.Spec.MatchConstraints.ResourceRules).To(ConsistOf(SatisfyAll(
+			HaveField("Operations", ConsistOf(admissionregistrationv1beta1.Delete)),
+			HaveField("Rule.APIGroups", ConsistOf("apiextensions.k8s.io")),
+			HaveField("Rule.Resources", ConsistOf("customresourcedefinitions")),
+		)))
+	})
+
+	It("should allow the deletion of the CRDs with the override annotation", func() {
+		policy := desiredCRDProtectionPolicy()
+
+		Expect(policy.Spec.Validations[0].Expression).To(ContainSubstring(consts.AllowCRDDeletionAnnotation))
+	})
+})
+
+var _ = Describe("CRD protection policy controller", func() {
+	const capiNamespace = "openshift-cluster-api"
+
+	var k komega.Komega
+
+	reconcilePolicy := func() {
+		reconciler := &CRDProtectionPolicyReconciler{Client: cl, CAPINamespace: capiNamespace}
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKey{Name: capiNamespace}})
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	newCRD := func(plural string, labels map[string]string) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: plural + ".test.cluster-api.openshift.io", Labels: labels},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Group: "test.cluster-api.openshift.io",
+				Names: apiextensionsv1.CustomResourceDefinitionNames{Plural: plural, Kind: plural + "Kind"},
+				Scope: apiextensionsv1.NamespaceScoped,
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{
+					Name:    "v1",
+					Served:  true,
+					Storage: true,
+					Schema: &apiextensionsv1.CustomResourceValidation{
+						OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{Type: "object"},
+					},
+				}},
+			},
+		}
+	}
+
+	policy := &admissionregistrationv1beta1.ValidatingAdmissionPolicy{}
+	policy.SetName(CRDProtectionPolicyName)
+
+	binding := &admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding{}
+	binding.SetName(CRDProtectionPolicyName)
+
+	BeforeEach(func() {
+		k = komega.New(cl)
+	})
+
+	AfterEach(func() {
+		Expect(removePolicies(ctx, cl, CRDProtectionPolicyName)).To(Succeed())
+	})
+
+	It("should create the policy and its binding", func() {
+		reconcilePolicy()
+
+		Eventually(k.Get(policy.DeepCopy())).Should(Succeed())
+		Eventually(k.Object(binding.DeepCopy())).Should(HaveField("Spec.PolicyName", CRDProtectionPolicyName))
+	})
+
+	It("should restore a modified policy", func() {
+		reconcilePolicy()
+
+		modified := policy.DeepCopy()
+		Eventually(k.Update(modified, func() {
+			modified.Spec.Validations[0].Expression = "true"
+		})).Should(Succeed())
+
+		reconcilePolicy()
+
+		Eventually(k.Object(modified)).Should(HaveField("Spec.Validations", Equal(desiredCRDProtectionPolicy().Spec.Validations)))
+	})
+
+	It("should deny the deletion of a managed CRD until it has the override annotation", func() {
+		reconcilePolicy()
+
+		crd := newCRD("protectedwidgets", map[string]string{consts.ManagedByLabel: consts.ManagedByLabelValue})
+		Expect(cl.Create(ctx, crd)).To(Succeed())
+
+		Eventually(func() error {
+			return cl.Delete(ctx, crd.DeepCopy())
+		}).Should(MatchError(ContainSubstring(consts.AllowCRDDeletionAnnotation)))
+
+		Eventually(k.Update(crd, func() {
+			crd.SetAnnotations(map[string]string{consts.AllowCRDDeletionAnnotation: "true"})
+		})).Should(Succeed())
+
+		Eventually(func() error {
+			return cl.Delete(ctx, crd.DeepCopy())
+		}).Should(Succeed())
+	})
+
+	It("should allow the deletion of the CRDs not managed by the operator", func() {
+		reconcilePolicy()
+
+		crd := newCRD("unmanagedwidgets", nil)
+		Expect(cl.Create(ctx, crd)).To(Succeed())
+
+		Expect(cl.Delete(ctx, crd)).To(Succeed())
+		Eventually(k.Get(crd)).Should(WithTransform(apierrors.IsNotFound, BeTrue()))
+	})
+})
@@ -0,0 +1,85 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package admissionpolicy
+
+import (
+	"context"
+	"fmt"
+
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const machinePoolPolicyControllerName = "MachinePoolPolicyController"
+
+// MachinePoolPolicyReconciler owns the ValidatingAdmissionPolicy, and its binding, denying the creation of CAPI
+// MachinePools in the CAPI namespace, as OpenShift does not support them yet. The existing MachinePools are left as is.
+// When Block is false, the policy is removed instead, to allow MachinePools.
+type MachinePoolPolicyReconciler struct {
+	client.Client
+
+	CAPINamespace string
+	Block         bool
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MachinePoolPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	toCAPINamespace := handler.EnqueueRequestsFromMapFunc(func(context.Context, client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: client.ObjectKey{Name: r.CAPINamespace}}}
+	})
+
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named(machinePoolPolicyControllerName).
+		// The CAPI namespace is watched so that the policy is reconciled as soon as the controller starts.
+		For(&corev1.Namespace{}, builder.WithPredicates(namePredicate(r.CAPINamespace))).
+		Watches(&admissionregistrationv1beta1.ValidatingAdmissionPolicy{}, toCAPINamespace, builder.WithPredicates(namePredicate(MachinePoolPolicyName))).
+		Watches(&admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding{}, toCAPINamespace, builder.WithPredicates(namePredicate(MachinePoolPolicyName))).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create controller: %w", err)
+	}
+
+	r.Client = mgr.GetClient()
+
+	return nil
+}
+
+// Reconcile creates the policy and its binding, or restores them when they drifted. They are removed when
+// MachinePools are not blocked.
+func (r *MachinePoolPolicyReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (ctrl.Result, error) {
+	logger := ctrl.LoggerFrom(ctx).WithName(machinePoolPolicyControllerName)
+	ctx = ctrl.LoggerInto(ctx, logger)
+
+	if !r.Block {
+		return ctrl.Result{}, removePolicies(ctx, r.Client, MachinePoolPolicyName)
+	}
+
+	if err := ensurePolicy(ctx, r.Client, desiredMachinePoolPolicy(r.CAPINamespace)); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	for _, binding := range desiredBindings(MachinePoolPolicyName) {
+		if err := ensureBinding(ctx, r.Client, binding); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
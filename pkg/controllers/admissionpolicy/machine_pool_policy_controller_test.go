@@ -0,0 +1,96 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package admissionpolicy
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest/komega"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	configv1alpha1 "github.com/openshift/cluster-capi-operator/pkg/apis/config/v1alpha1"
+)
+
+var _ = Describe("desiredMachinePoolPolicy", func() {
+	It("should only deny the creation of MachinePools in the CAPI namespace", func() {
+		policy := desiredMachinePoolPolicy("capi-namespace")
+
+		Expect(policy.Spec.MatchConstraints.NamespaceSelector.MatchLabels).To(HaveKeyWithValue(corev1.LabelMetadataName, "capi-namespace"))
+		Expect(policy.Spec.MatchConstraints.ResourceRules).To(ConsistOf(SatisfyAll(
+			HaveField("Operations", ConsistOf(admissionregistrationv1beta1.Create)),
+			HaveField("Rule.APIGroups", ConsistOf("cluster.x-k8s.io")),
+			HaveField("Rule.Resources", ConsistOf("machinepools")),
+		)))
+	})
+})
+
+var _ = Describe("MachinePool policy controller", func() {
+	const capiNamespace = "openshift-cluster-api"
+
+	var k komega.Komega
+
+	reconcilePolicy := func(block bool) {
+		reconciler := &MachinePoolPolicyReconciler{Client: cl, CAPINamespace: capiNamespace, Block: block}
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKey{Name: capiNamespace}})
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	policy := &admissionregistrationv1beta1.ValidatingAdmissionPolicy{}
+	policy.SetName(MachinePoolPolicyName)
+
+	binding := &admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding{}
+	binding.SetName(MachinePoolPolicyName)
+
+	BeforeEach(func() {
+		k = komega.New(cl)
+	})
+
+	AfterEach(func() {
+		Expect(removePolicies(ctx, cl, MachinePoolPolicyName)).To(Succeed())
+	})
+
+	It("should create the policy and its binding when MachinePools are blocked", func() {
+		reconcilePolicy(true)
+
+		Eventually(k.Get(policy.DeepCopy())).Should(Succeed())
+		Eventually(k.Object(binding.DeepCopy())).Should(HaveField("Spec.PolicyName", MachinePoolPolicyName))
+	})
+
+	It("should remove the policy and its binding when MachinePools are allowed", func() {
+		reconcilePolicy(true)
+		reconcilePolicy(false)
+
+		Eventually(k.Get(policy.DeepCopy())).Should(WithTransform(apierrors.IsNotFound, BeTrue()))
+		Eventually(k.Get(binding.DeepCopy())).Should(WithTransform(apierrors.IsNotFound, BeTrue()))
+	})
+
+	It("should not remove the migration policies when MachinePools are allowed", func() {
+		migrationPolicy := &admissionregistrationv1beta1.ValidatingAdmissionPolicy{}
+		migrationPolicy.SetName(AuthoritativeAPIPolicyName)
+
+		Expect(ensurePolicy(ctx, cl, desiredPolicies([]configv1alpha1.NamespacePair{{MAPINamespace: "openshift-machine-api", CAPINamespace: capiNamespace}})[0])).To(Succeed())
+		DeferCleanup(func() { Expect(RemovePolicies(ctx, cl)).To(Succeed()) })
+
+		reconcilePolicy(false)
+
+		Consistently(k.Get(migrationPolicy)).Should(Succeed())
+	})
+})
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package admissionpolicy
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest/komega"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+)
+
+var _ = Describe("desiredManagedResourcesPolicy", func() {
+	It("should only deny the updates of the labeled resources in the CAPI namespace", func() {
+		policy := desiredManagedResourcesPolicy("capi-namespace")
+
+		Expect(policy.Spec.MatchConstraints.NamespaceSelector.MatchLabels).To(HaveKeyWithValue(corev1.LabelMetadataName, "capi-namespace"))
+		Expect(policy.Spec.MatchConstraints.ObjectSelector.MatchLabels).To(HaveKeyWithValue(consts.ManagedByLabel, consts.ManagedByLabelValue))
+		Expect(policy.Spec.MatchConstraints.ResourceRules).To(ConsistOf(
+			HaveField("Rule.Resources", ConsistOf("deployments")),
+			HaveField("Rule.Resources", ConsistOf("clusters")),
+			HaveField("Rule.Resources", ConsistOf("*")),
+		))
+		Expect(policy.Spec.MatchConstraints.ResourceRules).To(HaveEach(HaveField("Operations", ConsistOf(admissionregistrationv1beta1.Update))))
+	})
+
+	It("should allow the changes of the service accounts of the CAPI namespace", func() {
+		policy := desiredManagedResourcesPolicy("capi-namespace")
+
+		Expect(policy.Spec.MatchConditions[0].Expression).To(ContainSubstring("system:serviceaccount:capi-namespace:"))
+	})
+
+	It("should allow the changes of the resources with the break-glass annotation", func() {
+		policy := desiredManagedResourcesPolicy("capi-namespace")
+
+		Expect(policy.Spec.Validations[0].Expression).To(ContainSubstring(consts.AllowManualChangesAnnotation))
+	})
+})
+
+var _ = Describe("Managed resources policy controller", func() {
+	const capiNamespace = "openshift-cluster-api"
+
+	var k komega.Komega
+
+	reconcilePolicy := func(protect bool) {
+		reconciler := &ManagedResourcesPolicyReconciler{Client: cl, CAPINamespace: capiNamespace, Protect: protect}
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKey{Name: capiNamespace}})
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	policy := &admissionregistrationv1beta1.ValidatingAdmissionPolicy{}
+	policy.SetName(ManagedResourcesPolicyName)
+
+	binding := &admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding{}
+	binding.SetName(ManagedResourcesPolicyName)
+
+	BeforeEach(func() {
+		k = komega.New(cl)
+	})
+
+	AfterEach(func() {
+		Expect(removePolicies(ctx, cl, ManagedResourcesPolicyName)).To(Succeed())
+	})
+
+	It("should create the policy and its binding when the managed resources are protected", func() {
+		reconcilePolicy(true)
+
+		Eventually(k.Get(policy.DeepCopy())).Should(Succeed())
+		Eventually(k.Object(binding.DeepCopy())).Should(HaveField("Spec.PolicyName", ManagedResourcesPolicyName))
+	})
+
+	It("should restore a modified policy", func() {
+		reconcilePolicy(true)
+
+		modified := policy.DeepCopy()
+		Eventually(k.Update(modified, func() {
+			modified.Spec.Validations[0].Expression = "true"
+		})).Should(Succeed())
+
+		reconcilePolicy(true)
+
+		Eventually(k.Object(modified)).Should(HaveField("Spec.Validations", Equal(desiredManagedResourcesPolicy(capiNamespace).Spec.Validations)))
+	})
+
+	It("should remove the policy and its binding when the managed resources are not protected", func() {
+		reconcilePolicy(true)
+		reconcilePolicy(false)
+
+		Eventually(k.Get(policy.DeepCopy())).Should(WithTransform(apierrors.IsNotFound, BeTrue()))
+		Eventually(k.Get(binding.DeepCopy())).Should(WithTransform(apierrors.IsNotFound, BeTrue()))
+	})
+})
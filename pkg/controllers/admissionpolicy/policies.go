@@ -0,0 +1,523 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package admissionpolicy
+
+import (
+	"fmt"
+	"strings"
+
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	configv1alpha1 "github.com/openshift/cluster-capi-operator/pkg/apis/config/v1alpha1"
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+)
+
+const (
+	// AuthoritativeAPIPolicyName is the name of the policy, and of its binding, preventing the authoritative API of
+	// a MAPI resource from being changed while the resource is migrating.
+	AuthoritativeAPIPolicyName = "machine-api-migration-authoritative-api"
+
+	// CAPIMirrorPolicyName is the name of the policy, and of its binding, preventing the spec of the paused CAPI
+	// mirrors of MAPI resources from being changed by anyone but the operator.
+	CAPIMirrorPolicyName = "machine-api-migration-protect-capi-mirrors"
+
+	// CAPIMachineSetAuthorityPolicyName is the name of the policy, and of its binding, preventing the spec of the CAPI
+	// MachineSets, and of the InfraMachineTemplates mirrored from MAPI MachineSets, from being changed by anyone but
+	// the operator while their MAPI MachineSet is authoritative.
+	CAPIMachineSetAuthorityPolicyName = "machine-api-migration-capi-machine-set-authority"
+
+	// CAPIMachineSetSyncWarningPolicyName is the name of the policy, and of its binding, warning the users changing a
+	// CAPI MachineSet, or its mirrored InfraMachineTemplate, whose MAPI MachineSet is not synchronized.
+	CAPIMachineSetSyncWarningPolicyName = "machine-api-migration-capi-machine-set-sync-warning"
+
+	// InfraMachineTemplateDeletionPolicyName is the name of the policy, and of its binding, preventing the
+	// InfraMachineTemplates mirrored from MAPI MachineSets from being deleted while a CAPI MachineSet references them.
+	InfraMachineTemplateDeletionPolicyName = "machine-api-migration-referenced-infra-machine-templates"
+
+	// MAPIMachineDeletionPolicyName is the name of the policy, and of its binding, preventing the deletion of a MAPI
+	// Machine while it is migrating, or while it is authoritative and its CAPI mirror is being synchronized.
+	MAPIMachineDeletionPolicyName = "machine-api-migration-mapi-machine-deletion"
+
+	// CAPIMachineDeletionPolicyName is the name of the policy, and of its binding, preventing the deletion of a CAPI
+	// Machine while its MAPI Machine is migrating, or while it is authoritative and its MAPI mirror is being synchronized.
+	CAPIMachineDeletionPolicyName = "machine-api-migration-capi-machine-deletion"
+
+	// MachinePoolPolicyName is the name of the policy, and of its binding, preventing CAPI MachinePools, which OpenShift
+	// does not support yet, from being created in the CAPI namespace.
+	MachinePoolPolicyName = "cluster-api-block-machine-pools"
+
+	// CRDProtectionPolicyName is the name of the policy, and of its binding, preventing the provider CRDs installed by
+	// the operator from being deleted along with all their custom resources.
+	CRDProtectionPolicyName = "cluster-api-protect-crds"
+
+	// ManagedResourcesPolicyName is the name of the policy, and of its binding, preventing the provider Deployments, the
+	// Cluster and the InfraCluster managed by the operator in the CAPI namespace from being changed manually.
+	ManagedResourcesPolicyName = "cluster-api-protect-managed-resources"
+
+	// operatorServiceAccountName is the service account the sync controllers run as, in the CAPI namespace.
+	operatorServiceAccountName = "cluster-capi-operator"
+)
+
+// PolicyNames are the names of the policies owned by the controller. Each of them has a binding of the same name for
+// the first namespace pair, and a binding suffixed with the MAPI namespace for each additional pair.
+var PolicyNames = []string{
+	AuthoritativeAPIPolicyName,
+	CAPIMirrorPolicyName,
+	CAPIMachineSetAuthorityPolicyName,
+	CAPIMachineSetSyncWarningPolicyName,
+	InfraMachineTemplateDeletionPolicyName,
+	MAPIMachineDeletionPolicyName,
+	CAPIMachineDeletionPolicyName,
+}
+
+// infraMachineTemplateResources are the InfraMachineTemplate resources of the platforms whose MachineSets are
+// synchronized, all of them in the infrastructure.cluster.x-k8s.io API group.
+var infraMachineTemplateResources = []string{"awsmachinetemplates", "ibmpowervsmachinetemplates", "openstackmachinetemplates"} //nolint:gochecknoglobals
+
+// machineSetNameVariable is the name of the MAPI MachineSet a CAPI MachineSet, or a mirrored InfraMachineTemplate, is
+// synchronized from. It is empty for the InfraMachineTemplates which are not mirrored.
+var machineSetNameVariable = admissionregistrationv1beta1.Variable{ //nolint:gochecknoglobals
+	Name: "machineSetName",
+	Expression: fmt.Sprintf("request.resource.group == 'cluster.x-k8s.io' ? object.metadata.name : "+
+		"object.metadata.?annotations[?'%s'].orValue('')", consts.InfraMachineTemplateMachineSetAnnotation),
+}
+
+// forceDeleteVariable is whether the deleted Machine has the ForceDeleteAnnotation, allowing its deletion whatever the
+// state of its migration.
+var forceDeleteVariable = admissionregistrationv1beta1.Variable{ //nolint:gochecknoglobals
+	Name:       "forceDelete",
+	Expression: fmt.Sprintf("oldObject.metadata.?annotations[?'%s'].orValue('') == 'true'", consts.ForceDeleteAnnotation),
+}
+
+// notAControllerCondition skips the requests of the service accounts of the MAPI and CAPI namespaces, those of the
+// operator and of the Machine API and Cluster API controllers, such as the MachineSet and MachineHealthCheck controllers.
+func notAControllerCondition(pairs []configv1alpha1.NamespacePair) admissionregistrationv1beta1.MatchCondition {
+	expressions := []string{}
+
+	for _, pair := range pairs {
+		for _, namespace := range []string{pair.MAPINamespace, pair.CAPINamespace} {
+			expressions = append(expressions, fmt.Sprintf("!request.userInfo.username.startsWith('system:serviceaccount:%s:')", namespace))
+		}
+	}
+
+	return admissionregistrationv1beta1.MatchCondition{
+		Name:       "not-a-controller",
+		Expression: strings.Join(expressions, " && "),
+	}
+}
+
+// notTheOperatorCondition skips the requests of the operator, which runs in the CAPI namespace of the first pair.
+func notTheOperatorCondition(pairs []configv1alpha1.NamespacePair) admissionregistrationv1beta1.MatchCondition {
+	return admissionregistrationv1beta1.MatchCondition{
+		Name:       "not-the-operator",
+		Expression: fmt.Sprintf("request.userInfo.username != 'system:serviceaccount:%s:%s'", pairs[0].CAPINamespace, operatorServiceAccountName),
+	}
+}
+
+// mapiNamespaces returns the MAPI namespaces of the pairs.
+func mapiNamespaces(pairs []configv1alpha1.NamespacePair) []string {
+	namespaces := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		namespaces = append(namespaces, pair.MAPINamespace)
+	}
+
+	return namespaces
+}
+
+// capiNamespaces returns the CAPI namespaces of the pairs.
+func capiNamespaces(pairs []configv1alpha1.NamespacePair) []string {
+	namespaces := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		namespaces = append(namespaces, pair.CAPINamespace)
+	}
+
+	return namespaces
+}
+
+// notOwnedByMachineDeploymentCondition skips the CAPI MachineSets owned by a MachineDeployment. Their MAPI MachineSet
+// is a read-only mirror, the MachineSet sync controller no longer writes to them, and their changes are made by the
+// MachineDeployment controller.
+var notOwnedByMachineDeploymentCondition = admissionregistrationv1beta1.MatchCondition{ //nolint:gochecknoglobals
+	Name: "not-owned-by-a-machine-deployment",
+	Expression: "!object.metadata.?ownerReferences.orValue([]).exists(ref, " +
+		"ref.kind == 'MachineDeployment' && ref.apiVersion.startsWith('cluster.x-k8s.io/'))",
+}
+
+// desiredPolicies returns the policies protecting the migration, templated for the namespaces of all the pairs. The
+// bindings of each pair restrict them to its namespaces.
+// The defaults set by the API server are set explicitly, so that the policies read back compare equal.
+func desiredPolicies(pairs []configv1alpha1.NamespacePair) []*admissionregistrationv1beta1.ValidatingAdmissionPolicy {
+	return []*admissionregistrationv1beta1.ValidatingAdmissionPolicy{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: AuthoritativeAPIPolicyName, Labels: managedByLabels()},
+			Spec: admissionregistrationv1beta1.ValidatingAdmissionPolicySpec{
+				MatchConstraints: matchConstraints(mapiNamespaces(pairs), "machine.openshift.io", admissionregistrationv1beta1.Update, "machines", "machinesets"),
+				Validations: []admissionregistrationv1beta1.Validation{{
+					Expression: "oldObject.?status.?authoritativeAPI.orValue('') != 'Migrating' || " +
+						"object.spec.?authoritativeAPI == oldObject.spec.?authoritativeAPI",
+					Message: "spec.authoritativeAPI cannot be changed while the resource is migrating between the Machine API and Cluster API",
+				}},
+				FailurePolicy: ptr.To(admissionregistrationv1beta1.Fail),
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: CAPIMirrorPolicyName, Labels: managedByLabels()},
+			Spec: admissionregistrationv1beta1.ValidatingAdmissionPolicySpec{
+				MatchConstraints: matchConstraints(capiNamespaces(pairs), "cluster.x-k8s.io", admissionregistrationv1beta1.Update, "machines", "machinesets"),
+				MatchConditions:  []admissionregistrationv1beta1.MatchCondition{notTheOperatorCondition(pairs), notOwnedByMachineDeploymentCondition},
+				Validations: []admissionregistrationv1beta1.Validation{{
+					Expression: fmt.Sprintf("!('%s' in oldObject.metadata.?annotations.orValue({}) && '%s' in oldObject.metadata.?annotations.orValue({})) || "+
+						"object.spec == oldObject.spec", consts.LastSyncTimeAnnotation, capiv1beta1.PausedAnnotation),
+					Message: "the spec of the Cluster API mirror of a Machine API resource is synchronized from it, change the Machine API resource instead",
+				}},
+				FailurePolicy: ptr.To(admissionregistrationv1beta1.Fail),
+			},
+		},
+		desiredCAPIMachineSetAuthorityPolicy(pairs),
+		desiredCAPIMachineSetSyncWarningPolicy(pairs),
+		desiredInfraMachineTemplateDeletionPolicy(pairs),
+		desiredMAPIMachineDeletionPolicy(pairs),
+		desiredCAPIMachineDeletionPolicy(pairs),
+	}
+}
+
+// desiredCAPIMachineSetAuthorityPolicy returns the policy denying the changes of the spec of the CAPI MachineSets and
+// of their mirrored InfraMachineTemplates, unless they come from the operator, while their MAPI MachineSet is
+// authoritative. The policy is evaluated against each MAPI MachineSet, only the one of the object is checked.
+func desiredCAPIMachineSetAuthorityPolicy(pairs []configv1alpha1.NamespacePair) *admissionregistrationv1beta1.ValidatingAdmissionPolicy {
+	return &admissionregistrationv1beta1.ValidatingAdmissionPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: CAPIMachineSetAuthorityPolicyName, Labels: managedByLabels()},
+		Spec: admissionregistrationv1beta1.ValidatingAdmissionPolicySpec{
+			ParamKind:        &admissionregistrationv1beta1.ParamKind{APIVersion: "machine.openshift.io/v1beta1", Kind: "MachineSet"},
+			MatchConstraints: machineSetMatchConstraints(capiNamespaces(pairs), admissionregistrationv1beta1.Update),
+			MatchConditions:  []admissionregistrationv1beta1.MatchCondition{notTheOperatorCondition(pairs), notOwnedByMachineDeploymentCondition},
+			Variables:        []admissionregistrationv1beta1.Variable{machineSetNameVariable},
+			Validations: []admissionregistrationv1beta1.Validation{{
+				Expression: "params.metadata.name != variables.machineSetName || " +
+					"params.?status.?authoritativeAPI.orValue('') != 'MachineAPI' || object.spec == oldObject.spec",
+				Message: "the Machine API MachineSet of this resource is authoritative, its spec is synchronized from it, " +
+					"change the Machine API MachineSet instead",
+			}},
+			FailurePolicy: ptr.To(admissionregistrationv1beta1.Fail),
+		},
+	}
+}
+
+// desiredCAPIMachineSetSyncWarningPolicy returns the policy failing the changes of the CAPI MachineSets and of their
+// mirrored InfraMachineTemplates whose MAPI MachineSet is not synchronized. Its binding only warns, as the changes
+// may be the fix of the synchronization.
+func desiredCAPIMachineSetSyncWarningPolicy(pairs []configv1alpha1.NamespacePair) *admissionregistrationv1beta1.ValidatingAdmissionPolicy {
+	return &admissionregistrationv1beta1.ValidatingAdmissionPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: CAPIMachineSetSyncWarningPolicyName, Labels: managedByLabels()},
+		Spec: admissionregistrationv1beta1.ValidatingAdmissionPolicySpec{
+			ParamKind:        &admissionregistrationv1beta1.ParamKind{APIVersion: "machine.openshift.io/v1beta1", Kind: "MachineSet"},
+			MatchConstraints: machineSetMatchConstraints(capiNamespaces(pairs), admissionregistrationv1beta1.Update),
+			MatchConditions:  []admissionregistrationv1beta1.MatchCondition{notTheOperatorCondition(pairs)},
+			Variables:        []admissionregistrationv1beta1.Variable{machineSetNameVariable},
+			Validations: []admissionregistrationv1beta1.Validation{{
+				Expression: fmt.Sprintf("params.metadata.name != variables.machineSetName || "+
+					"!params.?status.?conditions.orValue([]).exists(c, c.type == '%s' && c.status == 'False')", consts.SynchronizedCondition),
+				Message: fmt.Sprintf("the Machine API MachineSet of this resource is not synchronized with Cluster API, "+
+					"see its %s condition", consts.SynchronizedCondition),
+			}},
+			FailurePolicy: ptr.To(admissionregistrationv1beta1.Fail),
+		},
+	}
+}
+
+// desiredInfraMachineTemplateDeletionPolicy returns the policy denying the deletion of the InfraMachineTemplates
+// mirrored from MAPI MachineSets while a CAPI MachineSet which is not being deleted references them. The policy is
+// evaluated against each CAPI MachineSet.
+func desiredInfraMachineTemplateDeletionPolicy(pairs []configv1alpha1.NamespacePair) *admissionregistrationv1beta1.ValidatingAdmissionPolicy {
+	return &admissionregistrationv1beta1.ValidatingAdmissionPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: InfraMachineTemplateDeletionPolicyName, Labels: managedByLabels()},
+		Spec: admissionregistrationv1beta1.ValidatingAdmissionPolicySpec{
+			ParamKind: &admissionregistrationv1beta1.ParamKind{APIVersion: capiv1beta1.GroupVersion.String(), Kind: "MachineSet"},
+			MatchConstraints: matchConstraints(capiNamespaces(pairs), "infrastructure.cluster.x-k8s.io", admissionregistrationv1beta1.Delete,
+				infraMachineTemplateResources...),
+			Validations: []admissionregistrationv1beta1.Validation{{
+				Expression: fmt.Sprintf("!('%s' in oldObject.metadata.?annotations.orValue({})) || has(params.metadata.deletionTimestamp) || "+
+					"params.?spec.?template.?spec.?infrastructureRef.?name.orValue('') != oldObject.metadata.name", consts.InfraMachineTemplateMachineSetAnnotation),
+				Message: "the InfraMachineTemplate is referenced by a Cluster API MachineSet, it is deleted once no longer referenced",
+			}},
+			FailurePolicy: ptr.To(admissionregistrationv1beta1.Fail),
+		},
+	}
+}
+
+// desiredMAPIMachineDeletionPolicy returns the policy denying the deletion of the MAPI Machines which are migrating, or
+// which are authoritative while their CAPI mirror is not synchronized with their latest generation yet. Deleting them
+// then could leave the mirror managing, or recreating, an instance nothing deletes anymore. The machines never
+// synchronized have no mirror to strand, and the ForceDeleteAnnotation allows deleting them anyway. Only the users are
+// denied, the controllers, such as those scaling down the MachineSets or remediating the machines, are not.
+func desiredMAPIMachineDeletionPolicy(pairs []configv1alpha1.NamespacePair) *admissionregistrationv1beta1.ValidatingAdmissionPolicy {
+	return &admissionregistrationv1beta1.ValidatingAdmissionPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: MAPIMachineDeletionPolicyName, Labels: managedByLabels()},
+		Spec: admissionregistrationv1beta1.ValidatingAdmissionPolicySpec{
+			MatchConstraints: matchConstraints(mapiNamespaces(pairs), "machine.openshift.io", admissionregistrationv1beta1.Delete, "machines"),
+			MatchConditions:  []admissionregistrationv1beta1.MatchCondition{notAControllerCondition(pairs)},
+			Variables:        []admissionregistrationv1beta1.Variable{forceDeleteVariable},
+			Validations: []admissionregistrationv1beta1.Validation{{
+				Expression: "variables.forceDelete || oldObject.?status.?authoritativeAPI.orValue('') != 'Migrating'",
+				Message: fmt.Sprintf("the Machine is migrating between the Machine API and Cluster API, wait for the migration to complete "+
+					"or set the %s=true annotation on it to force its deletion", consts.ForceDeleteAnnotation),
+			}, {
+				Expression: "variables.forceDelete || oldObject.?status.?authoritativeAPI.orValue('') != 'MachineAPI' || " +
+					"oldObject.?status.?synchronizedGeneration.orValue(0) == 0 || oldObject.status.synchronizedGeneration >= oldObject.metadata.generation",
+				Message: fmt.Sprintf("the Cluster API mirror of the Machine is being synchronized, wait for the synchronization to complete "+
+					"or set the %s=true annotation on it to force its deletion", consts.ForceDeleteAnnotation),
+			}},
+			FailurePolicy: ptr.To(admissionregistrationv1beta1.Fail),
+		},
+	}
+}
+
+// desiredCAPIMachineDeletionPolicy returns the policy denying the deletion of the CAPI Machines whose MAPI Machine is
+// migrating, or is a mirror of the CAPI Machine not synchronized with its latest generation yet, in the same way as
+// desiredMAPIMachineDeletionPolicy. The policy is evaluated against each MAPI Machine, only the one of the same name
+// is checked.
+func desiredCAPIMachineDeletionPolicy(pairs []configv1alpha1.NamespacePair) *admissionregistrationv1beta1.ValidatingAdmissionPolicy {
+	return &admissionregistrationv1beta1.ValidatingAdmissionPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: CAPIMachineDeletionPolicyName, Labels: managedByLabels()},
+		Spec: admissionregistrationv1beta1.ValidatingAdmissionPolicySpec{
+			ParamKind:        &admissionregistrationv1beta1.ParamKind{APIVersion: "machine.openshift.io/v1beta1", Kind: "Machine"},
+			MatchConstraints: matchConstraints(capiNamespaces(pairs), "cluster.x-k8s.io", admissionregistrationv1beta1.Delete, "machines"),
+			MatchConditions:  []admissionregistrationv1beta1.MatchCondition{notAControllerCondition(pairs)},
+			Variables:        []admissionregistrationv1beta1.Variable{forceDeleteVariable},
+			Validations: []admissionregistrationv1beta1.Validation{{
+				Expression: "variables.forceDelete || params.metadata.name != oldObject.metadata.name || " +
+					"params.?status.?authoritativeAPI.orValue('') != 'Migrating'",
+				Message: fmt.Sprintf("the Machine is migrating between the Machine API and Cluster API, wait for the migration to complete "+
+					"or set the %s=true annotation on it to force its deletion", consts.ForceDeleteAnnotation),
+			}, {
+				Expression: "variables.forceDelete || params.metadata.name != oldObject.metadata.name || " +
+					"params.?status.?authoritativeAPI.orValue('') != 'ClusterAPI' || " +
+					"params.?status.?synchronizedGeneration.orValue(0) == 0 || params.status.synchronizedGeneration >= oldObject.metadata.generation",
+				Message: fmt.Sprintf("the Machine API mirror of the Machine is being synchronized, wait for the synchronization to complete "+
+					"or set the %s=true annotation on it to force its deletion", consts.ForceDeleteAnnotation),
+			}},
+			FailurePolicy: ptr.To(admissionregistrationv1beta1.Fail),
+		},
+	}
+}
+
+// desiredMachinePoolPolicy returns the policy denying the creation of CAPI MachinePools in the CAPI namespace.
+func desiredMachinePoolPolicy(capiNamespace string) *admissionregistrationv1beta1.ValidatingAdmissionPolicy {
+	return &admissionregistrationv1beta1.ValidatingAdmissionPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: MachinePoolPolicyName, Labels: managedByLabels()},
+		Spec: admissionregistrationv1beta1.ValidatingAdmissionPolicySpec{
+			MatchConstraints: matchConstraints([]string{capiNamespace}, "cluster.x-k8s.io", admissionregistrationv1beta1.Create, "machinepools"),
+			Validations: []admissionregistrationv1beta1.Validation{{
+				Expression: "false",
+				Message: "MachinePools are not supported by OpenShift yet, use MachineSets instead. " +
+					"The BlockMachinePools feature of the ClusterAPIOperatorConfig can be disabled to allow them",
+			}},
+			FailurePolicy: ptr.To(admissionregistrationv1beta1.Fail),
+		},
+	}
+}
+
+// desiredManagedResourcesPolicy returns the policy denying the manual changes of the resources managed by the operator
+// in the CAPI namespace, that is of its labeled provider Deployments, Cluster and InfraCluster. The changes made by the
+// service accounts of the CAPI namespace, those of the operator and of the providers, are allowed, as are the changes
+// of the resources with the AllowManualChangesAnnotation.
+func desiredManagedResourcesPolicy(capiNamespace string) *admissionregistrationv1beta1.ValidatingAdmissionPolicy {
+	return &admissionregistrationv1beta1.ValidatingAdmissionPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: ManagedResourcesPolicyName, Labels: managedByLabels()},
+		Spec: admissionregistrationv1beta1.ValidatingAdmissionPolicySpec{
+			MatchConstraints: &admissionregistrationv1beta1.MatchResources{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{corev1.LabelMetadataName: capiNamespace}},
+				// An update is matched when either the old or the new object is labeled, so removing the label is denied too.
+				ObjectSelector: &metav1.LabelSelector{MatchLabels: managedByLabels()},
+				ResourceRules: []admissionregistrationv1beta1.NamedRuleWithOperations{
+					resourceRule("apps", admissionregistrationv1beta1.Update, "deployments"),
+					resourceRule("cluster.x-k8s.io", admissionregistrationv1beta1.Update, "clusters"),
+					// The InfraCluster resource depends on the platform, the label only matches the InfraCluster.
+					resourceRule("infrastructure.cluster.x-k8s.io", admissionregistrationv1beta1.Update, "*"),
+				},
+				MatchPolicy: ptr.To(admissionregistrationv1beta1.Equivalent),
+			},
+			MatchConditions: []admissionregistrationv1beta1.MatchCondition{{
+				Name:       "not-a-capi-namespace-service-account",
+				Expression: fmt.Sprintf("!request.userInfo.username.startsWith('system:serviceaccount:%s:')", capiNamespace),
+			}},
+			Validations: []admissionregistrationv1beta1.Validation{{
+				Expression: fmt.Sprintf("('%[1]s' in object.metadata.?annotations.orValue({}) && object.metadata.annotations['%[1]s'] == 'true') || "+
+					"(object.spec == oldObject.spec && '%[2]s' in object.metadata.?labels.orValue({}) && object.metadata.labels['%[2]s'] == '%[3]s')",
+					consts.AllowManualChangesAnnotation, consts.ManagedByLabel, consts.ManagedByLabelValue),
+				Message: fmt.Sprintf("the resource is managed by the cluster-capi-operator, "+
+					"set the %s=true annotation on it to change it manually", consts.AllowManualChangesAnnotation),
+			}},
+			FailurePolicy: ptr.To(admissionregistrationv1beta1.Fail),
+		},
+	}
+}
+
+// desiredCRDProtectionPolicy returns the policy denying the deletion of the provider CRDs installed by the operator,
+// labeled as managed by it, which would delete all their custom resources, such as the CAPI Machines. The deletion of
+// the CRDs with the AllowCRDDeletionAnnotation is allowed.
+func desiredCRDProtectionPolicy() *admissionregistrationv1beta1.ValidatingAdmissionPolicy {
+	return &admissionregistrationv1beta1.ValidatingAdmissionPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: CRDProtectionPolicyName, Labels: managedByLabels()},
+		Spec: admissionregistrationv1beta1.ValidatingAdmissionPolicySpec{
+			MatchConstraints: &admissionregistrationv1beta1.MatchResources{
+				ObjectSelector: &metav1.LabelSelector{MatchLabels: managedByLabels()},
+				ResourceRules: []admissionregistrationv1beta1.NamedRuleWithOperations{{
+					RuleWithOperations: admissionregistrationv1beta1.RuleWithOperations{
+						Operations: []admissionregistrationv1beta1.OperationType{admissionregistrationv1beta1.Delete},
+						Rule: admissionregistrationv1beta1.Rule{
+							APIGroups:   []string{"apiextensions.k8s.io"},
+							APIVersions: []string{"*"},
+							Resources:   []string{"customresourcedefinitions"},
+							Scope:       ptr.To(admissionregistrationv1beta1.ClusterScope),
+						},
+					},
+				}},
+				MatchPolicy: ptr.To(admissionregistrationv1beta1.Equivalent),
+			},
+			Validations: []admissionregistrationv1beta1.Validation{{
+				Expression: fmt.Sprintf("oldObject.metadata.?annotations[?'%s'].orValue('') == 'true'", consts.AllowCRDDeletionAnnotation),
+				Message: fmt.Sprintf("deleting the CRD deletes all its custom resources, "+
+					"set the %s=true annotation on it to delete it anyway", consts.AllowCRDDeletionAnnotation),
+			}},
+			FailurePolicy: ptr.To(admissionregistrationv1beta1.Fail),
+		},
+	}
+}
+
+// desiredBindings returns the bindings denying the requests failing the named policies.
+func desiredBindings(names ...string) []*admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding {
+	bindings := []*admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding{}
+
+	for _, name := range names {
+		bindings = append(bindings, &admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: managedByLabels()},
+			Spec: admissionregistrationv1beta1.ValidatingAdmissionPolicyBindingSpec{
+				PolicyName:        name,
+				ValidationActions: []admissionregistrationv1beta1.ValidationAction{admissionregistrationv1beta1.Deny},
+			},
+		})
+	}
+
+	return bindings
+}
+
+// desiredMigrationBindings returns the bindings of the policies protecting the migration, a set for each namespace
+// pair restricted to its namespaces. The policies protecting the CAPI MachineSets are evaluated against the MAPI or
+// CAPI MachineSets of the pair, and the one protecting the CAPI Machines against its MAPI Machines, a missing
+// MachineSet or Machine allows the request.
+func desiredMigrationBindings(pairs []configv1alpha1.NamespacePair) []*admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding {
+	bindings := []*admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding{}
+
+	for i, pair := range pairs {
+		pairBindings := append(desiredBindings(AuthoritativeAPIPolicyName, CAPIMirrorPolicyName, MAPIMachineDeletionPolicyName),
+			paramBinding(CAPIMachineSetAuthorityPolicyName, pair.MAPINamespace, admissionregistrationv1beta1.Deny),
+			paramBinding(CAPIMachineSetSyncWarningPolicyName, pair.MAPINamespace, admissionregistrationv1beta1.Warn),
+			paramBinding(InfraMachineTemplateDeletionPolicyName, pair.CAPINamespace, admissionregistrationv1beta1.Deny),
+			paramBinding(CAPIMachineDeletionPolicyName, pair.MAPINamespace, admissionregistrationv1beta1.Deny),
+		)
+
+		for _, binding := range pairBindings {
+			// The bindings of the first pair keep the name of their policy, so that they are not recreated when pairs
+			// are added.
+			if i > 0 {
+				binding.SetName(binding.GetName() + "-" + pair.MAPINamespace)
+			}
+
+			binding.Spec.MatchResources = &admissionregistrationv1beta1.MatchResources{
+				NamespaceSelector: namespaceSelector(pair.MAPINamespace, pair.CAPINamespace),
+				ObjectSelector:    &metav1.LabelSelector{},
+				MatchPolicy:       ptr.To(admissionregistrationv1beta1.Equivalent),
+			}
+		}
+
+		bindings = append(bindings, pairBindings...)
+	}
+
+	return bindings
+}
+
+// paramBinding returns the binding of the named policy, evaluating it against each of its params in the namespace.
+func paramBinding(name, paramNamespace string, actions ...admissionregistrationv1beta1.ValidationAction) *admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding {
+	return &admissionregistrationv1beta1.ValidatingAdmissionPolicyBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: managedByLabels()},
+		Spec: admissionregistrationv1beta1.ValidatingAdmissionPolicyBindingSpec{
+			PolicyName: name,
+			ParamRef: &admissionregistrationv1beta1.ParamRef{
+				Namespace:               paramNamespace,
+				Selector:                &metav1.LabelSelector{},
+				ParameterNotFoundAction: ptr.To(admissionregistrationv1beta1.AllowAction),
+			},
+			ValidationActions: actions,
+		},
+	}
+}
+
+// managedByLabels returns the labels of the policies and bindings, marking them as managed by the operator.
+func managedByLabels() map[string]string {
+	return map[string]string{consts.ManagedByLabel: consts.ManagedByLabelValue}
+}
+
+// matchConstraints matches the requests of the operation on the given resources of the API group in the namespaces.
+func matchConstraints(namespaces []string, apiGroup string, operation admissionregistrationv1beta1.OperationType, resources ...string) *admissionregistrationv1beta1.MatchResources {
+	return &admissionregistrationv1beta1.MatchResources{
+		NamespaceSelector: namespaceSelector(namespaces...),
+		ObjectSelector:    &metav1.LabelSelector{},
+		ResourceRules:     []admissionregistrationv1beta1.NamedRuleWithOperations{resourceRule(apiGroup, operation, resources...)},
+		MatchPolicy:       ptr.To(admissionregistrationv1beta1.Equivalent),
+	}
+}
+
+// namespaceSelector selects the given namespaces by their name label.
+func namespaceSelector(namespaces ...string) *metav1.LabelSelector {
+	if len(namespaces) == 1 {
+		return &metav1.LabelSelector{MatchLabels: map[string]string{corev1.LabelMetadataName: namespaces[0]}}
+	}
+
+	return &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+		Key:      corev1.LabelMetadataName,
+		Operator: metav1.LabelSelectorOpIn,
+		Values:   namespaces,
+	}}}
+}
+
+// machineSetMatchConstraints matches the requests of the operation on the CAPI MachineSets and InfraMachineTemplates
+// in the CAPI namespaces.
+func machineSetMatchConstraints(capiNamespaces []string, operation admissionregistrationv1beta1.OperationType) *admissionregistrationv1beta1.MatchResources {
+	constraints := matchConstraints(capiNamespaces, "cluster.x-k8s.io", operation, "machinesets")
+	constraints.ResourceRules = append(constraints.ResourceRules, resourceRule("infrastructure.cluster.x-k8s.io", operation, infraMachineTemplateResources...))
+
+	return constraints
+}
+
+// resourceRule matches the requests of the operation on the given namespaced resources of the API group.
+func resourceRule(apiGroup string, operation admissionregistrationv1beta1.OperationType, resources ...string) admissionregistrationv1beta1.NamedRuleWithOperations {
+	return admissionregistrationv1beta1.NamedRuleWithOperations{
+		RuleWithOperations: admissionregistrationv1beta1.RuleWithOperations{
+			Operations: []admissionregistrationv1beta1.OperationType{operation},
+			Rule: admissionregistrationv1beta1.Rule{
+				APIGroups:   []string{apiGroup},
+				APIVersions: []string{"*"},
+				Resources:   resources,
+				Scope:       ptr.To(admissionregistrationv1beta1.NamespacedScope),
+			},
+		},
+	}
+}
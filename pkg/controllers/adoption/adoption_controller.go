@@ -0,0 +1,256 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package adoption
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+)
+
+const (
+	controllerName = "AdoptionController"
+
+	// verificationRetryDelay is how long to wait before verifying again a machine that could not be adopted,
+	// as the InfraMachines it depends on are not watched.
+	verificationRetryDelay = 5 * time.Minute
+
+	reasonAdopted    = "Adopted"
+	reasonNotAdopted = "NotAdopted"
+)
+
+// AdoptionReconciler adopts the CAPI Machines created out of band in the CAPI namespace, typically by users who
+// experimented with upstream Cluster API before the operator managed the namespace.
+// A machine is unmanaged when it is neither a copy written by the sync controllers, nor paired with a MAPI Machine,
+// nor controlled by another resource, such as a MachineSet. It is only adopted once verified to belong to the cluster
+// managed by the operator and to be backed by an instance. Adopted machines are labelled with consts.AdoptedLabel,
+// owned by the cluster and unpaused, as nothing else would ever unpause a CAPI Machine without a MAPI counterpart.
+type AdoptionReconciler struct {
+	client.Client
+	Recorder record.EventRecorder
+
+	Infra         *configv1.Infrastructure
+	MAPINamespace string
+	CAPINamespace string
+
+	// Shard restricts the reconciler to a subset of the machines, so that the work can be split across replicas.
+	Shard util.Shard
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *AdoptionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named(util.NamespacedControllerName(controllerName, r.MAPINamespace)).
+		For(&capiv1beta1.Machine{}, builder.WithPredicates(util.FilterNamespace(r.CAPINamespace), util.FilterShard(r.Shard))).
+		// The cluster is watched so that the machines waiting for it are adopted as soon as it is created.
+		Watches(
+			&capiv1beta1.Cluster{},
+			handler.EnqueueRequestsFromMapFunc(r.mapClusterToMachines),
+			builder.WithPredicates(util.FilterNamespace(r.CAPINamespace)),
+		).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create controller: %w", err)
+	}
+
+	r.Client = mgr.GetClient()
+	r.Recorder = mgr.GetEventRecorderFor("adoption-controller")
+
+	return nil
+}
+
+// Reconcile adopts the CAPI Machine when it is unmanaged and verified.
+func (r *AdoptionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := ctrl.LoggerFrom(ctx).WithName(controllerName)
+	ctx = ctrl.LoggerInto(ctx, logger)
+
+	capiMachine := &capiv1beta1.Machine{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: r.CAPINamespace, Name: req.Name}, capiMachine); apierrors.IsNotFound(err) {
+		return ctrl.Result{}, nil
+	} else if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get CAPI machine: %w", err)
+	}
+
+	if !capiMachine.GetDeletionTimestamp().IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	if managed, err := r.isManaged(ctx, capiMachine); err != nil || managed {
+		return ctrl.Result{}, err
+	}
+
+	cluster, infraMachine, reason, err := r.verify(ctx, capiMachine)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if reason != "" {
+		logger.Info("Not adopting unmanaged CAPI machine", "reason", reason)
+		r.Recorder.Event(capiMachine, corev1.EventTypeWarning, reasonNotAdopted, reason)
+
+		return ctrl.Result{RequeueAfter: verificationRetryDelay}, nil
+	}
+
+	return ctrl.Result{}, r.adopt(ctx, capiMachine, cluster, infraMachine)
+}
+
+// isManaged returns whether the CAPI Machine is already managed, by the operator or by another resource.
+func (r *AdoptionReconciler) isManaged(ctx context.Context, capiMachine *capiv1beta1.Machine) (bool, error) {
+	if capiMachine.GetLabels()[consts.AdoptedLabel] == "true" {
+		return true, nil
+	}
+
+	if _, synchronized := capiMachine.GetAnnotations()[consts.LastSyncTimeAnnotation]; synchronized {
+		// Written by the sync controllers, the mirror cleanup controller takes care of it once orphaned.
+		return true, nil
+	}
+
+	if metav1.GetControllerOf(capiMachine) != nil {
+		// Machines controlled by a MachineSet, or by anything else, follow their controller.
+		return true, nil
+	}
+
+	mapiMachine := &machinev1beta1.Machine{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: r.MAPINamespace, Name: capiMachine.GetName()}, mapiMachine); err == nil {
+		// The sync controllers are in charge of the machines that have a MAPI counterpart.
+		return true, nil
+	} else if !apierrors.IsNotFound(err) {
+		return false, fmt.Errorf("failed to get MAPI machine: %w", err)
+	}
+
+	return false, nil
+}
+
+// verify checks the CAPI Machine belongs to the cluster managed by the operator and is backed by an instance,
+// and returns the cluster and the InfraMachine of the machine. When it cannot be adopted, the reason is returned instead.
+func (r *AdoptionReconciler) verify(ctx context.Context, capiMachine *capiv1beta1.Machine) (*capiv1beta1.Cluster, *unstructured.Unstructured, string, error) {
+	clusterName := r.Infra.Status.InfrastructureName
+	if capiMachine.Spec.ClusterName != clusterName {
+		return nil, nil, fmt.Sprintf("Machine belongs to cluster %q, only the machines of cluster %q managed by the operator are adopted",
+			capiMachine.Spec.ClusterName, clusterName), nil
+	}
+
+	cluster := &capiv1beta1.Cluster{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: r.CAPINamespace, Name: clusterName}, cluster); apierrors.IsNotFound(err) {
+		return nil, nil, fmt.Sprintf("Cluster %q does not exist yet", clusterName), nil
+	} else if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to get cluster: %w", err)
+	}
+
+	ref := capiMachine.Spec.InfrastructureRef
+	if ref.Name == "" {
+		return nil, nil, "Machine has no InfraMachine", nil
+	}
+
+	infraMachine := &unstructured.Unstructured{}
+	infraMachine.SetGroupVersionKind(ref.GroupVersionKind())
+
+	if err := r.Get(ctx, client.ObjectKey{Namespace: r.CAPINamespace, Name: ref.Name}, infraMachine); apierrors.IsNotFound(err) {
+		return nil, nil, fmt.Sprintf("%s %s does not exist", ref.Kind, ref.Name), nil
+	} else if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to get InfraMachine %s %s: %w", ref.Kind, ref.Name, err)
+	}
+
+	if providerID, _, _ := unstructured.NestedString(infraMachine.Object, "spec", "providerID"); providerID == "" {
+		return nil, nil, fmt.Sprintf("%s %s has no provider ID, it is not backed by an instance", ref.Kind, ref.Name), nil
+	}
+
+	return cluster, infraMachine, "", nil
+}
+
+// adopt labels the InfraMachine and the CAPI Machine as adopted, makes the cluster own the machine and unpauses it.
+// The machine is labelled last, so that an adoption failing half way is retried.
+func (r *AdoptionReconciler) adopt(ctx context.Context, capiMachine *capiv1beta1.Machine, cluster *capiv1beta1.Cluster, infraMachine *unstructured.Unstructured) error {
+	logger := ctrl.LoggerFrom(ctx)
+
+	infraPatchBase := client.MergeFrom(infraMachine.DeepCopy())
+	infraMachine.SetLabels(util.MergeMaps(infraMachine.GetLabels(), map[string]string{consts.AdoptedLabel: "true"}))
+
+	if err := r.Patch(ctx, infraMachine, infraPatchBase); err != nil {
+		return fmt.Errorf("failed to label InfraMachine %s as adopted: %w", infraMachine.GetName(), err)
+	}
+
+	patchBase := client.MergeFrom(capiMachine.DeepCopy())
+	capiMachine.SetLabels(util.MergeMaps(capiMachine.GetLabels(), map[string]string{
+		consts.AdoptedLabel:          "true",
+		capiv1beta1.ClusterNameLabel: cluster.GetName(),
+	}))
+
+	if err := controllerutil.SetOwnerReference(cluster, capiMachine, r.Scheme()); err != nil {
+		return fmt.Errorf("failed to set cluster owner reference: %w", err)
+	}
+
+	paused := annotations.HasPaused(capiMachine)
+	if paused {
+		delete(capiMachine.Annotations, capiv1beta1.PausedAnnotation)
+	}
+
+	if err := r.Patch(ctx, capiMachine, patchBase); err != nil {
+		return fmt.Errorf("failed to adopt CAPI machine: %w", err)
+	}
+
+	logger.Info("Adopted unmanaged CAPI machine", "unpaused", paused)
+
+	message := fmt.Sprintf("Adopted by the operator as part of cluster %s", cluster.GetName())
+	if paused {
+		message += ", and unpaused as it has no Machine API counterpart"
+	}
+
+	r.Recorder.Event(capiMachine, corev1.EventTypeNormal, reasonAdopted, message)
+
+	return nil
+}
+
+// mapClusterToMachines requeues the CAPI Machines of the cluster owned by the shard, so that the ones waiting for it are adopted.
+func (r *AdoptionReconciler) mapClusterToMachines(ctx context.Context, obj client.Object) []reconcile.Request {
+	capiMachines := &capiv1beta1.MachineList{}
+	if err := r.List(ctx, capiMachines, client.InNamespace(r.CAPINamespace)); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "Failed to list CAPI machines to requeue after a cluster change")
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+
+	for _, capiMachine := range capiMachines.Items {
+		if capiMachine.Spec.ClusterName != obj.GetName() || !r.Shard.Owns(capiMachine.GetName()) {
+			continue
+		}
+
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKey{Namespace: r.CAPINamespace, Name: capiMachine.GetName()},
+		})
+	}
+
+	return requests
+}
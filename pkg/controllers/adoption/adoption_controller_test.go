@@ -0,0 +1,182 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package adoption
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	capiv1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/core/v1beta1"
+	capav1builder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/infrastructure/v1beta2"
+	corev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/core/v1"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	capav1beta2 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest/komega"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/test"
+)
+
+var _ = Describe("Adoption controller", func() {
+	const (
+		machineName = "foo"
+		clusterName = "cluster-foo"
+	)
+
+	var k komega.Komega
+	var reconciler *AdoptionReconciler
+	var recorder *record.FakeRecorder
+
+	var mapiNamespace, capiNamespace *corev1.Namespace
+	var mapiMachine *machinev1beta1.Machine
+	var capiMachine *capiv1beta1.Machine
+	var awsMachine *capav1beta2.AWSMachine
+	var cluster *capiv1beta1.Cluster
+
+	var awsMachineBuilder capav1builder.AWSMachineBuilder
+	var capiMachineBuilder capiv1resourcebuilder.MachineBuilder
+
+	reconcileMachine := func() reconcile.Result {
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{
+			NamespacedName: client.ObjectKey{Namespace: capiNamespace.GetName(), Name: machineName},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		return result
+	}
+
+	createMachines := func() {
+		awsMachine = awsMachineBuilder.Build()
+		Expect(cl.Create(ctx, awsMachine)).To(Succeed())
+
+		capiMachine = capiMachineBuilder.Build()
+		Expect(cl.Create(ctx, capiMachine)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		k = komega.New(cl)
+
+		By("Setting up the namespaces for the test")
+		mapiNamespace = corev1resourcebuilder.Namespace().WithGenerateName("openshift-machine-api-").Build()
+		Expect(cl.Create(ctx, mapiNamespace)).To(Succeed())
+
+		capiNamespace = corev1resourcebuilder.Namespace().WithGenerateName("openshift-cluster-api-").Build()
+		Expect(cl.Create(ctx, capiNamespace)).To(Succeed())
+
+		recorder = record.NewFakeRecorder(32)
+
+		reconciler = &AdoptionReconciler{
+			Client:   cl,
+			Recorder: recorder,
+			Infra: &configv1.Infrastructure{
+				Status: configv1.InfrastructureStatus{InfrastructureName: clusterName},
+			},
+			MAPINamespace: mapiNamespace.GetName(),
+			CAPINamespace: capiNamespace.GetName(),
+		}
+
+		By("Creating the cluster managed by the operator")
+		cluster = capiv1resourcebuilder.Cluster().WithNamespace(capiNamespace.GetName()).WithName(clusterName).Build()
+		Expect(cl.Create(ctx, cluster)).To(Succeed())
+
+		mapiMachine = machinev1resourcebuilder.Machine().
+			WithNamespace(mapiNamespace.GetName()).
+			WithName(machineName).
+			WithProviderSpecBuilder(machinev1resourcebuilder.AWSProviderSpec()).
+			Build()
+
+		awsMachineBuilder = capav1builder.AWSMachine().
+			WithNamespace(capiNamespace.GetName()).
+			WithName(machineName).
+			WithInstanceType("m5.large").
+			WithProviderID(ptr.To("aws:///us-east-1a/i-0123456789"))
+
+		capiMachineBuilder = capiv1resourcebuilder.Machine().
+			WithNamespace(capiNamespace.GetName()).
+			WithName(machineName).
+			WithClusterName(clusterName).
+			WithAnnotations(map[string]string{capiv1beta1.PausedAnnotation: ""}).
+			WithInfrastructureRef(corev1.ObjectReference{
+				APIVersion: capav1beta2.GroupVersion.String(),
+				Kind:       "AWSMachine",
+				Name:       machineName,
+			})
+	})
+
+	AfterEach(func() {
+		Expect(test.CleanupAndWait(ctx, cl, mapiMachine, capiMachine, awsMachine, cluster)).To(Succeed())
+	})
+
+	It("should adopt and unpause an unmanaged machine backed by an instance", func() {
+		createMachines()
+
+		Expect(reconcileMachine()).To(Equal(reconcile.Result{}))
+
+		Eventually(k.Object(capiMachine)).Should(SatisfyAll(
+			HaveField("Labels", HaveKeyWithValue(consts.AdoptedLabel, "true")),
+			HaveField("Labels", HaveKeyWithValue(capiv1beta1.ClusterNameLabel, clusterName)),
+			HaveField("Annotations", Not(HaveKey(capiv1beta1.PausedAnnotation))),
+			HaveField("OwnerReferences", ContainElement(HaveField("Name", clusterName))),
+		))
+		Eventually(k.Object(awsMachine)).Should(HaveField("Labels", HaveKeyWithValue(consts.AdoptedLabel, "true")))
+		Expect(<-recorder.Events).To(ContainSubstring(reasonAdopted))
+	})
+
+	It("should not adopt a machine that is not backed by an instance", func() {
+		awsMachineBuilder = awsMachineBuilder.WithProviderID(nil)
+		createMachines()
+
+		Expect(reconcileMachine().RequeueAfter).To(Equal(verificationRetryDelay))
+
+		Consistently(k.Object(capiMachine), time.Second).Should(SatisfyAll(
+			HaveField("Labels", Not(HaveKey(consts.AdoptedLabel))),
+			HaveField("Annotations", HaveKey(capiv1beta1.PausedAnnotation)),
+		))
+		Expect(<-recorder.Events).To(ContainSubstring(reasonNotAdopted))
+	})
+
+	It("should not adopt a machine that belongs to another cluster", func() {
+		capiMachineBuilder = capiMachineBuilder.WithClusterName("other-cluster")
+		createMachines()
+
+		Expect(reconcileMachine().RequeueAfter).To(Equal(verificationRetryDelay))
+
+		Consistently(k.Object(capiMachine), time.Second).Should(HaveField("Labels", Not(HaveKey(consts.AdoptedLabel))))
+		Expect(<-recorder.Events).To(ContainSubstring(reasonNotAdopted))
+	})
+
+	It("should leave a machine with a MAPI counterpart to the sync controllers", func() {
+		Expect(cl.Create(ctx, mapiMachine)).To(Succeed())
+		createMachines()
+
+		Expect(reconcileMachine()).To(Equal(reconcile.Result{}))
+
+		Consistently(k.Object(capiMachine), time.Second).Should(SatisfyAll(
+			HaveField("Labels", Not(HaveKey(consts.AdoptedLabel))),
+			HaveField("Annotations", HaveKey(capiv1beta1.PausedAnnotation)),
+		))
+		Expect(recorder.Events).To(BeEmpty())
+	})
+})
@@ -0,0 +1,300 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bootstrapsecret
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
+)
+
+const (
+	// MachineConfigPoolAnnotation opts a CAPI MachineSet into a generated bootstrap data secret,
+	// pointing its Machines at the Machine Config Server endpoint of the named MachineConfigPool.
+	MachineConfigPoolAnnotation = "cluster-api.openshift.io/machine-config-pool"
+
+	// BootstrapSecretForLabel is set on the generated bootstrap data secrets, with the name of the MachineSet they belong to.
+	BootstrapSecretForLabel = "cluster-api.openshift.io/bootstrap-secret-for"
+
+	// templateUserDataSecretName is the worker user data secret synced by the secret sync controller.
+	// Its pointer ignition config is used as the template for the generated secrets.
+	templateUserDataSecretName = "worker-user-data"
+	userDataSecretSuffix       = "-user-data"
+
+	// machineConfigServerPathPrefix is the path the Machine Config Server serves the config of a pool at.
+	machineConfigServerPathPrefix = "/config/"
+
+	// Controller conditions for the Cluster Operator resource.
+	bootstrapSecretControllerAvailableCondition = "BootstrapSecretControllerAvailable"
+	bootstrapSecretControllerDegradedCondition  = "BootstrapSecretControllerDegraded"
+
+	capiUserDataKey = "value"
+	capiFormatKey   = "format"
+	ignitionFormat  = "ignition"
+	controllerName  = "BootstrapSecretController"
+)
+
+var (
+	errTemplateMissingUserData      = errors.New("template user data secret does not have user data")
+	errTemplateMissingConfigSources = errors.New("template user data does not merge any Machine Config Server config")
+)
+
+// UserDataSecretName returns the name of the bootstrap data secret generated for a MachineSet.
+func UserDataSecretName(machineSetName string) string {
+	return machineSetName + userDataSecretSuffix
+}
+
+// BootstrapSecretController generates a bootstrap data secret for each CAPI MachineSet carrying the
+// MachineConfigPoolAnnotation, and points the MachineSet template at it when no bootstrap data is set.
+// This lets users create MachineSets for any MachineConfigPool without knowing the user data secret conventions.
+type BootstrapSecretController struct {
+	operatorstatus.ClusterOperatorStatusClient
+	Scheme *runtime.Scheme
+}
+
+// Reconcile reconciles the bootstrap data secret of a MachineSet.
+func (r *BootstrapSecretController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx).WithName(controllerName).WithValues("machineSet", req.Name)
+	log.Info("reconciling bootstrap data secret")
+
+	machineSet := &clusterv1.MachineSet{}
+	if err := r.Get(ctx, req.NamespacedName, machineSet); apierrors.IsNotFound(err) {
+		// The generated secret is owned by the MachineSet, so it is garbage collected along with it.
+		log.Info("machine set not found, nothing to do")
+		return ctrl.Result{}, nil
+	} else if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get machine set: %w", err)
+	}
+
+	pool, ok := machineSet.GetAnnotations()[MachineConfigPoolAnnotation]
+	if !ok || pool == "" || !machineSet.GetDeletionTimestamp().IsZero() {
+		log.Info("machine set does not request a bootstrap data secret, nothing to do")
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.reconcileBootstrapSecret(ctx, machineSet, pool); err != nil {
+		log.Error(err, "unable to reconcile bootstrap data secret")
+
+		if err := r.setDegradedCondition(ctx, log, err); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to set conditions for bootstrap secret controller: %w", err)
+		}
+
+		return ctrl.Result{}, err
+	}
+
+	if err := r.setAvailableCondition(ctx, log); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to set conditions for bootstrap secret controller: %w", err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// reconcileBootstrapSecret writes the bootstrap data secret of the MachineSet, then sets it on the MachineSet template
+// when the template does not have any bootstrap data yet.
+func (r *BootstrapSecretController) reconcileBootstrapSecret(ctx context.Context, machineSet *clusterv1.MachineSet, pool string) error {
+	templateSecret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: r.ManagedNamespace, Name: templateUserDataSecretName}, templateSecret); err != nil {
+		return fmt.Errorf("failed to get template user data secret: %w", err)
+	}
+
+	userData, err := poolUserData(templateSecret.Data[capiUserDataKey], pool)
+	if err != nil {
+		return err
+	}
+
+	secret := &corev1.Secret{}
+	secret.SetName(UserDataSecretName(machineSet.GetName()))
+	secret.SetNamespace(machineSet.GetNamespace())
+
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		labels := secret.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+
+		labels[BootstrapSecretForLabel] = machineSet.GetName()
+		labels[controllers.ManagedByLabel] = controllers.ManagedByLabelValue
+		secret.SetLabels(labels)
+
+		secret.Data = map[string][]byte{
+			capiUserDataKey: userData,
+			capiFormatKey:   []byte(ignitionFormat),
+		}
+
+		return controllerutil.SetControllerReference(machineSet, secret, r.Scheme)
+	}); err != nil {
+		return fmt.Errorf("failed to write bootstrap data secret: %w", err)
+	}
+
+	bootstrap := machineSet.Spec.Template.Spec.Bootstrap
+	if bootstrap.ConfigRef != nil || bootstrap.DataSecretName != nil {
+		return nil
+	}
+
+	patchBase := client.MergeFrom(machineSet.DeepCopy())
+	machineSet.Spec.Template.Spec.Bootstrap.DataSecretName = ptr.To(secret.GetName())
+
+	if err := r.Patch(ctx, machineSet, patchBase); err != nil {
+		return fmt.Errorf("failed to set bootstrap data secret on machine set: %w", err)
+	}
+
+	return nil
+}
+
+// poolUserData returns the pointer ignition config of the template, with the Machine Config Server
+// config sources rewritten to serve the config of the given pool.
+// The rest of the template, such as the Machine Config Server CA, is kept as is.
+func poolUserData(template []byte, pool string) ([]byte, error) {
+	if len(template) == 0 {
+		return nil, errTemplateMissingUserData
+	}
+
+	config := map[string]interface{}{}
+	if err := json.Unmarshal(template, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse template user data: %w", err)
+	}
+
+	ignition, _ := config["ignition"].(map[string]interface{})
+	ignitionConfig, _ := ignition["config"].(map[string]interface{})
+	merge, _ := ignitionConfig["merge"].([]interface{})
+
+	rewritten := 0
+
+	for _, m := range merge {
+		source, _ := m.(map[string]interface{})
+
+		url, ok := source["source"].(string)
+		if !ok {
+			continue
+		}
+
+		i := strings.LastIndex(url, machineConfigServerPathPrefix)
+		if i < 0 {
+			continue
+		}
+
+		source["source"] = url[:i] + machineConfigServerPathPrefix + pool
+		rewritten++
+	}
+
+	if rewritten == 0 {
+		return nil, errTemplateMissingConfigSources
+	}
+
+	var out bytes.Buffer
+
+	encoder := json.NewEncoder(&out)
+	// Keep the data: URLs of the template readable, the default encoder would escape them.
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(config); err != nil {
+		return nil, fmt.Errorf("failed to encode user data: %w", err)
+	}
+
+	return bytes.TrimSpace(out.Bytes()), nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BootstrapSecretController) SetupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named(controllerName).
+		For(
+			&clusterv1.MachineSet{},
+			builder.WithPredicates(machineSetPredicate(r.ManagedNamespace)),
+		).
+		Owns(
+			&corev1.Secret{},
+			builder.OnlyMetadata,
+		).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.toMachineSetsWithPool),
+			builder.WithPredicates(templateSecretPredicate(r.ManagedNamespace)),
+			builder.OnlyMetadata,
+		).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create controller: %w", err)
+	}
+
+	return nil
+}
+
+func (r *BootstrapSecretController) setAvailableCondition(ctx context.Context, log logr.Logger) error {
+	co, err := r.GetOrCreateClusterOperator(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get cluster operator: %w", err)
+	}
+
+	conds := []configv1.ClusterOperatorStatusCondition{
+		operatorstatus.NewClusterOperatorStatusCondition(bootstrapSecretControllerAvailableCondition, configv1.ConditionTrue, operatorstatus.ReasonAsExpected,
+			"Bootstrap Secret Controller works as expected"),
+		operatorstatus.NewClusterOperatorStatusCondition(bootstrapSecretControllerDegradedCondition, configv1.ConditionFalse, operatorstatus.ReasonAsExpected,
+			"Bootstrap Secret Controller works as expected"),
+	}
+
+	r.SetOperatorVersion(co)
+
+	log.Info("Bootstrap Secret Controller is available")
+
+	if err := r.SyncStatus(ctx, co, conds); err != nil {
+		return fmt.Errorf("failed to sync status: %w", err)
+	}
+
+	return nil
+}
+
+func (r *BootstrapSecretController) setDegradedCondition(ctx context.Context, log logr.Logger, reconcileErr error) error {
+	co, err := r.GetOrCreateClusterOperator(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get cluster operator: %w", err)
+	}
+
+	conds := []configv1.ClusterOperatorStatusCondition{
+		operatorstatus.NewClusterOperatorStatusCondition(bootstrapSecretControllerAvailableCondition, configv1.ConditionFalse, operatorstatus.ReasonSyncFailed,
+			fmt.Sprintf("Bootstrap Secret Controller failed to generate bootstrap data secret: %v", reconcileErr)),
+		operatorstatus.NewClusterOperatorStatusCondition(bootstrapSecretControllerDegradedCondition, configv1.ConditionTrue, operatorstatus.ReasonSyncFailed,
+			fmt.Sprintf("Bootstrap Secret Controller failed to generate bootstrap data secret: %v", reconcileErr)),
+	}
+
+	r.SetOperatorVersion(co)
+
+	log.Info("Bootstrap Secret Controller is degraded")
+
+	if err := r.SyncStatus(ctx, co, conds); err != nil {
+		return fmt.Errorf("failed to sync status: %w", err)
+	}
+
+	return nil
+}
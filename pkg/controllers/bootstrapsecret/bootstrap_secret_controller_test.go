@@ -0,0 +1,223 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bootstrapsecret
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/config"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
+	"github.com/openshift/cluster-capi-operator/pkg/test"
+)
+
+const (
+	workerUserData = `{"ignition":{"config":{"merge":[{"source":"https://api-int.example.com:22623/config/worker"}]},` +
+		`"security":{"tls":{"certificateAuthorities":[{"source":"data:text/plain;charset=utf-8;base64,Zm9v"}]}},"version":"3.2.0"}}`
+	infraUserData = `{"ignition":{"config":{"merge":[{"source":"https://api-int.example.com:22623/config/infra"}]},` +
+		`"security":{"tls":{"certificateAuthorities":[{"source":"data:text/plain;charset=utf-8;base64,Zm9v"}]}},"version":"3.2.0"}}`
+
+	timeout = time.Second * 10
+)
+
+func makeTemplateSecret() *corev1.Secret {
+	return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name:      templateUserDataSecretName,
+		Namespace: controllers.DefaultManagedNamespace,
+	}, Data: map[string][]byte{capiUserDataKey: []byte(workerUserData), capiFormatKey: []byte(ignitionFormat)}}
+}
+
+func makeMachineSet(pool string) *clusterv1.MachineSet {
+	return &clusterv1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "infra-machineset",
+			Namespace:   controllers.DefaultManagedNamespace,
+			Annotations: map[string]string{MachineConfigPoolAnnotation: pool},
+		},
+		Spec: clusterv1.MachineSetSpec{
+			ClusterName: "cluster-foo",
+			Template: clusterv1.MachineTemplateSpec{
+				Spec: clusterv1.MachineSpec{
+					ClusterName: "cluster-foo",
+					InfrastructureRef: corev1.ObjectReference{
+						Kind: "AWSMachineTemplate",
+						Name: "infra-template",
+					},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("poolUserData", func() {
+	It("should point the Machine Config Server config source at the pool", func() {
+		userData, err := poolUserData([]byte(workerUserData), "infra")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(userData).To(MatchJSON(infraUserData))
+	})
+
+	It("should fail when the template has no user data", func() {
+		_, err := poolUserData(nil, "infra")
+		Expect(err).To(MatchError(errTemplateMissingUserData))
+	})
+
+	It("should fail when the template does not merge a Machine Config Server config", func() {
+		_, err := poolUserData([]byte(`{"ignition":{"version":"3.2.0"}}`), "infra")
+		Expect(err).To(MatchError(errTemplateMissingConfigSources))
+	})
+})
+
+var _ = Describe("Bootstrap Secret controller", func() {
+	var rec *record.FakeRecorder
+
+	var mgrCtxCancel context.CancelFunc
+	var mgrStopped chan struct{}
+	ctx := context.Background()
+
+	var templateSecret *corev1.Secret
+	var machineSet *clusterv1.MachineSet
+
+	generatedSecretKey := client.ObjectKey{Namespace: controllers.DefaultManagedNamespace, Name: UserDataSecretName("infra-machineset")}
+
+	BeforeEach(func() {
+		By("Setting up a manager and controller")
+		mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+			Controller: config.Controller{
+				SkipNameValidation: ptr.To(true),
+			},
+		})
+		Expect(err).ToNot(HaveOccurred(), "Manager should be able to be created")
+
+		reconciler := &BootstrapSecretController{
+			ClusterOperatorStatusClient: operatorstatus.ClusterOperatorStatusClient{
+				Client:           cl,
+				Recorder:         rec,
+				ManagedNamespace: controllers.DefaultManagedNamespace,
+			},
+			Scheme: scheme.Scheme,
+		}
+		Expect(reconciler.SetupWithManager(mgr)).To(Succeed())
+
+		By("Creating the template user data secret")
+		templateSecret = makeTemplateSecret()
+		Expect(cl.Create(ctx, templateSecret)).To(Succeed())
+
+		var mgrCtx context.Context
+		mgrCtx, mgrCtxCancel = context.WithCancel(ctx)
+		mgrStopped = make(chan struct{})
+
+		By("Starting the manager")
+		go func() {
+			defer GinkgoRecover()
+			defer close(mgrStopped)
+
+			Expect(mgr.Start(mgrCtx)).To(Succeed())
+		}()
+	})
+
+	AfterEach(func() {
+		By("Closing the manager")
+		mgrCtxCancel()
+		Eventually(mgrStopped, timeout).Should(BeClosed())
+
+		co := &configv1.ClusterOperator{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: controllers.ClusterOperatorName,
+			},
+		}
+
+		By("Cleanup resources")
+		Expect(test.CleanupAndWait(ctx, cl, co, machineSet, templateSecret, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name: generatedSecretKey.Name, Namespace: generatedSecretKey.Namespace,
+		}})).To(Succeed())
+	})
+
+	It("should generate the bootstrap data secret and set it on the machine set", func() {
+		machineSet = makeMachineSet("infra")
+		Expect(cl.Create(ctx, machineSet)).To(Succeed())
+
+		Eventually(func(g Gomega) {
+			secret := &corev1.Secret{}
+			g.Expect(cl.Get(ctx, generatedSecretKey, secret)).To(Succeed())
+			g.Expect(secret.Data[capiUserDataKey]).To(MatchJSON(infraUserData))
+			g.Expect(secret.Data[capiFormatKey]).To(BeEquivalentTo(ignitionFormat))
+			g.Expect(secret.GetLabels()).To(HaveKeyWithValue(BootstrapSecretForLabel, machineSet.GetName()))
+			g.Expect(secret.GetOwnerReferences()).To(ContainElement(HaveField("Name", machineSet.GetName())))
+		}, timeout).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machineSet), machineSet)).To(Succeed())
+			g.Expect(machineSet.Spec.Template.Spec.Bootstrap.DataSecretName).To(HaveValue(Equal(generatedSecretKey.Name)))
+		}, timeout).Should(Succeed())
+	})
+
+	It("should not override the bootstrap data secret set by the user", func() {
+		machineSet = makeMachineSet("infra")
+		machineSet.Spec.Template.Spec.Bootstrap.DataSecretName = ptr.To("custom-user-data")
+		Expect(cl.Create(ctx, machineSet)).To(Succeed())
+
+		Eventually(func() error {
+			return cl.Get(ctx, generatedSecretKey, &corev1.Secret{})
+		}, timeout).Should(Succeed())
+
+		Consistently(func(g Gomega) {
+			g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machineSet), machineSet)).To(Succeed())
+			g.Expect(machineSet.Spec.Template.Spec.Bootstrap.DataSecretName).To(HaveValue(Equal("custom-user-data")))
+		}).Should(Succeed())
+	})
+
+	It("should regenerate the bootstrap data secret when the template changes", func() {
+		machineSet = makeMachineSet("infra")
+		Expect(cl.Create(ctx, machineSet)).To(Succeed())
+
+		Eventually(func() error {
+			return cl.Get(ctx, generatedSecretKey, &corev1.Secret{})
+		}, timeout).Should(Succeed())
+
+		changedTemplate := templateSecret.DeepCopy()
+		changedTemplate.Data[capiUserDataKey] = []byte(`{"ignition":{"config":{"merge":[{"source":"https://api-int.other.com:22623/config/worker"}]},"version":"3.2.0"}}`)
+		Expect(cl.Update(ctx, changedTemplate)).To(Succeed())
+
+		Eventually(func(g Gomega) {
+			secret := &corev1.Secret{}
+			g.Expect(cl.Get(ctx, generatedSecretKey, secret)).To(Succeed())
+			g.Expect(secret.Data[capiUserDataKey]).To(MatchJSON(`{"ignition":{"config":{"merge":[{"source":"https://api-int.other.com:22623/config/infra"}]},"version":"3.2.0"}}`))
+		}, timeout).Should(Succeed())
+	})
+
+	It("should ignore machine sets without a machine config pool", func() {
+		machineSet = makeMachineSet("")
+		machineSet.Annotations = nil
+		Expect(cl.Create(ctx, machineSet)).To(Succeed())
+
+		Consistently(func() error {
+			return cl.Get(ctx, generatedSecretKey, &corev1.Secret{})
+		}).ShouldNot(Succeed())
+	})
+})
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bootstrapsecret
+
+import (
+	"context"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// toMachineSetsWithPool enqueues every MachineSet requesting a bootstrap data secret,
+// so the generated secrets follow changes to the template user data secret.
+func (r *BootstrapSecretController) toMachineSetsWithPool(ctx context.Context, _ client.Object) []reconcile.Request {
+	machineSets := &clusterv1.MachineSetList{}
+	if err := r.List(ctx, machineSets, client.InNamespace(r.ManagedNamespace)); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "unable to list machine sets")
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+
+	for _, machineSet := range machineSets.Items {
+		if _, ok := machineSet.GetAnnotations()[MachineConfigPoolAnnotation]; !ok {
+			continue
+		}
+
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&machineSet)})
+	}
+
+	return requests
+}
+
+func machineSetPredicate(namespace string) predicate.Funcs {
+	hasPool := func(obj client.Object) bool {
+		_, ok := obj.GetAnnotations()[MachineConfigPoolAnnotation]
+		return obj.GetNamespace() == namespace && ok
+	}
+
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool { return hasPool(e.Object) },
+		UpdateFunc: func(e event.UpdateEvent) bool { return hasPool(e.ObjectNew) },
+		// The generated secret is garbage collected with the MachineSet, there is nothing to do on deletion.
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return hasPool(e.Object) },
+	}
+}
+
+func templateSecretPredicate(namespace string) predicate.Funcs {
+	isTemplateSecret := func(obj client.Object) bool {
+		return obj.GetNamespace() == namespace && obj.GetName() == templateUserDataSecretName
+	}
+
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return isTemplateSecret(e.Object) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return isTemplateSecret(e.ObjectNew) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return isTemplateSecret(e.Object) },
+		GenericFunc: func(e event.GenericEvent) bool { return isTemplateSecret(e.Object) },
+	}
+}
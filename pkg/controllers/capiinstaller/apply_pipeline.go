@@ -0,0 +1,193 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capiinstaller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+)
+
+const (
+	// applyFieldOwner is the field manager of the provider components applied by the controller.
+	applyFieldOwner = "cluster-capi-operator-capi-installer"
+
+	// legacyFieldManager is the field manager of the updates of the provider components, before they were applied with
+	// server-side apply. It is the user agent of the operator, as the updates did not set a field manager.
+	legacyFieldManager = "cluster-capi-operator"
+
+	// applyWorkers is the number of provider components applied concurrently.
+	applyWorkers = 8
+)
+
+// applyBackoff is the backoff between the attempts to apply a provider component, failing on a transient error.
+//
+//nolint:gochecknoglobals
+var applyBackoff = wait.Backoff{
+	Duration: 200 * time.Millisecond,
+	Factor:   2,
+	Steps:    5,
+	Jitter:   0.1,
+}
+
+// applyPhase orders the provider components so that they are applied after the components they depend on.
+type applyPhase int
+
+const (
+	// applyPhaseNamespaces applies the namespaces, before the namespaced components.
+	applyPhaseNamespaces applyPhase = iota
+	// applyPhaseCRDs applies the CRDs, before the custom resources.
+	applyPhaseCRDs
+	// applyPhaseResources applies the built-in resources, such as the RBAC, Services and webhook configurations.
+	applyPhaseResources
+	// applyPhaseCustomResources applies the custom resources defined by the CRDs of the components.
+	applyPhaseCustomResources
+	// applyPhaseDeployments applies the Deployments last, so that they start with their dependencies in place.
+	applyPhaseDeployments
+)
+
+// applyPhases groups the provider components by apply phase, keeping their order within each phase.
+func applyPhases(objs []*unstructured.Unstructured) [][]*unstructured.Unstructured {
+	customResources := map[schema.GroupKind]bool{}
+
+	for _, obj := range objs {
+		if obj.GroupVersionKind().GroupKind() != apiextensionsv1.Kind("CustomResourceDefinition") {
+			continue
+		}
+
+		group, _, _ := unstructured.NestedString(obj.Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(obj.Object, "spec", "names", "kind")
+		customResources[schema.GroupKind{Group: group, Kind: kind}] = true
+	}
+
+	phases := make([][]*unstructured.Unstructured, applyPhaseDeployments+1)
+
+	for _, obj := range objs {
+		gk := obj.GroupVersionKind().GroupKind()
+
+		phase := applyPhaseResources
+
+		switch {
+		case gk == corev1.SchemeGroupVersion.WithKind("Namespace").GroupKind():
+			phase = applyPhaseNamespaces
+		case gk == apiextensionsv1.Kind("CustomResourceDefinition"):
+			phase = applyPhaseCRDs
+		case customResources[gk]:
+			phase = applyPhaseCustomResources
+		case gk == appsv1.SchemeGroupVersion.WithKind("Deployment").GroupKind():
+			phase = applyPhaseDeployments
+		}
+
+		phases[phase] = append(phases[phase], obj)
+	}
+
+	return phases
+}
+
+// applyComponents applies the provider components with server-side apply, phase by phase.
+// The components of a phase are applied concurrently by up to workers workers, and each of them is retried on
+// transient errors. The objects are updated in place with the applied state.
+// The components of a failing phase are all attempted, but the following phases are not, as they depend on it.
+func applyComponents(ctx context.Context, cl client.Client, objs []*unstructured.Unstructured, workers int) error {
+	for _, phase := range applyPhases(objs) {
+		if err := applyPhaseComponents(ctx, cl, phase, workers); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyPhaseComponents applies the components of a phase with a bounded worker pool, and returns their joined errors.
+func applyPhaseComponents(ctx context.Context, cl client.Client, objs []*unstructured.Unstructured, workers int) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs error
+	)
+
+	queue := make(chan *unstructured.Unstructured)
+
+	for range min(workers, len(objs)) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for obj := range queue {
+				if err := applyComponent(ctx, cl, obj); err != nil {
+					mu.Lock()
+					errs = errors.Join(errs, err)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for _, obj := range objs {
+		queue <- obj
+	}
+
+	close(queue)
+	wg.Wait()
+
+	return errs
+}
+
+// applyComponent applies a provider component with server-side apply, retrying on transient errors.
+func applyComponent(ctx context.Context, cl client.Client, obj *unstructured.Unstructured) error {
+	if err := retry.OnError(applyBackoff, isTransientApplyError, func() error {
+		// The fields removed from the component since it was last updated, such as the kube-rbac-proxy sidecars of
+		// the Deployments, are pruned only once the apply owns them.
+		if err := util.UpgradeManagedFields(ctx, cl, obj, legacyFieldManager, applyFieldOwner); err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		return cl.Patch(ctx, obj, client.Apply, client.FieldOwner(applyFieldOwner), client.ForceOwnership) //nolint:wrapcheck
+	}); err != nil {
+		return fmt.Errorf("error applying CAPI provider component %s %q: %w",
+			obj.GroupVersionKind().GroupKind(), getResourceName(obj.GetNamespace(), obj.GetName()), err)
+	}
+
+	return nil
+}
+
+// isTransientApplyError returns true for the apply errors expected to resolve by themselves, such as a custom resource
+// applied before its CRD is served, or a webhook not available yet.
+func isTransientApplyError(err error) bool {
+	return meta.IsNoMatchError(err) ||
+		apierrors.IsConflict(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsInternalError(err)
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capiinstaller
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("apply pipeline", func() {
+	component := func(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion(apiVersion)
+		u.SetKind(kind)
+		u.SetNamespace(namespace)
+		u.SetName(name)
+
+		return u
+	}
+
+	crd := func(group, kind string) *unstructured.Unstructured {
+		u := component("apiextensions.k8s.io/v1", "CustomResourceDefinition", "", kind+"."+group)
+		Expect(unstructured.SetNestedField(u.Object, group, "spec", "group")).To(Succeed())
+		Expect(unstructured.SetNestedField(u.Object, kind, "spec", "names", "kind")).To(Succeed())
+
+		return u
+	}
+
+	var (
+		namespace      = component("v1", "Namespace", "", "capi-system")
+		awsClusterCRD  = crd("infrastructure.cluster.x-k8s.io", "AWSClusterControllerIdentity")
+		clusterRole    = component("rbac.authorization.k8s.io/v1", "ClusterRole", "", "capa-manager-role")
+		service        = component("v1", "Service", "capi-system", "capa-webhook-service")
+		customResource = component("infrastructure.cluster.x-k8s.io/v1beta2", "AWSClusterControllerIdentity", "", "default")
+		deployment     = component("apps/v1", "Deployment", "capi-system", "capa-controller-manager")
+	)
+
+	// newClient returns a client recording the applied components, and failing to apply them with the error
+	// returned by fail, if any.
+	newClient := func(applied *[]string, fail func(obj client.Object) error) client.Client {
+		var mu sync.Mutex
+
+		return fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(_ context.Context, _ client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				Expect(patch).To(Equal(client.Apply))
+				Expect(opts).To(ContainElements(client.FieldOwner(applyFieldOwner), client.ForceOwnership))
+
+				if fail != nil {
+					if err := fail(obj); err != nil {
+						return err
+					}
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				*applied = append(*applied, obj.GetName())
+
+				return nil
+			},
+		}).Build()
+	}
+
+	It("groups the components by dependency order", func() {
+		Expect(applyPhases([]*unstructured.Unstructured{deployment, customResource, service, awsClusterCRD, clusterRole, namespace})).To(Equal([][]*unstructured.Unstructured{
+			{namespace},
+			{awsClusterCRD},
+			{service, clusterRole},
+			{customResource},
+			{deployment},
+		}))
+	})
+
+	It("applies the components in dependency order", func() {
+		applied := []string{}
+		cl := newClient(&applied, nil)
+
+		Expect(applyComponents(context.Background(), cl, []*unstructured.Unstructured{deployment, customResource, service, awsClusterCRD, clusterRole, namespace}, 1)).To(Succeed())
+		Expect(applied).To(Equal([]string{namespace.GetName(), awsClusterCRD.GetName(), service.GetName(), clusterRole.GetName(), customResource.GetName(), deployment.GetName()}))
+	})
+
+	It("retries the components failing with a transient error", func() {
+		applied := []string{}
+		attempts := 0
+
+		cl := newClient(&applied, func(obj client.Object) error {
+			if obj.GetName() != customResource.GetName() {
+				return nil
+			}
+
+			attempts++
+			if attempts == 1 {
+				return &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: "infrastructure.cluster.x-k8s.io", Kind: "AWSClusterControllerIdentity"}}
+			}
+
+			return nil
+		})
+
+		Expect(applyComponents(context.Background(), cl, []*unstructured.Unstructured{awsClusterCRD, customResource}, 1)).To(Succeed())
+		Expect(attempts).To(Equal(2))
+		Expect(applied).To(Equal([]string{awsClusterCRD.GetName(), customResource.GetName()}))
+	})
+
+	It("does not apply the components depending on a failing phase", func() {
+		applied := []string{}
+
+		cl := newClient(&applied, func(obj client.Object) error {
+			if obj.GetName() == clusterRole.GetName() {
+				return apierrors.NewForbidden(schema.GroupResource{Group: "rbac.authorization.k8s.io", Resource: "clusterroles"}, obj.GetName(), nil)
+			}
+
+			return nil
+		})
+
+		err := applyComponents(context.Background(), cl, []*unstructured.Unstructured{namespace, service, clusterRole, deployment}, 1)
+		Expect(err).To(MatchError(ContainSubstring(`ClusterRole.rbac.authorization.k8s.io "capa-manager-role"`)))
+		Expect(applied).To(Equal([]string{namespace.GetName(), service.GetName()}))
+	})
+
+	It("bounds the number of components applied concurrently", func() {
+		var inFlight, maxInFlight atomic.Int32
+
+		applied := []string{}
+		cl := newClient(&applied, func(client.Object) error {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+
+			for {
+				m := maxInFlight.Load()
+				if n <= m || maxInFlight.CompareAndSwap(m, n) {
+					break
+				}
+			}
+
+			time.Sleep(10 * time.Millisecond)
+
+			return nil
+		})
+
+		objs := []*unstructured.Unstructured{}
+		for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+			objs = append(objs, component("v1", "ServiceAccount", "capi-system", name))
+		}
+
+		Expect(applyComponents(context.Background(), cl, objs, 2)).To(Succeed())
+		Expect(applied).To(ConsistOf("a", "b", "c", "d", "e", "f"))
+		Expect(maxInFlight.Load()).To(BeNumerically("<=", 2))
+	})
+
+	It("hands the fields updated before server-side apply over to its field manager", func() {
+		legacyFields := `{"f:spec":{"f:template":{"f:spec":{"f:containers":{` +
+			`"k:{\"name\":\"kube-rbac-proxy\"}":{".":{},"f:image":{},"f:name":{}},` +
+			`"k:{\"name\":\"manager\"}":{".":{},"f:image":{},"f:name":{}}}}}}}`
+
+		existing := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      deployment.GetName(),
+				Namespace: deployment.GetNamespace(),
+				ManagedFields: []metav1.ManagedFieldsEntry{{
+					Manager:    legacyFieldManager,
+					Operation:  metav1.ManagedFieldsOperationUpdate,
+					APIVersion: "apps/v1",
+					FieldsType: "FieldsV1",
+					FieldsV1:   &metav1.FieldsV1{Raw: []byte(legacyFields)},
+				}},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{Name: "manager", Image: "capa:latest"},
+							{Name: "kube-rbac-proxy", Image: "kube-rbac-proxy:latest"},
+						},
+					},
+				},
+			},
+		}
+
+		applied := []string{}
+		cl := fake.NewClientBuilder().WithObjects(existing).WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				if patch != client.Apply {
+					return c.Patch(ctx, obj, patch, opts...)
+				}
+
+				// The fields must have been handed over before the apply, so that it prunes the sidecar.
+				current := &appsv1.Deployment{}
+				Expect(c.Get(ctx, client.ObjectKeyFromObject(obj), current)).To(Succeed())
+				Expect(current.ManagedFields).To(HaveLen(1))
+				Expect(current.ManagedFields[0].Manager).To(Equal(applyFieldOwner))
+				Expect(current.ManagedFields[0].Operation).To(Equal(metav1.ManagedFieldsOperationApply))
+				Expect(string(current.ManagedFields[0].FieldsV1.Raw)).To(ContainSubstring("kube-rbac-proxy"))
+
+				applied = append(applied, obj.GetName())
+
+				return nil
+			},
+		}).Build()
+
+		Expect(applyComponent(context.Background(), cl, deployment.DeepCopy())).To(Succeed())
+		Expect(applied).To(Equal([]string{deployment.GetName()}))
+	})
+
+	It("does not hand over the fields of other field managers", func() {
+		existing := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      deployment.GetName(),
+				Namespace: deployment.GetNamespace(),
+				ManagedFields: []metav1.ManagedFieldsEntry{{
+					Manager:    "kube-controller-manager",
+					Operation:  metav1.ManagedFieldsOperationUpdate,
+					APIVersion: "apps/v1",
+					FieldsType: "FieldsV1",
+					FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:status":{"f:replicas":{}}}`)},
+				}},
+			},
+		}
+
+		cl := fake.NewClientBuilder().WithObjects(existing).WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(_ context.Context, _ client.WithWatch, _ client.Object, patch client.Patch, _ ...client.PatchOption) error {
+				Expect(patch).To(Equal(client.Apply))
+
+				return nil
+			},
+		}).Build()
+
+		Expect(applyComponent(context.Background(), cl, deployment.DeepCopy())).To(Succeed())
+	})
+})
@@ -0,0 +1,72 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capiinstaller
+
+import (
+	"os"
+	"regexp"
+	"sort"
+)
+
+// ignitionVariablePattern matches the drone/envsubst variables a provider manifest gates the ignition bootstrap format
+// behind, such as ${EXP_BOOTSTRAP_FORMAT_IGNITION:=false} for the BootstrapFormatIgnition feature gate of CAPA and CAPIBM.
+// The variable names vary between providers, but all of them name the bootstrap format.
+var ignitionVariablePattern = regexp.MustCompile(`\$\{([A-Z0-9_]*BOOTSTRAP_FORMAT_IGNITION[A-Z0-9_]*)`)
+
+// ignitionVariables returns the sorted variables gating the ignition bootstrap format in the provider components.
+// Providers without any, such as OpenStack, vSphere, Nutanix and Metal3, read the format from the "format" key of
+// the bootstrap data secret, which is always ignition in OpenShift, so they need nothing to be enabled.
+func ignitionVariables(components string) []string {
+	seen := map[string]struct{}{}
+	variables := []string{}
+
+	for _, match := range ignitionVariablePattern.FindAllStringSubmatch(components, -1) {
+		if _, ok := seen[match[1]]; ok {
+			continue
+		}
+
+		seen[match[1]] = struct{}{}
+		variables = append(variables, match[1])
+	}
+
+	sort.Strings(variables)
+
+	return variables
+}
+
+// bootstrapFormatMapping returns the drone/envsubst mapping of the provider components.
+// It enables the ignition bootstrap format for the variables detected in the components, sets the provider feature
+// flags to the given values, and falls back to the environment for the other variables, or to the defaults of the
+// template when they are not set.
+func bootstrapFormatMapping(components string, featureFlags map[string]string) func(string) string {
+	enabled := map[string]struct{}{}
+	for _, variable := range ignitionVariables(components) {
+		enabled[variable] = struct{}{}
+	}
+
+	return func(variable string) string {
+		if _, ok := enabled[variable]; ok {
+			return "true"
+		}
+
+		if value, ok := featureFlags[variable]; ok {
+			return value
+		}
+
+		return os.Getenv(variable)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capiinstaller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const gatedIgnitionComponents = `apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - args:
+        - --feature-gates=BootstrapFormatIgnition=${EXP_BOOTSTRAP_FORMAT_IGNITION:=false},EKS=${CAPA_EKS:=false}
+        - --other-gate=${EXP_BOOTSTRAP_FORMAT_IGNITION:=false}
+`
+
+const nativeIgnitionComponents = `apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - args:
+        - --feature-gates=MultiNetworks=${EXP_MULTI_NETWORKS:=false}
+`
+
+var _ = Describe("Bootstrap format", func() {
+	It("detects the variables gating the ignition bootstrap format", func() {
+		Expect(ignitionVariables(gatedIgnitionComponents)).To(Equal([]string{"EXP_BOOTSTRAP_FORMAT_IGNITION"}))
+	})
+
+	It("detects no variables for providers reading the format from the bootstrap data secret", func() {
+		Expect(ignitionVariables(nativeIgnitionComponents)).To(BeEmpty())
+	})
+
+	It("enables the ignition bootstrap format only for the providers gating it", func() {
+		manifests, err := extractManifests(corev1.ConfigMap{Data: map[string]string{"components": gatedIgnitionComponents}}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manifests).To(ConsistOf(SatisfyAll(
+			ContainSubstring("BootstrapFormatIgnition=true,EKS=false"),
+			ContainSubstring("--other-gate=true"),
+		)))
+
+		manifests, err = extractManifests(corev1.ConfigMap{Data: map[string]string{"components": nativeIgnitionComponents}}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manifests).To(ConsistOf(ContainSubstring("MultiNetworks=false")))
+	})
+
+	It("takes the other variables from the environment", func() {
+		GinkgoT().Setenv("CAPA_EKS", "true")
+
+		manifests, err := extractManifests(corev1.ConfigMap{Data: map[string]string{"components": gatedIgnitionComponents}}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manifests).To(ConsistOf(ContainSubstring("BootstrapFormatIgnition=true,EKS=true")))
+	})
+})
@@ -0,0 +1,104 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capiinstaller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+const (
+	// HostNetworkAnnotation is set on the provider Deployments when the bootstrap host network mode is enabled,
+	// to "true" while they run on the host network and to "false" once they were switched back to the pod network.
+	// Changing it makes sure the Deployments are applied again when the mode is switched.
+	HostNetworkAnnotation = "cluster-api.openshift.io/host-network"
+
+	// bootstrapPriorityClassName is the priority class of the provider Deployments running on the host network,
+	// so that they are scheduled on the few nodes available while the cluster is installed.
+	bootstrapPriorityClassName = "system-cluster-critical"
+)
+
+// bootstrapTolerations let the provider Deployments run on the host network be scheduled on nodes whose network,
+// or cloud provider, is not ready yet.
+var bootstrapTolerations = []corev1.Toleration{
+	{Key: "node.kubernetes.io/not-ready", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: "node.kubernetes.io/network-unavailable", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: "node.cloudprovider.kubernetes.io/uninitialized", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: "node-role.kubernetes.io/master", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+}
+
+// useBootstrapHostNetwork returns whether the provider Deployments must run on the host network.
+// They do when the bootstrap host network mode is enabled, until the installation of the cluster completes.
+// On clusters not managed by the CVO the completion cannot be observed, so they do as long as the mode is enabled.
+func (r *CapiInstallerController) useBootstrapHostNetwork(ctx context.Context) (bool, error) {
+	if !r.BootstrapHostNetwork {
+		return false, nil
+	}
+
+	clusterVersion := &configv1.ClusterVersion{}
+	if err := r.Get(ctx, client.ObjectKey{Name: clusterVersionName}, clusterVersion); apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return true, nil
+	} else if err != nil {
+		return false, fmt.Errorf("unable to get ClusterVersion: %w", err)
+	}
+
+	return !isInstallCompleted(clusterVersion), nil
+}
+
+// isInstallCompleted returns whether the CVO completed the rollout of a release, which first happens at the end of
+// the installation.
+func isInstallCompleted(clusterVersion *configv1.ClusterVersion) bool {
+	return slices.ContainsFunc(clusterVersion.Status.History, func(update configv1.UpdateHistory) bool {
+		return update.State == configv1.CompletedUpdate
+	})
+}
+
+// setBootstrapHostNetwork renders the provider Deployment for the bootstrap host network mode.
+// When hostNetwork is true, the Deployment runs on the host network, tolerates the nodes that are not ready yet and
+// is scheduled with a critical priority. Otherwise, it is left as shipped, on the pod network.
+func setBootstrapHostNetwork(deployment *appsv1.Deployment, hostNetwork bool) {
+	if deployment.Annotations == nil {
+		deployment.Annotations = map[string]string{}
+	}
+
+	deployment.Annotations[HostNetworkAnnotation] = strconv.FormatBool(hostNetwork)
+
+	if !hostNetwork {
+		return
+	}
+
+	podSpec := &deployment.Spec.Template.Spec
+	podSpec.HostNetwork = true
+	// Keep resolving the cluster services, such as the API server, from the host network.
+	podSpec.DNSPolicy = corev1.DNSClusterFirstWithHostNet
+	podSpec.PriorityClassName = bootstrapPriorityClassName
+
+	for _, toleration := range bootstrapTolerations {
+		if !slices.ContainsFunc(podSpec.Tolerations, func(t corev1.Toleration) bool { return t.MatchToleration(&toleration) }) {
+			podSpec.Tolerations = append(podSpec.Tolerations, toleration)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capiinstaller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("Bootstrap host network", func() {
+	controlPlaneToleration := corev1.Toleration{Key: "node-role.kubernetes.io/master", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}
+
+	newDeployment := func() *appsv1.Deployment {
+		deployment := &appsv1.Deployment{}
+		deployment.Spec.Template.Spec.Tolerations = []corev1.Toleration{controlPlaneToleration}
+
+		return deployment
+	}
+
+	It("runs the deployment on the host network while enabled", func() {
+		deployment := newDeployment()
+
+		setBootstrapHostNetwork(deployment, true)
+
+		Expect(deployment.Annotations).To(HaveKeyWithValue(HostNetworkAnnotation, "true"))
+		Expect(deployment.Spec.Template.Spec.HostNetwork).To(BeTrue())
+		Expect(deployment.Spec.Template.Spec.DNSPolicy).To(Equal(corev1.DNSClusterFirstWithHostNet))
+		Expect(deployment.Spec.Template.Spec.PriorityClassName).To(Equal(bootstrapPriorityClassName))
+		Expect(deployment.Spec.Template.Spec.Tolerations).To(ConsistOf(bootstrapTolerations))
+	})
+
+	It("leaves the deployment on the pod network once switched back", func() {
+		deployment := newDeployment()
+
+		setBootstrapHostNetwork(deployment, false)
+
+		Expect(deployment.Annotations).To(HaveKeyWithValue(HostNetworkAnnotation, "false"))
+		Expect(deployment.Spec.Template.Spec).To(Equal(newDeployment().Spec.Template.Spec))
+	})
+
+	It("considers the installation completed once a release was completely rolled out", func() {
+		clusterVersion := &configv1.ClusterVersion{}
+		Expect(isInstallCompleted(clusterVersion)).To(BeFalse())
+
+		clusterVersion.Status.History = []configv1.UpdateHistory{{State: configv1.PartialUpdate, Version: "4.18.0"}}
+		Expect(isInstallCompleted(clusterVersion)).To(BeFalse())
+
+		clusterVersion.Status.History = []configv1.UpdateHistory{
+			{State: configv1.PartialUpdate, Version: "4.18.1"},
+			{State: configv1.CompletedUpdate, Version: "4.18.0"},
+		}
+		Expect(isInstallCompleted(clusterVersion)).To(BeTrue())
+	})
+})
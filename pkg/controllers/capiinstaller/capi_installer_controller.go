@@ -20,35 +20,39 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/drone/envsubst/v2"
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
 
 	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
-	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 
-	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	configv1 "github.com/openshift/api/config/v1"
-	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
+	"github.com/openshift/cluster-capi-operator/pkg/metrics"
 	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
-	"github.com/openshift/library-go/pkg/operator/events"
-	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
-	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
+	"github.com/openshift/cluster-capi-operator/pkg/tracing"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
 
 	"github.com/klauspost/compress/zstd"
 )
@@ -70,38 +74,112 @@ const (
 	clusterOperatorName               = "cluster-api"
 	defaultCoreProviderComponentName  = "cluster-api"
 	powerVSIBMCloudProvider           = "ibmcloud"
+	kubeRBACProxyImageKey             = "kube-rbac-proxy"
+
+	// DisabledProvidersAnnotation is set on the cluster-api ClusterOperator to disable CAPI providers.
+	// It holds a comma separated list of provider types (core, infrastructure) or provider names (e.g. cluster-api, aws).
+	// The components of a disabled provider, including its CRDs, are still installed but its Deployments are scaled down to zero.
+	DisabledProvidersAnnotation = "cluster-api.openshift.io/disabled-providers"
 )
 
 var (
 	errEmptyProviderConfigMap = errors.New("provider configmap has no components data")
-	errResourceNotFound       = errors.New("resource not found")
+	errProviderImageNotFound  = errors.New("no image found in the images file for key")
 )
 
+// providersStatus holds the versions and related objects of the installed providers,
+// reported in the ClusterOperator status.
+type providersStatus struct {
+	versions       []configv1.OperandVersion
+	relatedObjects []configv1.ObjectReference
+	// versionSkews describes the provider Deployments that do not match the release payload yet.
+	versionSkews []string
+	// crdDeletions describes the provider CRDs deleted since they were installed.
+	crdDeletions crdDeletions
+}
+
 // CapiInstallerController reconciles a ClusterOperator object.
 // It is resopnsible for installing the Cluster API components in the cluster.
 type CapiInstallerController struct {
 	operatorstatus.ClusterOperatorStatusClient
-	Scheme              *runtime.Scheme
-	Images              map[string]string
-	RestCfg             *rest.Config
-	Platform            configv1.PlatformType
-	ApplyClient         *kubernetes.Clientset
-	APIExtensionsClient *apiextensionsclient.Clientset
+	Scheme   *runtime.Scheme
+	Images   map[string]string
+	RestCfg  *rest.Config
+	Platform configv1.PlatformType
+
+	// BootstrapHostNetwork runs the provider Deployments on the host network until the cluster installation
+	// completes, for the installs needing the providers before the pod network is ready, such as bare metal ones.
+	BootstrapHostNetwork bool
+
+	// PriorityClassName replaces the priority class the provider Deployments are shipped with, when set.
+	PriorityClassName string
+
+	// DisablePodDisruptionBudgets skips the PodDisruptionBudgets shipped with the provider Deployments, and deletes
+	// the installed ones.
+	DisablePodDisruptionBudgets bool
 }
 
 // Reconcile reconciles the cluster-api ClusterOperator object.
 func (r *CapiInstallerController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx).WithName(controllerName)
 
-	res, err := r.reconcile(ctx, log)
+	co, err := r.GetOrCreateClusterOperator(ctx)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("unable to get cluster operator: %w", err)
+	}
+
+	disabled := disabledProviders(co.GetAnnotations())
+
+	payloadVersion, err := r.getPayloadVersion(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// The provider images come with the operator, deploying them across minor versions could run providers
+	// against CRDs and transport ConfigMaps of another release. The operator waits to be updated instead.
+	if isMinorVersionSkewed(r.ReleaseVersion, payloadVersion) {
+		log.Info("not deploying CAPI providers, the operator is at a different minor version than the release payload",
+			"operatorVersion", r.ReleaseVersion, "payloadVersion", payloadVersion)
+
+		return ctrl.Result{}, r.syncProviderVersionSkew(ctx, []string{
+			fmt.Sprintf("the operator at release %q is not deploying CAPI providers until it is updated to %q", r.ReleaseVersion, payloadVersion),
+		})
+	}
+
+	hostNetwork, err := r.useBootstrapHostNetwork(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	enabledFeatureGates, err := r.getEnabledFeatureGates(ctx, payloadVersion)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	reconcileCtx, span := tracing.Start(ctx, "CapiInstaller")
+	providers, res, err := r.reconcile(reconcileCtx, log, disabled, payloadVersion, hostNetwork, featureFlagValues(providerFeatureFlags, enabledFeatureGates))
+	tracing.End(span, err)
+
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("error during reconcile: %w", err)
 	}
 
-	if err := r.setAvailableCondition(ctx, log); err != nil {
+	// The deleted provider CRDs are re-applied, but their custom resources are lost, so the controller is degraded
+	// instead of available.
+	if err := providers.crdDeletions.err(); err != nil {
+		r.recordCRDDeletions(ctx, err)
+
+		return ctrl.Result{}, r.setDegradedCondition(ctx, log, err)
+	}
+
+	if err := r.setAvailableCondition(ctx, log, disabled, providers); err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to set conditions for CAPI Installer Controller: %w", err)
 	}
 
+	if err := r.syncProviderVersionSkew(ctx, providers.versionSkews); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	return res, nil
 }
 
@@ -109,9 +187,20 @@ func (r *CapiInstallerController) Reconcile(ctx context.Context, req ctrl.Reques
 // Notably it fetches CAPI providers "transport" ConfigMap(s) matching the required labels,
 // it extracts from those ConfigMaps the embedded CAPI providers manifests for the components
 // and it applies them to the cluster.
+// Providers matching one of the disabled providers have their Deployments scaled down to zero.
+// When hostNetwork is true the Deployments are run on the host network.
+// The provider feature flags templated in the components are set to the given featureFlags values.
+// It returns the versions and related objects of the installed providers, and how their Deployments are skewed from
+// the given release payload version.
 //
 //nolint:unparam
-func (r *CapiInstallerController) reconcile(ctx context.Context, log logr.Logger) (ctrl.Result, error) {
+func (r *CapiInstallerController) reconcile(ctx context.Context, log logr.Logger, disabled []string, payloadVersion string, hostNetwork bool, featureFlags map[string]string) (providersStatus, ctrl.Result, error) {
+	providers := providersStatus{}
+
+	if hostNetwork {
+		log.Info("running the CAPI provider deployments on the host network until the cluster installation completes")
+	}
+
 	// Define the desired providers to be installed for this cluster.
 	// We always want to install the core provider, which in our case is the default cluster-api core provider.
 	// We also want to install the infrastructure provider that matches the currently detected platform the cluster is running on.
@@ -120,168 +209,365 @@ func (r *CapiInstallerController) reconcile(ctx context.Context, log logr.Logger
 		"infrastructure": platformToProviderConfigMapLabelNameValue(r.Platform),
 	}
 
-	// Process each one of the desired providers.
-	for providerConfigMapLabelTypeVal, providerConfigMapLabelNameVal := range providerConfigMapLabels {
+	// The component names of the providers, used to report their versions.
+	providerComponentNames := map[string]string{
+		"core":           defaultCoreProviderComponentName,
+		"infrastructure": platformToInfraProviderComponentName(r.Platform),
+	}
+
+	// Process each one of the desired providers, in a stable order so the reported status does not change between reconciles.
+	for _, providerConfigMapLabelTypeVal := range []string{"core", "infrastructure"} {
+		providerConfigMapLabelNameVal := providerConfigMapLabels[providerConfigMapLabelTypeVal]
+
 		log.Info("reconciling CAPI provider", "name", providerConfigMapLabelNameVal)
 
+		providerDisabled := isProviderDisabled(disabled, providerConfigMapLabelTypeVal, providerConfigMapLabelNameVal)
+		if providerDisabled {
+			log.Info("CAPI provider is disabled, scaling down its deployments", "name", providerConfigMapLabelNameVal)
+		}
+
+		providerAttr := attribute.String("provider", providerConfigMapLabelNameVal)
+
+		// Make sure the provider images are usable before installing any of its components.
+		if err := tracing.Trace(ctx, tracing.PhaseFetch, func(ctx context.Context) error {
+			return r.resolveProviderImages(ctx, log, providerConfigMapLabelNameVal)
+		}, providerAttr); err != nil {
+			installErr := fmt.Errorf("unable to resolve CAPI provider %q images: %w", providerConfigMapLabelNameVal, err)
+
+			if err := r.setDegradedCondition(ctx, log, installErr); err != nil {
+				return providersStatus{}, ctrl.Result{}, fmt.Errorf("failed to set conditions for CAPI Installer controller: %w", err)
+			}
+
+			return providersStatus{}, ctrl.Result{}, installErr
+		}
+
 		// Get a List all the ConfigMaps matching the desired provider labels.
 		configMapList := &corev1.ConfigMapList{}
-		if err := r.List(ctx, configMapList, client.InNamespace(defaultCAPINamespace),
-			client.MatchingLabels{
-				providerConfigMapLabelNameKey: providerConfigMapLabelNameVal,
-				providerConfigMapLabelTypeKey: providerConfigMapLabelTypeVal,
-			},
-		); err != nil {
-			if err := r.setDegradedCondition(ctx, log); err != nil {
-				return ctrl.Result{}, fmt.Errorf("failed to set conditions for CAPI Installer controller: %w", err)
+		if err := tracing.Trace(ctx, tracing.PhaseFetch, func(ctx context.Context) error {
+			return r.List(ctx, configMapList, client.InNamespace(defaultCAPINamespace),
+				client.MatchingLabels{
+					providerConfigMapLabelNameKey: providerConfigMapLabelNameVal,
+					providerConfigMapLabelTypeKey: providerConfigMapLabelTypeVal,
+				},
+			)
+		}, providerAttr); err != nil {
+			installErr := fmt.Errorf("unable to list CAPI provider %q ConfigMaps: %w", providerConfigMapLabelNameVal, err)
+
+			if err := r.setDegradedCondition(ctx, log, installErr); err != nil {
+				return providersStatus{}, ctrl.Result{}, fmt.Errorf("failed to set conditions for CAPI Installer controller: %w", err)
 			}
 
-			return ctrl.Result{}, fmt.Errorf("unable to list CAPI provider %q ConfigMaps: %w", providerConfigMapLabelNameVal, err)
+			return providersStatus{}, ctrl.Result{}, installErr
 		}
 
 		// Extract the provider manifests stored each of the matching ConfigMaps.
-		var providerComponents []string
+		var (
+			providerComponents []string
+			providerVersion    string
+		)
 
 		for _, cm := range configMapList.Items {
 			log.Info("processing CAPI provider ConfigMap", "configmapName", cm.Name, "providerType", cm.Labels[providerConfigMapLabelTypeKey],
 				"providerName", cm.Labels[providerConfigMapLabelNameKey], "providerVersion", cm.Labels[providerConfigMapLabelVersionKey])
 
-			partialComponents, err := r.extractProviderComponents(cm)
+			partialComponents, err := r.extractProviderComponents(cm, featureFlags)
 			if err != nil {
-				if err := r.setDegradedCondition(ctx, log); err != nil {
-					return ctrl.Result{}, fmt.Errorf("failed to set conditions for CAPI Installer controller: %w", err)
+				installErr := fmt.Errorf("error extracting CAPI provider components from ConfigMap %q/%q: %w", cm.Namespace, cm.Name, err)
+
+				if err := r.setDegradedCondition(ctx, log, installErr); err != nil {
+					return providersStatus{}, ctrl.Result{}, fmt.Errorf("failed to set conditions for CAPI Installer controller: %w", err)
 				}
 
-				return ctrl.Result{}, fmt.Errorf("error extracting CAPI provider components from ConfigMap %q/%q: %w", cm.Namespace, cm.Name, err)
+				return providersStatus{}, ctrl.Result{}, installErr
+			}
+
+			if version := cm.Labels[providerConfigMapLabelVersionKey]; version != "" {
+				providerVersion = version
 			}
 
 			providerComponents = append(providerComponents, partialComponents...)
 		}
 
 		// Apply all the collected provider components manifests.
-		if err := r.applyProviderComponents(ctx, providerComponents); err != nil {
-			if err := r.setDegradedCondition(ctx, log); err != nil {
-				return ctrl.Result{}, fmt.Errorf("failed to set conditions for CAPI Installer controller: %w", err)
+		applyStart := time.Now()
+
+		var (
+			deployments []*appsv1.Deployment
+			deletions   crdDeletions
+		)
+
+		if err := tracing.Trace(ctx, tracing.PhaseApply, func(ctx context.Context) (err error) {
+			deployments, deletions, err = r.applyProviderComponents(ctx, providerComponents, providerDisabled, hostNetwork)
+			return err
+		}, providerAttr); err != nil {
+			metrics.RecordProviderApplyFailure(providerConfigMapLabelNameVal)
+
+			installErr := fmt.Errorf("error applying CAPI provider %q components: %w", providerConfigMapLabelNameVal, err)
+
+			if err := r.setDegradedCondition(ctx, log, installErr); err != nil {
+				return providersStatus{}, ctrl.Result{}, fmt.Errorf("failed to set conditions for CAPI Installer controller: %w", err)
 			}
 
-			return ctrl.Result{}, fmt.Errorf("error applying CAPI provider %q components: %w", providerConfigMapLabelNameVal, err)
+			return providersStatus{}, ctrl.Result{}, installErr
 		}
 
+		relatedObjects, err := getProviderRelatedObjects(r.Scheme, providerComponents)
+		if err != nil {
+			return providersStatus{}, ctrl.Result{}, fmt.Errorf("error getting CAPI provider %q related objects: %w", providerConfigMapLabelNameVal, err)
+		}
+
+		providers.relatedObjects = append(providers.relatedObjects, relatedObjects...)
+		providers.crdDeletions.recreated = append(providers.crdDeletions.recreated, deletions.recreated...)
+
+		for _, deployment := range deployments {
+			if skew := deploymentVersionSkew(deployment, payloadVersion); skew != "" {
+				providers.versionSkews = append(providers.versionSkews, skew)
+			}
+		}
+
+		if providerVersion != "" {
+			providers.versions = append(providers.versions, configv1.OperandVersion{
+				Name:    providerComponentNames[providerConfigMapLabelTypeVal],
+				Version: providerVersion,
+			})
+		}
+
+		metrics.RecordProviderApplySuccess(providerConfigMapLabelNameVal, time.Since(applyStart))
+
 		log.Info("finished reconciling CAPI provider", "name", providerConfigMapLabelNameVal)
 	}
 
-	return ctrl.Result{}, nil
+	return providers, ctrl.Result{}, nil
 }
 
-// applyProviderComponents applies the provider components to the cluster.
-// It does so by differentiating between static components and dynamic components (i.e. Deployments).
-func (r *CapiInstallerController) applyProviderComponents(ctx context.Context, components []string) error {
-	componentsFilenames, componentsAssets, deploymentsFilenames, deploymentsAssets, err := getProviderComponents(r.Scheme, components)
-	if err != nil {
-		return fmt.Errorf("error getting provider components: %w", err)
-	}
-
-	// Perform a Direct apply of the static components.
-	res := resourceapply.ApplyDirectly(
-		ctx,
-		resourceapply.NewKubeClientHolder(r.ApplyClient).WithAPIExtensionsClient(r.APIExtensionsClient),
-		events.NewInMemoryRecorder("cluster-capi-operator-capi-installer-apply-client"),
-		resourceapply.NewResourceCache(),
-		assetFn(componentsAssets),
-		componentsFilenames...,
-	)
-
-	// For each of the Deployment components perform a Deployment-specific apply.
-	for _, d := range deploymentsFilenames {
-		deploymentManifest, ok := deploymentsAssets[d]
-		if !ok {
-			panic("error finding deployment manifest")
-		}
+// applyProviderComponents applies the provider components to the cluster with server-side apply.
+// The components are applied in dependency order by the apply pipeline, see applyComponents.
+// The provider CRDs deleted since they were installed are returned, see findDeletedProviderCRDs.
+// The Deployments allowing manual changes are not applied, see holdManuallyChangedDeployments.
+// When scaleDown is true the Deployments are applied with zero replicas.
+// When the bootstrap host network mode is enabled, the Deployments are rendered for it according to hostNetwork.
+// When the pod disruption budgets are disabled, they are not applied and their installed copies are deleted.
+// The Deployments are pinned to the release version of the operator, and returned as applied, or as installed when held.
+func (r *CapiInstallerController) applyProviderComponents(ctx context.Context, components []string, scaleDown, hostNetwork bool) ([]*appsv1.Deployment, crdDeletions, error) {
+	objs := []*unstructured.Unstructured{}
 
-		obj, err := yamlToRuntimeObject(r.Scheme, deploymentManifest)
+	for i, m := range components {
+		u, err := yamlToUnstructured(r.Scheme, m)
 		if err != nil {
-			return fmt.Errorf("error parsing CAPI provider deployment manifets %q: %w", d, err)
+			return nil, crdDeletions{}, fmt.Errorf("error parsing provider component at position %d to unstructured: %w", i, err)
+		}
+
+		if u.GroupVersionKind().GroupKind() == appsv1.SchemeGroupVersion.WithKind("Deployment").GroupKind() {
+			if u, err = r.renderDeployment(u, scaleDown, hostNetwork); err != nil {
+				return nil, crdDeletions{}, err
+			}
 		}
 
-		// TODO: Deployments State/Conditions should influence the overall ClusterOperator Status.
-		deployment, ok := obj.(*appsv1.Deployment)
-		if !ok {
-			return fmt.Errorf("error casting object to Deployment: %w", err)
+		util.SetManagedByLabel(u)
+		objs = append(objs, u)
+	}
+
+	if r.DisablePodDisruptionBudgets {
+		var err error
+		if objs, err = r.removePodDisruptionBudgets(ctx, objs); err != nil {
+			return nil, crdDeletions{}, err
+		}
+	}
+
+	deletions, err := r.findDeletedProviderCRDs(ctx, objs)
+	if err != nil {
+		return nil, crdDeletions{}, err
+	}
+
+	objs, deployments, err := r.holdManuallyChangedDeployments(ctx, objs)
+	if err != nil {
+		return nil, crdDeletions{}, err
+	}
+
+	if err := applyComponents(ctx, r.Client, objs, applyWorkers); err != nil {
+		return nil, crdDeletions{}, err
+	}
+
+	for _, u := range objs {
+		if u.GroupVersionKind().GroupKind() != appsv1.SchemeGroupVersion.WithKind("Deployment").GroupKind() {
+			continue
 		}
 
-		if _, _, err := resourceapply.ApplyDeployment(
-			ctx,
-			r.ApplyClient.AppsV1(),
-			events.NewInMemoryRecorder("cluster-capi-operator-capi-installer-apply-client"),
-			deployment,
-			resourcemerge.ExpectedDeploymentGeneration(deployment, nil),
-		); err != nil {
-			return fmt.Errorf("error applying CAPI provider deployment %q: %w", deployment.Name, err)
+		deployment := &appsv1.Deployment{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, deployment); err != nil {
+			return nil, crdDeletions{}, fmt.Errorf("error converting applied CAPI provider deployment %q: %w", u.GetName(), err)
 		}
+
+		deployments = append(deployments, deployment)
 	}
 
-	var errs error
+	return deployments, deletions, nil
+}
+
+// renderDeployment customizes a provider Deployment for OpenShift before it is applied.
+func (r *CapiInstallerController) renderDeployment(u *unstructured.Unstructured, scaleDown, hostNetwork bool) (*unstructured.Unstructured, error) {
+	deployment := &appsv1.Deployment{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, deployment); err != nil {
+		return nil, fmt.Errorf("error parsing CAPI provider deployment manifest %q: %w", u.GetName(), err)
+	}
 
-	for i, r := range res {
-		if r.Error != nil {
-			errs = errors.Join(errs, fmt.Errorf("error applying CAPI provider component %q at position %d: %w", r.File, i, r.Error))
+	if scaleDown {
+		deployment.Spec.Replicas = ptr.To(int32(0))
+	}
+
+	if deployment.Annotations == nil {
+		deployment.Annotations = map[string]string{}
+	}
+
+	deployment.Annotations[ReleaseVersionAnnotation] = r.ReleaseVersion
+
+	replaceKubeRBACProxy(deployment)
+
+	if r.PriorityClassName != "" {
+		deployment.Spec.Template.Spec.PriorityClassName = r.PriorityClassName
+	}
+
+	// The bootstrap priority class takes precedence while the cluster is installed.
+	if r.BootstrapHostNetwork {
+		setBootstrapHostNetwork(deployment, hostNetwork)
+	}
+
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(deployment)
+	if err != nil {
+		return nil, fmt.Errorf("error converting CAPI provider deployment %q to unstructured: %w", deployment.Name, err)
+	}
+
+	return &unstructured.Unstructured{Object: obj}, nil
+}
+
+// disabledProviders returns the providers listed in the DisabledProvidersAnnotation, sorted and without duplicates.
+func disabledProviders(annotations map[string]string) []string {
+	disabled := []string{}
+
+	for _, provider := range strings.Split(annotations[DisabledProvidersAnnotation], ",") {
+		provider = strings.TrimSpace(provider)
+		if provider != "" && !slices.Contains(disabled, provider) {
+			disabled = append(disabled, provider)
 		}
 	}
 
-	return errs
+	slices.Sort(disabled)
+
+	return disabled
 }
 
-// getProviderComponents parses the provided list of components into a map of filenames and assets.
-// Deployments are handled separately so are returned in a separate map.
-func getProviderComponents(scheme *runtime.Scheme, components []string) ([]string, map[string]string, []string, map[string]string, error) {
-	componentsFilenames := []string{}
-	componentsAssets := make(map[string]string)
+// isProviderDisabled returns true if the provider type or name is part of the disabled providers.
+func isProviderDisabled(disabled []string, providerType, providerName string) bool {
+	return slices.Contains(disabled, providerType) || slices.Contains(disabled, providerName)
+}
 
-	deploymentsFilenames := []string{}
-	deploymentsAssets := make(map[string]string)
+// resolveProviderImages validates the images substituted in the provider components, so that a missing or malformed
+// image is reported with its reference instead of producing Deployments that can never be pulled.
+// The mirrors configured for each image are logged, to help debugging image pulls in disconnected environments.
+func (r *CapiInstallerController) resolveProviderImages(ctx context.Context, log logr.Logger, providerName string) error {
+	mirrorConfig, err := r.getImageMirrorConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The kube-rbac-proxy sidecars are removed from the provider Deployments, so its image is not required.
+	imageKey := providerNameToImageKey(providerName)
+
+	image, ok := r.Images[imageKey]
+	if !ok {
+		return fmt.Errorf("%w: %q", errProviderImageNotFound, imageKey)
+	}
+
+	if err := util.ValidateImageReference(image); err != nil {
+		return fmt.Errorf("image %q: %w", imageKey, err)
+	}
+
+	if mirrors := util.ImageMirrors(image, mirrorConfig); len(mirrors) > 0 {
+		log.V(2).Info("CAPI provider image is mirrored", "imageKey", imageKey, "image", image, "mirrors", mirrors)
+	}
+
+	return nil
+}
+
+// getImageMirrorConfig fetches the cluster wide image mirroring configuration.
+// Mirroring APIs that are not available in the cluster are ignored.
+func (r *CapiInstallerController) getImageMirrorConfig(ctx context.Context) (util.ImageMirrorConfig, error) {
+	idmsList := &configv1.ImageDigestMirrorSetList{}
+	if err := r.List(ctx, idmsList); err != nil && !meta.IsNoMatchError(err) {
+		return util.ImageMirrorConfig{}, fmt.Errorf("unable to list ImageDigestMirrorSets: %w", err)
+	}
+
+	itmsList := &configv1.ImageTagMirrorSetList{}
+	if err := r.List(ctx, itmsList); err != nil && !meta.IsNoMatchError(err) {
+		return util.ImageMirrorConfig{}, fmt.Errorf("unable to list ImageTagMirrorSets: %w", err)
+	}
+
+	icspList := &operatorv1alpha1.ImageContentSourcePolicyList{}
+	if err := r.List(ctx, icspList); err != nil && !meta.IsNoMatchError(err) {
+		return util.ImageMirrorConfig{}, fmt.Errorf("unable to list ImageContentSourcePolicies: %w", err)
+	}
+
+	return util.ImageMirrorConfig{
+		ImageDigestMirrorSets:      idmsList.Items,
+		ImageTagMirrorSets:         itmsList.Items,
+		ImageContentSourcePolicies: icspList.Items,
+	}, nil
+}
+
+// getProviderRelatedObjects returns references to the Deployments and CRDs of a provider,
+// so that they are gathered by must-gather and `oc adm inspect` along with the ClusterOperator.
+func getProviderRelatedObjects(scheme *runtime.Scheme, components []string) ([]configv1.ObjectReference, error) {
+	relatedObjects := []configv1.ObjectReference{}
 
 	for i, m := range components {
-		// Parse the YAML manifests into unstructure objects.
 		u, err := yamlToUnstructured(scheme, m)
 		if err != nil {
-			return nil, nil, nil, nil, fmt.Errorf("error parsing provider component at position %d to unstructured: %w", i, err)
+			return nil, fmt.Errorf("error parsing provider component at position %d to unstructured: %w", i, err)
 		}
 
-		name := fmt.Sprintf("%s/%s/%s - %s",
-			u.GroupVersionKind().Group,
-			u.GroupVersionKind().Version,
-			u.GroupVersionKind().Kind,
-			getResourceName(u.GetNamespace(), u.GetName()),
-		)
-
-		// Divide manifests into static vs deployment components.
-		if u.GroupVersionKind().Kind == "Deployment" {
-			deploymentsFilenames = append(deploymentsFilenames, name)
-			deploymentsAssets[name] = m
-		} else {
-			componentsFilenames = append(componentsFilenames, name)
-			componentsAssets[name] = m
+		switch u.GroupVersionKind().GroupKind() {
+		case appsv1.SchemeGroupVersion.WithKind("Deployment").GroupKind():
+			relatedObjects = append(relatedObjects, configv1.ObjectReference{
+				Group:     appsv1.GroupName,
+				Resource:  "deployments",
+				Namespace: u.GetNamespace(),
+				Name:      u.GetName(),
+			})
+		case apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition").GroupKind():
+			relatedObjects = append(relatedObjects, configv1.ObjectReference{
+				Group:    apiextensionsv1.GroupName,
+				Resource: "customresourcedefinitions",
+				Name:     u.GetName(),
+			})
 		}
 	}
 
-	return componentsFilenames, componentsAssets, deploymentsFilenames, deploymentsAssets, nil
+	return relatedObjects, nil
 }
 
 // setAvailableCondition sets the ClusterOperator status condition to Available.
-func (r *CapiInstallerController) setAvailableCondition(ctx context.Context, log logr.Logger) error {
+// Disabled providers are not a failure, they are only reported in the condition messages.
+// The versions and related objects of the installed providers are reported alongside the operator ones.
+func (r *CapiInstallerController) setAvailableCondition(ctx context.Context, log logr.Logger, disabled []string, providers providersStatus) error {
 	co, err := r.GetOrCreateClusterOperator(ctx)
 	if err != nil {
 		return fmt.Errorf("unable to get cluster operator: %w", err)
 	}
 
+	message := "CAPI Installer Controller works as expected"
+	if len(disabled) > 0 {
+		message = fmt.Sprintf("%s, disabled providers: %s", message, strings.Join(disabled, ", "))
+	}
+
 	conds := []configv1.ClusterOperatorStatusCondition{
 		operatorstatus.NewClusterOperatorStatusCondition(capiInstallerControllerAvailableCondition, configv1.ConditionTrue, operatorstatus.ReasonAsExpected,
-			"CAPI Installer Controller works as expected"),
+			message),
 		operatorstatus.NewClusterOperatorStatusCondition(capiInstallerControllerDegradedCondition, configv1.ConditionFalse, operatorstatus.ReasonAsExpected,
-			"CAPI Installer Controller works as expected"),
+			message),
 	}
 
-	co.Status.Versions = []configv1.OperandVersion{{Name: controllers.OperatorVersionKey, Version: r.ReleaseVersion}}
+	co.Status.Versions = r.OperandVersions(providers.versions...)
+	co.Status.RelatedObjects = r.RelatedObjects(providers.relatedObjects...)
 
 	log.V(2).Info("CAPI Installer Controller is Available")
 
@@ -292,8 +578,8 @@ func (r *CapiInstallerController) setAvailableCondition(ctx context.Context, log
 	return nil
 }
 
-// setAvailableCondition sets the ClusterOperator status condition to Degraded.
-func (r *CapiInstallerController) setDegradedCondition(ctx context.Context, log logr.Logger) error {
+// setDegradedCondition sets the ClusterOperator status condition to Degraded, with the install error in the message.
+func (r *CapiInstallerController) setDegradedCondition(ctx context.Context, log logr.Logger, installErr error) error {
 	co, err := r.GetOrCreateClusterOperator(ctx)
 	if err != nil {
 		return fmt.Errorf("unable to get cluster operator: %w", err)
@@ -301,12 +587,12 @@ func (r *CapiInstallerController) setDegradedCondition(ctx context.Context, log
 
 	conds := []configv1.ClusterOperatorStatusCondition{
 		operatorstatus.NewClusterOperatorStatusCondition(capiInstallerControllerAvailableCondition, configv1.ConditionFalse, operatorstatus.ReasonSyncFailed,
-			"CAPI Installer Controller failed install"),
+			fmt.Sprintf("CAPI Installer Controller failed install: %v", installErr)),
 		operatorstatus.NewClusterOperatorStatusCondition(capiInstallerControllerDegradedCondition, configv1.ConditionTrue, operatorstatus.ReasonSyncFailed,
-			"CAPI Installer Controller failed install"),
+			fmt.Sprintf("CAPI Installer Controller failed install: %v", installErr)),
 	}
 
-	co.Status.Versions = []configv1.OperandVersion{{Name: controllers.OperatorVersionKey, Version: r.ReleaseVersion}}
+	r.SetOperatorVersion(co)
 
 	log.Info("CAPI Installer Controller is Degraded")
 
@@ -326,6 +612,16 @@ func (r *CapiInstallerController) SetupWithManager(mgr ctrl.Manager) error {
 			&corev1.ConfigMap{},
 			handler.EnqueueRequestsFromMapFunc(toClusterOperator),
 			builder.WithPredicates(configMapPredicate(r.ManagedNamespace, r.Platform)),
+		).
+		// The ClusterVersion is watched so that the providers are compared with the new payload as soon as an update starts.
+		Watches(
+			&configv1.ClusterVersion{},
+			handler.EnqueueRequestsFromMapFunc(toClusterOperator),
+		).
+		// The FeatureGate is watched so that the providers are redeployed with their new feature flags.
+		Watches(
+			&configv1.FeatureGate{},
+			handler.EnqueueRequestsFromMapFunc(toClusterOperator),
 		)
 
 	// All of the following watches share the ownedPlatformLabelPredicate.
@@ -345,6 +641,7 @@ func (r *CapiInstallerController) SetupWithManager(mgr ctrl.Manager) error {
 		{&rbacv1.ClusterRole{}, notNamespaced},
 		{&rbacv1.Role{}, r.ManagedNamespace},
 		{&rbacv1.RoleBinding{}, r.ManagedNamespace},
+		{&policyv1.PodDisruptionBudget{}, r.ManagedNamespace},
 	}
 
 	for _, w := range watches {
@@ -366,8 +663,8 @@ func (r *CapiInstallerController) SetupWithManager(mgr ctrl.Manager) error {
 // The format of the ConfigMap is well known and follows the upstream CAPI's
 // clusterctl Provider Contract - Components YAML file contract defined at:
 // https://github.com/kubernetes-sigs/cluster-api/blob/a36712e28bf5d54e398ea84cb3e20102c0499426/docs/book/src/clusterctl/provider-contract.md?plain=1#L157-L162
-func (r *CapiInstallerController) extractProviderComponents(cm corev1.ConfigMap) ([]string, error) {
-	yamlManifests, err := extractManifests(cm)
+func (r *CapiInstallerController) extractProviderComponents(cm corev1.ConfigMap, featureFlags map[string]string) ([]string, error) {
+	yamlManifests, err := extractManifests(cm, featureFlags)
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract manifests from configMap: %w", err)
 	}
@@ -377,7 +674,7 @@ func (r *CapiInstallerController) extractProviderComponents(cm corev1.ConfigMap)
 
 	for _, m := range yamlManifests {
 		newM := strings.Replace(m, imagePlaceholder, r.Images[providerNameToImageKey(providerName)], 1)
-		newM = strings.Replace(newM, "registry.ci.openshift.org/openshift:kube-rbac-proxy", r.Images["kube-rbac-proxy"], 1)
+		newM = strings.Replace(newM, "registry.ci.openshift.org/openshift:kube-rbac-proxy", r.Images[kubeRBACProxyImageKey], 1)
 		// TODO: change this to manager in the forked providers openshift/Dockerfile.rhel.
 		newM = strings.Replace(newM, "/manager", providerNameToCommand(providerName), 1)
 
@@ -389,7 +686,7 @@ func (r *CapiInstallerController) extractProviderComponents(cm corev1.ConfigMap)
 
 // extractManifests extracts and processes component manifests from given ConfiMap.
 // If the data is in compressed binary form, it decompresses them.
-func extractManifests(cm corev1.ConfigMap) ([]string, error) {
+func extractManifests(cm corev1.ConfigMap, featureFlags map[string]string) ([]string, error) {
 	data, hasData := cm.Data["components"]
 	binaryData, hasBinary := cm.BinaryData["components-zstd"]
 
@@ -412,9 +709,10 @@ func extractManifests(cm corev1.ConfigMap) ([]string, error) {
 	}
 
 	// Certain provider components have drone/envsubst environment variables interpolated within the manifest.
-	// Substitute them with the value defined in the environment variable (see setFeatureGatesEnvVars()).
-	// If that's not set, fallback to the default value defined in the template.
-	components, err := envsubst.EvalEnv(data)
+	// The ones gating the ignition bootstrap format are enabled for the providers that have them, the provider feature
+	// flags are set from the OpenShift feature gates, the other ones are substituted with the value of the environment
+	// variable, or fall back to the default value defined in the template.
+	components, err := envsubst.Eval(data, bootstrapFormatMapping(data, featureFlags))
 	if err != nil {
 		return nil, fmt.Errorf("failed to substitute environment variables in component manifests: %w", err)
 	}
@@ -455,18 +753,6 @@ func getResourceName(namespace, name string) string {
 	return resourceName
 }
 
-// assetsFn is a resourceapply.AssetFunc.
-func assetFn(assetsMap map[string]string) resourceapply.AssetFunc {
-	return func(name string) ([]byte, error) {
-		o, ok := assetsMap[name]
-		if !ok {
-			return nil, fmt.Errorf("error fetching resource %s: %w", name, errResourceNotFound)
-		}
-
-		return []byte(o), nil
-	}
-}
-
 // yamlToRuntimeObject parses a YAML manifest into a runtime.Object.
 func yamlToRuntimeObject(sch *runtime.Scheme, m string) (runtime.Object, error) {
 	decode := serializer.NewCodecFactory(sch).UniversalDeserializer().Decode
@@ -20,6 +20,11 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+
+	configv1 "github.com/openshift/api/config/v1"
 )
 
 var _ = Describe("CAPI installer", func() {
@@ -93,7 +98,7 @@ var _ = Describe("extractManifests", func() {
 
 	for _, tc := range testCases {
 		It(tc.name, func() {
-			manifests, err := extractManifests(tc.configMap)
+			manifests, err := extractManifests(tc.configMap, nil)
 
 			if tc.expectedError != nil {
 				Expect(err).To(MatchError(tc.expectedError))
@@ -105,3 +110,79 @@ var _ = Describe("extractManifests", func() {
 		})
 	}
 })
+
+var _ = Describe("disabledProviders", func() {
+	It("returns no providers when the annotation is not set", func() {
+		Expect(disabledProviders(nil)).To(BeEmpty())
+	})
+
+	It("returns the sorted providers without duplicates or blanks", func() {
+		Expect(disabledProviders(map[string]string{
+			DisabledProvidersAnnotation: " infrastructure, core,, infrastructure ",
+		})).To(Equal([]string{"core", "infrastructure"}))
+	})
+
+	It("matches a provider by type or by name", func() {
+		disabled := []string{"aws"}
+
+		Expect(isProviderDisabled(disabled, "infrastructure", "aws")).To(BeTrue())
+		Expect(isProviderDisabled(disabled, "core", "cluster-api")).To(BeFalse())
+		Expect(isProviderDisabled([]string{"core"}, "core", "cluster-api")).To(BeTrue())
+	})
+})
+
+var _ = Describe("getProviderRelatedObjects", func() {
+	It("returns the provider Deployments and CRDs", func() {
+		crdManifest := `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: awsmachines.infrastructure.cluster.x-k8s.io
+`
+		serviceManifest := `apiVersion: v1
+kind: Service
+metadata:
+  name: capa-webhook-service
+  namespace: openshift-cluster-api
+`
+
+		testScheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(testScheme)).To(Succeed())
+		Expect(apiextensionsv1.AddToScheme(testScheme)).To(Succeed())
+
+		relatedObjects, err := getProviderRelatedObjects(testScheme, []string{testManifest, crdManifest, serviceManifest})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(relatedObjects).To(Equal([]configv1.ObjectReference{
+			{Group: "apps", Resource: "deployments", Name: "nginx-deployment"},
+			{Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions", Name: "awsmachines.infrastructure.cluster.x-k8s.io"},
+		}))
+	})
+})
+
+var _ = Describe("isProviderTransportConfigMap", func() {
+	transportConfigMap := func(namespace, providerType, providerName string) *corev1.ConfigMap {
+		cm := &corev1.ConfigMap{}
+		cm.SetNamespace(namespace)
+		cm.SetLabels(map[string]string{
+			providerConfigMapLabelTypeKey: providerType,
+			providerConfigMapLabelNameKey: providerName,
+		})
+
+		return cm
+	}
+
+	It("matches the core provider ConfigMap", func() {
+		Expect(isProviderTransportConfigMap(transportConfigMap(defaultCAPINamespace, "core", "cluster-api"), defaultCAPINamespace, configv1.AWSPlatformType)).To(BeTrue())
+	})
+
+	It("matches the infrastructure provider ConfigMap of the current platform", func() {
+		Expect(isProviderTransportConfigMap(transportConfigMap(defaultCAPINamespace, "infrastructure", "ibmcloud"), defaultCAPINamespace, configv1.PowerVSPlatformType)).To(BeTrue())
+	})
+
+	It("does not match the infrastructure provider ConfigMap of another platform", func() {
+		Expect(isProviderTransportConfigMap(transportConfigMap(defaultCAPINamespace, "infrastructure", "gcp"), defaultCAPINamespace, configv1.AWSPlatformType)).To(BeFalse())
+	})
+
+	It("does not match a provider ConfigMap in another namespace", func() {
+		Expect(isProviderTransportConfigMap(transportConfigMap("default", "core", "cluster-api"), defaultCAPINamespace, configv1.AWSPlatformType)).To(BeFalse())
+	})
+})
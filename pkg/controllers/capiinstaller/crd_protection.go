@@ -0,0 +1,103 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capiinstaller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const reasonProviderCRDDeleted = "ProviderCRDDeleted"
+
+var errProviderCRDsDeleted = errors.New("provider CRDs deleted")
+
+// crdDeletions describes the provider CRDs deleted since they were installed. Their deletion is denied by the CRD
+// protection admission policy, unless allowed with the AllowCRDDeletionAnnotation, or while the policy is missing.
+type crdDeletions struct {
+	// recreated lists the CRDs deleted, and recreated.
+	recreated []string
+}
+
+// err returns an error describing the deletions, or nil if there are none.
+func (d crdDeletions) err() error {
+	if len(d.recreated) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: CRDs %s were deleted and have been recreated, their custom resources are lost",
+		errProviderCRDsDeleted, strings.Join(d.recreated, ", "))
+}
+
+// findDeletedProviderCRDs returns the provider CRDs to apply which were installed by a previous reconcile, and no
+// longer exist. They are recreated by the apply, without their custom resources.
+//
+// The protection finalizer, set on the CRDs by previous versions of the controller, is no longer part of the applied
+// CRDs, so it is removed by the apply, which owns it.
+func (r *CapiInstallerController) findDeletedProviderCRDs(ctx context.Context, objs []*unstructured.Unstructured) (crdDeletions, error) {
+	deletions := crdDeletions{}
+
+	co, err := r.GetOrCreateClusterOperator(ctx)
+	if err != nil {
+		return deletions, fmt.Errorf("unable to get cluster operator: %w", err)
+	}
+
+	// The CRDs reported in the related objects were installed by a previous reconcile.
+	installed := sets.New[string]()
+
+	for _, ref := range co.Status.RelatedObjects {
+		if ref.Group == apiextensionsv1.GroupName && ref.Resource == "customresourcedefinitions" {
+			installed.Insert(ref.Name)
+		}
+	}
+
+	for _, obj := range objs {
+		if obj.GroupVersionKind().GroupKind() != apiextensionsv1.Kind("CustomResourceDefinition") || !installed.Has(obj.GetName()) {
+			continue
+		}
+
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := r.Get(ctx, client.ObjectKey{Name: obj.GetName()}, crd); apierrors.IsNotFound(err) {
+			deletions.recreated = append(deletions.recreated, obj.GetName())
+		} else if err != nil {
+			return deletions, fmt.Errorf("unable to get CRD %q: %w", obj.GetName(), err)
+		}
+	}
+
+	return deletions, nil
+}
+
+// recordCRDDeletions records a warning event on the ClusterOperator for the deleted provider CRDs.
+func (r *CapiInstallerController) recordCRDDeletions(ctx context.Context, err error) {
+	if r.Recorder == nil {
+		return
+	}
+
+	co, coErr := r.GetOrCreateClusterOperator(ctx)
+	if coErr != nil {
+		return
+	}
+
+	r.Recorder.Event(co, corev1.EventTypeWarning, reasonProviderCRDDeleted, err.Error())
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capiinstaller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
+)
+
+var _ = Describe("findDeletedProviderCRDs", func() {
+	const (
+		installedCRDName = "awsclusters.infrastructure.cluster.x-k8s.io"
+		newCRDName       = "awsmachines.infrastructure.cluster.x-k8s.io"
+	)
+
+	var (
+		ctx    = context.Background()
+		scheme *runtime.Scheme
+	)
+
+	crdComponent := func(name string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("apiextensions.k8s.io/v1")
+		u.SetKind("CustomResourceDefinition")
+		u.SetName(name)
+
+		return u
+	}
+
+	newController := func(objs ...client.Object) *CapiInstallerController {
+		co := &configv1.ClusterOperator{}
+		co.SetName(clusterOperatorName)
+		co.Status.RelatedObjects = []configv1.ObjectReference{
+			{Group: apiextensionsv1.GroupName, Resource: "customresourcedefinitions", Name: installedCRDName},
+		}
+
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(objs, co)...).WithStatusSubresource(co).Build()
+
+		return &CapiInstallerController{
+			ClusterOperatorStatusClient: operatorstatus.ClusterOperatorStatusClient{Client: cl},
+		}
+	}
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Expect(configv1.Install(scheme)).To(Succeed())
+		Expect(apiextensionsv1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	It("does not report the installed CRDs, nor the new ones", func() {
+		r := newController(&apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: installedCRDName}})
+
+		deletions, err := r.findDeletedProviderCRDs(ctx, []*unstructured.Unstructured{crdComponent(installedCRDName), crdComponent(newCRDName)})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deletions.err()).ToNot(HaveOccurred())
+	})
+
+	It("reports an installed CRD which no longer exists as recreated", func() {
+		r := newController()
+
+		deletions, err := r.findDeletedProviderCRDs(ctx, []*unstructured.Unstructured{crdComponent(installedCRDName), crdComponent(newCRDName)})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deletions.recreated).To(ConsistOf(installedCRDName))
+		Expect(deletions.err()).To(MatchError(ContainSubstring("custom resources are lost")))
+	})
+})
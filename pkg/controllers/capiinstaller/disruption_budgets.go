@@ -0,0 +1,53 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capiinstaller
+
+import (
+	"context"
+	"fmt"
+
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// removePodDisruptionBudgets removes from the components to apply the PodDisruptionBudgets shipped with the provider
+// Deployments, and deletes their installed copies, so that disabling them removes the budgets already installed.
+// The other components are returned as is.
+func (r *CapiInstallerController) removePodDisruptionBudgets(ctx context.Context, objs []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	applied := []*unstructured.Unstructured{}
+
+	for _, obj := range objs {
+		if obj.GroupVersionKind().GroupKind() != policyv1.SchemeGroupVersion.WithKind("PodDisruptionBudget").GroupKind() {
+			applied = append(applied, obj)
+			continue
+		}
+
+		if err := r.Delete(ctx, obj); apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to delete CAPI provider pod disruption budget %q: %w", getResourceName(obj.GetNamespace(), obj.GetName()), err)
+		}
+
+		log.Info("CAPI provider pod disruption budgets are disabled, deleted pod disruption budget",
+			"name", getResourceName(obj.GetNamespace(), obj.GetName()))
+	}
+
+	return applied, nil
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capiinstaller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
+)
+
+var _ = Describe("Provider disruption budgets and priority", func() {
+	const (
+		namespace      = "openshift-cluster-api"
+		deploymentName = "capa-controller-manager"
+	)
+
+	var ctx = context.Background()
+
+	component := func(apiVersion, kind, name string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion(apiVersion)
+		u.SetKind(kind)
+		u.SetNamespace(namespace)
+		u.SetName(name)
+
+		return u
+	}
+
+	newController := func(objs ...client.Object) (*CapiInstallerController, client.Client) {
+		scheme := runtime.NewScheme()
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+
+		return &CapiInstallerController{
+			ClusterOperatorStatusClient: operatorstatus.ClusterOperatorStatusClient{Client: cl},
+		}, cl
+	}
+
+	Context("removePodDisruptionBudgets", func() {
+		It("skips the PodDisruptionBudgets and deletes the installed ones", func() {
+			installed := &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: deploymentName}}
+			r, cl := newController(installed)
+
+			objs, err := r.removePodDisruptionBudgets(ctx, []*unstructured.Unstructured{
+				component("apps/v1", "Deployment", deploymentName),
+				component("policy/v1", "PodDisruptionBudget", deploymentName),
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(objs).To(ConsistOf(HaveField("Object", HaveKeyWithValue("kind", "Deployment"))))
+
+			err = cl.Get(ctx, client.ObjectKeyFromObject(installed), &policyv1.PodDisruptionBudget{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("skips the PodDisruptionBudgets that are not installed", func() {
+			r, _ := newController()
+
+			objs, err := r.removePodDisruptionBudgets(ctx, []*unstructured.Unstructured{component("policy/v1", "PodDisruptionBudget", deploymentName)})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(objs).To(BeEmpty())
+		})
+	})
+
+	Context("renderDeployment", func() {
+		shippedDeployment := func() *unstructured.Unstructured {
+			deployment := &appsv1.Deployment{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: deploymentName},
+				Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					PriorityClassName: "system-cluster-critical",
+				}}},
+			}
+
+			obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(deployment)
+			Expect(err).ToNot(HaveOccurred())
+
+			return &unstructured.Unstructured{Object: obj}
+		}
+
+		priorityClassName := func(u *unstructured.Unstructured) string {
+			value, _, err := unstructured.NestedString(u.Object, "spec", "template", "spec", "priorityClassName")
+			Expect(err).ToNot(HaveOccurred())
+
+			return value
+		}
+
+		It("keeps the shipped priority class by default", func() {
+			r, _ := newController()
+
+			u, err := r.renderDeployment(shippedDeployment(), false, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(priorityClassName(u)).To(Equal("system-cluster-critical"))
+		})
+
+		It("replaces the priority class with the configured one", func() {
+			r, _ := newController()
+			r.PriorityClassName = "openshift-user-critical"
+
+			u, err := r.renderDeployment(shippedDeployment(), false, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(priorityClassName(u)).To(Equal("openshift-user-critical"))
+		})
+
+		It("keeps the bootstrap priority class while running on the host network", func() {
+			r, _ := newController()
+			r.PriorityClassName = "openshift-user-critical"
+			r.BootstrapHostNetwork = true
+
+			u, err := r.renderDeployment(shippedDeployment(), false, true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(priorityClassName(u)).To(Equal(bootstrapPriorityClassName))
+		})
+	})
+})
@@ -0,0 +1,65 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capiinstaller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+)
+
+// holdManuallyChangedDeployments removes from the components to apply the provider Deployments whose installed copy
+// has the AllowManualChangesAnnotation, so that their manual changes are not reverted, and returns their installed
+// copies. The other components, and the Deployments without the annotation, are returned as is, their drift is
+// reverted when they are applied.
+func (r *CapiInstallerController) holdManuallyChangedDeployments(ctx context.Context, objs []*unstructured.Unstructured) ([]*unstructured.Unstructured, []*appsv1.Deployment, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	applied := []*unstructured.Unstructured{}
+	held := []*appsv1.Deployment{}
+
+	for _, obj := range objs {
+		if obj.GroupVersionKind().GroupKind() != appsv1.SchemeGroupVersion.WithKind("Deployment").GroupKind() {
+			applied = append(applied, obj)
+			continue
+		}
+
+		deployment := &appsv1.Deployment{}
+
+		switch err := r.Get(ctx, client.ObjectKeyFromObject(obj), deployment); {
+		case apierrors.IsNotFound(err):
+			applied = append(applied, obj)
+		case err != nil:
+			return nil, nil, fmt.Errorf("unable to get CAPI provider deployment %q: %w", getResourceName(obj.GetNamespace(), obj.GetName()), err)
+		case deployment.Annotations[consts.AllowManualChangesAnnotation] == "true":
+			log.Info("CAPI provider deployment allows manual changes, not updating it",
+				"name", getResourceName(deployment.Namespace, deployment.Name), "annotation", consts.AllowManualChangesAnnotation)
+
+			held = append(held, deployment)
+		default:
+			applied = append(applied, obj)
+		}
+	}
+
+	return applied, held, nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capiinstaller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
+)
+
+var _ = Describe("holdManuallyChangedDeployments", func() {
+	const (
+		namespace      = "openshift-cluster-api"
+		deploymentName = "capa-controller-manager"
+	)
+
+	var ctx = context.Background()
+
+	component := func(apiVersion, kind, name string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion(apiVersion)
+		u.SetKind(kind)
+		u.SetNamespace(namespace)
+		u.SetName(name)
+
+		return u
+	}
+
+	newController := func(objs ...client.Object) *CapiInstallerController {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+
+		return &CapiInstallerController{
+			ClusterOperatorStatusClient: operatorstatus.ClusterOperatorStatusClient{Client: cl},
+		}
+	}
+
+	installedDeployment := func(annotations map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: deploymentName, Annotations: annotations},
+		}
+	}
+
+	It("applies the Deployments that are not installed yet", func() {
+		r := newController()
+
+		objs, held, err := r.holdManuallyChangedDeployments(ctx, []*unstructured.Unstructured{component("apps/v1", "Deployment", deploymentName)})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(objs).To(HaveLen(1))
+		Expect(held).To(BeEmpty())
+	})
+
+	It("applies the installed Deployments, reverting their drift", func() {
+		r := newController(installedDeployment(nil))
+
+		objs, held, err := r.holdManuallyChangedDeployments(ctx, []*unstructured.Unstructured{component("apps/v1", "Deployment", deploymentName)})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(objs).To(HaveLen(1))
+		Expect(held).To(BeEmpty())
+	})
+
+	It("holds the Deployments allowing manual changes", func() {
+		r := newController(installedDeployment(map[string]string{consts.AllowManualChangesAnnotation: "true"}))
+
+		objs, held, err := r.holdManuallyChangedDeployments(ctx, []*unstructured.Unstructured{
+			component("apps/v1", "Deployment", deploymentName),
+			component("v1", "Service", deploymentName),
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(objs).To(ConsistOf(HaveField("Object", HaveKeyWithValue("kind", "Service"))))
+		Expect(held).To(ConsistOf(HaveField("Name", deploymentName)))
+	})
+})
@@ -0,0 +1,154 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capiinstaller
+
+import (
+	"path"
+	"slices"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// kubeRBACProxyContainerName is the name of the sidecar the providers ship to serve their metrics securely.
+	kubeRBACProxyContainerName = "kube-rbac-proxy"
+
+	// managerContainerName is the name of the provider manager containers, as scaffolded by kubebuilder.
+	managerContainerName = "manager"
+
+	// metricsServerCertDir is the directory the controller-runtime metrics server loads its certificate from,
+	// and watches to reload it when it is rotated.
+	metricsServerCertDir = "/tmp/k8s-metrics-server/serving-certs"
+)
+
+// metricsBindAddressArgs are the flags the provider managers used to bind their insecure metrics endpoint proxied by
+// kube-rbac-proxy.
+var metricsBindAddressArgs = []string{"--metrics-bind-addr", "--metrics-bind-address"}
+
+// replaceKubeRBACProxy removes the kube-rbac-proxy sidecar from a provider Deployment, and makes its manager serve
+// the metrics on the secure diagnostics endpoint instead.
+// The diagnostics endpoint authenticates and authorizes the requests itself, with the controller-runtime
+// WithAuthenticationAndAuthorization filter, so the sidecar is no longer needed.
+// The endpoint listens on the address, and serves the certificate, the sidecar was configured with, so that the
+// Services and ServiceMonitors of the provider keep working.
+// Deployments without the sidecar are left untouched.
+func replaceKubeRBACProxy(deployment *appsv1.Deployment) {
+	podSpec := &deployment.Spec.Template.Spec
+
+	proxyIndex := slices.IndexFunc(podSpec.Containers, func(c corev1.Container) bool { return c.Name == kubeRBACProxyContainerName })
+	if proxyIndex < 0 {
+		return
+	}
+
+	proxy := podSpec.Containers[proxyIndex]
+	podSpec.Containers = slices.Delete(podSpec.Containers, proxyIndex, proxyIndex+1)
+
+	managerIndex := managerContainerIndex(podSpec.Containers)
+	if managerIndex < 0 {
+		return
+	}
+
+	manager := &podSpec.Containers[managerIndex]
+
+	manager.Args = slices.DeleteFunc(manager.Args, func(arg string) bool {
+		return slices.Contains(metricsBindAddressArgs, argName(arg)) || argName(arg) == "--diagnostics-address"
+	})
+
+	if address, ok := argValue(proxy.Args, "--secure-listen-address"); ok {
+		manager.Args = append(manager.Args, "--diagnostics-address="+address)
+	}
+
+	for _, port := range proxy.Ports {
+		if !slices.ContainsFunc(manager.Ports, func(p corev1.ContainerPort) bool { return p.ContainerPort == port.ContainerPort }) {
+			manager.Ports = append(manager.Ports, port)
+		}
+	}
+
+	moveServingCertMount(proxy, manager)
+}
+
+// managerContainerIndex returns the index of the manager container of a provider Deployment, the one binding the
+// metrics endpoint, or the one named after the kubebuilder scaffolding, or the first one.
+func managerContainerIndex(containers []corev1.Container) int {
+	if i := slices.IndexFunc(containers, func(c corev1.Container) bool {
+		return slices.ContainsFunc(c.Args, func(arg string) bool { return slices.Contains(metricsBindAddressArgs, argName(arg)) })
+	}); i >= 0 {
+		return i
+	}
+
+	if i := slices.IndexFunc(containers, func(c corev1.Container) bool { return c.Name == managerContainerName }); i >= 0 {
+		return i
+	}
+
+	if len(containers) > 0 {
+		return 0
+	}
+
+	return -1
+}
+
+// moveServingCertMount mounts the certificate served by kube-rbac-proxy in the manager, where its metrics server
+// loads it from. The metrics server expects the tls.crt and tls.key file names, other certificates are left
+// out and the metrics server generates a self-signed one.
+func moveServingCertMount(proxy corev1.Container, manager *corev1.Container) {
+	certFile, ok := argValue(proxy.Args, "--tls-cert-file")
+	if !ok || path.Base(certFile) != corev1.TLSCertKey {
+		return
+	}
+
+	if keyFile, ok := argValue(proxy.Args, "--tls-private-key-file"); ok && (path.Base(keyFile) != corev1.TLSPrivateKeyKey || path.Dir(keyFile) != path.Dir(certFile)) {
+		return
+	}
+
+	for _, mount := range proxy.VolumeMounts {
+		if path.Clean(mount.MountPath) != path.Dir(certFile) || mount.SubPath != "" {
+			continue
+		}
+
+		if slices.ContainsFunc(manager.VolumeMounts, func(m corev1.VolumeMount) bool { return path.Clean(m.MountPath) == metricsServerCertDir }) {
+			return
+		}
+
+		mount.MountPath = metricsServerCertDir
+		mount.ReadOnly = true
+		manager.VolumeMounts = append(manager.VolumeMounts, mount)
+
+		return
+	}
+}
+
+// argName returns the name of a --name=value argument.
+func argName(arg string) string {
+	name, _, _ := strings.Cut(arg, "=")
+
+	return name
+}
+
+// argValue returns the value of the first --name=value argument with the given name.
+func argValue(args []string, name string) (string, bool) {
+	for _, arg := range args {
+		if argName(arg) == name {
+			_, value, _ := strings.Cut(arg, "=")
+
+			return value, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capiinstaller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("kube-rbac-proxy replacement", func() {
+	newDeployment := func(proxyCertDir string) *appsv1.Deployment {
+		deployment := &appsv1.Deployment{}
+		deployment.Spec.Template.Spec.Containers = []corev1.Container{
+			{
+				Name: kubeRBACProxyContainerName,
+				Args: []string{
+					"--secure-listen-address=0.0.0.0:8443",
+					"--upstream=http://127.0.0.1:8080/",
+					"--tls-cert-file=" + proxyCertDir + "/tls.crt",
+					"--tls-private-key-file=" + proxyCertDir + "/tls.key",
+					"--v=0",
+				},
+				Ports:        []corev1.ContainerPort{{Name: "https", ContainerPort: 8443}},
+				VolumeMounts: []corev1.VolumeMount{{Name: "metrics-cert", MountPath: "/etc/tls/private"}},
+			},
+			{
+				Name:  managerContainerName,
+				Args:  []string{"--leader-elect", "--metrics-bind-addr=127.0.0.1:8080"},
+				Ports: []corev1.ContainerPort{{Name: "webhook-server", ContainerPort: 9443}},
+			},
+		}
+
+		return deployment
+	}
+
+	It("makes the manager serve the metrics on the address and with the certificate of the sidecar", func() {
+		deployment := newDeployment("/etc/tls/private")
+
+		replaceKubeRBACProxy(deployment)
+
+		Expect(deployment.Spec.Template.Spec.Containers).To(HaveLen(1))
+
+		manager := deployment.Spec.Template.Spec.Containers[0]
+		Expect(manager.Name).To(Equal(managerContainerName))
+		Expect(manager.Args).To(Equal([]string{"--leader-elect", "--diagnostics-address=0.0.0.0:8443"}))
+		Expect(manager.Ports).To(ConsistOf(
+			corev1.ContainerPort{Name: "webhook-server", ContainerPort: 9443},
+			corev1.ContainerPort{Name: "https", ContainerPort: 8443},
+		))
+		Expect(manager.VolumeMounts).To(ConsistOf(
+			corev1.VolumeMount{Name: "metrics-cert", MountPath: metricsServerCertDir, ReadOnly: true},
+		))
+	})
+
+	It("does not mount a certificate the metrics server cannot load", func() {
+		deployment := newDeployment("/etc/tls/other")
+
+		replaceKubeRBACProxy(deployment)
+
+		Expect(deployment.Spec.Template.Spec.Containers).To(HaveLen(1))
+		Expect(deployment.Spec.Template.Spec.Containers[0].VolumeMounts).To(BeEmpty())
+	})
+
+	It("leaves the deployments without the sidecar untouched", func() {
+		deployment := newDeployment("/etc/tls/private")
+		deployment.Spec.Template.Spec.Containers = deployment.Spec.Template.Spec.Containers[1:]
+
+		replaceKubeRBACProxy(deployment)
+
+		Expect(deployment.Spec.Template.Spec.Containers).To(Equal(newDeployment("/etc/tls/private").Spec.Template.Spec.Containers[1:]))
+	})
+})
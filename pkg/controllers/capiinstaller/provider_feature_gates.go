@@ -0,0 +1,87 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capiinstaller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+const featureGateName = "cluster"
+
+// providerFeatureFlag is a feature flag of the CAPI providers, templated in their components with a drone/envsubst
+// variable, such as ${EXP_MACHINE_POOL:=true} for the MachinePool feature gate of the core provider, CAPA and CAPZ.
+type providerFeatureFlag struct {
+	variable string
+	// featureGate is the OpenShift feature gate enabling the flag. Flags without one are always disabled.
+	featureGate configv1.FeatureGateName
+}
+
+// providerFeatureFlags are set from the OpenShift feature gates, rather than from the defaults of the templates, which
+// enable features OpenShift does not support, such as MachinePools or ClusterClass topologies.
+var providerFeatureFlags = []providerFeatureFlag{
+	{variable: "EXP_MACHINE_POOL"},
+	{variable: "CLUSTER_TOPOLOGY"},
+	{variable: "EXP_CLUSTER_RESOURCE_SET"},
+	{variable: "EXP_RUNTIME_SDK"},
+	{variable: "EXP_MACHINE_SET_PREFLIGHT_CHECKS"},
+}
+
+// featureFlagValues returns the values of the provider feature flags for the enabled OpenShift feature gates,
+// keyed by their drone/envsubst variable.
+func featureFlagValues(flags []providerFeatureFlag, enabled sets.Set[configv1.FeatureGateName]) map[string]string {
+	values := make(map[string]string, len(flags))
+
+	for _, flag := range flags {
+		values[flag.variable] = strconv.FormatBool(flag.featureGate != "" && enabled.Has(flag.featureGate))
+	}
+
+	return values
+}
+
+// getEnabledFeatureGates returns the OpenShift feature gates enabled for the given release payload version.
+// None are enabled when the FeatureGate is not available, or not rendered for the version yet.
+func (r *CapiInstallerController) getEnabledFeatureGates(ctx context.Context, payloadVersion string) (sets.Set[configv1.FeatureGateName], error) {
+	enabled := sets.New[configv1.FeatureGateName]()
+
+	featureGate := &configv1.FeatureGate{}
+	if err := r.Get(ctx, client.ObjectKey{Name: featureGateName}, featureGate); apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return enabled, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to get FeatureGate: %w", err)
+	}
+
+	for _, details := range featureGate.Status.FeatureGates {
+		if details.Version != payloadVersion {
+			continue
+		}
+
+		for _, gate := range details.Enabled {
+			enabled.Insert(gate.Name)
+		}
+	}
+
+	return enabled, nil
+}
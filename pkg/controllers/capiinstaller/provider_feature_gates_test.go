@@ -0,0 +1,104 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capiinstaller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
+)
+
+const featureFlagComponents = `apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - args:
+        - --feature-gates=MachinePool=${EXP_MACHINE_POOL:=true},ClusterTopology=${CLUSTER_TOPOLOGY:=true},EKS=${CAPA_EKS:=false}
+`
+
+var _ = Describe("Provider feature gates", func() {
+	const gatedFeature configv1.FeatureGateName = "ExampleMachinePools"
+
+	ctx := context.Background()
+
+	flags := []providerFeatureFlag{
+		{variable: "EXP_MACHINE_POOL", featureGate: gatedFeature},
+		{variable: "CLUSTER_TOPOLOGY"},
+	}
+
+	newController := func(featureGates ...configv1.FeatureGateDetails) *CapiInstallerController {
+		scheme := runtime.NewScheme()
+		Expect(configv1.Install(scheme)).To(Succeed())
+
+		builder := fake.NewClientBuilder().WithScheme(scheme)
+		if featureGates != nil {
+			builder = builder.WithObjects(&configv1.FeatureGate{
+				ObjectMeta: metav1.ObjectMeta{Name: featureGateName},
+				Status:     configv1.FeatureGateStatus{FeatureGates: featureGates},
+			})
+		}
+
+		return &CapiInstallerController{ClusterOperatorStatusClient: operatorstatus.ClusterOperatorStatusClient{Client: builder.Build()}}
+	}
+
+	It("disables the flags whose feature gate is not enabled", func() {
+		Expect(featureFlagValues(flags, sets.New[configv1.FeatureGateName]())).To(Equal(map[string]string{
+			"EXP_MACHINE_POOL": "false",
+			"CLUSTER_TOPOLOGY": "false",
+		}))
+
+		Expect(featureFlagValues(flags, sets.New(gatedFeature))).To(Equal(map[string]string{
+			"EXP_MACHINE_POOL": "true",
+			"CLUSTER_TOPOLOGY": "false",
+		}))
+	})
+
+	It("templates the flags into the provider components", func() {
+		manifests, err := extractManifests(corev1.ConfigMap{Data: map[string]string{"components": featureFlagComponents}},
+			featureFlagValues(flags, sets.New(gatedFeature)))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manifests).To(ConsistOf(ContainSubstring("MachinePool=true,ClusterTopology=false,EKS=false")))
+	})
+
+	It("reads the feature gates enabled for the payload version", func() {
+		r := newController(
+			configv1.FeatureGateDetails{Version: "4.18.0", Enabled: []configv1.FeatureGateAttributes{{Name: "Old"}}},
+			configv1.FeatureGateDetails{Version: "4.18.1", Enabled: []configv1.FeatureGateAttributes{{Name: gatedFeature}}},
+		)
+
+		enabled, err := r.getEnabledFeatureGates(ctx, "4.18.1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(enabled).To(Equal(sets.New(gatedFeature)))
+	})
+
+	It("enables no feature gates without a FeatureGate", func() {
+		enabled, err := newController().getEnabledFeatureGates(ctx, "4.18.1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(enabled).To(BeEmpty())
+	})
+})
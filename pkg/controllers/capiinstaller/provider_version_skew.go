@@ -0,0 +1,113 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capiinstaller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+const (
+	clusterVersionName = "version"
+
+	// ReleaseVersionAnnotation is set on the provider Deployments to the release version of the operator that applied them.
+	// It pins the provider images to the release payload they were delivered with, so that a Deployment left behind by
+	// a previous release is told apart from an up to date one.
+	ReleaseVersionAnnotation = "cluster-api.openshift.io/release-version"
+)
+
+// getPayloadVersion returns the version of the release payload the cluster is at, or is being updated to.
+// The operator version is returned when the ClusterVersion is not available, as on clusters not managed by the CVO.
+func (r *CapiInstallerController) getPayloadVersion(ctx context.Context) (string, error) {
+	clusterVersion := &configv1.ClusterVersion{}
+	if err := r.Get(ctx, client.ObjectKey{Name: clusterVersionName}, clusterVersion); apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return r.ReleaseVersion, nil
+	} else if err != nil {
+		return "", fmt.Errorf("unable to get ClusterVersion: %w", err)
+	}
+
+	if clusterVersion.Status.Desired.Version == "" {
+		return r.ReleaseVersion, nil
+	}
+
+	return clusterVersion.Status.Desired.Version, nil
+}
+
+// isMinorVersionSkewed returns whether the two release versions differ by their major or minor version.
+// Versions that cannot be parsed, such as the unknown version of development builds, are never skewed.
+func isMinorVersionSkewed(operatorVersion, payloadVersion string) bool {
+	operator, err := version.ParseGeneric(operatorVersion)
+	if err != nil {
+		return false
+	}
+
+	payload, err := version.ParseGeneric(payloadVersion)
+	if err != nil {
+		return false
+	}
+
+	return operator.Major() != payload.Major() || operator.Minor() != payload.Minor()
+}
+
+// deploymentVersionSkew returns why the provider Deployment does not match the release payload, or an empty string
+// when it does. A Deployment matches once it is pinned to the payload version and all of its replicas were updated to
+// the images it was pinned with.
+func deploymentVersionSkew(deployment *appsv1.Deployment, payloadVersion string) string {
+	name := getResourceName(deployment.GetNamespace(), deployment.GetName())
+
+	if pinned := deployment.GetAnnotations()[ReleaseVersionAnnotation]; pinned != payloadVersion {
+		return fmt.Sprintf("deployment %s is pinned to release %q instead of %q", name, pinned, payloadVersion)
+	}
+
+	replicas := ptr.Deref(deployment.Spec.Replicas, 1)
+	status := deployment.Status
+
+	if status.ObservedGeneration < deployment.GetGeneration() || status.UpdatedReplicas != replicas || status.Replicas != replicas {
+		return fmt.Sprintf("deployment %s is rolling out the images of release %q", name, payloadVersion)
+	}
+
+	return ""
+}
+
+// syncProviderVersionSkew sets the ClusterOperator Progressing while the providers are skewed from the release payload,
+// and reverts it once they are not.
+func (r *CapiInstallerController) syncProviderVersionSkew(ctx context.Context, skews []string) error {
+	if len(skews) == 0 {
+		if err := r.ClearProviderVersionSkew(ctx); err != nil {
+			return fmt.Errorf("failed to clear provider version skew: %w", err)
+		}
+
+		return nil
+	}
+
+	message := fmt.Sprintf("Waiting for the CAPI providers to match the release payload: %s", strings.Join(skews, "; "))
+
+	if err := r.SetProviderVersionSkew(ctx, message); err != nil {
+		return fmt.Errorf("failed to set provider version skew: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package capiinstaller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/utils/ptr"
+)
+
+var _ = Describe("isMinorVersionSkewed", func() {
+	DescribeTable("compares the operator and payload versions",
+		func(operatorVersion, payloadVersion string, expected bool) {
+			Expect(isMinorVersionSkewed(operatorVersion, payloadVersion)).To(Equal(expected))
+		},
+		Entry("same version", "4.17.3", "4.17.3", false),
+		Entry("patch update", "4.17.3", "4.17.5", false),
+		Entry("minor update", "4.17.3", "4.18.0", true),
+		Entry("major update", "4.17.3", "5.17.3", true),
+		Entry("nightly of the same minor", "4.18.0-0.nightly-2024-09-01-000000", "4.18.0", false),
+		Entry("unknown operator version", "unknown", "4.18.0", false),
+		Entry("empty payload version", "4.17.3", "", false),
+	)
+})
+
+var _ = Describe("deploymentVersionSkew", func() {
+	deployment := func(pinnedVersion string, generation, observedGeneration int64, updatedReplicas int32) *appsv1.Deployment {
+		d := &appsv1.Deployment{}
+		d.SetNamespace(defaultCAPINamespace)
+		d.SetName("capi-controller-manager")
+		d.SetGeneration(generation)
+		d.SetAnnotations(map[string]string{ReleaseVersionAnnotation: pinnedVersion})
+		d.Spec.Replicas = ptr.To(int32(2))
+		d.Status.ObservedGeneration = observedGeneration
+		d.Status.Replicas = 2
+		d.Status.UpdatedReplicas = updatedReplicas
+
+		return d
+	}
+
+	It("reports no skew for a rolled out Deployment pinned to the payload version", func() {
+		Expect(deploymentVersionSkew(deployment("4.18.0", 2, 2, 2), "4.18.0")).To(BeEmpty())
+	})
+
+	It("reports a Deployment pinned to another release", func() {
+		Expect(deploymentVersionSkew(deployment("4.17.3", 2, 2, 2), "4.18.0")).To(
+			Equal(`deployment openshift-cluster-api/capi-controller-manager is pinned to release "4.17.3" instead of "4.18.0"`))
+	})
+
+	It("reports a Deployment whose new generation was not observed yet", func() {
+		Expect(deploymentVersionSkew(deployment("4.18.0", 3, 2, 2), "4.18.0")).To(ContainSubstring("is rolling out"))
+	})
+
+	It("reports a Deployment with replicas left to update", func() {
+		Expect(deploymentVersionSkew(deployment("4.18.0", 2, 2, 1), "4.18.0")).To(ContainSubstring("is rolling out"))
+	})
+})
@@ -47,14 +47,40 @@ func toClusterOperator(ctx context.Context, cO client.Object) []reconcile.Reques
 	}}
 }
 
-// configMapPredicate defines a predicate function for owned ConfigMaps.
+// configMapPredicate defines a predicate function for owned ConfigMaps and provider transport ConfigMaps.
+// Changes to the transport ConfigMaps, delivered by the payload or edited manually, trigger a re-apply of the provider
+// components, including their ValidatingAdmissionPolicies and Bindings, without restarting the operator.
 func configMapPredicate(namespace string, platform configv1.PlatformType) predicate.Funcs {
+	isProviderConfigMap := func(obj runtime.Object) bool {
+		return isOwnedProviderComponent(obj, namespace, platform) || isProviderTransportConfigMap(obj, namespace, platform)
+	}
+
 	return predicate.Funcs{
-		CreateFunc:  func(e event.CreateEvent) bool { return isOwnedProviderComponent(e.Object, namespace, platform) },
-		UpdateFunc:  func(e event.UpdateEvent) bool { return isOwnedProviderComponent(e.ObjectNew, namespace, platform) },
-		DeleteFunc:  func(e event.DeleteEvent) bool { return isOwnedProviderComponent(e.Object, namespace, platform) },
-		GenericFunc: func(e event.GenericEvent) bool { return isOwnedProviderComponent(e.Object, namespace, platform) },
+		CreateFunc:  func(e event.CreateEvent) bool { return isProviderConfigMap(e.Object) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return isProviderConfigMap(e.ObjectNew) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return isProviderConfigMap(e.Object) },
+		GenericFunc: func(e event.GenericEvent) bool { return isProviderConfigMap(e.Object) },
+	}
+}
+
+// isProviderTransportConfigMap checks whether an object is a transport ConfigMap of the core or the current platform provider.
+// Transport ConfigMaps are identified by the provider labels the reconciler lists them with.
+func isProviderTransportConfigMap(obj runtime.Object, namespace string, platform configv1.PlatformType) bool {
+	cO, ok := obj.(client.Object)
+	if !ok || cO.GetNamespace() != namespace {
+		return false
+	}
+
+	labels := cO.GetLabels()
+
+	switch labels[providerConfigMapLabelTypeKey] {
+	case "core":
+		return labels[providerConfigMapLabelNameKey] == defaultCoreProviderComponentName
+	case "infrastructure":
+		return labels[providerConfigMapLabelNameKey] == platformToProviderConfigMapLabelNameValue(platform)
 	}
+
+	return false
 }
 
 // ownedPlatformLabelPredicate defines a predicate function for owned objects.
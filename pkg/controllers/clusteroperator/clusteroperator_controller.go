@@ -47,19 +47,24 @@ func (r *ClusterOperatorController) Reconcile(ctx context.Context, req ctrl.Requ
 	log := ctrl.LoggerFrom(ctx).WithName(controllerName)
 	log.Info(fmt.Sprintf("Reconciling %q ClusterObject", controllers.ClusterOperatorName))
 
+	availableConditionMsg := ""
 	if r.IsUnsupportedPlatform {
-		if err := r.ClusterOperatorStatusClient.SetStatusAvailable(ctx, capiUnsupportedPlatformMsg); err != nil {
-			return ctrl.Result{}, fmt.Errorf("failed to set conditions for %q ClusterObject: %w", controllers.ClusterOperatorName, err)
-		}
-	} else {
-		// TODO: wrap this into status aggregation logic to get these conditions conform,
-		// to the meaningful aggregation of all the other controllers ones.
-		if err := r.ClusterOperatorStatusClient.SetStatusAvailable(ctx, ""); err != nil {
-			return ctrl.Result{}, fmt.Errorf("failed to set conditions for %q ClusterObject: %w", controllers.ClusterOperatorName, err)
-		}
+		availableConditionMsg = capiUnsupportedPlatformMsg
 	}
 
-	return ctrl.Result{}, nil
+	// The Degraded condition is rolled up from the conditions reported by the other controllers.
+	if err := r.ClusterOperatorStatusClient.SetStatusAvailable(ctx, availableConditionMsg); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to set conditions for %q ClusterObject: %w", controllers.ClusterOperatorName, err)
+	}
+
+	co, err := r.GetOrCreateClusterOperator(ctx)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get %q ClusterObject: %w", controllers.ClusterOperatorName, err)
+	}
+
+	// Controllers degraded within their error budget do not update the ClusterOperator again when the budget runs out,
+	// so the roll up is re-evaluated then.
+	return ctrl.Result{RequeueAfter: r.DegradedRequeueAfter(co)}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
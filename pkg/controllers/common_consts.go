@@ -43,4 +43,140 @@ const (
 	// ReasonResourceSynchronized denotes that the resource is synchronized
 	// successfully.
 	ReasonResourceSynchronized = "ResourceSynchronized"
+
+	// DegradedCondition is set by a synchronization controller on a MAPI
+	// resource it stopped retrying to synchronize, after exhausting its retry
+	// budget. The resource is synchronized again when it changes.
+	DegradedCondition machinev1beta1.ConditionType = "Degraded"
+
+	// ReasonRetryBudgetExhausted denotes that a synchronization controller
+	// stopped retrying to synchronize the resource.
+	ReasonRetryBudgetExhausted = "RetryBudgetExhausted"
+
+	// PausedCondition is set by a synchronization controller on a MAPI
+	// resource while the synchronization of its namespace is paused with the
+	// util.SyncPausedAnnotation.
+	PausedCondition machinev1beta1.ConditionType = "Paused"
+
+	// ReasonSyncPaused denotes that the synchronization of the resource is
+	// paused.
+	ReasonSyncPaused = "SyncPaused"
+
+	// ReasonSyncResumed denotes that the synchronization of the resource was
+	// resumed after being paused.
+	ReasonSyncResumed = "SyncResumed"
+
+	// DriftedCondition is set by the machine set synchronization controller on a
+	// MAPI machine set whose MAPI and CAPI copies have differed for longer than
+	// the drift threshold, in the fields mirrored from the authoritative copy.
+	DriftedCondition machinev1beta1.ConditionType = "Drifted"
+
+	// ReasonMachineSetDrifted denotes that the MAPI and CAPI copies of the
+	// machine set differ.
+	ReasonMachineSetDrifted = "MachineSetDrifted"
+
+	// ReasonProviderIDMismatch denotes that the MAPI and CAPI copies of a
+	// machine, its InfraMachine or its Node disagree on the providerID, so the
+	// authority of the machine cannot be handed over without orphaning its Node.
+	ReasonProviderIDMismatch = "ProviderIDMismatch"
+
+	// DrainPendingCondition is set by the machine synchronization controller on
+	// a migrating MAPI machine whose Node is being drained, while the completion
+	// of the migration waits for the drain to settle.
+	DrainPendingCondition machinev1beta1.ConditionType = "DrainPending"
+
+	// ReasonDrainInProgress denotes that the Node of the machine is being
+	// drained, so the authority of the machine is not handed over yet.
+	ReasonDrainInProgress = "DrainInProgress"
+
+	// ReasonDrainSettled denotes that the Node of the machine is no longer
+	// being drained.
+	ReasonDrainSettled = "DrainSettled"
+
+	// ReasonDrainTimedOut denotes that the drain of the Node of the machine did
+	// not settle within the drain settle timeout, so the migration proceeds.
+	ReasonDrainTimedOut = "DrainTimedOut"
+
+	// InfraMachineRecreationBlockedCondition is set by the machine
+	// synchronization controller on a MAPI authoritative machine whose
+	// outdated InfraMachine mirror is being deleted, while a finalizer not
+	// owned by its provider keeps the InfraMachine from being removed, and so
+	// from being recreated.
+	InfraMachineRecreationBlockedCondition machinev1beta1.ConditionType = "InfraMachineRecreationBlocked"
+
+	// ReasonThirdPartyFinalizer denotes that the InfraMachine of the machine
+	// cannot be removed as it has finalizers the synchronization controller
+	// does not own.
+	ReasonThirdPartyFinalizer = "ThirdPartyFinalizer"
+
+	// ReasonInfraMachineReleased denotes that the InfraMachine whose removal
+	// was blocked has been removed.
+	ReasonInfraMachineReleased = "InfraMachineReleased"
+
+	// LastSyncTimeAnnotation records when a synchronization controller last
+	// created or updated a non-authoritative resource.
+	LastSyncTimeAnnotation = "cluster-api.openshift.io/last-sync-time"
+
+	// LastSyncHashAnnotation records a hash of the spec written by the last
+	// synchronization of a non-authoritative resource.
+	LastSyncHashAnnotation = "cluster-api.openshift.io/last-sync-hash"
+
+	// ConversionLossAnnotation records, on a non-authoritative resource, the
+	// JSON list of the paths of the fields that were dropped or modified when
+	// converting the authoritative resource, so that the data lost by a
+	// migration can be audited before committing to it.
+	ConversionLossAnnotation = "cluster-api.openshift.io/conversion-loss"
+
+	// InfraMachineTemplateMachineSetAnnotation records, on the CAPI
+	// InfraMachineTemplates created from a MAPI machine set, the name of that
+	// machine set, so that its templates that are no longer referenced can be
+	// found and removed.
+	InfraMachineTemplateMachineSetAnnotation = "cluster-api.openshift.io/machine-set"
+
+	// AdoptedLabel is set to "true" by the adoption controller on the CAPI
+	// resources created out of band that it adopted, so that they can be told
+	// apart from the ones created by the operator and its sync controllers.
+	AdoptedLabel = "cluster-api.openshift.io/adopted"
+
+	// ManagedByLabel is set to ManagedByLabelValue on the resources managed by
+	// the operator, such as the provider components, the admission policies and
+	// the ConfigMaps and Secrets it writes, so that they can be listed together,
+	// for instance by the must-gather collection script.
+	ManagedByLabel = "cluster-api.openshift.io/managed-by"
+
+	// ManagedByLabelValue is the value of the ManagedByLabel.
+	ManagedByLabelValue = "cluster-capi-operator"
+
+	// AllowManualChangesAnnotation allows, when set to "true" on a resource
+	// managed by the operator, such as a provider Deployment, the Cluster or
+	// the InfraCluster, to change it manually. The provider Deployments are not
+	// updated by the operator while it is set, so that their changes are kept.
+	AllowManualChangesAnnotation = "cluster-api.openshift.io/allow-manual-changes"
+
+	// AllowCRDDeletionAnnotation allows, when set to "true" on a provider CRD
+	// installed by the operator, to delete it, which the CRD protection policy
+	// otherwise denies as it deletes all its custom resources. The CRD is
+	// recreated once deleted, without its custom resources.
+	AllowCRDDeletionAnnotation = "cluster-api.openshift.io/allow-crd-deletion"
+
+	// ForceDeleteAnnotation allows, when set to "true" on the authoritative
+	// copy of a machine, to delete it while it is migrating or while its
+	// mirror is being synchronized, which the admission policies of the
+	// migration otherwise deny, as its instance could be stranded.
+	ForceDeleteAnnotation = "cluster-api.openshift.io/force-delete"
+
+	// ConversionReportConfigMapName is the name of the ConfigMap, in the CAPI
+	// namespace, reporting the data the conversion of the Machine API resources
+	// to Cluster API would lose.
+	ConversionReportConfigMapName = "machine-api-conversion-report"
+
+	// MigrationSummaryConfigMapName is the name of the ConfigMap, in the CAPI
+	// namespace, summarizing the migration of the Machine API resources to
+	// Cluster API.
+	MigrationSummaryConfigMapName = "machine-api-migration-summary"
+
+	// SyncFinalizer is set by the synchronization controllers on both the MAPI
+	// and CAPI copies of a resource, so that the deletion of either copy can be
+	// propagated to its counterpart before they are removed.
+	SyncFinalizer = "sync.machine.openshift.io/finalizer"
 )
@@ -0,0 +1,211 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package conversionreport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+)
+
+const (
+	controllerName = "ConversionReportController"
+
+	// ReportConfigMapName is the name of the ConfigMap, in the CAPI namespace, reporting the fields lost by the conversion
+	// of the resources synchronized between the Machine API and Cluster API.
+	ReportConfigMapName = consts.ConversionReportConfigMapName
+
+	// MachineSetsReportKey is the ConfigMap key holding the JSON report of the machine sets.
+	MachineSetsReportKey = "machinesets.json"
+)
+
+// MachineSetReport lists the fields dropped or modified when converting the authoritative copy of a machine set.
+type MachineSetReport struct {
+	// Name is the name of the machine set, shared by its MAPI and CAPI copies.
+	Name string `json:"name"`
+
+	// AuthoritativeAPI is the API of the copy the non-authoritative copy was converted from.
+	AuthoritativeAPI machinev1beta1.MachineAuthority `json:"authoritativeAPI"`
+
+	// LossyFields are the paths, in the authoritative copy, of the fields dropped or modified by the conversion.
+	LossyFields []string `json:"lossyFields"`
+}
+
+// ConversionReportReconciler aggregates the consts.ConversionLossAnnotation set by the sync controllers on the
+// non-authoritative resources into a single ConfigMap, so that fleet tooling can audit the data a migration would lose.
+type ConversionReportReconciler struct {
+	client.Client
+
+	MAPINamespace string
+	CAPINamespace string
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ConversionReportReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// All the watched resources contribute to the same report.
+	toReport := handler.EnqueueRequestsFromMapFunc(func(context.Context, client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: client.ObjectKey{Namespace: r.CAPINamespace, Name: ReportConfigMapName}}}
+	})
+
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named(util.NamespacedControllerName(controllerName, r.MAPINamespace)).
+		// The report is watched to restore it when it is modified or deleted.
+		For(&corev1.ConfigMap{}, builder.WithPredicates(util.FilterNamespace(r.CAPINamespace), reportPredicate())).
+		Watches(&machinev1beta1.MachineSet{}, toReport, builder.WithPredicates(util.FilterNamespace(r.MAPINamespace))).
+		Watches(&capiv1beta1.MachineSet{}, toReport, builder.WithPredicates(util.FilterNamespace(r.CAPINamespace))).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create controller: %w", err)
+	}
+
+	r.Client = mgr.GetClient()
+
+	return nil
+}
+
+// Reconcile writes the conversion report.
+func (r *ConversionReportReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := ctrl.LoggerFrom(ctx).WithName(controllerName)
+	ctx = ctrl.LoggerInto(ctx, logger)
+
+	mapiMachineSets := &machinev1beta1.MachineSetList{}
+	if err := r.List(ctx, mapiMachineSets, client.InNamespace(r.MAPINamespace)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list MAPI machine sets: %w", err)
+	}
+
+	capiMachineSets := &capiv1beta1.MachineSetList{}
+	if err := r.List(ctx, capiMachineSets, client.InNamespace(r.CAPINamespace)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list CAPI machine sets: %w", err)
+	}
+
+	reports := machineSetReports(ctx, mapiMachineSets.Items, capiMachineSets.Items)
+
+	reportsJSON, err := json.Marshal(reports)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to marshal machine set reports: %w", err)
+	}
+
+	cm := &corev1.ConfigMap{}
+	cm.SetNamespace(r.CAPINamespace)
+	cm.SetName(ReportConfigMapName)
+
+	result, err := controllerutil.CreateOrPatch(ctx, r.Client, cm, func() error {
+		util.SetManagedByLabel(cm)
+		cm.Data = map[string]string{MachineSetsReportKey: string(reportsJSON)}
+
+		return nil
+	})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to write conversion report: %w", err)
+	}
+
+	if result != controllerutil.OperationResultNone {
+		logger.Info("Updated conversion report", "lossyMachineSets", len(reports))
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// machineSetReports returns the report of each machine set whose conversion is lossy, sorted by name.
+// Only the annotation of the non-authoritative copy is considered, as the authoritative copy may still carry
+// the annotation written while it was not authoritative. Migrating machine sets have no non-authoritative copy.
+// Annotations that cannot be parsed are ignored, as they are rewritten on the next synchronization.
+func machineSetReports(ctx context.Context, mapiMachineSets []machinev1beta1.MachineSet, capiMachineSets []capiv1beta1.MachineSet) []MachineSetReport {
+	capiMachineSetsByName := map[string]*capiv1beta1.MachineSet{}
+	for i := range capiMachineSets {
+		capiMachineSetsByName[capiMachineSets[i].GetName()] = &capiMachineSets[i]
+	}
+
+	reports := []MachineSetReport{}
+
+	for i := range mapiMachineSets {
+		mapiMachineSet := &mapiMachineSets[i]
+
+		var nonAuthoritative client.Object
+
+		switch mapiMachineSet.Status.AuthoritativeAPI {
+		case machinev1beta1.MachineAuthorityMachineAPI:
+			if capiMachineSet, ok := capiMachineSetsByName[mapiMachineSet.GetName()]; ok {
+				nonAuthoritative = capiMachineSet
+			}
+		case machinev1beta1.MachineAuthorityClusterAPI:
+			nonAuthoritative = mapiMachineSet
+		}
+
+		if nonAuthoritative == nil {
+			continue
+		}
+
+		lossyFields, err := parseConversionLossAnnotation(nonAuthoritative)
+		if err != nil {
+			ctrl.LoggerFrom(ctx).Error(err, "Ignoring invalid conversion loss annotation", "machineSet", mapiMachineSet.GetName())
+			continue
+		}
+
+		if len(lossyFields) == 0 {
+			continue
+		}
+
+		reports = append(reports, MachineSetReport{
+			Name:             mapiMachineSet.GetName(),
+			AuthoritativeAPI: mapiMachineSet.Status.AuthoritativeAPI,
+			LossyFields:      lossyFields,
+		})
+	}
+
+	slices.SortFunc(reports, func(a, b MachineSetReport) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return reports
+}
+
+// parseConversionLossAnnotation returns the lossy fields recorded on the object, if any.
+func parseConversionLossAnnotation(obj client.Object) ([]string, error) {
+	value, ok := obj.GetAnnotations()[consts.ConversionLossAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	lossyFields := []string{}
+	if err := json.Unmarshal([]byte(value), &lossyFields); err != nil {
+		return nil, fmt.Errorf("failed to parse %s annotation of %T %s: %w", consts.ConversionLossAnnotation, obj, obj.GetName(), err)
+	}
+
+	return lossyFields, nil
+}
+
+// reportPredicate filters the events of the report ConfigMap.
+func reportPredicate() predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetName() == ReportConfigMapName
+	})
+}
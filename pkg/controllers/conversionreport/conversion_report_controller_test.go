@@ -0,0 +1,129 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package conversionreport
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	capiv1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/core/v1beta1"
+	corev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/core/v1"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest/komega"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/test"
+)
+
+var _ = Describe("machineSetReports", func() {
+	lossy := map[string]string{consts.ConversionLossAnnotation: `["spec.foo"]`}
+
+	It("should only report the lossy fields of the non-authoritative copies", func() {
+		mapiMachineSets := []machinev1beta1.MachineSet{
+			*machinev1resourcebuilder.MachineSet().WithName("mapi-authoritative").
+				WithAuthoritativeAPIStatus(machinev1beta1.MachineAuthorityMachineAPI).WithAnnotations(lossy).Build(),
+			*machinev1resourcebuilder.MachineSet().WithName("capi-authoritative").
+				WithAuthoritativeAPIStatus(machinev1beta1.MachineAuthorityClusterAPI).WithAnnotations(lossy).Build(),
+			*machinev1resourcebuilder.MachineSet().WithName("migrating").
+				WithAuthoritativeAPIStatus(machinev1beta1.MachineAuthorityMigrating).WithAnnotations(lossy).Build(),
+			*machinev1resourcebuilder.MachineSet().WithName("lossless").
+				WithAuthoritativeAPIStatus(machinev1beta1.MachineAuthorityMachineAPI).Build(),
+		}
+		capiMachineSets := []capiv1beta1.MachineSet{
+			*capiv1resourcebuilder.MachineSet().WithName("mapi-authoritative").
+				WithAnnotations(map[string]string{consts.ConversionLossAnnotation: `["spec.bar","spec.baz"]`}).Build(),
+			*capiv1resourcebuilder.MachineSet().WithName("capi-authoritative").WithAnnotations(lossy).Build(),
+			*capiv1resourcebuilder.MachineSet().WithName("migrating").WithAnnotations(lossy).Build(),
+			*capiv1resourcebuilder.MachineSet().WithName("lossless").Build(),
+		}
+
+		Expect(machineSetReports(context.Background(), mapiMachineSets, capiMachineSets)).To(Equal([]MachineSetReport{
+			{Name: "capi-authoritative", AuthoritativeAPI: machinev1beta1.MachineAuthorityClusterAPI, LossyFields: []string{"spec.foo"}},
+			{Name: "mapi-authoritative", AuthoritativeAPI: machinev1beta1.MachineAuthorityMachineAPI, LossyFields: []string{"spec.bar", "spec.baz"}},
+		}))
+	})
+
+	It("should ignore invalid annotations", func() {
+		mapiMachineSets := []machinev1beta1.MachineSet{
+			*machinev1resourcebuilder.MachineSet().WithName("foo").
+				WithAuthoritativeAPIStatus(machinev1beta1.MachineAuthorityClusterAPI).
+				WithAnnotations(map[string]string{consts.ConversionLossAnnotation: "spec.foo"}).Build(),
+		}
+
+		Expect(machineSetReports(context.Background(), mapiMachineSets, nil)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Conversion report controller", func() {
+	const machineSetName = "foo"
+
+	var k komega.Komega
+	var reconciler *ConversionReportReconciler
+
+	var mapiNamespace, capiNamespace *corev1.Namespace
+	var mapiMachineSet *machinev1beta1.MachineSet
+	var report *corev1.ConfigMap
+
+	BeforeEach(func() {
+		k = komega.New(cl)
+
+		By("Setting up the namespaces for the test")
+		mapiNamespace = corev1resourcebuilder.Namespace().WithGenerateName("openshift-machine-api-").Build()
+		Expect(cl.Create(ctx, mapiNamespace)).To(Succeed())
+
+		capiNamespace = corev1resourcebuilder.Namespace().WithGenerateName("openshift-cluster-api-").Build()
+		Expect(cl.Create(ctx, capiNamespace)).To(Succeed())
+
+		reconciler = &ConversionReportReconciler{
+			Client:        cl,
+			MAPINamespace: mapiNamespace.GetName(),
+			CAPINamespace: capiNamespace.GetName(),
+		}
+
+		By("Creating a MAPI machine set synchronized from its lossy CAPI counterpart")
+		mapiMachineSet = machinev1resourcebuilder.MachineSet().
+			WithNamespace(mapiNamespace.GetName()).
+			WithName(machineSetName).
+			WithAnnotations(map[string]string{consts.ConversionLossAnnotation: `["spec.template.spec.foo"]`}).
+			Build()
+		Expect(cl.Create(ctx, mapiMachineSet)).To(Succeed())
+		Eventually(k.UpdateStatus(mapiMachineSet, func() {
+			mapiMachineSet.Status.AuthoritativeAPI = machinev1beta1.MachineAuthorityClusterAPI
+		})).Should(Succeed())
+
+		report = &corev1.ConfigMap{}
+		report.SetNamespace(capiNamespace.GetName())
+		report.SetName(ReportConfigMapName)
+	})
+
+	AfterEach(func() {
+		Expect(test.CleanupAndWait(ctx, cl, mapiMachineSet, report)).To(Succeed())
+	})
+
+	It("should write the report of the lossy machine sets", func() {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(report)})
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(k.Object(report)).Should(HaveField("Data", HaveKeyWithValue(MachineSetsReportKey,
+			`[{"name":"foo","authoritativeAPI":"ClusterAPI","lossyFields":["spec.template.spec.foo"]}]`)))
+	})
+})
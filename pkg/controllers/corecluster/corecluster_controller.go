@@ -19,8 +19,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net/url"
-	"strconv"
 
 	"github.com/go-logr/logr"
 	configv1 "github.com/openshift/api/config/v1"
@@ -37,6 +35,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
 	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
 	"github.com/openshift/cluster-capi-operator/pkg/util"
 )
@@ -46,6 +45,13 @@ const (
 	capiInfraClusterAPIVersionV1Beta1 = "infrastructure.cluster.x-k8s.io/v1beta1"
 	capiInfraClusterAPIVersionV1Beta2 = "infrastructure.cluster.x-k8s.io/v1beta2"
 	clusterOperatorName               = "cluster-api"
+
+	// UnsupportedFieldsStrippedCondition reports that fields the core cluster does not support, such as its
+	// ClusterClass topology, were set by a user and have been removed by the controller.
+	UnsupportedFieldsStrippedCondition clusterv1.ConditionType = "UnsupportedFieldsStripped"
+
+	// TopologyStrippedReason is the reason of the UnsupportedFieldsStrippedCondition when the topology was removed.
+	TopologyStrippedReason = "TopologyStripped"
 )
 
 var (
@@ -91,6 +97,11 @@ func (r *CoreClusterController) Reconcile(ctx context.Context, req reconcile.Req
 		return ctrl.Result{}, fmt.Errorf("failed to obtain infrastructure name: %w", err)
 	}
 
+	external := util.IsExternalControlPlaneTopology(r.Infra)
+	if err := r.SetControlPlaneTopologyMode(ctx, controllerName, external, controlPlaneTopologyMessage(external)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to set control plane topology mode: %w", err)
+	}
+
 	cluster, err := r.ensureCoreCluster(ctx, client.ObjectKey{Namespace: r.ManagedNamespace, Name: ocpInfrastructureName}, logger)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to ensure core cluster: %w", err)
@@ -104,6 +115,14 @@ func (r *CoreClusterController) Reconcile(ctx context.Context, req reconcile.Req
 		return ctrl.Result{}, nil
 	}
 
+	if err := r.ensureManagedByLabel(ctx, cluster); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to ensure core cluster is labeled as managed by the operator: %w", err)
+	}
+
+	if err := r.ensureUnsupportedFieldsStripped(ctx, cluster); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to strip unsupported fields from core cluster: %w", err)
+	}
+
 	if err := r.ensureCoreClusterControlPlaneInitializedCondition(ctx, cluster); err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to ensure core cluster has the ControlPlaneInitializedCondition: %w", err)
 	}
@@ -158,21 +177,23 @@ func (r *CoreClusterController) ensureCoreCluster(ctx context.Context, clusterOb
 }
 
 // generateCoreClusterObject generates a new core cluster object to be created.
-func (r *CoreClusterController) generateCoreClusterObject(_ context.Context, clusterObjectKey client.ObjectKey, infraClusterAPIVersion, infraClusterKind string) (*clusterv1.Cluster, error) {
-	apiURL, err := url.Parse(r.Infra.Status.APIServerInternalURL)
+// The control plane endpoint is overridden by the util.APIServerEndpointOverrideAnnotation of the ClusterOperator, when set.
+func (r *CoreClusterController) generateCoreClusterObject(ctx context.Context, clusterObjectKey client.ObjectKey, infraClusterAPIVersion, infraClusterKind string) (*clusterv1.Cluster, error) {
+	co, err := r.GetOrCreateClusterOperator(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse apiURL: %w", err)
+		return nil, fmt.Errorf("failed to get cluster operator: %w", err)
 	}
 
-	port, err := strconv.ParseInt(apiURL.Port(), 10, 32)
+	endpoint, err := util.GetAPIServerEndpoint(co.GetAnnotations(), r.Infra)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse apiURL port: %w", err)
+		return nil, fmt.Errorf("failed to get API server endpoint: %w", err)
 	}
 
 	return &clusterv1.Cluster{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      clusterObjectKey.Name,
 			Namespace: clusterObjectKey.Namespace,
+			Labels:    map[string]string{consts.ManagedByLabel: consts.ManagedByLabelValue},
 		},
 		Spec: clusterv1.ClusterSpec{
 			InfrastructureRef: &corev1.ObjectReference{
@@ -182,13 +203,66 @@ func (r *CoreClusterController) generateCoreClusterObject(_ context.Context, clu
 				Namespace:  clusterObjectKey.Namespace,
 			},
 			ControlPlaneEndpoint: clusterv1.APIEndpoint{
-				Host: apiURL.Hostname(),
-				Port: int32(port),
+				Host: endpoint.Host,
+				Port: endpoint.Port,
 			},
 		},
 	}, nil
 }
 
+// ensureManagedByLabel labels the core cluster as managed by the operator, when it was created before it was labeled,
+// so that it is protected from manual changes.
+func (r *CoreClusterController) ensureManagedByLabel(ctx context.Context, cluster *clusterv1.Cluster) error {
+	if util.HasManagedByLabel(cluster) {
+		return nil
+	}
+
+	patchBase := cluster.DeepCopy()
+	util.SetManagedByLabel(cluster)
+
+	if err := r.Patch(ctx, cluster, client.MergeFrom(patchBase)); err != nil {
+		return fmt.Errorf("unable to label core cluster: %w", err)
+	}
+
+	return nil
+}
+
+// ensureUnsupportedFieldsStripped removes the ClusterClass topology of the core cluster, which describes the cluster
+// the operator runs on and is not created from a ClusterClass, so the behaviour of the topology would be undefined.
+// The removal is reported by the UnsupportedFieldsStrippedCondition of the core cluster, and a warning event.
+func (r *CoreClusterController) ensureUnsupportedFieldsStripped(ctx context.Context, cluster *clusterv1.Cluster) error {
+	if cluster.Spec.Topology == nil {
+		return nil
+	}
+
+	message := fmt.Sprintf("spec.topology, using the ClusterClass %q, is not supported on the core cluster and was removed", cluster.Spec.Topology.Class)
+
+	patchBase := cluster.DeepCopy()
+	cluster.Spec.Topology = nil
+
+	if err := r.Patch(ctx, cluster, client.MergeFrom(patchBase)); err != nil {
+		return fmt.Errorf("unable to remove the topology of core cluster: %w", err)
+	}
+
+	statusPatchBase := cluster.DeepCopy()
+	conditions.Set(cluster, &clusterv1.Condition{
+		Type:    UnsupportedFieldsStrippedCondition,
+		Status:  corev1.ConditionTrue,
+		Reason:  TopologyStrippedReason,
+		Message: message,
+	})
+
+	if err := r.Status().Patch(ctx, cluster, client.MergeFrom(statusPatchBase)); err != nil {
+		return fmt.Errorf("unable to update core cluster status: %w", err)
+	}
+
+	if r.Recorder != nil {
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, TopologyStrippedReason, message)
+	}
+
+	return nil
+}
+
 // ensureCoreClusterControlPlaneInitializedCondition makes sure the ControlPlaneInitializedCondition condition on the cluster.
 func (r *CoreClusterController) ensureCoreClusterControlPlaneInitializedCondition(ctx context.Context, cluster *clusterv1.Cluster) error {
 	if conditions.Get(cluster, clusterv1.ControlPlaneInitializedCondition) != nil {
@@ -215,6 +289,15 @@ func (r *CoreClusterController) ensureCoreClusterControlPlaneInitializedConditio
 	return nil
 }
 
+// controlPlaneTopologyMessage describes the control plane endpoint of the core cluster for the control plane topology.
+func controlPlaneTopologyMessage(external bool) string {
+	if external {
+		return "The control plane is external, the control plane endpoint of the core cluster is the API server URL of the Infrastructure"
+	}
+
+	return "The control plane endpoint of the core cluster is the internal API server URL of the Infrastructure"
+}
+
 // mapOCPPlatformToInfraClusterKindAndVersion maps an OCP Infrastructure PlatformType to a CAPI InfraCluster Kind and APIVersion.
 func mapOCPPlatformToInfraClusterKindAndVersion(platform configv1.PlatformType) (string, string, error) {
 	switch platform {
@@ -35,6 +35,7 @@ import (
 	capabuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/infrastructure/v1beta2"
 	configv1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/config/v1"
 	corev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/core/v1"
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
 	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
 )
 
@@ -162,7 +163,8 @@ var _ = Describe("Reconcile Core cluster", func() {
 				It("should create the core cluster", func() {
 					testCoreCluster := capibuilder.Cluster().WithName(testInfraName).WithNamespace(testNamespaceName).Build()
 					Eventually(komega.Get(testCoreCluster)).Should(Succeed(), "should have been able to successfully get the core cluster")
-					Eventually(komega.Object(testCoreCluster)).Should(
+					Eventually(komega.Object(testCoreCluster)).Should(SatisfyAll(
+						HaveField("Labels", HaveKeyWithValue(consts.ManagedByLabel, consts.ManagedByLabelValue)),
 						HaveField("Status.Conditions", SatisfyAll(
 							Not(BeEmpty()),
 							ContainElement(SatisfyAll(
@@ -170,7 +172,7 @@ var _ = Describe("Reconcile Core cluster", func() {
 								HaveField("Status", Equal(corev1.ConditionTrue)),
 							)),
 						)),
-					)
+					))
 				})
 
 				Context("With a ClusterOperator", func() {
@@ -186,6 +188,17 @@ var _ = Describe("Reconcile Core cluster", func() {
 						)
 					})
 
+					It("should report the self hosted control plane topology mode", func() {
+						co := komega.Object(configv1resourcebuilder.ClusterOperator().WithName(clusterOperatorName).Build())
+						Eventually(co).Should(
+							HaveField("Status.Conditions", ContainElement(SatisfyAll(
+								HaveField("Type", Equal(operatorstatus.ControlPlaneTopologyConditionType(controllerName))),
+								HaveField("Status", Equal(configv1.ConditionFalse)),
+								HaveField("Reason", Equal(operatorstatus.ReasonSelfHostedControlPlane)),
+							))),
+						)
+					})
+
 					It("should update the ClusterOperator status version to the desired one", func() {
 						co := komega.Object(configv1resourcebuilder.ClusterOperator().WithName(clusterOperatorName).Build())
 						Eventually(co).Should(
@@ -199,6 +212,35 @@ var _ = Describe("Reconcile Core cluster", func() {
 			})
 		})
 
+		Context("When the control plane is external", func() {
+			BeforeEach(func() {
+				infra.Status.APIServerURL = "https://api.hosted.example.com:443"
+				infra.Status.ControlPlaneTopology = configv1.ExternalTopologyMode
+
+				By("Creating a testing infra cluster")
+				infraCluster := capabuilder.AWSCluster().WithName(testInfraName).WithNamespace(testNamespaceName).Build()
+				Eventually(cl.Create(ctx, infraCluster)).Should(Succeed())
+			})
+
+			It("should create the core cluster with the API server URL as control plane endpoint", func() {
+				testCoreCluster := capibuilder.Cluster().WithName(testInfraName).WithNamespace(testNamespaceName).Build()
+				Eventually(komega.Object(testCoreCluster)).Should(
+					HaveField("Spec.ControlPlaneEndpoint", Equal(capiv1.APIEndpoint{Host: "api.hosted.example.com", Port: 443})),
+				)
+			})
+
+			It("should report the external control plane topology mode", func() {
+				co := komega.Object(configv1resourcebuilder.ClusterOperator().WithName(clusterOperatorName).Build())
+				Eventually(co).Should(
+					HaveField("Status.Conditions", ContainElement(SatisfyAll(
+						HaveField("Type", Equal(operatorstatus.ControlPlaneTopologyConditionType(controllerName))),
+						HaveField("Status", Equal(configv1.ConditionTrue)),
+						HaveField("Reason", Equal(operatorstatus.ReasonExternalControlPlane)),
+					))),
+				)
+			})
+		})
+
 		Context("When there is an existing core cluster", func() {
 			BeforeEach(func() {
 				By("Creating a testing core cluster object")
@@ -234,6 +276,32 @@ var _ = Describe("Reconcile Core cluster", func() {
 					)
 				})
 			})
+
+			Context("When the core cluster has a topology", func() {
+				BeforeEach(func() {
+					By("Setting a ClusterClass topology on the core cluster")
+					Eventually(komega.Update(coreCluster, func() {
+						coreCluster.Spec.Topology = &capiv1.Topology{Class: "quick-start", Version: "v1.30.0"}
+					})).Should(Succeed())
+
+					By("Creating a testing infra cluster")
+					infraCluster := capabuilder.AWSCluster().WithName(testInfraName).WithNamespace(testNamespaceName).Build()
+					Eventually(cl.Create(ctx, infraCluster)).Should(Succeed())
+				})
+
+				It("should strip the topology and report it", func() {
+					testCoreCluster := capibuilder.Cluster().WithName(testInfraName).WithNamespace(testNamespaceName).Build()
+					Eventually(komega.Object(testCoreCluster)).Should(SatisfyAll(
+						HaveField("Spec.Topology", BeNil()),
+						HaveField("Status.Conditions", ContainElement(SatisfyAll(
+							HaveField("Type", Equal(UnsupportedFieldsStrippedCondition)),
+							HaveField("Status", Equal(corev1.ConditionTrue)),
+							HaveField("Reason", Equal(TopologyStrippedReason)),
+							HaveField("Message", ContainSubstring(`ClusterClass "quick-start"`)),
+						))),
+					))
+				})
+			})
 		})
 	})
 
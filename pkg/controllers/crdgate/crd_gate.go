@@ -0,0 +1,263 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package crdgate
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	configv1 "github.com/openshift/api/config/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+	"github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
+)
+
+const (
+	// defaultInterval is the default interval between two checks of the CRDs.
+	defaultInterval = 30 * time.Second
+)
+
+// resourceDiscoverer is the subset of the discovery client used to check the CRDs are served.
+type resourceDiscoverer interface {
+	ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error)
+}
+
+// CRDGate runs a controller only while the CRDs of the resources it watches are served by the API server.
+// A controller watching a resource whose CRD was removed can no longer sync its cache, and would take the whole
+// manager down with it. The gate stops the controller instead, and reports it as degraded on the ClusterOperator,
+// until the CRDs are recreated, e.g. by the CAPI installer, and the controller is set up again.
+type CRDGate struct {
+	operatorstatus.ClusterOperatorStatusClient
+
+	// Name is the name of the gated controller. The gate reports it through the <Name>Degraded condition.
+	Name string
+	// Objects are the CRD backed resources watched by the gated controller.
+	Objects []client.Object
+	// Setup sets the gated controller up with the manager, it is called each time the CRDs are established.
+	Setup func(mgr ctrl.Manager) error
+	// Interval is the interval between two checks of the CRDs, it defaults to 30 seconds.
+	Interval time.Duration
+
+	mgr       ctrl.Manager
+	discovery resourceDiscoverer
+}
+
+// SetupWithManager adds the gate to the manager. The gated controller must not be set up with the manager directly.
+func (g *CRDGate) SetupWithManager(mgr ctrl.Manager) error {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		return fmt.Errorf("failed to create discovery client: %w", err)
+	}
+
+	g.mgr = mgr
+	g.discovery = discoveryClient
+
+	if g.Interval == 0 {
+		g.Interval = defaultInterval
+	}
+
+	if err := mgr.Add(g); err != nil {
+		return fmt.Errorf("failed to add %s CRD gate to manager: %w", g.Name, err)
+	}
+
+	return nil
+}
+
+// Start runs the gated controller while its CRDs are served, until the context is cancelled.
+func (g *CRDGate) Start(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx).WithName(g.Name).WithValues("gate", "CRD")
+	ctx = ctrl.LoggerInto(ctx, log)
+
+	var stop context.CancelFunc
+
+	var done <-chan error
+
+	stopController := func() {
+		if stop == nil {
+			return
+		}
+
+		stop()
+		<-done
+
+		stop, done = nil, nil
+
+		g.removeInformers(ctx, log)
+	}
+	defer stopController()
+
+	ticker := time.NewTicker(g.Interval)
+	defer ticker.Stop()
+
+	for {
+		missing, err := g.missingResources()
+
+		switch {
+		case err != nil:
+			log.Error(err, "unable to check CRDs are served")
+		case len(missing) > 0:
+			if stop != nil {
+				log.Info("CRDs were removed, stopping controller", "missing", missing)
+			}
+
+			stopController()
+
+			if err := g.setDegraded(ctx, fmt.Sprintf("%s is stopped until the CRDs of %s are recreated", g.Name, strings.Join(missing, ", "))); err != nil {
+				log.Error(err, "unable to set degraded condition")
+			}
+		case stop == nil:
+			log.Info("CRDs are served, starting controller")
+
+			stop, done, err = util.StartGatedControllers(ctx, g.mgr, g.Setup)
+			if err != nil {
+				log.Error(err, "unable to start controller")
+
+				if err := g.setDegraded(ctx, fmt.Sprintf("%s failed to start: %v", g.Name, err)); err != nil {
+					log.Error(err, "unable to set degraded condition")
+				}
+			} else if err := g.clearDegraded(ctx); err != nil {
+				log.Error(err, "unable to clear degraded condition")
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-done:
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			// The controller fails to start when a CRD is removed before its caches are synced.
+			// It is started again, if its CRDs are still served, on the next check.
+			log.Error(err, "controller stopped")
+
+			stop, done = nil, nil
+
+			g.removeInformers(ctx, log)
+		case <-ticker.C:
+		}
+	}
+}
+
+// missingResources returns the group kinds of the watched resources that are not served by the API server.
+func (g *CRDGate) missingResources() ([]string, error) {
+	servedKinds := map[string][]string{}
+	missing := []string{}
+
+	for _, obj := range g.Objects {
+		gvk, err := apiutil.GVKForObject(obj, g.Scheme())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get group version kind: %w", err)
+		}
+
+		gv := gvk.GroupVersion().String()
+
+		kinds, ok := servedKinds[gv]
+		if !ok {
+			resources, err := g.discovery.ServerResourcesForGroupVersion(gv)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("failed to discover resources of %s: %w", gv, err)
+			}
+
+			kinds = []string{}
+
+			if resources != nil {
+				for _, resource := range resources.APIResources {
+					// Subresources are listed with the kind of their parent resource.
+					if !strings.Contains(resource.Name, "/") {
+						kinds = append(kinds, resource.Kind)
+					}
+				}
+			}
+
+			servedKinds[gv] = kinds
+		}
+
+		if !slices.Contains(kinds, gvk.Kind) {
+			missing = append(missing, gvk.GroupKind().String())
+		}
+	}
+
+	return missing, nil
+}
+
+// removeInformers stops the informers of the watched resources, so that they do not retry to list resources whose
+// CRDs are gone. They are created again by the controller once the CRDs are recreated.
+func (g *CRDGate) removeInformers(ctx context.Context, log logr.Logger) {
+	for _, obj := range g.Objects {
+		if err := g.mgr.GetCache().RemoveInformer(ctx, obj); err != nil {
+			log.Error(err, "unable to remove informer", "type", fmt.Sprintf("%T", obj))
+		}
+	}
+}
+
+func (g *CRDGate) setDegraded(ctx context.Context, message string) error {
+	co, err := g.GetOrCreateClusterOperator(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get cluster operator: %w", err)
+	}
+
+	cond := operatorstatus.NewClusterOperatorStatusCondition(g.degradedCondition(), configv1.ConditionTrue, operatorstatus.ReasonCRDsMissing, message)
+
+	current := v1helpers.FindStatusCondition(co.Status.Conditions, cond.Type)
+	if current != nil && current.Status == cond.Status && current.Reason == cond.Reason && current.Message == cond.Message {
+		return nil
+	}
+
+	if err := g.SyncStatus(ctx, co, []configv1.ClusterOperatorStatusCondition{cond}); err != nil {
+		return fmt.Errorf("failed to sync status: %w", err)
+	}
+
+	return nil
+}
+
+// clearDegraded reverts the condition set by setDegraded, conditions set by the controller itself are left untouched.
+func (g *CRDGate) clearDegraded(ctx context.Context) error {
+	co, err := g.GetOrCreateClusterOperator(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get cluster operator: %w", err)
+	}
+
+	current := v1helpers.FindStatusCondition(co.Status.Conditions, g.degradedCondition())
+	if current == nil || current.Reason != operatorstatus.ReasonCRDsMissing {
+		return nil
+	}
+
+	if err := g.SyncStatus(ctx, co, []configv1.ClusterOperatorStatusCondition{
+		operatorstatus.NewClusterOperatorStatusCondition(g.degradedCondition(), configv1.ConditionFalse, operatorstatus.ReasonAsExpected,
+			fmt.Sprintf("%s CRDs are served", g.Name)),
+	}); err != nil {
+		return fmt.Errorf("failed to sync status: %w", err)
+	}
+
+	return nil
+}
+
+func (g *CRDGate) degradedCondition() configv1.ClusterStatusConditionType {
+	return configv1.ClusterStatusConditionType(g.Name + "Degraded")
+}
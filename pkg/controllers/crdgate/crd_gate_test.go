@@ -0,0 +1,233 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package crdgate
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/config"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
+)
+
+// fakeDiscoverer serves the kinds of the cluster.x-k8s.io/v1beta1 group version it holds.
+type fakeDiscoverer struct {
+	sync.Mutex
+
+	kinds []string
+}
+
+func (d *fakeDiscoverer) setKinds(kinds ...string) {
+	d.Lock()
+	defer d.Unlock()
+
+	d.kinds = kinds
+}
+
+func (d *fakeDiscoverer) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	d.Lock()
+	defer d.Unlock()
+
+	if groupVersion != clusterv1.GroupVersion.String() || len(d.kinds) == 0 {
+		return nil, apierrors.NewNotFound(schema.GroupResource{}, groupVersion)
+	}
+
+	resources := &metav1.APIResourceList{GroupVersion: groupVersion}
+	for _, kind := range d.kinds {
+		resources.APIResources = append(resources.APIResources,
+			metav1.APIResource{Name: kind, Kind: kind}, metav1.APIResource{Name: kind + "/status", Kind: kind})
+	}
+
+	return resources, nil
+}
+
+// fakeManager provides the manager methods used by the gate.
+type fakeManager struct {
+	manager.Manager
+
+	cache *fakeCache
+}
+
+func (m *fakeManager) GetCache() cache.Cache {
+	return m.cache
+}
+
+func (m *fakeManager) GetControllerOptions() config.Controller {
+	return config.Controller{}
+}
+
+// fakeCache records the removed informers.
+type fakeCache struct {
+	cache.Cache
+
+	removedInformers chan client.Object
+}
+
+func (m *fakeCache) RemoveInformer(_ context.Context, obj client.Object) error {
+	m.removedInformers <- obj
+
+	return nil
+}
+
+var _ = Describe("CRD gate", func() {
+	var gate *CRDGate
+	var discoverer *fakeDiscoverer
+	var mgr *fakeManager
+	var cl client.Client
+
+	var started chan struct{}
+	var stopped chan struct{}
+
+	ctx := context.Background()
+
+	degradedCondition := func() *configv1.ClusterOperatorStatusCondition {
+		co := &configv1.ClusterOperator{}
+		Expect(cl.Get(ctx, client.ObjectKey{Name: controllers.ClusterOperatorName}, co)).To(Succeed())
+
+		return v1helpers.FindStatusCondition(co.Status.Conditions, "TestControllerDegraded")
+	}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(configv1.AddToScheme(scheme)).To(Succeed())
+		Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+		cl = fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&configv1.ClusterOperator{}).Build()
+		discoverer = &fakeDiscoverer{}
+		mgr = &fakeManager{cache: &fakeCache{removedInformers: make(chan client.Object, 10)}}
+
+		started = make(chan struct{}, 10)
+		stopped = make(chan struct{}, 10)
+
+		gate = &CRDGate{
+			ClusterOperatorStatusClient: operatorstatus.ClusterOperatorStatusClient{
+				Client:   cl,
+				Recorder: record.NewFakeRecorder(32),
+			},
+			Name:    "TestController",
+			Objects: []client.Object{&clusterv1.Cluster{}, &clusterv1.MachineSet{}},
+			Setup: func(mgr ctrl.Manager) error {
+				return mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+					started <- struct{}{}
+					<-ctx.Done()
+					stopped <- struct{}{}
+
+					return nil
+				}))
+			},
+			Interval:  10 * time.Millisecond,
+			mgr:       mgr,
+			discovery: discoverer,
+		}
+	})
+
+	It("should report the missing resources", func() {
+		discoverer.setKinds("Cluster")
+
+		Expect(gate.missingResources()).To(ConsistOf("MachineSet.cluster.x-k8s.io"))
+	})
+
+	It("should report all the resources of a missing group version", func() {
+		Expect(gate.missingResources()).To(ConsistOf("Cluster.cluster.x-k8s.io", "MachineSet.cluster.x-k8s.io"))
+	})
+
+	It("should stop the controller while its CRDs are missing", func() {
+		discoverer.setKinds("Cluster", "MachineSet")
+
+		gateCtx, cancel := context.WithCancel(ctx)
+		gateDone := make(chan error)
+
+		go func() {
+			gateDone <- gate.Start(gateCtx)
+		}()
+
+		By("Starting the controller when its CRDs are served")
+		Eventually(started).Should(Receive())
+
+		By("Stopping the controller when a CRD is removed")
+		discoverer.setKinds("Cluster")
+
+		Eventually(stopped).Should(Receive())
+		Eventually(mgr.cache.removedInformers).Should(Receive(BeAssignableToTypeOf(&clusterv1.MachineSet{})))
+		Eventually(degradedCondition).Should(SatisfyAll(
+			HaveField("Status", Equal(configv1.ConditionTrue)),
+			HaveField("Reason", Equal(operatorstatus.ReasonCRDsMissing)),
+			HaveField("Message", Equal("TestController is stopped until the CRDs of MachineSet.cluster.x-k8s.io are recreated")),
+		))
+		Consistently(started, 100*time.Millisecond).ShouldNot(Receive())
+
+		By("Starting the controller again when the CRD is recreated")
+		discoverer.setKinds("Cluster", "MachineSet")
+
+		Eventually(started).Should(Receive())
+		Eventually(degradedCondition).Should(HaveField("Status", Equal(configv1.ConditionFalse)))
+
+		cancel()
+		Eventually(gateDone).Should(Receive(BeNil()))
+		Eventually(stopped).Should(Receive())
+	})
+
+	It("should start the controller again when it stops unexpectedly", func() {
+		discoverer.setKinds("Cluster", "MachineSet")
+
+		setups := 0
+		gate.Setup = func(mgr ctrl.Manager) error {
+			setups++
+			firstSetup := setups == 1
+
+			return mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+				started <- struct{}{}
+
+				if firstSetup {
+					return context.DeadlineExceeded
+				}
+
+				<-ctx.Done()
+
+				return nil
+			}))
+		}
+
+		gateCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		go func() {
+			defer GinkgoRecover()
+			Expect(gate.Start(gateCtx)).To(Succeed())
+		}()
+
+		Eventually(started).Should(Receive())
+		Eventually(started).Should(Receive())
+	})
+})
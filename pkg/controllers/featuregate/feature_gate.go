@@ -0,0 +1,190 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package featuregate
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+)
+
+const (
+	// ReasonFeatureGateEnabled is the reason of the event recorded when the gated controllers are started after the
+	// feature gate was enabled.
+	ReasonFeatureGateEnabled = "FeatureGateEnabled"
+
+	// ReasonFeatureGateDisabled is the reason of the event recorded when the gated controllers are stopped after the
+	// feature gate was disabled.
+	ReasonFeatureGateDisabled = "FeatureGateDisabled"
+
+	// clusterFeatureGateName is the name of the cluster wide FeatureGate the events are recorded on.
+	clusterFeatureGateName = "cluster"
+)
+
+// Gate runs controllers only while a feature gate is enabled, so that they are started and stopped when the feature
+// gate is toggled without restarting the manager they share with the other controllers.
+// The feature gate changes are observed through FeatureGatesChanged, which must be set as the change handler of the
+// FeatureGateAccess instead of its default handler exiting the process.
+type Gate struct {
+	// Name is the name of the gated controllers, used in the logs and errors.
+	Name string
+	// Feature is the feature gate the controllers are gated on.
+	Feature configv1.FeatureGateName
+	// FeatureGates returns the current feature gates, usually FeatureGateAccess.CurrentFeatureGates.
+	FeatureGates func() (featuregates.FeatureGate, error)
+	// Recorder records the feature gate transitions on the cluster FeatureGate.
+	Recorder record.EventRecorder
+	// Setup sets the gated controllers up with the manager, it is called each time the feature gate is enabled.
+	Setup func(mgr ctrl.Manager) error
+	// Disabled, when set, is called when the feature gate is disabled, once the gated controllers are stopped.
+	// It is also called when the gate starts with the feature gate disabled, to clean up after a previous run.
+	Disabled func(ctx context.Context) error
+
+	mgr ctrl.Manager
+
+	changedOnce sync.Once
+	changed     chan struct{}
+}
+
+// SetupWithManager adds the gate to the manager. The gated controllers must not be set up with the manager directly.
+func (g *Gate) SetupWithManager(mgr ctrl.Manager) error {
+	g.mgr = mgr
+
+	if err := mgr.Add(g); err != nil {
+		return fmt.Errorf("failed to add %s feature gate to manager: %w", g.Name, err)
+	}
+
+	return nil
+}
+
+// FeatureGatesChanged notifies the gate that the feature gates changed, it never blocks.
+func (g *Gate) FeatureGatesChanged(featuregates.FeatureChange) {
+	select {
+	case g.changes() <- struct{}{}:
+	default:
+	}
+}
+
+// Start runs the gated controllers while the feature gate is enabled, until the context is cancelled.
+// It returns an error when the gated controllers fail, as the manager would if they were set up with it directly.
+func (g *Gate) Start(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx).WithName(g.Name).WithValues("gate", "FeatureGate", "featureGate", g.Feature)
+	ctx = ctrl.LoggerInto(ctx, log)
+
+	var stop context.CancelFunc
+
+	var done <-chan error
+
+	stopControllers := func() {
+		if stop == nil {
+			return
+		}
+
+		stop()
+		<-done
+
+		stop, done = nil, nil
+	}
+	defer stopControllers()
+
+	for initial := true; ; initial = false {
+		enabled, err := g.enabled()
+
+		switch {
+		case err != nil:
+			log.Error(err, "unable to get feature gates")
+		case enabled && stop == nil:
+			log.Info("Feature gate is enabled, starting controllers")
+
+			stop, done, err = util.StartGatedControllers(ctx, g.mgr, g.Setup)
+			if err != nil {
+				return fmt.Errorf("failed to start %s: %w", g.Name, err)
+			}
+
+			if !initial {
+				g.recordEvent(ReasonFeatureGateEnabled, fmt.Sprintf("Feature gate %s was enabled, its controllers were started", g.Feature))
+			}
+		case !enabled && (stop != nil || initial):
+			if stop != nil {
+				log.Info("Feature gate was disabled, stopping controllers")
+
+				stopControllers()
+
+				g.recordEvent(ReasonFeatureGateDisabled, fmt.Sprintf("Feature gate %s was disabled, its controllers were stopped", g.Feature))
+			} else {
+				log.Info("Feature gate is not enabled, waiting for it to be enabled")
+			}
+
+			if g.Disabled != nil {
+				if err := g.Disabled(ctx); err != nil {
+					return fmt.Errorf("failed to clean up %s: %w", g.Name, err)
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-done:
+			stop, done = nil, nil
+
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("%s stopped: %w", g.Name, err)
+		case <-g.changes():
+		}
+	}
+}
+
+// enabled returns whether the feature gate is currently enabled.
+func (g *Gate) enabled() (bool, error) {
+	featureGates, err := g.FeatureGates()
+	if err != nil {
+		return false, fmt.Errorf("failed to get current feature gates: %w", err)
+	}
+
+	return featureGates.Enabled(g.Feature), nil
+}
+
+// changes returns the channel notified of the feature gate changes. The handler may be called by the
+// FeatureGateAccess before the gate is set up, so the channel is created on first use.
+func (g *Gate) changes() chan struct{} {
+	g.changedOnce.Do(func() {
+		g.changed = make(chan struct{}, 1)
+	})
+
+	return g.changed
+}
+
+func (g *Gate) recordEvent(reason, message string) {
+	if g.Recorder == nil {
+		return
+	}
+
+	g.Recorder.Event(&configv1.FeatureGate{ObjectMeta: metav1.ObjectMeta{Name: clusterFeatureGateName}}, corev1.EventTypeNormal, reason, message)
+}
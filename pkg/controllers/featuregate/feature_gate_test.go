@@ -0,0 +1,189 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package featuregate
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/config"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const testFeature configv1.FeatureGateName = "TestFeature"
+
+// fakeFeatureGates holds the feature gates returned to the gate.
+type fakeFeatureGates struct {
+	sync.Mutex
+
+	enabled bool
+}
+
+func (f *fakeFeatureGates) set(enabled bool) {
+	f.Lock()
+	defer f.Unlock()
+
+	f.enabled = enabled
+}
+
+func (f *fakeFeatureGates) current() (featuregates.FeatureGate, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if f.enabled {
+		return featuregates.NewFeatureGate([]configv1.FeatureGateName{testFeature}, nil), nil
+	}
+
+	return featuregates.NewFeatureGate(nil, []configv1.FeatureGateName{testFeature}), nil
+}
+
+// fakeManager provides the manager methods used by the gate.
+type fakeManager struct {
+	manager.Manager
+}
+
+func (m *fakeManager) GetControllerOptions() config.Controller {
+	return config.Controller{}
+}
+
+var _ = Describe("Feature gate", func() {
+	var gate *Gate
+	var featureGates *fakeFeatureGates
+	var recorder *record.FakeRecorder
+
+	var started chan struct{}
+	var stopped chan struct{}
+	var disabled chan struct{}
+
+	ctx := context.Background()
+
+	toggle := func(enabled bool) {
+		featureGates.set(enabled)
+		gate.FeatureGatesChanged(featuregates.FeatureChange{})
+	}
+
+	BeforeEach(func() {
+		featureGates = &fakeFeatureGates{}
+		recorder = record.NewFakeRecorder(32)
+
+		started = make(chan struct{}, 10)
+		stopped = make(chan struct{}, 10)
+		disabled = make(chan struct{}, 10)
+
+		gate = &Gate{
+			Name:         "TestControllers",
+			Feature:      testFeature,
+			FeatureGates: featureGates.current,
+			Recorder:     recorder,
+			Setup: func(mgr ctrl.Manager) error {
+				return mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+					started <- struct{}{}
+					<-ctx.Done()
+					stopped <- struct{}{}
+
+					return nil
+				}))
+			},
+			Disabled: func(context.Context) error {
+				disabled <- struct{}{}
+
+				return nil
+			},
+			mgr: &fakeManager{},
+		}
+	})
+
+	It("should start and stop the controllers when the feature gate is toggled", func() {
+		gateCtx, cancel := context.WithCancel(ctx)
+		gateDone := make(chan error)
+
+		go func() {
+			gateDone <- gate.Start(gateCtx)
+		}()
+
+		By("Cleaning up when starting with the feature gate disabled")
+		Eventually(disabled).Should(Receive())
+		Consistently(started).ShouldNot(Receive())
+		Expect(recorder.Events).ToNot(Receive())
+
+		By("Starting the controllers when the feature gate is enabled")
+		toggle(true)
+
+		Eventually(started).Should(Receive())
+		Eventually(recorder.Events).Should(Receive(ContainSubstring(ReasonFeatureGateEnabled)))
+
+		By("Ignoring the changes of the other feature gates")
+		gate.FeatureGatesChanged(featuregates.FeatureChange{})
+
+		Consistently(started).ShouldNot(Receive())
+
+		By("Stopping the controllers when the feature gate is disabled")
+		toggle(false)
+
+		Eventually(stopped).Should(Receive())
+		Eventually(disabled).Should(Receive())
+		Eventually(recorder.Events).Should(Receive(ContainSubstring(ReasonFeatureGateDisabled)))
+
+		By("Starting the controllers again when the feature gate is enabled again")
+		toggle(true)
+
+		Eventually(started).Should(Receive())
+
+		cancel()
+		Eventually(gateDone).Should(Receive(BeNil()))
+		Eventually(stopped).Should(Receive())
+		Expect(disabled).ToNot(Receive())
+	})
+
+	It("should not record an event when starting with the feature gate enabled", func() {
+		featureGates.set(true)
+
+		gateCtx, cancel := context.WithCancel(ctx)
+		gateDone := make(chan error)
+
+		go func() {
+			gateDone <- gate.Start(gateCtx)
+		}()
+
+		Eventually(started).Should(Receive())
+		Consistently(recorder.Events).ShouldNot(Receive())
+		Expect(disabled).ToNot(Receive())
+
+		cancel()
+		Eventually(gateDone).Should(Receive(BeNil()))
+	})
+
+	It("should fail when the controllers stop unexpectedly", func() {
+		featureGates.set(true)
+
+		errFailed := errors.New("failed")
+		gate.Setup = func(mgr ctrl.Manager) error {
+			return mgr.Add(manager.RunnableFunc(func(context.Context) error {
+				return errFailed
+			}))
+		}
+
+		Expect(gate.Start(ctx)).To(MatchError(errFailed))
+	})
+})
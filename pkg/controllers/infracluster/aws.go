@@ -18,10 +18,9 @@ package infracluster
 import (
 	"context"
 	"fmt"
-	"net/url"
-	"strconv"
 
 	"github.com/go-logr/logr"
+	configv1 "github.com/openshift/api/config/v1"
 	cerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	awsv1 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
@@ -46,14 +45,9 @@ func (r *InfraClusterController) ensureAWSCluster(ctx context.Context, log logr.
 
 	log.Info(fmt.Sprintf("AWSCluster %s/%s does not exist, creating it", target.Namespace, target.Name))
 
-	apiURL, err := url.Parse(r.Infra.Status.APIServerInternalURL)
+	endpoint, err := r.getAPIServerEndpoint(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse apiURL: %w", err)
-	}
-
-	port, err := strconv.ParseInt(apiURL.Port(), 10, 32)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse apiURL port: %w", err)
+		return nil, fmt.Errorf("failed to get API server endpoint: %w", err)
 	}
 
 	if r.Infra.Status.PlatformStatus == nil {
@@ -71,10 +65,11 @@ func (r *InfraClusterController) ensureAWSCluster(ctx context.Context, log logr.
 			},
 		},
 		Spec: awsv1.AWSClusterSpec{
-			Region: r.Infra.Status.PlatformStatus.AWS.Region,
+			Region:         r.Infra.Status.PlatformStatus.AWS.Region,
+			AdditionalTags: awsResourceTags(r.Infra.Status.PlatformStatus.AWS),
 			ControlPlaneEndpoint: clusterv1.APIEndpoint{
-				Host: apiURL.Hostname(),
-				Port: int32(port),
+				Host: endpoint.Host,
+				Port: endpoint.Port,
 			},
 		},
 	}
@@ -87,3 +82,19 @@ func (r *InfraClusterController) ensureAWSCluster(ctx context.Context, log logr.
 
 	return target, nil
 }
+
+// awsResourceTags returns the user-defined tags of the Infrastructure, as the additional tags of the AWSCluster.
+// CAPA adds them to every AWS resource it manages, including the instances of the Machines it reconciles, and updates
+// them on the existing resources when they change.
+func awsResourceTags(platformStatus *configv1.AWSPlatformStatus) awsv1.Tags {
+	if platformStatus == nil || len(platformStatus.ResourceTags) == 0 {
+		return nil
+	}
+
+	tags := awsv1.Tags{}
+	for _, tag := range platformStatus.ResourceTags {
+		tags[tag.Key] = tag.Value
+	}
+
+	return tags
+}
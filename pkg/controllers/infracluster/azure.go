@@ -19,8 +19,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net/url"
-	"strconv"
 
 	"github.com/go-logr/logr"
 	mapiv1beta1 "github.com/openshift/api/machine/v1beta1"
@@ -32,6 +30,8 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/cluster-capi-operator/pkg/util"
 )
 
 var (
@@ -76,8 +76,8 @@ func (r *InfraClusterController) ensureAzureCluster(ctx context.Context, log log
 }
 
 // getAzureMAPIProviderSpec returns a Azure Machine ProviderSpec from the the cluster.
-func getAzureMAPIProviderSpec(ctx context.Context, cl client.Client) (*mapiv1beta1.AzureMachineProviderSpec, error) {
-	rawProviderSpec, err := getRawMAPIProviderSpec(ctx, cl)
+func getAzureMAPIProviderSpec(ctx context.Context, cl client.Client, mapiNamespace string) (*mapiv1beta1.AzureMachineProviderSpec, error) {
+	rawProviderSpec, err := getRawMAPIProviderSpec(ctx, cl, mapiNamespace)
 	if err != nil {
 		return nil, fmt.Errorf("unable to obtain MAPI ProviderSpec: %w", err)
 	}
@@ -93,7 +93,7 @@ func getAzureMAPIProviderSpec(ctx context.Context, cl client.Client) (*mapiv1bet
 // getAzureLocation obtains the Azure Location.
 func (r *InfraClusterController) getAzureLocation(ctx context.Context) (string, error) {
 	// Devise Azure location via MAPI providerSpec.
-	machineSpec, err := getAzureMAPIProviderSpec(ctx, r.Client)
+	machineSpec, err := getAzureMAPIProviderSpec(ctx, r.Client, r.MAPINamespace)
 	if err != nil {
 		return "", fmt.Errorf("error getting azure providerSpec: %w", err)
 	}
@@ -218,17 +218,12 @@ func (r *InfraClusterController) ensureAzureInfraCluster(ctx context.Context, ta
 
 	log.Info(fmt.Sprintf("AzureCluster %s/%s does not exist, creating it", target.Namespace, target.Name))
 
-	apiURL, err := url.Parse(r.Infra.Status.APIServerInternalURL)
-	if err != nil {
-		return fmt.Errorf("failed to parse apiUrl: %w", err)
-	}
-
-	port, err := strconv.ParseInt(apiURL.Port(), 10, 32)
+	endpoint, err := r.getAPIServerEndpoint(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to parse apiUrl port: %w", err)
+		return fmt.Errorf("failed to get API server endpoint: %w", err)
 	}
 
-	providerSpec, err := getAzureMAPIProviderSpec(ctx, r.Client)
+	providerSpec, err := getAzureMAPIProviderSpec(ctx, r.Client, r.MAPINamespace)
 	if err != nil {
 		return fmt.Errorf("error obtaining Azure Provider Spec: %w", err)
 	}
@@ -238,7 +233,7 @@ func (r *InfraClusterController) ensureAzureInfraCluster(ctx context.Context, ta
 		return fmt.Errorf("error obtaining Azure Cluster location: %w", err)
 	}
 
-	azureCluster := r.newAzureCluster(providerSpec, apiURL, port, location)
+	azureCluster := r.newAzureCluster(providerSpec, endpoint, location)
 	if err := r.Create(ctx, azureCluster); err != nil {
 		return fmt.Errorf("error creating New Azure Cluster: %w", err)
 	}
@@ -249,7 +244,7 @@ func (r *InfraClusterController) ensureAzureInfraCluster(ctx context.Context, ta
 }
 
 // createNewAzureCluster creates a new Azure Infra Cluster.
-func (r *InfraClusterController) newAzureCluster(providerSpec *mapiv1beta1.AzureMachineProviderSpec, apiURL *url.URL, port int64, location string) *azurev1.AzureCluster {
+func (r *InfraClusterController) newAzureCluster(providerSpec *mapiv1beta1.AzureMachineProviderSpec, endpoint util.APIServerEndpoint, location string) *azurev1.AzureCluster {
 	return &azurev1.AzureCluster{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      r.Infra.Status.InfrastructureName,
@@ -285,9 +280,8 @@ func (r *InfraClusterController) newAzureCluster(providerSpec *mapiv1beta1.Azure
 			},
 			ResourceGroup: providerSpec.ResourceGroup,
 			ControlPlaneEndpoint: clusterv1.APIEndpoint{
-				Host: apiURL.Hostname(),
-				// Port numbers are less than 65536, ignore gosec.
-				Port: int32(port), //nolint:gosec
+				Host: endpoint.Host,
+				Port: endpoint.Port,
 			},
 		},
 	}
@@ -0,0 +1,197 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package infracluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	awsv1 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
+	azurev1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	gcpv1 "sigs.k8s.io/cluster-api-provider-gcp/api/v1beta1"
+	ibmpowervsv1 "sigs.k8s.io/cluster-api-provider-ibmcloud/api/v1beta2"
+	vspherev1 "sigs.k8s.io/cluster-api-provider-vsphere/apis/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+)
+
+// ReasonManualInterventionRequired is the reason of the InfraClusterControllerDegraded condition when the InfraCluster
+// differs from the Infrastructure in fields that cannot be updated in place.
+const ReasonManualInterventionRequired = "ManualInterventionRequired"
+
+var errInfraClusterDrifted = errors.New("InfraCluster differs from the Infrastructure in fields that cannot be updated in place," +
+	" delete the InfraCluster for it to be recreated")
+
+// infraClusterDrift is a field of the InfraCluster that differs from the value generated from the Infrastructure.
+type infraClusterDrift struct {
+	field   string
+	current string
+	desired string
+	// inPlace is whether the field can be updated on the existing InfraCluster.
+	inPlace bool
+}
+
+func (d infraClusterDrift) String() string {
+	return fmt.Sprintf("%s is %q instead of %q", d.field, d.current, d.desired)
+}
+
+// driftDetector collects the drifted fields of an InfraCluster, setting the ones that can be updated in place to their
+// desired value.
+type driftDetector struct {
+	drifts []infraClusterDrift
+}
+
+// compare records the field as drifted when it differs from the desired value. Empty desired values are not compared,
+// as the Infrastructure does not report them.
+func (d *driftDetector) compare(field string, current *string, desired string, inPlace bool) {
+	if desired == "" || *current == desired {
+		return
+	}
+
+	d.drifts = append(d.drifts, infraClusterDrift{field: field, current: *current, desired: desired, inPlace: inPlace})
+
+	if inPlace {
+		*current = desired
+	}
+}
+
+// compareTags records the tags as drifted when they differ from the desired ones, and sets them to the desired ones.
+// The tags of the InfraCluster are only generated from the Infrastructure, so the tags it no longer has are removed.
+func (d *driftDetector) compareTags(field string, current *awsv1.Tags, desired awsv1.Tags) {
+	if maps.Equal(*current, desired) {
+		return
+	}
+
+	d.drifts = append(d.drifts, infraClusterDrift{
+		field:   field,
+		current: fmt.Sprint(map[string]string(*current)),
+		desired: fmt.Sprint(map[string]string(desired)),
+		inPlace: true,
+	})
+	*current = desired
+}
+
+// compareEndpoint compares the control plane endpoint of the InfraCluster, which follows the API server URL of the
+// Infrastructure, or its override.
+func (d *driftDetector) compareEndpoint(host *string, port *int32, desired util.APIServerEndpoint) {
+	d.compare("spec.controlPlaneEndpoint.host", host, desired.Host, true)
+
+	if desired.Port != 0 && *port != desired.Port {
+		d.drifts = append(d.drifts, infraClusterDrift{
+			field:   "spec.controlPlaneEndpoint.port",
+			current: strconv.Itoa(int(*port)),
+			desired: strconv.Itoa(int(desired.Port)),
+			inPlace: true,
+		})
+		*port = desired.Port
+	}
+}
+
+// detectDrift compares the fields of the InfraCluster generated from the Infrastructure with their current value,
+// and sets the ones that can be updated in place to their desired value.
+// The fields generated from the Machine API provider specs are not compared, as they do not follow the Infrastructure.
+func (r *InfraClusterController) detectDrift(ctx context.Context, infraCluster client.Object) ([]infraClusterDrift, error) {
+	endpoint, err := r.getAPIServerEndpoint(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API server endpoint: %w", err)
+	}
+
+	platformStatus := r.Infra.Status.PlatformStatus
+	d := &driftDetector{}
+
+	switch c := infraCluster.(type) {
+	case *awsv1.AWSCluster:
+		d.compareEndpoint(&c.Spec.ControlPlaneEndpoint.Host, &c.Spec.ControlPlaneEndpoint.Port, endpoint)
+
+		if platformStatus != nil && platformStatus.AWS != nil {
+			// The region of an AWSCluster is immutable.
+			d.compare("spec.region", &c.Spec.Region, platformStatus.AWS.Region, false)
+			d.compareTags("spec.additionalTags", &c.Spec.AdditionalTags, awsResourceTags(platformStatus.AWS))
+		}
+	case *gcpv1.GCPCluster:
+		d.compareEndpoint(&c.Spec.ControlPlaneEndpoint.Host, &c.Spec.ControlPlaneEndpoint.Port, endpoint)
+
+		if platformStatus != nil && platformStatus.GCP != nil {
+			// The region of a GCPCluster is immutable.
+			d.compare("spec.region", &c.Spec.Region, platformStatus.GCP.Region, false)
+		}
+	case *azurev1.AzureCluster:
+		d.compareEndpoint(&c.Spec.ControlPlaneEndpoint.Host, &c.Spec.ControlPlaneEndpoint.Port, endpoint)
+	case *ibmpowervsv1.IBMPowerVSCluster:
+		d.compareEndpoint(&c.Spec.ControlPlaneEndpoint.Host, &c.Spec.ControlPlaneEndpoint.Port, endpoint)
+	case *vspherev1.VSphereCluster:
+		d.compareEndpoint(&c.Spec.ControlPlaneEndpoint.Host, &c.Spec.ControlPlaneEndpoint.Port, endpoint)
+
+		// The credentials secret is generated for the server the VSphereCluster was created with.
+		if vsphere := r.Infra.Spec.PlatformSpec.VSphere; vsphere != nil && len(vsphere.VCenters) > 0 {
+			d.compare("spec.server", &c.Spec.Server, vsphere.VCenters[0].Server, false)
+		}
+	}
+
+	return d.drifts, nil
+}
+
+// reconcileDrift updates the InfraCluster in place when it differs from the Infrastructure, such as after a change of
+// the API server URL. It returns an errInfraClusterDrifted listing the fields that cannot be updated in place, either
+// because they are immutable or because the update was rejected by the provider.
+func (r *InfraClusterController) reconcileDrift(ctx context.Context, log logr.Logger, infraCluster client.Object) error {
+	patchBase, ok := infraCluster.DeepCopyObject().(client.Object)
+	if !ok {
+		return errCouldNotDeepCopyInfraObject
+	}
+
+	drifts, err := r.detectDrift(ctx, infraCluster)
+	if err != nil {
+		return err
+	}
+
+	var inPlace, manual []string
+
+	for _, drift := range drifts {
+		if drift.inPlace {
+			inPlace = append(inPlace, drift.String())
+		} else {
+			manual = append(manual, drift.String())
+		}
+	}
+
+	if len(inPlace) > 0 {
+		log.Info(fmt.Sprintf("InfraCluster '%s/%s' differs from the Infrastructure, updating it", infraCluster.GetNamespace(), infraCluster.GetName()),
+			"changes", inPlace)
+
+		if err := r.Patch(ctx, infraCluster, client.MergeFrom(patchBase)); kerrors.IsInvalid(err) || kerrors.IsForbidden(err) {
+			// The provider webhooks reject the updates of the fields they consider immutable.
+			log.Info("InfraCluster update rejected", "reason", err.Error())
+
+			manual = append(manual, inPlace...)
+		} else if err != nil {
+			return fmt.Errorf("unable to update InfraCluster: %w", err)
+		}
+	}
+
+	if len(manual) > 0 {
+		return fmt.Errorf("%w: %s", errInfraClusterDrifted, strings.Join(manual, ", "))
+	}
+
+	return nil
+}
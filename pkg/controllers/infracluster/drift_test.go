@@ -0,0 +1,168 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package infracluster
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configv1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/config/v1"
+	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	awsv1 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("InfraCluster drift", func() {
+	const (
+		infraName    = "test-infra-cluster-name"
+		endpointHost = "api-int.test-cluster.test-domain"
+	)
+
+	var scheme *runtime.Scheme
+	var awsCluster *awsv1.AWSCluster
+	var patches int
+
+	newReconciler := func(funcs interceptor.Funcs) *InfraClusterController {
+		co := configv1resourcebuilder.ClusterOperator().WithName(clusterOperatorName).Build()
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(co, awsCluster.DeepCopy()).WithInterceptorFuncs(funcs).Build()
+
+		return &InfraClusterController{
+			ClusterOperatorStatusClient: operatorstatus.ClusterOperatorStatusClient{Client: cl},
+			Infra:                       configv1resourcebuilder.Infrastructure().AsAWS(infraName, awsTestRegion).Build(),
+			Platform:                    configv1.AWSPlatformType,
+		}
+	}
+
+	countPatches := interceptor.Funcs{
+		Patch: func(ctx context.Context, cl client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			patches++
+			return cl.Patch(ctx, obj, patch, opts...)
+		},
+	}
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Expect(configv1.Install(scheme)).To(Succeed())
+		Expect(awsv1.AddToScheme(scheme)).To(Succeed())
+
+		patches = 0
+		awsCluster = &awsv1.AWSCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      infraName,
+				Namespace: defaultCAPINamespace,
+				Annotations: map[string]string{
+					clusterv1.ManagedByAnnotation: managedByAnnotationValueClusterCAPIOperatorInfraClusterController,
+				},
+			},
+			Spec: awsv1.AWSClusterSpec{
+				Region:               awsTestRegion,
+				ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: endpointHost, Port: 6443},
+			},
+		}
+	})
+
+	It("should not update an InfraCluster matching the Infrastructure", func() {
+		r := newReconciler(countPatches)
+
+		Expect(r.reconcileDrift(ctx, log.Log, awsCluster)).To(Succeed())
+		Expect(patches).To(BeZero())
+	})
+
+	It("should update the control plane endpoint in place", func() {
+		r := newReconciler(countPatches)
+		r.Infra.Status.APIServerInternalURL = "https://" + endpointHost + ":7443"
+
+		Expect(r.reconcileDrift(ctx, log.Log, awsCluster)).To(Succeed())
+		Expect(patches).To(Equal(1))
+
+		updated := &awsv1.AWSCluster{}
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(awsCluster), updated)).To(Succeed())
+		Expect(updated.Spec.ControlPlaneEndpoint).To(Equal(clusterv1.APIEndpoint{Host: endpointHost, Port: 7443}))
+	})
+
+	It("should update the additional tags in place when the resource tags of the Infrastructure change", func() {
+		awsCluster.Spec.AdditionalTags = awsv1.Tags{"team": "storage", "retired": "true"}
+		r := newReconciler(countPatches)
+		r.Infra.Status.PlatformStatus.AWS.ResourceTags = []configv1.AWSResourceTag{
+			{Key: "team", Value: "compute"},
+			{Key: "cost-center", Value: "1234"},
+		}
+
+		Expect(r.reconcileDrift(ctx, log.Log, awsCluster)).To(Succeed())
+		Expect(patches).To(Equal(1))
+
+		updated := &awsv1.AWSCluster{}
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(awsCluster), updated)).To(Succeed())
+		Expect(updated.Spec.AdditionalTags).To(Equal(awsv1.Tags{"team": "compute", "cost-center": "1234"}))
+	})
+
+	It("should not update the additional tags matching the resource tags of the Infrastructure", func() {
+		awsCluster.Spec.AdditionalTags = awsv1.Tags{"team": "compute"}
+		r := newReconciler(countPatches)
+		r.Infra.Status.PlatformStatus.AWS.ResourceTags = []configv1.AWSResourceTag{{Key: "team", Value: "compute"}}
+
+		Expect(r.reconcileDrift(ctx, log.Log, awsCluster)).To(Succeed())
+		Expect(patches).To(BeZero())
+	})
+
+	It("should require a manual intervention when the region changes", func() {
+		r := newReconciler(countPatches)
+		r.Infra.Status.PlatformStatus.AWS.Region = "eu-west-1"
+
+		err := r.reconcileDrift(ctx, log.Log, awsCluster)
+		Expect(err).To(MatchError(errInfraClusterDrifted))
+		Expect(err).To(MatchError(ContainSubstring(`spec.region is "us-east-1" instead of "eu-west-1"`)))
+		Expect(patches).To(BeZero())
+	})
+
+	It("should require a manual intervention when the provider rejects the update", func() {
+		r := newReconciler(interceptor.Funcs{
+			Patch: func(_ context.Context, _ client.WithWatch, obj client.Object, _ client.Patch, _ ...client.PatchOption) error {
+				return kerrors.NewInvalid(schema.GroupKind{Group: awsv1.GroupVersion.Group, Kind: "AWSCluster"}, obj.GetName(), nil)
+			},
+		})
+		r.Infra.Status.APIServerInternalURL = "https://" + endpointHost + ":7443"
+
+		err := r.reconcileDrift(ctx, log.Log, awsCluster)
+		Expect(err).To(MatchError(errInfraClusterDrifted))
+		Expect(err).To(MatchError(ContainSubstring(`spec.controlPlaneEndpoint.port is "6443" instead of "7443"`)))
+	})
+
+	It("should return other update errors", func() {
+		r := newReconciler(interceptor.Funcs{
+			Patch: func(_ context.Context, _ client.WithWatch, _ client.Object, _ client.Patch, _ ...client.PatchOption) error {
+				return kerrors.NewServiceUnavailable("unavailable")
+			},
+		})
+		r.Infra.Status.APIServerInternalURL = "https://" + endpointHost + ":7443"
+
+		err := r.reconcileDrift(ctx, log.Log, awsCluster)
+		Expect(err).To(HaveOccurred())
+		Expect(err).ToNot(MatchError(errInfraClusterDrifted))
+	})
+})
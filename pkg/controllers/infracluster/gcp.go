@@ -18,8 +18,6 @@ package infracluster
 import (
 	"context"
 	"fmt"
-	"net/url"
-	"strconv"
 
 	"github.com/go-logr/logr"
 	mapiv1beta1 "github.com/openshift/api/machine/v1beta1"
@@ -49,14 +47,9 @@ func (r *InfraClusterController) ensureGCPCluster(ctx context.Context, log logr.
 
 	log.Info(fmt.Sprintf("GCPCluster %s/%s does not exist, creating it", target.Namespace, target.Name))
 
-	apiURL, err := url.Parse(r.Infra.Status.APIServerInternalURL)
+	endpoint, err := r.getAPIServerEndpoint(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse apiUrl: %w", err)
-	}
-
-	port, err := strconv.ParseInt(apiURL.Port(), 10, 32)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse apiUrl port: %w", err)
+		return nil, fmt.Errorf("failed to get API server endpoint: %w", err)
 	}
 
 	if r.Infra.Status.PlatformStatus == nil {
@@ -68,7 +61,7 @@ func (r *InfraClusterController) ensureGCPCluster(ctx context.Context, log logr.
 		return nil, fmt.Errorf("error obtaining GCP Project ID: %w", err)
 	}
 
-	providerSpec, err := getGCPMAPIProviderSpec(ctx, r.Client)
+	providerSpec, err := getGCPMAPIProviderSpec(ctx, r.Client, r.MAPINamespace)
 	if err != nil {
 		return nil, fmt.Errorf("error obtaining GCP Provider Spec: %w", err)
 	}
@@ -90,8 +83,8 @@ func (r *InfraClusterController) ensureGCPCluster(ctx context.Context, log logr.
 			Region:  r.Infra.Status.PlatformStatus.GCP.Region,
 			Project: gcpProjectID,
 			ControlPlaneEndpoint: clusterv1.APIEndpoint{
-				Host: apiURL.Hostname(),
-				Port: int32(port),
+				Host: endpoint.Host,
+				Port: endpoint.Port,
 			},
 		},
 	}
@@ -106,8 +99,8 @@ func (r *InfraClusterController) ensureGCPCluster(ctx context.Context, log logr.
 }
 
 // getGCPMAPIProviderSpec returns a GCP Machine ProviderSpec from the the cluster.
-func getGCPMAPIProviderSpec(ctx context.Context, cl client.Client) (*mapiv1beta1.GCPMachineProviderSpec, error) {
-	rawProviderSpec, err := getRawMAPIProviderSpec(ctx, cl)
+func getGCPMAPIProviderSpec(ctx context.Context, cl client.Client, mapiNamespace string) (*mapiv1beta1.GCPMachineProviderSpec, error) {
+	rawProviderSpec, err := getRawMAPIProviderSpec(ctx, cl, mapiNamespace)
 	if err != nil {
 		return nil, fmt.Errorf("unable to obtain MAPI ProviderSpec: %w", err)
 	}
@@ -124,7 +117,7 @@ func getGCPMAPIProviderSpec(ctx context.Context, cl client.Client) (*mapiv1beta1
 func (r *InfraClusterController) getGCPProjectID(ctx context.Context) (string, error) {
 	if r.Infra.Spec.PlatformSpec.GCP == nil || len(r.Infra.Status.PlatformStatus.GCP.ProjectID) == 0 {
 		// Devise GCP Project ID via MAPI providerSpec.
-		machineSpec, err := getGCPMAPIProviderSpec(ctx, r.Client)
+		machineSpec, err := getGCPMAPIProviderSpec(ctx, r.Client, r.MAPINamespace)
 		if err != nil || machineSpec == nil {
 			return "", fmt.Errorf("unable to get GCP MAPI ProviderSpec: %w", err)
 		}
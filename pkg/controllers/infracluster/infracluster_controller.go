@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
 
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -38,8 +39,11 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	mapiv1 "github.com/openshift/api/machine/v1"
 	mapiv1beta1 "github.com/openshift/api/machine/v1beta1"
-	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/metrics"
 	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
+	"github.com/openshift/cluster-capi-operator/pkg/tracing"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
 )
 
 const (
@@ -52,9 +56,10 @@ const (
 	InfraClusterControllerDegradedCondition = "InfraClusterControllerDegraded"
 
 	defaultCAPINamespace = "openshift-cluster-api"
-	defaultMAPINamespace = "openshift-machine-api"
 	controllerName       = "InfraClusterController"
 	clusterOperatorName  = "cluster-api"
+
+	infrastructureResourceName = "cluster"
 	// This is the managedByAnnotation value that this controller sets by default when it creates an InfraCluster object.
 	// If the managedByAnnotation key is set, and it has this as the value, it means this controller is managing the InfraCluster.
 	managedByAnnotationValueClusterCAPIOperatorInfraClusterController = "cluster-capi-operator-infracluster-controller"
@@ -78,6 +83,10 @@ type InfraClusterController struct {
 	RestCfg  *rest.Config
 	Platform configv1.PlatformType
 	Infra    *configv1.Infrastructure
+
+	// MAPINamespace is the namespace of the Machine API resources the provider spec of the InfraCluster is
+	// derived from, defaults to the openshift-machine-api namespace.
+	MAPINamespace string
 }
 
 // Reconcile reconciles the cluster-api ClusterOperator object.
@@ -86,7 +95,38 @@ func (r *InfraClusterController) Reconcile(ctx context.Context, req ctrl.Request
 
 	log.Info("Reconciling InfraCluster")
 
-	res, err := r.reconcile(ctx, log)
+	co, err := r.GetOrCreateClusterOperator(ctx)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get cluster operator: %w", err)
+	}
+
+	// A malformed override is reported rather than retried, the ClusterOperator is reconciled again once it is fixed.
+	if _, _, err := util.GetAPIServerEndpointOverride(co.GetAnnotations()); err != nil {
+		if err := r.setDegradedCondition(ctx, log, operatorstatus.ReasonSyncFailed, err); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to set conditions for InfraCluster controller: %w", err)
+		}
+
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.refreshInfrastructure(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	reconcileCtx, span := tracing.Start(ctx, "InfraCluster", attribute.String("platform", string(r.Platform)))
+	res, err := r.reconcile(reconcileCtx, log)
+	tracing.End(span, err)
+
+	// An InfraCluster that cannot be updated in place is reported rather than retried, it is reconciled again once
+	// it is recreated or the Infrastructure changes.
+	if errors.Is(err, errInfraClusterDrifted) {
+		if err := r.setDegradedCondition(ctx, log, ReasonManualInterventionRequired, err); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to set conditions for InfraCluster controller: %w", err)
+		}
+
+		return ctrl.Result{}, nil
+	}
+
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("error during reconcile: %w", err)
 	}
@@ -99,11 +139,19 @@ func (r *InfraClusterController) Reconcile(ctx context.Context, req ctrl.Request
 }
 
 func (r *InfraClusterController) reconcile(ctx context.Context, log logr.Logger) (ctrl.Result, error) {
+	_, span := tracing.Start(ctx, tracing.PhaseFetch)
 	infraCluster, err := r.ensureInfraCluster(ctx, log)
+
 	if err != nil && errors.Is(err, errPlatformNotSupported) {
+		tracing.End(span, nil)
 		log.Info("Could not find or create an InfraCluster on this platform as it is not yet supported.")
+
 		return ctrl.Result{}, nil
-	} else if err != nil {
+	}
+
+	tracing.End(span, err)
+
+	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("unable to ensure InfraCluster: %w", err)
 	}
 
@@ -139,33 +187,81 @@ func (r *InfraClusterController) reconcileInfraCluster(ctx context.Context, log
 	}
 
 	// At this point it is this controller's responsibility to manage this InfraCluster object.
+	if err := r.reconcileManagedByLabel(ctx, infraCluster); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	driftErr := r.reconcileDrift(ctx, log, infraCluster)
+	if driftErr != nil && !errors.Is(driftErr, errInfraClusterDrifted) {
+		return ctrl.Result{}, driftErr
+	}
+
+	if err := r.reconcileReadiness(ctx, log, infraCluster); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// The fields that cannot be updated in place do not prevent the InfraCluster from being ready, they are reported afterwards.
+	return ctrl.Result{}, driftErr
+}
+
+// reconcileManagedByLabel labels the InfraCluster as managed by the operator, if it is not already, so that it is
+// protected from manual changes.
+func (r *InfraClusterController) reconcileManagedByLabel(ctx context.Context, infraCluster client.Object) error {
+	if util.HasManagedByLabel(infraCluster) {
+		return nil
+	}
+
+	patchBase, ok := infraCluster.DeepCopyObject().(client.Object)
+	if !ok {
+		return errCouldNotDeepCopyInfraObject
+	}
+
+	util.SetManagedByLabel(infraCluster)
+
+	if err := r.Patch(ctx, infraCluster, client.MergeFrom(patchBase)); err != nil {
+		return fmt.Errorf("unable to label InfraCluster: %w", err)
+	}
+
+	return nil
+}
+
+// reconcileReadiness sets the InfraCluster ready, if it is not already.
+func (r *InfraClusterController) reconcileReadiness(ctx context.Context, log logr.Logger, infraCluster client.Object) error {
 	isReady, err := getReadiness(infraCluster)
 	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("unable to get readiness for InfraCluster: %w", err)
+		return fmt.Errorf("unable to get readiness for InfraCluster: %w", err)
 	}
 
 	if isReady {
 		// The Infrastructure for this CAPI Cluster is already ready - nothing to do.
-		return ctrl.Result{}, nil
+		metrics.SetInfraClusterReady(r.Platform, true)
+
+		return nil
 	}
 
 	infraClusterPatchCopy, ok := infraCluster.DeepCopyObject().(client.Object)
 	if !ok {
-		return ctrl.Result{}, errCouldNotDeepCopyInfraObject
+		return errCouldNotDeepCopyInfraObject
 	}
 
 	// Set Status.Ready=true to indicate that cluster's infrastructure ready.
 	if err := setReadiness(infraCluster, true); err != nil {
-		return ctrl.Result{}, fmt.Errorf("unable to set readiness for InfraCluster: %w", err)
+		return fmt.Errorf("unable to set readiness for InfraCluster: %w", err)
 	}
 
-	if err := r.Client.Status().Patch(ctx, infraCluster, client.MergeFrom(infraClusterPatchCopy)); err != nil {
-		return ctrl.Result{}, fmt.Errorf("unable to patch InfraCluster: %w", err)
+	if err := tracing.Trace(ctx, tracing.PhaseApply, func(ctx context.Context) error {
+		return r.Client.Status().Patch(ctx, infraCluster, client.MergeFrom(infraClusterPatchCopy))
+	}); err != nil {
+		metrics.SetInfraClusterReady(r.Platform, false)
+
+		return fmt.Errorf("unable to patch InfraCluster: %w", err)
 	}
 
+	metrics.SetInfraClusterReady(r.Platform, true)
+
 	log.Info(fmt.Sprintf("InfraCluster '%s/%s' successfully set to Ready", infraCluster.GetNamespace(), infraCluster.GetName()))
 
-	return ctrl.Result{}, nil
+	return nil
 }
 
 // ensureInfraCluster ensures an InfraCluster object exists in the cluster.
@@ -227,6 +323,37 @@ func (r *InfraClusterController) ensureInfraCluster(ctx context.Context, log log
 	return infraCluster, nil
 }
 
+// refreshInfrastructure reads the Infrastructure again, so that its changes are reflected in the InfraCluster.
+// The Infrastructure the controller was set up with is kept when it cannot be found.
+func (r *InfraClusterController) refreshInfrastructure(ctx context.Context) error {
+	infra := &configv1.Infrastructure{}
+	if err := r.Get(ctx, client.ObjectKey{Name: infrastructureResourceName}, infra); kerrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get Infrastructure: %w", err)
+	}
+
+	r.Infra = infra
+
+	return nil
+}
+
+// getAPIServerEndpoint returns the endpoint the control plane of the InfraCluster is reached at.
+// It is overridden by the util.APIServerEndpointOverrideAnnotation of the ClusterOperator, when set.
+func (r *InfraClusterController) getAPIServerEndpoint(ctx context.Context) (util.APIServerEndpoint, error) {
+	co, err := r.GetOrCreateClusterOperator(ctx)
+	if err != nil {
+		return util.APIServerEndpoint{}, fmt.Errorf("failed to get cluster operator: %w", err)
+	}
+
+	endpoint, err := util.GetAPIServerEndpoint(co.GetAnnotations(), r.Infra)
+	if err != nil {
+		return util.APIServerEndpoint{}, fmt.Errorf("failed to get API server endpoint: %w", err)
+	}
+
+	return endpoint, nil
+}
+
 // setAvailableCondition sets the ClusterOperator status condition to Available.
 func (r *InfraClusterController) setAvailableCondition(ctx context.Context, log logr.Logger) error {
 	co, err := r.GetOrCreateClusterOperator(ctx)
@@ -241,7 +368,7 @@ func (r *InfraClusterController) setAvailableCondition(ctx context.Context, log
 			"InfraCluster Controller works as expected"),
 	}
 
-	co.Status.Versions = []configv1.OperandVersion{{Name: controllers.OperatorVersionKey, Version: r.ReleaseVersion}}
+	r.SetOperatorVersion(co)
 
 	log.V(2).Info("InfraCluster Controller is Available")
 
@@ -252,11 +379,45 @@ func (r *InfraClusterController) setAvailableCondition(ctx context.Context, log
 	return nil
 }
 
+// setDegradedCondition sets the ClusterOperator status condition to Degraded, with the given reason and the reconcile error in the message.
+func (r *InfraClusterController) setDegradedCondition(ctx context.Context, log logr.Logger, reason string, reconcileErr error) error {
+	co, err := r.GetOrCreateClusterOperator(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get cluster operator: %w", err)
+	}
+
+	message := fmt.Sprintf("InfraCluster Controller failed to reconcile: %v", reconcileErr)
+
+	conds := []configv1.ClusterOperatorStatusCondition{
+		operatorstatus.NewClusterOperatorStatusCondition(InfraClusterControllerAvailableCondition, configv1.ConditionFalse, reason, message),
+		operatorstatus.NewClusterOperatorStatusCondition(InfraClusterControllerDegradedCondition, configv1.ConditionTrue, reason, message),
+	}
+
+	r.SetOperatorVersion(co)
+
+	log.Info("InfraCluster Controller is Degraded", "reason", reconcileErr.Error())
+
+	if err := r.SyncStatus(ctx, co, conds); err != nil {
+		return fmt.Errorf("failed to sync status: %w", err)
+	}
+
+	return nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *InfraClusterController) SetupWithManager(mgr ctrl.Manager, watchedObject client.Object) error {
+	if r.MAPINamespace == "" {
+		r.MAPINamespace = consts.DefaultMAPIManagedNamespace
+	}
+
 	if err := ctrl.NewControllerManagedBy(mgr).
 		Named(controllerName).
 		For(&configv1.ClusterOperator{}, builder.WithPredicates(clusterOperatorPredicates())).
+		Watches(
+			&configv1.Infrastructure{},
+			handler.EnqueueRequestsFromMapFunc(toClusterOperator),
+			builder.WithPredicates(infrastructurePredicate()),
+		).
 		Watches(
 			watchedObject,
 			handler.EnqueueRequestsFromMapFunc(toClusterOperator),
@@ -305,8 +466,8 @@ func getReadiness(infraCluster client.Object) (bool, error) {
 }
 
 // getRawMAPIProviderSpec returns a raw Machine ProviderSpec from the the cluster.
-func getRawMAPIProviderSpec(ctx context.Context, cl client.Client) ([]byte, error) {
-	cpms, err := getActiveCPMS(ctx, cl)
+func getRawMAPIProviderSpec(ctx context.Context, cl client.Client, mapiNamespace string) ([]byte, error) {
+	cpms, err := getActiveCPMS(ctx, cl, mapiNamespace)
 	if err != nil {
 		return nil, fmt.Errorf("unable to get control plane machine set: %w", err)
 	}
@@ -315,7 +476,7 @@ func getRawMAPIProviderSpec(ctx context.Context, cl client.Client) ([]byte, erro
 		// The CPMS is not present or inactive.
 		// Devise VSphere providerSpec via one of the machines in the cluster.
 		machineSetList := &mapiv1beta1.MachineSetList{}
-		if err := cl.List(ctx, machineSetList, client.InNamespace(defaultMAPINamespace)); err != nil {
+		if err := cl.List(ctx, machineSetList, client.InNamespace(mapiNamespace)); err != nil {
 			return nil, fmt.Errorf("%w: %w", errUnableToListMachineSets, err)
 		}
 
@@ -331,9 +492,9 @@ func getRawMAPIProviderSpec(ctx context.Context, cl client.Client) ([]byte, erro
 }
 
 // getActiveCPMS returns the CPMS if it exists and it is in Active state, otherwise returns nil.
-func getActiveCPMS(ctx context.Context, cl client.Client) (*mapiv1.ControlPlaneMachineSet, error) {
+func getActiveCPMS(ctx context.Context, cl client.Client, mapiNamespace string) (*mapiv1.ControlPlaneMachineSet, error) {
 	cpms := &mapiv1.ControlPlaneMachineSet{}
-	if err := cl.Get(ctx, client.ObjectKey{Name: "cluster", Namespace: defaultMAPINamespace}, cpms); err != nil {
+	if err := cl.Get(ctx, client.ObjectKey{Name: "cluster", Namespace: mapiNamespace}, cpms); err != nil {
 		if kerrors.IsNotFound(err) {
 			return nil, nil //nolint:nilnil
 		}
@@ -22,6 +22,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	configv1 "github.com/openshift/api/config/v1"
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
 	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
 
 	"github.com/openshift/cluster-api-actuator-pkg/testutils"
@@ -111,6 +112,7 @@ var _ = Describe("InfraCluster", func() {
 			Eventually(komega.Object(bareInfraCluster)).Should(SatisfyAll(
 				HaveField("Status.Ready", BeTrue()),
 				HaveField("Annotations", HaveKeyWithValue(clusterv1.ManagedByAnnotation, managedByAnnotationValueClusterCAPIOperatorInfraClusterController)),
+				HaveField("Labels", HaveKeyWithValue(consts.ManagedByLabel, consts.ManagedByLabelValue)),
 			))
 		})
 	})
@@ -200,6 +202,34 @@ var _ = Describe("InfraCluster", func() {
 			})
 		})
 	})
+
+	Context("When the API server URL of the Infrastructure changes", func() {
+		BeforeEach(func() {
+			Expect(cl.Create(ctx, infraClusterWithExternallyManagedByAnnotation.DeepCopy())).To(Succeed())
+
+			infra := configv1resourcebuilder.Infrastructure().AsAWS(ocpInfraClusterName, awsTestRegion).Build()
+			infra.SetName(infrastructureResourceName)
+			status := infra.Status.DeepCopy()
+			Expect(cl.Create(ctx, infra)).To(Succeed())
+
+			Eventually(komega.UpdateStatus(infra, func() {
+				infra.Status = *status
+				infra.Status.APIServerInternalURL = "https://api-int.test-cluster.test-domain:7443"
+			})).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			testutils.CleanupResources(Default, ctx, cfg, cl, "", &configv1.Infrastructure{})
+		})
+
+		It("should update the control plane endpoint of the InfraCluster in place", func() {
+			Eventually(komega.Object(bareInfraCluster)).Should(SatisfyAll(
+				HaveField("Spec.ControlPlaneEndpoint.Host", Equal("api-int.test-cluster.test-domain")),
+				HaveField("Spec.ControlPlaneEndpoint.Port", BeEquivalentTo(7443)),
+				HaveField("Status.Ready", BeTrue()),
+			))
+		})
+	})
 })
 
 func mustPatchAWSInfraClusterReadiness(awsInfraCluster *awsv1.AWSCluster, readiness bool) {
@@ -20,8 +20,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net/url"
-	"strconv"
 
 	"github.com/go-logr/logr"
 
@@ -61,14 +59,9 @@ func (r *InfraClusterController) ensureIBMPowerVSCluster(ctx context.Context, lo
 
 	log.Info(fmt.Sprintf("IBMPowerVSCluster %s does not exist, creating it", klog.KObj(target)))
 
-	apiURL, err := url.Parse(r.Infra.Status.APIServerInternalURL)
+	endpoint, err := r.getAPIServerEndpoint(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse apiURL: %w", err)
-	}
-
-	port, err := strconv.ParseInt(apiURL.Port(), 10, 32)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse apiURL port: %w", err)
+		return nil, fmt.Errorf("failed to get API server endpoint: %w", err)
 	}
 
 	if r.Infra.Status.PlatformStatus == nil {
@@ -77,7 +70,7 @@ func (r *InfraClusterController) ensureIBMPowerVSCluster(ctx context.Context, lo
 
 	// Derive service instance and network from machine spec
 
-	machineSpec, err := getPowerVSMAPIProviderSpec(ctx, r.Client)
+	machineSpec, err := getPowerVSMAPIProviderSpec(ctx, r.Client, r.MAPINamespace)
 	if err != nil {
 		return nil, fmt.Errorf("unable to get PowerVS MAPI ProviderSpec: %w", err)
 	}
@@ -104,8 +97,8 @@ func (r *InfraClusterController) ensureIBMPowerVSCluster(ctx context.Context, lo
 		},
 		Spec: ibmpowervsv1.IBMPowerVSClusterSpec{
 			ControlPlaneEndpoint: clusterv1.APIEndpoint{
-				Host: apiURL.Hostname(),
-				Port: int32(port),
+				Host: endpoint.Host,
+				Port: endpoint.Port,
 			},
 			ServiceInstance: serviceInstance,
 			Network:         network,
@@ -122,8 +115,8 @@ func (r *InfraClusterController) ensureIBMPowerVSCluster(ctx context.Context, lo
 }
 
 // getPowerVSMAPIProviderSpec returns a PowerVS Machine ProviderSpec from the the cluster.
-func getPowerVSMAPIProviderSpec(ctx context.Context, cl client.Client) (*mapiv1.PowerVSMachineProviderConfig, error) {
-	rawProviderSpec, err := getRawMAPIProviderSpec(ctx, cl)
+func getPowerVSMAPIProviderSpec(ctx context.Context, cl client.Client, mapiNamespace string) (*mapiv1.PowerVSMachineProviderConfig, error) {
+	rawProviderSpec, err := getRawMAPIProviderSpec(ctx, cl, mapiNamespace)
 	if err != nil {
 		return nil, fmt.Errorf("unable to obtain MAPI ProviderSpec: %w", err)
 	}
@@ -19,8 +19,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net/url"
-	"strconv"
 
 	"github.com/go-logr/logr"
 	mapiv1beta1 "github.com/openshift/api/machine/v1beta1"
@@ -67,14 +65,9 @@ func (r *InfraClusterController) ensureVSphereCluster(ctx context.Context, log l
 
 	log.Info(fmt.Sprintf("VSphereCluster %s/%s does not exist, creating it", target.Namespace, target.Name))
 
-	apiURL, err := url.Parse(r.Infra.Status.APIServerInternalURL)
+	endpoint, err := r.getAPIServerEndpoint(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse apiUrl: %w", err)
-	}
-
-	port, err := strconv.ParseInt(apiURL.Port(), 10, 32)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse apiUrl port: %w", err)
+		return nil, fmt.Errorf("failed to get API server endpoint: %w", err)
 	}
 
 	if r.Infra.Status.PlatformStatus == nil {
@@ -98,8 +91,8 @@ func (r *InfraClusterController) ensureVSphereCluster(ctx context.Context, log l
 				Name: r.Infra.Status.InfrastructureName,
 			},
 			ControlPlaneEndpoint: vspherev1.APIEndpoint{
-				Host: apiURL.Hostname(),
-				Port: int32(port),
+				Host: endpoint.Host,
+				Port: endpoint.Port,
 			},
 		},
 	}
@@ -114,8 +107,8 @@ func (r *InfraClusterController) ensureVSphereCluster(ctx context.Context, log l
 }
 
 // getVSphereMAPIProviderSpec returns a VSphere Machine ProviderSpec from the the cluster.
-func getVSphereMAPIProviderSpec(ctx context.Context, cl client.Client) (*mapiv1beta1.VSphereMachineProviderSpec, error) {
-	rawProviderSpec, err := getRawMAPIProviderSpec(ctx, cl)
+func getVSphereMAPIProviderSpec(ctx context.Context, cl client.Client, mapiNamespace string) (*mapiv1beta1.VSphereMachineProviderSpec, error) {
+	rawProviderSpec, err := getRawMAPIProviderSpec(ctx, cl, mapiNamespace)
 	if err != nil {
 		return nil, fmt.Errorf("unable to obtain MAPI ProviderSpec: %w", err)
 	}
@@ -188,7 +181,7 @@ func (r *InfraClusterController) getVSphereCredentials(ctx context.Context, vsph
 func (r *InfraClusterController) getVSphereServerAddr(ctx context.Context) (string, error) {
 	if r.Infra.Spec.PlatformSpec.VSphere == nil || len(r.Infra.Spec.PlatformSpec.VSphere.VCenters) == 0 {
 		// Devise VSphere server addr via MAPI providerSpec.
-		machineSpec, err := getVSphereMAPIProviderSpec(ctx, r.Client)
+		machineSpec, err := getVSphereMAPIProviderSpec(ctx, r.Client, r.MAPINamespace)
 		if err != nil {
 			return "", fmt.Errorf("unable to get VSphere MAPI ProviderSpec: %w", err)
 		}
@@ -42,6 +42,22 @@ func clusterOperatorPredicates() predicate.Funcs {
 	}
 }
 
+// infrastructurePredicate defines a predicate function for the cluster Infrastructure, whose status changes are
+// reflected in the InfraCluster.
+func infrastructurePredicate() predicate.Funcs {
+	isInfrastructure := func(obj runtime.Object) bool {
+		infra, ok := obj.(*configv1.Infrastructure)
+		return ok && infra.GetName() == infrastructureResourceName
+	}
+
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return isInfrastructure(e.Object) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return isInfrastructure(e.ObjectNew) },
+		GenericFunc: func(e event.GenericEvent) bool { return isInfrastructure(e.Object) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+	}
+}
+
 // toClusterOperator maps a reconcile request to the cluster-api ClusterOperator.
 func toClusterOperator(ctx context.Context, cO client.Object) []reconcile.Request {
 	return []reconcile.Request{{
@@ -38,6 +38,7 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/cluster-capi-operator/pkg/controllers"
 	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
 )
 
 const (
@@ -60,11 +61,13 @@ func (r *KubeconfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(
 			&corev1.Secret{},
 			builder.WithPredicates(tokenSecretPredicate()),
+			builder.OnlyMetadata,
 		).
 		Watches(
 			&corev1.Secret{},
 			handler.EnqueueRequestsFromMapFunc(toTokenSecret),
 			builder.WithPredicates(kubeconfigSecretPredicate()),
+			builder.OnlyMetadata,
 		).
 		Complete(r); err != nil {
 		return fmt.Errorf("failed to create controller: %w", err)
@@ -81,7 +84,7 @@ func (r *KubeconfigReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (c
 	if err := r.Get(ctx, client.ObjectKey{Name: controllers.InfrastructureResourceName}, infra); err != nil {
 		log.Error(err, "Unable to retrieve Infrastructure object")
 
-		if err := r.SetStatusDegraded(ctx, err); err != nil {
+		if err := r.SetControllerDegraded(ctx, controllerName, err); err != nil {
 			return ctrl.Result{}, fmt.Errorf("error syncing ClusterOperatorStatus: %w", err)
 		}
 
@@ -91,7 +94,7 @@ func (r *KubeconfigReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (c
 	if infra.Status.PlatformStatus == nil {
 		log.Info("No platform status exists in infrastructure object. Skipping kubeconfig reconciliation...")
 
-		if err := r.SetStatusAvailable(ctx, ""); err != nil {
+		if err := r.ClearControllerDegraded(ctx, controllerName); err != nil {
 			return ctrl.Result{}, fmt.Errorf("error syncing ClusterOperatorStatus: %w", err)
 		}
 
@@ -100,27 +103,43 @@ func (r *KubeconfigReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (c
 
 	r.clusterName = infra.Status.InfrastructureName
 
-	log.Info("Reconciling kubeconfig secret")
+	apiServerEndpoint, message := r.getAPIServerEndpoint(infra)
+	if err := r.SetControlPlaneTopologyMode(ctx, controllerName, util.IsExternalControlPlaneTopology(infra), message); err != nil {
+		return ctrl.Result{}, fmt.Errorf("error syncing ClusterOperatorStatus: %w", err)
+	}
 
-	res, err := r.reconcileKubeconfig(ctx, log)
+	log.Info("Reconciling kubeconfig secret", "apiServerEndpoint", apiServerEndpoint)
+
+	res, err := r.reconcileKubeconfig(ctx, log, apiServerEndpoint)
 	if err != nil {
 		log.Error(err, "Error reconciling kubeconfig")
 
-		if err := r.SetStatusDegraded(ctx, err); err != nil {
+		if err := r.SetControllerDegraded(ctx, controllerName, err); err != nil {
 			return ctrl.Result{}, fmt.Errorf("error syncing ClusterOperatorStatus: %w", err)
 		}
 
 		return ctrl.Result{}, fmt.Errorf("error reconciling kubeconfig: %w", err)
 	}
 
-	if err := r.SetStatusAvailable(ctx, ""); err != nil {
+	if err := r.ClearControllerDegraded(ctx, controllerName); err != nil {
 		return ctrl.Result{}, fmt.Errorf("error syncing ClusterOperatorStatus: %w", err)
 	}
 
 	return res, nil
 }
 
-func (r *KubeconfigReconciler) reconcileKubeconfig(ctx context.Context, log logr.Logger) (ctrl.Result, error) {
+// getAPIServerEndpoint returns the API server endpoint of the kubeconfig, and a message describing how it was chosen.
+// The operator reaches the API server through its in-cluster address. On clusters with an external control plane, that
+// address is only served by a proxy on the nodes, the kubeconfig points at the API server URL of the Infrastructure.
+func (r *KubeconfigReconciler) getAPIServerEndpoint(infra *configv1.Infrastructure) (string, string) {
+	if util.IsExternalControlPlaneTopology(infra) && infra.Status.APIServerURL != "" {
+		return infra.Status.APIServerURL, "The control plane is external, the kubeconfig points at the API server URL of the Infrastructure"
+	}
+
+	return r.RestCfg.Host, "The kubeconfig points at the in-cluster API server address"
+}
+
+func (r *KubeconfigReconciler) reconcileKubeconfig(ctx context.Context, log logr.Logger, apiServerEndpoint string) (ctrl.Result, error) {
 	// Get the token secret
 	tokenSecret := &corev1.Secret{}
 	tokenSecretKey := client.ObjectKey{
@@ -153,7 +172,7 @@ func (r *KubeconfigReconciler) reconcileKubeconfig(ctx context.Context, log logr
 	kubeconfig, err := generateKubeconfig(kubeconfigOptions{
 		token:            tokenSecret.Data["token"],
 		caCert:           tokenSecret.Data["ca.crt"],
-		apiServerEnpoint: r.RestCfg.Host,
+		apiServerEnpoint: apiServerEndpoint,
 		clusterName:      r.clusterName,
 	})
 
@@ -190,6 +209,7 @@ func newKubeConfigSecret(clusterName string, data []byte) *corev1.Secret {
 			Namespace: controllers.DefaultManagedNamespace,
 			Labels: map[string]string{
 				clusterv1.ClusterNameLabel: clusterName,
+				controllers.ManagedByLabel: controllers.ManagedByLabelValue,
 			},
 		},
 		Data: map[string][]byte{
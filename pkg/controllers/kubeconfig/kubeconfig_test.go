@@ -23,10 +23,12 @@ import (
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/cluster-capi-operator/pkg/controllers"
 	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
 	"github.com/openshift/cluster-capi-operator/pkg/test"
@@ -67,7 +69,7 @@ var _ = Describe("Reconcile kubeconfig secret", func() {
 		})
 
 		It("should create a kubeconfig secret when it doesn't exist", func() {
-			_, err := r.reconcileKubeconfig(ctx, log)
+			_, err := r.reconcileKubeconfig(ctx, log, cfg.Host)
 			Expect(err).To(Succeed())
 
 			Expect(cl.Get(ctx, client.ObjectKey{
@@ -78,9 +80,9 @@ var _ = Describe("Reconcile kubeconfig secret", func() {
 		})
 
 		It("should reconcile existing kubeconfig secret when it doesn't exist", func() {
-			_, err := r.reconcileKubeconfig(ctx, log)
+			_, err := r.reconcileKubeconfig(ctx, log, cfg.Host)
 			Expect(err).To(Succeed())
-			_, err = r.reconcileKubeconfig(ctx, log)
+			_, err = r.reconcileKubeconfig(ctx, log, cfg.Host)
 			Expect(err).To(Succeed())
 
 			Expect(cl.Get(ctx, client.ObjectKey{
@@ -96,7 +98,7 @@ var _ = Describe("Reconcile kubeconfig secret", func() {
 				return cl.Get(ctx, client.ObjectKeyFromObject(tokenSecret), tokenSecret)
 			}, timeout).Should(Not(Succeed()))
 
-			res, err := r.reconcileKubeconfig(ctx, log)
+			res, err := r.reconcileKubeconfig(ctx, log, cfg.Host)
 			Expect(err).To(Succeed())
 			Expect(res.RequeueAfter).To(Equal(1 * time.Minute))
 		})
@@ -107,7 +109,7 @@ var _ = Describe("Reconcile kubeconfig secret", func() {
 			r.Client = fakeClient
 			tokenSecret.SetCreationTimestamp(metav1.Time{Time: time.Now().Add(-1 * time.Hour)})
 			Expect(fakeClient.Update(ctx, tokenSecret)).To(Succeed())
-			res, err := r.reconcileKubeconfig(ctx, log)
+			res, err := r.reconcileKubeconfig(ctx, log, cfg.Host)
 			Expect(err).To(Succeed())
 
 			Expect(res.RequeueAfter).To(Equal(1 * time.Minute))
@@ -117,3 +119,29 @@ var _ = Describe("Reconcile kubeconfig secret", func() {
 		})
 	})
 })
+
+var _ = Describe("Kubeconfig API server endpoint", func() {
+	r := &KubeconfigReconciler{RestCfg: &rest.Config{Host: "https://172.30.0.1:443"}}
+
+	infra := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			APIServerURL:         "https://api.hosted.example.com:443",
+			APIServerInternalURL: "https://api-int.hosted.example.com:443",
+			ControlPlaneTopology: configv1.HighlyAvailableTopologyMode,
+		},
+	}
+
+	It("should use the in-cluster API server address when the control plane is self hosted", func() {
+		endpoint, _ := r.getAPIServerEndpoint(infra)
+		Expect(endpoint).To(Equal("https://172.30.0.1:443"))
+	})
+
+	It("should use the API server URL of the Infrastructure when the control plane is external", func() {
+		externalInfra := infra.DeepCopy()
+		externalInfra.Status.ControlPlaneTopology = configv1.ExternalTopologyMode
+
+		endpoint, message := r.getAPIServerEndpoint(externalInfra)
+		Expect(endpoint).To(Equal("https://api.hosted.example.com:443"))
+		Expect(message).To(ContainSubstring("control plane is external"))
+	})
+})
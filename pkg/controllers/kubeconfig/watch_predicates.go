@@ -19,8 +19,6 @@ import (
 	"context"
 	"strings"
 
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -36,12 +34,7 @@ func toTokenSecret(ctx context.Context, o client.Object) []reconcile.Request {
 }
 
 func tokenSecretPredicate() predicate.Funcs {
-	isOwnedTokenSecret := func(obj runtime.Object) bool {
-		secret, ok := obj.(*corev1.Secret)
-		if !ok {
-			panic("expected to get an of object of type corev1.Secret")
-		}
-
+	isOwnedTokenSecret := func(secret client.Object) bool {
 		return secret.GetNamespace() == controllers.DefaultManagedNamespace && secret.GetName() == tokenSecretName
 	}
 
@@ -54,12 +47,7 @@ func tokenSecretPredicate() predicate.Funcs {
 }
 
 func kubeconfigSecretPredicate() predicate.Funcs {
-	isKubeconfigSecret := func(obj runtime.Object) bool {
-		secret, ok := obj.(*corev1.Secret)
-		if !ok {
-			panic("expected to get an of object of type corev1.Secret")
-		}
-
+	isKubeconfigSecret := func(secret client.Object) bool {
 		return secret.GetNamespace() == controllers.DefaultManagedNamespace && strings.HasSuffix(secret.GetName(), "-kubeconfig")
 	}
 
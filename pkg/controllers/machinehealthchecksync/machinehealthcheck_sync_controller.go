@@ -0,0 +1,84 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package machinehealthchecksync mirrors the Machine API MachineHealthChecks to the Cluster API, with the
+// synccommon Mirror.
+package machinehealthchecksync
+
+import (
+	"context"
+
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/synccommon"
+	"github.com/openshift/cluster-capi-operator/pkg/conversion/mapi2capi"
+)
+
+const (
+	controllerName = "machinehealthcheck"
+
+	// mirrorFieldOwner is the field manager the CAPI MachineHealthChecks are applied as.
+	mirrorFieldOwner = "machinehealthcheck-sync-controller-mirror"
+)
+
+// MachineHealthCheckSyncReconciler mirrors the MAPI MachineHealthChecks to CAPI MachineHealthChecks of the same name.
+//
+// MachineHealthChecks have no authoritative API of their own: they remain authored in the Machine API, and their CAPI
+// mirrors remediate the CAPI Machines. A Machine is only remediated by the API it is authoritative in, as the
+// MachineSets of the other API are paused.
+type MachineHealthCheckSyncReconciler struct {
+	synccommon.Mirror[*machinev1beta1.MachineHealthCheck, *capiv1beta1.MachineHealthCheck]
+
+	Infra *configv1.Infrastructure
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MachineHealthCheckSyncReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.FieldOwner = mirrorFieldOwner
+	r.Hooks = r.hooks()
+
+	return r.Mirror.SetupWithManager(mgr, controllerName)
+}
+
+// hooks returns the MachineHealthCheck specific parts of the Mirror.
+func (r *MachineHealthCheckSyncReconciler) hooks() synccommon.Hooks[*machinev1beta1.MachineHealthCheck, *capiv1beta1.MachineHealthCheck] {
+	return synccommon.Hooks[*machinev1beta1.MachineHealthCheck, *capiv1beta1.MachineHealthCheck]{
+		NewMAPI:     func() *machinev1beta1.MachineHealthCheck { return &machinev1beta1.MachineHealthCheck{} },
+		NewCAPI:     func() *capiv1beta1.MachineHealthCheck { return &capiv1beta1.MachineHealthCheck{} },
+		NewMAPIList: func() client.ObjectList { return &machinev1beta1.MachineHealthCheckList{} },
+		Authority: func(*machinev1beta1.MachineHealthCheck) machinev1beta1.MachineAuthority {
+			return machinev1beta1.MachineAuthorityMachineAPI
+		},
+		ToCAPI: func(_ context.Context, mapiMHC *machinev1beta1.MachineHealthCheck) (*capiv1beta1.MachineHealthCheck, []string, error) {
+			return mapi2capi.FromMachineHealthCheckAndInfra(mapiMHC, r.Infra)
+		},
+		CompareCAPI: compareMachineHealthChecks,
+	}
+}
+
+// compareMachineHealthChecks returns the fields of the MachineHealthChecks which differ. Only the fields set by the
+// conversion are compared, the finalizers and owner references of the CAPI MachineHealthChecks are left to CAPI.
+func compareMachineHealthChecks(existing, converted *capiv1beta1.MachineHealthCheck) ([]string, error) {
+	return synccommon.ChangedFields(existing.Spec, converted.Spec,
+		metav1.ObjectMeta{Labels: existing.Labels, Annotations: existing.Annotations},
+		metav1.ObjectMeta{Labels: converted.Labels, Annotations: converted.Annotations},
+	), nil
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinehealthchecksync
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	configv1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/config/v1"
+	corev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/synccommon"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+)
+
+const (
+	mapiNamespace = "openshift-machine-api"
+	capiNamespace = "openshift-cluster-api"
+)
+
+// applyAsCreateOrUpdate stands in for server-side apply, which the fake client does not support, by creating the
+// applied object or replacing the existing one.
+func applyAsCreateOrUpdate(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch != client.Apply {
+		return c.Patch(ctx, obj, patch, opts...)
+	}
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GetObjectKind().GroupVersionKind())
+
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), existing); apierrors.IsNotFound(err) {
+		return c.Create(ctx, obj)
+	} else if err != nil {
+		return err
+	}
+
+	obj.SetResourceVersion(existing.GetResourceVersion())
+
+	return c.Update(ctx, obj)
+}
+
+var _ = Describe("MachineHealthCheck sync controller", func() {
+	var cl client.Client
+	var reconciler *MachineHealthCheckSyncReconciler
+
+	ctx := context.Background()
+
+	newMAPIMachineHealthCheck := func() *machinev1beta1.MachineHealthCheck {
+		return &machinev1beta1.MachineHealthCheck{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: mapiNamespace},
+			Spec: machinev1beta1.MachineHealthCheckSpec{
+				Selector: metav1.LabelSelector{MatchLabels: map[string]string{machinev1beta1.MachineClusterIDLabel: "test"}},
+				UnhealthyConditions: []machinev1beta1.UnhealthyCondition{{
+					Type:    corev1.NodeReady,
+					Status:  corev1.ConditionFalse,
+					Timeout: metav1.Duration{Duration: 5 * time.Minute},
+				}},
+			},
+		}
+	}
+
+	setUp := func(objs ...client.Object) {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(machinev1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(capiv1beta1.AddToScheme(scheme)).To(Succeed())
+
+		objs = append(objs, corev1resourcebuilder.Namespace().WithName(mapiNamespace).Build())
+		cl = fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).WithInterceptorFuncs(interceptor.Funcs{
+			Patch: applyAsCreateOrUpdate,
+		}).Build()
+
+		reconciler = &MachineHealthCheckSyncReconciler{
+			Mirror: synccommon.Mirror[*machinev1beta1.MachineHealthCheck, *capiv1beta1.MachineHealthCheck]{
+				Client:        cl,
+				MAPINamespace: mapiNamespace,
+				CAPINamespace: capiNamespace,
+				FieldOwner:    mirrorFieldOwner,
+				Backoff:       util.NewBackoff(util.DefaultBackoffConfig),
+			},
+			Infra: configv1resourcebuilder.Infrastructure().AsAWS("test", "eu-west-2").Build(),
+		}
+		reconciler.Hooks = reconciler.hooks()
+	}
+
+	reconcileMachineHealthCheck := func() reconcile.Result {
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKey{Namespace: mapiNamespace, Name: "worker"}})
+		Expect(err).ToNot(HaveOccurred())
+
+		return result
+	}
+
+	getCAPIMachineHealthCheck := func() *capiv1beta1.MachineHealthCheck {
+		capiMHC := &capiv1beta1.MachineHealthCheck{}
+		Expect(cl.Get(ctx, client.ObjectKey{Namespace: capiNamespace, Name: "worker"}, capiMHC)).To(Succeed())
+
+		return capiMHC
+	}
+
+	It("should create the CAPI mirror of the MAPI MachineHealthCheck", func() {
+		setUp(newMAPIMachineHealthCheck())
+
+		reconcileMachineHealthCheck()
+
+		capiMHC := getCAPIMachineHealthCheck()
+		Expect(capiMHC.Spec.ClusterName).To(Equal("test"))
+		Expect(capiMHC.Spec.Selector.MatchLabels).To(HaveKeyWithValue(machinev1beta1.MachineClusterIDLabel, "test"))
+		Expect(capiMHC.Spec.UnhealthyConditions).To(ConsistOf(HaveField("Type", corev1.NodeReady)))
+		Expect(capiMHC.Labels).To(HaveKeyWithValue(capiv1beta1.ClusterNameLabel, "test"))
+		Expect(capiMHC.Annotations).To(HaveKey(consts.LastSyncTimeAnnotation))
+	})
+
+	It("should update the CAPI mirror when the MAPI MachineHealthCheck changes", func() {
+		mapiMHC := newMAPIMachineHealthCheck()
+		setUp(mapiMHC)
+
+		reconcileMachineHealthCheck()
+
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(mapiMHC), mapiMHC)).To(Succeed())
+		mapiMHC.Spec.UnhealthyConditions[0].Timeout = metav1.Duration{Duration: 10 * time.Minute}
+		Expect(cl.Update(ctx, mapiMHC)).To(Succeed())
+
+		reconcileMachineHealthCheck()
+
+		Expect(getCAPIMachineHealthCheck().Spec.UnhealthyConditions).To(ConsistOf(
+			HaveField("Timeout", metav1.Duration{Duration: 10 * time.Minute}),
+		))
+	})
+
+	It("should not update an up to date CAPI mirror, whatever its finalizers", func() {
+		setUp(newMAPIMachineHealthCheck())
+
+		reconcileMachineHealthCheck()
+
+		capiMHC := getCAPIMachineHealthCheck()
+		capiMHC.SetFinalizers([]string{"foo.io/finalizer"})
+		Expect(cl.Update(ctx, capiMHC)).To(Succeed())
+
+		reconcileMachineHealthCheck()
+
+		Expect(getCAPIMachineHealthCheck().ResourceVersion).To(Equal(capiMHC.ResourceVersion))
+	})
+
+	It("should not mirror a MachineHealthCheck CAPI does not support", func() {
+		mapiMHC := newMAPIMachineHealthCheck()
+		mapiMHC.Spec.RemediationTemplate = &corev1.ObjectReference{Kind: "Metal3RemediationTemplate", Name: "foo"}
+		setUp(mapiMHC)
+
+		Expect(reconcileMachineHealthCheck()).To(Equal(reconcile.Result{}), "should not retry until the MachineHealthCheck changes")
+
+		err := cl.Get(ctx, client.ObjectKey{Namespace: capiNamespace, Name: "worker"}, &capiv1beta1.MachineHealthCheck{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
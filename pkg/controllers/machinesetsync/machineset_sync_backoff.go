@@ -0,0 +1,42 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinesetsync
+
+import (
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/synccommon"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// backoffFieldOwner owns the degraded condition. It differs from the owner of the synchronized condition,
+	// so that applying either condition does not remove the other one.
+	backoffFieldOwner = "machineset-sync-controller-backoff"
+)
+
+// statusReporter returns the reporter of the paused and degraded synchronizations on the MAPI machine sets.
+func (r *MachineSetSyncReconciler) statusReporter() synccommon.StatusReporter {
+	return synccommon.StatusReporter{
+		Client:            r.Client,
+		Recorder:          r.Recorder,
+		Backoff:           r.Backoff,
+		Namespace:         r.MAPINamespace,
+		NewObject:         func() client.Object { return &machinev1beta1.MachineSet{} },
+		PauseFieldOwner:   pauseFieldOwner,
+		BackoffFieldOwner: backoffFieldOwner,
+	}
+}
@@ -21,29 +21,37 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	configv1 "github.com/openshift/api/config/v1"
 	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
 	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/synccommon"
 	"github.com/openshift/cluster-capi-operator/pkg/conversion/capi2mapi"
 	"github.com/openshift/cluster-capi-operator/pkg/conversion/mapi2capi"
+	conversionutil "github.com/openshift/cluster-capi-operator/pkg/conversion/util"
+	"github.com/openshift/cluster-capi-operator/pkg/errorsx"
+	"github.com/openshift/cluster-capi-operator/pkg/tracing"
 	"github.com/openshift/cluster-capi-operator/pkg/util"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 
 	machinev1applyconfigs "github.com/openshift/client-go/machine/applyconfigurations/machine/v1beta1"
 	"k8s.io/client-go/tools/record"
 	awscapiv1beta1 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
 	capibmv1 "sigs.k8s.io/cluster-api-provider-ibmcloud/api/v1beta2"
+	capov1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1beta1"
 	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -64,6 +72,9 @@ var (
 
 	// errAssertingCAPIPowerVSMachineTemplate is returned when we encounter an issue asserting a client.Object into a IBMPowerVSMachineTemplate.
 	errAssertingCAPIIBMPowerVSMachineTemplate = errors.New("error asserting the CAPI IBMPowerVSMachineTemplate object")
+
+	// errAssertingCAPIOpenStackMachineTemplate is returned when we encounter an issue asserting a client.Object into a OpenStackMachineTemplate.
+	errAssertingCAPIOpenStackMachineTemplate = errors.New("error asserting the CAPI OpenStackMachineTemplate object")
 )
 
 const (
@@ -77,8 +88,19 @@ const (
 	reasonFailedToCreateCAPIInfraMachineTemplate = "FailedToCreateCAPIInfraMachineTemplate"
 	reasonFailedToGetCAPIMachineSet              = "FailedToGetCAPIMachineSet"
 	reasonResourceSynchronized                   = "ResourceSynchronized"
-
-	messageSuccessfullySynchronized = "Successfully synchronized CAPI MachineSet to MAPI"
+	reasonCreatedCAPIMachineSet                  = "CreatedCAPIMachineSet"
+	reasonUpdatedCAPIMachineSet                  = "UpdatedCAPIMachineSet"
+	reasonUpdatedMAPIMachineSet                  = "UpdatedMAPIMachineSet"
+	reasonCreatedCAPIInfraMachineTemplate        = "CreatedCAPIInfraMachineTemplate"
+	reasonUpdatedCAPIInfraMachineTemplate        = "UpdatedCAPIInfraMachineTemplate"
+	reasonCAPIMachineSetOwnedByMachineDeployment = "CAPIMachineSetOwnedByMachineDeployment"
+
+	messageSuccessfullySynchronized               = "Successfully synchronized CAPI MachineSet to MAPI"
+	messageCAPIMachineSetOwnedByMachineDeployment = "CAPI MachineSet is owned by the MachineDeployment %s, which has no Machine API equivalent. " +
+		"The MAPI MachineSet is a read-only mirror, its changes are not synchronized"
+
+	// mirrorFieldOwner owns the fields of the mirrored machine sets and infra machine templates set by the conversion.
+	mirrorFieldOwner = "machineset-sync-controller-mirror"
 )
 
 // MachineSetSyncReconciler reconciles CAPI and MAPI MachineSets.
@@ -91,6 +113,18 @@ type MachineSetSyncReconciler struct {
 	Platform      configv1.PlatformType
 	CAPINamespace string
 	MAPINamespace string
+
+	// MaxConcurrentReconciles is the maximum number of concurrent reconciles, defaults to 1.
+	MaxConcurrentReconciles int
+	// Shard restricts the reconciler to a subset of the resources, so that the work can be split across replicas.
+	Shard util.Shard
+	// Backoff delays the retries of the machine sets that failed to synchronize, defaults to util.DefaultBackoffConfig.
+	Backoff *util.Backoff
+	// DriftThreshold is how long the copies of a machine set may differ before it is reported as drifted,
+	// defaults to DefaultDriftThreshold.
+	DriftThreshold time.Duration
+
+	drift driftTracker
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -110,24 +144,51 @@ func (r *MachineSetSyncReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		r.MAPINamespace = consts.DefaultMAPIManagedNamespace
 	}
 
+	if r.Backoff == nil {
+		r.Backoff = util.NewBackoff(util.DefaultBackoffConfig)
+	}
+
+	if r.DriftThreshold == 0 {
+		r.DriftThreshold = DefaultDriftThreshold
+	}
+
 	if err := ctrl.NewControllerManagedBy(mgr).
-		For(&machinev1beta1.MachineSet{}, builder.WithPredicates(util.FilterNamespace(r.MAPINamespace))).
+		Named(util.NamespacedControllerName("machineset", r.MAPINamespace)).
+		For(&machinev1beta1.MachineSet{}, builder.WithPredicates(util.FilterNamespace(r.MAPINamespace), util.FilterShard(r.Shard))).
 		Watches(
 			&capiv1beta1.MachineSet{},
 			handler.EnqueueRequestsFromMapFunc(util.RewriteNamespace(r.MAPINamespace)),
-			builder.WithPredicates(util.FilterNamespace(r.CAPINamespace)),
+			builder.WithPredicates(util.FilterNamespace(r.CAPINamespace), util.FilterShard(r.Shard)),
 		).
 		Watches(
+			// The machines are mapped to the machine set controlling them, so that the failures to
+			// synchronize them are reported on it. The shard is checked in Reconcile.
+			&machinev1beta1.Machine{},
+			handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &machinev1beta1.MachineSet{}, handler.OnlyControllerOwner()),
+			builder.WithPredicates(util.FilterNamespace(r.MAPINamespace)),
+		).
+		Watches(
+			// The InfraMachineTemplate name differs from the MachineSet one,
+			// so the shard is checked in Reconcile rather than with a predicate.
 			infraMachineTemplate,
 			handler.EnqueueRequestsFromMapFunc(util.ResolveCAPIMachineSetFromObject(r.MAPINamespace)),
 			builder.WithPredicates(util.FilterNamespace(r.CAPINamespace)),
 		).
+		Watches(
+			&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(r.mapNamespaceToMachineSets),
+			builder.WithPredicates(util.FilterSyncPauseChanges(r.MAPINamespace)),
+		).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Complete(r); err != nil {
 		return fmt.Errorf("failed to create controller: %w", err)
 	}
 
-	// Set up API helpers from the manager.
-	r.Client = mgr.GetClient()
+	// Set up API helpers from the manager, the client may be set to rate limit the sync controllers apart.
+	if r.Client == nil {
+		r.Client = mgr.GetClient()
+	}
+
 	r.Scheme = mgr.GetScheme()
 	r.Recorder = mgr.GetEventRecorderFor("machineset-sync-controller")
 
@@ -139,10 +200,50 @@ func (r *MachineSetSyncReconciler) Reconcile(ctx context.Context, req reconcile.
 	logger := log.FromContext(ctx).WithValues("namespace", req.Namespace, "name", req.Name)
 	ctx = logr.NewContext(ctx, logger)
 
+	if !r.Shard.Owns(req.Name) {
+		// Requests mapped from InfraMachineTemplates and Machines are not filtered by the watch predicates.
+		return ctrl.Result{}, nil
+	}
+
 	logger.V(1).Info("Reconciling machine set")
 	defer logger.V(1).Info("Finished reconciling machine set")
 
+	paused, err := util.IsSyncPaused(ctx, r.Client, r.MAPINamespace)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to check whether the synchronization is paused: %w", err)
+	}
+
+	// The backoff is left untouched while paused, so that the retry budget of the machine sets is kept across the pause.
+	if err := r.statusReporter().SyncPausedCondition(ctx, req.Name, paused); err != nil {
+		return ctrl.Result{}, err
+	} else if paused {
+		logger.Info("Synchronization is paused, not reconciling machine set")
+		return ctrl.Result{}, nil
+	}
+
+	ctx, span := tracing.Start(ctx, "MachineSetSync", tracing.ObjectAttributes(req.Namespace, req.Name)...)
+	result, err := r.reconcile(ctx, req)
+	tracing.End(span, err)
+
+	// The drift is checked whether the synchronization succeeded or not, as a failing synchronization is
+	// the most likely reason for the copies to keep differing.
+	if requeueAfter, driftErr := r.syncDrift(ctx, req.Name); driftErr != nil {
+		logger.Error(driftErr, "Failed to check machine set drift")
+	} else if requeueAfter > 0 && (result.RequeueAfter == 0 || requeueAfter < result.RequeueAfter) {
+		result.RequeueAfter = requeueAfter
+	}
+
+	return r.statusReporter().ResultWithBackoff(ctx, req.Name, result, err)
+}
+
+// reconcile fetches the MAPI and CAPI machine sets and synchronizes them.
+func (r *MachineSetSyncReconciler) reconcile(ctx context.Context, req reconcile.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	_, span := tracing.Start(ctx, tracing.PhaseFetch)
 	mapiMachineSet, capiMachineSet, err := r.fetchMachineSets(ctx, req.Name)
+	tracing.End(span, err)
+
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to fetch machine sets: %w", err)
 	}
@@ -157,9 +258,64 @@ func (r *MachineSetSyncReconciler) Reconcile(ctx context.Context, req reconcile.
 		return ctrl.Result{}, nil
 	}
 
+	if capiMachineSet != nil && isOwnedByMachineDeployment(capiMachineSet) {
+		// MachineDeployments have no Machine API equivalent, so the MachineSets they own cannot be mirrored.
+		// Synchronizing the MAPI machine set into it would fight the MachineDeployment controller.
+		logger.Info("CAPI machine set is owned by a machine deployment, not synchronizing",
+			"machineDeployment", owningMachineDeployment(capiMachineSet))
+
+		return ctrl.Result{}, r.handOverToMachineDeployment(ctx, mapiMachineSet, capiMachineSet)
+	}
+
+	if capiMachineSet == nil && isHandedOverToMachineDeployment(mapiMachineSet) {
+		// The MachineDeployment deleted its machine set, e.g. when rolling out a new template.
+		// Recreating it from the read-only MAPI mirror would fight the MachineDeployment controller.
+		logger.Info("CAPI machine set owned by a machine deployment was deleted, not recreating it")
+
+		return ctrl.Result{}, nil
+	}
+
 	return r.syncMachineSets(ctx, mapiMachineSet, capiMachineSet)
 }
 
+// handOverToMachineDeployment marks the MAPI machine set as a read-only mirror of a CAPI MachineSet owned by a
+// MachineDeployment, through its Synchronized condition. An event is recorded when the machine set is handed over.
+func (r *MachineSetSyncReconciler) handOverToMachineDeployment(ctx context.Context, mapiMachineSet *machinev1beta1.MachineSet, capiMachineSet *capiv1beta1.MachineSet) error {
+	message := fmt.Sprintf(messageCAPIMachineSetOwnedByMachineDeployment, owningMachineDeployment(capiMachineSet))
+
+	if !isHandedOverToMachineDeployment(mapiMachineSet) {
+		r.recordSyncEvent(reasonCAPIMachineSetOwnedByMachineDeployment, message, mapiMachineSet, capiMachineSet)
+	}
+
+	return r.updateSynchronizedConditionWithPatch(ctx, mapiMachineSet, corev1.ConditionFalse,
+		reasonCAPIMachineSetOwnedByMachineDeployment, message, nil)
+}
+
+// isHandedOverToMachineDeployment returns whether the MAPI machine set was last seen mirroring a CAPI MachineSet owned
+// by a MachineDeployment. The condition outlives the CAPI MachineSet, so that it is not recreated once deleted.
+func isHandedOverToMachineDeployment(mapiMachineSet *machinev1beta1.MachineSet) bool {
+	condition := synccommon.FindCondition(mapiMachineSet.Status.Conditions, consts.SynchronizedCondition)
+
+	return condition != nil && condition.Reason == reasonCAPIMachineSetOwnedByMachineDeployment
+}
+
+// isOwnedByMachineDeployment returns whether the CAPI MachineSet is managed by a CAPI MachineDeployment.
+func isOwnedByMachineDeployment(capiMachineSet *capiv1beta1.MachineSet) bool {
+	return owningMachineDeployment(capiMachineSet) != ""
+}
+
+// owningMachineDeployment returns the name of the CAPI MachineDeployment owning the CAPI MachineSet, if any.
+func owningMachineDeployment(capiMachineSet *capiv1beta1.MachineSet) string {
+	for _, ref := range capiMachineSet.GetOwnerReferences() {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err == nil && gv.Group == capiv1beta1.GroupVersion.Group && ref.Kind == "MachineDeployment" {
+			return ref.Name
+		}
+	}
+
+	return ""
+}
+
 // fetchMachineSets fetches both MAPI and CAPI MachineSets.
 func (r *MachineSetSyncReconciler) fetchMachineSets(ctx context.Context, name string) (*machinev1beta1.MachineSet, *capiv1beta1.MachineSet, error) {
 	logger := log.FromContext(ctx)
@@ -209,15 +365,23 @@ func (r *MachineSetSyncReconciler) fetchCAPIInfraResources(ctx context.Context,
 	case configv1.PowerVSPlatformType:
 		infraCluster = &capibmv1.IBMPowerVSCluster{}
 		infraMachineTemplate = &capibmv1.IBMPowerVSMachineTemplate{}
+	case configv1.OpenStackPlatformType:
+		infraCluster = &capov1.OpenStackCluster{}
+		infraMachineTemplate = &capov1.OpenStackMachineTemplate{}
 	default:
 		return nil, nil, fmt.Errorf("%w: %s", errPlatformNotSupported, r.Platform)
 	}
 
-	if err := r.Get(ctx, infraClusterKey, infraCluster); err != nil {
+	// The infra resources may not be created yet, their absence is retried as an errorsx.ErrNotFoundCounterpart.
+	if err := r.Get(ctx, infraClusterKey, infraCluster); apierrors.IsNotFound(err) {
+		return nil, nil, errorsx.NotFoundCounterpart(fmt.Errorf("failed to get CAPI infrastructure cluster: %w", err))
+	} else if err != nil {
 		return nil, nil, fmt.Errorf("failed to get CAPI infrastructure cluster: %w", err)
 	}
 
-	if err := r.Get(ctx, infraMachineTemplateKey, infraMachineTemplate); err != nil {
+	if err := r.Get(ctx, infraMachineTemplateKey, infraMachineTemplate); apierrors.IsNotFound(err) {
+		return nil, nil, errorsx.NotFoundCounterpart(fmt.Errorf("failed to get CAPI infrastructure machine template: %w", err))
+	} else if err != nil {
 		return nil, nil, fmt.Errorf("failed to get CAPI infrastructure machine template: %w", err)
 	}
 
@@ -239,7 +403,7 @@ func (r *MachineSetSyncReconciler) syncMachineSets(ctx context.Context, mapiMach
 		return r.reconcileCAPIMachineSetToMAPIMachineSet(ctx, capiMachineSet, mapiMachineSet)
 	case authoritativeAPI == machinev1beta1.MachineAuthorityMigrating:
 		logger.Info("machine set is currently being migrated")
-		return ctrl.Result{}, nil
+		return ctrl.Result{}, r.syncScalingDuringMigration(ctx, mapiMachineSet, capiMachineSet)
 
 	default:
 		logger.Info("unexpected value for authoritativeAPI", "AuthoritativeAPI", mapiMachineSet.Status.AuthoritativeAPI)
@@ -248,11 +412,114 @@ func (r *MachineSetSyncReconciler) syncMachineSets(ctx context.Context, mapiMach
 	}
 }
 
+// PendingChanges describes the changes the reconciler would make to the non-authoritative copy of the given MachineSet pair,
+// without making them. An empty list means both copies are in sync.
+// The CAPI MachineSet may be nil when it does not exist yet.
+func (r *MachineSetSyncReconciler) PendingChanges(ctx context.Context, mapiMachineSet *machinev1beta1.MachineSet, capiMachineSet *capiv1beta1.MachineSet) ([]string, error) {
+	// The machine sets handed over to a MachineDeployment are not synchronized anymore.
+	if capiMachineSet != nil && isOwnedByMachineDeployment(capiMachineSet) || capiMachineSet == nil && isHandedOverToMachineDeployment(mapiMachineSet) {
+		return nil, nil
+	}
+
+	switch mapiMachineSet.Status.AuthoritativeAPI {
+	case machinev1beta1.MachineAuthorityMachineAPI:
+		return r.pendingCAPIMachineSetChanges(ctx, mapiMachineSet, capiMachineSet)
+	case machinev1beta1.MachineAuthorityClusterAPI:
+		if capiMachineSet == nil {
+			return r.pendingCAPIMachineSetChanges(ctx, mapiMachineSet, capiMachineSet)
+		}
+
+		return r.pendingMAPIMachineSetChanges(ctx, capiMachineSet, mapiMachineSet)
+	default:
+		// Nothing but the scaling is synchronized while migrating, and nothing when the authoritative API is unknown.
+		return nil, nil
+	}
+}
+
+// pendingCAPIMachineSetChanges compares the CAPI resources converted from the MAPI MachineSet with the existing ones,
+// in the same way reconcileMAPIMachineSetToCAPIMachineSet does.
+func (r *MachineSetSyncReconciler) pendingCAPIMachineSetChanges(ctx context.Context, mapiMachineSet *machinev1beta1.MachineSet, capiMachineSet *capiv1beta1.MachineSet) ([]string, error) {
+	changes := []string{}
+
+	newCAPIMachineSet, newCAPIInfraMachineTemplate, warns, err := r.convertMAPIToCAPIMachineSet(mapiMachineSet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert MAPI machine set to CAPI machine set: %w", err)
+	}
+
+	if err := synccommon.SetConversionLossAnnotation(newCAPIMachineSet, warns); err != nil {
+		return nil, err
+	}
+
+	if err := setInfraMachineTemplateName(mapiMachineSet, newCAPIMachineSet, newCAPIInfraMachineTemplate); err != nil {
+		return nil, fmt.Errorf("failed to name CAPI infra machine template: %w", err)
+	}
+
+	newCAPIMachineSet.SetNamespace(r.CAPINamespace)
+	newCAPIMachineSet.Spec.Template.Spec.InfrastructureRef.Namespace = r.CAPINamespace
+	newCAPIInfraMachineTemplate.SetNamespace(r.CAPINamespace)
+
+	if capiMachineSet != nil {
+		newCAPIMachineSet.Labels = conversionutil.MergeMAPIMachineLabels(capiMachineSet.Labels, newCAPIMachineSet.Labels)
+		newCAPIMachineSet.Spec.Template.Labels = conversionutil.MergeMAPIMachineLabels(capiMachineSet.Spec.Template.Labels, newCAPIMachineSet.Spec.Template.Labels)
+	}
+
+	_, infraMachineTemplate, err := r.fetchCAPIInfraResources(ctx, newCAPIMachineSet)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to fetch CAPI infra resources: %w", err)
+	}
+
+	if infraMachineTemplate == nil {
+		changes = append(changes, "create CAPI infrastructure machine template")
+	} else if changedFields, err := capiInfraMachineTemplateChangedFields(r.Platform, infraMachineTemplate, newCAPIInfraMachineTemplate); err != nil {
+		return nil, fmt.Errorf("failed to check CAPI infra machine template diff: %w", err)
+	} else if len(changedFields) > 0 {
+		changes = append(changes, fmt.Sprintf("update CAPI infrastructure machine template (%s)", strings.Join(changedFields, ", ")))
+	}
+
+	if capiMachineSet == nil {
+		changes = append(changes, "create CAPI machine set")
+	} else if changedFields := synccommon.ChangedFields(capiMachineSet.Spec, newCAPIMachineSet.Spec, capiMachineSet.ObjectMeta, newCAPIMachineSet.ObjectMeta); len(changedFields) > 0 {
+		changes = append(changes, fmt.Sprintf("update CAPI machine set (%s)", strings.Join(changedFields, ", ")))
+	}
+
+	return changes, nil
+}
+
+// pendingMAPIMachineSetChanges compares the MAPI MachineSet converted from the CAPI resources with the existing one,
+// in the same way reconcileCAPIMachineSetToMAPIMachineSet does.
+func (r *MachineSetSyncReconciler) pendingMAPIMachineSetChanges(ctx context.Context, capiMachineSet *capiv1beta1.MachineSet, mapiMachineSet *machinev1beta1.MachineSet) ([]string, error) {
+	infraCluster, infraMachineTemplate, err := r.fetchCAPIInfraResources(ctx, capiMachineSet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch CAPI infra resources: %w", err)
+	}
+
+	newMapiMachineSet, warns, err := r.convertCAPIToMAPIMachineSet(capiMachineSet, infraMachineTemplate, infraCluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert CAPI machine set to MAPI machine set: %w", err)
+	}
+
+	if err := synccommon.SetConversionLossAnnotation(newMapiMachineSet, warns); err != nil {
+		return nil, err
+	}
+
+	newMapiMachineSet.Spec.Template.Labels = util.MergeMaps(mapiMachineSet.Spec.Template.Labels, newMapiMachineSet.Spec.Template.Labels)
+	newMapiMachineSet.Labels = conversionutil.MergeMAPIMachineLabels(mapiMachineSet.Labels, newMapiMachineSet.Labels)
+
+	if changedFields := synccommon.ChangedFields(mapiMachineSet.Spec, newMapiMachineSet.Spec, mapiMachineSet.ObjectMeta, newMapiMachineSet.ObjectMeta); len(changedFields) > 0 {
+		return []string{fmt.Sprintf("update MAPI machine set (%s)", strings.Join(changedFields, ", "))}, nil
+	}
+
+	return []string{}, nil
+}
+
 // reconcileMAPIMachineSetToCAPIMachineSet reconciles a MAPI MachineSet to a CAPI MachineSet.
 func (r *MachineSetSyncReconciler) reconcileMAPIMachineSetToCAPIMachineSet(ctx context.Context, mapiMachineSet *machinev1beta1.MachineSet, capiMachineSet *capiv1beta1.MachineSet) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
+	_, span := tracing.Start(ctx, tracing.PhaseConvert)
 	newCAPIMachineSet, newCAPIInfraMachineTemplate, warns, err := r.convertMAPIToCAPIMachineSet(mapiMachineSet)
+	tracing.End(span, err)
+
 	if err != nil {
 		conversionErr := fmt.Errorf("failed to convert MAPI machine set to CAPI machine set: %w", err)
 		if condErr := r.updateSynchronizedConditionWithPatch(ctx, mapiMachineSet, corev1.ConditionFalse, reasonFailedToConvertMAPIMachineSetToCAPI, conversionErr.Error(), nil); condErr != nil {
@@ -267,11 +534,28 @@ func (r *MachineSetSyncReconciler) reconcileMAPIMachineSetToCAPIMachineSet(ctx c
 		r.Recorder.Event(mapiMachineSet, corev1.EventTypeWarning, "ConversionWarning", warning)
 	}
 
-	newCAPIMachineSet.SetResourceVersion(getResourceVersion(client.Object(capiMachineSet)))
+	if err := synccommon.SetConversionLossAnnotation(newCAPIMachineSet, warns); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := setInfraMachineTemplateName(mapiMachineSet, newCAPIMachineSet, newCAPIInfraMachineTemplate); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to name CAPI infra machine template: %w", err)
+	}
+
 	newCAPIMachineSet.SetNamespace(r.CAPINamespace)
 	newCAPIMachineSet.Spec.Template.Spec.InfrastructureRef.Namespace = r.CAPINamespace
 
+	// The machine.openshift.io labels of the existing CAPI machine set that MAPI does not set are kept, so that the
+	// tooling keyed on them keeps matching it. The authoritative MAPI labels take precedence on conflicts.
+	if capiMachineSet != nil {
+		newCAPIMachineSet.Labels = conversionutil.MergeMAPIMachineLabels(capiMachineSet.Labels, newCAPIMachineSet.Labels)
+		newCAPIMachineSet.Spec.Template.Labels = conversionutil.MergeMAPIMachineLabels(capiMachineSet.Spec.Template.Labels, newCAPIMachineSet.Spec.Template.Labels)
+	}
+
+	_, span = tracing.Start(ctx, tracing.PhaseFetch)
 	_, infraMachineTemplate, err := r.fetchCAPIInfraResources(ctx, newCAPIMachineSet)
+	tracing.End(span, client.IgnoreNotFound(err))
+
 	if err != nil && !apierrors.IsNotFound(err) {
 		fetchErr := fmt.Errorf("failed to fetch CAPI infra resources: %w", err)
 
@@ -283,19 +567,39 @@ func (r *MachineSetSyncReconciler) reconcileMAPIMachineSetToCAPIMachineSet(ctx c
 		return ctrl.Result{}, fetchErr
 	}
 
-	newCAPIInfraMachineTemplate.SetResourceVersion(getResourceVersion(infraMachineTemplate))
 	newCAPIInfraMachineTemplate.SetNamespace(r.CAPINamespace)
 
+	if err := r.ensureCAPIIdentitySecret(ctx, mapiMachineSet, newCAPIInfraMachineTemplate); err != nil {
+		identityErr := fmt.Errorf("failed to ensure CAPI identity secret: %w", err)
+
+		if condErr := r.updateSynchronizedConditionWithPatch(
+			ctx, mapiMachineSet, corev1.ConditionFalse, reasonFailedToEnsureCAPIIdentitySecret, identityErr.Error(), nil); condErr != nil {
+			return ctrl.Result{}, utilerrors.NewAggregate([]error{identityErr, condErr})
+		}
+
+		return ctrl.Result{}, identityErr
+	}
+
 	if result, err := r.createOrUpdateCAPIInfraMachineTemplate(ctx, mapiMachineSet, infraMachineTemplate, newCAPIInfraMachineTemplate); err != nil {
 		return result, fmt.Errorf("unable to ensure CAPI infra machine template: %w", err)
 	}
 
+	// The template referenced before the update identifies the legacy template of the machine set, if any.
+	previousName := ""
+	if capiMachineSet != nil {
+		previousName = capiMachineSet.Spec.Template.Spec.InfrastructureRef.Name
+	}
+
 	if result, err := r.createOrUpdateCAPIMachineSet(ctx, mapiMachineSet, capiMachineSet, newCAPIMachineSet); err != nil {
 		return result, fmt.Errorf("unable to ensure CAPI machine set: %w", err)
 	}
 
-	return ctrl.Result{}, r.updateSynchronizedConditionWithPatch(ctx, mapiMachineSet, corev1.ConditionTrue,
-		consts.ReasonResourceSynchronized, messageSuccessfullySynchronized, &mapiMachineSet.Generation)
+	// The previous templates are only deleted once the CAPI machine set no longer references them.
+	if err := r.deleteStaleInfraMachineTemplates(ctx, mapiMachineSet, newCAPIInfraMachineTemplate.GetName(), previousName); err != nil {
+		return ctrl.Result{}, fmt.Errorf("unable to delete stale CAPI infra machine templates: %w", err)
+	}
+
+	return ctrl.Result{}, r.updateSynchronizedConditionForMachines(ctx, mapiMachineSet, &mapiMachineSet.Generation)
 }
 
 // reconcileCAPIMachineSetToMAPIMachineSet reconciles a CAPI MachineSet to a
@@ -303,7 +607,10 @@ func (r *MachineSetSyncReconciler) reconcileMAPIMachineSetToCAPIMachineSet(ctx c
 func (r *MachineSetSyncReconciler) reconcileCAPIMachineSetToMAPIMachineSet(ctx context.Context, capiMachineSet *capiv1beta1.MachineSet, mapiMachineSet *machinev1beta1.MachineSet) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
+	_, span := tracing.Start(ctx, tracing.PhaseFetch)
 	infraCluster, infraMachineTemplate, err := r.fetchCAPIInfraResources(ctx, capiMachineSet)
+	tracing.End(span, err)
+
 	if err != nil {
 		fetchErr := fmt.Errorf("failed to fetch CAPI infra resources: %w", err)
 
@@ -315,7 +622,10 @@ func (r *MachineSetSyncReconciler) reconcileCAPIMachineSetToMAPIMachineSet(ctx c
 		return ctrl.Result{}, fetchErr
 	}
 
+	_, span = tracing.Start(ctx, tracing.PhaseConvert)
 	newMapiMachineSet, warns, err := r.convertCAPIToMAPIMachineSet(capiMachineSet, infraMachineTemplate, infraCluster)
+	tracing.End(span, err)
+
 	if err != nil {
 		conversionErr := fmt.Errorf("failed to convert CAPI machine set to MAPI machine set: %w", err)
 
@@ -332,16 +642,27 @@ func (r *MachineSetSyncReconciler) reconcileCAPIMachineSetToMAPIMachineSet(ctx c
 		r.Recorder.Event(mapiMachineSet, corev1.EventTypeWarning, "ConversionWarning", warning)
 	}
 
+	if err := synccommon.SetConversionLossAnnotation(newMapiMachineSet, warns); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	newMapiMachineSet.Spec.Template.Labels = util.MergeMaps(mapiMachineSet.Spec.Template.Labels, newMapiMachineSet.Spec.Template.Labels)
+	newMapiMachineSet.Labels = conversionutil.MergeMAPIMachineLabels(mapiMachineSet.Labels, newMapiMachineSet.Labels)
 
 	newMapiMachineSet.SetNamespace(mapiMachineSet.GetNamespace())
-	// The conversion does not set a resource version, so we must copy it over
-	newMapiMachineSet.SetResourceVersion(getResourceVersion(mapiMachineSet))
 
-	if !reflect.DeepEqual(newMapiMachineSet.Spec, mapiMachineSet.Spec) || !objectMetaIsEqual(newMapiMachineSet.ObjectMeta, mapiMachineSet.ObjectMeta) {
-		logger.Info("Updating MAPI machine set")
+	_, span = tracing.Start(ctx, tracing.PhaseDiff)
+	changedFields := synccommon.ChangedFields(mapiMachineSet.Spec, newMapiMachineSet.Spec, mapiMachineSet.ObjectMeta, newMapiMachineSet.ObjectMeta)
+	tracing.End(span, nil)
+
+	if len(changedFields) > 0 {
+		logger.Info("Updating MAPI machine set", "changedFields", changedFields)
 
-		if err := r.Update(ctx, newMapiMachineSet); err != nil {
+		if err := synccommon.SetLastSyncAnnotations(newMapiMachineSet); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		if err := tracing.Trace(ctx, tracing.PhaseApply, func(ctx context.Context) error { return r.applyMirror(ctx, newMapiMachineSet) }); err != nil {
 			logger.Error(err, "Failed to update MAPI machine set")
 
 			updateErr := fmt.Errorf("failed to update MAPI machine set: %w", err)
@@ -355,12 +676,14 @@ func (r *MachineSetSyncReconciler) reconcileCAPIMachineSetToMAPIMachineSet(ctx c
 		}
 
 		logger.Info("Successfully updated MAPI machine set")
+
+		r.recordSyncEvent(reasonUpdatedMAPIMachineSet, fmt.Sprintf("Updated MAPI machine set from CAPI, changed fields: %s", strings.Join(changedFields, ", ")),
+			mapiMachineSet, capiMachineSet)
 	} else {
 		logger.Info("No changes detected in MAPI machine set")
 	}
 
-	return ctrl.Result{}, r.updateSynchronizedConditionWithPatch(ctx, mapiMachineSet, corev1.ConditionTrue,
-		consts.ReasonResourceSynchronized, messageSuccessfullySynchronized, &capiMachineSet.Generation)
+	return ctrl.Result{}, r.updateSynchronizedConditionForMachines(ctx, mapiMachineSet, &capiMachineSet.Generation)
 }
 
 // convertCAPIToMAPIMachineSet converts a CAPI MachineSet to a MAPI MachineSet, selecting the correct converter based on the platform.
@@ -394,6 +717,20 @@ func (r *MachineSetSyncReconciler) convertCAPIToMAPIMachineSet(capiMachineSet *c
 		return capi2mapi.FromMachineSetAndPowerVSMachineTemplateAndPowerVSCluster( //nolint: wrapcheck
 			capiMachineSet, powerVSMachineTemplate, powerVSCluster,
 		).ToMachineSet()
+	case configv1.OpenStackPlatformType:
+		openStackMachineTemplate, ok := infraMachineTemplate.(*capov1.OpenStackMachineTemplate)
+		if !ok {
+			return nil, nil, fmt.Errorf("%w, expected OpenStackMachineTemplate, got %T", errUnexpectedInfraMachineTemplateType, infraMachineTemplate)
+		}
+
+		openStackCluster, ok := infraCluster.(*capov1.OpenStackCluster)
+		if !ok {
+			return nil, nil, fmt.Errorf("%w, expected OpenStackCluster, got %T", errUnexpectedInfraClusterType, infraCluster)
+		}
+
+		return capi2mapi.FromMachineSetAndOpenStackMachineTemplateAndOpenStackCluster( //nolint: wrapcheck
+			capiMachineSet, openStackMachineTemplate, openStackCluster,
+		).ToMachineSet()
 	default:
 		return nil, nil, fmt.Errorf("%w: %s", errPlatformNotSupported, r.Platform)
 	}
@@ -406,6 +743,8 @@ func (r *MachineSetSyncReconciler) convertMAPIToCAPIMachineSet(mapiMachineSet *m
 		return mapi2capi.FromAWSMachineSetAndInfra(mapiMachineSet, r.Infra).ToMachineSetAndMachineTemplate() //nolint:wrapcheck
 	case configv1.PowerVSPlatformType:
 		return mapi2capi.FromPowerVSMachineSetAndInfra(mapiMachineSet, r.Infra).ToMachineSetAndMachineTemplate() //nolint:wrapcheck
+	case configv1.OpenStackPlatformType:
+		return mapi2capi.FromOpenStackMachineSetAndInfra(mapiMachineSet, r.Infra).ToMachineSetAndMachineTemplate() //nolint:wrapcheck
 	default:
 		return nil, nil, nil, fmt.Errorf("%w: %s", errPlatformNotSupported, r.Platform)
 	}
@@ -429,7 +768,7 @@ func (r *MachineSetSyncReconciler) updateSynchronizedConditionWithPatch(ctx cont
 		WithMessage(message).
 		WithSeverity(severity)
 
-	setLastTransitionTime(consts.SynchronizedCondition, mapiMachineSet.Status.Conditions, conditionAc)
+	synccommon.SetLastTransitionTime(mapiMachineSet.Status.Conditions, conditionAc)
 
 	statusAc := machinev1applyconfigs.MachineSetStatus().
 		WithConditions(conditionAc)
@@ -453,7 +792,11 @@ func (r *MachineSetSyncReconciler) createOrUpdateCAPIInfraMachineTemplate(ctx co
 	logger := log.FromContext(ctx)
 
 	if infraMachineTemplate == nil {
-		if err := r.Create(ctx, newCAPIInfraMachineTemplate); err != nil {
+		if err := synccommon.SetLastSyncAnnotations(newCAPIInfraMachineTemplate); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		if err := tracing.Trace(ctx, tracing.PhaseApply, func(ctx context.Context) error { return r.applyMirror(ctx, newCAPIInfraMachineTemplate) }); err != nil {
 			logger.Error(err, "Failed to create CAPI infra machine template")
 			createErr := fmt.Errorf("failed to create CAPI infra machine template: %w", err)
 
@@ -466,10 +809,16 @@ func (r *MachineSetSyncReconciler) createOrUpdateCAPIInfraMachineTemplate(ctx co
 
 		logger.Info("Successfully created CAPI infra machine template")
 
+		r.recordSyncEvent(reasonCreatedCAPIInfraMachineTemplate, fmt.Sprintf("Created CAPI infra machine template %s", newCAPIInfraMachineTemplate.GetName()),
+			mapiMachineSet, newCAPIInfraMachineTemplate)
+
 		return ctrl.Result{}, nil
 	}
 
-	isEqualCAPIInfraMachineTemplate, err := capiInfraMachineTemplateIsEqual(r.Platform, infraMachineTemplate, newCAPIInfraMachineTemplate)
+	_, span := tracing.Start(ctx, tracing.PhaseDiff)
+	changedFields, err := capiInfraMachineTemplateChangedFields(r.Platform, infraMachineTemplate, newCAPIInfraMachineTemplate)
+	tracing.End(span, err)
+
 	if err != nil {
 		logger.Error(err, "Failed to check CAPI infra machine template diff")
 		updateErr := fmt.Errorf("failed to check CAPI infra machine template diff: %w", err)
@@ -482,14 +831,18 @@ func (r *MachineSetSyncReconciler) createOrUpdateCAPIInfraMachineTemplate(ctx co
 		return ctrl.Result{}, updateErr
 	}
 
-	if isEqualCAPIInfraMachineTemplate {
+	if len(changedFields) == 0 {
 		logger.Info("No changes detected in CAPI infra machine template")
 		return ctrl.Result{}, nil
 	}
 
-	logger.Info("Updating CAPI infra machine template")
+	logger.Info("Updating CAPI infra machine template", "changedFields", changedFields)
 
-	if err := r.Update(ctx, newCAPIInfraMachineTemplate); err != nil {
+	if err := synccommon.SetLastSyncAnnotations(newCAPIInfraMachineTemplate); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := tracing.Trace(ctx, tracing.PhaseApply, func(ctx context.Context) error { return r.applyMirror(ctx, newCAPIInfraMachineTemplate) }); err != nil {
 		logger.Error(err, "Failed to update CAPI infra machine template")
 
 		updateErr := fmt.Errorf("failed to update CAPI infra machine template: %w", err)
@@ -504,6 +857,10 @@ func (r *MachineSetSyncReconciler) createOrUpdateCAPIInfraMachineTemplate(ctx co
 
 	logger.Info("Successfully updated CAPI infra machine template")
 
+	r.recordSyncEvent(reasonUpdatedCAPIInfraMachineTemplate,
+		fmt.Sprintf("Updated CAPI infra machine template %s, changed fields: %s", newCAPIInfraMachineTemplate.GetName(), strings.Join(changedFields, ", ")),
+		mapiMachineSet, infraMachineTemplate)
+
 	return ctrl.Result{}, nil
 }
 
@@ -512,7 +869,11 @@ func (r *MachineSetSyncReconciler) createOrUpdateCAPIMachineSet(ctx context.Cont
 	logger := log.FromContext(ctx)
 
 	if capiMachineSet == nil {
-		if err := r.Create(ctx, newCAPIMachineSet); err != nil {
+		if err := synccommon.SetLastSyncAnnotations(newCAPIMachineSet); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		if err := tracing.Trace(ctx, tracing.PhaseApply, func(ctx context.Context) error { return r.applyMirror(ctx, newCAPIMachineSet) }); err != nil {
 			logger.Error(err, "Failed to create CAPI machine set")
 
 			createErr := fmt.Errorf("failed to create CAPI machine set: %w", err)
@@ -526,17 +887,27 @@ func (r *MachineSetSyncReconciler) createOrUpdateCAPIMachineSet(ctx context.Cont
 
 		logger.Info("Successfully created CAPI machine set")
 
+		r.recordSyncEvent(reasonCreatedCAPIMachineSet, "Created CAPI machine set from MAPI", mapiMachineSet, newCAPIMachineSet)
+
 		return ctrl.Result{}, nil
 	}
 
-	if reflect.DeepEqual(newCAPIMachineSet.Spec, capiMachineSet.Spec) && objectMetaIsEqual(newCAPIMachineSet.ObjectMeta, capiMachineSet.ObjectMeta) {
+	_, span := tracing.Start(ctx, tracing.PhaseDiff)
+	changedFields := synccommon.ChangedFields(capiMachineSet.Spec, newCAPIMachineSet.Spec, capiMachineSet.ObjectMeta, newCAPIMachineSet.ObjectMeta)
+	tracing.End(span, nil)
+
+	if len(changedFields) == 0 {
 		logger.Info("No changes detected in CAPI machine set")
 		return ctrl.Result{}, nil
 	}
 
-	logger.Info("Updating CAPI machine set")
+	logger.Info("Updating CAPI machine set", "changedFields", changedFields)
+
+	if err := synccommon.SetLastSyncAnnotations(newCAPIMachineSet); err != nil {
+		return ctrl.Result{}, err
+	}
 
-	if err := r.Update(ctx, newCAPIMachineSet); err != nil {
+	if err := tracing.Trace(ctx, tracing.PhaseApply, func(ctx context.Context) error { return r.applyMirror(ctx, newCAPIMachineSet) }); err != nil {
 		logger.Error(err, "Failed to update CAPI machine set")
 
 		updateErr := fmt.Errorf("failed to update CAPI machine set: %w", err)
@@ -550,6 +921,9 @@ func (r *MachineSetSyncReconciler) createOrUpdateCAPIMachineSet(ctx context.Cont
 
 	logger.Info("Successfully updated CAPI machine set")
 
+	r.recordSyncEvent(reasonUpdatedCAPIMachineSet, fmt.Sprintf("Updated CAPI machine set from MAPI, changed fields: %s", strings.Join(changedFields, ", ")),
+		mapiMachineSet, capiMachineSet)
+
 	return ctrl.Result{}, nil
 }
 
@@ -561,84 +935,70 @@ func getInfraMachineTemplateFromProvider(platform configv1.PlatformType) (client
 		return &awscapiv1beta1.AWSMachineTemplate{}, nil
 	case configv1.PowerVSPlatformType:
 		return &capibmv1.IBMPowerVSMachineTemplate{}, nil
+	case configv1.OpenStackPlatformType:
+		return &capov1.OpenStackMachineTemplate{}, nil
 	default:
 		return nil, fmt.Errorf("%w: %s", errPlatformNotSupported, platform)
 	}
 }
 
-// setLastTransitionTime determines if the last transition time should be set or updated for a given condition type.
-func setLastTransitionTime(condType machinev1beta1.ConditionType, conditions []machinev1beta1.Condition, conditionAc *machinev1applyconfigs.ConditionApplyConfiguration) {
-	for _, condition := range conditions {
-		if condition.Type == condType {
-			if !hasSameState(&condition, conditionAc) {
-				conditionAc.WithLastTransitionTime(metav1.Now())
-
-				return
-			}
-
-			conditionAc.WithLastTransitionTime(condition.LastTransitionTime)
-
-			return
-		}
-	}
-	// Condition does not exist; set the transition time
-	conditionAc.WithLastTransitionTime(metav1.Now())
-}
-
-// hasSameState returns true if a condition has the same state as a condition
-// apply config; state is defined by the union of following fields: Type,
-// Status.
-func hasSameState(i *machinev1beta1.Condition, j *machinev1applyconfigs.ConditionApplyConfiguration) bool {
-	return i.Type == *j.Type &&
-		i.Status == *j.Status
-}
-
-// objectMetaIsEqual determines if the two ObjectMeta are equal for the fields we care about
-// when synchronising MAPI and CAPI MachineSets.
-func objectMetaIsEqual(a, b metav1.ObjectMeta) bool {
-	return reflect.DeepEqual(a.Labels, b.Labels) &&
-		reflect.DeepEqual(a.Annotations, b.Annotations) &&
-		reflect.DeepEqual(a.Finalizers, b.Finalizers) &&
-		reflect.DeepEqual(a.OwnerReferences, b.OwnerReferences)
-}
-
-// capiInfraMachineTemplateIsEqual checks whether the provided CAPI infra machine templates are equal.
-func capiInfraMachineTemplateIsEqual(platform configv1.PlatformType, infraMachineTemplate1, infraMachineTemplate2 client.Object) (bool, error) {
+// capiInfraMachineTemplateChangedFields returns the fields that differ between the provided CAPI infra machine templates.
+func capiInfraMachineTemplateChangedFields(platform configv1.PlatformType, infraMachineTemplate1, infraMachineTemplate2 client.Object) ([]string, error) {
 	switch platform {
 	case configv1.AWSPlatformType:
 		typedInfraMachineTemplate1, ok := infraMachineTemplate1.(*awscapiv1beta1.AWSMachineTemplate)
 		if !ok {
-			return false, errAssertingCAPIAWSMachineTemplate
+			return nil, errAssertingCAPIAWSMachineTemplate
 		}
 
 		typedinfraMachineTemplate2, ok := infraMachineTemplate2.(*awscapiv1beta1.AWSMachineTemplate)
 		if !ok {
-			return false, errAssertingCAPIAWSMachineTemplate
+			return nil, errAssertingCAPIAWSMachineTemplate
 		}
 
-		return reflect.DeepEqual(typedInfraMachineTemplate1.Spec, typedinfraMachineTemplate2.Spec) && objectMetaIsEqual(typedInfraMachineTemplate1.ObjectMeta, typedinfraMachineTemplate2.ObjectMeta), nil
+		return synccommon.ChangedFields(typedInfraMachineTemplate1.Spec, typedinfraMachineTemplate2.Spec, typedInfraMachineTemplate1.ObjectMeta, typedinfraMachineTemplate2.ObjectMeta), nil
 	case configv1.PowerVSPlatformType:
 		typedInfraMachineTemplate1, ok := infraMachineTemplate1.(*capibmv1.IBMPowerVSMachineTemplate)
 		if !ok {
-			return false, errAssertingCAPIIBMPowerVSMachineTemplate
+			return nil, errAssertingCAPIIBMPowerVSMachineTemplate
 		}
 
 		typedinfraMachineTemplate2, ok := infraMachineTemplate2.(*capibmv1.IBMPowerVSMachineTemplate)
 		if !ok {
-			return false, errAssertingCAPIIBMPowerVSMachineTemplate
+			return nil, errAssertingCAPIIBMPowerVSMachineTemplate
 		}
 
-		return reflect.DeepEqual(typedInfraMachineTemplate1.Spec, typedinfraMachineTemplate2.Spec) && objectMetaIsEqual(typedInfraMachineTemplate1.ObjectMeta, typedinfraMachineTemplate2.ObjectMeta), nil
+		return synccommon.ChangedFields(typedInfraMachineTemplate1.Spec, typedinfraMachineTemplate2.Spec, typedInfraMachineTemplate1.ObjectMeta, typedinfraMachineTemplate2.ObjectMeta), nil
+	case configv1.OpenStackPlatformType:
+		typedInfraMachineTemplate1, ok := infraMachineTemplate1.(*capov1.OpenStackMachineTemplate)
+		if !ok {
+			return nil, errAssertingCAPIOpenStackMachineTemplate
+		}
+
+		typedinfraMachineTemplate2, ok := infraMachineTemplate2.(*capov1.OpenStackMachineTemplate)
+		if !ok {
+			return nil, errAssertingCAPIOpenStackMachineTemplate
+		}
+
+		return synccommon.ChangedFields(typedInfraMachineTemplate1.Spec, typedinfraMachineTemplate2.Spec, typedInfraMachineTemplate1.ObjectMeta, typedinfraMachineTemplate2.ObjectMeta), nil
 	default:
-		return false, fmt.Errorf("%w: %s", errPlatformNotSupported, platform)
+		return nil, fmt.Errorf("%w: %s", errPlatformNotSupported, platform)
 	}
 }
 
-// getResourceVersion returns the object ResourceVersion or the zero value for it.
-func getResourceVersion(obj client.Object) string {
-	if obj == nil || reflect.ValueOf(obj).IsNil() {
-		return "0"
+// recordSyncEvent records an event describing a synchronization decision on each of the given objects.
+func (r *MachineSetSyncReconciler) recordSyncEvent(reason, message string, objs ...runtime.Object) {
+	for _, obj := range objs {
+		if obj == nil || reflect.ValueOf(obj).IsNil() {
+			continue
+		}
+
+		r.Recorder.Event(obj, corev1.EventTypeNormal, reason, message)
 	}
+}
 
-	return obj.GetResourceVersion()
+// applyMirror creates or updates a mirror with server-side apply, so that only the fields set by the conversion
+// are owned by the controller, and the fields set on the mirror by other controllers are kept.
+func (r *MachineSetSyncReconciler) applyMirror(ctx context.Context, mirror client.Object) error {
+	return synccommon.ApplyMirror(ctx, r.Client, mirror, mirrorFieldOwner) //nolint:wrapcheck
 }
@@ -18,6 +18,8 @@ package machinesetsync
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -30,6 +32,7 @@ import (
 	corev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/core/v1"
 	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	capav1 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
 
@@ -37,8 +40,10 @@ import (
 
 	"github.com/openshift/cluster-api-actuator-pkg/testutils"
 	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/config"
 	"sigs.k8s.io/controller-runtime/pkg/envtest/komega"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -188,6 +193,38 @@ var _ = Describe("With a running MachineSetSync controller", func() {
 			Expect(k8sClient.Create(ctx, capaMachineTemplate)).To(Succeed(), "capa machine template should be able to be created")
 		})
 
+		Context("when the CAPI machine set was written by the updates of a previous version of the controller", func() {
+			BeforeEach(func() {
+				By("Creating the CAPI machine set with a label the MAPI machine set does not have, as the former field manager")
+				capiMachineSet = capiMachineSetBuilder.WithLabels(map[string]string{"removed-label": "true"}).Build()
+				Expect(k8sClient.Create(ctx, capiMachineSet, client.FieldOwner("machine-api-migration"))).Should(Succeed())
+
+				By("Creating the MAPI machine set")
+				mapiMachineSet = mapiMachineSetBuilder.Build()
+				Expect(k8sClient.Create(ctx, mapiMachineSet)).Should(Succeed())
+
+				By("Setting the MAPI machine set AuthoritativeAPI to MachineAPI")
+				Eventually(k.UpdateStatus(mapiMachineSet, func() {
+					mapiMachineSet.Status.AuthoritativeAPI = machinev1beta1.MachineAuthorityMachineAPI
+				})).Should(Succeed())
+			})
+
+			It("should remove the label from the CAPI machine set", func() {
+				Eventually(k.Object(capiMachineSet), timeout).Should(
+					HaveField("ObjectMeta.Labels", Not(HaveKey("removed-label"))),
+				)
+			})
+
+			It("should hand the fields of the former field manager over to the mirror field manager", func() {
+				Eventually(k.Object(capiMachineSet), timeout).Should(
+					HaveField("ObjectMeta.ManagedFields", SatisfyAll(
+						Not(ContainElement(HaveField("Manager", Equal("machine-api-migration")))),
+						ContainElement(HaveField("Manager", Equal(mirrorFieldOwner))),
+					)),
+				)
+			})
+		})
+
 		Context("when the MAPI machine set has MachineAuthority set to Machine API", func() {
 			BeforeEach(func() {
 				By("Creating the MAPI machine set")
@@ -207,6 +244,17 @@ var _ = Describe("With a running MachineSetSync controller", func() {
 					)).Should(Succeed())
 				})
 
+				It("should record the last sync on the CAPI machine set", func() {
+					Eventually(k.Object(
+						capiv1resourcebuilder.MachineSet().WithName(mapiMachineSet.Name).WithNamespace(capiNamespace.Name).Build(),
+					), timeout).Should(
+						HaveField("ObjectMeta.Annotations", SatisfyAll(
+							HaveKey(consts.LastSyncTimeAnnotation),
+							HaveKey(consts.LastSyncHashAnnotation),
+						)),
+					)
+				})
+
 				It("should update the synchronized condition on the MAPI machine set to True", func() {
 					Eventually(k.Object(mapiMachineSet), timeout).Should(
 						HaveField("Status.Conditions", ContainElement(
@@ -220,6 +268,32 @@ var _ = Describe("With a running MachineSetSync controller", func() {
 				})
 			})
 
+			Context("when the CAPI machine set has MAPI machine labels the MAPI machine set does not", func() {
+				BeforeEach(func() {
+					By("Creating the CAPI machine set with MAPI machine labels")
+					capiMachineSet = capiMachineSetBuilder.WithLabels(map[string]string{
+						"machine.openshift.io/cluster-api-cluster": "stale",
+						"machine.openshift.io/region":              "us-east-1",
+					}).Build()
+					Expect(k8sClient.Create(ctx, capiMachineSet)).Should(Succeed())
+				})
+
+				It("should keep the MAPI machine labels, unless the MAPI machine set sets or back-fills them", func() {
+					Eventually(k.Object(capiMachineSet), timeout).Should(
+						HaveField("ObjectMeta.Labels", SatisfyAll(
+							HaveKeyWithValue("machine.openshift.io/cluster-api-cluster", "cluster-foo"),
+							HaveKeyWithValue("machine.openshift.io/region", "us-east-1"),
+						)),
+					)
+				})
+
+				It("should back-fill the MAPI machine set label of the CAPI machine set template", func() {
+					Eventually(k.Object(capiMachineSet), timeout).Should(
+						HaveField("Spec.Template.ObjectMeta.Labels", HaveKeyWithValue("machine.openshift.io/cluster-api-machineset", mapiMachineSet.Name)),
+					)
+				})
+			})
+
 			Context("when the CAPI machine set does exist", func() {
 				BeforeEach(func() {
 					capiMachineSet = capiMachineSetBuilder.Build()
@@ -240,6 +314,128 @@ var _ = Describe("With a running MachineSetSync controller", func() {
 			})
 		})
 
+		Context("when the synchronization is paused", func() {
+			BeforeEach(func() {
+				By("Pausing the synchronization of the MAPI namespace")
+				Eventually(k.Update(mapiNamespace, func() {
+					mapiNamespace.SetAnnotations(map[string]string{util.SyncPausedAnnotation: "true"})
+				})).Should(Succeed())
+
+				// Wait for the controller cache to see the pause, so that the machine set is never synchronized.
+				Eventually(komega.New(mgr.GetClient()).Object(mapiNamespace), timeout).Should(
+					HaveField("ObjectMeta.Annotations", HaveKeyWithValue(util.SyncPausedAnnotation, "true")),
+				)
+
+				By("Creating the MAPI machine set")
+				mapiMachineSet = mapiMachineSetBuilder.Build()
+				Expect(k8sClient.Create(ctx, mapiMachineSet)).Should(Succeed())
+
+				Eventually(k.UpdateStatus(mapiMachineSet, func() {
+					mapiMachineSet.Status.AuthoritativeAPI = machinev1beta1.MachineAuthorityMachineAPI
+				})).Should(Succeed())
+			})
+
+			It("should set the paused condition on the MAPI machine set and not create the CAPI machine set", func() {
+				Eventually(k.Object(mapiMachineSet), timeout).Should(
+					HaveField("Status.Conditions", ContainElement(SatisfyAll(
+						HaveField("Type", Equal(consts.PausedCondition)),
+						HaveField("Status", Equal(corev1.ConditionTrue)),
+						HaveField("Reason", Equal(consts.ReasonSyncPaused)),
+					))),
+				)
+
+				Consistently(k.Get(
+					capiv1resourcebuilder.MachineSet().WithName(mapiMachineSet.Name).WithNamespace(capiNamespace.Name).Build(),
+				), time.Second).ShouldNot(Succeed())
+			})
+
+			It("should synchronize the machine set once resumed", func() {
+				Eventually(k.Update(mapiNamespace, func() {
+					mapiNamespace.SetAnnotations(nil)
+				})).Should(Succeed())
+
+				Eventually(k.Get(
+					capiv1resourcebuilder.MachineSet().WithName(mapiMachineSet.Name).WithNamespace(capiNamespace.Name).Build(),
+				), timeout).Should(Succeed())
+
+				Eventually(k.Object(mapiMachineSet), timeout).Should(
+					HaveField("Status.Conditions", ContainElement(SatisfyAll(
+						HaveField("Type", Equal(consts.PausedCondition)),
+						HaveField("Status", Equal(corev1.ConditionFalse)),
+						HaveField("Reason", Equal(consts.ReasonSyncResumed)),
+					))),
+				)
+			})
+		})
+
+		Context("when machines of the MAPI machine set failed to synchronize", func() {
+			BeforeEach(func() {
+				By("Creating the MAPI machine set")
+				mapiMachineSet = mapiMachineSetBuilder.Build()
+				Expect(k8sClient.Create(ctx, mapiMachineSet)).Should(Succeed())
+
+				By("Creating machines controlled by the MAPI machine set")
+				for _, name := range []string{"foo-a", "foo-b"} {
+					mapiMachine := machinev1resourcebuilder.Machine().
+						WithNamespace(mapiNamespace.GetName()).
+						WithName(name).
+						WithOwnerReferences([]metav1.OwnerReference{*metav1.NewControllerRef(mapiMachineSet, machinev1beta1.GroupVersion.WithKind("MachineSet"))}).
+						Build()
+					Expect(k8sClient.Create(ctx, mapiMachine)).Should(Succeed())
+
+					Eventually(k.UpdateStatus(mapiMachine, func() {
+						mapiMachine.Status.Conditions = []machinev1beta1.Condition{{
+							Type:               consts.SynchronizedCondition,
+							Status:             corev1.ConditionFalse,
+							Severity:           machinev1beta1.ConditionSeverityError,
+							Reason:             "FailedToConvertMAPIMachineToCAPI",
+							Message:            "spec.providerSpec.value.instanceType: Required value",
+							LastTransitionTime: metav1.Now(),
+						}}
+					})).Should(Succeed())
+				}
+
+				By("Setting the MAPI machine set AuthoritativeAPI to MachineAPI")
+				Eventually(k.UpdateStatus(mapiMachineSet, func() {
+					mapiMachineSet.Status.AuthoritativeAPI = machinev1beta1.MachineAuthorityMachineAPI
+				})).Should(Succeed())
+			})
+
+			It("should list the failing machines in the synchronized condition on the MAPI machine set", func() {
+				Eventually(k.Object(mapiMachineSet), timeout).Should(
+					HaveField("Status.Conditions", ContainElement(
+						SatisfyAll(
+							HaveField("Type", Equal(consts.SynchronizedCondition)),
+							HaveField("Status", Equal(corev1.ConditionFalse)),
+							HaveField("Reason", Equal("MachinesNotSynchronized")),
+							HaveField("Message", Equal("2 machine(s) failed to synchronize: "+
+								"foo-a: spec.providerSpec.value.instanceType: Required value; "+
+								"foo-b: spec.providerSpec.value.instanceType: Required value")),
+						))),
+				)
+			})
+
+			It("should report the machine set as synchronized once the machines are synchronized", func() {
+				for _, name := range []string{"foo-a", "foo-b"} {
+					mapiMachine := machinev1resourcebuilder.Machine().WithNamespace(mapiNamespace.GetName()).WithName(name).Build()
+					Eventually(k.UpdateStatus(mapiMachine, func() {
+						mapiMachine.Status.Conditions[0].Status = corev1.ConditionTrue
+						mapiMachine.Status.Conditions[0].Severity = machinev1beta1.ConditionSeverityNone
+						mapiMachine.Status.Conditions[0].Reason = consts.ReasonResourceSynchronized
+						mapiMachine.Status.Conditions[0].Message = ""
+					})).Should(Succeed())
+				}
+
+				Eventually(k.Object(mapiMachineSet), timeout).Should(
+					HaveField("Status.Conditions", ContainElement(
+						SatisfyAll(
+							HaveField("Type", Equal(consts.SynchronizedCondition)),
+							HaveField("Status", Equal(corev1.ConditionTrue)),
+						))),
+				)
+			})
+		})
+
 		Context("when the MAPI machine set has MachineAuthority set to Cluster API", func() {
 			BeforeEach(func() {
 				By("Creating the MAPI machine set")
@@ -298,14 +494,43 @@ var _ = Describe("With a running MachineSetSync controller", func() {
 							))
 					})
 
-					It("should update the labels", func() {
+					It("should update the labels, back-filling the MAPI cluster label", func() {
 						Eventually(k.Object(mapiMachineSet), timeout).Should(
-							HaveField("Labels", Equal(map[string]string{"foo": "bar"})),
+							HaveField("Labels", Equal(map[string]string{
+								"foo": "bar",
+								"machine.openshift.io/cluster-api-cluster": "cluster-foo",
+							})),
 						)
 					})
 
 				})
 
+				Context("where the MAPI machine set has MAPI machine labels the CAPI machine set does not", func() {
+					BeforeEach(func() {
+						By("Adding MAPI machine labels to the MAPI machine set")
+						Eventually(k.Update(mapiMachineSet, func() {
+							mapiMachineSet.Labels = map[string]string{
+								"machine.openshift.io/cluster-api-cluster": "stale",
+								"machine.openshift.io/region":              "us-east-1",
+							}
+						})).Should(Succeed())
+
+						By("Creating the CAPI machine set with other labels")
+						capiMachineSet = capiMachineSetBuilder.WithLabels(map[string]string{"foo": "bar"}).Build()
+						Expect(k8sClient.Create(ctx, capiMachineSet)).Should(Succeed())
+					})
+
+					It("should keep the MAPI machine labels, unless the CAPI machine set sets them", func() {
+						Eventually(k.Object(mapiMachineSet), timeout).Should(
+							HaveField("Labels", Equal(map[string]string{
+								"foo": "bar",
+								"machine.openshift.io/cluster-api-cluster": "cluster-foo",
+								"machine.openshift.io/region":              "us-east-1",
+							})),
+						)
+					})
+				})
+
 				Context("where the field is not meant to be copied", func() {
 					BeforeEach(func() {
 						By("Creating the CAPI machine set with differing object meta in non relevant field")
@@ -390,9 +615,10 @@ var _ = Describe("With a running MachineSetSync controller", func() {
 			BeforeEach(func() {
 				By("Creating the CAPI and MAPI machine sets")
 				// We want a difference, so if we try to reconcile either way we
-				// will get a new resourceversion
-				mapiMachineSet = mapiMachineSetBuilder.WithReplicas(6).Build()
-				capiMachineSet = capiMachineSetBuilder.WithReplicas(9).Build()
+				// will get a new resourceversion. The scaling is carried over
+				// while migrating, so the difference must not be the replicas.
+				mapiMachineSet = mapiMachineSetBuilder.WithReplicas(6).WithLabels(map[string]string{"foo": "bar"}).Build()
+				capiMachineSet = capiMachineSetBuilder.WithReplicas(6).WithLabels(map[string]string{"foo": "baz"}).Build()
 
 				Expect(k8sClient.Create(ctx, capiMachineSet)).Should(Succeed())
 				Expect(k8sClient.Create(ctx, mapiMachineSet)).Should(Succeed())
@@ -415,6 +641,92 @@ var _ = Describe("With a running MachineSetSync controller", func() {
 			})
 		})
 
+		Context("when the machine sets are scaled during a migration to Cluster API", func() {
+			BeforeEach(func() {
+				By("Creating the CAPI and MAPI machine sets")
+				mapiMachineSet = mapiMachineSetBuilder.WithReplicas(3).WithAuthoritativeAPI(machinev1beta1.MachineAuthorityClusterAPI).Build()
+				capiMachineSet = capiMachineSetBuilder.WithReplicas(3).WithAnnotations(map[string]string{
+					capiv1beta1.AutoscalerMinSizeAnnotation: "1",
+				}).Build()
+
+				Expect(k8sClient.Create(ctx, capiMachineSet)).Should(Succeed())
+				Expect(k8sClient.Create(ctx, mapiMachineSet)).Should(Succeed())
+
+				By("Setting the AuthoritativeAPI to Migrating")
+				Eventually(k.UpdateStatus(mapiMachineSet, func() {
+					mapiMachineSet.Status.AuthoritativeAPI = machinev1beta1.MachineAuthorityMigrating
+				})).Should(Succeed())
+
+				By("Scaling the MAPI machine set, as the cluster autoscaler would")
+				Eventually(k.Update(mapiMachineSet, func() {
+					mapiMachineSet.Spec.Replicas = ptr.To(int32(5))
+					mapiMachineSet.Annotations = map[string]string{
+						"machine.openshift.io/cluster-api-autoscaler-node-group-min-size": "2",
+						"machine.openshift.io/cluster-api-autoscaler-node-group-max-size": "8",
+					}
+				})).Should(Succeed())
+			})
+
+			It("should carry the scaling over to the CAPI machine set", func() {
+				Eventually(k.Object(capiMachineSet), timeout).Should(SatisfyAll(
+					HaveField("Spec.Replicas", Equal(ptr.To(int32(5)))),
+					HaveField("ObjectMeta.Annotations", SatisfyAll(
+						HaveKeyWithValue(capiv1beta1.AutoscalerMinSizeAnnotation, "2"),
+						HaveKeyWithValue(capiv1beta1.AutoscalerMaxSizeAnnotation, "8"),
+					)),
+				))
+			})
+
+			It("should not revert the scaling once Cluster API is authoritative", func() {
+				Eventually(k.Object(capiMachineSet), timeout).Should(HaveField("Spec.Replicas", Equal(ptr.To(int32(5)))))
+
+				By("Completing the migration")
+				Eventually(k.UpdateStatus(mapiMachineSet, func() {
+					mapiMachineSet.Status.AuthoritativeAPI = machinev1beta1.MachineAuthorityClusterAPI
+				})).Should(Succeed())
+
+				Eventually(k.Object(mapiMachineSet), timeout).Should(HaveField("Status.Conditions", ContainElement(SatisfyAll(
+					HaveField("Type", Equal(consts.SynchronizedCondition)),
+					HaveField("Status", Equal(corev1.ConditionTrue)),
+				))))
+				Consistently(k.Object(mapiMachineSet), timeout).Should(SatisfyAll(
+					HaveField("Spec.Replicas", Equal(ptr.To(int32(5)))),
+					HaveField("ObjectMeta.Annotations", HaveKeyWithValue("machine.openshift.io/cluster-api-autoscaler-node-group-max-size", "8")),
+				))
+			})
+		})
+
+		Context("when the machine sets are scaled during a migration to Machine API", func() {
+			BeforeEach(func() {
+				By("Creating the CAPI and MAPI machine sets")
+				mapiMachineSet = mapiMachineSetBuilder.WithReplicas(3).WithAuthoritativeAPI(machinev1beta1.MachineAuthorityMachineAPI).Build()
+				capiMachineSet = capiMachineSetBuilder.WithReplicas(3).Build()
+
+				Expect(k8sClient.Create(ctx, capiMachineSet)).Should(Succeed())
+				Expect(k8sClient.Create(ctx, mapiMachineSet)).Should(Succeed())
+
+				By("Setting the AuthoritativeAPI to Migrating")
+				Eventually(k.UpdateStatus(mapiMachineSet, func() {
+					mapiMachineSet.Status.AuthoritativeAPI = machinev1beta1.MachineAuthorityMigrating
+				})).Should(Succeed())
+
+				By("Scaling the CAPI machine set, as the cluster autoscaler would")
+				Eventually(k.Update(capiMachineSet, func() {
+					capiMachineSet.Spec.Replicas = ptr.To(int32(1))
+					capiMachineSet.Annotations = map[string]string{
+						"capacity.cluster-autoscaler.kubernetes.io/memory": "8Gi",
+					}
+				})).Should(Succeed())
+			})
+
+			It("should carry the scaling over to the MAPI machine set", func() {
+				Eventually(k.Object(mapiMachineSet), timeout).Should(SatisfyAll(
+					HaveField("Spec.Replicas", Equal(ptr.To(int32(1)))),
+					HaveField("ObjectMeta.Annotations", HaveKeyWithValue("machine.openshift.io/memoryMb", "8192")),
+				))
+			})
+		})
+
 		Context("when the MAPI machine set has MachineAuthority not set", func() {
 			BeforeEach(func() {
 				By("Creating the CAPI and MAPI MachineSets")
@@ -438,6 +750,67 @@ var _ = Describe("With a running MachineSetSync controller", func() {
 			})
 		})
 
+		Context("when the CAPI machine set is owned by a MachineDeployment", func() {
+			BeforeEach(func() {
+				By("Creating the CAPI and MAPI machine sets")
+				mapiMachineSet = mapiMachineSetBuilder.WithReplicas(6).Build()
+				capiMachineSet = capiMachineSetBuilder.WithReplicas(9).Build()
+				capiMachineSet.OwnerReferences = []metav1.OwnerReference{{
+					APIVersion: capiv1beta1.GroupVersion.String(),
+					Kind:       "MachineDeployment",
+					Name:       "foo",
+					UID:        "foo-uid",
+				}}
+
+				Expect(k8sClient.Create(ctx, capiMachineSet)).Should(Succeed())
+				Expect(k8sClient.Create(ctx, mapiMachineSet)).Should(Succeed())
+
+				By("Setting the MAPI machine set AuthoritativeAPI to MachineAPI")
+				Eventually(k.UpdateStatus(mapiMachineSet, func() {
+					mapiMachineSet.Status.AuthoritativeAPI = machinev1beta1.MachineAuthorityMachineAPI
+				})).Should(Succeed())
+			})
+
+			It("should update the synchronized condition on the MAPI machine set to False", func() {
+				Eventually(k.Object(mapiMachineSet), timeout).Should(
+					HaveField("Status.Conditions", ContainElement(
+						SatisfyAll(
+							HaveField("Type", Equal(consts.SynchronizedCondition)),
+							HaveField("Status", Equal(corev1.ConditionFalse)),
+							HaveField("Reason", Equal("CAPIMachineSetOwnedByMachineDeployment")),
+							HaveField("Message", SatisfyAll(ContainSubstring("MachineDeployment foo"), ContainSubstring("read-only mirror"))),
+						))),
+				)
+			})
+
+			It("should not make any changes to the CAPI machine set", func() {
+				resourceVersion := capiMachineSet.GetResourceVersion()
+				Consistently(k.Object(capiMachineSet), timeout).Should(
+					HaveField("ResourceVersion", Equal(resourceVersion)),
+				)
+			})
+
+			Context("when the machine deployment deletes the CAPI machine set", func() {
+				BeforeEach(func() {
+					By("Waiting for the MAPI machine set to be handed over to the machine deployment")
+					Eventually(k.Object(mapiMachineSet), timeout).Should(
+						HaveField("Status.Conditions", ContainElement(
+							HaveField("Reason", Equal("CAPIMachineSetOwnedByMachineDeployment")),
+						)),
+					)
+
+					By("Deleting the CAPI machine set")
+					Expect(k8sClient.Delete(ctx, capiMachineSet)).Should(Succeed())
+				})
+
+				It("should not recreate the CAPI machine set", func() {
+					Consistently(k.ObjectList(&capiv1beta1.MachineSetList{}), timeout).ShouldNot(HaveField("Items",
+						ContainElement(HaveField("ObjectMeta.Name", Equal(capiMachineSet.GetName()))),
+					))
+				})
+			})
+		})
+
 		Context("when the MAPI machine set does not exist and the CAPI machine set does", func() {
 			BeforeEach(func() {
 				By("Creating the CAPI machine set")
@@ -480,7 +853,12 @@ var _ = Describe("With a running MachineSetSync controller", func() {
 					capiMachineSet = capiv1resourcebuilder.MachineSet().WithName(mapiMachineSet.Name).WithNamespace(capiNamespace.Name).Build()
 					Expect(k8sClient.Create(ctx, capiMachineSet)).Should(Succeed())
 
-					capiInfraMachineTemplate := capav1builder.AWSMachineTemplate().WithName(mapiMachineSet.Name).WithNamespace(capiNamespace.Name).Build()
+					By("Checking the CAPI machine set references a template named after its spec hash")
+					Eventually(k.Object(capiMachineSet), timeout).Should(
+						HaveField("Spec.Template.Spec.InfrastructureRef.Name", HavePrefix(mapiMachineSet.Name+"-")))
+
+					capiInfraMachineTemplate := capav1builder.AWSMachineTemplate().
+						WithName(capiMachineSet.Spec.Template.Spec.InfrastructureRef.Name).WithNamespace(capiNamespace.Name).Build()
 					Eventually(k.Get(capiInfraMachineTemplate)).Should(Succeed())
 				})
 
@@ -504,10 +882,43 @@ var _ = Describe("With a running MachineSetSync controller", func() {
 				})
 
 				It("should create the CAPI infra machine template", func() {
-					capiInfraMachineTemplate := capav1builder.AWSMachineTemplate().WithName(mapiMachineSet.Name).WithNamespace(capiNamespace.Name).Build()
+					By("Checking the CAPI machine set references a template named after its spec hash")
+					Eventually(k.Object(capiMachineSet), timeout).Should(
+						HaveField("Spec.Template.Spec.InfrastructureRef.Name", HavePrefix(mapiMachineSet.Name+"-")))
+
+					capiInfraMachineTemplate := capav1builder.AWSMachineTemplate().
+						WithName(capiMachineSet.Spec.Template.Spec.InfrastructureRef.Name).WithNamespace(capiNamespace.Name).Build()
 					Eventually(k.Get(capiInfraMachineTemplate)).Should(Succeed())
 				})
 
+				It("should replace the CAPI infra machine template when the provider spec changes", func() {
+					Eventually(k.Object(capiMachineSet), timeout).Should(
+						HaveField("Spec.Template.Spec.InfrastructureRef.Name", HavePrefix(mapiMachineSet.Name+"-")))
+
+					oldInfraMachineTemplate := capav1builder.AWSMachineTemplate().
+						WithName(capiMachineSet.Spec.Template.Spec.InfrastructureRef.Name).WithNamespace(capiNamespace.Name).Build()
+					Eventually(k.Get(oldInfraMachineTemplate)).Should(Succeed())
+
+					By("Changing the instance type of the MAPI machine set")
+					Eventually(k.Update(mapiMachineSet, func() {
+						mapiMachineSet.Spec.Template.Spec.ProviderSpec.Value = machinev1resourcebuilder.AWSProviderSpec().
+							WithLoadBalancers(nil).WithInstanceType("m6i.xlarge").BuildRawExtension()
+					})).Should(Succeed())
+
+					By("Checking the CAPI machine set references a new template")
+					Eventually(k.Object(capiMachineSet), timeout).Should(SatisfyAll(
+						HaveField("Spec.Template.Spec.InfrastructureRef.Name", HavePrefix(mapiMachineSet.Name+"-")),
+						HaveField("Spec.Template.Spec.InfrastructureRef.Name", Not(Equal(oldInfraMachineTemplate.Name))),
+					))
+
+					newInfraMachineTemplate := capav1builder.AWSMachineTemplate().
+						WithName(capiMachineSet.Spec.Template.Spec.InfrastructureRef.Name).WithNamespace(capiNamespace.Name).Build()
+					Eventually(k.Object(newInfraMachineTemplate)).Should(HaveField("Spec.Template.Spec.InstanceType", Equal("m6i.xlarge")))
+
+					By("Checking the previous template is deleted")
+					Eventually(k.Get(oldInfraMachineTemplate)).Should(WithTransform(apierrors.IsNotFound, BeTrue()))
+				})
+
 				It("should update the synchronized condition on the MAPI machine set to True", func() {
 					Eventually(k.Object(mapiMachineSet), timeout).Should(
 						HaveField("Status.Conditions", ContainElement(
@@ -558,7 +969,12 @@ var _ = Describe("With a running MachineSetSync controller", func() {
 					capiMachineSet = capiv1resourcebuilder.MachineSet().WithName(mapiMachineSet.Name).WithNamespace(capiNamespace.Name).Build()
 					Expect(k8sClient.Create(ctx, capiMachineSet)).Should(Succeed())
 
-					capiInfraMachineTemplate := capav1builder.AWSMachineTemplate().WithName(mapiMachineSet.Name).WithNamespace(capiNamespace.Name).Build()
+					By("Checking the CAPI machine set references a template named after its spec hash")
+					Eventually(k.Object(capiMachineSet), timeout).Should(
+						HaveField("Spec.Template.Spec.InfrastructureRef.Name", HavePrefix(mapiMachineSet.Name+"-")))
+
+					capiInfraMachineTemplate := capav1builder.AWSMachineTemplate().
+						WithName(capiMachineSet.Spec.Template.Spec.InfrastructureRef.Name).WithNamespace(capiNamespace.Name).Build()
 					Eventually(k.Get(capiInfraMachineTemplate)).Should(Succeed())
 				})
 
@@ -578,3 +994,18 @@ var _ = Describe("With a running MachineSetSync controller", func() {
 	})
 
 })
+
+var _ = Describe("machinesNotSynchronizedMessage", func() {
+	It("should cap the number of machines and the length of their error", func() {
+		machineErrors := map[string]string{}
+		for _, name := range []string{"foo-g", "foo-f", "foo-e", "foo-d", "foo-c", "foo-b", "foo-a"} {
+			machineErrors[name] = "failed to convert"
+		}
+
+		machineErrors["foo-a"] = strings.Repeat("x", maxReportedMachineErrorLength+1) + "\nsecond error"
+
+		Expect(machinesNotSynchronizedMessage(machineErrors)).To(Equal("7 machine(s) failed to synchronize: " +
+			"foo-a: " + strings.Repeat("x", maxReportedMachineErrorLength) + "...; " +
+			"foo-b: failed to convert; foo-c: failed to convert; foo-d: failed to convert; foo-e: failed to convert; and 2 more"))
+	})
+})
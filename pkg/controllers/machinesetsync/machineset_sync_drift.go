@@ -0,0 +1,256 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinesetsync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	machinev1applyconfigs "github.com/openshift/client-go/machine/applyconfigurations/machine/v1beta1"
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/synccommon"
+	"github.com/openshift/cluster-capi-operator/pkg/metrics"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultDriftThreshold is how long the MAPI and CAPI copies of a machine set may differ
+	// before the drifted condition is set.
+	DefaultDriftThreshold = 10 * time.Minute
+
+	// driftFieldOwner owns the drifted condition. It differs from the owners of the other conditions,
+	// so that applying them does not remove the drifted condition.
+	driftFieldOwner = "machineset-sync-controller-drift"
+
+	driftFieldReplicas = "replicas"
+	driftFieldSelector = "selector"
+	driftFieldTemplate = "template"
+)
+
+// driftTracker records since when the copies of each machine set have been drifting.
+// The zero value is ready to use.
+type driftTracker struct {
+	mu    sync.Mutex
+	since map[string]time.Time
+}
+
+// observe records whether the copies of the machine set with the given name differ at the given time,
+// and returns since when they have been differing. The zero time is returned when they are in sync.
+func (t *driftTracker) observe(name string, drifted bool, now time.Time) time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !drifted {
+		delete(t.since, name)
+		return time.Time{}
+	}
+
+	if t.since == nil {
+		t.since = map[string]time.Time{}
+	}
+
+	since, ok := t.since[name]
+	if !ok {
+		since = now
+		t.since[name] = since
+	}
+
+	return since
+}
+
+// syncDrift compares the copies of the machine set with the given name, records the fields that differ in the drift
+// metric and sets the drifted condition of the MAPI machine set once they have differed for longer than the threshold.
+// The copies are expected to differ briefly, until the non-authoritative one is synchronized, so the returned delay
+// is when the machine set should be checked again for the threshold to be enforced, zero when it needs no check.
+func (r *MachineSetSyncReconciler) syncDrift(ctx context.Context, name string) (time.Duration, error) {
+	mapiMachineSet, capiMachineSet, err := r.fetchMachineSets(ctx, name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch machine sets: %w", err)
+	}
+
+	fields, err := r.driftedFields(ctx, mapiMachineSet, capiMachineSet)
+	if err != nil {
+		return 0, err
+	}
+
+	metrics.SetMachineSetDrift(name, fields)
+
+	now := time.Now()
+	since := r.drift.observe(name, len(fields) > 0, now)
+
+	if mapiMachineSet == nil {
+		return 0, nil
+	}
+
+	drifted := synccommon.FindCondition(mapiMachineSet.Status.Conditions, consts.DriftedCondition)
+
+	switch {
+	case !since.IsZero() && now.Sub(since) < r.DriftThreshold:
+		return r.DriftThreshold - now.Sub(since), nil
+	case !since.IsZero():
+		message := fmt.Sprintf("The MAPI and CAPI machine sets have differed since %s: %s",
+			since.UTC().Format(time.RFC3339), strings.Join(fields, ", "))
+
+		if drifted != nil && drifted.Status == corev1.ConditionTrue && drifted.Message == message {
+			return 0, nil
+		}
+
+		if drifted == nil || drifted.Status != corev1.ConditionTrue {
+			r.Recorder.Event(mapiMachineSet, corev1.EventTypeWarning, consts.ReasonMachineSetDrifted, message)
+		}
+
+		return 0, r.updateDriftedConditionWithPatch(ctx, mapiMachineSet, corev1.ConditionTrue, consts.ReasonMachineSetDrifted, message)
+	case drifted != nil && drifted.Status == corev1.ConditionTrue:
+		return 0, r.updateDriftedConditionWithPatch(ctx, mapiMachineSet, corev1.ConditionFalse, consts.ReasonResourceSynchronized, "")
+	default:
+		return 0, nil
+	}
+}
+
+// driftedFields returns the fields the non-authoritative copy of the machine set is expected to mirror from the
+// authoritative one but does not: its replicas, its selector, or the hash of its template.
+// The copies are converted in the same way as when synchronizing them. Copies that are not synchronized,
+// because either is missing, the machine set is migrating or its CAPI copy is owned by a machine deployment, never drift.
+func (r *MachineSetSyncReconciler) driftedFields(ctx context.Context, mapiMachineSet *machinev1beta1.MachineSet, capiMachineSet *capiv1beta1.MachineSet) ([]string, error) {
+	if mapiMachineSet == nil || capiMachineSet == nil || isOwnedByMachineDeployment(capiMachineSet) {
+		return nil, nil
+	}
+
+	switch mapiMachineSet.Status.AuthoritativeAPI {
+	case machinev1beta1.MachineAuthorityMachineAPI:
+		newCAPIMachineSet, newCAPIInfraMachineTemplate, _, err := r.convertMAPIToCAPIMachineSet(mapiMachineSet)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert MAPI machine set to CAPI machine set: %w", err)
+		}
+
+		if err := setInfraMachineTemplateName(mapiMachineSet, newCAPIMachineSet, newCAPIInfraMachineTemplate); err != nil {
+			return nil, fmt.Errorf("failed to name CAPI infra machine template: %w", err)
+		}
+
+		newCAPIMachineSet.Spec.Template.Spec.InfrastructureRef.Namespace = r.CAPINamespace
+
+		return compareMirroredFields(
+			capiMachineSet.Spec.Replicas, newCAPIMachineSet.Spec.Replicas,
+			capiMachineSet.Spec.Selector, newCAPIMachineSet.Spec.Selector,
+			capiMachineSet.Spec.Template, newCAPIMachineSet.Spec.Template,
+		)
+	case machinev1beta1.MachineAuthorityClusterAPI:
+		infraCluster, infraMachineTemplate, err := r.fetchCAPIInfraResources(ctx, capiMachineSet)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch CAPI infra resources: %w", err)
+		}
+
+		newMAPIMachineSet, _, err := r.convertCAPIToMAPIMachineSet(capiMachineSet, infraMachineTemplate, infraCluster)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert CAPI machine set to MAPI machine set: %w", err)
+		}
+
+		newMAPIMachineSet.Spec.Template.Labels = util.MergeMaps(mapiMachineSet.Spec.Template.Labels, newMAPIMachineSet.Spec.Template.Labels)
+
+		return compareMirroredFields(
+			mapiMachineSet.Spec.Replicas, newMAPIMachineSet.Spec.Replicas,
+			mapiMachineSet.Spec.Selector, newMAPIMachineSet.Spec.Selector,
+			mapiMachineSet.Spec.Template, newMAPIMachineSet.Spec.Template,
+		)
+	default:
+		return nil, nil
+	}
+}
+
+// compareMirroredFields returns the mirrored fields that differ between the current and the expected copy of a machine set.
+// The selectors and templates are compared by the hash of their json serialisation, so that unset and empty fields are equal.
+func compareMirroredFields(currentReplicas, expectedReplicas *int32, currentSelector, expectedSelector metav1.LabelSelector, currentTemplate, expectedTemplate interface{}) ([]string, error) {
+	fields := []string{}
+
+	if !ptr.Equal(currentReplicas, expectedReplicas) {
+		fields = append(fields, driftFieldReplicas)
+	}
+
+	for _, f := range []struct {
+		name              string
+		current, expected interface{}
+	}{
+		{name: driftFieldSelector, current: currentSelector, expected: expectedSelector},
+		{name: driftFieldTemplate, current: currentTemplate, expected: expectedTemplate},
+	} {
+		currentHash, err := hashJSON(f.current)
+		if err != nil {
+			return nil, err
+		}
+
+		expectedHash, err := hashJSON(f.expected)
+		if err != nil {
+			return nil, err
+		}
+
+		if currentHash != expectedHash {
+			fields = append(fields, f.name)
+		}
+	}
+
+	return fields, nil
+}
+
+// hashJSON returns the sha256 hash of the json serialisation of the given value.
+func hashJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal %T: %w", v, err)
+	}
+
+	hash := sha256.Sum256(data)
+
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// updateDriftedConditionWithPatch updates the drifted condition using a server side apply patch.
+func (r *MachineSetSyncReconciler) updateDriftedConditionWithPatch(ctx context.Context, mapiMachineSet *machinev1beta1.MachineSet, status corev1.ConditionStatus, reason, message string) error {
+	severity := machinev1beta1.ConditionSeverityNone
+	if status == corev1.ConditionTrue {
+		severity = machinev1beta1.ConditionSeverityWarning
+	}
+
+	conditionAc := machinev1applyconfigs.Condition().
+		WithType(consts.DriftedCondition).
+		WithStatus(status).
+		WithReason(reason).
+		WithMessage(message).
+		WithSeverity(severity)
+
+	synccommon.SetLastTransitionTime(mapiMachineSet.Status.Conditions, conditionAc)
+
+	msAc := machinev1applyconfigs.MachineSet(mapiMachineSet.GetName(), mapiMachineSet.GetNamespace()).
+		WithStatus(machinev1applyconfigs.MachineSetStatus().WithConditions(conditionAc))
+
+	if err := r.Status().Patch(ctx, mapiMachineSet, util.ApplyConfigPatch(msAc), client.ForceOwnership, client.FieldOwner(driftFieldOwner)); err != nil {
+		return fmt.Errorf("failed to patch MAPI machine set status with drifted condition: %w", err)
+	}
+
+	return nil
+}
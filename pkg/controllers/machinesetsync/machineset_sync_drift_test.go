@@ -0,0 +1,72 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinesetsync
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+var _ = Describe("MachineSet drift detection", func() {
+	Context("when tracking the drift of a machine set", func() {
+		It("should keep the time the drift started until it is resolved", func() {
+			tracker := &driftTracker{}
+			start := time.Now()
+
+			Expect(tracker.observe("worker", false, start)).To(BeZero())
+			Expect(tracker.observe("worker", true, start)).To(Equal(start))
+			Expect(tracker.observe("worker", true, start.Add(time.Minute))).To(Equal(start))
+			Expect(tracker.observe("other", true, start.Add(time.Minute))).To(Equal(start.Add(time.Minute)))
+
+			Expect(tracker.observe("worker", false, start.Add(2*time.Minute))).To(BeZero())
+			Expect(tracker.observe("worker", true, start.Add(3*time.Minute))).To(Equal(start.Add(3 * time.Minute)))
+		})
+	})
+
+	Context("when comparing the mirrored fields", func() {
+		selector := metav1.LabelSelector{MatchLabels: map[string]string{"machine.openshift.io/cluster-api-machineset": "worker"}}
+		template := machinev1beta1.MachineTemplateSpec{
+			ObjectMeta: machinev1beta1.ObjectMeta{Labels: map[string]string{"machine.openshift.io/cluster-api-machineset": "worker"}},
+		}
+
+		It("should report no drift when the copies match", func() {
+			Expect(compareMirroredFields(ptr.To[int32](3), ptr.To[int32](3), selector, *selector.DeepCopy(), template, *template.DeepCopy())).To(BeEmpty())
+		})
+
+		It("should ignore the difference between unset and empty fields", func() {
+			emptySelector := metav1.LabelSelector{MatchLabels: map[string]string{}}
+
+			Expect(compareMirroredFields(nil, nil, metav1.LabelSelector{}, emptySelector, template, *template.DeepCopy())).To(BeEmpty())
+		})
+
+		It("should report the drifted fields", func() {
+			otherSelector := metav1.LabelSelector{MatchLabels: map[string]string{"machine.openshift.io/cluster-api-machineset": "other"}}
+			otherTemplate := template.DeepCopy()
+			otherTemplate.Spec.ProviderID = ptr.To("aws:///us-east-1a/i-0123456789")
+
+			Expect(compareMirroredFields(ptr.To[int32](3), ptr.To[int32](1), selector, selector, template, template)).To(ConsistOf(driftFieldReplicas))
+			Expect(compareMirroredFields(ptr.To[int32](3), nil, selector, otherSelector, template, *otherTemplate)).To(
+				ConsistOf(driftFieldReplicas, driftFieldSelector, driftFieldTemplate))
+		})
+	})
+})
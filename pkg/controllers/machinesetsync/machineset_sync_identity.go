@@ -0,0 +1,91 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinesetsync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	capov1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	reasonFailedToEnsureCAPIIdentitySecret = "FailedToEnsureCAPIIdentitySecret"
+	reasonCopiedCAPIIdentitySecret         = "CopiedCAPIIdentitySecret"
+)
+
+// ensureCAPIIdentitySecret copies the credentials secret referenced by the identity of a converted InfraMachineTemplate
+// from the MAPI namespace to the CAPI namespace, under the same name, as the identities of the providers reference
+// secrets of the namespace of the machines. The copy is labeled as managed, and kept up to date. A secret of the CAPI
+// namespace which is not managed, such as the credentials of the cluster, is left untouched.
+func (r *MachineSetSyncReconciler) ensureCAPIIdentitySecret(ctx context.Context, mapiMachineSet *machinev1beta1.MachineSet, infraMachineTemplate client.Object) error {
+	secretName := identitySecretName(infraMachineTemplate)
+	if secretName == "" {
+		return nil
+	}
+
+	source := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: mapiMachineSet.GetNamespace(), Name: secretName}, source); err != nil {
+		return fmt.Errorf("failed to get MAPI credentials secret %s: %w", secretName, err)
+	}
+
+	existing := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: r.CAPINamespace, Name: secretName}, existing); apierrors.IsNotFound(err) {
+		copied := &corev1.Secret{Type: source.Type, Data: source.Data}
+		copied.SetName(secretName)
+		copied.SetNamespace(r.CAPINamespace)
+		util.SetManagedByLabel(copied)
+
+		if err := r.Create(ctx, copied); err != nil {
+			return fmt.Errorf("failed to create CAPI credentials secret %s: %w", secretName, err)
+		}
+
+		r.recordSyncEvent(reasonCopiedCAPIIdentitySecret, fmt.Sprintf("Copied credentials secret %s to the CAPI namespace", secretName), mapiMachineSet)
+
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get CAPI credentials secret %s: %w", secretName, err)
+	}
+
+	if !util.HasManagedByLabel(existing) || maps.EqualFunc(existing.Data, source.Data, bytes.Equal) {
+		return nil
+	}
+
+	existing.Data = source.Data
+	if err := r.Update(ctx, existing); err != nil {
+		return fmt.Errorf("failed to update CAPI credentials secret %s: %w", secretName, err)
+	}
+
+	return nil
+}
+
+// identitySecretName returns the name of the credentials secret referenced by the identity of the InfraMachineTemplate,
+// empty when the machines use the credentials of the cluster.
+func identitySecretName(infraMachineTemplate client.Object) string {
+	if template, ok := infraMachineTemplate.(*capov1.OpenStackMachineTemplate); ok && template.Spec.Template.Spec.IdentityRef != nil {
+		return template.Spec.Template.Spec.IdentityRef.Name
+	}
+
+	return ""
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinesetsync
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	capav1builder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/infrastructure/v1beta2"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	capov1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("CAPI identity secrets", func() {
+	ctx := context.Background()
+
+	openStackTemplate := func(identityRef *capov1.OpenStackIdentityReference) *capov1.OpenStackMachineTemplate {
+		template := &capov1.OpenStackMachineTemplate{}
+		template.Spec.Template.Spec.IdentityRef = identityRef
+
+		return template
+	}
+
+	secret := func(namespace string, data string, managed bool) *corev1.Secret {
+		s := &corev1.Secret{Data: map[string][]byte{"clouds.yaml": []byte(data)}}
+		s.SetName("tenant-credentials")
+		s.SetNamespace(namespace)
+
+		if managed {
+			util.SetManagedByLabel(s)
+		}
+
+		return s
+	}
+
+	DescribeTable("should only name the secrets of the identities of the templates",
+		func(template client.Object, expected string) {
+			Expect(identitySecretName(template)).To(Equal(expected))
+		},
+		Entry("with an AWSMachineTemplate", capav1builder.AWSMachineTemplate().Build(), ""),
+		Entry("with an OpenStackMachineTemplate using the credentials of the cluster", openStackTemplate(nil), ""),
+		Entry("with an OpenStackMachineTemplate with an identity",
+			openStackTemplate(&capov1.OpenStackIdentityReference{Name: "tenant-credentials", CloudName: "tenant"}), "tenant-credentials"),
+	)
+
+	Context("when the template has an identity", func() {
+		var (
+			cl         client.Client
+			reconciler *MachineSetSyncReconciler
+		)
+
+		mapiMachineSet := machinev1resourcebuilder.MachineSet().WithName("worker").WithNamespace(consts.DefaultMAPIManagedNamespace).Build()
+		template := openStackTemplate(&capov1.OpenStackIdentityReference{Name: "tenant-credentials", CloudName: "tenant"})
+
+		setup := func(objs ...client.Object) {
+			scheme := runtime.NewScheme()
+			Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+			cl = fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+			reconciler = &MachineSetSyncReconciler{Client: cl, Recorder: record.NewFakeRecorder(10), CAPINamespace: consts.DefaultManagedNamespace}
+		}
+
+		getCAPISecret := func() *corev1.Secret {
+			copied := &corev1.Secret{}
+			Expect(cl.Get(ctx, client.ObjectKey{Namespace: consts.DefaultManagedNamespace, Name: "tenant-credentials"}, copied)).To(Succeed())
+
+			return copied
+		}
+
+		It("should copy the secret to the CAPI namespace", func() {
+			setup(secret(consts.DefaultMAPIManagedNamespace, "source", false))
+
+			Expect(reconciler.ensureCAPIIdentitySecret(ctx, mapiMachineSet, template)).To(Succeed())
+
+			copied := getCAPISecret()
+			Expect(copied.Data).To(HaveKeyWithValue("clouds.yaml", []byte("source")))
+			Expect(util.HasManagedByLabel(copied)).To(BeTrue())
+		})
+
+		It("should update the copied secret when the source changes", func() {
+			setup(secret(consts.DefaultMAPIManagedNamespace, "updated", false), secret(consts.DefaultManagedNamespace, "source", true))
+
+			Expect(reconciler.ensureCAPIIdentitySecret(ctx, mapiMachineSet, template)).To(Succeed())
+
+			Expect(getCAPISecret().Data).To(HaveKeyWithValue("clouds.yaml", []byte("updated")))
+		})
+
+		It("should leave a secret of the CAPI namespace which is not managed untouched", func() {
+			setup(secret(consts.DefaultMAPIManagedNamespace, "source", false), secret(consts.DefaultManagedNamespace, "unmanaged", false))
+
+			Expect(reconciler.ensureCAPIIdentitySecret(ctx, mapiMachineSet, template)).To(Succeed())
+
+			Expect(getCAPISecret().Data).To(HaveKeyWithValue("clouds.yaml", []byte("unmanaged")))
+		})
+
+		It("should fail when the source secret does not exist", func() {
+			setup()
+
+			Expect(reconciler.ensureCAPIIdentitySecret(ctx, mapiMachineSet, template)).To(MatchError(ContainSubstring("failed to get MAPI credentials secret tenant-credentials")))
+		})
+	})
+})
@@ -0,0 +1,119 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinesetsync
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/synccommon"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	reasonMachinesNotSynchronized = "MachinesNotSynchronized"
+
+	// maxReportedMachineErrors is the maximum number of machines listed in the synchronized condition of a machine set,
+	// so that the condition message stays bounded for machine sets with many replicas.
+	maxReportedMachineErrors = 5
+
+	// maxReportedMachineErrorLength is the maximum length of the error reported for each machine.
+	maxReportedMachineErrorLength = 256
+)
+
+// updateSynchronizedConditionForMachines sets the synchronized condition of a machine set that was synchronized successfully.
+// The condition is only true when none of the machines of the machine set failed to synchronize, otherwise it lists the
+// failing machines along with their error, and an event is recorded each time the list changes.
+func (r *MachineSetSyncReconciler) updateSynchronizedConditionForMachines(ctx context.Context, mapiMachineSet *machinev1beta1.MachineSet, generation *int64) error {
+	machineErrors, err := r.machineSyncErrors(ctx, mapiMachineSet)
+	if err != nil {
+		return err
+	}
+
+	if len(machineErrors) == 0 {
+		return r.updateSynchronizedConditionWithPatch(ctx, mapiMachineSet, corev1.ConditionTrue,
+			consts.ReasonResourceSynchronized, messageSuccessfullySynchronized, generation)
+	}
+
+	message := machinesNotSynchronizedMessage(machineErrors)
+
+	if current := synccommon.FindCondition(mapiMachineSet.Status.Conditions, consts.SynchronizedCondition); current == nil || current.Message != message {
+		r.Recorder.Event(mapiMachineSet, corev1.EventTypeWarning, reasonMachinesNotSynchronized, message)
+	}
+
+	return r.updateSynchronizedConditionWithPatch(ctx, mapiMachineSet, corev1.ConditionFalse, reasonMachinesNotSynchronized, message, nil)
+}
+
+// machineSyncErrors returns the message of the synchronized condition of the MAPI machines controlled by the machine set
+// which failed to synchronize, by machine name.
+func (r *MachineSetSyncReconciler) machineSyncErrors(ctx context.Context, mapiMachineSet *machinev1beta1.MachineSet) (map[string]string, error) {
+	machines := &machinev1beta1.MachineList{}
+	if err := r.List(ctx, machines, client.InNamespace(mapiMachineSet.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list MAPI machines: %w", err)
+	}
+
+	machineErrors := map[string]string{}
+
+	for i := range machines.Items {
+		machine := &machines.Items[i]
+		if !metav1.IsControlledBy(machine, mapiMachineSet) {
+			continue
+		}
+
+		if cond := synccommon.FindCondition(machine.Status.Conditions, consts.SynchronizedCondition); cond != nil && cond.Status == corev1.ConditionFalse {
+			machineErrors[machine.Name] = cond.Message
+		}
+	}
+
+	return machineErrors, nil
+}
+
+// machinesNotSynchronizedMessage lists the failing machines, sorted by name, along with the first line of their error.
+// At most maxReportedMachineErrors machines are listed, the others are only counted.
+func machinesNotSynchronizedMessage(machineErrors map[string]string) string {
+	names := make([]string, 0, len(machineErrors))
+	for name := range machineErrors {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	reported := make([]string, 0, maxReportedMachineErrors)
+
+	for _, name := range names[:min(len(names), maxReportedMachineErrors)] {
+		machineError, _, _ := strings.Cut(machineErrors[name], "\n")
+		if len(machineError) > maxReportedMachineErrorLength {
+			machineError = machineError[:maxReportedMachineErrorLength] + "..."
+		}
+
+		reported = append(reported, fmt.Sprintf("%s: %s", name, machineError))
+	}
+
+	message := fmt.Sprintf("%d machine(s) failed to synchronize: %s", len(names), strings.Join(reported, "; "))
+
+	if len(names) > maxReportedMachineErrors {
+		message += fmt.Sprintf("; and %d more", len(names)-maxReportedMachineErrors)
+	}
+
+	return message
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinesetsync
+
+import (
+	"context"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/synccommon"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// pauseFieldOwner owns the paused condition. It differs from the owners of the other conditions,
+	// so that applying them does not remove the paused condition.
+	pauseFieldOwner = "machineset-sync-controller-pause"
+)
+
+// mapNamespaceToMachineSets requeues the MAPI machine sets of the namespace owned by the shard,
+// so that their synchronization is paused or resumed along with the namespace.
+func (r *MachineSetSyncReconciler) mapNamespaceToMachineSets(ctx context.Context, _ client.Object) []reconcile.Request {
+	return synccommon.RequestsForNamespace(ctx, r.Client, &machinev1beta1.MachineSetList{}, r.MAPINamespace, r.Shard)
+}
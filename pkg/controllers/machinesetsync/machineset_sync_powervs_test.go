@@ -0,0 +1,234 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinesetsync
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	configv1 "github.com/openshift/api/config/v1"
+	mapiv1 "github.com/openshift/api/machine/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-api-actuator-pkg/testutils"
+	capiv1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/core/v1beta1"
+	capibmv1builder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/infrastructure/v1beta2"
+	configv1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/config/v1"
+	corev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/core/v1"
+	powervsbuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	capibmv1 "sigs.k8s.io/cluster-api-provider-ibmcloud/api/v1beta2"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/config"
+	"sigs.k8s.io/controller-runtime/pkg/envtest/komega"
+
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+)
+
+var _ = Describe("With a running MachineSetSync controller on PowerVS", func() {
+	const infrastructureName = "cluster-powervs"
+
+	var mgrCancel context.CancelFunc
+	var mgrDone chan struct{}
+	var k komega.Komega
+
+	var capiNamespace *corev1.Namespace
+	var mapiNamespace *corev1.Namespace
+
+	var mapiMachineSet *machinev1beta1.MachineSet
+	var powerVSMachineTemplate *capibmv1.IBMPowerVSMachineTemplate
+
+	synchronized := func() OmegaMatcher {
+		return HaveField("Status.Conditions", ContainElement(SatisfyAll(
+			HaveField("Type", Equal(consts.SynchronizedCondition)),
+			HaveField("Status", Equal(corev1.ConditionTrue)),
+		)))
+	}
+
+	BeforeEach(func() {
+		k = komega.New(k8sClient)
+
+		By("Setting up the namespaces for the test")
+		mapiNamespace = corev1resourcebuilder.Namespace().WithGenerateName("openshift-machine-api-").Build()
+		Expect(k8sClient.Create(ctx, mapiNamespace)).To(Succeed())
+
+		capiNamespace = corev1resourcebuilder.Namespace().WithGenerateName("openshift-cluster-api-").Build()
+		Expect(k8sClient.Create(ctx, capiNamespace)).To(Succeed())
+
+		By("Creating the IBMPowerVSCluster")
+		Expect(k8sClient.Create(ctx, capibmv1builder.PowerVSCluster().
+			WithNamespace(capiNamespace.GetName()).WithName(infrastructureName).Build())).To(Succeed())
+
+		powerVSMachineTemplate = capibmv1builder.PowerVSMachineTemplate().
+			WithNamespace(capiNamespace.GetName()).
+			WithName("powervs-machine-template").
+			WithServiceInstance(&capibmv1.IBMPowerVSResourceReference{ID: ptr.To("service-instance-id")}).
+			WithImage(&capibmv1.IBMPowerVSResourceReference{ID: ptr.To("image-id")}).
+			WithNetwork(capibmv1.IBMPowerVSResourceReference{ID: ptr.To("network-id")}).
+			WithSSHKey("ssh-key").
+			WithSystemType("s922").
+			WithProcessorType(capibmv1.PowerVSProcessorTypeShared).
+			WithProcessors(intstr.FromString("0.5")).
+			WithMemoryGiB(32).
+			Build()
+
+		By("Setting up a manager and controller")
+		mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+			Scheme: testScheme,
+			Controller: config.Controller{
+				SkipNameValidation: ptr.To(true),
+			},
+		})
+		Expect(err).ToNot(HaveOccurred(), "Manager should be able to be created")
+
+		reconciler := &MachineSetSyncReconciler{
+			Client: mgr.GetClient(),
+			Infra: configv1resourcebuilder.Infrastructure().
+				AsPowerVS("cluster").WithInfrastructureName(infrastructureName).Build(),
+			Platform:      configv1.PowerVSPlatformType,
+			CAPINamespace: capiNamespace.GetName(),
+			MAPINamespace: mapiNamespace.GetName(),
+		}
+		Expect(reconciler.SetupWithManager(mgr)).To(Succeed(), "Reconciler should be able to setup with manager")
+
+		var mgrCtx context.Context
+		mgrCtx, mgrCancel = context.WithCancel(context.Background())
+		mgrDone = make(chan struct{})
+
+		go func() {
+			defer GinkgoRecover()
+			defer close(mgrDone)
+
+			Expect(mgr.Start(mgrCtx)).To(Succeed())
+		}()
+	})
+
+	AfterEach(func() {
+		By("Stopping the manager")
+		mgrCancel()
+		Eventually(mgrDone, timeout).Should(BeClosed())
+
+		By("Cleaning up the test resources")
+		testutils.CleanupResources(Default, ctx, cfg, k8sClient, mapiNamespace.GetName(),
+			&machinev1beta1.MachineSet{},
+		)
+
+		testutils.CleanupResources(Default, ctx, cfg, k8sClient, capiNamespace.GetName(),
+			&capiv1beta1.MachineSet{},
+			&capibmv1.IBMPowerVSCluster{},
+			&capibmv1.IBMPowerVSMachineTemplate{},
+		)
+	})
+
+	Context("when the MAPI machine set is authoritative", func() {
+		BeforeEach(func() {
+			By("Creating the MAPI machine set")
+			mapiMachineSet = machinev1resourcebuilder.MachineSet().
+				WithNamespace(mapiNamespace.GetName()).
+				WithName("powervs").
+				WithProviderSpecBuilder(powervsbuilder.PowerVSProviderSpec().WithLoadBalancers(nil)).
+				Build()
+			Expect(k8sClient.Create(ctx, mapiMachineSet)).To(Succeed())
+
+			Eventually(k.UpdateStatus(mapiMachineSet, func() {
+				mapiMachineSet.Status.AuthoritativeAPI = machinev1beta1.MachineAuthorityMachineAPI
+			})).Should(Succeed())
+		})
+
+		It("should create the CAPI machine set and its IBMPowerVSMachineTemplate", func() {
+			capiMachineSet := capiv1resourcebuilder.MachineSet().WithName(mapiMachineSet.Name).WithNamespace(capiNamespace.Name).Build()
+			Eventually(k.Object(capiMachineSet), timeout).Should(SatisfyAll(
+				HaveField("Spec.ClusterName", Equal(infrastructureName)),
+				HaveField("Spec.Template.Spec.InfrastructureRef.Kind", Equal("IBMPowerVSMachineTemplate")),
+				HaveField("Spec.Template.Spec.InfrastructureRef.Name", HavePrefix(mapiMachineSet.Name+"-")),
+			))
+
+			template := capibmv1builder.PowerVSMachineTemplate().
+				WithName(capiMachineSet.Spec.Template.Spec.InfrastructureRef.Name).WithNamespace(capiNamespace.Name).Build()
+			Eventually(k.Object(template), timeout).Should(
+				HaveField("Spec.Template.Spec.ServiceInstance", HaveField("ID", Equal(ptr.To("default-serviceInstanceID")))),
+			)
+		})
+
+		It("should update the synchronized condition on the MAPI machine set to True", func() {
+			Eventually(k.Object(mapiMachineSet), timeout).Should(synchronized())
+		})
+	})
+
+	Context("when the CAPI machine set is authoritative", func() {
+		BeforeEach(func() {
+			By("Creating the MAPI machine set")
+			mapiMachineSet = machinev1resourcebuilder.MachineSet().
+				WithNamespace(mapiNamespace.GetName()).
+				WithName("powervs").
+				WithProviderSpecBuilder(powervsbuilder.PowerVSProviderSpec().WithLoadBalancers(nil)).
+				Build()
+			Expect(k8sClient.Create(ctx, mapiMachineSet)).To(Succeed())
+
+			Eventually(k.UpdateStatus(mapiMachineSet, func() {
+				mapiMachineSet.Status.AuthoritativeAPI = machinev1beta1.MachineAuthorityClusterAPI
+			})).Should(Succeed())
+
+			By("Creating the CAPI machine set and its IBMPowerVSMachineTemplate")
+			Expect(k8sClient.Create(ctx, powerVSMachineTemplate)).To(Succeed())
+
+			capiMachineSet := capiv1resourcebuilder.MachineSet().
+				WithNamespace(capiNamespace.GetName()).
+				WithName(mapiMachineSet.Name).
+				WithClusterName(infrastructureName).
+				WithReplicas(3).
+				WithTemplate(capiv1beta1.MachineTemplateSpec{
+					Spec: capiv1beta1.MachineSpec{
+						ClusterName: infrastructureName,
+						InfrastructureRef: corev1.ObjectReference{
+							APIVersion: capibmv1.GroupVersion.String(),
+							Kind:       powerVSMachineTemplate.Kind,
+							Name:       powerVSMachineTemplate.GetName(),
+							Namespace:  powerVSMachineTemplate.GetNamespace(),
+						},
+					},
+				}).
+				Build()
+			Expect(k8sClient.Create(ctx, capiMachineSet)).To(Succeed())
+		})
+
+		It("should update the MAPI machine set from the CAPI machine set and its IBMPowerVSMachineTemplate", func() {
+			Eventually(k.Object(mapiMachineSet), timeout).Should(SatisfyAll(
+				synchronized(),
+				HaveField("Spec.Replicas", Equal(ptr.To(int32(3)))),
+			))
+
+			providerSpec := mapiv1.PowerVSMachineProviderConfig{}
+			Expect(json.Unmarshal(mapiMachineSet.Spec.Template.Spec.ProviderSpec.Value.Raw, &providerSpec)).To(Succeed())
+
+			Expect(providerSpec).To(SatisfyAll(
+				HaveField("ServiceInstance.ID", Equal(ptr.To("service-instance-id"))),
+				HaveField("Image.ID", Equal(ptr.To("image-id"))),
+				HaveField("Network.ID", Equal(ptr.To("network-id"))),
+				HaveField("KeyPairName", Equal("ssh-key")),
+				HaveField("SystemType", Equal("s922")),
+				HaveField("MemoryGiB", Equal(int32(32))),
+			))
+		})
+	})
+})
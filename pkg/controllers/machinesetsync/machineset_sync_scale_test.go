@@ -0,0 +1,191 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinesetsync
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	capav1builder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/infrastructure/v1beta2"
+	configv1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/config/v1"
+	corev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/core/v1"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/config"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	"github.com/openshift/cluster-capi-operator/pkg/test/scale"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+)
+
+const (
+	// scaleSyncTimeout bounds how long the controller may take to synchronize the machine sets of a benchmark.
+	scaleSyncTimeout = 30 * time.Minute
+
+	scaleInfrastructureName = "cluster-foo"
+)
+
+// BenchmarkMachineSetSyncScale measures the machineset sync controller synchronizing scale.Size MAPI machine sets,
+// authoritative in the Machine API, to Cluster API, from its start until all their CAPI mirrors are created and its
+// work queue is drained.
+func BenchmarkMachineSetSyncScale(b *testing.B) {
+	size, err := scale.Size()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	steadyState, err := scale.SteadyState()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	cfg, cl := scale.StartEnvTest(b)
+	ctx := context.Background()
+
+	for range b.N {
+		b.StopTimer()
+
+		mapiNamespace, capiNamespace := createScaleMachineSets(ctx, b, cl, size)
+
+		counter := &scale.RequestCounter{}
+		reconciler := &MachineSetSyncReconciler{
+			Infra: configv1resourcebuilder.Infrastructure().
+				AsAWS("cluster", "us-east-1").WithInfrastructureName(scaleInfrastructureName).Build(),
+			Platform:      configv1.AWSPlatformType,
+			MAPINamespace: mapiNamespace,
+			CAPINamespace: capiNamespace,
+		}
+
+		mgr, err := ctrl.NewManager(counter.Instrument(cfg), ctrl.Options{
+			Metrics:    metricsserver.Options{BindAddress: "0"},
+			Controller: config.Controller{SkipNameValidation: ptr.To(true)},
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if err := reconciler.SetupWithManager(mgr); err != nil {
+			b.Fatal(err)
+		}
+
+		name := util.NamespacedControllerName("machineset", mapiNamespace)
+		heapBefore := scale.HeapInUse()
+
+		mgrCtx, mgrCancel := context.WithCancel(ctx)
+		mgrDone := make(chan error)
+
+		b.StartTimer()
+
+		start := time.Now()
+
+		go func() { mgrDone <- mgr.Start(mgrCtx) }()
+
+		if err := scale.WaitFor(ctx, scaleSyncTimeout, func(ctx context.Context) (bool, error) {
+			capiMachineSets := &capiv1beta1.MachineSetList{}
+			if err := cl.List(ctx, capiMachineSets, client.InNamespace(capiNamespace)); err != nil {
+				return false, fmt.Errorf("failed to list CAPI machine sets: %w", err)
+			}
+
+			depth, err := scale.QueueDepth(name)
+
+			return len(capiMachineSets.Items) == size && depth == 0, err
+		}); err != nil {
+			b.Fatal(err)
+		}
+
+		syncDuration := time.Since(start)
+
+		b.StopTimer()
+
+		reconciles, err := scale.ReconcileTotal(name)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		heapGrowth := int64(scale.HeapInUse()) - int64(heapBefore) //nolint:gosec
+
+		counter.Reset()
+		time.Sleep(steadyState)
+
+		scale.Result{
+			Resources:           size,
+			SyncDuration:        syncDuration,
+			Reconciles:          reconciles,
+			HeapGrowth:          heapGrowth,
+			SteadyStateRequests: counter.Reset(),
+			SteadyStateDuration: steadyState,
+		}.Report(b)
+
+		mgrCancel()
+
+		if err := <-mgrDone; err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// createScaleMachineSets creates a MAPI and a CAPI namespace, with size MAPI machine sets authoritative in the
+// Machine API and the AWSCluster their CAPI mirrors belong to, and returns the namespaces.
+func createScaleMachineSets(ctx context.Context, b *testing.B, cl client.Client, size int) (string, string) {
+	b.Helper()
+
+	mapiNamespace := corev1resourcebuilder.Namespace().WithGenerateName("openshift-machine-api-").Build()
+	capiNamespace := corev1resourcebuilder.Namespace().WithGenerateName("openshift-cluster-api-").Build()
+
+	for _, namespace := range []*corev1.Namespace{mapiNamespace, capiNamespace} {
+		if err := cl.Create(ctx, namespace); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	awsCluster := capav1builder.AWSCluster().WithNamespace(capiNamespace.GetName()).WithName(scaleInfrastructureName).Build()
+	if err := cl.Create(ctx, awsCluster); err != nil {
+		b.Fatal(err)
+	}
+
+	if err := scale.ForEach(ctx, size, func(ctx context.Context, i int) error {
+		name := fmt.Sprintf("machineset-%d", i)
+
+		mapiMachineSet := machinev1resourcebuilder.MachineSet().
+			WithNamespace(mapiNamespace.GetName()).
+			WithName(name).
+			WithProviderSpecBuilder(machinev1resourcebuilder.AWSProviderSpec().WithLoadBalancers(nil)).
+			Build()
+		if err := cl.Create(ctx, mapiMachineSet); err != nil {
+			return fmt.Errorf("failed to create MAPI machine set %s: %w", name, err)
+		}
+
+		mapiMachineSet.Status.AuthoritativeAPI = machinev1beta1.MachineAuthorityMachineAPI
+		if err := cl.Status().Update(ctx, mapiMachineSet); err != nil {
+			return fmt.Errorf("failed to set the authority of MAPI machine set %s: %w", name, err)
+		}
+
+		return nil
+	}); err != nil {
+		b.Fatal(err)
+	}
+
+	return mapiNamespace.GetName(), capiNamespace.GetName()
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinesetsync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/synccommon"
+	conversionutil "github.com/openshift/cluster-capi-operator/pkg/conversion/util"
+)
+
+const (
+	reasonScalingCarriedOver = "ScalingCarriedOver"
+)
+
+// syncScalingDuringMigration carries the scaling of the MachineSet that was authoritative before the migration over to
+// the one that will be authoritative after it.
+// Nothing else is synchronized while migrating, but the cluster autoscaler, or a user, may still scale the MachineSet
+// and, without this, the scaling would be reverted by the new authority once the migration completes.
+// The target of the migration is the authoritative API set in the MAPI MachineSet spec.
+func (r *MachineSetSyncReconciler) syncScalingDuringMigration(ctx context.Context, mapiMachineSet *machinev1beta1.MachineSet, capiMachineSet *capiv1beta1.MachineSet) error {
+	if capiMachineSet == nil {
+		// The CAPI machine set is created from the MAPI one, scaling included, once Cluster API is authoritative.
+		return nil
+	}
+
+	switch mapiMachineSet.Spec.AuthoritativeAPI {
+	case machinev1beta1.MachineAuthorityClusterAPI:
+		return r.carryScalingToCAPIMachineSet(ctx, mapiMachineSet, capiMachineSet)
+	case machinev1beta1.MachineAuthorityMachineAPI:
+		return r.carryScalingToMAPIMachineSet(ctx, capiMachineSet, mapiMachineSet)
+	default:
+		return nil
+	}
+}
+
+// carryScalingToCAPIMachineSet copies the replicas and the cluster autoscaler annotations of the MAPI machine set to the CAPI one.
+func (r *MachineSetSyncReconciler) carryScalingToCAPIMachineSet(ctx context.Context, mapiMachineSet *machinev1beta1.MachineSet, capiMachineSet *capiv1beta1.MachineSet) error {
+	logger := log.FromContext(ctx)
+
+	newCAPIMachineSet := capiMachineSet.DeepCopy()
+	if mapiMachineSet.Spec.Replicas != nil {
+		newCAPIMachineSet.Spec.Replicas = mapiMachineSet.Spec.Replicas
+	}
+
+	newCAPIMachineSet.Annotations = mergeAutoscalerAnnotations(capiMachineSet.Annotations,
+		conversionutil.ConvertMAPIAutoscalerAnnotationsToCAPI(mapiMachineSet.Annotations), conversionutil.IsCAPIAutoscalerAnnotation)
+
+	changedFields := synccommon.ChangedFields(capiMachineSet.Spec, newCAPIMachineSet.Spec, capiMachineSet.ObjectMeta, newCAPIMachineSet.ObjectMeta)
+	if len(changedFields) == 0 {
+		return nil
+	}
+
+	logger.Info("Carrying scaling over to CAPI machine set", "changedFields", changedFields)
+
+	if err := r.Patch(ctx, newCAPIMachineSet, client.MergeFrom(capiMachineSet)); err != nil {
+		return fmt.Errorf("failed to carry scaling over to CAPI machine set: %w", err)
+	}
+
+	r.recordSyncEvent(reasonScalingCarriedOver, fmt.Sprintf("Carried scaling over from MAPI to CAPI machine set during migration, changed fields: %s", strings.Join(changedFields, ", ")),
+		mapiMachineSet, capiMachineSet)
+
+	return nil
+}
+
+// carryScalingToMAPIMachineSet copies the replicas and the cluster autoscaler annotations of the CAPI machine set to the MAPI one.
+func (r *MachineSetSyncReconciler) carryScalingToMAPIMachineSet(ctx context.Context, capiMachineSet *capiv1beta1.MachineSet, mapiMachineSet *machinev1beta1.MachineSet) error {
+	logger := log.FromContext(ctx)
+
+	annotations, errs := conversionutil.ConvertCAPIAutoscalerAnnotationsToMAPI(field.NewPath("metadata", "annotations"), capiMachineSet.Annotations)
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to convert CAPI machine set autoscaler annotations: %w", errs.ToAggregate())
+	}
+
+	newMAPIMachineSet := mapiMachineSet.DeepCopy()
+	if capiMachineSet.Spec.Replicas != nil {
+		newMAPIMachineSet.Spec.Replicas = capiMachineSet.Spec.Replicas
+	}
+
+	newMAPIMachineSet.Annotations = mergeAutoscalerAnnotations(mapiMachineSet.Annotations, annotations, conversionutil.IsMAPIAutoscalerAnnotation)
+
+	changedFields := synccommon.ChangedFields(mapiMachineSet.Spec, newMAPIMachineSet.Spec, mapiMachineSet.ObjectMeta, newMAPIMachineSet.ObjectMeta)
+	if len(changedFields) == 0 {
+		return nil
+	}
+
+	logger.Info("Carrying scaling over to MAPI machine set", "changedFields", changedFields)
+
+	if err := r.Patch(ctx, newMAPIMachineSet, client.MergeFrom(mapiMachineSet)); err != nil {
+		return fmt.Errorf("failed to carry scaling over to MAPI machine set: %w", err)
+	}
+
+	r.recordSyncEvent(reasonScalingCarriedOver, fmt.Sprintf("Carried scaling over from CAPI to MAPI machine set during migration, changed fields: %s", strings.Join(changedFields, ", ")),
+		mapiMachineSet, capiMachineSet)
+
+	return nil
+}
+
+// mergeAutoscalerAnnotations returns a copy of the target annotations where the cluster autoscaler annotations,
+// as identified by isAutoscalerAnnotation, are replaced by those of the source annotations.
+func mergeAutoscalerAnnotations(target, source map[string]string, isAutoscalerAnnotation func(string) bool) map[string]string {
+	merged := map[string]string{}
+
+	for k, v := range target {
+		if !isAutoscalerAnnotation(k) {
+			merged[k] = v
+		}
+	}
+
+	for k, v := range source {
+		if isAutoscalerAnnotation(k) {
+			merged[k] = v
+		}
+	}
+
+	if len(merged) == 0 && target == nil {
+		return nil
+	}
+
+	return merged
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinesetsync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/synccommon"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	awscapiv1beta1 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
+	capibmv1 "sigs.k8s.io/cluster-api-provider-ibmcloud/api/v1beta2"
+	capov1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1beta1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// infraMachineTemplateHashLength is the length of the spec hash suffixed to the InfraMachineTemplate names.
+	infraMachineTemplateHashLength = 10
+
+	reasonDeletedCAPIInfraMachineTemplate = "DeletedCAPIInfraMachineTemplate"
+)
+
+// setInfraMachineTemplateName names the InfraMachineTemplate converted from a MAPI machine set after the machine set
+// and a hash of the template spec, and points the CAPI machine set at it.
+// Cluster API expects InfraMachineTemplates to be immutable, so a change of the providerSpec is rolled out with a new
+// template, as MachineDeployments do, rather than by updating the template the existing machines were created from.
+// The templates that are no longer referenced are removed by deleteStaleInfraMachineTemplates.
+func setInfraMachineTemplateName(mapiMachineSet *machinev1beta1.MachineSet, capiMachineSet *capiv1beta1.MachineSet, infraMachineTemplate client.Object) error {
+	hash, err := synccommon.SpecHash(infraMachineTemplate)
+	if err != nil {
+		return err
+	}
+
+	name := infraMachineTemplateName(mapiMachineSet.Name, hash[:infraMachineTemplateHashLength])
+
+	infraMachineTemplate.SetName(name)
+	// Copy the annotations as the converted objects may share their annotations map with the source object.
+	infraMachineTemplate.SetAnnotations(util.MergeMaps(infraMachineTemplate.GetAnnotations(), map[string]string{
+		consts.InfraMachineTemplateMachineSetAnnotation: mapiMachineSet.Name,
+	}))
+
+	capiMachineSet.Spec.Template.Spec.InfrastructureRef.Name = name
+
+	return nil
+}
+
+// infraMachineTemplateName returns the name of the InfraMachineTemplate of a machine set with the given spec hash.
+// The machine set name is truncated so that the name remains a valid DNS subdomain.
+func infraMachineTemplateName(machineSetName, hash string) string {
+	if maxPrefixLength := validation.DNS1123SubdomainMaxLength - len(hash) - 1; len(machineSetName) > maxPrefixLength {
+		machineSetName = strings.TrimRight(machineSetName[:maxPrefixLength], "-.")
+	}
+
+	return fmt.Sprintf("%s-%s", machineSetName, hash)
+}
+
+// isStaleInfraMachineTemplate returns true when the InfraMachineTemplate was created from the given MAPI machine set
+// and is not the one it currently references.
+// Templates created before they were named after their spec hash are named after the machine set, and may have no
+// annotation at all. These are recognised by the annotation recording their last synchronization, when they have it,
+// or by the CAPI machine set having referenced them before its update, previousName.
+func isStaleInfraMachineTemplate(mapiMachineSet *machinev1beta1.MachineSet, currentName, previousName string, infraMachineTemplate client.Object) bool {
+	if infraMachineTemplate.GetName() == currentName {
+		return false
+	}
+
+	annotations := infraMachineTemplate.GetAnnotations()
+
+	if machineSetName, ok := annotations[consts.InfraMachineTemplateMachineSetAnnotation]; ok {
+		return machineSetName == mapiMachineSet.Name
+	}
+
+	if infraMachineTemplate.GetName() != mapiMachineSet.Name {
+		return false
+	}
+
+	_, synced := annotations[consts.LastSyncTimeAnnotation]
+
+	return synced || infraMachineTemplate.GetName() == previousName
+}
+
+// deleteStaleInfraMachineTemplates deletes the InfraMachineTemplates created from the MAPI machine set that the CAPI
+// machine set no longer references, once it has been pointed at currentName from previousName.
+func (r *MachineSetSyncReconciler) deleteStaleInfraMachineTemplates(ctx context.Context, mapiMachineSet *machinev1beta1.MachineSet, currentName, previousName string) error {
+	logger := log.FromContext(ctx)
+
+	infraMachineTemplateList, err := getInfraMachineTemplateListFromProvider(r.Platform)
+	if err != nil {
+		return err
+	}
+
+	if err := r.List(ctx, infraMachineTemplateList, client.InNamespace(r.CAPINamespace)); err != nil {
+		return fmt.Errorf("failed to list CAPI infra machine templates: %w", err)
+	}
+
+	items, err := meta.ExtractList(infraMachineTemplateList)
+	if err != nil {
+		return fmt.Errorf("failed to extract CAPI infra machine templates: %w", err)
+	}
+
+	var errs []error
+
+	for _, item := range items {
+		infraMachineTemplate, ok := item.(client.Object)
+		if !ok || !isStaleInfraMachineTemplate(mapiMachineSet, currentName, previousName, infraMachineTemplate) {
+			continue
+		}
+
+		if err := r.Delete(ctx, infraMachineTemplate); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete CAPI infra machine template %s: %w", infraMachineTemplate.GetName(), err))
+			continue
+		}
+
+		logger.Info("Deleted stale CAPI infra machine template", "name", infraMachineTemplate.GetName())
+
+		r.recordSyncEvent(reasonDeletedCAPIInfraMachineTemplate,
+			fmt.Sprintf("Deleted CAPI infra machine template %s, replaced by %s", infraMachineTemplate.GetName(), currentName), mapiMachineSet)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// getInfraMachineTemplateListFromProvider returns the correct InfraMachineTemplate list implementation
+// for a given provider.
+func getInfraMachineTemplateListFromProvider(platform configv1.PlatformType) (client.ObjectList, error) {
+	switch platform {
+	case configv1.AWSPlatformType:
+		return &awscapiv1beta1.AWSMachineTemplateList{}, nil
+	case configv1.PowerVSPlatformType:
+		return &capibmv1.IBMPowerVSMachineTemplateList{}, nil
+	case configv1.OpenStackPlatformType:
+		return &capov1.OpenStackMachineTemplateList{}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", errPlatformNotSupported, platform)
+	}
+}
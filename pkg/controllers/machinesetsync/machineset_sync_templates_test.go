@@ -0,0 +1,106 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinesetsync
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	capiv1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/core/v1beta1"
+	capav1builder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/infrastructure/v1beta2"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+var _ = Describe("InfraMachineTemplate naming", func() {
+	mapiMachineSet := machinev1resourcebuilder.MachineSet().WithName("worker").Build()
+
+	Context("when naming the template converted from a machine set", func() {
+		It("should suffix the machine set name with a hash of the template spec and reference it", func() {
+			capiMachineSet := capiv1resourcebuilder.MachineSet().WithName("worker").Build()
+			template := capav1builder.AWSMachineTemplate().WithName("worker").WithInstanceType("m6i.large").Build()
+
+			Expect(setInfraMachineTemplateName(mapiMachineSet, capiMachineSet, template)).To(Succeed())
+
+			Expect(template.Name).To(MatchRegexp(`^worker-[0-9a-f]{10}$`))
+			Expect(template.Annotations).To(HaveKeyWithValue(consts.InfraMachineTemplateMachineSetAnnotation, "worker"))
+			Expect(capiMachineSet.Spec.Template.Spec.InfrastructureRef.Name).To(Equal(template.Name))
+		})
+
+		It("should only change the name when the template spec changes", func() {
+			name := func(instanceType string, labels map[string]string) string {
+				capiMachineSet := capiv1resourcebuilder.MachineSet().WithName("worker").Build()
+				template := capav1builder.AWSMachineTemplate().WithInstanceType(instanceType).WithLabels(labels).Build()
+
+				Expect(setInfraMachineTemplateName(mapiMachineSet, capiMachineSet, template)).To(Succeed())
+
+				return template.Name
+			}
+
+			Expect(name("m6i.large", nil)).To(Equal(name("m6i.large", map[string]string{"foo": "bar"})))
+			Expect(name("m6i.large", nil)).ToNot(Equal(name("m6i.xlarge", nil)))
+		})
+
+		It("should truncate long machine set names to a valid name", func() {
+			name := infraMachineTemplateName(strings.Repeat("a", 250)+"-.b", "0123456789")
+
+			Expect(name).To(HaveLen(validation.DNS1123SubdomainMaxLength))
+			Expect(validation.IsDNS1123Subdomain(name)).To(BeEmpty())
+
+			name = infraMachineTemplateName(strings.Repeat("a", 241)+"-.b", "0123456789")
+
+			Expect(name).To(Equal(strings.Repeat("a", 241) + "-0123456789"))
+			Expect(validation.IsDNS1123Subdomain(name)).To(BeEmpty())
+		})
+	})
+
+	Context("when looking for the stale templates of a machine set", func() {
+		DescribeTable("should only match the templates created from the machine set it no longer references",
+			func(name string, annotations map[string]string, expected bool) {
+				template := capav1builder.AWSMachineTemplate().WithName(name).WithAnnotations(annotations).Build()
+
+				Expect(isStaleInfraMachineTemplate(mapiMachineSet, "worker-0123456789", "worker-9876543210", template)).To(Equal(expected))
+			},
+			Entry("a previous template", "worker-9876543210",
+				map[string]string{consts.InfraMachineTemplateMachineSetAnnotation: "worker"}, true),
+			Entry("the current template", "worker-0123456789",
+				map[string]string{consts.InfraMachineTemplateMachineSetAnnotation: "worker"}, false),
+			Entry("a template of another machine set", "worker-infra-0123456789",
+				map[string]string{consts.InfraMachineTemplateMachineSetAnnotation: "worker-infra"}, false),
+			Entry("a synchronized template named after the machine set", "worker",
+				map[string]string{consts.LastSyncTimeAnnotation: "2024-01-01T00:00:00Z"}, true),
+			Entry("a template named after the machine set that was not synchronized", "worker", nil, false),
+			Entry("a template named after the machine set created from another one", "worker",
+				map[string]string{consts.InfraMachineTemplateMachineSetAnnotation: "other"}, false),
+		)
+
+		It("should match a legacy template without annotations that the CAPI machine set referenced", func() {
+			// The templates created before the synchronization was annotated are only named after the machine set.
+			template := capav1builder.AWSMachineTemplate().WithName("worker").Build()
+			Expect(template.Annotations).To(BeEmpty())
+
+			Expect(isStaleInfraMachineTemplate(mapiMachineSet, "worker-0123456789", "worker", template)).To(BeTrue())
+			Expect(isStaleInfraMachineTemplate(mapiMachineSet, "worker-0123456789", "worker-9876543210", template)).To(BeFalse(),
+				"should not match a template the CAPI machine set did not reference")
+			Expect(isStaleInfraMachineTemplate(mapiMachineSet, "worker-0123456789", "", template)).To(BeFalse(),
+				"should not match a template when the CAPI machine set did not exist")
+		})
+	})
+})
@@ -0,0 +1,42 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinesync
+
+import (
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/synccommon"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// backoffFieldOwner owns the degraded condition, separately from the other conditions set by the controller,
+	// so that applying them does not remove the degraded condition.
+	backoffFieldOwner = "machine-sync-controller-backoff"
+)
+
+// statusReporter returns the reporter of the paused and degraded synchronizations on the MAPI machines.
+func (r *MachineSyncReconciler) statusReporter() synccommon.StatusReporter {
+	return synccommon.StatusReporter{
+		Client:            r.Client,
+		Recorder:          r.Recorder,
+		Backoff:           r.Backoff,
+		Namespace:         r.MAPINamespace,
+		NewObject:         func() client.Object { return &machinev1beta1.Machine{} },
+		PauseFieldOwner:   pauseFieldOwner,
+		BackoffFieldOwner: backoffFieldOwner,
+	}
+}
@@ -20,28 +20,32 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	configv1 "github.com/openshift/api/config/v1"
 	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/tracing"
 	"github.com/openshift/cluster-capi-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	capav1beta2 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
 	capibmv1 "sigs.k8s.io/cluster-api-provider-ibmcloud/api/v1beta2"
+	capov1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1beta1"
 	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 const (
-	capiNamespace  string = "openshift-cluster-api"
-	mapiNamespace  string = "openshift-machine-api"
 	machineSetKind string = "MachineSet"
 	controllerName string = "MachineSyncController"
 )
@@ -61,6 +65,16 @@ type MachineSyncReconciler struct {
 	Platform      configv1.PlatformType
 	CAPINamespace string
 	MAPINamespace string
+
+	// MaxConcurrentReconciles is the maximum number of concurrent reconciles, defaults to 1.
+	MaxConcurrentReconciles int
+	// Shard restricts the reconciler to a subset of the resources, so that the work can be split across replicas.
+	Shard util.Shard
+	// Backoff delays the retries of the machines that failed to synchronize, defaults to util.DefaultBackoffConfig.
+	Backoff *util.Backoff
+	// DrainSettleTimeout is how long the completion of a migration waits for the drain of the Node of the machine
+	// to settle, defaults to DefaultDrainSettleTimeout.
+	DrainSettleTimeout time.Duration
 }
 
 // SetupWithManager sets the CoreClusterReconciler controller up with the given manager.
@@ -73,32 +87,49 @@ func (r *MachineSyncReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// Allow the namespaces to be set externally for test purposes, when not set,
 	// default to the production namespaces.
 	if r.CAPINamespace == "" {
-		r.CAPINamespace = capiNamespace
+		r.CAPINamespace = consts.DefaultManagedNamespace
 	}
 
 	if r.MAPINamespace == "" {
-		r.MAPINamespace = mapiNamespace
+		r.MAPINamespace = consts.DefaultMAPIManagedNamespace
+	}
+
+	if r.Backoff == nil {
+		r.Backoff = util.NewBackoff(util.DefaultBackoffConfig)
+	}
+
+	if r.DrainSettleTimeout == 0 {
+		r.DrainSettleTimeout = DefaultDrainSettleTimeout
 	}
 
 	if err := ctrl.NewControllerManagedBy(mgr).
-		Named(controllerName).
-		For(&machinev1beta1.Machine{}, builder.WithPredicates(util.FilterNamespace(r.MAPINamespace))).
+		Named(util.NamespacedControllerName(controllerName, r.MAPINamespace)).
+		For(&machinev1beta1.Machine{}, builder.WithPredicates(util.FilterNamespace(r.MAPINamespace), util.FilterShard(r.Shard))).
 		Watches(
 			&capiv1beta1.Machine{},
 			handler.EnqueueRequestsFromMapFunc(util.RewriteNamespace(r.MAPINamespace)),
-			builder.WithPredicates(util.FilterNamespace(r.CAPINamespace)),
+			builder.WithPredicates(util.FilterNamespace(r.CAPINamespace), util.FilterShard(r.Shard)),
 		).
 		Watches(
 			infraMachine,
 			handler.EnqueueRequestsFromMapFunc(util.RewriteNamespace(r.MAPINamespace)),
-			builder.WithPredicates(util.FilterNamespace(r.CAPINamespace)),
+			builder.WithPredicates(util.FilterNamespace(r.CAPINamespace), util.FilterShard(r.Shard)),
 		).
+		Watches(
+			&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(r.mapNamespaceToMachines),
+			builder.WithPredicates(util.FilterSyncPauseChanges(r.MAPINamespace)),
+		).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Complete(r); err != nil {
 		return fmt.Errorf("failed to create controller: %w", err)
 	}
 
-	// Set up API helpers from the manager.
-	r.Client = mgr.GetClient()
+	// Set up API helpers from the manager, the client may be set to rate limit the sync controllers apart.
+	if r.Client == nil {
+		r.Client = mgr.GetClient()
+	}
+
 	r.Scheme = mgr.GetScheme()
 	r.Recorder = mgr.GetEventRecorderFor("machine-sync-controller")
 
@@ -106,16 +137,41 @@ func (r *MachineSyncReconciler) SetupWithManager(mgr ctrl.Manager) error {
 }
 
 // Reconcile reconciles CAPI and MAPI machines for their respective namespaces.
-//
-//nolint:funlen
 func (r *MachineSyncReconciler) Reconcile(ctx context.Context, req reconcile.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx, "namespace", req.Namespace, "name", req.Name)
+	ctx = log.IntoContext(ctx, logger)
 
 	logger.V(1).Info("Reconciling machine")
 	defer logger.V(1).Info("Finished reconciling machine")
 
+	paused, err := util.IsSyncPaused(ctx, r.Client, r.MAPINamespace)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to check whether the synchronization is paused: %w", err)
+	}
+
+	// The backoff is left untouched while paused, so that the retry budget of the machines is kept across the pause.
+	if err := r.statusReporter().SyncPausedCondition(ctx, req.Name, paused); err != nil {
+		return ctrl.Result{}, err
+	} else if paused {
+		logger.Info("Synchronization is paused, not reconciling machine")
+		return ctrl.Result{}, nil
+	}
+
+	ctx, span := tracing.Start(ctx, "MachineSync", tracing.ObjectAttributes(req.Namespace, req.Name)...)
+	result, err := r.reconcile(ctx, logger, req)
+	tracing.End(span, err)
+
+	return r.statusReporter().ResultWithBackoff(ctx, req.Name, result, err)
+}
+
+// reconcile fetches the MAPI and CAPI machines and synchronizes them.
+//
+//nolint:funlen
+func (r *MachineSyncReconciler) reconcile(ctx context.Context, logger logr.Logger, req reconcile.Request) (ctrl.Result, error) {
 	var mapiMachineNotFound, capiMachineNotFound bool
 
+	_, fetchSpan := tracing.Start(ctx, tracing.PhaseFetch)
+
 	// Get the MAPI Machine.
 	mapiMachine := &machinev1beta1.Machine{}
 	mapiNamespacedName := client.ObjectKey{
@@ -129,6 +185,8 @@ func (r *MachineSyncReconciler) Reconcile(ctx context.Context, req reconcile.Req
 		mapiMachineNotFound = true
 	} else if err != nil {
 		logger.Error(err, "Failed to get MAPI Machine")
+		tracing.End(fetchSpan, err)
+
 		return ctrl.Result{}, fmt.Errorf("failed to get MAPI machine: %w", err)
 	}
 
@@ -145,14 +203,81 @@ func (r *MachineSyncReconciler) Reconcile(ctx context.Context, req reconcile.Req
 		capiMachineNotFound = true
 	} else if err != nil {
 		logger.Error(err, "Failed to get CAPI Machine")
+		tracing.End(fetchSpan, err)
+
 		return ctrl.Result{}, fmt.Errorf("failed to get CAPI machine:: %w", err)
 	}
 
 	if mapiMachineNotFound && capiMachineNotFound {
 		logger.Info("CAPI and MAPI machines not found, nothing to do")
+		tracing.End(fetchSpan, nil)
+
 		return ctrl.Result{}, nil
 	}
 
+	// A machine recreated under another name, for instance when restoring from a backup, is still backed by the
+	// instance of its previous copy. Its counterpart is then matched by providerID and adopted, rather than duplicated.
+	switch {
+	case capiMachineNotFound:
+		counterpart, err := r.findCAPIMachineByProviderID(ctx, mapiMachine)
+		if err != nil {
+			tracing.End(fetchSpan, err)
+			return ctrl.Result{}, err
+		}
+
+		if counterpart != nil {
+			r.recordCounterpartAdoption(logger, mapiMachine, counterpart)
+			capiMachine, capiMachineNotFound = counterpart, false
+		}
+	case mapiMachineNotFound:
+		counterpart, err := r.findMAPIMachineByProviderID(ctx, capiMachine)
+		if err != nil {
+			tracing.End(fetchSpan, err)
+			return ctrl.Result{}, err
+		}
+
+		if counterpart != nil {
+			r.recordCounterpartAdoption(logger, capiMachine, counterpart)
+			mapiMachine, mapiMachineNotFound = counterpart, false
+		}
+	}
+
+	infraMachine, infraMachineNotFound, err := r.getInfraMachine(ctx, req.Name, capiMachine, capiMachineNotFound)
+	tracing.End(fetchSpan, err)
+
+	if err != nil {
+		logger.Error(err, "Failed to get InfraMachine")
+		return ctrl.Result{}, err
+	}
+
+	// Use nil for the machines that were not found, so the deletion can tell a missing copy from an empty one.
+	var existingMAPIMachine *machinev1beta1.Machine
+	if !mapiMachineNotFound {
+		existingMAPIMachine = mapiMachine
+	}
+
+	var existingCAPIMachine *capiv1beta1.Machine
+	if !capiMachineNotFound {
+		existingCAPIMachine = capiMachine
+	}
+
+	if infraMachineNotFound {
+		infraMachine = nil
+	}
+
+	_, deletionSpan := tracing.Start(ctx, tracing.PhaseApply)
+	deleting, result, err := r.reconcileDeletion(ctx, logger, existingMAPIMachine, existingCAPIMachine, infraMachine)
+	tracing.End(deletionSpan, err)
+
+	if err != nil {
+		logger.Error(err, "Failed to reconcile machine deletion")
+		return ctrl.Result{}, err
+	}
+
+	if deleting {
+		return result, nil
+	}
+
 	// We mirror if the CAPI machine is owned by a MachineSet which has a MAPI
 	// counterpart. This is because we want to be able to migrate in both directions.
 	if mapiMachineNotFound {
@@ -169,7 +294,25 @@ func (r *MachineSyncReconciler) Reconcile(ctx context.Context, req reconcile.Req
 	case machinev1beta1.MachineAuthorityClusterAPI:
 		return r.reconcileCAPIMachinetoMAPIMachine(ctx, capiMachine, mapiMachine)
 	case machinev1beta1.MachineAuthorityMigrating:
+		// The authority is only handed over once every copy agrees on the instance backing the machine.
+		if err := r.validateProviderIDs(ctx, mapiMachine, existingCAPIMachine, infraMachine); err != nil {
+			if errors.Is(err, errProviderIDMismatch) {
+				r.Recorder.Event(mapiMachine, corev1.EventTypeWarning, consts.ReasonProviderIDMismatch, err.Error())
+			}
+
+			return ctrl.Result{}, fmt.Errorf("refusing to complete the migration of machine %q: %w", mapiMachine.GetName(), err)
+		}
+
+		// Nor while the Node of the machine is being drained, so that it is not drained twice or left cordoned.
+		if proceed, result, err := r.waitForDrain(ctx, mapiMachine, existingCAPIMachine); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to check the drain of machine %q: %w", mapiMachine.GetName(), err)
+		} else if !proceed {
+			logger.Info("machine currently migrating, waiting for its drain to settle", "machine", mapiMachine.GetName())
+			return result, nil
+		}
+
 		logger.Info("machine currently migrating", "machine", mapiMachine.GetName())
+
 		return ctrl.Result{}, nil
 	default:
 		logger.Info("machine AuthoritativeAPI has unexpected value", "AuthoritativeAPI", mapiMachine.Status.AuthoritativeAPI)
@@ -197,11 +340,34 @@ func getInfraMachineFromProvider(platform configv1.PlatformType) (client.Object,
 		return &capav1beta2.AWSMachine{}, nil
 	case configv1.PowerVSPlatformType:
 		return &capibmv1.IBMPowerVSMachine{}, nil
+	case configv1.OpenStackPlatformType:
+		return &capov1.OpenStackMachine{}, nil
 	default:
 		return nil, fmt.Errorf("%w: %s", errPlatformNotSupported, platform)
 	}
 }
 
+// getInfraMachine returns the InfraMachine of the CAPI Machine, and whether it was not found.
+// When the CAPI Machine is not found, the InfraMachine is looked up by the Machine name, which mirrors share.
+func (r *MachineSyncReconciler) getInfraMachine(ctx context.Context, name string, capiMachine *capiv1beta1.Machine, capiMachineNotFound bool) (client.Object, bool, error) {
+	infraMachine, err := getInfraMachineFromProvider(r.Platform)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get InfraMachine from Provider: %w", err)
+	}
+
+	if !capiMachineNotFound && capiMachine.Spec.InfrastructureRef.Name != "" {
+		name = capiMachine.Spec.InfrastructureRef.Name
+	}
+
+	if err := r.Get(ctx, client.ObjectKey{Namespace: r.CAPINamespace, Name: name}, infraMachine); apierrors.IsNotFound(err) {
+		return infraMachine, true, nil
+	} else if err != nil {
+		return nil, false, fmt.Errorf("failed to get InfraMachine: %w", err)
+	}
+
+	return infraMachine, false, nil
+}
+
 // shouldMirrorCAPIMachineToMAPIMachine takes a CAPI machine and determines if there should
 // be a MAPI mirror, it returns true only if:
 //
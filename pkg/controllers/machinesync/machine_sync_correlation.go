@@ -0,0 +1,139 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinesync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-capi-operator/pkg/errorsx"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/ptr"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const reasonCounterpartAdopted = "CounterpartAdopted"
+
+// errAmbiguousCounterpart is returned when several machines without a counterpart share the providerID of a machine.
+// It is returned as an errorsx.ErrAuthorityConflict, as retrying does not help until the duplicates are removed.
+var errAmbiguousCounterpart = errors.New("several machines share the providerID")
+
+// findCAPIMachineByProviderID returns the CAPI Machine backed by the same instance as the MAPI Machine, under another
+// name, for instance when the MAPI Machine was recreated from a backup under a new name.
+// Only a CAPI Machine without a MAPI counterpart of its own name is returned, nil when there is none.
+func (r *MachineSyncReconciler) findCAPIMachineByProviderID(ctx context.Context, mapiMachine *machinev1beta1.Machine) (*capiv1beta1.Machine, error) {
+	providerID := ptr.Deref(mapiMachine.Spec.ProviderID, "")
+	if providerID == "" {
+		return nil, nil
+	}
+
+	capiMachines := &capiv1beta1.MachineList{}
+	if err := r.List(ctx, capiMachines, client.InNamespace(r.CAPINamespace)); err != nil {
+		return nil, fmt.Errorf("failed to list CAPI machines: %w", err)
+	}
+
+	candidates := []client.Object{}
+
+	for i := range capiMachines.Items {
+		if m := &capiMachines.Items[i]; m.GetName() != mapiMachine.GetName() && ptr.Deref(m.Spec.ProviderID, "") == providerID {
+			candidates = append(candidates, m)
+		}
+	}
+
+	counterpart, err := r.orphanCounterpart(ctx, candidates, r.MAPINamespace, &machinev1beta1.Machine{})
+	if counterpart == nil || err != nil {
+		return nil, err
+	}
+
+	return counterpart.(*capiv1beta1.Machine), nil //nolint:forcetypeassert
+}
+
+// findMAPIMachineByProviderID returns the MAPI Machine backed by the same instance as the CAPI Machine, under another
+// name. Only a MAPI Machine without a CAPI counterpart of its own name is returned, nil when there is none.
+func (r *MachineSyncReconciler) findMAPIMachineByProviderID(ctx context.Context, capiMachine *capiv1beta1.Machine) (*machinev1beta1.Machine, error) {
+	providerID := ptr.Deref(capiMachine.Spec.ProviderID, "")
+	if providerID == "" {
+		return nil, nil
+	}
+
+	mapiMachines := &machinev1beta1.MachineList{}
+	if err := r.List(ctx, mapiMachines, client.InNamespace(r.MAPINamespace)); err != nil {
+		return nil, fmt.Errorf("failed to list MAPI machines: %w", err)
+	}
+
+	candidates := []client.Object{}
+
+	for i := range mapiMachines.Items {
+		if m := &mapiMachines.Items[i]; m.GetName() != capiMachine.GetName() && ptr.Deref(m.Spec.ProviderID, "") == providerID {
+			candidates = append(candidates, m)
+		}
+	}
+
+	counterpart, err := r.orphanCounterpart(ctx, candidates, r.CAPINamespace, &capiv1beta1.Machine{})
+	if counterpart == nil || err != nil {
+		return nil, err
+	}
+
+	return counterpart.(*machinev1beta1.Machine), nil //nolint:forcetypeassert
+}
+
+// orphanCounterpart returns the only candidate without a counterpart of its own name in the given namespace, nil when
+// there is none. The candidates with a counterpart of their own name are already paired, adopting them would pair two
+// machines with the same one.
+func (r *MachineSyncReconciler) orphanCounterpart(ctx context.Context, candidates []client.Object, namespace string, counterpart client.Object) (client.Object, error) {
+	orphans := []client.Object{}
+
+	for _, candidate := range candidates {
+		if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: candidate.GetName()}, counterpart); apierrors.IsNotFound(err) {
+			orphans = append(orphans, candidate)
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to get the counterpart of %s: %w", describe(candidate), err)
+		}
+	}
+
+	switch len(orphans) {
+	case 0:
+		return nil, nil
+	case 1:
+		return orphans[0], nil
+	default:
+		names := make([]string, 0, len(orphans))
+		for _, orphan := range orphans {
+			names = append(names, describe(orphan))
+		}
+
+		return nil, errorsx.AuthorityConflict(fmt.Errorf("%w: %s", errAmbiguousCounterpart, strings.Join(names, ", ")))
+	}
+}
+
+// recordCounterpartAdoption logs and records on the machine that its counterpart was matched by providerID.
+func (r *MachineSyncReconciler) recordCounterpartAdoption(logger logr.Logger, obj client.Object, counterpart client.Object) {
+	logger.Info("Adopting counterpart with the same providerID", "counterpart", describe(counterpart))
+
+	if r.Recorder == nil {
+		return
+	}
+
+	r.Recorder.Event(obj, corev1.EventTypeNormal, reasonCounterpartAdopted,
+		fmt.Sprintf("Adopted %s, backed by the same instance, as counterpart", describe(counterpart)))
+}
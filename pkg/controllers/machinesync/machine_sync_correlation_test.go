@@ -0,0 +1,105 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinesync
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("MachineSync Reconciler providerID correlation", func() {
+	const (
+		providerID      = "aws:///us-east-1a/i-0123456789abcdef0"
+		otherProviderID = "aws:///us-east-1a/i-0fedcba9876543210"
+	)
+
+	var reconciler *MachineSyncReconciler
+
+	mapiMachine := func(name, providerID string) *machinev1beta1.Machine {
+		return &machinev1beta1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: consts.DefaultMAPIManagedNamespace, Name: name},
+			Spec:       machinev1beta1.MachineSpec{ProviderID: ptr.To(providerID)},
+		}
+	}
+
+	capiMachine := func(name, providerID string) *capiv1beta1.Machine {
+		return &capiv1beta1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: consts.DefaultManagedNamespace, Name: name},
+			Spec:       capiv1beta1.MachineSpec{ProviderID: ptr.To(providerID)},
+		}
+	}
+
+	withObjects := func(objs ...client.Object) {
+		scheme := runtime.NewScheme()
+		Expect(machinev1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(capiv1beta1.AddToScheme(scheme)).To(Succeed())
+
+		reconciler.Client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	}
+
+	BeforeEach(func() {
+		reconciler = &MachineSyncReconciler{MAPINamespace: consts.DefaultMAPIManagedNamespace, CAPINamespace: consts.DefaultManagedNamespace}
+	})
+
+	It("should match the CAPI machine of a MAPI machine recreated under another name", func() {
+		withObjects(capiMachine("old", providerID), capiMachine("other", otherProviderID))
+
+		counterpart, err := reconciler.findCAPIMachineByProviderID(ctx, mapiMachine("new", providerID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(counterpart).ToNot(BeNil())
+		Expect(counterpart.GetName()).To(Equal("old"))
+	})
+
+	It("should match the MAPI machine of a CAPI machine recreated under another name", func() {
+		withObjects(mapiMachine("old", providerID))
+
+		counterpart, err := reconciler.findMAPIMachineByProviderID(ctx, capiMachine("new", providerID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(counterpart).ToNot(BeNil())
+		Expect(counterpart.GetName()).To(Equal("old"))
+	})
+
+	It("should not match a machine already paired with a counterpart of its own name", func() {
+		withObjects(capiMachine("old", providerID), mapiMachine("old", providerID))
+
+		Expect(reconciler.findCAPIMachineByProviderID(ctx, mapiMachine("new", providerID))).To(BeNil())
+	})
+
+	It("should not match anything for a machine without a providerID", func() {
+		withObjects(capiMachine("old", ""))
+
+		machine := mapiMachine("new", "")
+		machine.Spec.ProviderID = nil
+
+		Expect(reconciler.findCAPIMachineByProviderID(ctx, machine)).To(BeNil())
+	})
+
+	It("should fail when several machines share the providerID", func() {
+		withObjects(capiMachine("old", providerID), capiMachine("older", providerID))
+
+		_, err := reconciler.findCAPIMachineByProviderID(ctx, mapiMachine("new", providerID))
+		Expect(err).To(MatchError(errAmbiguousCounterpart))
+	})
+})
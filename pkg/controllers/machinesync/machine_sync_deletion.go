@@ -0,0 +1,351 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinesync
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+)
+
+const (
+	// mapiExcludeNodeDrainingAnnotation skips the Node drain when set on a MAPI Machine.
+	mapiExcludeNodeDrainingAnnotation = "machine.openshift.io/exclude-node-draining"
+
+	reasonDeletionPropagated = "DeletionPropagated"
+	reasonMirrorReleased     = "MirrorReleased"
+)
+
+// reconcileDeletion coordinates the deletion of a MAPI Machine and of its CAPI counterpart.
+// The machines are nil when not found.
+// It returns true when a deletion is in progress, in which case the machines must not be synchronized,
+// and when the Machine should be reconciled again.
+//
+// The deletion goes through the following states:
+//  1. Neither copy is deleted: the SyncFinalizer is kept on both copies.
+//  2. One copy is deleted: the deletion is propagated to the other copy, carrying over the drain annotation.
+//  3. Both copies are deleted: the authoritative controller drains the Node, runs the lifecycle hooks and
+//     removes the instance, then removes its own finalizer.
+//  4. The authoritative copy only has the SyncFinalizer left: the paused mirror finalizers are removed,
+//     as its controller never acts on it, and the SyncFinalizer is removed from both copies.
+func (r *MachineSyncReconciler) reconcileDeletion(ctx context.Context, logger logr.Logger, mapiMachine *machinev1beta1.Machine, capiMachine *capiv1beta1.Machine, infraMachine client.Object) (bool, ctrl.Result, error) {
+	switch {
+	case isDeleting(mapiMachine) || isDeleting(capiMachine):
+		return true, ctrl.Result{}, r.reconcileMachineDeletion(ctx, logger, mapiMachine, capiMachine, infraMachine)
+	case isDeleting(infraMachine):
+		result, err := r.reconcileInfraMachineDeletion(ctx, logger, mapiMachine, capiMachine, infraMachine)
+		return true, result, err
+	default:
+		if !isSynchronized(mapiMachine, capiMachine) {
+			return false, ctrl.Result{}, nil
+		}
+
+		// The InfraMachine whose removal was blocked is gone, or was never deleted.
+		if err := r.resetInfraMachineRecreationBlocked(ctx, mapiMachine); err != nil {
+			return false, ctrl.Result{}, err
+		}
+
+		return false, ctrl.Result{}, r.ensureSyncFinalizers(ctx, mapiMachine, capiMachine)
+	}
+}
+
+// ensureSyncFinalizers sets the SyncFinalizer on both copies of the Machine.
+// A Machine without a counterpart has nothing to coordinate with, so the finalizer is removed from it instead.
+func (r *MachineSyncReconciler) ensureSyncFinalizers(ctx context.Context, mapiMachine *machinev1beta1.Machine, capiMachine *capiv1beta1.Machine) error {
+	paired := mapiMachine != nil && capiMachine != nil
+
+	for _, machine := range []client.Object{mapiMachine, capiMachine} {
+		if isNil(machine) {
+			continue
+		}
+
+		if err := r.patchFinalizers(ctx, machine, func(obj client.Object) {
+			if paired {
+				controllerutil.AddFinalizer(obj, consts.SyncFinalizer)
+			} else {
+				controllerutil.RemoveFinalizer(obj, consts.SyncFinalizer)
+			}
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reconcileMachineDeletion propagates the deletion of a copy of the Machine to its counterpart,
+// then releases both copies once the authoritative controller is done with the deletion.
+func (r *MachineSyncReconciler) reconcileMachineDeletion(ctx context.Context, logger logr.Logger, mapiMachine *machinev1beta1.Machine, capiMachine *capiv1beta1.Machine, infraMachine client.Object) error {
+	// Propagate the deletion first, so that both copies agree the Machine is going away
+	// before the authoritative controller starts draining the Node.
+	if mapiMachine != nil && !isDeleting(mapiMachine) {
+		logger.Info("CAPI machine is being deleted, deleting MAPI machine")
+
+		return r.propagateDeletion(ctx, mapiMachine, mapiExcludeNodeDrainingAnnotation, capiMachine, capiv1beta1.ExcludeNodeDrainingAnnotation)
+	}
+
+	if capiMachine != nil && !isDeleting(capiMachine) {
+		logger.Info("MAPI machine is being deleted, deleting CAPI machine")
+
+		return r.propagateDeletion(ctx, capiMachine, capiv1beta1.ExcludeNodeDrainingAnnotation, mapiMachine, mapiExcludeNodeDrainingAnnotation)
+	}
+
+	switch authority := machineAuthority(mapiMachine, capiMachine); authority {
+	case machinev1beta1.MachineAuthorityMachineAPI:
+		if !isDeletionComplete(mapiMachine) {
+			logger.Info("Waiting for the MAPI machine deletion to complete", "finalizers", mapiMachine.GetFinalizers())
+			return nil
+		}
+
+		if err := r.releaseMirror(ctx, logger, infraMachine, capiMachine); err != nil {
+			return err
+		}
+
+		return r.removeSyncFinalizer(ctx, mapiMachine)
+	case machinev1beta1.MachineAuthorityClusterAPI:
+		if !isDeletionComplete(capiMachine) {
+			logger.Info("Waiting for the CAPI machine deletion to complete", "finalizers", capiMachine.GetFinalizers())
+			return nil
+		}
+
+		if err := r.releaseMirror(ctx, logger, mapiMachine); err != nil {
+			return err
+		}
+
+		return r.removeSyncFinalizer(ctx, capiMachine)
+	case machinev1beta1.MachineAuthorityMigrating:
+		// Neither controller acts on the Machine until the migration completes, releasing either copy
+		// now could leak the instance.
+		logger.Info("Waiting for the machine migration to complete before completing the deletion")
+		return nil
+	default:
+		// The Machine is not synchronized, only get out of the way of its own controllers.
+		logger.Info("Machine AuthoritativeAPI has unexpected value, removing the sync finalizer", "AuthoritativeAPI", authority)
+
+		if err := r.removeSyncFinalizer(ctx, mapiMachine); err != nil {
+			return err
+		}
+
+		return r.removeSyncFinalizer(ctx, capiMachine)
+	}
+}
+
+// reconcileInfraMachineDeletion handles the InfraMachine being deleted while neither copy of the Machine is.
+func (r *MachineSyncReconciler) reconcileInfraMachineDeletion(ctx context.Context, logger logr.Logger, mapiMachine *machinev1beta1.Machine, capiMachine *capiv1beta1.Machine, infraMachine client.Object) (ctrl.Result, error) {
+	switch authority := machineAuthority(mapiMachine, capiMachine); authority {
+	case machinev1beta1.MachineAuthorityMachineAPI:
+		// The InfraMachine belongs to the paused CAPI mirror and the instance belongs to the MAPI Machine,
+		// so the provider must not act on its deletion. Let it go, it is recreated by the next synchronization.
+		logger.Info("InfraMachine of the CAPI mirror is being deleted, releasing it")
+
+		return r.releaseInfraMachine(ctx, logger, mapiMachine, infraMachine)
+	case machinev1beta1.MachineAuthorityClusterAPI:
+		if capiMachine == nil {
+			return ctrl.Result{}, nil
+		}
+
+		// The provider is terminating the instance backing the Machine, which cannot recover from it.
+		// Delete the Machine so that the Node is drained and the deletion is propagated to the MAPI copy.
+		logger.Info("InfraMachine is being deleted, deleting CAPI machine")
+
+		return ctrl.Result{}, r.deleteWithEvent(ctx, capiMachine, "Deleted as its InfraMachine is being deleted")
+	default:
+		logger.Info("Waiting for the machine authority to settle before handling the InfraMachine deletion", "authoritativeAPI", authority)
+		return ctrl.Result{}, nil
+	}
+}
+
+// propagateDeletion deletes obj as its counterpart is being deleted, first copying the drain annotation
+// from the counterpart. The annotation is copied as the authoritative controller is the one draining the Node,
+// whichever copy the user annotated and deleted.
+func (r *MachineSyncReconciler) propagateDeletion(ctx context.Context, obj client.Object, drainAnnotation string, deleted client.Object, deletedDrainAnnotation string) error {
+	_, alreadySet := obj.GetAnnotations()[drainAnnotation]
+	if value, ok := getAnnotation(deleted, deletedDrainAnnotation); ok && !alreadySet {
+		patchBase := client.MergeFrom(obj.DeepCopyObject().(client.Object)) //nolint:forcetypeassert
+
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+
+		annotations[drainAnnotation] = value
+		obj.SetAnnotations(annotations)
+
+		if err := r.Patch(ctx, obj, patchBase); err != nil {
+			return fmt.Errorf("failed to copy drain annotation to %s: %w", describe(obj), err)
+		}
+	}
+
+	return r.deleteWithEvent(ctx, obj, "Deleted as its counterpart is being deleted")
+}
+
+// deleteWithEvent deletes the object and records why on it.
+func (r *MachineSyncReconciler) deleteWithEvent(ctx context.Context, obj client.Object, message string) error {
+	if err := r.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete %s: %w", describe(obj), err)
+	}
+
+	r.recordDeletionEvent(obj, reasonDeletionPropagated, message)
+
+	return nil
+}
+
+// releaseMirror removes every finalizer from the given non-authoritative objects, deleting them first if needed.
+// The controllers of mirrors are paused, so nothing else removes their finalizers.
+func (r *MachineSyncReconciler) releaseMirror(ctx context.Context, logger logr.Logger, objs ...client.Object) error {
+	for _, obj := range objs {
+		if isNil(obj) {
+			continue
+		}
+
+		if !isDeleting(obj) {
+			if err := r.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete %s: %w", describe(obj), err)
+			}
+		}
+
+		if len(obj.GetFinalizers()) == 0 {
+			continue
+		}
+
+		logger.Info("Releasing mirror", "object", describe(obj), "finalizers", obj.GetFinalizers())
+
+		if err := r.patchFinalizers(ctx, obj, func(o client.Object) { o.SetFinalizers(nil) }); err != nil {
+			return err
+		}
+
+		r.recordDeletionEvent(obj, reasonMirrorReleased, "Finalizers removed as the authoritative copy has been deleted")
+	}
+
+	return nil
+}
+
+// removeSyncFinalizer removes the SyncFinalizer from the object, if present.
+func (r *MachineSyncReconciler) removeSyncFinalizer(ctx context.Context, obj client.Object) error {
+	if isNil(obj) {
+		return nil
+	}
+
+	return r.patchFinalizers(ctx, obj, func(o client.Object) {
+		controllerutil.RemoveFinalizer(o, consts.SyncFinalizer)
+	})
+}
+
+// patchFinalizers applies mutate to the object finalizers and patches the object when they changed.
+func (r *MachineSyncReconciler) patchFinalizers(ctx context.Context, obj client.Object, mutate func(client.Object)) error {
+	original := obj.DeepCopyObject().(client.Object) //nolint:forcetypeassert
+
+	mutate(obj)
+
+	if reflect.DeepEqual(original.GetFinalizers(), obj.GetFinalizers()) {
+		return nil
+	}
+
+	// The optimistic lock makes sure finalizers added concurrently by other controllers are not dropped.
+	if err := r.Patch(ctx, obj, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to update finalizers of %s: %w", describe(obj), err)
+	}
+
+	return nil
+}
+
+// recordDeletionEvent records an event on the object, when the reconciler has an event recorder.
+func (r *MachineSyncReconciler) recordDeletionEvent(obj client.Object, reason, message string) {
+	if r.Recorder == nil {
+		return
+	}
+
+	r.Recorder.Event(obj, corev1.EventTypeNormal, reason, message)
+}
+
+// machineAuthority returns the authoritative API of the Machine.
+// Once the MAPI Machine is gone, a paused CAPI Machine that was paired with it is the leftover mirror
+// of a MAPI authoritative Machine.
+func machineAuthority(mapiMachine *machinev1beta1.Machine, capiMachine *capiv1beta1.Machine) machinev1beta1.MachineAuthority {
+	if mapiMachine != nil {
+		return mapiMachine.Status.AuthoritativeAPI
+	}
+
+	if _, paused := getAnnotation(capiMachine, capiv1beta1.PausedAnnotation); paused && controllerutil.ContainsFinalizer(capiMachine, consts.SyncFinalizer) {
+		return machinev1beta1.MachineAuthorityMachineAPI
+	}
+
+	return machinev1beta1.MachineAuthorityClusterAPI
+}
+
+// isSynchronized returns true when the Machine has a known authoritative API, and is therefore handled by the sync controller.
+func isSynchronized(mapiMachine *machinev1beta1.Machine, capiMachine *capiv1beta1.Machine) bool {
+	switch machineAuthority(mapiMachine, capiMachine) {
+	case machinev1beta1.MachineAuthorityMachineAPI, machinev1beta1.MachineAuthorityClusterAPI, machinev1beta1.MachineAuthorityMigrating:
+		return true
+	default:
+		return false
+	}
+}
+
+// isDeletionComplete returns true when the authoritative controller is done with the deletion of the object,
+// that is when no finalizer other than the SyncFinalizer is left on it.
+func isDeletionComplete(obj client.Object) bool {
+	if isNil(obj) {
+		return true
+	}
+
+	for _, finalizer := range obj.GetFinalizers() {
+		if finalizer != consts.SyncFinalizer {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isDeleting returns true when the object exists and is being deleted.
+func isDeleting(obj client.Object) bool {
+	return !isNil(obj) && !obj.GetDeletionTimestamp().IsZero()
+}
+
+// getAnnotation returns the value of the annotation on the object, if the object exists and has it.
+func getAnnotation(obj client.Object, key string) (string, bool) {
+	if isNil(obj) || key == "" {
+		return "", false
+	}
+
+	value, ok := obj.GetAnnotations()[key]
+
+	return value, ok
+}
+
+// describe returns a human readable reference to the object for errors and logs.
+func describe(obj client.Object) string {
+	return fmt.Sprintf("%T %s", obj, client.ObjectKeyFromObject(obj))
+}
+
+// isNil returns true when obj is nil, or a typed nil pointer.
+func isNil(obj client.Object) bool {
+	return obj == nil || reflect.ValueOf(obj).IsNil()
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinesync
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	capiv1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/core/v1beta1"
+	capav1builder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/infrastructure/v1beta2"
+	corev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/core/v1"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	capav1beta2 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/envtest/komega"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/test"
+)
+
+var _ = Describe("MachineSync Reconciler deletion", func() {
+	const (
+		machineName           = "foo"
+		capiProviderFinalizer = "machine.cluster.x-k8s.io"
+		capaFinalizer         = capav1beta2.MachineFinalizer
+	)
+
+	var k komega.Komega
+	var reconciler *MachineSyncReconciler
+
+	var mapiNamespace, capiNamespace *corev1.Namespace
+	var mapiMachine *machinev1beta1.Machine
+	var capiMachine *capiv1beta1.Machine
+	var awsMachine *capav1beta2.AWSMachine
+
+	reconcileMachine := func() {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: mapiNamespace.GetName(), Name: machineName},
+		})
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	setAuthority := func(authority machinev1beta1.MachineAuthority) {
+		Eventually(k.UpdateStatus(mapiMachine, func() {
+			mapiMachine.Status.AuthoritativeAPI = authority
+		})).Should(Succeed())
+	}
+
+	BeforeEach(func() {
+		k = komega.New(k8sClient)
+
+		By("Setting up the namespaces for the test")
+		mapiNamespace = corev1resourcebuilder.Namespace().WithGenerateName("openshift-machine-api-").Build()
+		Expect(k8sClient.Create(ctx, mapiNamespace)).To(Succeed())
+
+		capiNamespace = corev1resourcebuilder.Namespace().WithGenerateName("openshift-cluster-api-").Build()
+		Expect(k8sClient.Create(ctx, capiNamespace)).To(Succeed())
+
+		reconciler = &MachineSyncReconciler{
+			Client:        k8sClient,
+			Scheme:        testScheme,
+			Platform:      configv1.AWSPlatformType,
+			MAPINamespace: mapiNamespace.GetName(),
+			CAPINamespace: capiNamespace.GetName(),
+		}
+
+		By("Creating a MAPI machine and its CAPI counterpart")
+		mapiMachine = machinev1resourcebuilder.Machine().
+			WithNamespace(mapiNamespace.GetName()).
+			WithName(machineName).
+			WithProviderSpecBuilder(machinev1resourcebuilder.AWSProviderSpec()).
+			Build()
+		mapiMachine.Finalizers = []string{machinev1beta1.MachineFinalizer}
+		Expect(k8sClient.Create(ctx, mapiMachine)).To(Succeed())
+
+		awsMachine = capav1builder.AWSMachine().
+			WithNamespace(capiNamespace.GetName()).
+			WithName(machineName).
+			WithInstanceType("m5.large").
+			Build()
+		awsMachine.Finalizers = []string{capaFinalizer}
+		Expect(k8sClient.Create(ctx, awsMachine)).To(Succeed())
+
+		capiMachine = capiv1resourcebuilder.Machine().
+			WithNamespace(capiNamespace.GetName()).
+			WithName(machineName).
+			WithClusterName("cluster-foo").
+			WithInfrastructureRef(corev1.ObjectReference{
+				APIVersion: capav1beta2.GroupVersion.String(),
+				Kind:       "AWSMachine",
+				Name:       awsMachine.GetName(),
+			}).
+			Build()
+		capiMachine.Finalizers = []string{capiProviderFinalizer}
+		Expect(k8sClient.Create(ctx, capiMachine)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(test.CleanupAndWait(ctx, k8sClient, mapiMachine, capiMachine, awsMachine)).To(Succeed())
+	})
+
+	Context("when the MAPI machine is authoritative", func() {
+		BeforeEach(func() {
+			setAuthority(machinev1beta1.MachineAuthorityMachineAPI)
+
+			Eventually(k.Update(capiMachine, func() {
+				capiMachine.Annotations = map[string]string{capiv1beta1.PausedAnnotation: ""}
+			})).Should(Succeed())
+
+			reconcileMachine()
+		})
+
+		It("should add the sync finalizer to both machines", func() {
+			Eventually(k.Object(mapiMachine)).Should(HaveField("Finalizers", ContainElement(consts.SyncFinalizer)))
+			Eventually(k.Object(capiMachine)).Should(HaveField("Finalizers", ContainElement(consts.SyncFinalizer)))
+		})
+
+		It("should propagate the MAPI machine deletion and release the CAPI mirror once the MAPI machine is deleted", func() {
+			Expect(k8sClient.Delete(ctx, mapiMachine)).To(Succeed())
+			Eventually(k.Object(mapiMachine)).Should(HaveField("DeletionTimestamp", Not(BeNil())))
+
+			reconcileMachine()
+
+			By("Deleting the CAPI mirror without releasing it while the MAPI machine is being deleted")
+			Eventually(k.Object(capiMachine)).Should(SatisfyAll(
+				HaveField("DeletionTimestamp", Not(BeNil())),
+				HaveField("Finalizers", ContainElement(capiProviderFinalizer)),
+			))
+
+			By("Completing the MAPI machine deletion")
+			Eventually(k.Update(mapiMachine, func() {
+				mapiMachine.Finalizers = []string{consts.SyncFinalizer}
+			})).Should(Succeed())
+
+			reconcileMachine()
+
+			Eventually(k.Get(mapiMachine)).Should(WithTransform(apierrors.IsNotFound, BeTrue()))
+			Eventually(k.Get(capiMachine)).Should(WithTransform(apierrors.IsNotFound, BeTrue()))
+			Eventually(k.Get(awsMachine)).Should(WithTransform(apierrors.IsNotFound, BeTrue()))
+		})
+
+		It("should propagate the CAPI machine deletion to the MAPI machine with its drain annotation", func() {
+			Eventually(k.Update(capiMachine, func() {
+				capiMachine.Annotations[capiv1beta1.ExcludeNodeDrainingAnnotation] = "true"
+			})).Should(Succeed())
+
+			Expect(k8sClient.Delete(ctx, capiMachine)).To(Succeed())
+
+			reconcileMachine()
+
+			Eventually(k.Object(mapiMachine)).Should(SatisfyAll(
+				HaveField("DeletionTimestamp", Not(BeNil())),
+				HaveField("Annotations", HaveKeyWithValue(mapiExcludeNodeDrainingAnnotation, "true")),
+			))
+		})
+
+		It("should release the InfraMachine when only the InfraMachine is deleted", func() {
+			Expect(k8sClient.Delete(ctx, awsMachine)).To(Succeed())
+
+			reconcileMachine()
+
+			Eventually(k.Get(awsMachine)).Should(WithTransform(apierrors.IsNotFound, BeTrue()))
+			Consistently(k.Object(mapiMachine)).Should(HaveField("DeletionTimestamp", BeNil()))
+			Consistently(k.Object(capiMachine)).Should(HaveField("DeletionTimestamp", BeNil()))
+		})
+	})
+
+	Context("when the CAPI machine is authoritative", func() {
+		BeforeEach(func() {
+			setAuthority(machinev1beta1.MachineAuthorityClusterAPI)
+
+			reconcileMachine()
+		})
+
+		It("should release the MAPI mirror once the CAPI machine is deleted", func() {
+			Expect(k8sClient.Delete(ctx, capiMachine)).To(Succeed())
+
+			reconcileMachine()
+
+			Eventually(k.Object(mapiMachine)).Should(SatisfyAll(
+				HaveField("DeletionTimestamp", Not(BeNil())),
+				HaveField("Finalizers", ContainElement(machinev1beta1.MachineFinalizer)),
+			))
+
+			By("Completing the CAPI machine deletion")
+			Eventually(k.Update(capiMachine, func() {
+				capiMachine.Finalizers = []string{consts.SyncFinalizer}
+			})).Should(Succeed())
+
+			reconcileMachine()
+
+			Eventually(k.Get(mapiMachine)).Should(WithTransform(apierrors.IsNotFound, BeTrue()))
+			Eventually(k.Get(capiMachine)).Should(WithTransform(apierrors.IsNotFound, BeTrue()))
+		})
+
+		It("should delete the CAPI machine when only the InfraMachine is deleted", func() {
+			Expect(k8sClient.Delete(ctx, awsMachine)).To(Succeed())
+
+			reconcileMachine()
+
+			Eventually(k.Object(capiMachine)).Should(HaveField("DeletionTimestamp", Not(BeNil())))
+			Eventually(k.Object(awsMachine)).Should(HaveField("Finalizers", ContainElement(capaFinalizer)))
+		})
+	})
+})
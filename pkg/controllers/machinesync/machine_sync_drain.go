@@ -0,0 +1,148 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinesync
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	machinev1applyconfigs "github.com/openshift/client-go/machine/applyconfigurations/machine/v1beta1"
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/synccommon"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultDrainSettleTimeout is how long the completion of the migration of a machine waits for the drain of its
+	// Node to settle, before proceeding regardless.
+	DefaultDrainSettleTimeout = 10 * time.Minute
+
+	// drainRecheckInterval is how often a machine waiting for its drain to settle is checked again. Nodes are not
+	// watched, so the machine is requeued instead.
+	drainRecheckInterval = 30 * time.Second
+
+	// drainFieldOwner owns the drain pending condition, separately from the other conditions set by the controller,
+	// so that applying them does not remove the drain pending condition.
+	drainFieldOwner = "machine-sync-controller-drain"
+)
+
+// waitForDrain delays the completion of the migration of a machine while its Node is being drained, as handing the
+// authority over mid-drain lets the new authority drain the Node again, or strands it cordoned.
+// The previous authority is not recorded while migrating, so the drain is looked for on both copies of the machine.
+// The drain pending condition of the MAPI machine reports the wait, which is bounded by the drain settle timeout, after
+// which the migration proceeds with a warning event. It returns whether the migration may proceed, and otherwise when
+// the machine should be checked again. The CAPI Machine may be nil when it does not exist.
+func (r *MachineSyncReconciler) waitForDrain(ctx context.Context, mapiMachine *machinev1beta1.Machine, capiMachine *capiv1beta1.Machine) (bool, ctrl.Result, error) {
+	draining, err := r.drainingReasons(ctx, mapiMachine, capiMachine)
+	if err != nil {
+		return false, ctrl.Result{}, err
+	}
+
+	pending := synccommon.FindCondition(mapiMachine.Status.Conditions, consts.DrainPendingCondition)
+	waiting := pending != nil && pending.Status == corev1.ConditionTrue
+
+	switch {
+	case len(draining) == 0 && (waiting || pending != nil && pending.Reason == consts.ReasonDrainTimedOut):
+		return true, ctrl.Result{}, r.updateDrainPendingCondition(ctx, mapiMachine, corev1.ConditionFalse, consts.ReasonDrainSettled, "")
+	case len(draining) == 0:
+		return true, ctrl.Result{}, nil
+	case pending != nil && pending.Reason == consts.ReasonDrainTimedOut:
+		// The wait already timed out, the migration keeps proceeding until the drain settles.
+		return true, ctrl.Result{}, nil
+	case !waiting:
+		message := "Waiting for the drain to settle before completing the migration: " + strings.Join(draining, ", ")
+
+		return false, ctrl.Result{RequeueAfter: min(drainRecheckInterval, r.DrainSettleTimeout)},
+			r.updateDrainPendingCondition(ctx, mapiMachine, corev1.ConditionTrue, consts.ReasonDrainInProgress, message)
+	}
+
+	if elapsed := time.Since(pending.LastTransitionTime.Time); elapsed < r.DrainSettleTimeout {
+		return false, ctrl.Result{RequeueAfter: min(drainRecheckInterval, r.DrainSettleTimeout-elapsed)}, nil
+	}
+
+	message := fmt.Sprintf("The drain did not settle within %s, completing the migration regardless: %s", r.DrainSettleTimeout, strings.Join(draining, ", "))
+	r.Recorder.Event(mapiMachine, corev1.EventTypeWarning, consts.ReasonDrainTimedOut, message)
+
+	return true, ctrl.Result{}, r.updateDrainPendingCondition(ctx, mapiMachine, corev1.ConditionFalse, consts.ReasonDrainTimedOut, message)
+}
+
+// drainingReasons returns why the Node of the machine is considered as being drained, empty when it is not: the Node
+// is cordoned, or a copy of the machine being deleted still has pre-drain lifecycle hooks.
+func (r *MachineSyncReconciler) drainingReasons(ctx context.Context, mapiMachine *machinev1beta1.Machine, capiMachine *capiv1beta1.Machine) ([]string, error) {
+	var reasons []string
+
+	if nodeName := machineNodeName(mapiMachine, capiMachine); nodeName != "" {
+		node := &corev1.Node{}
+		if err := r.Get(ctx, client.ObjectKey{Name: nodeName}, node); err != nil && !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get Node %q: %w", nodeName, err)
+		} else if err == nil && node.Spec.Unschedulable {
+			reasons = append(reasons, fmt.Sprintf("Node %q is cordoned", nodeName))
+		}
+	}
+
+	if !mapiMachine.DeletionTimestamp.IsZero() && len(mapiMachine.Spec.LifecycleHooks.PreDrain) > 0 {
+		reasons = append(reasons, "MAPI Machine has pending pre-drain hooks")
+	}
+
+	if capiMachine != nil && !capiMachine.DeletionTimestamp.IsZero() && hasCAPIPreDrainHooks(capiMachine) {
+		reasons = append(reasons, "CAPI Machine has pending pre-drain hooks")
+	}
+
+	sort.Strings(reasons)
+
+	return reasons, nil
+}
+
+// hasCAPIPreDrainHooks returns whether the CAPI Machine has pre-drain delete hook annotations.
+func hasCAPIPreDrainHooks(capiMachine *capiv1beta1.Machine) bool {
+	for key := range capiMachine.GetAnnotations() {
+		if strings.HasPrefix(key, capiv1beta1.PreDrainDeleteHookAnnotationPrefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// updateDrainPendingCondition sets the drain pending condition of the MAPI machine.
+func (r *MachineSyncReconciler) updateDrainPendingCondition(ctx context.Context, mapiMachine *machinev1beta1.Machine, status corev1.ConditionStatus, reason, message string) error {
+	severity := machinev1beta1.ConditionSeverityNone
+
+	switch reason {
+	case consts.ReasonDrainInProgress:
+		severity = machinev1beta1.ConditionSeverityInfo
+	case consts.ReasonDrainTimedOut:
+		severity = machinev1beta1.ConditionSeverityWarning
+	}
+
+	conditionAc := machinev1applyconfigs.Condition().
+		WithType(consts.DrainPendingCondition).
+		WithStatus(status).
+		WithReason(reason).
+		WithMessage(message).
+		WithSeverity(severity)
+
+	return synccommon.PatchMAPIConditions(ctx, r.Client, mapiMachine, drainFieldOwner, conditionAc)
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinesync
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("MachineSync Reconciler drain awareness", func() {
+	const nodeName = "ip-10-0-0-1.ec2.internal"
+
+	var reconciler *MachineSyncReconciler
+	var recorder *record.FakeRecorder
+	var mapiMachine *machinev1beta1.Machine
+	var capiMachine *capiv1beta1.Machine
+	var patched []machinev1beta1.Condition
+
+	withNode := func(cordoned bool) {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName}, Spec: corev1.NodeSpec{Unschedulable: cordoned}}
+		reconciler.Client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(node).WithInterceptorFuncs(interceptor.Funcs{
+			SubResourcePatch: func(ctx context.Context, _ client.Client, _ string, obj client.Object, patch client.Patch, _ ...client.SubResourcePatchOption) error {
+				data, err := patch.Data(obj)
+				Expect(err).NotTo(HaveOccurred())
+
+				applied := &machinev1beta1.Machine{}
+				Expect(json.Unmarshal(data, applied)).To(Succeed())
+
+				patched = append(patched, applied.Status.Conditions...)
+
+				return nil
+			},
+		}).Build()
+	}
+
+	withPendingCondition := func(status corev1.ConditionStatus, reason string, since time.Time) {
+		mapiMachine.Status.Conditions = []machinev1beta1.Condition{{
+			Type:               consts.DrainPendingCondition,
+			Status:             status,
+			Reason:             reason,
+			LastTransitionTime: metav1.NewTime(since),
+		}}
+	}
+
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &MachineSyncReconciler{Recorder: recorder, DrainSettleTimeout: 10 * time.Minute}
+		patched = nil
+		withNode(false)
+
+		mapiMachine = &machinev1beta1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: consts.DefaultMAPIManagedNamespace, Name: "machine"},
+			Status:     machinev1beta1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: nodeName}},
+		}
+		capiMachine = &capiv1beta1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: consts.DefaultManagedNamespace, Name: "machine"},
+			Status:     capiv1beta1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: nodeName}},
+		}
+	})
+
+	It("should proceed without a condition when the Node is not being drained", func() {
+		proceed, result, err := reconciler.waitForDrain(ctx, mapiMachine, capiMachine)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(proceed).To(BeTrue())
+		Expect(result).To(Equal(ctrl.Result{}))
+		Expect(patched).To(BeEmpty())
+	})
+
+	It("should wait while the Node is cordoned", func() {
+		withNode(true)
+
+		proceed, result, err := reconciler.waitForDrain(ctx, mapiMachine, capiMachine)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(proceed).To(BeFalse())
+		Expect(result.RequeueAfter).To(Equal(drainRecheckInterval))
+		Expect(patched).To(ConsistOf(SatisfyAll(
+			HaveField("Type", consts.DrainPendingCondition),
+			HaveField("Status", corev1.ConditionTrue),
+			HaveField("Reason", consts.ReasonDrainInProgress),
+			HaveField("Message", ContainSubstring(`Node "`+nodeName+`" is cordoned`)),
+		)))
+	})
+
+	It("should wait while a deleting CAPI Machine has pre-drain hooks", func() {
+		capiMachine.DeletionTimestamp = ptr.To(metav1.Now())
+		capiMachine.Annotations = map[string]string{capiv1beta1.PreDrainDeleteHookAnnotationPrefix + "/example": "owner"}
+
+		proceed, _, err := reconciler.waitForDrain(ctx, mapiMachine, capiMachine)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(proceed).To(BeFalse())
+		Expect(patched).To(ConsistOf(HaveField("Message", ContainSubstring("CAPI Machine has pending pre-drain hooks"))))
+	})
+
+	It("should keep waiting without patching the condition again before the timeout", func() {
+		withNode(true)
+		withPendingCondition(corev1.ConditionTrue, consts.ReasonDrainInProgress, time.Now().Add(-9*time.Minute-50*time.Second))
+
+		proceed, result, err := reconciler.waitForDrain(ctx, mapiMachine, capiMachine)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(proceed).To(BeFalse())
+		Expect(result.RequeueAfter).To(BeNumerically("<=", 10*time.Second))
+		Expect(patched).To(BeEmpty())
+	})
+
+	It("should proceed with a warning once the timeout elapses", func() {
+		withNode(true)
+		withPendingCondition(corev1.ConditionTrue, consts.ReasonDrainInProgress, time.Now().Add(-11*time.Minute))
+
+		proceed, _, err := reconciler.waitForDrain(ctx, mapiMachine, capiMachine)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(proceed).To(BeTrue())
+		Expect(patched).To(ConsistOf(SatisfyAll(
+			HaveField("Status", corev1.ConditionFalse),
+			HaveField("Reason", consts.ReasonDrainTimedOut),
+		)))
+		Expect(recorder.Events).To(Receive(ContainSubstring(consts.ReasonDrainTimedOut)))
+	})
+
+	It("should keep proceeding after the timeout while the Node is still cordoned", func() {
+		withNode(true)
+		withPendingCondition(corev1.ConditionFalse, consts.ReasonDrainTimedOut, time.Now())
+
+		proceed, _, err := reconciler.waitForDrain(ctx, mapiMachine, capiMachine)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(proceed).To(BeTrue())
+		Expect(patched).To(BeEmpty())
+	})
+
+	It("should reset the condition once the drain settles", func() {
+		withPendingCondition(corev1.ConditionTrue, consts.ReasonDrainInProgress, time.Now())
+
+		proceed, _, err := reconciler.waitForDrain(ctx, mapiMachine, capiMachine)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(proceed).To(BeTrue())
+		Expect(patched).To(ConsistOf(SatisfyAll(
+			HaveField("Status", corev1.ConditionFalse),
+			HaveField("Reason", consts.ReasonDrainSettled),
+		)))
+	})
+})
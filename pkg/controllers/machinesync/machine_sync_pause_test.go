@@ -0,0 +1,120 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinesync
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	corev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/core/v1"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/envtest/komega"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/test"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+)
+
+var _ = Describe("MachineSync Reconciler pause", func() {
+	const machineName = "foo"
+
+	var k komega.Komega
+	var reconciler *MachineSyncReconciler
+
+	var mapiNamespace, capiNamespace *corev1.Namespace
+	var mapiMachine *machinev1beta1.Machine
+
+	reconcileMachine := func() {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: mapiNamespace.GetName(), Name: machineName},
+		})
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	setPaused := func(paused string) {
+		Eventually(k.Update(mapiNamespace, func() {
+			mapiNamespace.SetAnnotations(map[string]string{util.SyncPausedAnnotation: paused})
+		})).Should(Succeed())
+	}
+
+	BeforeEach(func() {
+		k = komega.New(k8sClient)
+
+		By("Setting up the namespaces for the test")
+		mapiNamespace = corev1resourcebuilder.Namespace().WithGenerateName("openshift-machine-api-").Build()
+		Expect(k8sClient.Create(ctx, mapiNamespace)).To(Succeed())
+
+		capiNamespace = corev1resourcebuilder.Namespace().WithGenerateName("openshift-cluster-api-").Build()
+		Expect(k8sClient.Create(ctx, capiNamespace)).To(Succeed())
+
+		reconciler = &MachineSyncReconciler{
+			Client:        k8sClient,
+			Scheme:        testScheme,
+			Platform:      configv1.AWSPlatformType,
+			MAPINamespace: mapiNamespace.GetName(),
+			CAPINamespace: capiNamespace.GetName(),
+			Backoff:       util.NewBackoff(util.DefaultBackoffConfig),
+		}
+
+		By("Creating a MAPI machine")
+		mapiMachine = machinev1resourcebuilder.Machine().
+			WithNamespace(mapiNamespace.GetName()).
+			WithName(machineName).
+			WithProviderSpecBuilder(machinev1resourcebuilder.AWSProviderSpec()).
+			Build()
+		Expect(k8sClient.Create(ctx, mapiMachine)).To(Succeed())
+
+		Eventually(k.UpdateStatus(mapiMachine, func() {
+			mapiMachine.Status.AuthoritativeAPI = machinev1beta1.MachineAuthorityMachineAPI
+		})).Should(Succeed())
+
+		By("Pausing the synchronization")
+		setPaused("true")
+		reconcileMachine()
+	})
+
+	AfterEach(func() {
+		Expect(test.CleanupAndWait(ctx, k8sClient, mapiMachine)).To(Succeed())
+	})
+
+	It("should set the paused condition on the MAPI machine", func() {
+		Eventually(k.Object(mapiMachine)).Should(
+			HaveField("Status.Conditions", ContainElement(SatisfyAll(
+				HaveField("Type", Equal(consts.PausedCondition)),
+				HaveField("Status", Equal(corev1.ConditionTrue)),
+				HaveField("Reason", Equal(consts.ReasonSyncPaused)),
+			))),
+		)
+	})
+
+	It("should reset the paused condition once resumed", func() {
+		setPaused("false")
+		reconcileMachine()
+
+		Eventually(k.Object(mapiMachine)).Should(
+			HaveField("Status.Conditions", ContainElement(SatisfyAll(
+				HaveField("Type", Equal(consts.PausedCondition)),
+				HaveField("Status", Equal(corev1.ConditionFalse)),
+				HaveField("Reason", Equal(consts.ReasonSyncResumed)),
+			))),
+		)
+	})
+})
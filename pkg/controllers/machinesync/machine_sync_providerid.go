@@ -0,0 +1,127 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinesync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-capi-operator/pkg/errorsx"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/ptr"
+	capav1beta2 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
+	capibmv1 "sigs.k8s.io/cluster-api-provider-ibmcloud/api/v1beta2"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// errProviderIDMismatch is returned when the copies of a machine, its InfraMachine or its Node disagree on the providerID.
+// It is returned as an errorsx.ErrTransientAPI, as the mismatch usually resolves once the InfraMachine reports its providerID.
+var errProviderIDMismatch = errors.New("providerID mismatch")
+
+// providerIDSource is a resource reporting the providerID of a machine.
+type providerIDSource struct {
+	name       string
+	providerID string
+}
+
+// validateProviderIDs cross-checks the providerID of the MAPI Machine against the CAPI Machine, its InfraMachine and the
+// Node of the machine, in both directions of a migration. Handing the authority over while they disagree would leave the
+// new authoritative copy managing another instance than the one backing the Node, orphaning the Node.
+// The resources without a providerID, such as those of a machine that is not provisioned yet, are not compared.
+// The CAPI Machine and the InfraMachine may be nil when they do not exist.
+func (r *MachineSyncReconciler) validateProviderIDs(ctx context.Context, mapiMachine *machinev1beta1.Machine, capiMachine *capiv1beta1.Machine, infraMachine client.Object) error {
+	sources := []providerIDSource{{name: "MAPI Machine", providerID: ptr.Deref(mapiMachine.Spec.ProviderID, "")}}
+
+	if capiMachine != nil {
+		sources = append(sources, providerIDSource{name: "CAPI Machine", providerID: ptr.Deref(capiMachine.Spec.ProviderID, "")})
+	}
+
+	if infraMachine != nil {
+		sources = append(sources, infraMachineProviderID(infraMachine))
+	}
+
+	if nodeName := machineNodeName(mapiMachine, capiMachine); nodeName != "" {
+		node := &corev1.Node{}
+		if err := r.Get(ctx, client.ObjectKey{Name: nodeName}, node); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get Node %q: %w", nodeName, err)
+		} else if err == nil {
+			sources = append(sources, providerIDSource{name: fmt.Sprintf("Node %q", nodeName), providerID: node.Spec.ProviderID})
+		}
+	}
+
+	return compareProviderIDs(sources)
+}
+
+// machineNodeName returns the name of the Node of the machine, as referenced by the MAPI Machine or else by the CAPI
+// Machine, empty when neither references one. The CAPI Machine may be nil when it does not exist.
+func machineNodeName(mapiMachine *machinev1beta1.Machine, capiMachine *capiv1beta1.Machine) string {
+	if mapiMachine.Status.NodeRef != nil {
+		return mapiMachine.Status.NodeRef.Name
+	}
+
+	if capiMachine != nil && capiMachine.Status.NodeRef != nil {
+		return capiMachine.Status.NodeRef.Name
+	}
+
+	return ""
+}
+
+// compareProviderIDs returns an errProviderIDMismatch listing every source and its providerID when the sources with a
+// providerID disagree.
+func compareProviderIDs(sources []providerIDSource) error {
+	var expected string
+
+	mismatch := false
+
+	for _, s := range sources {
+		switch {
+		case s.providerID == "":
+		case expected == "":
+			expected = s.providerID
+		case s.providerID != expected:
+			mismatch = true
+		}
+	}
+
+	if !mismatch {
+		return nil
+	}
+
+	reported := make([]string, 0, len(sources))
+	for _, s := range sources {
+		reported = append(reported, fmt.Sprintf("%s has %q", s.name, s.providerID))
+	}
+
+	return errorsx.TransientAPI(fmt.Errorf("%w: %s", errProviderIDMismatch, strings.Join(reported, ", ")))
+}
+
+// infraMachineProviderID returns the providerID of the given InfraMachine, empty if it has none.
+func infraMachineProviderID(infraMachine client.Object) providerIDSource {
+	switch m := infraMachine.(type) {
+	case *capav1beta2.AWSMachine:
+		return providerIDSource{name: "AWSMachine", providerID: ptr.Deref(m.Spec.ProviderID, "")}
+	case *capibmv1.IBMPowerVSMachine:
+		return providerIDSource{name: "IBMPowerVSMachine", providerID: ptr.Deref(m.Spec.ProviderID, "")}
+	default:
+		return providerIDSource{name: fmt.Sprintf("%T", infraMachine)}
+	}
+}
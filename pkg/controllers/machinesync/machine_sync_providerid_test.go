@@ -0,0 +1,129 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinesync
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	capav1beta2 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("MachineSync Reconciler providerID validation", func() {
+	const (
+		providerID      = "aws:///us-east-1a/i-0123456789abcdef0"
+		otherProviderID = "aws:///us-east-1a/i-0fedcba9876543210"
+		nodeName        = "ip-10-0-0-1.ec2.internal"
+	)
+
+	var reconciler *MachineSyncReconciler
+	var mapiMachine *machinev1beta1.Machine
+	var capiMachine *capiv1beta1.Machine
+	var awsMachine *capav1beta2.AWSMachine
+
+	withNode := func(nodeProviderID string) {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName}, Spec: corev1.NodeSpec{ProviderID: nodeProviderID}}
+		reconciler.Client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(node).Build()
+	}
+
+	BeforeEach(func() {
+		reconciler = &MachineSyncReconciler{}
+		withNode(providerID)
+
+		mapiMachine = &machinev1beta1.Machine{
+			Spec:   machinev1beta1.MachineSpec{ProviderID: ptr.To(providerID)},
+			Status: machinev1beta1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: nodeName}},
+		}
+		capiMachine = &capiv1beta1.Machine{
+			Spec:   capiv1beta1.MachineSpec{ProviderID: ptr.To(providerID)},
+			Status: capiv1beta1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: nodeName}},
+		}
+		awsMachine = &capav1beta2.AWSMachine{Spec: capav1beta2.AWSMachineSpec{ProviderID: ptr.To(providerID)}}
+	})
+
+	It("should succeed when every resource agrees on the providerID", func() {
+		Expect(reconciler.validateProviderIDs(ctx, mapiMachine, capiMachine, awsMachine)).To(Succeed())
+	})
+
+	It("should succeed when the machine is not provisioned yet", func() {
+		mapiMachine.Spec.ProviderID = nil
+		mapiMachine.Status.NodeRef = nil
+		capiMachine.Spec.ProviderID = nil
+		capiMachine.Status.NodeRef = nil
+		awsMachine.Spec.ProviderID = nil
+
+		Expect(reconciler.validateProviderIDs(ctx, mapiMachine, capiMachine, awsMachine)).To(Succeed())
+	})
+
+	It("should succeed when the CAPI copies do not exist", func() {
+		Expect(reconciler.validateProviderIDs(ctx, mapiMachine, nil, nil)).To(Succeed())
+	})
+
+	It("should fail when the CAPI Machine disagrees with the MAPI Machine", func() {
+		capiMachine.Spec.ProviderID = ptr.To(otherProviderID)
+
+		err := reconciler.validateProviderIDs(ctx, mapiMachine, capiMachine, awsMachine)
+		Expect(err).To(MatchError(errProviderIDMismatch))
+		Expect(err).To(MatchError(ContainSubstring(`CAPI Machine has "` + otherProviderID + `"`)))
+	})
+
+	It("should fail when the InfraMachine disagrees with the machines", func() {
+		awsMachine.Spec.ProviderID = ptr.To(otherProviderID)
+
+		Expect(reconciler.validateProviderIDs(ctx, mapiMachine, capiMachine, awsMachine)).To(MatchError(
+			`providerID mismatch: MAPI Machine has "` + providerID + `", CAPI Machine has "` + providerID +
+				`", AWSMachine has "` + otherProviderID + `", Node "` + nodeName + `" has "` + providerID + `"`))
+	})
+
+	It("should fail when the Node disagrees with the machines", func() {
+		withNode(otherProviderID)
+
+		err := reconciler.validateProviderIDs(ctx, mapiMachine, capiMachine, awsMachine)
+		Expect(err).To(MatchError(errProviderIDMismatch))
+		Expect(err).To(MatchError(ContainSubstring(`Node "` + nodeName + `" has "` + otherProviderID + `"`)))
+	})
+
+	It("should use the Node of the CAPI Machine when the MAPI Machine has none", func() {
+		mapiMachine.Status.NodeRef = nil
+		withNode(otherProviderID)
+
+		Expect(reconciler.validateProviderIDs(ctx, mapiMachine, capiMachine, awsMachine)).To(MatchError(errProviderIDMismatch))
+	})
+
+	It("should not compare a Node that does not exist", func() {
+		reconciler.Client = fake.NewClientBuilder().Build()
+
+		Expect(reconciler.validateProviderIDs(ctx, mapiMachine, capiMachine, awsMachine)).To(Succeed())
+	})
+
+	It("should return an error when the Node cannot be read", func() {
+		reconciler.Client = fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+
+		err := reconciler.validateProviderIDs(ctx, mapiMachine, capiMachine, awsMachine)
+		Expect(err).To(HaveOccurred())
+		Expect(err).ToNot(MatchError(errProviderIDMismatch))
+	})
+})
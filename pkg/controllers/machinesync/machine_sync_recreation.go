@@ -0,0 +1,192 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinesync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	machinev1applyconfigs "github.com/openshift/client-go/machine/applyconfigurations/machine/v1beta1"
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/synccommon"
+	corev1 "k8s.io/api/core/v1"
+	capav1beta2 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
+	capibmv1 "sigs.k8s.io/cluster-api-provider-ibmcloud/api/v1beta2"
+	capov1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1beta1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// recreationFieldOwner owns the InfraMachine recreation blocked condition, separately from the other conditions
+	// set by the controller, so that applying them does not remove the recreation blocked condition.
+	recreationFieldOwner = "machine-sync-controller-recreation"
+
+	// recreationRecheckMinInterval and recreationRecheckMaxInterval bound how often an InfraMachine whose removal is
+	// blocked by a third-party finalizer is checked again. In between, the interval grows with how long it has been
+	// blocked, as the finalizer removal is watched and only the requeue is a fallback.
+	recreationRecheckMinInterval = 10 * time.Second
+	recreationRecheckMaxInterval = 5 * time.Minute
+)
+
+// releaseInfraMachine releases the InfraMachine of the paused CAPI mirror of a MAPI authoritative machine, which is
+// being deleted, so that it can be recreated by the next synchronization.
+//
+// The InfraMachine is paused first: its provider may be reconciling the deletion already, and must neither act on
+// the instance of the MAPI machine nor keep racing with its finalizer being removed. Only the finalizers of the
+// provider are then removed, the optimistic lock making the removal retried with the backoff of the controller when
+// the provider updated the InfraMachine concurrently. Third-party finalizers are left to their owners, the wait for
+// their removal being reported by the recreation blocked condition of the MAPI machine, which may be nil.
+func (r *MachineSyncReconciler) releaseInfraMachine(ctx context.Context, logger logr.Logger, mapiMachine *machinev1beta1.Machine, infraMachine client.Object) (ctrl.Result, error) {
+	if _, paused := getAnnotation(infraMachine, capiv1beta1.PausedAnnotation); !paused {
+		logger.Info("Pausing the InfraMachine of the CAPI mirror before releasing it", "object", describe(infraMachine))
+
+		if err := r.pauseInfraMachine(ctx, infraMachine); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	released := releasedInfraMachineFinalizers(r.Platform)
+	before := len(infraMachine.GetFinalizers())
+
+	if err := r.patchFinalizers(ctx, infraMachine, func(o client.Object) {
+		for _, finalizer := range released {
+			controllerutil.RemoveFinalizer(o, finalizer)
+		}
+	}); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if len(infraMachine.GetFinalizers()) < before {
+		r.recordDeletionEvent(infraMachine, reasonMirrorReleased, "Provider finalizers removed as the InfraMachine of the paused CAPI mirror is being deleted")
+	}
+
+	if blocking := infraMachine.GetFinalizers(); len(blocking) > 0 {
+		logger.Info("Waiting for third-party finalizers to be removed from the InfraMachine before it can be recreated", "object", describe(infraMachine), "finalizers", blocking)
+
+		return r.reportInfraMachineRecreationBlocked(ctx, mapiMachine, infraMachine, blocking)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// pauseInfraMachine sets the paused annotation on the InfraMachine, so that its provider stops reconciling it.
+func (r *MachineSyncReconciler) pauseInfraMachine(ctx context.Context, infraMachine client.Object) error {
+	patchBase := client.MergeFrom(infraMachine.DeepCopyObject().(client.Object)) //nolint:forcetypeassert
+
+	annotations := infraMachine.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	annotations[capiv1beta1.PausedAnnotation] = ""
+	infraMachine.SetAnnotations(annotations)
+
+	if err := r.Patch(ctx, infraMachine, patchBase); err != nil {
+		return fmt.Errorf("failed to pause %s: %w", describe(infraMachine), err)
+	}
+
+	return nil
+}
+
+// reportInfraMachineRecreationBlocked sets the recreation blocked condition of the MAPI machine, recording a warning
+// event when the InfraMachine becomes blocked, and returns when the InfraMachine should be checked again.
+func (r *MachineSyncReconciler) reportInfraMachineRecreationBlocked(ctx context.Context, mapiMachine *machinev1beta1.Machine, infraMachine client.Object, blocking []string) (ctrl.Result, error) {
+	if mapiMachine == nil {
+		// The MAPI machine is gone, there is nowhere to report the wait.
+		return ctrl.Result{RequeueAfter: recreationRecheckMinInterval}, nil
+	}
+
+	message := fmt.Sprintf("%s cannot be removed, and so recreated, until its third-party finalizers are removed: %s",
+		describe(infraMachine), strings.Join(blocking, ", "))
+
+	current := synccommon.FindCondition(mapiMachine.Status.Conditions, consts.InfraMachineRecreationBlockedCondition)
+
+	var blockedFor time.Duration
+
+	if current != nil && current.Status == corev1.ConditionTrue {
+		blockedFor = time.Since(current.LastTransitionTime.Time)
+
+		if current.Message == message {
+			return ctrl.Result{RequeueAfter: recreationRecheckInterval(blockedFor)}, nil
+		}
+	} else if r.Recorder != nil {
+		r.Recorder.Event(mapiMachine, corev1.EventTypeWarning, consts.ReasonThirdPartyFinalizer, message)
+	}
+
+	return ctrl.Result{RequeueAfter: recreationRecheckInterval(blockedFor)},
+		r.updateInfraMachineRecreationBlockedCondition(ctx, mapiMachine, corev1.ConditionTrue, consts.ReasonThirdPartyFinalizer, message)
+}
+
+// resetInfraMachineRecreationBlocked resets the recreation blocked condition of the MAPI machine, which may be nil,
+// once its InfraMachine is no longer blocked.
+func (r *MachineSyncReconciler) resetInfraMachineRecreationBlocked(ctx context.Context, mapiMachine *machinev1beta1.Machine) error {
+	if mapiMachine == nil {
+		return nil
+	}
+
+	if current := synccommon.FindCondition(mapiMachine.Status.Conditions, consts.InfraMachineRecreationBlockedCondition); current == nil || current.Status != corev1.ConditionTrue {
+		return nil
+	}
+
+	return r.updateInfraMachineRecreationBlockedCondition(ctx, mapiMachine, corev1.ConditionFalse, consts.ReasonInfraMachineReleased, "")
+}
+
+// updateInfraMachineRecreationBlockedCondition sets the recreation blocked condition of the MAPI machine.
+func (r *MachineSyncReconciler) updateInfraMachineRecreationBlockedCondition(ctx context.Context, mapiMachine *machinev1beta1.Machine, status corev1.ConditionStatus, reason, message string) error {
+	severity := machinev1beta1.ConditionSeverityNone
+	if status == corev1.ConditionTrue {
+		severity = machinev1beta1.ConditionSeverityWarning
+	}
+
+	conditionAc := machinev1applyconfigs.Condition().
+		WithType(consts.InfraMachineRecreationBlockedCondition).
+		WithStatus(status).
+		WithReason(reason).
+		WithMessage(message).
+		WithSeverity(severity)
+
+	return synccommon.PatchMAPIConditions(ctx, r.Client, mapiMachine, recreationFieldOwner, conditionAc)
+}
+
+// recreationRecheckInterval returns when an InfraMachine blocked for the given duration should be checked again.
+// Rechecking after as long as it has been blocked doubles the interval, within the recheck bounds.
+func recreationRecheckInterval(blockedFor time.Duration) time.Duration {
+	return min(max(blockedFor, recreationRecheckMinInterval), recreationRecheckMaxInterval)
+}
+
+// releasedInfraMachineFinalizers returns the finalizers removed from the InfraMachines of the paused CAPI mirrors on
+// the platform: the ones of their provider, which never acts on them, and the SyncFinalizer.
+func releasedInfraMachineFinalizers(platform configv1.PlatformType) []string {
+	switch platform {
+	case configv1.AWSPlatformType:
+		return []string{capav1beta2.MachineFinalizer, consts.SyncFinalizer}
+	case configv1.PowerVSPlatformType:
+		return []string{capibmv1.IBMPowerVSMachineFinalizer, consts.SyncFinalizer}
+	case configv1.OpenStackPlatformType:
+		return []string{capov1.MachineFinalizer, capov1.IPClaimMachineFinalizer, consts.SyncFinalizer}
+	default:
+		return []string{consts.SyncFinalizer}
+	}
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinesync
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	capav1beta2 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("MachineSync Reconciler InfraMachine recreation", func() {
+	const thirdPartyFinalizer = "backup.example.com/finalizer"
+
+	var reconciler *MachineSyncReconciler
+	var recorder *record.FakeRecorder
+	var mapiMachine *machinev1beta1.Machine
+	var awsMachine *capav1beta2.AWSMachine
+	var patched []machinev1beta1.Condition
+
+	withInfraMachine := func(finalizers ...string) {
+		scheme := runtime.NewScheme()
+		Expect(capav1beta2.AddToScheme(scheme)).To(Succeed())
+
+		awsMachine = &capav1beta2.AWSMachine{ObjectMeta: metav1.ObjectMeta{
+			Namespace:         consts.DefaultManagedNamespace,
+			Name:              "machine",
+			DeletionTimestamp: ptr.To(metav1.Now()),
+			Finalizers:        finalizers,
+		}}
+
+		reconciler.Client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(awsMachine).WithInterceptorFuncs(interceptor.Funcs{
+			SubResourcePatch: func(ctx context.Context, _ client.Client, _ string, obj client.Object, patch client.Patch, _ ...client.SubResourcePatchOption) error {
+				data, err := patch.Data(obj)
+				Expect(err).NotTo(HaveOccurred())
+
+				applied := &machinev1beta1.Machine{}
+				Expect(json.Unmarshal(data, applied)).To(Succeed())
+
+				patched = append(patched, applied.Status.Conditions...)
+
+				return nil
+			},
+		}).Build()
+
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(awsMachine), awsMachine)).To(Succeed())
+	}
+
+	withBlockedCondition := func(message string, since time.Time) {
+		mapiMachine.Status.Conditions = []machinev1beta1.Condition{{
+			Type:               consts.InfraMachineRecreationBlockedCondition,
+			Status:             corev1.ConditionTrue,
+			Reason:             consts.ReasonThirdPartyFinalizer,
+			Message:            message,
+			LastTransitionTime: metav1.NewTime(since),
+		}}
+	}
+
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &MachineSyncReconciler{Recorder: recorder, Platform: configv1.AWSPlatformType}
+		patched = nil
+
+		mapiMachine = &machinev1beta1.Machine{ObjectMeta: metav1.ObjectMeta{Namespace: consts.DefaultMAPIManagedNamespace, Name: "machine"}}
+	})
+
+	It("should pause the InfraMachine and release it when only its provider finalizer is left", func() {
+		withInfraMachine(capav1beta2.MachineFinalizer)
+
+		result, err := reconciler.releaseInfraMachine(ctx, log.FromContext(ctx), mapiMachine, awsMachine)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+		Expect(awsMachine.GetAnnotations()).To(HaveKey(capiv1beta1.PausedAnnotation))
+		Expect(patched).To(BeEmpty())
+
+		err = reconciler.Get(ctx, client.ObjectKeyFromObject(awsMachine), &capav1beta2.AWSMachine{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should keep a third-party finalizer and report the blocked recreation", func() {
+		withInfraMachine(capav1beta2.MachineFinalizer, thirdPartyFinalizer)
+
+		result, err := reconciler.releaseInfraMachine(ctx, log.FromContext(ctx), mapiMachine, awsMachine)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(recreationRecheckMinInterval))
+
+		current := &capav1beta2.AWSMachine{}
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(awsMachine), current)).To(Succeed())
+		Expect(current.GetAnnotations()).To(HaveKey(capiv1beta1.PausedAnnotation))
+		Expect(current.GetFinalizers()).To(ConsistOf(thirdPartyFinalizer))
+
+		Expect(patched).To(ConsistOf(SatisfyAll(
+			HaveField("Type", consts.InfraMachineRecreationBlockedCondition),
+			HaveField("Status", corev1.ConditionTrue),
+			HaveField("Reason", consts.ReasonThirdPartyFinalizer),
+			HaveField("Message", ContainSubstring(thirdPartyFinalizer)),
+		)))
+		Eventually(recorder.Events).Should(Receive(ContainSubstring(consts.ReasonThirdPartyFinalizer)))
+	})
+
+	It("should back off without patching the condition again while the recreation stays blocked", func() {
+		withInfraMachine(thirdPartyFinalizer)
+
+		_, err := reconciler.releaseInfraMachine(ctx, log.FromContext(ctx), mapiMachine, awsMachine)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(patched).To(HaveLen(1))
+
+		withBlockedCondition(patched[0].Message, time.Now().Add(-time.Minute))
+		patched = nil
+
+		result, err := reconciler.releaseInfraMachine(ctx, log.FromContext(ctx), mapiMachine, awsMachine)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">=", time.Minute))
+		Expect(patched).To(BeEmpty())
+		Expect(recorder.Events).To(HaveLen(1))
+	})
+
+	It("should reset the condition once the InfraMachine is no longer blocked", func() {
+		withInfraMachine(thirdPartyFinalizer)
+		withBlockedCondition("blocked", time.Now())
+
+		Expect(reconciler.resetInfraMachineRecreationBlocked(ctx, mapiMachine)).To(Succeed())
+		Expect(patched).To(ConsistOf(SatisfyAll(
+			HaveField("Type", consts.InfraMachineRecreationBlockedCondition),
+			HaveField("Status", corev1.ConditionFalse),
+			HaveField("Reason", consts.ReasonInfraMachineReleased),
+		)))
+	})
+
+	It("should not set the condition on a machine that was never blocked", func() {
+		withInfraMachine(thirdPartyFinalizer)
+
+		Expect(reconciler.resetInfraMachineRecreationBlocked(ctx, mapiMachine)).To(Succeed())
+		Expect(patched).To(BeEmpty())
+	})
+
+	DescribeTable("should recheck a blocked InfraMachine within the recheck bounds",
+		func(blockedFor, expected time.Duration) {
+			Expect(recreationRecheckInterval(blockedFor)).To(Equal(expected))
+		},
+		Entry("when it just became blocked", time.Duration(0), recreationRecheckMinInterval),
+		Entry("as long as it has been blocked", 2*time.Minute, 2*time.Minute),
+		Entry("when it has been blocked for long", time.Hour, recreationRecheckMaxInterval),
+	)
+})
@@ -0,0 +1,206 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinesync
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	capiv1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/core/v1beta1"
+	capav1builder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/infrastructure/v1beta2"
+	corev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/core/v1"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+	capav1beta2 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/config"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	"github.com/openshift/cluster-capi-operator/pkg/test/scale"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+)
+
+// scaleSyncTimeout bounds how long the controller may take to reconcile the machines of a benchmark.
+const scaleSyncTimeout = 30 * time.Minute
+
+// BenchmarkMachineSyncScale measures the machine sync controller reconciling scale.Size pairs of MAPI and CAPI
+// machines, authoritative in the Machine API, from its start until its work queue is drained.
+func BenchmarkMachineSyncScale(b *testing.B) {
+	size, err := scale.Size()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	steadyState, err := scale.SteadyState()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	cfg, cl := scale.StartEnvTest(b)
+	ctx := context.Background()
+
+	for range b.N {
+		b.StopTimer()
+
+		mapiNamespace, capiNamespace := createScaleMachinePairs(ctx, b, cl, size)
+
+		counter := &scale.RequestCounter{}
+		reconciler := &MachineSyncReconciler{
+			Platform:      configv1.AWSPlatformType,
+			MAPINamespace: mapiNamespace,
+			CAPINamespace: capiNamespace,
+		}
+
+		mgr, err := ctrl.NewManager(counter.Instrument(cfg), ctrl.Options{
+			Metrics:    metricsserver.Options{BindAddress: "0"},
+			Controller: config.Controller{SkipNameValidation: ptr.To(true)},
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if err := reconciler.SetupWithManager(mgr); err != nil {
+			b.Fatal(err)
+		}
+
+		name := util.NamespacedControllerName(controllerName, mapiNamespace)
+		heapBefore := scale.HeapInUse()
+
+		mgrCtx, mgrCancel := context.WithCancel(ctx)
+		mgrDone := make(chan error)
+
+		b.StartTimer()
+
+		start := time.Now()
+
+		go func() { mgrDone <- mgr.Start(mgrCtx) }()
+
+		// Every machine is reconciled at least once, the requests of the watches of its copies being deduplicated.
+		if err := scale.WaitFor(ctx, scaleSyncTimeout, func(context.Context) (bool, error) {
+			reconciles, err := scale.ReconcileTotal(name)
+			if err != nil {
+				return false, err
+			}
+
+			depth, err := scale.QueueDepth(name)
+
+			return reconciles >= float64(size) && depth == 0, err
+		}); err != nil {
+			b.Fatal(err)
+		}
+
+		syncDuration := time.Since(start)
+
+		b.StopTimer()
+
+		reconciles, err := scale.ReconcileTotal(name)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		heapGrowth := int64(scale.HeapInUse()) - int64(heapBefore) //nolint:gosec
+
+		counter.Reset()
+		time.Sleep(steadyState)
+
+		scale.Result{
+			Resources:           size,
+			SyncDuration:        syncDuration,
+			Reconciles:          reconciles,
+			HeapGrowth:          heapGrowth,
+			SteadyStateRequests: counter.Reset(),
+			SteadyStateDuration: steadyState,
+		}.Report(b)
+
+		mgrCancel()
+
+		if err := <-mgrDone; err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// createScaleMachinePairs creates a MAPI and a CAPI namespace, with size MAPI machines authoritative in the Machine
+// API, and their CAPI machines and AWSMachines, and returns the namespaces.
+func createScaleMachinePairs(ctx context.Context, b *testing.B, cl client.Client, size int) (string, string) {
+	b.Helper()
+
+	mapiNamespace := corev1resourcebuilder.Namespace().WithGenerateName("openshift-machine-api-").Build()
+	capiNamespace := corev1resourcebuilder.Namespace().WithGenerateName("openshift-cluster-api-").Build()
+
+	for _, namespace := range []*corev1.Namespace{mapiNamespace, capiNamespace} {
+		if err := cl.Create(ctx, namespace); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	if err := scale.ForEach(ctx, size, func(ctx context.Context, i int) error {
+		name := fmt.Sprintf("machine-%d", i)
+
+		mapiMachine := machinev1resourcebuilder.Machine().
+			WithNamespace(mapiNamespace.GetName()).
+			WithName(name).
+			WithProviderSpecBuilder(machinev1resourcebuilder.AWSProviderSpec()).
+			Build()
+		if err := cl.Create(ctx, mapiMachine); err != nil {
+			return fmt.Errorf("failed to create MAPI machine %s: %w", name, err)
+		}
+
+		mapiMachine.Status.AuthoritativeAPI = machinev1beta1.MachineAuthorityMachineAPI
+		if err := cl.Status().Update(ctx, mapiMachine); err != nil {
+			return fmt.Errorf("failed to set the authority of MAPI machine %s: %w", name, err)
+		}
+
+		awsMachine := capav1builder.AWSMachine().
+			WithNamespace(capiNamespace.GetName()).
+			WithName(name).
+			WithInstanceType("m5.large").
+			Build()
+		if err := cl.Create(ctx, awsMachine); err != nil {
+			return fmt.Errorf("failed to create AWSMachine %s: %w", name, err)
+		}
+
+		capiMachine := capiv1resourcebuilder.Machine().
+			WithNamespace(capiNamespace.GetName()).
+			WithName(name).
+			WithClusterName("cluster-foo").
+			WithInfrastructureRef(corev1.ObjectReference{
+				APIVersion: capav1beta2.GroupVersion.String(),
+				Kind:       "AWSMachine",
+				Name:       name,
+			}).
+			Build()
+		capiMachine.Annotations = map[string]string{capiv1beta1.PausedAnnotation: ""}
+
+		if err := cl.Create(ctx, capiMachine); err != nil {
+			return fmt.Errorf("failed to create CAPI machine %s: %w", name, err)
+		}
+
+		return nil
+	}); err != nil {
+		b.Fatal(err)
+	}
+
+	return mapiNamespace.GetName(), capiNamespace.GetName()
+}
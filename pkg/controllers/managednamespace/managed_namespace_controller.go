@@ -0,0 +1,279 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package managednamespace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+)
+
+const (
+	controllerName = "ManagedNamespaceController"
+
+	// SecuritySettingsChangedReason is the reason of the warning event emitted when the security labels of the
+	// namespace were changed, before they are restored.
+	SecuritySettingsChangedReason = "SecuritySettingsChanged"
+
+	// MetadataRestoredReason is the reason of the event emitted when the other labels, or annotations, of the
+	// namespace were changed, and are restored.
+	MetadataRestoredReason = "NamespaceMetadataRestored"
+
+	podSecurityLabelPrefix = "pod-security.kubernetes.io/"
+	runLevelLabel          = "openshift.io/run-level"
+)
+
+// desiredLabels are the labels of the namespace owned by the controller, the namespace manifest sets them too.
+//
+//nolint:gochecknoglobals
+var desiredLabels = map[string]string{
+	runLevelLabel:                      "0",
+	"openshift.io/cluster-monitoring":  "true",
+	podSecurityLabelPrefix + "enforce": "privileged",
+	podSecurityLabelPrefix + "audit":   "privileged",
+	podSecurityLabelPrefix + "warn":    "privileged",
+}
+
+// desiredAnnotations are the annotations of the namespace owned by the controller. The release annotations are left
+// to the cluster version operator.
+//
+//nolint:gochecknoglobals
+var desiredAnnotations = map[string]string{
+	"openshift.io/node-selector":    "",
+	"workload.openshift.io/allowed": "management",
+}
+
+// ManagedNamespaceReconciler owns the labels and annotations of the CAPI namespace, such as its pod security and
+// monitoring labels, and the baseline NetworkPolicies of its pods. The drifted labels, annotations and policies are
+// restored. When NetworkPolicies is false, the policies are removed instead.
+type ManagedNamespaceReconciler struct {
+	client.Client
+	Recorder record.EventRecorder
+
+	Namespace       string
+	NetworkPolicies bool
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ManagedNamespaceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	toNamespace := handler.EnqueueRequestsFromMapFunc(func(context.Context, client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: client.ObjectKey{Name: r.Namespace}}}
+	})
+
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named(controllerName).
+		For(&corev1.Namespace{}, builder.WithPredicates(namePredicate(r.Namespace))).
+		Watches(&networkingv1.NetworkPolicy{}, toNamespace, builder.WithPredicates(
+			namePredicate(NetworkPolicyNames...),
+			predicate.NewPredicateFuncs(func(obj client.Object) bool { return obj.GetNamespace() == r.Namespace }),
+		)).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create controller: %w", err)
+	}
+
+	r.Client = mgr.GetClient()
+	r.Recorder = mgr.GetEventRecorderFor(controllerName)
+
+	return nil
+}
+
+// Reconcile restores the labels and annotations of the namespace, and creates its NetworkPolicies, or restores them
+// when they drifted. They are removed when the NetworkPolicies are disabled.
+func (r *ManagedNamespaceReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (ctrl.Result, error) {
+	logger := ctrl.LoggerFrom(ctx).WithName(controllerName)
+	ctx = ctrl.LoggerInto(ctx, logger)
+
+	namespace := &corev1.Namespace{}
+	if err := r.Get(ctx, client.ObjectKey{Name: r.Namespace}, namespace); apierrors.IsNotFound(err) {
+		// The namespace is created by the cluster version operator, it is reconciled once it exists.
+		logger.Info("Namespace not found, waiting for it to be created", "namespace", r.Namespace)
+
+		return ctrl.Result{}, nil
+	} else if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get namespace %s: %w", r.Namespace, err)
+	}
+
+	if !namespace.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.ensureNamespaceMetadata(ctx, namespace); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if !r.NetworkPolicies {
+		return ctrl.Result{}, removeNetworkPolicies(ctx, r.Client, r.Namespace)
+	}
+
+	var errs []error
+
+	for _, policy := range desiredNetworkPolicies(r.Namespace) {
+		if err := ensureNetworkPolicy(ctx, r.Client, policy); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return ctrl.Result{}, errors.Join(errs...)
+}
+
+// ensureNamespaceMetadata restores the labels and annotations of the namespace which differ from the desired ones.
+// The other labels and annotations are left untouched. A warning event is emitted when the security labels changed.
+func (r *ManagedNamespaceReconciler) ensureNamespaceMetadata(ctx context.Context, namespace *corev1.Namespace) error {
+	driftedLabels := driftedKeys(namespace.GetLabels(), desiredLabels)
+	driftedAnnotations := driftedKeys(namespace.GetAnnotations(), desiredAnnotations)
+
+	if len(driftedLabels) == 0 && len(driftedAnnotations) == 0 {
+		return nil
+	}
+
+	patchBase := client.MergeFrom(namespace.DeepCopy())
+
+	labels := namespace.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	for _, key := range driftedLabels {
+		labels[key] = desiredLabels[key]
+	}
+
+	annotations := namespace.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	for _, key := range driftedAnnotations {
+		annotations[key] = desiredAnnotations[key]
+	}
+
+	namespace.SetLabels(labels)
+	namespace.SetAnnotations(annotations)
+
+	if err := r.Patch(ctx, namespace, patchBase); err != nil {
+		return fmt.Errorf("failed to restore the metadata of namespace %s: %w", namespace.GetName(), err)
+	}
+
+	drifted := slices.Concat(driftedLabels, driftedAnnotations)
+	ctrl.LoggerFrom(ctx).Info("Restored drifted namespace metadata", "namespace", namespace.GetName(), "keys", drifted)
+
+	if r.Recorder == nil {
+		return nil
+	}
+
+	if securityLabels := slices.DeleteFunc(slices.Clone(driftedLabels), func(key string) bool { return !isSecurityLabel(key) }); len(securityLabels) > 0 {
+		r.Recorder.Eventf(namespace, corev1.EventTypeWarning, SecuritySettingsChangedReason,
+			"The security labels %s of the namespace were changed, they are restored", strings.Join(securityLabels, ", "))
+	} else {
+		r.Recorder.Eventf(namespace, corev1.EventTypeNormal, MetadataRestoredReason,
+			"The labels and annotations %s of the namespace were changed, they are restored", strings.Join(drifted, ", "))
+	}
+
+	return nil
+}
+
+// driftedKeys returns the sorted keys whose current value is missing, or differs from the desired one.
+func driftedKeys(current, desired map[string]string) []string {
+	var drifted []string
+
+	for key, value := range desired {
+		if currentValue, ok := current[key]; !ok || currentValue != value {
+			drifted = append(drifted, key)
+		}
+	}
+
+	sort.Strings(drifted)
+
+	return drifted
+}
+
+// isSecurityLabel returns whether the label relaxes, or restricts, the security of the pods of the namespace.
+func isSecurityLabel(key string) bool {
+	return strings.HasPrefix(key, podSecurityLabelPrefix) || key == runLevelLabel
+}
+
+// ensureNetworkPolicy creates the NetworkPolicy, or restores its spec and labels when they differ.
+func ensureNetworkPolicy(ctx context.Context, cl client.Client, desired *networkingv1.NetworkPolicy) error {
+	logger := ctrl.LoggerFrom(ctx)
+
+	existing := &networkingv1.NetworkPolicy{}
+	if err := cl.Get(ctx, client.ObjectKeyFromObject(desired), existing); apierrors.IsNotFound(err) {
+		if err := cl.Create(ctx, desired); err != nil {
+			return fmt.Errorf("failed to create NetworkPolicy %s: %w", desired.GetName(), err)
+		}
+
+		logger.Info("Created NetworkPolicy", "name", desired.GetName())
+
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get NetworkPolicy %s: %w", desired.GetName(), err)
+	}
+
+	if equality.Semantic.DeepEqual(existing.Spec, desired.Spec) && util.HasManagedByLabel(existing) {
+		return nil
+	}
+
+	existing.Spec = desired.Spec
+	util.SetManagedByLabel(existing)
+
+	if err := cl.Update(ctx, existing); err != nil {
+		return fmt.Errorf("failed to update NetworkPolicy %s: %w", desired.GetName(), err)
+	}
+
+	logger.Info("Restored drifted NetworkPolicy", "name", desired.GetName())
+
+	return nil
+}
+
+// removeNetworkPolicies deletes the NetworkPolicies owned by the controller, ignoring the missing ones.
+func removeNetworkPolicies(ctx context.Context, cl client.Client, namespace string) error {
+	var errs []error
+
+	for _, name := range NetworkPolicyNames {
+		policy := &networkingv1.NetworkPolicy{}
+		policy.SetName(name)
+		policy.SetNamespace(namespace)
+
+		if err := cl.Delete(ctx, policy); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete NetworkPolicy %s: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// namePredicate filters the events of the objects with one of the given names.
+func namePredicate(names ...string) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return slices.Contains(names, obj.GetName())
+	})
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package managednamespace
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest/komega"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+)
+
+var _ = Describe("driftedKeys", func() {
+	It("should return the sorted keys missing or differing from the desired ones", func() {
+		Expect(driftedKeys(
+			map[string]string{"a": "1", "b": "2", "extra": "kept"},
+			map[string]string{"c": "3", "b": "changed", "a": "1"},
+		)).To(Equal([]string{"b", "c"}))
+	})
+
+	It("should return no keys when the desired keys are set", func() {
+		Expect(driftedKeys(map[string]string{"a": "1", "extra": "kept"}, map[string]string{"a": "1"})).To(BeEmpty())
+	})
+})
+
+var _ = Describe("desiredNetworkPolicies", func() {
+	It("should deny the traffic of the pods of the namespace which is not allowed", func() {
+		policies := desiredNetworkPolicies("capi-namespace")
+
+		Expect(policies).To(HaveLen(len(NetworkPolicyNames)))
+		Expect(policies).To(HaveEach(HaveField("Namespace", "capi-namespace")))
+		Expect(policies).To(HaveEach(WithTransform(util.HasManagedByLabel, BeTrue())))
+		Expect(policies).To(HaveEach(HaveField("Spec.PodSelector.MatchLabels", BeEmpty())))
+
+		Expect(policies[0].Spec.PolicyTypes).To(ConsistOf(networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress))
+		Expect(policies[0].Spec.Ingress).To(BeEmpty())
+		Expect(policies[0].Spec.Egress).To(BeEmpty())
+	})
+
+	It("should only allow the ingress on the webhook port and from the cluster monitoring", func() {
+		policies := desiredNetworkPolicies("capi-namespace")
+
+		Expect(policies[2].Spec.Ingress).To(ConsistOf(HaveField("Ports", ConsistOf(HaveField("Port.IntVal", BeEquivalentTo(webhookPort))))))
+		Expect(policies[3].Spec.Ingress).To(ConsistOf(HaveField("From", ConsistOf(
+			HaveField("NamespaceSelector.MatchLabels", HaveKeyWithValue(corev1.LabelMetadataName, monitoringNamespace)),
+		))))
+	})
+})
+
+var _ = Describe("Managed namespace controller", func() {
+	var (
+		k         komega.Komega
+		namespace *corev1.Namespace
+		recorder  *record.FakeRecorder
+	)
+
+	reconcileNamespace := func(networkPolicies bool) {
+		reconciler := &ManagedNamespaceReconciler{Client: cl, Recorder: recorder, Namespace: namespace.GetName(), NetworkPolicies: networkPolicies}
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(namespace)})
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	networkPolicy := func(name string) *networkingv1.NetworkPolicy {
+		policy := &networkingv1.NetworkPolicy{}
+		policy.SetName(name)
+		policy.SetNamespace(namespace.GetName())
+
+		return policy
+	}
+
+	BeforeEach(func() {
+		k = komega.New(cl)
+		recorder = record.NewFakeRecorder(10)
+
+		namespace = &corev1.Namespace{}
+		namespace.SetGenerateName("managed-namespace-")
+		namespace.SetLabels(map[string]string{"user": "label"})
+		Expect(cl.Create(ctx, namespace)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(removeNetworkPolicies(ctx, cl, namespace.GetName())).To(Succeed())
+		Expect(cl.Delete(ctx, namespace)).To(Succeed())
+	})
+
+	It("should set the labels and annotations of the namespace, keeping the other ones", func() {
+		reconcileNamespace(true)
+
+		Eventually(k.Object(namespace)).Should(SatisfyAll(
+			HaveField("Labels", HaveKeyWithValue("pod-security.kubernetes.io/enforce", "privileged")),
+			HaveField("Labels", HaveKeyWithValue("openshift.io/cluster-monitoring", "true")),
+			HaveField("Labels", HaveKeyWithValue("user", "label")),
+			HaveField("Annotations", HaveKeyWithValue("workload.openshift.io/allowed", "management")),
+		))
+	})
+
+	It("should restore the changed security labels, and report them", func() {
+		reconcileNamespace(true)
+		Eventually(recorder.Events).Should(Receive())
+
+		Eventually(k.Update(namespace, func() {
+			namespace.Labels["pod-security.kubernetes.io/enforce"] = "restricted"
+		})).Should(Succeed())
+
+		reconcileNamespace(true)
+
+		Eventually(k.Object(namespace)).Should(HaveField("Labels", HaveKeyWithValue("pod-security.kubernetes.io/enforce", "privileged")))
+		Eventually(recorder.Events).Should(Receive(SatisfyAll(
+			ContainSubstring(corev1.EventTypeWarning),
+			ContainSubstring(SecuritySettingsChangedReason),
+			ContainSubstring("pod-security.kubernetes.io/enforce"),
+		)))
+	})
+
+	It("should create the NetworkPolicies, and restore a modified one", func() {
+		reconcileNamespace(true)
+
+		for _, name := range NetworkPolicyNames {
+			Eventually(k.Get(networkPolicy(name))).Should(Succeed())
+		}
+
+		modified := networkPolicy(AllowEgressPolicyName)
+		Eventually(k.Update(modified, func() {
+			modified.Spec.Egress = nil
+		})).Should(Succeed())
+
+		reconcileNamespace(true)
+
+		Eventually(k.Object(modified)).Should(HaveField("Spec.Egress", HaveLen(1)))
+	})
+
+	It("should remove the NetworkPolicies when they are disabled", func() {
+		reconcileNamespace(true)
+		reconcileNamespace(false)
+
+		for _, name := range NetworkPolicyNames {
+			Eventually(k.Get(networkPolicy(name))).Should(WithTransform(apierrors.IsNotFound, BeTrue()))
+		}
+	})
+})
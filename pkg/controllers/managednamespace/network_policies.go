@@ -0,0 +1,109 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package managednamespace
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+)
+
+const (
+	// DefaultDenyPolicyName is the NetworkPolicy denying the traffic of the pods of the CAPI namespace which is not
+	// allowed by the other policies.
+	DefaultDenyPolicyName = "cluster-api-default-deny"
+
+	// AllowEgressPolicyName is the NetworkPolicy allowing the egress traffic of the pods of the CAPI namespace, as the
+	// providers talk to the API server and to the cloud APIs.
+	AllowEgressPolicyName = "cluster-api-allow-egress"
+
+	// AllowWebhooksPolicyName is the NetworkPolicy allowing the API server to call the webhooks of the operator and of
+	// the providers.
+	AllowWebhooksPolicyName = "cluster-api-allow-webhooks"
+
+	// AllowMetricsPolicyName is the NetworkPolicy allowing the cluster monitoring to scrape the metrics of the pods of
+	// the CAPI namespace.
+	AllowMetricsPolicyName = "cluster-api-allow-metrics"
+
+	// webhookPort is the port the operator and the providers serve their webhooks on.
+	webhookPort = 9443
+
+	// monitoringNamespace is the namespace of the cluster monitoring Prometheus.
+	monitoringNamespace = "openshift-monitoring"
+)
+
+// NetworkPolicyNames are the names of the NetworkPolicies owned by the controller.
+//
+//nolint:gochecknoglobals
+var NetworkPolicyNames = []string{DefaultDenyPolicyName, AllowEgressPolicyName, AllowWebhooksPolicyName, AllowMetricsPolicyName}
+
+// desiredNetworkPolicies returns the baseline NetworkPolicies of the pods of the namespace: all their traffic is
+// denied, but their egress, the webhook calls and the metrics scraping.
+func desiredNetworkPolicies(namespace string) []*networkingv1.NetworkPolicy {
+	allPods := metav1.LabelSelector{}
+
+	return []*networkingv1.NetworkPolicy{
+		newNetworkPolicy(namespace, DefaultDenyPolicyName, networkingv1.NetworkPolicySpec{
+			PodSelector: allPods,
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+		}),
+		newNetworkPolicy(namespace, AllowEgressPolicyName, networkingv1.NetworkPolicySpec{
+			PodSelector: allPods,
+			Egress:      []networkingv1.NetworkPolicyEgressRule{{}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+		}),
+		// The API server may run on the host network, the webhooks are reachable from any peer.
+		newNetworkPolicy(namespace, AllowWebhooksPolicyName, networkingv1.NetworkPolicySpec{
+			PodSelector: allPods,
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				Ports: []networkingv1.NetworkPolicyPort{{
+					Protocol: ptr.To(corev1.ProtocolTCP),
+					Port:     ptr.To(intstr.FromInt32(webhookPort)),
+				}},
+			}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		}),
+		// The metrics ports differ across the providers, all the ports are open to the cluster monitoring.
+		newNetworkPolicy(namespace, AllowMetricsPolicyName, networkingv1.NetworkPolicySpec{
+			PodSelector: allPods,
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From: []networkingv1.NetworkPolicyPeer{{
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{corev1.LabelMetadataName: monitoringNamespace},
+					},
+				}},
+			}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		}),
+	}
+}
+
+func newNetworkPolicy(namespace, name string, spec networkingv1.NetworkPolicySpec) *networkingv1.NetworkPolicy {
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: spec,
+	}
+	util.SetManagedByLabel(policy)
+
+	return policy
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package migrationsummary
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/synccommon"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+)
+
+const (
+	controllerName = "MigrationSummaryController"
+
+	// SummaryKey is the ConfigMap key holding the JSON migration summary.
+	SummaryKey = "summary.json"
+
+	// unsetAuthority counts the resources whose authoritative API was not reported yet.
+	unsetAuthority machinev1beta1.MachineAuthority = "Unset"
+)
+
+// Summary summarizes the migration of the Machine API resources to Cluster API.
+type Summary struct {
+	// Machines summarizes the MAPI machines.
+	Machines ResourceSummary `json:"machines"`
+
+	// MachineSets summarizes the MAPI machine sets.
+	MachineSets ResourceSummary `json:"machineSets"`
+
+	// LastErrors are the last synchronization error reported in each namespace, by namespace.
+	LastErrors map[string]SyncError `json:"lastErrors"`
+}
+
+// ResourceSummary counts the resources of a kind by authoritative API.
+type ResourceSummary struct {
+	// AuthoritativeAPIs counts the resources by the authoritative API reported in their status.
+	// The resources whose authoritative API is not reported yet are counted as Unset.
+	AuthoritativeAPIs map[machinev1beta1.MachineAuthority]int `json:"authoritativeAPIs"`
+
+	// PendingMigrations counts the resources whose requested authoritative API is not the one reported in their status.
+	PendingMigrations int `json:"pendingMigrations"`
+}
+
+// SyncError is a synchronization error reported by the Synchronized condition of a resource.
+type SyncError struct {
+	// Kind and Name identify the resource.
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+
+	// Reason and Message are the reason and message of the Synchronized condition.
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+
+	// Time is when the Synchronized condition last became False.
+	Time metav1.Time `json:"time"`
+}
+
+// MigrationSummaryReconciler summarizes the migration of the MAPI machines and machine sets in the
+// consts.MigrationSummaryConfigMapName ConfigMap, so that the console and Insights rules can report the adoption of
+// the migration across the fleet.
+type MigrationSummaryReconciler struct {
+	client.Client
+
+	MAPINamespace string
+	CAPINamespace string
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MigrationSummaryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// All the watched resources contribute to the same summary.
+	toSummary := handler.EnqueueRequestsFromMapFunc(func(context.Context, client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: client.ObjectKey{Namespace: r.CAPINamespace, Name: consts.MigrationSummaryConfigMapName}}}
+	})
+
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named(util.NamespacedControllerName(controllerName, r.MAPINamespace)).
+		// The summary is watched to restore it when it is modified or deleted.
+		For(&corev1.ConfigMap{}, builder.WithPredicates(util.FilterNamespace(r.CAPINamespace), summaryPredicate())).
+		Watches(&machinev1beta1.Machine{}, toSummary, builder.WithPredicates(util.FilterNamespace(r.MAPINamespace))).
+		Watches(&machinev1beta1.MachineSet{}, toSummary, builder.WithPredicates(util.FilterNamespace(r.MAPINamespace))).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create controller: %w", err)
+	}
+
+	r.Client = mgr.GetClient()
+
+	return nil
+}
+
+// Reconcile writes the migration summary.
+func (r *MigrationSummaryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := ctrl.LoggerFrom(ctx).WithName(controllerName)
+	ctx = ctrl.LoggerInto(ctx, logger)
+
+	mapiMachines := &machinev1beta1.MachineList{}
+	if err := r.List(ctx, mapiMachines, client.InNamespace(r.MAPINamespace)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list MAPI machines: %w", err)
+	}
+
+	mapiMachineSets := &machinev1beta1.MachineSetList{}
+	if err := r.List(ctx, mapiMachineSets, client.InNamespace(r.MAPINamespace)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list MAPI machine sets: %w", err)
+	}
+
+	summary := summarize(mapiMachines.Items, mapiMachineSets.Items)
+
+	summaryJSON, err := json.Marshal(summary)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to marshal migration summary: %w", err)
+	}
+
+	cm := &corev1.ConfigMap{}
+	cm.SetNamespace(r.CAPINamespace)
+	cm.SetName(consts.MigrationSummaryConfigMapName)
+
+	result, err := controllerutil.CreateOrPatch(ctx, r.Client, cm, func() error {
+		util.SetManagedByLabel(cm)
+		cm.Data = map[string]string{SummaryKey: string(summaryJSON)}
+
+		return nil
+	})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to write migration summary: %w", err)
+	}
+
+	if result != controllerutil.OperationResultNone {
+		logger.Info("Updated migration summary", "pendingMachineMigrations", summary.Machines.PendingMigrations,
+			"pendingMachineSetMigrations", summary.MachineSets.PendingMigrations)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// summarize returns the migration summary of the given MAPI machines and machine sets.
+func summarize(mapiMachines []machinev1beta1.Machine, mapiMachineSets []machinev1beta1.MachineSet) Summary {
+	summary := Summary{
+		Machines:    ResourceSummary{AuthoritativeAPIs: map[machinev1beta1.MachineAuthority]int{}},
+		MachineSets: ResourceSummary{AuthoritativeAPIs: map[machinev1beta1.MachineAuthority]int{}},
+		LastErrors:  map[string]SyncError{},
+	}
+
+	for i := range mapiMachines {
+		machine := &mapiMachines[i]
+		summary.Machines.add(machine.Spec.AuthoritativeAPI, machine.Status.AuthoritativeAPI)
+		summary.addError("Machine", machine, machine.Status.Conditions)
+	}
+
+	for i := range mapiMachineSets {
+		machineSet := &mapiMachineSets[i]
+		summary.MachineSets.add(machineSet.Spec.AuthoritativeAPI, machineSet.Status.AuthoritativeAPI)
+		summary.addError("MachineSet", machineSet, machineSet.Status.Conditions)
+	}
+
+	return summary
+}
+
+// add counts a resource with the given requested and reported authoritative APIs.
+func (s *ResourceSummary) add(requested, reported machinev1beta1.MachineAuthority) {
+	if reported == "" {
+		reported = unsetAuthority
+	}
+
+	s.AuthoritativeAPIs[reported]++
+
+	if requested != "" && requested != reported {
+		s.PendingMigrations++
+	}
+}
+
+// addError records the synchronization error of the resource, when it is the last one of its namespace.
+// Errors reported at the same time are ordered by kind and name, so that the summary is stable.
+func (s *Summary) addError(kind string, obj client.Object, conditions []machinev1beta1.Condition) {
+	condition := synccommon.FindCondition(conditions, consts.SynchronizedCondition)
+	if condition == nil || condition.Status != corev1.ConditionFalse {
+		return
+	}
+
+	syncErr := SyncError{
+		Kind:    kind,
+		Name:    obj.GetName(),
+		Reason:  condition.Reason,
+		Message: condition.Message,
+		Time:    condition.LastTransitionTime,
+	}
+
+	if last, ok := s.LastErrors[obj.GetNamespace()]; ok {
+		if last.Time.After(syncErr.Time.Time) || (last.Time.Equal(&syncErr.Time) && last.Kind+"/"+last.Name < kind+"/"+syncErr.Name) {
+			return
+		}
+	}
+
+	s.LastErrors[obj.GetNamespace()] = syncErr
+}
+
+// summaryPredicate filters the events of the summary ConfigMap.
+func summaryPredicate() predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetName() == consts.MigrationSummaryConfigMapName
+	})
+}
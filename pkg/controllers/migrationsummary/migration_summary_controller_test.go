@@ -0,0 +1,156 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package migrationsummary
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	corev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/core/v1"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest/komega"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/test"
+)
+
+var _ = Describe("summarize", func() {
+	notSynchronized := func(message string, at time.Time) []machinev1beta1.Condition {
+		return []machinev1beta1.Condition{{
+			Type:               consts.SynchronizedCondition,
+			Status:             corev1.ConditionFalse,
+			Reason:             "FailedToConvert",
+			Message:            message,
+			LastTransitionTime: metav1.NewTime(at),
+		}}
+	}
+
+	It("should count the resources by authoritative API and pending migrations", func() {
+		machines := []machinev1beta1.Machine{
+			*machinev1resourcebuilder.Machine().WithName("mapi").
+				WithAuthoritativeAPI(machinev1beta1.MachineAuthorityMachineAPI).
+				WithAuthoritativeAPIStatus(machinev1beta1.MachineAuthorityMachineAPI).Build(),
+			*machinev1resourcebuilder.Machine().WithName("migrating").
+				WithAuthoritativeAPI(machinev1beta1.MachineAuthorityClusterAPI).
+				WithAuthoritativeAPIStatus(machinev1beta1.MachineAuthorityMigrating).Build(),
+			*machinev1resourcebuilder.Machine().WithName("pending").
+				WithAuthoritativeAPI(machinev1beta1.MachineAuthorityClusterAPI).
+				WithAuthoritativeAPIStatus(machinev1beta1.MachineAuthorityMachineAPI).Build(),
+			*machinev1resourcebuilder.Machine().WithName("unset").
+				WithAuthoritativeAPI(machinev1beta1.MachineAuthorityMachineAPI).Build(),
+		}
+		machineSets := []machinev1beta1.MachineSet{
+			*machinev1resourcebuilder.MachineSet().WithName("capi").
+				WithAuthoritativeAPI(machinev1beta1.MachineAuthorityClusterAPI).
+				WithAuthoritativeAPIStatus(machinev1beta1.MachineAuthorityClusterAPI).Build(),
+		}
+
+		summary := summarize(machines, machineSets)
+
+		Expect(summary.Machines).To(Equal(ResourceSummary{
+			AuthoritativeAPIs: map[machinev1beta1.MachineAuthority]int{
+				machinev1beta1.MachineAuthorityMachineAPI: 2,
+				machinev1beta1.MachineAuthorityMigrating:  1,
+				unsetAuthority:                            1,
+			},
+			PendingMigrations: 3,
+		}))
+		Expect(summary.MachineSets).To(Equal(ResourceSummary{
+			AuthoritativeAPIs: map[machinev1beta1.MachineAuthority]int{machinev1beta1.MachineAuthorityClusterAPI: 1},
+		}))
+		Expect(summary.LastErrors).To(BeEmpty())
+	})
+
+	It("should report the last synchronization error of each namespace", func() {
+		now := time.Now().Truncate(time.Second)
+
+		machines := []machinev1beta1.Machine{
+			*machinev1resourcebuilder.Machine().WithNamespace("ns-a").WithName("older").
+				WithConditions(notSynchronized("older error", now.Add(-time.Minute))).Build(),
+			*machinev1resourcebuilder.Machine().WithNamespace("ns-a").WithName("newer").
+				WithConditions(notSynchronized("newer error", now)).Build(),
+		}
+		machineSets := []machinev1beta1.MachineSet{
+			*machinev1resourcebuilder.MachineSet().WithNamespace("ns-b").WithName("foo").
+				WithConditions(notSynchronized("machine set error", now)).Build(),
+		}
+
+		Expect(summarize(machines, machineSets).LastErrors).To(Equal(map[string]SyncError{
+			"ns-a": {Kind: "Machine", Name: "newer", Reason: "FailedToConvert", Message: "newer error", Time: metav1.NewTime(now)},
+			"ns-b": {Kind: "MachineSet", Name: "foo", Reason: "FailedToConvert", Message: "machine set error", Time: metav1.NewTime(now)},
+		}))
+	})
+})
+
+var _ = Describe("Migration summary controller", func() {
+	var k komega.Komega
+	var reconciler *MigrationSummaryReconciler
+
+	var mapiNamespace, capiNamespace *corev1.Namespace
+	var mapiMachine *machinev1beta1.Machine
+	var summary *corev1.ConfigMap
+
+	BeforeEach(func() {
+		k = komega.New(cl)
+
+		By("Setting up the namespaces for the test")
+		mapiNamespace = corev1resourcebuilder.Namespace().WithGenerateName("openshift-machine-api-").Build()
+		Expect(cl.Create(ctx, mapiNamespace)).To(Succeed())
+
+		capiNamespace = corev1resourcebuilder.Namespace().WithGenerateName("openshift-cluster-api-").Build()
+		Expect(cl.Create(ctx, capiNamespace)).To(Succeed())
+
+		reconciler = &MigrationSummaryReconciler{
+			Client:        cl,
+			MAPINamespace: mapiNamespace.GetName(),
+			CAPINamespace: capiNamespace.GetName(),
+		}
+
+		By("Creating a MAPI machine being migrated to Cluster API")
+		mapiMachine = machinev1resourcebuilder.Machine().
+			WithNamespace(mapiNamespace.GetName()).
+			WithName("foo").
+			WithAuthoritativeAPI(machinev1beta1.MachineAuthorityClusterAPI).
+			Build()
+		Expect(cl.Create(ctx, mapiMachine)).To(Succeed())
+		Eventually(k.UpdateStatus(mapiMachine, func() {
+			mapiMachine.Status.AuthoritativeAPI = machinev1beta1.MachineAuthorityMigrating
+		})).Should(Succeed())
+
+		summary = &corev1.ConfigMap{}
+		summary.SetNamespace(capiNamespace.GetName())
+		summary.SetName(consts.MigrationSummaryConfigMapName)
+	})
+
+	AfterEach(func() {
+		Expect(test.CleanupAndWait(ctx, cl, mapiMachine, summary)).To(Succeed())
+	})
+
+	It("should write the migration summary", func() {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(summary)})
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(k.Object(summary)).Should(HaveField("Data", HaveKeyWithValue(SummaryKey,
+			`{"machines":{"authoritativeAPIs":{"Migrating":1},"pendingMigrations":1},`+
+				`"machineSets":{"authoritativeAPIs":{},"pendingMigrations":0},"lastErrors":{}}`)))
+	})
+})
@@ -0,0 +1,256 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package mirrorcleanup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+)
+
+const (
+	controllerName = "MirrorCleanupController"
+
+	// DefaultGracePeriod is how long a mirror must have been orphaned before it is deleted, when none is configured.
+	DefaultGracePeriod = 10 * time.Minute
+
+	// orphanedSinceAnnotation records when the CAPI Machine was first found orphaned, the grace period starts from it.
+	orphanedSinceAnnotation = "cluster-api.openshift.io/orphaned-since"
+
+	reasonOrphanedMirrorDeleted = "OrphanedMirrorDeleted"
+)
+
+// MirrorCleanupReconciler deletes the CAPI Machines, along with their InfraMachine, that mirror a MAPI Machine
+// which no longer exists. Such mirrors are left behind when a MAPI Machine is deleted before it was ever migrated,
+// and as they are paused nothing else ever acts on them.
+// Mirrors are identified as paused CAPI Machines that were written by the machine sync controller.
+// They are only deleted once they have been orphaned for the grace period, so that a MAPI Machine that is recreated,
+// or that the cache has not seen yet, can reclaim its mirror.
+type MirrorCleanupReconciler struct {
+	client.Client
+	Recorder record.EventRecorder
+
+	MAPINamespace string
+	CAPINamespace string
+
+	// GracePeriod is how long a mirror must have been orphaned before it is deleted, defaults to DefaultGracePeriod.
+	GracePeriod time.Duration
+	// Shard restricts the reconciler to a subset of the machines, so that the work can be split across replicas.
+	Shard util.Shard
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MirrorCleanupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.GracePeriod == 0 {
+		r.GracePeriod = DefaultGracePeriod
+	}
+
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named(util.NamespacedControllerName(controllerName, r.MAPINamespace)).
+		For(&capiv1beta1.Machine{}, builder.WithPredicates(util.FilterNamespace(r.CAPINamespace), util.FilterShard(r.Shard))).
+		// The MAPI Machines are watched so that a mirror is reclaimed as soon as its counterpart is recreated.
+		Watches(
+			&machinev1beta1.Machine{},
+			handler.EnqueueRequestsFromMapFunc(util.RewriteNamespace(r.CAPINamespace)),
+			builder.WithPredicates(util.FilterNamespace(r.MAPINamespace), util.FilterShard(r.Shard)),
+		).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create controller: %w", err)
+	}
+
+	r.Client = mgr.GetClient()
+	r.Recorder = mgr.GetEventRecorderFor("mirror-cleanup-controller")
+
+	return nil
+}
+
+// Reconcile deletes the CAPI Machine once it has been an orphaned mirror for the grace period.
+func (r *MirrorCleanupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := ctrl.LoggerFrom(ctx).WithName(controllerName)
+	ctx = ctrl.LoggerInto(ctx, logger)
+
+	capiMachine := &capiv1beta1.Machine{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: r.CAPINamespace, Name: req.Name}, capiMachine); apierrors.IsNotFound(err) {
+		return ctrl.Result{}, nil
+	} else if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get CAPI machine: %w", err)
+	}
+
+	if !isMirror(capiMachine) {
+		return ctrl.Result{}, nil
+	}
+
+	mapiMachine := &machinev1beta1.Machine{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: r.MAPINamespace, Name: req.Name}, mapiMachine); err == nil {
+		// The mirror has a living counterpart, the sync controllers are in charge of it.
+		return ctrl.Result{}, r.clearOrphanedSince(ctx, capiMachine)
+	} else if !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, fmt.Errorf("failed to get MAPI machine: %w", err)
+	}
+
+	orphanedSince, err := r.markOrphaned(ctx, capiMachine)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if remaining := time.Until(orphanedSince.Add(r.GracePeriod)); remaining > 0 {
+		logger.Info("CAPI machine is an orphaned mirror, waiting for the grace period before deleting it", "remaining", remaining)
+		return ctrl.Result{RequeueAfter: remaining}, nil
+	}
+
+	return ctrl.Result{}, r.deleteOrphanedMirror(ctx, capiMachine)
+}
+
+// isMirror returns whether the CAPI Machine is a paused, and therefore non-authoritative, copy written by the sync controller.
+// CAPI Machines paused by anything else are left alone.
+func isMirror(capiMachine *capiv1beta1.Machine) bool {
+	_, synchronized := capiMachine.GetAnnotations()[consts.LastSyncTimeAnnotation]
+
+	return synchronized && annotations.HasPaused(capiMachine)
+}
+
+// markOrphaned returns when the CAPI Machine was first found orphaned, recording it on the machine the first time.
+func (r *MirrorCleanupReconciler) markOrphaned(ctx context.Context, capiMachine *capiv1beta1.Machine) (time.Time, error) {
+	if value, ok := capiMachine.GetAnnotations()[orphanedSinceAnnotation]; ok {
+		if orphanedSince, err := time.Parse(time.RFC3339, value); err == nil {
+			return orphanedSince, nil
+		}
+	}
+
+	orphanedSince := time.Now().UTC().Truncate(time.Second)
+
+	patchBase := client.MergeFrom(capiMachine.DeepCopy())
+	annotations.AddAnnotations(capiMachine, map[string]string{orphanedSinceAnnotation: orphanedSince.Format(time.RFC3339)})
+
+	if err := r.Patch(ctx, capiMachine, patchBase); err != nil {
+		return time.Time{}, fmt.Errorf("failed to mark CAPI machine as orphaned: %w", err)
+	}
+
+	return orphanedSince, nil
+}
+
+// clearOrphanedSince removes the orphaned since annotation from a mirror whose counterpart exists again.
+func (r *MirrorCleanupReconciler) clearOrphanedSince(ctx context.Context, capiMachine *capiv1beta1.Machine) error {
+	if _, ok := capiMachine.GetAnnotations()[orphanedSinceAnnotation]; !ok {
+		return nil
+	}
+
+	ctrl.LoggerFrom(ctx).Info("Orphaned mirror has been reclaimed by its MAPI machine")
+
+	patchBase := client.MergeFrom(capiMachine.DeepCopy())
+	delete(capiMachine.Annotations, orphanedSinceAnnotation)
+
+	if err := r.Patch(ctx, capiMachine, patchBase); err != nil {
+		return fmt.Errorf("failed to unmark CAPI machine as orphaned: %w", err)
+	}
+
+	return nil
+}
+
+// deleteOrphanedMirror deletes the InfraMachine of the mirror and then the mirror itself.
+// The finalizers are removed as well, as the paused controllers of the mirror never remove them. The instance is not
+// leaked by doing so, it belonged to the MAPI Machine, which took care of it when it was deleted.
+func (r *MirrorCleanupReconciler) deleteOrphanedMirror(ctx context.Context, capiMachine *capiv1beta1.Machine) error {
+	infraMachine, found, err := r.getInfraMachine(ctx, capiMachine)
+	if err != nil {
+		return err
+	}
+
+	if found {
+		if err := r.deleteAndRelease(ctx, infraMachine, capiMachine.GetName()); err != nil {
+			return err
+		}
+	}
+
+	return r.deleteAndRelease(ctx, capiMachine, capiMachine.GetName())
+}
+
+// getInfraMachine returns the InfraMachine referenced by the CAPI Machine, and whether it was found.
+func (r *MirrorCleanupReconciler) getInfraMachine(ctx context.Context, capiMachine *capiv1beta1.Machine) (client.Object, bool, error) {
+	ref := capiMachine.Spec.InfrastructureRef
+	if ref.Name == "" {
+		return nil, false, nil
+	}
+
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = capiMachine.GetNamespace()
+	}
+
+	infraMachine := &unstructured.Unstructured{}
+	infraMachine.SetGroupVersionKind(ref.GroupVersionKind())
+
+	if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, infraMachine); apierrors.IsNotFound(err) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, fmt.Errorf("failed to get InfraMachine %s/%s: %w", namespace, ref.Name, err)
+	}
+
+	return infraMachine, true, nil
+}
+
+// deleteAndRelease deletes the object, removes its finalizers and records an event about it.
+func (r *MirrorCleanupReconciler) deleteAndRelease(ctx context.Context, obj client.Object, mapiMachineName string) error {
+	if obj.GetDeletionTimestamp().IsZero() {
+		if err := r.Delete(ctx, obj); apierrors.IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to delete orphaned %s: %w", r.describe(obj), err)
+		}
+	}
+
+	if len(obj.GetFinalizers()) > 0 {
+		// No finalizer can be added to an object being deleted, so they can all be removed without an optimistic lock.
+		patchBase := client.MergeFrom(obj.DeepCopyObject().(client.Object)) //nolint:forcetypeassert
+		obj.SetFinalizers(nil)
+
+		if err := r.Patch(ctx, obj, patchBase); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to remove finalizers of orphaned %s: %w", r.describe(obj), err)
+		}
+	}
+
+	ctrl.LoggerFrom(ctx).Info("Deleted orphaned mirror", "object", r.describe(obj))
+
+	r.Recorder.Eventf(obj, corev1.EventTypeNormal, reasonOrphanedMirrorDeleted,
+		"Deleted as it was a paused mirror of MAPI machine %s, which no longer exists", mapiMachineName)
+
+	return nil
+}
+
+// describe returns a human readable reference to the object for errors and logs.
+func (r *MirrorCleanupReconciler) describe(obj client.Object) string {
+	kind := fmt.Sprintf("%T", obj)
+	if gvk, err := r.GroupVersionKindFor(obj); err == nil {
+		kind = gvk.Kind
+	}
+
+	return fmt.Sprintf("%s %s", kind, client.ObjectKeyFromObject(obj))
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package mirrorcleanup
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	capiv1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/core/v1beta1"
+	capav1builder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/infrastructure/v1beta2"
+	corev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/core/v1"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	capav1beta2 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest/komega"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/test"
+)
+
+var _ = Describe("Mirror cleanup controller", func() {
+	const (
+		machineName           = "foo"
+		capiProviderFinalizer = "machine.cluster.x-k8s.io"
+	)
+
+	var k komega.Komega
+	var reconciler *MirrorCleanupReconciler
+	var recorder *record.FakeRecorder
+
+	var mapiNamespace, capiNamespace *corev1.Namespace
+	var mapiMachine *machinev1beta1.Machine
+	var capiMachine *capiv1beta1.Machine
+	var awsMachine *capav1beta2.AWSMachine
+
+	reconcileMirror := func() reconcile.Result {
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{
+			NamespacedName: client.ObjectKey{Namespace: capiNamespace.GetName(), Name: machineName},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		return result
+	}
+
+	setOrphanedSince := func(orphanedSince time.Time) {
+		Eventually(k.Update(capiMachine, func() {
+			capiMachine.Annotations[orphanedSinceAnnotation] = orphanedSince.UTC().Format(time.RFC3339)
+		})).Should(Succeed())
+	}
+
+	BeforeEach(func() {
+		k = komega.New(cl)
+
+		By("Setting up the namespaces for the test")
+		mapiNamespace = corev1resourcebuilder.Namespace().WithGenerateName("openshift-machine-api-").Build()
+		Expect(cl.Create(ctx, mapiNamespace)).To(Succeed())
+
+		capiNamespace = corev1resourcebuilder.Namespace().WithGenerateName("openshift-cluster-api-").Build()
+		Expect(cl.Create(ctx, capiNamespace)).To(Succeed())
+
+		recorder = record.NewFakeRecorder(32)
+
+		reconciler = &MirrorCleanupReconciler{
+			Client:        cl,
+			Recorder:      recorder,
+			MAPINamespace: mapiNamespace.GetName(),
+			CAPINamespace: capiNamespace.GetName(),
+			GracePeriod:   time.Hour,
+		}
+
+		mapiMachine = machinev1resourcebuilder.Machine().
+			WithNamespace(mapiNamespace.GetName()).
+			WithName(machineName).
+			WithProviderSpecBuilder(machinev1resourcebuilder.AWSProviderSpec()).
+			Build()
+
+		By("Creating a paused CAPI mirror")
+		awsMachine = capav1builder.AWSMachine().
+			WithNamespace(capiNamespace.GetName()).
+			WithName(machineName).
+			WithInstanceType("m5.large").
+			Build()
+		awsMachine.Finalizers = []string{capav1beta2.MachineFinalizer}
+		Expect(cl.Create(ctx, awsMachine)).To(Succeed())
+
+		capiMachine = capiv1resourcebuilder.Machine().
+			WithNamespace(capiNamespace.GetName()).
+			WithName(machineName).
+			WithClusterName("cluster-foo").
+			WithAnnotations(map[string]string{
+				capiv1beta1.PausedAnnotation:  "",
+				consts.LastSyncTimeAnnotation: time.Now().UTC().Format(time.RFC3339),
+			}).
+			WithInfrastructureRef(corev1.ObjectReference{
+				APIVersion: capav1beta2.GroupVersion.String(),
+				Kind:       "AWSMachine",
+				Name:       awsMachine.GetName(),
+			}).
+			Build()
+		capiMachine.Finalizers = []string{capiProviderFinalizer}
+		Expect(cl.Create(ctx, capiMachine)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(test.CleanupAndWait(ctx, cl, mapiMachine, capiMachine, awsMachine)).To(Succeed())
+	})
+
+	It("should mark an orphaned mirror and wait for the grace period before deleting it", func() {
+		result := reconcileMirror()
+
+		Expect(result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+		Eventually(k.Object(capiMachine)).Should(HaveField("Annotations", HaveKey(orphanedSinceAnnotation)))
+		Consistently(k.Object(capiMachine)).Should(HaveField("DeletionTimestamp", BeNil()))
+	})
+
+	It("should delete an orphaned mirror and its InfraMachine after the grace period", func() {
+		setOrphanedSince(time.Now().Add(-2 * time.Hour))
+
+		reconcileMirror()
+
+		Eventually(k.Get(capiMachine)).Should(WithTransform(apierrors.IsNotFound, BeTrue()))
+		Eventually(k.Get(awsMachine)).Should(WithTransform(apierrors.IsNotFound, BeTrue()))
+		Expect(recorder.Events).To(HaveLen(2))
+		Expect(<-recorder.Events).To(ContainSubstring(reasonOrphanedMirrorDeleted))
+	})
+
+	It("should leave a mirror with a living counterpart alone and clear its orphaned mark", func() {
+		setOrphanedSince(time.Now().Add(-2 * time.Hour))
+		Expect(cl.Create(ctx, mapiMachine)).To(Succeed())
+
+		reconcileMirror()
+
+		Eventually(k.Object(capiMachine)).Should(SatisfyAll(
+			HaveField("DeletionTimestamp", BeNil()),
+			HaveField("Annotations", Not(HaveKey(orphanedSinceAnnotation))),
+		))
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("should leave paused CAPI machines that are not mirrors alone", func() {
+		Eventually(k.Update(capiMachine, func() {
+			delete(capiMachine.Annotations, consts.LastSyncTimeAnnotation)
+			capiMachine.Annotations[orphanedSinceAnnotation] = time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+		})).Should(Succeed())
+
+		reconcileMirror()
+
+		Consistently(k.Object(capiMachine)).Should(HaveField("DeletionTimestamp", BeNil()))
+		Expect(recorder.Events).To(BeEmpty())
+	})
+})
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operatorconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1alpha1 "github.com/openshift/cluster-capi-operator/pkg/apis/config/v1alpha1"
+)
+
+var errInvalidNamespacePairs = errors.New("invalid namespace pairs")
+
+// Get returns the spec of the ClusterAPIOperatorConfig singleton of the namespace. An empty spec, keeping the command
+// line configuration of the managers, is returned when the singleton or its CRD do not exist.
+func Get(ctx context.Context, cl client.Reader, namespace string) (configv1alpha1.ClusterAPIOperatorConfigSpec, error) {
+	config := &configv1alpha1.ClusterAPIOperatorConfig{}
+
+	err := cl.Get(ctx, client.ObjectKey{Namespace: namespace, Name: configv1alpha1.ClusterAPIOperatorConfigName}, config)
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return configv1alpha1.ClusterAPIOperatorConfigSpec{}, nil
+	} else if err != nil {
+		return configv1alpha1.ClusterAPIOperatorConfigSpec{}, fmt.Errorf("failed to get operator config: %w", err)
+	}
+
+	return config.Spec, nil
+}
+
+// SetInt overrides the command line value with the configured one, when it is set.
+func SetInt(value *int, configured *int32) {
+	if configured != nil {
+		*value = int(*configured)
+	}
+}
+
+// SetDuration overrides the command line value with the configured one, when it is set.
+func SetDuration(value *time.Duration, configured *metav1.Duration) {
+	if configured != nil {
+		*value = configured.Duration
+	}
+}
+
+// ImagesWithOverrides returns a copy of the provider images with the configured overrides applied.
+func ImagesWithOverrides(images map[string]string, spec configv1alpha1.ClusterAPIOperatorConfigSpec) map[string]string {
+	overridden := make(map[string]string, len(images))
+
+	for name, image := range images {
+		overridden[name] = image
+	}
+
+	for _, override := range spec.ProviderOverrides {
+		overridden[override.Name] = override.Image
+	}
+
+	return overridden
+}
+
+// NamespacePairs returns the namespace pairs synchronized by the sync controllers, the command line pair first,
+// followed by the configured ones. A namespace can only be part of a single pair.
+func NamespacePairs(mapiNamespace, capiNamespace string, spec configv1alpha1.ClusterAPIOperatorConfigSpec) ([]configv1alpha1.NamespacePair, error) {
+	pairs := append([]configv1alpha1.NamespacePair{{MAPINamespace: mapiNamespace, CAPINamespace: capiNamespace}}, spec.Sync.NamespacePairs...)
+
+	seen := make(map[string]struct{}, 2*len(pairs))
+
+	for _, pair := range pairs {
+		for _, namespace := range []string{pair.MAPINamespace, pair.CAPINamespace} {
+			if _, ok := seen[namespace]; ok {
+				return nil, fmt.Errorf("%w: namespace %q is part of more than one pair", errInvalidNamespacePairs, namespace)
+			}
+
+			seen[namespace] = struct{}{}
+		}
+	}
+
+	return pairs, nil
+}
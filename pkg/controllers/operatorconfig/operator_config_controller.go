@@ -0,0 +1,117 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operatorconfig
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	configv1alpha1 "github.com/openshift/cluster-capi-operator/pkg/apis/config/v1alpha1"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+)
+
+const (
+	controllerName = "OperatorConfigController"
+)
+
+// OperatorConfigReconciler applies the changes of the ClusterAPIOperatorConfig singleton to a running manager.
+// The log verbosity is applied as it changes. The other fields are only read when the manager starts, so the manager
+// is restarted when they change.
+type OperatorConfigReconciler struct {
+	client.Client
+
+	// Namespace is the namespace of the singleton.
+	Namespace string
+
+	// Initial is the spec the manager started with.
+	Initial configv1alpha1.ClusterAPIOperatorConfigSpec
+
+	// Verbosity is the log verbosity flag of the manager, DefaultVerbosity is its command line value, restored when
+	// the configured verbosity is unset.
+	Verbosity        flag.Value
+	DefaultVerbosity string
+
+	// Restart stops the manager, so that its container restarts it with the new configuration.
+	Restart func()
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *OperatorConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named(controllerName).
+		For(&configv1alpha1.ClusterAPIOperatorConfig{}, builder.WithPredicates(util.FilterNamespace(r.Namespace), singletonPredicate())).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create controller: %w", err)
+	}
+
+	r.Client = mgr.GetClient()
+
+	return nil
+}
+
+// Reconcile applies the operator configuration.
+func (r *OperatorConfigReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (ctrl.Result, error) {
+	logger := ctrl.LoggerFrom(ctx).WithName(controllerName)
+
+	spec, err := Get(ctx, r.Client, r.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	verbosity := r.DefaultVerbosity
+	if spec.LogVerbosity != nil {
+		verbosity = strconv.Itoa(int(*spec.LogVerbosity))
+	}
+
+	if r.Verbosity != nil && r.Verbosity.String() != verbosity {
+		if err := r.Verbosity.Set(verbosity); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to set log verbosity: %w", err)
+		}
+
+		logger.Info("Applied log verbosity", "verbosity", verbosity)
+	}
+
+	if requiresRestart(r.Initial, spec) {
+		logger.Info("Operator configuration changed, restarting to apply it")
+		r.Restart()
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// requiresRestart returns true when the fields only read when the manager starts differ.
+func requiresRestart(initial, current configv1alpha1.ClusterAPIOperatorConfigSpec) bool {
+	initial.LogVerbosity = nil
+	current.LogVerbosity = nil
+
+	return !equality.Semantic.DeepEqual(initial, current)
+}
+
+// singletonPredicate filters the ClusterAPIOperatorConfigs other than the singleton.
+func singletonPredicate() predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetName() == configv1alpha1.ClusterAPIOperatorConfigName
+	})
+}
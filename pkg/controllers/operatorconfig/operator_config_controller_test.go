@@ -0,0 +1,196 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operatorconfig
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1alpha1 "github.com/openshift/cluster-capi-operator/pkg/apis/config/v1alpha1"
+)
+
+const namespace = "openshift-cluster-api"
+
+func newOperatorConfig(spec configv1alpha1.ClusterAPIOperatorConfigSpec) *configv1alpha1.ClusterAPIOperatorConfig {
+	return &configv1alpha1.ClusterAPIOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: configv1alpha1.ClusterAPIOperatorConfigName},
+		Spec:       spec,
+	}
+}
+
+func newFakeClient(objs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	Expect(configv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+var _ = Describe("Get", func() {
+	ctx := context.Background()
+
+	It("should return an empty spec when the singleton does not exist", func() {
+		spec, err := Get(ctx, newFakeClient(), namespace)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(spec).To(Equal(configv1alpha1.ClusterAPIOperatorConfigSpec{}))
+	})
+
+	It("should return the spec of the singleton", func() {
+		config := newOperatorConfig(configv1alpha1.ClusterAPIOperatorConfigSpec{
+			Sync: configv1alpha1.SyncConfig{MachineConcurrency: ptr.To[int32](4)},
+		})
+
+		spec, err := Get(ctx, newFakeClient(config), namespace)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(spec).To(Equal(config.Spec))
+	})
+})
+
+var _ = Describe("Overrides", func() {
+	It("should only override the command line values that are configured", func() {
+		concurrency, gracePeriod := 1, time.Hour
+
+		SetInt(&concurrency, nil)
+		SetDuration(&gracePeriod, nil)
+		Expect(concurrency).To(Equal(1))
+		Expect(gracePeriod).To(Equal(time.Hour))
+
+		SetInt(&concurrency, ptr.To[int32](4))
+		SetDuration(&gracePeriod, &metav1.Duration{Duration: time.Minute})
+		Expect(concurrency).To(Equal(4))
+		Expect(gracePeriod).To(Equal(time.Minute))
+	})
+
+	It("should override the provider images without modifying the payload ones", func() {
+		images := map[string]string{"aws-cluster-api-controllers": "payload/aws", "cluster-capi-controllers": "payload/core"}
+
+		overridden := ImagesWithOverrides(images, configv1alpha1.ClusterAPIOperatorConfigSpec{
+			ProviderOverrides: []configv1alpha1.ProviderOverride{{Name: "aws-cluster-api-controllers", Image: "quay.io/dev/aws"}},
+		})
+
+		Expect(overridden).To(Equal(map[string]string{"aws-cluster-api-controllers": "quay.io/dev/aws", "cluster-capi-controllers": "payload/core"}))
+		Expect(images).To(HaveKeyWithValue("aws-cluster-api-controllers", "payload/aws"))
+	})
+
+	It("should enable the features by default", func() {
+		spec := configv1alpha1.ClusterAPIOperatorConfigSpec{
+			Features: []configv1alpha1.FeatureToggle{{Name: configv1alpha1.FeatureAdoption, Enabled: false}},
+		}
+
+		Expect(spec.FeatureEnabled(configv1alpha1.FeatureAdoption)).To(BeFalse())
+		Expect(spec.FeatureEnabled(configv1alpha1.FeatureMirrorCleanup)).To(BeTrue())
+	})
+
+	It("should add the configured namespace pairs after the command line one", func() {
+		spec := configv1alpha1.ClusterAPIOperatorConfigSpec{Sync: configv1alpha1.SyncConfig{
+			NamespacePairs: []configv1alpha1.NamespacePair{{MAPINamespace: "clusters-a-mapi", CAPINamespace: "clusters-a"}},
+		}}
+
+		pairs, err := NamespacePairs("openshift-machine-api", "openshift-cluster-api", spec)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pairs).To(Equal([]configv1alpha1.NamespacePair{
+			{MAPINamespace: "openshift-machine-api", CAPINamespace: "openshift-cluster-api"},
+			{MAPINamespace: "clusters-a-mapi", CAPINamespace: "clusters-a"},
+		}))
+	})
+
+	It("should reject a namespace part of more than one pair", func() {
+		spec := configv1alpha1.ClusterAPIOperatorConfigSpec{Sync: configv1alpha1.SyncConfig{
+			NamespacePairs: []configv1alpha1.NamespacePair{{MAPINamespace: "clusters-a", CAPINamespace: "openshift-cluster-api"}},
+		}}
+
+		_, err := NamespacePairs("openshift-machine-api", "openshift-cluster-api", spec)
+		Expect(err).To(MatchError(errInvalidNamespacePairs))
+	})
+})
+
+var _ = Describe("OperatorConfigReconciler", func() {
+	ctx := context.Background()
+
+	var verbosity *flag.FlagSet
+	var restarted bool
+
+	newReconciler := func(initial configv1alpha1.ClusterAPIOperatorConfigSpec, objs ...client.Object) *OperatorConfigReconciler {
+		verbosity = flag.NewFlagSet("test", flag.ContinueOnError)
+		verbosity.Int("v", 2, "")
+		restarted = false
+
+		return &OperatorConfigReconciler{
+			Client:           newFakeClient(objs...),
+			Namespace:        namespace,
+			Initial:          initial,
+			Verbosity:        verbosity.Lookup("v").Value,
+			DefaultVerbosity: "2",
+			Restart:          func() { restarted = true },
+		}
+	}
+
+	reconcileConfig := func(r *OperatorConfigReconciler) {
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKey{Namespace: namespace, Name: configv1alpha1.ClusterAPIOperatorConfigName}})
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	It("should apply the log verbosity without restarting", func() {
+		r := newReconciler(configv1alpha1.ClusterAPIOperatorConfigSpec{},
+			newOperatorConfig(configv1alpha1.ClusterAPIOperatorConfigSpec{LogVerbosity: ptr.To[int32](5)}))
+
+		reconcileConfig(r)
+
+		Expect(verbosity.Lookup("v").Value.String()).To(Equal("5"))
+		Expect(restarted).To(BeFalse())
+	})
+
+	It("should restore the command line verbosity when the configured one is unset", func() {
+		r := newReconciler(configv1alpha1.ClusterAPIOperatorConfigSpec{LogVerbosity: ptr.To[int32](5)})
+		Expect(r.Verbosity.Set("5")).To(Succeed())
+
+		reconcileConfig(r)
+
+		Expect(verbosity.Lookup("v").Value.String()).To(Equal("2"))
+		Expect(restarted).To(BeFalse())
+	})
+
+	It("should restart when a field only read on start changes", func() {
+		initial := configv1alpha1.ClusterAPIOperatorConfigSpec{Sync: configv1alpha1.SyncConfig{MachineConcurrency: ptr.To[int32](2)}}
+		r := newReconciler(initial, newOperatorConfig(configv1alpha1.ClusterAPIOperatorConfigSpec{
+			Sync: configv1alpha1.SyncConfig{MachineConcurrency: ptr.To[int32](4)},
+		}))
+
+		reconcileConfig(r)
+
+		Expect(restarted).To(BeTrue())
+	})
+
+	It("should not restart when the configuration is unchanged", func() {
+		initial := configv1alpha1.ClusterAPIOperatorConfigSpec{Sync: configv1alpha1.SyncConfig{MachineConcurrency: ptr.To[int32](2)}}
+		r := newReconciler(initial, newOperatorConfig(initial))
+
+		reconcileConfig(r)
+
+		Expect(restarted).To(BeFalse())
+	})
+})
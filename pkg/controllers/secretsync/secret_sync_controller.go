@@ -16,6 +16,7 @@ limitations under the License.
 package secretsync
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -33,13 +34,33 @@ import (
 
 	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/bootstrapsecret"
 	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
 )
 
 const (
 	managedUserDataSecretName = "worker-user-data"
 
-	// SecretSourceNamespace is the source namespace to copy the user data secret from.
+	// masterUserDataSecretName is the user data secret of the control plane machines, which Cluster API does not
+	// manage. It is never mirrored, even though it has the userDataSecretSuffix.
+	masterUserDataSecretName = "master-user-data"
+
+	// userDataSecretSuffix is the suffix of the user data secrets of the machine pools, e.g. worker-arm64-user-data
+	// on heterogeneous clusters. The secrets with this suffix are mirrored without being labeled.
+	userDataSecretSuffix = "-user-data"
+
+	// UserDataSecretSyncLabel is the label used to opt a user data secret in the source namespace into being
+	// mirrored into the managed namespace, in addition to the user data secrets of the machine pools.
+	// The mirrored copies of the secrets other than the worker user data secret carry the label, so that they are
+	// removed along with their source.
+	UserDataSecretSyncLabel = "cluster-api.openshift.io/sync-user-data"
+
+	// UserDataChecksumAnnotation records the SHA-256 checksum of the user data of a mirrored user data secret.
+	// When rollouts on user data changes are enabled, it is also set on the machine templates of the CAPI
+	// MachineDeployments and MachineSets bootstrapped from the secret, so that their machines are replaced.
+	UserDataChecksumAnnotation = "cluster-api.openshift.io/user-data-checksum"
+
+	// SecretSourceNamespace is the default source namespace to copy the user data secret from.
 	SecretSourceNamespace = "openshift-machine-api"
 
 	// Controller conditions for the Cluster Operator resource.
@@ -55,69 +76,104 @@ var (
 	errSourceSecretMissingUserData = errors.New("source secret does not have user data")
 )
 
-// UserDataSecretController reconciles a Secret object containing machine user data, from the Machine API to Cluster API namespaces.
+// UserDataSecretController reconciles Secret objects containing machine user data, from the Machine API to Cluster API namespaces.
+// The user data secrets of the machine pools, named *-user-data, are always mirrored, any other secret but the
+// master user data secret is mirrored when it carries the UserDataSecretSyncLabel.
 type UserDataSecretController struct {
 	operatorstatus.ClusterOperatorStatusClient
 	Scheme *runtime.Scheme
+
+	// SourceNamespace is the Machine API namespace the user data secrets are copied from, defaults to
+	// SecretSourceNamespace.
+	SourceNamespace string
+
+	// RolloutOnUserDataChange bumps the user data checksum annotation of the machine templates of the CAPI
+	// MachineDeployments and MachineSets bootstrapped from a mirrored secret when its user data changes.
+	RolloutOnUserDataChange bool
 }
 
 // Reconcile reconciles the user data secret.
 func (r *UserDataSecretController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	log := ctrl.LoggerFrom(ctx).WithName(controllerName)
-	log.Info("reconciling worker user data secret")
+	log := ctrl.LoggerFrom(ctx).WithName(controllerName).WithValues("secret", req.Name)
+	log.Info("reconciling user data secret")
 
-	defaultSourceSecretObjectKey := client.ObjectKey{
-		Name: managedUserDataSecretName, Namespace: SecretSourceNamespace,
+	sourceSecretObjectKey := client.ObjectKey{
+		Name: req.Name, Namespace: r.sourceNamespace(),
 	}
 	sourceSecret := &corev1.Secret{}
 
-	if err := r.Get(ctx, defaultSourceSecretObjectKey, sourceSecret); err != nil {
+	if err := r.Get(ctx, sourceSecretObjectKey, sourceSecret); err != nil {
+		if req.Name != managedUserDataSecretName && apierrors.IsNotFound(err) {
+			log.Info("source secret not found, removing mirrored user data secret")
+
+			return ctrl.Result{}, r.deleteTargetSecret(ctx, req.Name)
+		}
+
 		log.Error(err, "unable to get source secret for sync")
 
-		if err := r.setDegradedCondition(ctx, log); err != nil {
+		if err := r.setDegradedCondition(ctx, log, err); err != nil {
 			return ctrl.Result{}, fmt.Errorf("failed to set conditions for secret sync controller: %w", err)
 		}
 
 		return ctrl.Result{}, fmt.Errorf("failed to get source secret: %w", err)
 	}
 
+	if !isUserDataSecretToSync(sourceSecret) {
+		log.Info("source secret is no longer labeled for sync, removing mirrored user data secret")
+
+		return ctrl.Result{}, r.deleteTargetSecret(ctx, req.Name)
+	}
+
 	targetSecret := &corev1.Secret{}
 	targetSecretKey := client.ObjectKey{
 		Namespace: r.ManagedNamespace,
-		Name:      managedUserDataSecretName,
+		Name:      req.Name,
 	}
 
 	// If the secret does not exist, it will be created later, so we can ignore a Not Found error
 	if err := r.Get(ctx, targetSecretKey, targetSecret); err != nil && !apierrors.IsNotFound(err) {
 		log.Error(err, "unable to get target secret for sync")
 
-		if err := r.setDegradedCondition(ctx, log); err != nil {
+		if err := r.setDegradedCondition(ctx, log, err); err != nil {
 			return ctrl.Result{}, fmt.Errorf("failed to set conditions for secret controller: %w", err)
 		}
 
 		return ctrl.Result{}, fmt.Errorf("failed to get target secret: %w", err)
 	}
 
-	if r.areSecretsEqual(sourceSecret, targetSecret) {
-		log.Info("user data in source and target secrets is the same, no sync needed")
-
-		if err := r.setAvailableCondition(ctx, log); err != nil {
-			return ctrl.Result{}, fmt.Errorf("failed to set conditions for user data secret controller: %w", err)
-		}
+	if _, ok := targetSecret.GetLabels()[bootstrapsecret.BootstrapSecretForLabel]; ok {
+		log.Info("target secret is a generated bootstrap data secret, not overwriting it")
 
 		return ctrl.Result{}, nil
 	}
 
-	if err := r.syncSecretData(ctx, sourceSecret, targetSecret); err != nil {
+	// The user data only rotates when a mirrored copy already existed, and not when it is first created.
+	rotated := targetSecret.GetResourceVersion() != "" && !bytes.Equal(targetSecret.Data[capiUserDataKey], sourceSecret.Data[mapiUserDataKey])
+
+	if r.areSecretsEqual(sourceSecret, targetSecret) && hasUserDataChecksum(targetSecret) {
+		log.Info("user data in source and target secrets is the same, no sync needed")
+	} else if err := r.syncSecretData(ctx, sourceSecret, targetSecret); err != nil {
 		log.Error(err, "unable to sync user data secret")
 
-		if err := r.setDegradedCondition(ctx, log); err != nil {
+		if err := r.setDegradedCondition(ctx, log, err); err != nil {
 			return ctrl.Result{}, fmt.Errorf("failed to set conditions for user data secret controller: %w", err)
 		}
 
 		return ctrl.Result{}, err
 	}
 
+	if r.RolloutOnUserDataChange {
+		if err := r.rolloutUserDataChange(ctx, req.Name, userDataChecksum(sourceSecret.Data[mapiUserDataKey]), rotated); err != nil {
+			log.Error(err, "unable to roll out user data change")
+
+			if err := r.setDegradedCondition(ctx, log, err); err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to set conditions for user data secret controller: %w", err)
+			}
+
+			return ctrl.Result{}, err
+		}
+	}
+
 	if err := r.setAvailableCondition(ctx, log); err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to set conditions for user data secret controller: %w", err)
 	}
@@ -125,6 +181,34 @@ func (r *UserDataSecretController) Reconcile(ctx context.Context, req ctrl.Reque
 	return ctrl.Result{}, nil
 }
 
+// deleteTargetSecret removes a mirrored user data secret from the managed namespace.
+// The default worker user data secret and secrets not carrying the sync label, such as the generated bootstrap data
+// secrets, are never removed.
+func (r *UserDataSecretController) deleteTargetSecret(ctx context.Context, name string) error {
+	if name == managedUserDataSecretName {
+		return nil
+	}
+
+	targetSecret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: r.ManagedNamespace, Name: name}, targetSecret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to get target secret: %w", err)
+	}
+
+	if _, ok := targetSecret.GetLabels()[UserDataSecretSyncLabel]; !ok {
+		return nil
+	}
+
+	if err := r.Delete(ctx, targetSecret); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete target secret: %w", err)
+	}
+
+	return nil
+}
+
 func (r *UserDataSecretController) areSecretsEqual(source *corev1.Secret, target *corev1.Secret) bool {
 	return source.Immutable == target.Immutable &&
 		reflect.DeepEqual(source.Data[mapiUserDataKey], target.Data[capiUserDataKey]) && reflect.DeepEqual(source.StringData, target.StringData) &&
@@ -137,8 +221,30 @@ func (r *UserDataSecretController) syncSecretData(ctx context.Context, source *c
 		return errSourceSecretMissingUserData
 	}
 
-	target.SetName(managedUserDataSecretName)
+	target.SetName(source.GetName())
 	target.SetNamespace(r.ManagedNamespace)
+
+	labels := target.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	// The sync label marks the mirrored copy for removal along with its source.
+	if source.GetName() != managedUserDataSecretName {
+		labels[UserDataSecretSyncLabel] = source.GetLabels()[UserDataSecretSyncLabel]
+	}
+
+	labels[controllers.ManagedByLabel] = controllers.ManagedByLabelValue
+	target.SetLabels(labels)
+
+	annotations := target.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	annotations[UserDataChecksumAnnotation] = userDataChecksum(userData)
+	target.SetAnnotations(annotations)
+
 	target.Data = map[string][]byte{
 		"value":  userData,
 		"format": []byte("ignition"),
@@ -173,11 +279,13 @@ func (r *UserDataSecretController) SetupWithManager(mgr ctrl.Manager) error {
 		For(
 			&corev1.Secret{},
 			builder.WithPredicates(userDataSecretPredicate(r.ManagedNamespace)),
+			builder.OnlyMetadata,
 		).
 		Watches(
 			&corev1.Secret{},
-			handler.EnqueueRequestsFromMapFunc(toUserDataSecret),
-			builder.WithPredicates(userDataSecretPredicate(SecretSourceNamespace)),
+			handler.EnqueueRequestsFromMapFunc(toUserDataSecret(r.sourceNamespace())),
+			builder.WithPredicates(userDataSecretPredicate(r.sourceNamespace())),
+			builder.OnlyMetadata,
 		).
 		Complete(r); err != nil {
 		return fmt.Errorf("failed to create controller: %w", err)
@@ -186,6 +294,15 @@ func (r *UserDataSecretController) SetupWithManager(mgr ctrl.Manager) error {
 	return nil
 }
 
+// sourceNamespace returns the namespace the user data secrets are copied from.
+func (r *UserDataSecretController) sourceNamespace() string {
+	if r.SourceNamespace == "" {
+		return SecretSourceNamespace
+	}
+
+	return r.SourceNamespace
+}
+
 func (r *UserDataSecretController) setAvailableCondition(ctx context.Context, log logr.Logger) error {
 	co, err := r.GetOrCreateClusterOperator(ctx)
 	if err != nil {
@@ -199,7 +316,7 @@ func (r *UserDataSecretController) setAvailableCondition(ctx context.Context, lo
 			"User Data Secret Controller works as expected"),
 	}
 
-	co.Status.Versions = []configv1.OperandVersion{{Name: controllers.OperatorVersionKey, Version: r.ReleaseVersion}}
+	r.SetOperatorVersion(co)
 
 	log.Info("user Data Secret Controller is available")
 
@@ -210,7 +327,7 @@ func (r *UserDataSecretController) setAvailableCondition(ctx context.Context, lo
 	return nil
 }
 
-func (r *UserDataSecretController) setDegradedCondition(ctx context.Context, log logr.Logger) error {
+func (r *UserDataSecretController) setDegradedCondition(ctx context.Context, log logr.Logger, reconcileErr error) error {
 	co, err := r.GetOrCreateClusterOperator(ctx)
 	if err != nil {
 		return fmt.Errorf("unable to get cluster operator: %w", err)
@@ -218,12 +335,12 @@ func (r *UserDataSecretController) setDegradedCondition(ctx context.Context, log
 
 	conds := []configv1.ClusterOperatorStatusCondition{
 		operatorstatus.NewClusterOperatorStatusCondition(secretSyncControllerAvailableCondition, configv1.ConditionFalse, operatorstatus.ReasonSyncFailed,
-			"User Data Secret Controller failed to sync secret"),
+			fmt.Sprintf("User Data Secret Controller failed to sync secret: %v", reconcileErr)),
 		operatorstatus.NewClusterOperatorStatusCondition(secretSyncControllerDegradedCondition, configv1.ConditionTrue, operatorstatus.ReasonSyncFailed,
-			"User Data Secret Controller failed to sync secret"),
+			fmt.Sprintf("User Data Secret Controller failed to sync secret: %v", reconcileErr)),
 	}
 
-	co.Status.Versions = []configv1.OperandVersion{{Name: controllers.OperatorVersionKey, Version: r.ReleaseVersion}}
+	r.SetOperatorVersion(co)
 
 	log.Info("user Data Secret Controller is degraded")
 
@@ -24,6 +24,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
@@ -34,6 +35,7 @@ import (
 
 	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/bootstrapsecret"
 	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
 	"github.com/openshift/cluster-capi-operator/pkg/test"
 )
@@ -80,6 +82,19 @@ var _ = Describe("areSecretsEqual reconciler method", func() {
 	})
 })
 
+var _ = DescribeTable("isUserDataSecretToSync",
+	func(name string, labels map[string]string, expected bool) {
+		secret := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+		Expect(isUserDataSecretToSync(secret)).To(Equal(expected))
+	},
+	Entry("worker user data secret", managedUserDataSecretName, nil, true),
+	Entry("user data secret of another machine pool", "worker-arm64-user-data", nil, true),
+	Entry("labeled secret", "custom-ignition", map[string]string{UserDataSecretSyncLabel: ""}, true),
+	Entry("unlabeled secret", "custom-ignition", nil, false),
+	Entry("master user data secret", masterUserDataSecretName, nil, false),
+	Entry("labeled master user data secret", masterUserDataSecretName, map[string]string{UserDataSecretSyncLabel: ""}, false),
+)
+
 var _ = Describe("User Data Secret controller", func() {
 	var rec *record.FakeRecorder
 
@@ -176,6 +191,17 @@ var _ = Describe("User Data Secret controller", func() {
 		}, timeout).Should(BeTrue())
 	})
 
+	It("synced secret should carry the checksum of its user data", func() {
+		Eventually(func() (map[string]string, error) {
+			syncedUserDataSecret := &corev1.Secret{}
+			if err := cl.Get(ctx, syncedSecretKey, syncedUserDataSecret); err != nil {
+				return nil, err
+			}
+
+			return syncedUserDataSecret.GetAnnotations(), nil
+		}, timeout).Should(HaveKeyWithValue(UserDataChecksumAnnotation, userDataChecksum([]byte(defaultSecretValue))))
+	})
+
 	It("secret should be synced up if managed user data secret changed", func() {
 		changedSourceSecret := sourceSecret.DeepCopy()
 		changedSourceSecret.Data = map[string][]byte{mapiUserDataKey: []byte("managed one changed")}
@@ -234,4 +260,103 @@ var _ = Describe("User Data Secret controller", func() {
 		Expect(cl.Get(ctx, syncedSecretKey, syncedUserDataSecret)).Should(Succeed())
 		Expect(initialSecretresourceVersion).Should(BeEquivalentTo(syncedUserDataSecret.ResourceVersion))
 	})
+
+	It("additional labeled user data secret should be synced up", func() {
+		additionalSecret := makeUserDataSecret()
+		additionalSecret.SetName("custom-pool-ignition")
+		additionalSecret.SetLabels(map[string]string{UserDataSecretSyncLabel: ""})
+		additionalSecret.Data = map[string][]byte{mapiUserDataKey: []byte("custom")}
+		Expect(cl.Create(ctx, additionalSecret)).To(Succeed())
+
+		additionalSecretKey := client.ObjectKey{Namespace: controllers.DefaultManagedNamespace, Name: additionalSecret.GetName()}
+
+		Eventually(func() (bool, error) {
+			syncedUserDataSecret := &corev1.Secret{}
+			if err := cl.Get(ctx, additionalSecretKey, syncedUserDataSecret); err != nil {
+				return false, err
+			}
+
+			Expect(syncedUserDataSecret.GetLabels()).To(HaveKey(UserDataSecretSyncLabel))
+
+			return bytes.Equal(syncedUserDataSecret.Data[capiUserDataKey], []byte("custom")), nil
+		}, timeout).Should(BeTrue())
+
+		By("Removing the sync label from the source secret")
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(additionalSecret), additionalSecret)).To(Succeed())
+		additionalSecret.SetLabels(nil)
+		Expect(cl.Update(ctx, additionalSecret)).To(Succeed())
+
+		Eventually(func() bool {
+			return apierrors.IsNotFound(cl.Get(ctx, additionalSecretKey, &corev1.Secret{}))
+		}, timeout).Should(BeTrue())
+	})
+
+	It("user data secret of another machine pool should be synced up without being labeled", func() {
+		armSecret := makeUserDataSecret()
+		armSecret.SetName("worker-arm64-user-data")
+		armSecret.Data = map[string][]byte{mapiUserDataKey: []byte("arm64")}
+		Expect(cl.Create(ctx, armSecret)).To(Succeed())
+
+		armSecretKey := client.ObjectKey{Namespace: controllers.DefaultManagedNamespace, Name: armSecret.GetName()}
+
+		Eventually(func() (bool, error) {
+			syncedUserDataSecret := &corev1.Secret{}
+			if err := cl.Get(ctx, armSecretKey, syncedUserDataSecret); err != nil {
+				return false, err
+			}
+
+			Expect(syncedUserDataSecret.GetLabels()).To(HaveKey(UserDataSecretSyncLabel))
+
+			return bytes.Equal(syncedUserDataSecret.Data[capiUserDataKey], []byte("arm64")), nil
+		}, timeout).Should(BeTrue())
+
+		By("Deleting the source secret")
+		Expect(test.CleanupAndWait(ctx, cl, armSecret)).To(Succeed())
+
+		Eventually(func() bool {
+			return apierrors.IsNotFound(cl.Get(ctx, armSecretKey, &corev1.Secret{}))
+		}, timeout).Should(BeTrue())
+	})
+
+	It("generated bootstrap data secret should not be overwritten", func() {
+		generatedSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:      "infra-user-data",
+			Namespace: controllers.DefaultManagedNamespace,
+			Labels:    map[string]string{bootstrapsecret.BootstrapSecretForLabel: "infra"},
+		}, Data: map[string][]byte{capiUserDataKey: []byte("generated")}}
+		Expect(cl.Create(ctx, generatedSecret)).To(Succeed())
+
+		infraSecret := makeUserDataSecret()
+		infraSecret.SetName("infra-user-data")
+		Expect(cl.Create(ctx, infraSecret)).To(Succeed())
+
+		Consistently(func() ([]byte, error) {
+			secret := &corev1.Secret{}
+			if err := cl.Get(ctx, client.ObjectKeyFromObject(generatedSecret), secret); err != nil {
+				return nil, err
+			}
+
+			return secret.Data[capiUserDataKey], nil
+		}, time.Second*2).Should(Equal([]byte("generated")))
+	})
+
+	It("master user data secret should not be synced", func() {
+		masterSecret := makeUserDataSecret()
+		masterSecret.SetName(masterUserDataSecretName)
+		Expect(cl.Create(ctx, masterSecret)).To(Succeed())
+
+		Consistently(func() bool {
+			return apierrors.IsNotFound(cl.Get(ctx, client.ObjectKey{Namespace: controllers.DefaultManagedNamespace, Name: masterSecret.GetName()}, &corev1.Secret{}))
+		}, time.Second*2).Should(BeTrue())
+	})
+
+	It("unlabeled secret in the source namespace should not be synced", func() {
+		unlabeledSecret := makeUserDataSecret()
+		unlabeledSecret.SetName("unrelated-secret")
+		Expect(cl.Create(ctx, unlabeledSecret)).To(Succeed())
+
+		Consistently(func() bool {
+			return apierrors.IsNotFound(cl.Get(ctx, client.ObjectKey{Namespace: controllers.DefaultManagedNamespace, Name: unlabeledSecret.GetName()}, &corev1.Secret{}))
+		}, time.Second*2).Should(BeTrue())
+	})
 })
@@ -0,0 +1,136 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package secretsync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// userDataChecksum returns the hex encoded SHA-256 checksum of the user data.
+func userDataChecksum(userData []byte) string {
+	sum := sha256.Sum256(userData)
+	return hex.EncodeToString(sum[:])
+}
+
+// hasUserDataChecksum returns whether the mirrored user data secret carries the checksum of its user data.
+func hasUserDataChecksum(secret *corev1.Secret) bool {
+	return secret.GetAnnotations()[UserDataChecksumAnnotation] == userDataChecksum(secret.Data[capiUserDataKey])
+}
+
+// rolloutUserDataChange sets the user data checksum annotation on the machine templates of the CAPI MachineDeployments
+// and MachineSets of the managed namespace bootstrapped from the user data secret with the given name, so that their
+// machines are replaced with ones bootstrapped from the new user data. MachineDeployments roll their machines out,
+// MachineSets only create the new machines from the new template.
+// The templates carrying a stale checksum are always updated, so that an interrupted rollout is completed. The templates
+// without a checksum are only updated when the user data rotated, as setting it does not replace the machines otherwise.
+// The MachineSets owned by a MachineDeployment are rolled out by their MachineDeployment, and the paused MachineSets
+// mirroring Machine API MachineSets are left to the synchronization of their Machine API counterparts.
+func (r *UserDataSecretController) rolloutUserDataChange(ctx context.Context, name, checksum string, rotated bool) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	machineDeployments := &clusterv1.MachineDeploymentList{}
+	if err := r.List(ctx, machineDeployments, client.InNamespace(r.ManagedNamespace)); err != nil {
+		return fmt.Errorf("failed to list machine deployments: %w", err)
+	}
+
+	for i := range machineDeployments.Items {
+		md := &machineDeployments.Items[i]
+		if !needsUserDataRollout(md, &md.Spec.Template, name, checksum, rotated) {
+			continue
+		}
+
+		log.Info("rolling out user data change", "machineDeployment", md.GetName())
+
+		if err := patchTemplateChecksum(ctx, r.Client, md, &md.Spec.Template, checksum); err != nil {
+			return fmt.Errorf("failed to roll out machine deployment %q: %w", md.GetName(), err)
+		}
+	}
+
+	machineSets := &clusterv1.MachineSetList{}
+	if err := r.List(ctx, machineSets, client.InNamespace(r.ManagedNamespace)); err != nil {
+		return fmt.Errorf("failed to list machine sets: %w", err)
+	}
+
+	for i := range machineSets.Items {
+		ms := &machineSets.Items[i]
+		if isOwnedByMachineDeployment(ms) || !needsUserDataRollout(ms, &ms.Spec.Template, name, checksum, rotated) {
+			continue
+		}
+
+		log.Info("rolling out user data change", "machineSet", ms.GetName())
+
+		if err := patchTemplateChecksum(ctx, r.Client, ms, &ms.Spec.Template, checksum); err != nil {
+			return fmt.Errorf("failed to roll out machine set %q: %w", ms.GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+// needsUserDataRollout returns whether the machine template of the object is bootstrapped from the user data secret
+// with the given name and does not carry its checksum yet.
+func needsUserDataRollout(obj client.Object, template *clusterv1.MachineTemplateSpec, name, checksum string, rotated bool) bool {
+	if _, paused := obj.GetAnnotations()[clusterv1.PausedAnnotation]; paused {
+		return false
+	}
+
+	if ptr.Deref(template.Spec.Bootstrap.DataSecretName, "") != name {
+		return false
+	}
+
+	current, ok := template.ObjectMeta.Annotations[UserDataChecksumAnnotation]
+
+	return current != checksum && (ok || rotated)
+}
+
+// patchTemplateChecksum sets the user data checksum annotation on the machine template of the object.
+func patchTemplateChecksum(ctx context.Context, cl client.Client, obj client.Object, template *clusterv1.MachineTemplateSpec, checksum string) error {
+	patchBase := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+
+	if template.ObjectMeta.Annotations == nil {
+		template.ObjectMeta.Annotations = map[string]string{}
+	}
+
+	template.ObjectMeta.Annotations[UserDataChecksumAnnotation] = checksum
+
+	if err := cl.Patch(ctx, obj, patchBase); err != nil {
+		return fmt.Errorf("failed to patch machine template annotations: %w", err)
+	}
+
+	return nil
+}
+
+// isOwnedByMachineDeployment returns whether the MachineSet is owned by a MachineDeployment.
+func isOwnedByMachineDeployment(ms *clusterv1.MachineSet) bool {
+	for _, ref := range ms.GetOwnerReferences() {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err == nil && gv.Group == clusterv1.GroupVersion.Group && ref.Kind == "MachineDeployment" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package secretsync
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
+)
+
+var _ = Describe("User data rollout", func() {
+	const staleChecksum = "stale"
+
+	ctx := context.Background()
+	checksum := userDataChecksum([]byte(defaultSecretValue))
+
+	var reconciler *UserDataSecretController
+
+	template := func(dataSecretName string, annotations map[string]string) clusterv1.MachineTemplateSpec {
+		return clusterv1.MachineTemplateSpec{
+			ObjectMeta: clusterv1.ObjectMeta{Annotations: annotations},
+			Spec:       clusterv1.MachineSpec{Bootstrap: clusterv1.Bootstrap{DataSecretName: ptr.To(dataSecretName)}},
+		}
+	}
+
+	machineDeployment := func(name, dataSecretName string, annotations map[string]string) *clusterv1.MachineDeployment {
+		return &clusterv1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: controllers.DefaultManagedNamespace},
+			Spec:       clusterv1.MachineDeploymentSpec{Template: template(dataSecretName, annotations)},
+		}
+	}
+
+	machineSet := func(name, dataSecretName string, annotations map[string]string) *clusterv1.MachineSet {
+		return &clusterv1.MachineSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: controllers.DefaultManagedNamespace},
+			Spec:       clusterv1.MachineSetSpec{Template: template(dataSecretName, annotations)},
+		}
+	}
+
+	withObjects := func(objs ...client.Object) {
+		scheme := runtime.NewScheme()
+		Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+		reconciler = &UserDataSecretController{
+			ClusterOperatorStatusClient: operatorstatus.ClusterOperatorStatusClient{
+				Client:           fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+				ManagedNamespace: controllers.DefaultManagedNamespace,
+			},
+			RolloutOnUserDataChange: true,
+		}
+	}
+
+	templateChecksum := func(obj client.Object) string {
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(obj), obj)).To(Succeed())
+
+		switch o := obj.(type) {
+		case *clusterv1.MachineDeployment:
+			return o.Spec.Template.ObjectMeta.Annotations[UserDataChecksumAnnotation]
+		case *clusterv1.MachineSet:
+			return o.Spec.Template.ObjectMeta.Annotations[UserDataChecksumAnnotation]
+		default:
+			return ""
+		}
+	}
+
+	It("should bump the checksum of the templates bootstrapped from the rotated secret", func() {
+		md := machineDeployment("worker", managedUserDataSecretName, nil)
+		ms := machineSet("standalone", managedUserDataSecretName, map[string]string{UserDataChecksumAnnotation: staleChecksum})
+		other := machineSet("other", "worker-arm64-user-data", nil)
+		withObjects(md, ms, other)
+
+		Expect(reconciler.rolloutUserDataChange(ctx, managedUserDataSecretName, checksum, true)).To(Succeed())
+
+		Expect(templateChecksum(md)).To(Equal(checksum))
+		Expect(templateChecksum(ms)).To(Equal(checksum))
+		Expect(templateChecksum(other)).To(BeEmpty())
+	})
+
+	It("should only complete the rollout of the templates with a stale checksum when the secret did not rotate", func() {
+		md := machineDeployment("worker", managedUserDataSecretName, nil)
+		ms := machineSet("standalone", managedUserDataSecretName, map[string]string{UserDataChecksumAnnotation: staleChecksum})
+		withObjects(md, ms)
+
+		Expect(reconciler.rolloutUserDataChange(ctx, managedUserDataSecretName, checksum, false)).To(Succeed())
+
+		Expect(templateChecksum(md)).To(BeEmpty())
+		Expect(templateChecksum(ms)).To(Equal(checksum))
+	})
+
+	It("should not bump the machine sets owned by a machine deployment or mirroring Machine API machine sets", func() {
+		owned := machineSet("owned", managedUserDataSecretName, nil)
+		owned.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: clusterv1.GroupVersion.String(), Kind: "MachineDeployment", Name: "worker", UID: "uid",
+		}}
+		mirror := machineSet("mirror", managedUserDataSecretName, nil)
+		mirror.Annotations = map[string]string{clusterv1.PausedAnnotation: ""}
+		withObjects(owned, mirror)
+
+		Expect(reconciler.rolloutUserDataChange(ctx, managedUserDataSecretName, checksum, true)).To(Succeed())
+
+		Expect(templateChecksum(owned)).To(BeEmpty())
+		Expect(templateChecksum(mirror)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("hasUserDataChecksum", func() {
+	It("should only accept the checksum of the user data of the secret", func() {
+		secret := &corev1.Secret{Data: map[string][]byte{capiUserDataKey: []byte(defaultSecretValue)}}
+		Expect(hasUserDataChecksum(secret)).To(BeFalse())
+
+		secret.SetAnnotations(map[string]string{UserDataChecksumAnnotation: userDataChecksum([]byte("other"))})
+		Expect(hasUserDataChecksum(secret)).To(BeFalse())
+
+		secret.SetAnnotations(map[string]string{UserDataChecksumAnnotation: userDataChecksum([]byte(defaultSecretValue))})
+		Expect(hasUserDataChecksum(secret)).To(BeTrue())
+	})
+})
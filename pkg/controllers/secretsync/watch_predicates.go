@@ -17,30 +17,52 @@ package secretsync
 
 import (
 	"context"
+	"strings"
 
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
-func toUserDataSecret(ctx context.Context, obj client.Object) []reconcile.Request {
-	return []reconcile.Request{{
-		NamespacedName: client.ObjectKey{Name: managedUserDataSecretName, Namespace: SecretSourceNamespace},
-	}}
+func toUserDataSecret(sourceNamespace string) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		return []reconcile.Request{{
+			NamespacedName: client.ObjectKey{Name: obj.GetName(), Namespace: sourceNamespace},
+		}}
+	}
+}
+
+// isUserDataSecretToSync returns true when the secret is the user data secret of a machine pool, such as the default
+// worker user data secret, or has been labeled for mirroring into the managed namespace. The master user data secret
+// is never mirrored.
+// Only the secret metadata is used, as secrets are watched through metadata only informers.
+func isUserDataSecretToSync(secret client.Object) bool {
+	if secret.GetName() == masterUserDataSecretName {
+		return false
+	}
+
+	if strings.HasSuffix(secret.GetName(), userDataSecretSuffix) {
+		return true
+	}
+
+	_, ok := secret.GetLabels()[UserDataSecretSyncLabel]
+
+	return ok
 }
 
 func userDataSecretPredicate(targetNamespace string) predicate.Funcs {
-	isOwnedUserDataSecret := func(obj runtime.Object) bool {
-		secret, ok := obj.(*corev1.Secret)
-		return ok && secret.GetNamespace() == targetNamespace && secret.GetName() == managedUserDataSecretName
+	isOwnedUserDataSecret := func(obj client.Object) bool {
+		return obj.GetNamespace() == targetNamespace && isUserDataSecretToSync(obj)
 	}
 
 	return predicate.Funcs{
-		CreateFunc:  func(e event.CreateEvent) bool { return isOwnedUserDataSecret(e.Object) },
-		UpdateFunc:  func(e event.UpdateEvent) bool { return isOwnedUserDataSecret(e.ObjectNew) },
+		CreateFunc: func(e event.CreateEvent) bool { return isOwnedUserDataSecret(e.Object) },
+		// The old object is checked too so that removing the sync label triggers cleanup of the mirrored secret.
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return isOwnedUserDataSecret(e.ObjectOld) || isOwnedUserDataSecret(e.ObjectNew)
+		},
 		DeleteFunc:  func(e event.DeleteEvent) bool { return isOwnedUserDataSecret(e.Object) },
 		GenericFunc: func(e event.GenericEvent) bool { return isOwnedUserDataSecret(e.Object) },
 	}
@@ -0,0 +1,143 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package synccommon holds the building blocks shared by the controllers mirroring objects between the Machine API
+// and Cluster API namespaces: recording the last synchronization and the conversion losses on the mirrors, comparing
+// and applying the mirrors, reporting the paused and degraded synchronizations, and Mirror, a reconciler for the
+// mirrored kinds which only need converting.
+package synccommon
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	conversionutil "github.com/openshift/cluster-capi-operator/pkg/conversion/util"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SetLastSyncAnnotations records the time of the synchronization and a hash of the spec being written
+// on the non-authoritative object, so the last sync decision can be traced from the object itself.
+func SetLastSyncAnnotations(obj client.Object) error {
+	hash, err := SpecHash(obj)
+	if err != nil {
+		return err
+	}
+
+	// Copy the annotations as the converted objects may share their annotations map with the source object.
+	annotations := util.MergeMaps(obj.GetAnnotations(), map[string]string{
+		consts.LastSyncTimeAnnotation: time.Now().UTC().Format(time.RFC3339),
+		consts.LastSyncHashAnnotation: hash,
+	})
+	obj.SetAnnotations(annotations)
+
+	return nil
+}
+
+// SpecHash returns the hex encoded sha256 hash of the JSON representation of the object spec.
+func SpecHash(obj client.Object) (string, error) {
+	unstructuredObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert %T to unstructured: %w", obj, err)
+	}
+
+	specJSON, err := json.Marshal(unstructuredObj["spec"])
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal %T spec: %w", obj, err)
+	}
+
+	hash := sha256.Sum256(specJSON)
+
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// SetConversionLossAnnotation records the paths of the fields dropped or modified by the conversion that produced obj,
+// as reported by the conversion warnings. The annotation is removed when the conversion was lossless.
+func SetConversionLossAnnotation(obj client.Object, warnings []string) error {
+	// Copy the annotations as the converted objects may share their annotations map with the source object.
+	annotations := util.MergeMaps(obj.GetAnnotations(), nil)
+	delete(annotations, consts.ConversionLossAnnotation)
+
+	if len(warnings) > 0 {
+		lossyFieldsJSON, err := json.Marshal(conversionutil.LossyFieldPaths(warnings))
+		if err != nil {
+			return fmt.Errorf("failed to marshal lossy fields: %w", err)
+		}
+
+		annotations[consts.ConversionLossAnnotation] = string(lossyFieldsJSON)
+	}
+
+	obj.SetAnnotations(annotations)
+
+	return nil
+}
+
+// ChangedFields returns the fields that differ between two objects of the same API,
+// for the spec and metadata fields we care about when synchronising MAPI and CAPI objects.
+func ChangedFields(oldSpec, newSpec interface{}, oldMeta, newMeta metav1.ObjectMeta) []string {
+	return append(util.ChangedFields("spec", oldSpec, newSpec), ObjectMetaChangedFields(oldMeta, newMeta)...)
+}
+
+// ObjectMetaChangedFields returns the ObjectMeta fields that differ between a and b, for the fields we care about
+// when synchronising MAPI and CAPI objects.
+// The annotations recording the last synchronization are ignored as they are expected to differ.
+func ObjectMetaChangedFields(a, b metav1.ObjectMeta) []string {
+	changed := []string{}
+
+	if !reflect.DeepEqual(a.Labels, b.Labels) {
+		changed = append(changed, "metadata.labels")
+	}
+
+	if !reflect.DeepEqual(WithoutLastSyncAnnotations(a.Annotations), WithoutLastSyncAnnotations(b.Annotations)) {
+		changed = append(changed, "metadata.annotations")
+	}
+
+	if !reflect.DeepEqual(a.Finalizers, b.Finalizers) {
+		changed = append(changed, "metadata.finalizers")
+	}
+
+	if !reflect.DeepEqual(a.OwnerReferences, b.OwnerReferences) {
+		changed = append(changed, "metadata.ownerReferences")
+	}
+
+	return changed
+}
+
+// WithoutLastSyncAnnotations returns a copy of the annotations without the ones recording the last synchronization.
+func WithoutLastSyncAnnotations(annotations map[string]string) map[string]string {
+	filtered := map[string]string{}
+
+	for k, v := range annotations {
+		if k == consts.LastSyncTimeAnnotation || k == consts.LastSyncHashAnnotation {
+			continue
+		}
+
+		filtered[k] = v
+	}
+
+	if len(filtered) == 0 {
+		return nil
+	}
+
+	return filtered
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package synccommon
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	capiv1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/core/v1beta1"
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+)
+
+var _ = Describe("SetConversionLossAnnotation", func() {
+	It("should record the sorted paths of the lossy fields", func() {
+		capiMachineSet := capiv1resourcebuilder.MachineSet().WithName("foo").Build()
+
+		Expect(SetConversionLossAnnotation(capiMachineSet, []string{
+			"spec.template.spec.providerSpec.value.blockDevices[1].ebs: Invalid value: \"null\": missing ebs configuration for block device",
+			"spec.template.spec.providerSpec.value.blockDevices[0].ebs.deleteOnTermination: Invalid value: false: root volume must be deleted on termination, ignoring invalid value false",
+			"spec.template.spec.providerSpec.value.blockDevices[1].ebs: Invalid value: \"null\": missing ebs configuration for block device",
+		})).To(Succeed())
+
+		Expect(capiMachineSet.Annotations).To(HaveKeyWithValue(consts.ConversionLossAnnotation,
+			`["spec.template.spec.providerSpec.value.blockDevices[0].ebs.deleteOnTermination","spec.template.spec.providerSpec.value.blockDevices[1].ebs"]`))
+	})
+
+	It("should remove the annotation of a lossless conversion without modifying the source annotations", func() {
+		sourceAnnotations := map[string]string{consts.ConversionLossAnnotation: `["spec.foo"]`, "foo": "bar"}
+		capiMachineSet := capiv1resourcebuilder.MachineSet().WithName("foo").WithAnnotations(sourceAnnotations).Build()
+
+		Expect(SetConversionLossAnnotation(capiMachineSet, nil)).To(Succeed())
+
+		Expect(capiMachineSet.Annotations).To(Equal(map[string]string{"foo": "bar"}))
+		Expect(sourceAnnotations).To(HaveKey(consts.ConversionLossAnnotation))
+	})
+})
+
+var _ = Describe("ObjectMetaChangedFields", func() {
+	It("should ignore the last synchronization annotations", func() {
+		a := capiv1resourcebuilder.MachineSet().WithAnnotations(map[string]string{"foo": "bar"}).Build()
+		b := capiv1resourcebuilder.MachineSet().WithAnnotations(map[string]string{"foo": "bar"}).Build()
+		Expect(SetLastSyncAnnotations(b)).To(Succeed())
+
+		Expect(ObjectMetaChangedFields(a.ObjectMeta, b.ObjectMeta)).To(BeEmpty())
+
+		b.Annotations["foo"] = "baz"
+		Expect(ObjectMetaChangedFields(a.ObjectMeta, b.ObjectMeta)).To(ConsistOf("metadata.annotations"))
+	})
+})
@@ -0,0 +1,121 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package synccommon
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+)
+
+// legacyMirrorFieldManager is the field manager of the updates of the mirrors, before they were written with
+// server-side apply. It is the user agent of the machine-api-migration manager, as the updates did not set one.
+const legacyMirrorFieldManager = "machine-api-migration"
+
+// ApplyMirror creates or updates a mirror with server-side apply, as the given field manager. Only the fields set by
+// the conversion are applied, and forced, so that the fields of the mirror owned by other controllers, such as the
+// labels they propagate, are left untouched. The mirror is updated with the object returned by the API server.
+// The fields of an existing mirror written by its former updates are handed over to the field manager first, so that
+// the fields the conversion no longer sets, such as a label removed from the authoritative resource, are pruned.
+func ApplyMirror(ctx context.Context, cl client.Client, mirror client.Object, fieldOwner string) error {
+	applyObj, err := MirrorApplyConfiguration(mirror, cl.Scheme())
+	if err != nil {
+		return err
+	}
+
+	if err := util.UpgradeManagedFields(ctx, cl, applyObj, legacyMirrorFieldManager, fieldOwner); err != nil {
+		return fmt.Errorf("failed to upgrade managed fields of %T: %w", mirror, err)
+	}
+
+	if err := cl.Patch(ctx, applyObj, client.Apply, client.FieldOwner(fieldOwner), client.ForceOwnership); err != nil {
+		return fmt.Errorf("failed to apply %T: %w", mirror, err)
+	}
+
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(applyObj.Object, mirror); err != nil {
+		return fmt.Errorf("failed to convert applied %T: %w", mirror, err)
+	}
+
+	return nil
+}
+
+// MirrorApplyConfiguration returns the apply configuration of a mirror converted from the authoritative API: its
+// identity, the labels, annotations and owner references set by the conversion, and its spec. The fields managed by
+// the API server or other controllers, such as the resource version, the finalizers and the status, are left out.
+func MirrorApplyConfiguration(mirror client.Object, scheme *runtime.Scheme) (*unstructured.Unstructured, error) {
+	gvk, err := apiutil.GVKForObject(mirror, scheme)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the group version kind of %T: %w", mirror, err)
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(mirror)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %T to unstructured: %w", mirror, err)
+	}
+
+	applyObj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	applyObj.SetGroupVersionKind(gvk)
+	applyObj.SetName(mirror.GetName())
+	applyObj.SetNamespace(mirror.GetNamespace())
+	applyObj.SetLabels(mirror.GetLabels())
+	applyObj.SetAnnotations(mirror.GetAnnotations())
+	applyObj.SetOwnerReferences(mirror.GetOwnerReferences())
+
+	if spec, ok := content["spec"].(map[string]interface{}); ok {
+		applyObj.Object["spec"] = withoutNullFields(spec)
+	}
+
+	return applyObj, nil
+}
+
+// withoutNullFields returns a copy of the map without its null fields, such as the unset timestamps of the embedded
+// object metadata, which would otherwise be applied as owned by the field manager.
+func withoutNullFields(fields map[string]interface{}) map[string]interface{} {
+	pruned := make(map[string]interface{}, len(fields))
+
+	for key, value := range fields {
+		if value == nil {
+			continue
+		}
+
+		pruned[key] = withoutNullValues(value)
+	}
+
+	return pruned
+}
+
+// withoutNullValues prunes the null fields of the maps nested in the value.
+func withoutNullValues(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		return withoutNullFields(typed)
+	case []interface{}:
+		pruned := make([]interface{}, 0, len(typed))
+		for _, item := range typed {
+			pruned = append(pruned, withoutNullValues(item))
+		}
+
+		return pruned
+	default:
+		return value
+	}
+}
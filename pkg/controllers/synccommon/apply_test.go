@@ -0,0 +1,178 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package synccommon
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	capiv1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/core/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+const testMirrorFieldOwner = "test-mirror"
+
+var _ = Describe("MirrorApplyConfiguration", func() {
+	var scheme *runtime.Scheme
+	var mirror *capiv1beta1.MachineSet
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Expect(capiv1beta1.AddToScheme(scheme)).To(Succeed())
+
+		mirror = capiv1resourcebuilder.MachineSet().
+			WithName("foo").
+			WithNamespace(capiNamespace).
+			WithLabels(map[string]string{"converted": "label"}).
+			WithAnnotations(map[string]string{"converted": "annotation"}).
+			WithReplicas(3).
+			Build()
+		mirror.SetResourceVersion("42")
+		mirror.SetFinalizers([]string{"foo.io/finalizer"})
+		mirror.Status.Replicas = 2
+	})
+
+	It("should only hold the identity, metadata and spec set by the conversion", func() {
+		applyObj, err := MirrorApplyConfiguration(mirror, scheme)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(applyObj.GroupVersionKind()).To(Equal(capiv1beta1.GroupVersion.WithKind("MachineSet")))
+		Expect(applyObj.GetName()).To(Equal("foo"))
+		Expect(applyObj.GetNamespace()).To(Equal(capiNamespace))
+		Expect(applyObj.GetLabels()).To(Equal(map[string]string{"converted": "label"}))
+		Expect(applyObj.GetAnnotations()).To(Equal(map[string]string{"converted": "annotation"}))
+
+		Expect(applyObj.Object).To(HaveKey("spec"))
+		Expect(applyObj.Object).NotTo(HaveKey("status"))
+		Expect(applyObj.GetResourceVersion()).To(BeEmpty())
+		Expect(applyObj.GetFinalizers()).To(BeEmpty())
+
+		replicas, _, err := unstructured.NestedInt64(applyObj.Object, "spec", "replicas")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replicas).To(BeEquivalentTo(3))
+	})
+
+	It("should not apply the null fields nested in the spec", func() {
+		pruned := withoutNullFields(map[string]interface{}{
+			"providerSpec": map[string]interface{}{
+				"value": map[string]interface{}{
+					"metadata": map[string]interface{}{"creationTimestamp": nil},
+					"tags":     []interface{}{map[string]interface{}{"name": "foo", "value": nil}},
+				},
+			},
+			"taints": nil,
+		})
+
+		Expect(pruned).To(Equal(map[string]interface{}{
+			"providerSpec": map[string]interface{}{
+				"value": map[string]interface{}{
+					"metadata": map[string]interface{}{},
+					"tags":     []interface{}{map[string]interface{}{"name": "foo"}},
+				},
+			},
+		}))
+	})
+})
+
+var _ = Describe("ApplyMirror", func() {
+	ctx := context.Background()
+
+	It("should apply the mirror as the field owner, forcing the conversion fields", func() {
+		scheme := runtime.NewScheme()
+		Expect(capiv1beta1.AddToScheme(scheme)).To(Succeed())
+
+		var applied *unstructured.Unstructured
+		var appliedOpts []client.PatchOption
+
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(_ context.Context, _ client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				Expect(patch).To(Equal(client.Apply))
+
+				applied = obj.(*unstructured.Unstructured).DeepCopy()
+				appliedOpts = opts
+
+				// The API server returns the applied object.
+				obj.SetResourceVersion("43")
+
+				return nil
+			},
+		}).Build()
+
+		mirror := capiv1resourcebuilder.MachineSet().WithName("foo").WithNamespace(capiNamespace).WithReplicas(3).Build()
+		mirror.SetResourceVersion("42")
+
+		Expect(ApplyMirror(ctx, cl, mirror, testMirrorFieldOwner)).To(Succeed())
+
+		Expect(applied).NotTo(BeNil())
+		Expect(applied.GetResourceVersion()).To(BeEmpty(), "the mirror should not be applied with an optimistic lock")
+		Expect(appliedOpts).To(ContainElements(client.FieldOwner(testMirrorFieldOwner), client.ForceOwnership))
+
+		Expect(mirror.GetResourceVersion()).To(Equal("43"))
+		Expect(mirror.Spec.Replicas).To(HaveValue(BeEquivalentTo(3)))
+	})
+
+	It("should hand the fields written by the former updates over to the field owner before applying", func() {
+		scheme := runtime.NewScheme()
+		Expect(capiv1beta1.AddToScheme(scheme)).To(Succeed())
+
+		existing := capiv1resourcebuilder.MachineSet().WithName("foo").WithNamespace(capiNamespace).
+			WithLabels(map[string]string{"removed-label": "true"}).Build()
+		existing.ManagedFields = []metav1.ManagedFieldsEntry{{
+			Manager:    legacyMirrorFieldManager,
+			Operation:  metav1.ManagedFieldsOperationUpdate,
+			APIVersion: capiv1beta1.GroupVersion.String(),
+			FieldsType: "FieldsV1",
+			FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{".":{},"f:removed-label":{}}}}`)},
+		}}
+
+		applied := false
+
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				if patch != client.Apply {
+					return c.Patch(ctx, obj, patch, opts...)
+				}
+
+				// The field owner must own the label before the apply, so that the apply removes it.
+				current := &capiv1beta1.MachineSet{}
+				Expect(c.Get(ctx, client.ObjectKeyFromObject(obj), current)).To(Succeed())
+				Expect(current.ManagedFields).To(ConsistOf(SatisfyAll(
+					HaveField("Manager", Equal(testMirrorFieldOwner)),
+					HaveField("Operation", Equal(metav1.ManagedFieldsOperationApply)),
+					HaveField("FieldsV1.Raw", ContainSubstring("removed-label")),
+				)))
+
+				applied = true
+
+				return nil
+			},
+		}).Build()
+
+		mirror := capiv1resourcebuilder.MachineSet().WithName("foo").WithNamespace(capiNamespace).Build()
+
+		Expect(ApplyMirror(ctx, cl, mirror, testMirrorFieldOwner)).To(Succeed())
+		Expect(applied).To(BeTrue())
+	})
+})
@@ -0,0 +1,110 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package synccommon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	machinev1applyconfigs "github.com/openshift/client-go/machine/applyconfigurations/machine/v1beta1"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// errUnsupportedMAPIObject is returned when the conditions of an object which is not a MAPI Machine or MachineSet
+// are requested.
+var errUnsupportedMAPIObject = errors.New("unsupported MAPI object, expected a Machine or a MachineSet")
+
+// FindCondition returns the condition of the given type from the given conditions, or nil when it is not set.
+func FindCondition(conditions []machinev1beta1.Condition, condType machinev1beta1.ConditionType) *machinev1beta1.Condition {
+	for i := range conditions {
+		if conditions[i].Type == condType {
+			return &conditions[i]
+		}
+	}
+
+	return nil
+}
+
+// SetLastTransitionTime sets the last transition time of the condition apply configuration, keeping the one of the
+// current condition of the same type when its status does not change.
+func SetLastTransitionTime(conditions []machinev1beta1.Condition, conditionAc *machinev1applyconfigs.ConditionApplyConfiguration) {
+	if current := FindCondition(conditions, *conditionAc.Type); current != nil && current.Status == *conditionAc.Status {
+		conditionAc.WithLastTransitionTime(current.LastTransitionTime)
+		return
+	}
+
+	conditionAc.WithLastTransitionTime(metav1.Now())
+}
+
+// MAPIConditions returns the status conditions of a MAPI Machine or MachineSet.
+func MAPIConditions(mapiObj client.Object) ([]machinev1beta1.Condition, error) {
+	switch obj := mapiObj.(type) {
+	case *machinev1beta1.Machine:
+		return obj.Status.Conditions, nil
+	case *machinev1beta1.MachineSet:
+		return obj.Status.Conditions, nil
+	default:
+		return nil, fmt.Errorf("%w: %T", errUnsupportedMAPIObject, mapiObj)
+	}
+}
+
+// PatchMAPIConditions applies the conditions to the status of a MAPI Machine or MachineSet using a server side apply
+// patch. The conditions are owned by the field owner, so that applying them does not remove the conditions owned by
+// the other field owners. Their last transition time is set from the current conditions of the object.
+func PatchMAPIConditions(ctx context.Context, cl client.Client, mapiObj client.Object, fieldOwner string, conditionAcs ...*machinev1applyconfigs.ConditionApplyConfiguration) error {
+	conditions, err := MAPIConditions(mapiObj)
+	if err != nil {
+		return err
+	}
+
+	for _, conditionAc := range conditionAcs {
+		SetLastTransitionTime(conditions, conditionAc)
+	}
+
+	var applyConfig interface{}
+
+	switch mapiObj.(type) {
+	case *machinev1beta1.Machine:
+		applyConfig = machinev1applyconfigs.Machine(mapiObj.GetName(), mapiObj.GetNamespace()).
+			WithStatus(machinev1applyconfigs.MachineStatus().WithConditions(conditionAcs...))
+	case *machinev1beta1.MachineSet:
+		applyConfig = machinev1applyconfigs.MachineSet(mapiObj.GetName(), mapiObj.GetNamespace()).
+			WithStatus(machinev1applyconfigs.MachineSetStatus().WithConditions(conditionAcs...))
+	}
+
+	if err := cl.Status().Patch(ctx, mapiObj, util.ApplyConfigPatch(applyConfig), client.ForceOwnership, client.FieldOwner(fieldOwner)); err != nil {
+		return fmt.Errorf("failed to patch MAPI %s status conditions: %w", kindName(mapiObj), err)
+	}
+
+	return nil
+}
+
+// kindName returns the name of the kind of a MAPI object, as used in messages.
+func kindName(mapiObj client.Object) string {
+	switch mapiObj.(type) {
+	case *machinev1beta1.Machine:
+		return "machine"
+	case *machinev1beta1.MachineSet:
+		return "machine set"
+	default:
+		return fmt.Sprintf("%T", mapiObj)
+	}
+}
@@ -0,0 +1,317 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package synccommon
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	reasonCreatedMirror = "CreatedMirror"
+	reasonUpdatedMirror = "UpdatedMirror"
+)
+
+// Hooks are the kind specific parts of a Mirror. M is the type of the MAPI objects and C the type of the CAPI ones.
+type Hooks[M, C client.Object] struct {
+	// NewMAPI and NewCAPI return empty objects of the mirrored kind.
+	NewMAPI func() M
+	NewCAPI func() C
+	// NewMAPIList returns an empty list of the MAPI objects, used to requeue them when the synchronization is paused
+	// or resumed.
+	NewMAPIList func() client.ObjectList
+
+	// Authority returns the API authoritative for the object, given its MAPI copy. The copies are only mirrored while
+	// either API is authoritative.
+	Authority func(mapiObj M) machinev1beta1.MachineAuthority
+
+	// ToCAPI converts the MAPI copy of an object to its CAPI mirror, and returns the conversion warnings.
+	ToCAPI func(ctx context.Context, mapiObj M) (C, []string, error)
+	// ToMAPI converts the CAPI copy of an object to its MAPI mirror, and returns the conversion warnings. It may be
+	// nil for the kinds which are never authoritative in the Cluster API.
+	ToMAPI func(ctx context.Context, capiObj C) (M, []string, error)
+
+	// CompareCAPI and CompareMAPI return the fields that differ between an existing mirror and the converted one,
+	// the mirror is only updated when they differ. They default to CompareSpecAndMetadata.
+	CompareCAPI func(existing, converted C) ([]string, error)
+	CompareMAPI func(existing, converted M) ([]string, error)
+
+	// Paused is called with the MAPI copy of the object before it is mirrored, with whether the synchronization is
+	// paused, e.g. to report it in the status of the object. It is optional.
+	Paused func(ctx context.Context, mapiObj M, paused bool) error
+}
+
+// Mirror reconciles the objects of a kind which exist under the same name in the MAPI and CAPI namespaces,
+// creating or updating the non-authoritative copy of each object from the authoritative one.
+//
+// The objects are only mirrored when they have a MAPI copy, holding their authoritative API. The mirrors are written
+// with server-side apply, as FieldOwner, and annotated with their last synchronization and the fields lost by the
+// conversion. The objects failing to mirror are retried with a backoff.
+type Mirror[M, C client.Object] struct {
+	client.Client
+	Recorder record.EventRecorder
+
+	CAPINamespace string
+	MAPINamespace string
+
+	// FieldOwner is the field manager the mirrors are applied as.
+	FieldOwner string
+
+	// Shard restricts the reconciler to a subset of the objects, so that the work can be split across replicas.
+	Shard util.Shard
+	// Backoff delays the retries of the objects that failed to mirror, defaults to util.DefaultBackoffConfig.
+	Backoff *util.Backoff
+
+	Hooks Hooks[M, C]
+}
+
+// SetupWithManager sets up a controller with the given name, suffixed with the MAPI namespace, reconciling the MAPI
+// objects when either copy changes and when the synchronization is paused or resumed.
+func (m *Mirror[M, C]) SetupWithManager(mgr ctrl.Manager, name string) error {
+	if m.Backoff == nil {
+		m.Backoff = util.NewBackoff(util.DefaultBackoffConfig)
+	}
+
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named(util.NamespacedControllerName(name, m.MAPINamespace)).
+		For(m.Hooks.NewMAPI(), builder.WithPredicates(util.FilterNamespace(m.MAPINamespace), util.FilterShard(m.Shard))).
+		Watches(
+			m.Hooks.NewCAPI(),
+			handler.EnqueueRequestsFromMapFunc(util.RewriteNamespace(m.MAPINamespace)),
+			builder.WithPredicates(util.FilterNamespace(m.CAPINamespace), util.FilterShard(m.Shard)),
+		).
+		Watches(
+			&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, _ client.Object) []reconcile.Request {
+				return RequestsForNamespace(ctx, m.Client, m.Hooks.NewMAPIList(), m.MAPINamespace, m.Shard)
+			}),
+			builder.WithPredicates(util.FilterSyncPauseChanges(m.MAPINamespace)),
+		).
+		Complete(m); err != nil {
+		return fmt.Errorf("failed to create controller: %w", err)
+	}
+
+	if m.Client == nil {
+		m.Client = mgr.GetClient()
+	}
+
+	m.Recorder = mgr.GetEventRecorderFor(name)
+
+	return nil
+}
+
+// Reconcile mirrors the authoritative copy of the object onto the other one.
+func (m *Mirror[M, C]) Reconcile(ctx context.Context, req reconcile.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("namespace", req.Namespace, "name", req.Name)
+	ctx = log.IntoContext(ctx, logger)
+
+	paused, err := util.IsSyncPaused(ctx, m.Client, m.MAPINamespace)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to check whether the synchronization is paused: %w", err)
+	}
+
+	mapiObj := m.Hooks.NewMAPI()
+	if err := m.Get(ctx, client.ObjectKey{Namespace: m.MAPINamespace, Name: req.Name}, mapiObj); apierrors.IsNotFound(err) {
+		logger.V(1).Info("MAPI copy not found, nothing to mirror")
+		return ctrl.Result{}, nil
+	} else if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get MAPI %T: %w", mapiObj, err)
+	}
+
+	if m.Hooks.Paused != nil {
+		if err := m.Hooks.Paused(ctx, mapiObj, paused); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// The backoff is left untouched while paused, so that the retry budget of the objects is kept across the pause.
+	if paused {
+		logger.Info("Synchronization is paused, not mirroring")
+		return ctrl.Result{}, nil
+	}
+
+	err = m.reconcile(ctx, mapiObj)
+
+	result, _ := m.Backoff.Result(req.Name, ctrl.Result{}, err)
+	if err != nil {
+		logger.Error(err, "Failed to mirror", "requeueAfter", result.RequeueAfter)
+	}
+
+	return result, nil
+}
+
+// reconcile mirrors the copy of the authoritative API onto the other one.
+func (m *Mirror[M, C]) reconcile(ctx context.Context, mapiObj M) error {
+	capiObj := m.Hooks.NewCAPI()
+
+	capiFound := true
+	if err := m.Get(ctx, client.ObjectKey{Namespace: m.CAPINamespace, Name: mapiObj.GetName()}, capiObj); apierrors.IsNotFound(err) {
+		capiFound = false
+	} else if err != nil {
+		return fmt.Errorf("failed to get CAPI %T: %w", capiObj, err)
+	}
+
+	switch authority := m.Hooks.Authority(mapiObj); authority {
+	case machinev1beta1.MachineAuthorityMachineAPI:
+		converted, warnings, err := m.Hooks.ToCAPI(ctx, mapiObj)
+		if err != nil {
+			return fmt.Errorf("failed to convert MAPI %T to CAPI: %w", mapiObj, err)
+		}
+
+		return applyMirror(ctx, m, mapiObj, capiObj, capiFound, converted, warnings, m.CAPINamespace, m.Hooks.CompareCAPI)
+	case machinev1beta1.MachineAuthorityClusterAPI:
+		if !capiFound {
+			log.FromContext(ctx).Info("CAPI copy of the authoritative API not found, nothing to mirror")
+			return nil
+		}
+
+		if m.Hooks.ToMAPI == nil {
+			log.FromContext(ctx).Info("Not mirroring a kind which cannot be converted to MAPI", "authoritativeAPI", authority)
+			return nil
+		}
+
+		converted, warnings, err := m.Hooks.ToMAPI(ctx, capiObj)
+		if err != nil {
+			return fmt.Errorf("failed to convert CAPI %T to MAPI: %w", capiObj, err)
+		}
+
+		return applyMirror(ctx, m, mapiObj, mapiObj, true, converted, warnings, m.MAPINamespace, m.Hooks.CompareMAPI)
+	default:
+		log.FromContext(ctx).Info("Not mirroring while the authoritative API is not settled", "authoritativeAPI", authority)
+		return nil
+	}
+}
+
+// applyMirror applies the converted copy of an object as its mirror, when the mirror does not exist yet or when it
+// differs from the existing one.
+func applyMirror[M, C client.Object, T client.Object](ctx context.Context, m *Mirror[M, C], mapiObj M, existing T, found bool, converted T, warnings []string, namespace string, compare func(existing, converted T) ([]string, error)) error {
+	converted.SetName(mapiObj.GetName())
+	converted.SetNamespace(namespace)
+
+	if err := SetConversionLossAnnotation(converted, warnings); err != nil {
+		return err
+	}
+
+	reason, message := reasonCreatedMirror, fmt.Sprintf("Created mirror %T in namespace %s", converted, namespace)
+
+	if found {
+		if compare == nil {
+			compare = func(existing, converted T) ([]string, error) { return CompareSpecAndMetadata(existing, converted) }
+		}
+
+		changedFields, err := compare(existing, converted)
+		if err != nil {
+			return fmt.Errorf("failed to compare mirror %T: %w", existing, err)
+		} else if len(changedFields) == 0 {
+			log.FromContext(ctx).V(1).Info("Mirror is up to date", "type", fmt.Sprintf("%T", existing))
+			return nil
+		}
+
+		reason, message = reasonUpdatedMirror, fmt.Sprintf("Updated mirror %T in namespace %s, changed fields: %v", converted, namespace, changedFields)
+	}
+
+	if err := SetLastSyncAnnotations(converted); err != nil {
+		return err
+	}
+
+	if err := ApplyMirror(ctx, m.Client, converted, m.FieldOwner); err != nil {
+		return err
+	}
+
+	log.FromContext(ctx).Info(message)
+	m.recordEvent(mapiObj, reason, message)
+
+	return nil
+}
+
+// recordEvent records an event on the MAPI copy of an object, when the Mirror has a recorder.
+func (m *Mirror[M, C]) recordEvent(mapiObj M, reason, message string) {
+	if m.Recorder == nil {
+		return
+	}
+
+	m.Recorder.Event(mapiObj, corev1.EventTypeNormal, reason, message)
+}
+
+// CompareSpecAndMetadata returns the spec and metadata fields that differ between two objects of the same API,
+// for the fields we care about when synchronising MAPI and CAPI objects.
+func CompareSpecAndMetadata(a, b client.Object) ([]string, error) {
+	aSpec, err := unstructuredSpec(a)
+	if err != nil {
+		return nil, err
+	}
+
+	bSpec, err := unstructuredSpec(b)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := []string{}
+
+	for _, field := range unionKeys(aSpec, bSpec) {
+		if !reflect.DeepEqual(aSpec[field], bSpec[field]) {
+			changed = append(changed, "spec."+field)
+		}
+	}
+
+	return append(changed, ObjectMetaChangedFields(objectMeta(a), objectMeta(b))...), nil
+}
+
+// unstructuredSpec returns the spec of the object as an unstructured map.
+func unstructuredSpec(obj client.Object) (map[string]interface{}, error) {
+	unstructuredObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %T to unstructured: %w", obj, err)
+	}
+
+	spec, _ := unstructuredObj["spec"].(map[string]interface{})
+
+	return spec, nil
+}
+
+// unionKeys returns the sorted keys present in either map.
+func unionKeys(a, b map[string]interface{}) []string {
+	keys := sets.KeySet(a).Union(sets.KeySet(b))
+
+	return sets.List(keys)
+}
+
+// objectMeta returns the ObjectMeta fields of the object compared by ObjectMetaChangedFields.
+func objectMeta(obj client.Object) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Labels:          obj.GetLabels(),
+		Annotations:     obj.GetAnnotations(),
+		Finalizers:      obj.GetFinalizers(),
+		OwnerReferences: obj.GetOwnerReferences(),
+	}
+}
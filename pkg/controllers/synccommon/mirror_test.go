@@ -0,0 +1,241 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package synccommon
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	capiv1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/core/v1beta1"
+	corev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/core/v1"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// maxLosslessReplicas is the number of replicas above which the test conversion reports a loss.
+const maxLosslessReplicas = 5
+
+// machineSetHooks mirror the replicas of the machine sets, as a minimal mirrored kind.
+func machineSetHooks() Hooks[*machinev1beta1.MachineSet, *capiv1beta1.MachineSet] {
+	return Hooks[*machinev1beta1.MachineSet, *capiv1beta1.MachineSet]{
+		NewMAPI:     func() *machinev1beta1.MachineSet { return &machinev1beta1.MachineSet{} },
+		NewCAPI:     func() *capiv1beta1.MachineSet { return &capiv1beta1.MachineSet{} },
+		NewMAPIList: func() client.ObjectList { return &machinev1beta1.MachineSetList{} },
+		Authority: func(mapiMachineSet *machinev1beta1.MachineSet) machinev1beta1.MachineAuthority {
+			return mapiMachineSet.Spec.AuthoritativeAPI
+		},
+		ToCAPI: func(_ context.Context, mapiMachineSet *machinev1beta1.MachineSet) (*capiv1beta1.MachineSet, []string, error) {
+			var warnings []string
+			if ptr.Deref(mapiMachineSet.Spec.Replicas, 0) > maxLosslessReplicas {
+				warnings = append(warnings, fmt.Sprintf("spec.replicas: Invalid value: %d: too many replicas", *mapiMachineSet.Spec.Replicas))
+			}
+
+			return capiv1resourcebuilder.MachineSet().WithReplicas(ptr.Deref(mapiMachineSet.Spec.Replicas, 0)).Build(), warnings, nil
+		},
+		ToMAPI: func(_ context.Context, capiMachineSet *capiv1beta1.MachineSet) (*machinev1beta1.MachineSet, []string, error) {
+			return machinev1resourcebuilder.MachineSet().
+				WithReplicas(ptr.Deref(capiMachineSet.Spec.Replicas, 0)).
+				WithAuthoritativeAPI(machinev1beta1.MachineAuthorityClusterAPI).
+				Build(), nil, nil
+		},
+	}
+}
+
+// applyAsCreateOrUpdate stands in for server-side apply, which the fake client does not support, by creating the
+// applied object or replacing the existing one.
+func applyAsCreateOrUpdate(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch != client.Apply {
+		return c.Patch(ctx, obj, patch, opts...)
+	}
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GetObjectKind().GroupVersionKind())
+
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), existing); apierrors.IsNotFound(err) {
+		return c.Create(ctx, obj)
+	} else if err != nil {
+		return err
+	}
+
+	obj.SetResourceVersion(existing.GetResourceVersion())
+
+	return c.Update(ctx, obj)
+}
+
+var _ = Describe("Mirror", func() {
+	var cl client.Client
+	var mirror *Mirror[*machinev1beta1.MachineSet, *capiv1beta1.MachineSet]
+
+	ctx := context.Background()
+
+	newMAPIMachineSet := func(authority machinev1beta1.MachineAuthority, replicas int32) *machinev1beta1.MachineSet {
+		return machinev1resourcebuilder.MachineSet().
+			WithNamespace(mapiNamespace).
+			WithName("foo").
+			WithReplicas(replicas).
+			WithAuthoritativeAPI(authority).
+			Build()
+	}
+
+	newCAPIMachineSet := func(replicas int32) *capiv1beta1.MachineSet {
+		return capiv1resourcebuilder.MachineSet().WithNamespace(capiNamespace).WithName("foo").WithReplicas(replicas).Build()
+	}
+
+	setUp := func(objs ...client.Object) {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(machinev1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(capiv1beta1.AddToScheme(scheme)).To(Succeed())
+
+		objs = append(objs, corev1resourcebuilder.Namespace().WithName(mapiNamespace).Build())
+		cl = fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).WithInterceptorFuncs(interceptor.Funcs{
+			Patch: applyAsCreateOrUpdate,
+		}).Build()
+
+		mirror = &Mirror[*machinev1beta1.MachineSet, *capiv1beta1.MachineSet]{
+			Client:        cl,
+			MAPINamespace: mapiNamespace,
+			CAPINamespace: capiNamespace,
+			FieldOwner:    testMirrorFieldOwner,
+			Backoff:       util.NewBackoff(util.DefaultBackoffConfig),
+			Hooks:         machineSetHooks(),
+		}
+	}
+
+	reconcileMachineSet := func() {
+		_, err := mirror.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKey{Namespace: mapiNamespace, Name: "foo"}})
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	getCAPIMachineSet := func() *capiv1beta1.MachineSet {
+		capiMachineSet := &capiv1beta1.MachineSet{}
+		Expect(cl.Get(ctx, client.ObjectKey{Namespace: capiNamespace, Name: "foo"}, capiMachineSet)).To(Succeed())
+
+		return capiMachineSet
+	}
+
+	getMAPIMachineSet := func() *machinev1beta1.MachineSet {
+		mapiMachineSet := &machinev1beta1.MachineSet{}
+		Expect(cl.Get(ctx, client.ObjectKey{Namespace: mapiNamespace, Name: "foo"}, mapiMachineSet)).To(Succeed())
+
+		return mapiMachineSet
+	}
+
+	Context("when the MAPI copy is authoritative", func() {
+		It("should create the CAPI mirror with the last synchronization and conversion loss annotations", func() {
+			setUp(newMAPIMachineSet(machinev1beta1.MachineAuthorityMachineAPI, maxLosslessReplicas+1))
+
+			reconcileMachineSet()
+
+			capiMachineSet := getCAPIMachineSet()
+			Expect(capiMachineSet.Spec.Replicas).To(HaveValue(BeEquivalentTo(maxLosslessReplicas + 1)))
+			Expect(capiMachineSet.Annotations).To(SatisfyAll(
+				HaveKey(consts.LastSyncTimeAnnotation),
+				HaveKey(consts.LastSyncHashAnnotation),
+				HaveKeyWithValue(consts.ConversionLossAnnotation, `["spec.replicas"]`),
+			))
+		})
+
+		It("should update the CAPI mirror only when it differs", func() {
+			setUp(newMAPIMachineSet(machinev1beta1.MachineAuthorityMachineAPI, 3), newCAPIMachineSet(2))
+
+			reconcileMachineSet()
+
+			capiMachineSet := getCAPIMachineSet()
+			Expect(capiMachineSet.Spec.Replicas).To(HaveValue(BeEquivalentTo(3)))
+			Expect(capiMachineSet.Annotations).ToNot(HaveKey(consts.ConversionLossAnnotation))
+
+			reconcileMachineSet()
+
+			Expect(getCAPIMachineSet().ResourceVersion).To(Equal(capiMachineSet.ResourceVersion))
+		})
+
+		It("should use the compare hook to decide whether the CAPI mirror differs", func() {
+			setUp(newMAPIMachineSet(machinev1beta1.MachineAuthorityMachineAPI, 3), newCAPIMachineSet(2))
+			mirror.Hooks.CompareCAPI = func(_, _ *capiv1beta1.MachineSet) ([]string, error) { return nil, nil }
+
+			reconcileMachineSet()
+
+			Expect(getCAPIMachineSet().Spec.Replicas).To(HaveValue(BeEquivalentTo(2)))
+		})
+	})
+
+	Context("when the CAPI copy is authoritative", func() {
+		It("should update the MAPI mirror", func() {
+			setUp(newMAPIMachineSet(machinev1beta1.MachineAuthorityClusterAPI, 2), newCAPIMachineSet(3))
+
+			reconcileMachineSet()
+
+			mapiMachineSet := getMAPIMachineSet()
+			Expect(mapiMachineSet.Spec.Replicas).To(HaveValue(BeEquivalentTo(3)))
+			Expect(mapiMachineSet.Annotations).To(HaveKey(consts.LastSyncTimeAnnotation))
+		})
+
+		It("should not create a missing CAPI copy", func() {
+			setUp(newMAPIMachineSet(machinev1beta1.MachineAuthorityClusterAPI, 2))
+
+			reconcileMachineSet()
+
+			Expect(cl.Get(ctx, client.ObjectKey{Namespace: capiNamespace, Name: "foo"}, &capiv1beta1.MachineSet{})).ToNot(Succeed())
+		})
+	})
+
+	It("should not mirror a migrating object", func() {
+		setUp(newMAPIMachineSet(machinev1beta1.MachineAuthorityMigrating, 3), newCAPIMachineSet(2))
+
+		reconcileMachineSet()
+
+		Expect(getCAPIMachineSet().Spec.Replicas).To(HaveValue(BeEquivalentTo(2)))
+		Expect(getMAPIMachineSet().Spec.Replicas).To(HaveValue(BeEquivalentTo(3)))
+	})
+
+	It("should report the pause and not mirror while the synchronization is paused", func() {
+		setUp(newMAPIMachineSet(machinev1beta1.MachineAuthorityMachineAPI, 3))
+
+		namespace := corev1resourcebuilder.Namespace().WithName(mapiNamespace).Build()
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(namespace), namespace)).To(Succeed())
+		namespace.SetAnnotations(map[string]string{util.SyncPausedAnnotation: "true"})
+		Expect(cl.Update(ctx, namespace)).To(Succeed())
+
+		var reportedPaused *bool
+
+		mirror.Hooks.Paused = func(_ context.Context, _ *machinev1beta1.MachineSet, paused bool) error {
+			reportedPaused = &paused
+			return nil
+		}
+
+		reconcileMachineSet()
+
+		Expect(reportedPaused).To(HaveValue(BeTrue()))
+		Expect(cl.Get(ctx, client.ObjectKey{Namespace: capiNamespace, Name: "foo"}, &capiv1beta1.MachineSet{})).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,175 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package synccommon
+
+import (
+	"context"
+	"fmt"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	machinev1applyconfigs "github.com/openshift/client-go/machine/applyconfigurations/machine/v1beta1"
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// StatusReporter reports whether the synchronization of the MAPI objects of a kind is paused or degraded,
+// with the paused and degraded conditions of the MAPI objects.
+type StatusReporter struct {
+	Client   client.Client
+	Recorder record.EventRecorder
+	Backoff  *util.Backoff
+
+	// Namespace is the namespace of the MAPI objects.
+	Namespace string
+	// NewObject returns an empty MAPI object of the kind, either a Machine or a MachineSet.
+	NewObject func() client.Object
+
+	// PauseFieldOwner and BackoffFieldOwner own the paused and degraded conditions. They differ from the owners of the
+	// other conditions, so that applying these does not remove the paused and degraded conditions.
+	PauseFieldOwner   string
+	BackoffFieldOwner string
+}
+
+// SyncPausedCondition reports whether the synchronization is paused on the MAPI object with the given name.
+// The paused condition is only set once the synchronization has been paused, and reset when it is resumed.
+func (s StatusReporter) SyncPausedCondition(ctx context.Context, name string, paused bool) error {
+	mapiObj, conditions, err := s.getMAPIObject(ctx, name)
+	if err != nil || mapiObj == nil {
+		return err
+	}
+
+	current := FindCondition(conditions, consts.PausedCondition)
+
+	switch {
+	case paused && (current == nil || current.Status != corev1.ConditionTrue):
+		message := fmt.Sprintf("The synchronization of the %ss is paused by the %s annotation of the namespace", kindName(mapiObj), util.SyncPausedAnnotation)
+
+		return PatchMAPIConditions(ctx, s.Client, mapiObj, s.PauseFieldOwner,
+			condition(consts.PausedCondition, corev1.ConditionTrue, consts.ReasonSyncPaused, message, machinev1beta1.ConditionSeverityWarning))
+	case !paused && current != nil && current.Status == corev1.ConditionTrue:
+		return PatchMAPIConditions(ctx, s.Client, mapiObj, s.PauseFieldOwner,
+			condition(consts.PausedCondition, corev1.ConditionFalse, consts.ReasonSyncResumed, "", machinev1beta1.ConditionSeverityNone))
+	default:
+		return nil
+	}
+}
+
+// ResultWithBackoff ends the reconciliation of the object with the given name, delaying its retry when it failed.
+// Once its retry budget is exhausted, the object is no longer requeued and the degraded condition of the MAPI object
+// is set, the condition is reset once the object is reconciled successfully.
+func (s StatusReporter) ResultWithBackoff(ctx context.Context, name string, result ctrl.Result, err error) (ctrl.Result, error) {
+	failures := s.Backoff.Failures(name) + 1
+	result, exhausted := s.Backoff.Result(name, result, err)
+
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to reconcile "+kindName(s.NewObject()), "failures", failures, "requeueAfter", result.RequeueAfter)
+	}
+
+	mapiObj, conditions, getErr := s.getMAPIObject(ctx, name)
+	if getErr != nil || mapiObj == nil {
+		return result, getErr
+	}
+
+	degraded := FindCondition(conditions, consts.DegradedCondition)
+
+	switch {
+	case exhausted:
+		message := fmt.Sprintf("Stopped retrying to synchronize the %s, it is synchronized again when it changes: %v", kindName(mapiObj), err)
+
+		if degraded != nil && degraded.Status == corev1.ConditionTrue && degraded.Message == message {
+			return result, nil
+		}
+
+		if degraded == nil || degraded.Status != corev1.ConditionTrue {
+			s.Recorder.Event(mapiObj, corev1.EventTypeWarning, consts.ReasonRetryBudgetExhausted, message)
+		}
+
+		return result, PatchMAPIConditions(ctx, s.Client, mapiObj, s.BackoffFieldOwner,
+			condition(consts.DegradedCondition, corev1.ConditionTrue, consts.ReasonRetryBudgetExhausted, message, machinev1beta1.ConditionSeverityError))
+	case err == nil && degraded != nil && degraded.Status == corev1.ConditionTrue:
+		return result, PatchMAPIConditions(ctx, s.Client, mapiObj, s.BackoffFieldOwner,
+			condition(consts.DegradedCondition, corev1.ConditionFalse, consts.ReasonResourceSynchronized, "", machinev1beta1.ConditionSeverityNone))
+	default:
+		return result, nil
+	}
+}
+
+// getMAPIObject returns the MAPI object with the given name and its conditions, or nil when it does not exist.
+func (s StatusReporter) getMAPIObject(ctx context.Context, name string) (client.Object, []machinev1beta1.Condition, error) {
+	mapiObj := s.NewObject()
+	if err := s.Client.Get(ctx, client.ObjectKey{Namespace: s.Namespace, Name: name}, mapiObj); apierrors.IsNotFound(err) {
+		// A CAPI object without a MAPI counterpart has nowhere to report the conditions.
+		return nil, nil, nil
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("failed to get MAPI %s: %w", kindName(mapiObj), err)
+	}
+
+	conditions, err := MAPIConditions(mapiObj)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return mapiObj, conditions, nil
+}
+
+// condition returns the apply configuration of a condition.
+func condition(condType machinev1beta1.ConditionType, status corev1.ConditionStatus, reason, message string, severity machinev1beta1.ConditionSeverity) *machinev1applyconfigs.ConditionApplyConfiguration {
+	return machinev1applyconfigs.Condition().
+		WithType(condType).
+		WithStatus(status).
+		WithReason(reason).
+		WithMessage(message).
+		WithSeverity(severity)
+}
+
+// RequestsForNamespace returns the requests of the objects of the namespace owned by the shard, listed with the given
+// list, so that their synchronization is paused or resumed along with the namespace.
+func RequestsForNamespace(ctx context.Context, cl client.Reader, list client.ObjectList, namespace string, shard util.Shard) []reconcile.Request {
+	if err := cl.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list objects to requeue after a sync pause change", "type", fmt.Sprintf("%T", list))
+		return nil
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to extract objects to requeue after a sync pause change", "type", fmt.Sprintf("%T", list))
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+
+	for _, item := range items {
+		obj, ok := item.(client.Object)
+		if !ok || !shard.Owns(obj.GetName()) {
+			continue
+		}
+
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKey{Namespace: namespace, Name: obj.GetName()},
+		})
+	}
+
+	return requests
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package synccommon
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("RequestsForNamespace", func() {
+	It("should request the objects of the namespace owned by the shard", func() {
+		scheme := runtime.NewScheme()
+		Expect(machinev1beta1.AddToScheme(scheme)).To(Succeed())
+
+		objs := []client.Object{
+			machinev1resourcebuilder.Machine().WithNamespace(mapiNamespace).WithName("foo").Build(),
+			machinev1resourcebuilder.Machine().WithNamespace(mapiNamespace).WithName("bar").Build(),
+			machinev1resourcebuilder.Machine().WithNamespace("other").WithName("baz").Build(),
+		}
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+
+		// foo hashes to the second of two shards, bar to the first.
+		shard := util.Shard{Index: 1, Count: 2}
+
+		Expect(RequestsForNamespace(context.Background(), cl, &machinev1beta1.MachineList{}, mapiNamespace, shard)).To(ConsistOf(
+			reconcile.Request{NamespacedName: client.ObjectKey{Namespace: mapiNamespace, Name: "foo"}},
+		))
+	})
+})
@@ -0,0 +1,239 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package upgradeguard
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	configv1alpha1 "github.com/openshift/cluster-capi-operator/pkg/apis/config/v1alpha1"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
+)
+
+const (
+	controllerName = "UpgradeGuardController"
+
+	// maxListedBlockers is the maximum number of resources listed in the Upgradeable condition message.
+	maxListedBlockers = 10
+)
+
+// UpgradeGuardReconciler sets the Upgradeable condition of the ClusterOperator to False while Machine API
+// resources are being migrated, so that cluster upgrades do not race a half-finished authority transfer.
+// Upgrades are blocked while:
+//   - a MAPI Machine or MachineSet is migrating between the Machine API and Cluster API.
+//   - a paused CAPI Machine or MachineSet is not mirroring a MAPI authoritative resource, as nothing would unpause it.
+//
+// A single reconciler guards all the synchronized namespace pairs, as they all contribute to the same condition.
+type UpgradeGuardReconciler struct {
+	operatorstatus.ClusterOperatorStatusClient
+
+	// NamespacePairs are the synchronized MAPI and CAPI namespaces.
+	NamespacePairs []configv1alpha1.NamespacePair
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *UpgradeGuardReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// All the watched resources contribute to the same ClusterOperator condition.
+	toClusterOperator := handler.EnqueueRequestsFromMapFunc(func(context.Context, client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: client.ObjectKey{Name: controllers.ClusterOperatorName}}}
+	})
+
+	mapiNamespaces, capiNamespaces := sets.New[string](), sets.New[string]()
+	for _, pair := range r.NamespacePairs {
+		mapiNamespaces.Insert(pair.MAPINamespace)
+		capiNamespaces.Insert(pair.CAPINamespace)
+	}
+
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named(controllerName).
+		// The ClusterOperator is watched to restore the Upgradeable condition when it is overwritten.
+		For(&configv1.ClusterOperator{}, builder.WithPredicates(clusterOperatorPredicate())).
+		Watches(&machinev1beta1.Machine{}, toClusterOperator, builder.WithPredicates(inNamespaces(mapiNamespaces))).
+		Watches(&machinev1beta1.MachineSet{}, toClusterOperator, builder.WithPredicates(inNamespaces(mapiNamespaces))).
+		Watches(&capiv1beta1.Machine{}, toClusterOperator, builder.WithPredicates(inNamespaces(capiNamespaces))).
+		Watches(&capiv1beta1.MachineSet{}, toClusterOperator, builder.WithPredicates(inNamespaces(capiNamespaces))).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create controller: %w", err)
+	}
+
+	return nil
+}
+
+// Reconcile blocks or unblocks the cluster upgrades depending on the ongoing migrations.
+func (r *UpgradeGuardReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := ctrl.LoggerFrom(ctx).WithName(controllerName)
+	ctx = ctrl.LoggerInto(ctx, logger)
+
+	blockers, err := r.getUpgradeBlockers(ctx)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get resources blocking upgrades: %w", err)
+	}
+
+	if len(blockers) == 0 {
+		if err := r.ClearUpgradeBlocked(ctx); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to unblock upgrades: %w", err)
+		}
+
+		return ctrl.Result{}, nil
+	}
+
+	logger.Info("Blocking upgrades until migrations are complete", "resources", blockers)
+
+	if err := r.SetUpgradeBlocked(ctx, upgradeBlockedMessage(blockers)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to block upgrades: %w", err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// getUpgradeBlockers returns a description of each resource blocking upgrades in any of the namespace pairs, sorted
+// for a stable condition message.
+func (r *UpgradeGuardReconciler) getUpgradeBlockers(ctx context.Context) ([]string, error) {
+	blockers := []string{}
+
+	for _, pair := range r.NamespacePairs {
+		pairBlockers, err := r.getNamespacePairUpgradeBlockers(ctx, pair)
+		if err != nil {
+			return nil, err
+		}
+
+		blockers = append(blockers, pairBlockers...)
+	}
+
+	slices.Sort(blockers)
+
+	return blockers, nil
+}
+
+// getNamespacePairUpgradeBlockers returns a description of each resource of the namespace pair blocking upgrades.
+// The resources are named by their namespace when there are several pairs, so that they can be told apart.
+func (r *UpgradeGuardReconciler) getNamespacePairUpgradeBlockers(ctx context.Context, pair configv1alpha1.NamespacePair) ([]string, error) {
+	name := func(obj client.Object) string {
+		if len(r.NamespacePairs) > 1 {
+			return obj.GetNamespace() + "/" + obj.GetName()
+		}
+
+		return obj.GetName()
+	}
+
+	mapiMachines := &machinev1beta1.MachineList{}
+	if err := r.List(ctx, mapiMachines, client.InNamespace(pair.MAPINamespace)); err != nil {
+		return nil, fmt.Errorf("failed to list MAPI machines: %w", err)
+	}
+
+	mapiMachineSets := &machinev1beta1.MachineSetList{}
+	if err := r.List(ctx, mapiMachineSets, client.InNamespace(pair.MAPINamespace)); err != nil {
+		return nil, fmt.Errorf("failed to list MAPI machine sets: %w", err)
+	}
+
+	capiMachines := &capiv1beta1.MachineList{}
+	if err := r.List(ctx, capiMachines, client.InNamespace(pair.CAPINamespace)); err != nil {
+		return nil, fmt.Errorf("failed to list CAPI machines: %w", err)
+	}
+
+	capiMachineSets := &capiv1beta1.MachineSetList{}
+	if err := r.List(ctx, capiMachineSets, client.InNamespace(pair.CAPINamespace)); err != nil {
+		return nil, fmt.Errorf("failed to list CAPI machine sets: %w", err)
+	}
+
+	machineAuthorities := map[string]machinev1beta1.MachineAuthority{}
+	blockers := []string{}
+
+	for i := range mapiMachines.Items {
+		m := &mapiMachines.Items[i]
+		machineAuthorities[m.Name] = m.Status.AuthoritativeAPI
+
+		if m.Status.AuthoritativeAPI == machinev1beta1.MachineAuthorityMigrating {
+			blockers = append(blockers, fmt.Sprintf("Machine %s is migrating", name(m)))
+		}
+	}
+
+	machineSetAuthorities := map[string]machinev1beta1.MachineAuthority{}
+
+	for i := range mapiMachineSets.Items {
+		ms := &mapiMachineSets.Items[i]
+		machineSetAuthorities[ms.Name] = ms.Status.AuthoritativeAPI
+
+		if ms.Status.AuthoritativeAPI == machinev1beta1.MachineAuthorityMigrating {
+			blockers = append(blockers, fmt.Sprintf("MachineSet %s is migrating", name(ms)))
+		}
+	}
+
+	for i := range capiMachines.Items {
+		if m := &capiMachines.Items[i]; isOrphanedMirror(m, machineAuthorities) {
+			blockers = append(blockers, fmt.Sprintf("Cluster API Machine %s is paused without a Machine API authoritative Machine", name(m)))
+		}
+	}
+
+	for i := range capiMachineSets.Items {
+		if ms := &capiMachineSets.Items[i]; isOrphanedMirror(ms, machineSetAuthorities) {
+			blockers = append(blockers, fmt.Sprintf("Cluster API MachineSet %s is paused without a Machine API authoritative MachineSet", name(ms)))
+		}
+	}
+
+	return blockers, nil
+}
+
+// inNamespaces filters the objects of the given namespaces.
+func inNamespaces(namespaces sets.Set[string]) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return namespaces.Has(obj.GetNamespace())
+	})
+}
+
+// isOrphanedMirror returns whether the CAPI resource is paused, while its MAPI counterpart, that it is supposed
+// to mirror, is either missing or no longer authoritative. Nothing would unpause such a resource.
+func isOrphanedMirror(obj client.Object, mapiAuthorities map[string]machinev1beta1.MachineAuthority) bool {
+	if !annotations.HasPaused(obj) || !obj.GetDeletionTimestamp().IsZero() {
+		return false
+	}
+
+	authority, ok := mapiAuthorities[obj.GetName()]
+
+	// Migrating resources already block upgrades.
+	return !ok || (authority != machinev1beta1.MachineAuthorityMachineAPI && authority != machinev1beta1.MachineAuthorityMigrating)
+}
+
+// upgradeBlockedMessage returns the Upgradeable condition message listing the resources blocking upgrades.
+func upgradeBlockedMessage(blockers []string) string {
+	listed := blockers
+	if len(listed) > maxListedBlockers {
+		listed = listed[:maxListedBlockers]
+	}
+
+	message := fmt.Sprintf("Upgrades are blocked until Machine API migrations are complete: %s", strings.Join(listed, ", "))
+
+	if len(blockers) > len(listed) {
+		message += fmt.Sprintf(" and %d more", len(blockers)-len(listed))
+	}
+
+	return message
+}
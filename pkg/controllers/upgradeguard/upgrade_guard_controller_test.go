@@ -0,0 +1,213 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package upgradeguard
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	capiv1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/core/v1beta1"
+	corev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/core/v1"
+	machinev1resourcebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	"github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest/komega"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	configv1alpha1 "github.com/openshift/cluster-capi-operator/pkg/apis/config/v1alpha1"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
+	"github.com/openshift/cluster-capi-operator/pkg/test"
+)
+
+var _ = Describe("upgradeBlockedMessage", func() {
+	It("should list all the resources blocking upgrades", func() {
+		Expect(upgradeBlockedMessage([]string{"Machine a is migrating", "Machine b is migrating"})).To(Equal(
+			"Upgrades are blocked until Machine API migrations are complete: Machine a is migrating, Machine b is migrating"))
+	})
+
+	It("should truncate long lists of resources", func() {
+		blockers := []string{}
+		for i := 0; i < maxListedBlockers+2; i++ {
+			blockers = append(blockers, fmt.Sprintf("Machine %d is migrating", i))
+		}
+
+		Expect(upgradeBlockedMessage(blockers)).To(HaveSuffix("Machine 9 is migrating and 2 more"))
+	})
+})
+
+var _ = Describe("Upgrade guard controller", func() {
+	const machineName = "foo"
+
+	var k komega.Komega
+	var reconciler *UpgradeGuardReconciler
+
+	var mapiNamespace, capiNamespace *corev1.Namespace
+	var co *configv1.ClusterOperator
+	var mapiMachine *machinev1beta1.Machine
+	var capiMachine *capiv1beta1.Machine
+
+	reconcileUpgradeGuard := func() {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKey{Name: controllers.ClusterOperatorName}})
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	upgradeable := func() *configv1.ClusterOperatorStatusCondition {
+		Expect(k.Get(co)()).To(Succeed())
+		return v1helpers.FindStatusCondition(co.Status.Conditions, configv1.OperatorUpgradeable)
+	}
+
+	setAuthority := func(authority machinev1beta1.MachineAuthority) {
+		Eventually(k.UpdateStatus(mapiMachine, func() {
+			mapiMachine.Status.AuthoritativeAPI = authority
+		})).Should(Succeed())
+	}
+
+	BeforeEach(func() {
+		k = komega.New(cl)
+
+		By("Setting up the namespaces for the test")
+		mapiNamespace = corev1resourcebuilder.Namespace().WithGenerateName("openshift-machine-api-").Build()
+		Expect(cl.Create(ctx, mapiNamespace)).To(Succeed())
+
+		capiNamespace = corev1resourcebuilder.Namespace().WithGenerateName("openshift-cluster-api-").Build()
+		Expect(cl.Create(ctx, capiNamespace)).To(Succeed())
+
+		co = &configv1.ClusterOperator{ObjectMeta: metav1.ObjectMeta{Name: controllers.ClusterOperatorName}}
+		Expect(cl.Create(ctx, co)).To(Succeed())
+
+		reconciler = &UpgradeGuardReconciler{
+			ClusterOperatorStatusClient: operatorstatus.ClusterOperatorStatusClient{
+				Client:   cl,
+				Recorder: record.NewFakeRecorder(32),
+			},
+			NamespacePairs: []configv1alpha1.NamespacePair{{
+				MAPINamespace: mapiNamespace.GetName(),
+				CAPINamespace: capiNamespace.GetName(),
+			}},
+		}
+
+		mapiMachine = machinev1resourcebuilder.Machine().
+			WithNamespace(mapiNamespace.GetName()).
+			WithName(machineName).
+			WithProviderSpecBuilder(machinev1resourcebuilder.AWSProviderSpec()).
+			Build()
+
+		capiMachine = capiv1resourcebuilder.Machine().
+			WithNamespace(capiNamespace.GetName()).
+			WithName(machineName).
+			WithClusterName("cluster-foo").
+			WithAnnotations(map[string]string{capiv1beta1.PausedAnnotation: ""}).
+			WithInfrastructureRef(corev1.ObjectReference{
+				Kind: "AWSMachine",
+				Name: machineName,
+			}).
+			Build()
+	})
+
+	AfterEach(func() {
+		Expect(test.CleanupAndWait(ctx, cl, co, mapiMachine, capiMachine)).To(Succeed())
+	})
+
+	It("should keep upgrades unblocked without migrations", func() {
+		reconcileUpgradeGuard()
+
+		Expect(upgradeable()).To(BeNil())
+	})
+
+	It("should block upgrades while a machine is migrating", func() {
+		Expect(cl.Create(ctx, mapiMachine)).To(Succeed())
+		setAuthority(machinev1beta1.MachineAuthorityMigrating)
+
+		reconcileUpgradeGuard()
+
+		Expect(upgradeable()).To(SatisfyAll(
+			HaveField("Status", Equal(configv1.ConditionFalse)),
+			HaveField("Reason", Equal(operatorstatus.ReasonMigrationInProgress)),
+			HaveField("Message", ContainSubstring("Machine foo is migrating")),
+		))
+
+		By("Completing the migration")
+		setAuthority(machinev1beta1.MachineAuthorityClusterAPI)
+
+		reconcileUpgradeGuard()
+
+		Expect(upgradeable()).To(HaveField("Status", Equal(configv1.ConditionTrue)))
+	})
+
+	It("should block upgrades while a paused mirror has no Machine API authoritative machine", func() {
+		Expect(cl.Create(ctx, capiMachine)).To(Succeed())
+
+		reconcileUpgradeGuard()
+
+		Expect(upgradeable()).To(SatisfyAll(
+			HaveField("Status", Equal(configv1.ConditionFalse)),
+			HaveField("Message", ContainSubstring("Cluster API Machine foo is paused without a Machine API authoritative Machine")),
+		))
+	})
+
+	It("should not block upgrades for a paused mirror of a Machine API authoritative machine", func() {
+		Expect(cl.Create(ctx, mapiMachine)).To(Succeed())
+		setAuthority(machinev1beta1.MachineAuthorityMachineAPI)
+		Expect(cl.Create(ctx, capiMachine)).To(Succeed())
+
+		reconcileUpgradeGuard()
+
+		Expect(upgradeable()).To(BeNil())
+	})
+
+	It("should block upgrades while a machine of an additional namespace pair is migrating", func() {
+		additionalMAPINamespace := corev1resourcebuilder.Namespace().WithGenerateName("openshift-machine-api-").Build()
+		Expect(cl.Create(ctx, additionalMAPINamespace)).To(Succeed())
+
+		additionalCAPINamespace := corev1resourcebuilder.Namespace().WithGenerateName("openshift-cluster-api-").Build()
+		Expect(cl.Create(ctx, additionalCAPINamespace)).To(Succeed())
+
+		reconciler.NamespacePairs = append(reconciler.NamespacePairs, configv1alpha1.NamespacePair{
+			MAPINamespace: additionalMAPINamespace.GetName(),
+			CAPINamespace: additionalCAPINamespace.GetName(),
+		})
+
+		mapiMachine.SetNamespace(additionalMAPINamespace.GetName())
+		Expect(cl.Create(ctx, mapiMachine)).To(Succeed())
+		setAuthority(machinev1beta1.MachineAuthorityMigrating)
+
+		reconcileUpgradeGuard()
+
+		Expect(upgradeable()).To(SatisfyAll(
+			HaveField("Status", Equal(configv1.ConditionFalse)),
+			HaveField("Message", ContainSubstring(fmt.Sprintf("Machine %s/foo is migrating", additionalMAPINamespace.GetName()))),
+		))
+	})
+
+	It("should not unblock upgrades blocked by the operator being degraded", func() {
+		co.Status.Conditions = []configv1.ClusterOperatorStatusCondition{
+			operatorstatus.NewClusterOperatorStatusCondition(configv1.OperatorUpgradeable, configv1.ConditionFalse, operatorstatus.ReasonAsExpected, ""),
+		}
+		Expect(cl.Status().Update(ctx, co)).To(Succeed())
+
+		reconcileUpgradeGuard()
+
+		Expect(upgradeable()).To(HaveField("Status", Equal(configv1.ConditionFalse)))
+	})
+})
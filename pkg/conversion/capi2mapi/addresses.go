@@ -0,0 +1,53 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capi2mapi
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	capiv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// capiToMAPIAddressTypes maps the CAPI Machine address types to the MAPI ones, which are the Node address types.
+var capiToMAPIAddressTypes = map[capiv1.MachineAddressType]corev1.NodeAddressType{
+	capiv1.MachineHostName:    corev1.NodeHostName,
+	capiv1.MachineExternalIP:  corev1.NodeExternalIP,
+	capiv1.MachineInternalIP:  corev1.NodeInternalIP,
+	capiv1.MachineExternalDNS: corev1.NodeExternalDNS,
+	capiv1.MachineInternalDNS: corev1.NodeInternalDNS,
+}
+
+// convertCAPIMachineAddressesToMAPI translates the CAPI Machine addresses to MAPI Machine addresses.
+// Every address is kept, in the same order, so that the IPv4 and IPv6 addresses of a dual-stack machine keep the
+// order the provider reported them in, the first address of a type being the primary one. The address types without
+// a MAPI equivalent are kept as is.
+func convertCAPIMachineAddressesToMAPI(capiAddresses capiv1.MachineAddresses) []corev1.NodeAddress {
+	if len(capiAddresses) == 0 {
+		return nil
+	}
+
+	mapiAddresses := make([]corev1.NodeAddress, 0, len(capiAddresses))
+
+	for _, capiAddress := range capiAddresses {
+		addressType, ok := capiToMAPIAddressTypes[capiAddress.Type]
+		if !ok {
+			addressType = corev1.NodeAddressType(capiAddress.Type)
+		}
+
+		mapiAddresses = append(mapiAddresses, corev1.NodeAddress{Type: addressType, Address: capiAddress.Address})
+	}
+
+	return mapiAddresses
+}
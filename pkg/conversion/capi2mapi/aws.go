@@ -21,6 +21,8 @@ import (
 	"strings"
 
 	mapiv1 "github.com/openshift/api/machine/v1beta1"
+	conversionutil "github.com/openshift/cluster-capi-operator/pkg/conversion/util"
+	"github.com/openshift/cluster-capi-operator/pkg/errorsx"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -99,6 +101,11 @@ func (m machineAndAWSMachineAndAWSCluster) toProviderSpec() (*mapiv1.AWSMachineP
 		errors = append(errors, err)
 	}
 
+	mapaPlacementGroupPartition, err := convertAWSPlacementGroupPartitionToMAPI(fldPath.Child("placementGroupPartition"), m.awsMachine.Spec.PlacementGroupName, m.awsMachine.Spec.PlacementGroupPartition)
+	if err != nil {
+		errors = append(errors, err)
+	}
+
 	mapiAWSMetadataOptions, warn, errs := convertAWSMetadataOptionsToMAPI(fldPath.Child("instanceMetadataOptions"), m.awsMachine.Spec.InstanceMetadataOptions)
 	if errs != nil {
 		errors = append(errors, errs...)
@@ -106,6 +113,11 @@ func (m machineAndAWSMachineAndAWSCluster) toProviderSpec() (*mapiv1.AWSMachineP
 
 	warnings = append(warnings, warn...)
 
+	mapaNetworkInterfaceType, err := convertAWSNetworkInterfaceTypeToMAPI(field.NewPath("metadata", "annotations"), m.machine.Annotations)
+	if err != nil {
+		errors = append(errors, err)
+	}
+
 	mapaProviderConfig := mapiv1.AWSMachineProviderConfig{
 		TypeMeta: metav1.TypeMeta{
 			Kind: "AWSMachineProviderConfig",
@@ -130,7 +142,7 @@ func (m machineAndAWSMachineAndAWSCluster) toProviderSpec() (*mapiv1.AWSMachineP
 		KeyName: m.awsMachine.Spec.SSHKeyName,
 		// DeviceIndex - OCPCLOUD-2707: Value must always be zero. No other values are valid in MAPA even though the value is configurable.
 		PublicIP:             m.awsMachine.Spec.PublicIP,
-		NetworkInterfaceType: mapiv1.AWSENANetworkInterfaceType,                                          // TODO(OCPCLOUD-2708) This is the default value for MAPA, but other values are not configurable in CAPA.
+		NetworkInterfaceType: mapaNetworkInterfaceType,                                                   // TODO(OCPCLOUD-2708) Carried by an annotation, as the network interface type is not configurable in CAPA.
 		SecurityGroups:       convertAWSSecurityGroupstoMAPI(m.awsMachine.Spec.AdditionalSecurityGroups), // OCPCLOUD-2712: We need to ensure that this is the correct way to convert the security groups.
 		Subnet:               convertAWSResourceReferenceToMAPI(ptr.Deref(m.awsMachine.Spec.Subnet, capav1.AWSResourceReference{})),
 		Placement: mapiv1.Placement{
@@ -143,7 +155,7 @@ func (m machineAndAWSMachineAndAWSCluster) toProviderSpec() (*mapiv1.AWSMachineP
 		SpotMarketOptions:       convertAWSSpotMarketOptionsToMAPI(m.awsMachine.Spec.SpotMarketOptions),
 		MetadataServiceOptions:  mapiAWSMetadataOptions,
 		PlacementGroupName:      m.awsMachine.Spec.PlacementGroupName,
-		PlacementGroupPartition: convertAWSPlacementGroupPartition(m.awsMachine.Spec.PlacementGroupPartition),
+		PlacementGroupPartition: mapaPlacementGroupPartition,
 		CapacityReservationID:   ptr.Deref(m.awsMachine.Spec.CapacityReservationID, ""),
 	}
 
@@ -200,8 +212,11 @@ func (m machineAndAWSMachineAndAWSCluster) ToMachine() (*mapiv1.Machine, []strin
 
 	mapiMachine.Spec.ProviderSpec.Value = awsRawExt
 
+	// The network interface type has been restored to the provider spec above.
+	delete(mapiMachine.Annotations, conversionutil.AWSNetworkInterfaceTypeAnnotation)
+
 	if len(errors) > 0 {
-		return nil, warnings, errors.ToAggregate()
+		return nil, warnings, errorsx.UnsupportedFields(errors.ToAggregate())
 	}
 
 	return mapiMachine, warnings, nil
@@ -238,10 +253,10 @@ func (m machineSetAndAWSMachineTemplateAndAWSCluster) ToMachineSet() (*mapiv1.Ma
 
 	// Copy the labels and annotations from the Machine to the template.
 	mapiMachineSet.Spec.Template.ObjectMeta.Annotations = mapaMachine.ObjectMeta.Annotations
-	mapiMachineSet.Spec.Template.ObjectMeta.Labels = mapaMachine.ObjectMeta.Labels
+	mapiMachineSet.Spec.Template.ObjectMeta.Labels = mapiMachineSetTemplateLabels(m.machineSet, mapaMachine.ObjectMeta.Labels)
 
 	if len(errors) > 0 {
-		return nil, warnings, utilerrors.NewAggregate(errors)
+		return nil, warnings, errorsx.UnsupportedFields(utilerrors.NewAggregate(errors))
 	}
 
 	return mapiMachineSet, warnings, nil
@@ -301,6 +316,22 @@ func convertAWSMetadataOptionsToMAPI(fldPath *field.Path, capiMetadataOpts *capa
 	return metadataOpts, warnings, nil
 }
 
+// convertAWSNetworkInterfaceTypeToMAPI returns the MAPI network interface type carried by the CAPI Machine annotations,
+// defaulting to ENA, the MAPA default, when the annotation is not set.
+func convertAWSNetworkInterfaceTypeToMAPI(fldPath *field.Path, capiAnnotations map[string]string) (mapiv1.AWSNetworkInterfaceType, *field.Error) {
+	value, ok := capiAnnotations[conversionutil.AWSNetworkInterfaceTypeAnnotation]
+	if !ok {
+		return mapiv1.AWSENANetworkInterfaceType, nil
+	}
+
+	switch networkInterfaceType := mapiv1.AWSNetworkInterfaceType(value); networkInterfaceType {
+	case mapiv1.AWSENANetworkInterfaceType, mapiv1.AWSEFANetworkInterfaceType:
+		return networkInterfaceType, nil
+	default:
+		return "", field.Invalid(fldPath.Key(conversionutil.AWSNetworkInterfaceTypeAnnotation), value, "network interface type must be one of ENA or EFA, unsupported value")
+	}
+}
+
 func convertAWSResourceReferenceToMAPI(capiReference capav1.AWSResourceReference) mapiv1.AWSResourceReference {
 	filters := convertAWSFiltersToMAPI(capiReference.Filters)
 
@@ -422,13 +453,24 @@ func convertKMSKeyToMAPI(kmsKey string) mapiv1.AWSResourceReference {
 	}
 }
 
-func convertAWSPlacementGroupPartition(in int64) *int32 {
-	if in == 0 {
-		return nil
+// convertAWSPlacementGroupPartitionToMAPI converts the CAPA placement group partition, which is only valid
+// within a partition placement group, to its MAPI equivalent.
+func convertAWSPlacementGroupPartitionToMAPI(fldPath *field.Path, placementGroupName string, partition int64) (*int32, *field.Error) {
+	if partition == 0 {
+		return nil, nil
 	}
-	// We know the value is between 0 and 7 based on API validation. Ignore gosec.
-	//nolint:gosec
-	return ptr.To(int32(in))
+
+	if placementGroupName == "" {
+		return nil, field.Invalid(fldPath, partition, "placementGroupPartition requires placementGroupName to be set")
+	}
+
+	if partition < conversionutil.AWSMinPlacementGroupPartition || partition > conversionutil.AWSMaxPlacementGroupPartition {
+		return nil, field.Invalid(fldPath, partition, fmt.Sprintf("placementGroupPartition must be between %d and %d",
+			conversionutil.AWSMinPlacementGroupPartition, conversionutil.AWSMaxPlacementGroupPartition))
+	}
+
+	//nolint:gosec // The partition has been checked to be within the range of an int32 above.
+	return ptr.To(int32(partition)), nil
 }
 
 // handleUnsupportedAWSMachineFields returns an error for every present field in the AWSMachineSpec that
@@ -16,6 +16,7 @@ limitations under the License.
 package capi2mapi_test
 
 import (
+	"fmt"
 	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -29,6 +30,7 @@ import (
 	conversiontest "github.com/openshift/cluster-capi-operator/pkg/conversion/test/fuzz"
 
 	runtimeserializer "k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/utils/ptr"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -154,6 +156,13 @@ func awsMachineFuzzerFuncs(codecs runtimeserializer.CodecFactory) []interface{}
 
 			fuzzAWSMachineSpecTenancy(&spec.Tenancy, c)
 
+			// The partition is only valid within a placement group, where it is between 1 and 7, or unset.
+			if spec.PlacementGroupName == "" {
+				spec.PlacementGroupPartition = 0
+			} else {
+				spec.PlacementGroupPartition = c.Int63n(8)
+			}
+
 			// Fields not required for our use case can be ignored.
 			spec.ImageLookupFormat = ""
 			spec.ImageLookupOrg = ""
@@ -167,6 +176,13 @@ func awsMachineFuzzerFuncs(codecs runtimeserializer.CodecFactory) []interface{}
 			// TODO(OCPCLOUD-2712): Security group overrides still need investigation.
 			spec.SecurityGroupOverrides = nil
 		},
+		func(smo *capav1.SpotMarketOptions, c fuzz.Continue) {
+			// The max price is either unset or a decimal number, which the conversion back from MAPI validates.
+			smo.MaxPrice = nil
+			if c.RandBool() {
+				smo.MaxPrice = ptr.To(fmt.Sprintf("%d.%d", c.Intn(10), c.Intn(1000)))
+			}
+		},
 		func(m *capav1.AWSMachine, c fuzz.Continue) {
 			c.FuzzNoCustom(m)
 
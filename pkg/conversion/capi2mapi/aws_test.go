@@ -16,13 +16,18 @@ limitations under the License.
 package capi2mapi
 
 import (
+	"encoding/json"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	mapiv1 "github.com/openshift/api/machine/v1beta1"
 	capibuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/core/v1beta1"
 	capabuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/infrastructure/v1beta2"
 	"github.com/openshift/cluster-capi-operator/pkg/conversion/test/matchers"
+	conversionutil "github.com/openshift/cluster-capi-operator/pkg/conversion/util"
 	"k8s.io/utils/ptr"
 	capav1 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
+	capiv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
 var _ = Describe("capi2mapi AWS conversion", func() {
@@ -230,6 +235,45 @@ var _ = Describe("capi2mapi AWS conversion", func() {
 				"spec.instanceMetadataOptions.instanceMetadataTags: Invalid value: \"enabled\": instanceMetadataTags values other than \"disabled\" are not supported"},
 			expectedWarnings: []string{},
 		}),
+		Entry("With placement group partition without placement group name", awsCAPI2MAPIMachineConversionInput{
+			awsClusterBuilder: awsCAPIAWSClusterBase,
+			awsMachineBuilder: awsCAPIAWSMachineBase.WithPlacementGroupPartition(2),
+			machineBuilder:    awsCAPIMachineBase,
+			expectedErrors:    []string{"spec.placementGroupPartition: Invalid value: 2: placementGroupPartition requires placementGroupName to be set"},
+			expectedWarnings:  []string{},
+		}),
+		Entry("With placement group partition out of range", awsCAPI2MAPIMachineConversionInput{
+			awsClusterBuilder: awsCAPIAWSClusterBase,
+			awsMachineBuilder: awsCAPIAWSMachineBase.WithPlacementGroupName("pg").WithPlacementGroupPartition(8),
+			machineBuilder:    awsCAPIMachineBase,
+			expectedErrors:    []string{"spec.placementGroupPartition: Invalid value: 8: placementGroupPartition must be between 1 and 7"},
+			expectedWarnings:  []string{},
+		}),
+		Entry("With placement group and dedicated host tenancy", awsCAPI2MAPIMachineConversionInput{
+			awsClusterBuilder: awsCAPIAWSClusterBase,
+			awsMachineBuilder: awsCAPIAWSMachineBase.WithPlacementGroupName("pg").WithPlacementGroupPartition(3).WithTenancy("host"),
+			machineBuilder:    awsCAPIMachineBase,
+			expectedErrors:    []string{},
+			expectedWarnings:  []string{},
+		}),
+		Entry("With spot market options and a capacity reservation", awsCAPI2MAPIMachineConversionInput{
+			awsClusterBuilder: awsCAPIAWSClusterBase,
+			awsMachineBuilder: awsCAPIAWSMachineBase.
+				WithSpotMarketOptions(&capav1.SpotMarketOptions{MaxPrice: ptr.To("0.25")}).
+				WithCapacityReservationID(ptr.To("cr-0123456789abcdef0")),
+			machineBuilder:   awsCAPIMachineBase,
+			expectedErrors:   []string{},
+			expectedWarnings: []string{},
+		}),
+		Entry("With an unsupported network interface type annotation", awsCAPI2MAPIMachineConversionInput{
+			awsClusterBuilder: awsCAPIAWSClusterBase,
+			awsMachineBuilder: awsCAPIAWSMachineBase,
+			machineBuilder:    awsCAPIMachineBase.WithAnnotations(map[string]string{conversionutil.AWSNetworkInterfaceTypeAnnotation: "SRD"}),
+			expectedErrors: []string{
+				"metadata.annotations[cluster-api.openshift.io/aws-network-interface-type]: Invalid value: \"SRD\": network interface type must be one of ENA or EFA, unsupported value",
+			},
+			expectedWarnings: []string{},
+		}),
 	)
 
 	var _ = DescribeTable("capi2mapi AWS convert CAPI MachineSet/InfraMachineTemplate/InfraCluster to MAPI MachineSet",
@@ -254,4 +298,23 @@ var _ = Describe("capi2mapi AWS conversion", func() {
 			expectedWarnings:          []string{},
 		}),
 	)
+
+	It("should restore the EFA network interface type from the machine set template annotation", func() {
+		mapiMachineSet, warns, err := FromMachineSetAndAWSMachineTemplateAndAWSCluster(
+			capibuilder.MachineSet().WithTemplate(capiv1.MachineTemplateSpec{
+				ObjectMeta: capiv1.ObjectMeta{
+					Annotations: map[string]string{conversionutil.AWSNetworkInterfaceTypeAnnotation: "EFA"},
+				},
+			}).Build(),
+			capabuilder.AWSMachineTemplate().Build(),
+			awsCAPIAWSClusterBase.Build(),
+		).ToMachineSet()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(warns).To(BeEmpty())
+		Expect(mapiMachineSet.Spec.Template.Annotations).ToNot(HaveKey(conversionutil.AWSNetworkInterfaceTypeAnnotation))
+
+		providerSpec := &mapiv1.AWSMachineProviderConfig{}
+		Expect(json.Unmarshal(mapiMachineSet.Spec.Template.Spec.ProviderSpec.Value.Raw, providerSpec)).To(Succeed())
+		Expect(providerSpec.NetworkInterfaceType).To(Equal(mapiv1.AWSEFANetworkInterfaceType))
+	})
 })
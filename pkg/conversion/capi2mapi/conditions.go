@@ -0,0 +1,92 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capi2mapi
+
+import (
+	mapiv1 "github.com/openshift/api/machine/v1beta1"
+
+	capiv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+const (
+	// mapiReadyCondition carries the CAPI Ready condition, MAPI has no equivalent as it reports readiness through the phase.
+	mapiReadyCondition mapiv1.ConditionType = "Ready"
+	// mapiNodeHealthyCondition carries the CAPI NodeHealthy condition, MAPI has no equivalent as it leaves it to the
+	// MachineHealthChecks.
+	mapiNodeHealthyCondition mapiv1.ConditionType = "NodeHealthy"
+)
+
+// capiToMAPIMachineConditionTypes maps the CAPI Machine conditions translated to MAPI to their MAPI counterpart.
+// The other CAPI conditions describe CAPI internals, such as the bootstrap provider, and are not translated.
+var capiToMAPIMachineConditionTypes = map[capiv1.ConditionType]mapiv1.ConditionType{
+	capiv1.ReadyCondition:               mapiReadyCondition,
+	capiv1.InfrastructureReadyCondition: mapiv1.InstanceExistsCondition,
+	capiv1.MachineNodeHealthyCondition:  mapiNodeHealthyCondition,
+	capiv1.DrainingSucceededCondition:   mapiv1.MachineDrained,
+}
+
+// capiToMAPIInstanceExistsReasons maps the reasons of the CAPI InfrastructureReady condition to the reasons
+// of the MAPI InstanceExists condition, for those that have an equivalent.
+var capiToMAPIInstanceExistsReasons = map[string]string{
+	capiv1.WaitingForInfrastructureFallbackReason: mapiv1.InstanceNotCreatedReason,
+	capiv1.DeletedReason:                          mapiv1.InstanceMissingReason,
+}
+
+// convertCAPIMachineConditionsToMAPI translates the CAPI Machine conditions to MAPI Machine conditions,
+// so that the MAPI Machine still reflects the state of the machine when CAPI is authoritative.
+func convertCAPIMachineConditionsToMAPI(capiConditions capiv1.Conditions) []mapiv1.Condition {
+	var mapiConditions []mapiv1.Condition
+
+	for _, capiCondition := range capiConditions {
+		conditionType, ok := capiToMAPIMachineConditionTypes[capiCondition.Type]
+		if !ok {
+			continue
+		}
+
+		reason := capiCondition.Reason
+		if conditionType == mapiv1.InstanceExistsCondition {
+			if mapiReason, ok := capiToMAPIInstanceExistsReasons[reason]; ok {
+				reason = mapiReason
+			}
+		}
+
+		mapiConditions = append(mapiConditions, mapiv1.Condition{
+			Type:               conditionType,
+			Status:             capiCondition.Status,
+			Severity:           convertCAPIConditionSeverityToMAPI(capiCondition.Severity),
+			LastTransitionTime: capiCondition.LastTransitionTime,
+			Reason:             reason,
+			Message:            capiCondition.Message,
+		})
+	}
+
+	return mapiConditions
+}
+
+// convertCAPIConditionSeverityToMAPI translates a CAPI condition severity to the MAPI one.
+// Unknown severities are dropped, as MAPI only accepts the ones it defines.
+func convertCAPIConditionSeverityToMAPI(severity capiv1.ConditionSeverity) mapiv1.ConditionSeverity {
+	switch severity {
+	case capiv1.ConditionSeverityError:
+		return mapiv1.ConditionSeverityError
+	case capiv1.ConditionSeverityWarning:
+		return mapiv1.ConditionSeverityWarning
+	case capiv1.ConditionSeverityInfo:
+		return mapiv1.ConditionSeverityInfo
+	default:
+		return mapiv1.ConditionSeverityNone
+	}
+}
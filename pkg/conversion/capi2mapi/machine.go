@@ -42,34 +42,50 @@ func fromCAPIMachineToMAPIMachine(capiMachine *capiv1.Machine) (*mapiv1.Machine,
 
 	mapiMachine := &mapiv1.Machine{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        capiMachine.Name,
-			Namespace:   mapiNamespace,
-			Labels:      capiMachine.Labels,
+			Name:      capiMachine.Name,
+			Namespace: mapiNamespace,
+			// The machine.openshift.io labels the CAPI Machine lacks are back-filled, so that MAPI tooling keeps matching it.
+			Labels:      conversionutil.BackfillMAPIMachineLabels(capiMachine.Labels, capiMachine.Spec.ClusterName, capiMachine.Labels[capiv1.MachineSetNameLabel]),
 			Annotations: capiMachine.Annotations,
 			// OwnerReferences: TODO(OCPCLOUD-2716): These need to be converted so that any MachineSet owning a Machine is represented with the correct owner reference between the two APIs.
 		},
 		Spec: mapiv1.MachineSpec{
 			ObjectMeta: mapiv1.ObjectMeta{
-				// TODO(OCPCLOUD-2680): Fix CAPI metadata support to mirror MAPI.
-				// Labels: We only expect labels and annotations to be present, but, they have nowhere to go on a CAPI Machine at present.
-				// Annotations: We only expect labels and annotations to be present, but, they have nowhere to go on a CAPI Machine at present.
+				// Labels: populated below from the CAPI managed labels and the node labels annotation.
+				// Annotations: populated below from the node annotations annotation.
 			},
 			ProviderID:     capiMachine.Spec.ProviderID,
 			LifecycleHooks: getMAPILifecycleHooks(capiMachine),
-			// Taints: // TODO: lossy: Not Present on CAPI Machines, only done via BootstrapProvider?
+			// Taints: populated below from the node taints annotation.
 
 			// ProviderSpec: this MUST NOT be populated here. It will get populated later by higher level fuctions.
 		},
 	}
 
 	if len(capiMachine.OwnerReferences) > 0 {
-		// TODO(OCPCLOUD-2716): We should support converting CAPI MachineSet ORs to MAPI MachineSet ORs. NB working out the UID will be hard.
+		// TODO(OCPCLOUD-2716): Convert them with ConvertCAPIMachineOwnerReferencesToMAPI once the Machine sync controller resolves the
+		// MachineSets owning the Machine, the UID of the MAPI MachineSet is not known here.
 		errs = append(errs, field.Invalid(field.NewPath("metadata", "ownerReferences"), capiMachine.OwnerReferences, "ownerReferences are not supported"))
 	}
 
+	// The lifecycle hook annotations have been removed above, so the remaining annotations can now be translated.
+	mapiMachine.Annotations = convertCAPIDeleteMachineAnnotationToMAPI(mapiMachine.Annotations)
+
 	// Make sure the machine has a label map.
 	mapiMachine.Spec.ObjectMeta.Labels = map[string]string{}
-	setCAPIManagedNodeLabelsToMAPINodeLabels(capiMachine.Labels, mapiMachine.Spec.ObjectMeta.Labels)
+	setCAPIManagedNodeLabelsToMAPINodeLabels(mapiMachine.Labels, mapiMachine.Spec.ObjectMeta.Labels)
+
+	// The node metadata that CAPI Machines cannot represent is carried by annotations.
+	errs = append(errs, setMAPINodeMetadataFromAnnotations(field.NewPath("metadata", "annotations"), mapiMachine)...)
+
+	// The conditions are carried over so that the MAPI Machine reflects the state of the machine when CAPI is authoritative.
+	mapiMachine.Status.Conditions = convertCAPIMachineConditionsToMAPI(capiMachine.Status.Conditions)
+	mapiMachine.Status.Addresses = convertCAPIMachineAddressesToMAPI(capiMachine.Status.Addresses)
+	mapiMachine.Status.Phase = conversionutil.ConvertCAPIMachinePhaseToMAPI(capiMachine.Status.Phase)
+
+	// The terminal failure of a Failed machine explains why it failed.
+	mapiMachine.Status.ErrorReason, mapiMachine.Status.ErrorMessage = conversionutil.ConvertCAPIMachineFailureToMAPI(
+		capiMachine.Status.FailureReason, capiMachine.Status.FailureMessage)
 
 	// Unusued fields - Below this line are fields not used from the CAPI Machine.
 
@@ -107,11 +123,11 @@ func fromCAPIMachineToMAPIMachine(capiMachine *capiv1.Machine) (*mapiv1.Machine,
 }
 
 func setCAPIManagedNodeLabelsToMAPINodeLabels(capiNodeLabels map[string]string, mapiNodeLabels map[string]string) {
-	// TODO(OCPCLOUD-2680): Not all the labels on the CAPI Machine are propagated down to the corresponding CAPI Node, only the "CAPI Managed ones" are.
+	// Not all the labels on the CAPI Machine are propagated down to the corresponding CAPI Node, only the "CAPI Managed ones" are.
 	// These are those prefix by "node-role.kubernetes.io" or in the domains of "node-restriction.kubernetes.io" and "node.cluster.x-k8s.io".
 	// See: https://github.com/kubernetes-sigs/cluster-api/pull/7173
 	// and: https://github.com/fabriziopandini/cluster-api/blob/main/docs/proposals/20220927-label-sync-between-machine-and-nodes.md
-	// We should only copy these into the labels to be propagated to the Node.
+	// We should only copy these into the labels to be propagated to the Node, the others are carried by the node labels annotation.
 	if mapiNodeLabels == nil {
 		mapiNodeLabels = map[string]string{}
 	}
@@ -131,6 +147,63 @@ const (
 	capiPreTerminateAnnotationPrefix = capiv1.PreTerminateDeleteHookAnnotationPrefix + "/"
 )
 
+// setMAPINodeMetadataFromAnnotations restores the MAPI node labels, annotations and taints from the node metadata annotations
+// set by the MAPI to CAPI conversion. The node metadata annotations are removed from the MAPI Machine annotations.
+func setMAPINodeMetadataFromAnnotations(fldPath *field.Path, mapiMachine *mapiv1.Machine) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if value, ok := mapiMachine.Annotations[conversionutil.NodeLabelsAnnotation]; ok {
+		labels := map[string]string{}
+		if err := json.Unmarshal([]byte(value), &labels); err != nil {
+			errs = append(errs, field.Invalid(fldPath.Key(conversionutil.NodeLabelsAnnotation), value, fmt.Sprintf("failed to decode node labels: %v", err)))
+		}
+
+		for k, v := range labels {
+			mapiMachine.Spec.ObjectMeta.Labels[k] = v
+		}
+
+		delete(mapiMachine.Annotations, conversionutil.NodeLabelsAnnotation)
+	}
+
+	if value, ok := mapiMachine.Annotations[conversionutil.NodeAnnotationsAnnotation]; ok {
+		if err := json.Unmarshal([]byte(value), &mapiMachine.Spec.ObjectMeta.Annotations); err != nil {
+			errs = append(errs, field.Invalid(fldPath.Key(conversionutil.NodeAnnotationsAnnotation), value, fmt.Sprintf("failed to decode node annotations: %v", err)))
+		}
+
+		delete(mapiMachine.Annotations, conversionutil.NodeAnnotationsAnnotation)
+	}
+
+	if value, ok := mapiMachine.Annotations[conversionutil.NodeTaintsAnnotation]; ok {
+		if err := json.Unmarshal([]byte(value), &mapiMachine.Spec.Taints); err != nil {
+			errs = append(errs, field.Invalid(fldPath.Key(conversionutil.NodeTaintsAnnotation), value, fmt.Sprintf("failed to decode node taints: %v", err)))
+		}
+
+		delete(mapiMachine.Annotations, conversionutil.NodeTaintsAnnotation)
+	}
+
+	return errs
+}
+
+// convertCAPIDeleteMachineAnnotationToMAPI returns a copy of the CAPI Machine annotations where the CAPI delete machine annotation,
+// used to prioritise a Machine for deletion on MachineSet scale down, is replaced by the MAPI delete machine annotation.
+func convertCAPIDeleteMachineAnnotationToMAPI(capiAnnotations map[string]string) map[string]string {
+	if capiAnnotations == nil {
+		return nil
+	}
+
+	mapiAnnotations := make(map[string]string, len(capiAnnotations))
+
+	for k, v := range capiAnnotations {
+		if k == capiv1.DeleteMachineAnnotation {
+			mapiAnnotations[conversionutil.MAPIDeleteMachineAnnotation] = v
+		} else {
+			mapiAnnotations[k] = v
+		}
+	}
+
+	return mapiAnnotations
+}
+
 // getMAPILifecycleHooks extracts the lifecycle hooks from the CAPI Machine annotations.
 func getMAPILifecycleHooks(capiMachine *capiv1.Machine) mapiv1.LifecycleHooks {
 	hooks := mapiv1.LifecycleHooks{}
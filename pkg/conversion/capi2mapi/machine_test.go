@@ -20,12 +20,16 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	mapiv1 "github.com/openshift/api/machine/v1beta1"
 	capibuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/core/v1beta1"
 	capabuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/infrastructure/v1beta2"
 	"github.com/openshift/cluster-capi-operator/pkg/conversion/test/matchers"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
+	capiv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	capierrors "sigs.k8s.io/cluster-api/errors"
 )
 
 var _ = Describe("capi2mapi Machine conversion", func() {
@@ -78,5 +82,227 @@ var _ = Describe("capi2mapi Machine conversion", func() {
 			expectedErrors:   []string{"spec.nodeDeletionTimeout: Invalid value: v1.Duration{Duration:1000000000}: nodeDeletionTimeout is not supported"},
 			expectedWarnings: []string{},
 		}),
+		Entry("With malformed node taints annotation", capi2MAPIMachineConversionInput{
+			machineBuilder:   capiMachineBase.WithAnnotations(map[string]string{"cluster-api.openshift.io/node-taints": "{"}),
+			expectedErrors:   []string{"metadata.annotations[cluster-api.openshift.io/node-taints]: Invalid value: \"{\": failed to decode node taints"},
+			expectedWarnings: []string{},
+		}),
+	)
+
+	It("should convert the CAPI delete machine annotation to the MAPI one", func() {
+		mapiMachine, _, err := FromMachineAndAWSMachineAndAWSCluster(
+			capiMachineBase.WithAnnotations(map[string]string{
+				"cluster.x-k8s.io/delete-machine": "yes",
+				"foo":                             "bar",
+			}).Build(),
+			capabuilder.AWSMachine().Build(),
+			capabuilder.AWSCluster().Build(),
+		).ToMachine()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mapiMachine.Annotations).To(Equal(map[string]string{
+			"machine.openshift.io/delete-machine": "yes",
+			"foo":                                 "bar",
+		}))
+	})
+
+	It("should convert the node metadata annotations to the MAPI node metadata", func() {
+		mapiMachine, _, err := FromMachineAndAWSMachineAndAWSCluster(
+			capiMachineBase.
+				WithLabels(map[string]string{"node-role.kubernetes.io/worker": ""}).
+				WithAnnotations(map[string]string{
+					"cluster-api.openshift.io/node-labels":      `{"custom.domain/label":"value"}`,
+					"cluster-api.openshift.io/node-annotations": `{"foo":"bar"}`,
+					"cluster-api.openshift.io/node-taints":      `[{"key":"key1","value":"value1","effect":"NoSchedule"}]`,
+				}).Build(),
+			capabuilder.AWSMachine().Build(),
+			capabuilder.AWSCluster().Build(),
+		).ToMachine()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mapiMachine.Annotations).To(BeEmpty())
+		Expect(mapiMachine.Spec.ObjectMeta.Labels).To(Equal(map[string]string{
+			"node-role.kubernetes.io/worker": "",
+			"custom.domain/label":            "value",
+		}))
+		Expect(mapiMachine.Spec.ObjectMeta.Annotations).To(Equal(map[string]string{"foo": "bar"}))
+		Expect(mapiMachine.Spec.Taints).To(Equal([]corev1.Taint{{Key: "key1", Value: "value1", Effect: corev1.TaintEffectNoSchedule}}))
+	})
+
+	It("should back-fill the MAPI machine labels from the CAPI Machine", func() {
+		capiMachine := capiMachineBase.
+			WithClusterName("cluster-abc12").
+			WithLabels(map[string]string{
+				"cluster.x-k8s.io/set-name":      "worker-us-east-1a",
+				"node-role.kubernetes.io/worker": "",
+			}).Build()
+
+		mapiMachine, _, err := FromMachineAndAWSMachineAndAWSCluster(
+			capiMachine,
+			capabuilder.AWSMachine().Build(),
+			capabuilder.AWSCluster().Build(),
+		).ToMachine()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mapiMachine.Labels).To(Equal(map[string]string{
+			"cluster.x-k8s.io/set-name":                     "worker-us-east-1a",
+			"machine.openshift.io/cluster-api-cluster":      "cluster-abc12",
+			"machine.openshift.io/cluster-api-machineset":   "worker-us-east-1a",
+			"machine.openshift.io/cluster-api-machine-role": "worker",
+			"machine.openshift.io/cluster-api-machine-type": "worker",
+		}))
+		Expect(capiMachine.Labels).To(HaveKey("node-role.kubernetes.io/worker"), "should leave the CAPI Machine untouched")
+	})
+
+	It("should keep the MAPI machine labels set on the CAPI Machine", func() {
+		mapiMachine, _, err := FromMachineAndAWSMachineAndAWSCluster(
+			capiMachineBase.
+				WithClusterName("cluster-abc12").
+				WithLabels(map[string]string{
+					"machine.openshift.io/cluster-api-cluster":      "cluster-xyz34",
+					"machine.openshift.io/cluster-api-machine-role": "infra",
+					"node-role.kubernetes.io/worker":                "",
+					"node-role.kubernetes.io/infra":                 "",
+				}).Build(),
+			capabuilder.AWSMachine().Build(),
+			capabuilder.AWSCluster().Build(),
+		).ToMachine()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mapiMachine.Labels).To(Equal(map[string]string{
+			"machine.openshift.io/cluster-api-cluster":      "cluster-xyz34",
+			"machine.openshift.io/cluster-api-machine-role": "infra",
+		}), "should not derive a role from several node role labels")
+	})
+
+	It("should convert the CAPI conditions to the analogous MAPI conditions", func() {
+		transitionTime := metav1.NewTime(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+
+		mapiMachine, _, err := FromMachineAndAWSMachineAndAWSCluster(
+			capiMachineBase.WithConditions(capiv1.Conditions{
+				{
+					Type:               capiv1.ReadyCondition,
+					Status:             corev1.ConditionFalse,
+					Severity:           capiv1.ConditionSeverityInfo,
+					LastTransitionTime: transitionTime,
+					Reason:             capiv1.WaitingForInfrastructureFallbackReason,
+					Message:            "1 of 2 completed",
+				},
+				{
+					Type:               capiv1.InfrastructureReadyCondition,
+					Status:             corev1.ConditionFalse,
+					Severity:           capiv1.ConditionSeverityInfo,
+					LastTransitionTime: transitionTime,
+					Reason:             capiv1.WaitingForInfrastructureFallbackReason,
+				},
+				{
+					Type:               capiv1.MachineNodeHealthyCondition,
+					Status:             corev1.ConditionFalse,
+					Severity:           capiv1.ConditionSeverityError,
+					LastTransitionTime: transitionTime,
+					Reason:             capiv1.NodeConditionsFailedReason,
+				},
+				{
+					Type:               capiv1.DrainingSucceededCondition,
+					Status:             corev1.ConditionTrue,
+					LastTransitionTime: transitionTime,
+				},
+				{
+					Type:               capiv1.BootstrapReadyCondition,
+					Status:             corev1.ConditionTrue,
+					LastTransitionTime: transitionTime,
+				},
+			}).Build(),
+			capabuilder.AWSMachine().Build(),
+			capabuilder.AWSCluster().Build(),
+		).ToMachine()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mapiMachine.Status.Conditions).To(Equal([]mapiv1.Condition{
+			{
+				Type:               "Ready",
+				Status:             corev1.ConditionFalse,
+				Severity:           mapiv1.ConditionSeverityInfo,
+				LastTransitionTime: transitionTime,
+				Reason:             capiv1.WaitingForInfrastructureFallbackReason,
+				Message:            "1 of 2 completed",
+			},
+			{
+				Type:               mapiv1.InstanceExistsCondition,
+				Status:             corev1.ConditionFalse,
+				Severity:           mapiv1.ConditionSeverityInfo,
+				LastTransitionTime: transitionTime,
+				Reason:             mapiv1.InstanceNotCreatedReason,
+			},
+			{
+				Type:               "NodeHealthy",
+				Status:             corev1.ConditionFalse,
+				Severity:           mapiv1.ConditionSeverityError,
+				LastTransitionTime: transitionTime,
+				Reason:             capiv1.NodeConditionsFailedReason,
+			},
+			{
+				Type:               mapiv1.MachineDrained,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: transitionTime,
+			},
+		}))
+	})
+
+	It("should convert the phase and the failure of a Failed CAPI Machine", func() {
+		mapiMachine, _, err := FromMachineAndAWSMachineAndAWSCluster(
+			capiMachineBase.
+				WithPhase(capiv1.MachinePhaseFailed).
+				WithFailureReason(ptr.To(capierrors.InsufficientResourcesMachineError)).
+				WithFailureMessage(ptr.To("quota exceeded")).
+				Build(),
+			capabuilder.AWSMachine().Build(),
+			capabuilder.AWSCluster().Build(),
+		).ToMachine()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(mapiMachine.Status.Phase).To(Equal(ptr.To(mapiv1.PhaseFailed)))
+		Expect(mapiMachine.Status.ErrorReason).To(Equal(ptr.To(mapiv1.InsufficientResourcesMachineError)))
+		Expect(mapiMachine.Status.ErrorMessage).To(Equal(ptr.To("quota exceeded")))
+	})
+
+	DescribeTable("should convert the CAPI Machine addresses, keeping their order",
+		func(addresses capiv1.MachineAddresses, expectedAddresses []corev1.NodeAddress) {
+			mapiMachine, _, err := FromMachineAndAWSMachineAndAWSCluster(
+				capiMachineBase.WithAddresses(addresses).Build(),
+				capabuilder.AWSMachine().Build(),
+				capabuilder.AWSCluster().Build(),
+			).ToMachine()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mapiMachine.Status.Addresses).To(Equal(expectedAddresses))
+		},
+		Entry("Without addresses", nil, nil),
+		Entry("With dual-stack addresses, IPv4 first",
+			capiv1.MachineAddresses{
+				{Type: capiv1.MachineInternalIP, Address: "10.0.0.1"},
+				{Type: capiv1.MachineInternalIP, Address: "fd00:10::1"},
+				{Type: capiv1.MachineInternalDNS, Address: "ip-10-0-0-1.ec2.internal"},
+				{Type: capiv1.MachineHostName, Address: "ip-10-0-0-1.ec2.internal"},
+				{Type: capiv1.MachineExternalIP, Address: "2001:db8::1"},
+				{Type: capiv1.MachineExternalDNS, Address: "ec2.compute.amazonaws.com"},
+			},
+			[]corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: corev1.NodeInternalIP, Address: "fd00:10::1"},
+				{Type: corev1.NodeInternalDNS, Address: "ip-10-0-0-1.ec2.internal"},
+				{Type: corev1.NodeHostName, Address: "ip-10-0-0-1.ec2.internal"},
+				{Type: corev1.NodeExternalIP, Address: "2001:db8::1"},
+				{Type: corev1.NodeExternalDNS, Address: "ec2.compute.amazonaws.com"},
+			},
+		),
+		Entry("With dual-stack addresses, IPv6 first",
+			capiv1.MachineAddresses{
+				{Type: capiv1.MachineInternalIP, Address: "fd00:10::1"},
+				{Type: capiv1.MachineInternalIP, Address: "10.0.0.1"},
+			},
+			[]corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "fd00:10::1"},
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+			},
+		),
+		Entry("With an unknown address type",
+			capiv1.MachineAddresses{{Type: "Custom", Address: "10.0.0.1"}},
+			[]corev1.NodeAddress{{Type: "Custom", Address: "10.0.0.1"}},
+		),
 	)
 })
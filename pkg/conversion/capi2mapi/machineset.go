@@ -16,9 +16,12 @@ limitations under the License.
 package capi2mapi
 
 import (
+	"maps"
 	"strings"
 
 	mapiv1 "github.com/openshift/api/machine/v1beta1"
+	conversionutil "github.com/openshift/cluster-capi-operator/pkg/conversion/util"
+	"github.com/openshift/cluster-capi-operator/pkg/errorsx"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -29,28 +32,35 @@ import (
 func fromCAPIMachineSetToMAPIMachineSet(capiMachineSet *capiv1.MachineSet) (*mapiv1.MachineSet, error) {
 	errs := field.ErrorList{}
 
+	annotations, annotationErrs := conversionutil.ConvertCAPIAutoscalerAnnotationsToMAPI(field.NewPath("metadata", "annotations"), capiMachineSet.Annotations)
+	errs = append(errs, annotationErrs...)
+
 	mapiMachineSet := &mapiv1.MachineSet{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        capiMachineSet.Name,
 			Namespace:   capiMachineSet.Namespace,
-			Labels:      capiMachineSet.Labels,
-			Annotations: capiMachineSet.Annotations,
+			Labels:      conversionutil.BackfillMAPIMachineLabels(capiMachineSet.Labels, capiMachineSet.Spec.ClusterName, ""),
+			Annotations: annotations,
 			// OwnerReferences: There shouldn't be any OwnerReferences on a MachineSet.
 		},
 		Spec: mapiv1.MachineSetSpec{
 			Selector:        capiMachineSet.Spec.Selector,
 			Replicas:        capiMachineSet.Spec.Replicas,
 			MinReadySeconds: capiMachineSet.Spec.MinReadySeconds,
-			DeletePolicy:    capiMachineSet.Spec.DeletePolicy,
+			DeletePolicy:    convertCAPIMachineSetDeletePolicyToMAPI(capiMachineSet.Spec.DeletePolicy),
 			Template: mapiv1.MachineTemplateSpec{
 				ObjectMeta: mapiv1.ObjectMeta{
-					Labels:      capiMachineSet.Spec.Template.Labels,
+					Labels:      maps.Clone(capiMachineSet.Spec.Template.Labels),
 					Annotations: capiMachineSet.Spec.Template.Annotations,
 				},
 			},
 		},
 	}
 
+	if capiMachineSet.Spec.DeletePolicy != "" && !conversionutil.IsSupportedMachineSetDeletePolicy(capiMachineSet.Spec.DeletePolicy) {
+		errs = append(errs, field.NotSupported(field.NewPath("spec", "deletePolicy"), capiMachineSet.Spec.DeletePolicy, conversionutil.SupportedMachineSetDeletePolicies()))
+	}
+
 	if len(capiMachineSet.OwnerReferences) > 0 {
 		// TODO(OCPCLOUD-2748): We should prevent ownerreferences on MachineSets until such a time that we need to support them.
 		errs = append(errs, field.Invalid(field.NewPath("metadata", "ownerReferences"), capiMachineSet.OwnerReferences, "ownerReferences are not supported"))
@@ -70,7 +80,7 @@ func fromCAPIMachineSetToMAPIMachineSet(capiMachineSet *capiv1.MachineSet) (*map
 		}
 	}
 
-	setCAPIManagedNodeLabelsToMAPINodeLabels(capiMachineSet.Spec.Template.Labels, mapiMachineSet.Spec.Template.Spec.ObjectMeta.Labels)
+	setCAPIManagedNodeLabelsToMAPINodeLabels(mapiMachineSet.Spec.Template.Labels, mapiMachineSet.Spec.Template.Spec.ObjectMeta.Labels)
 
 	// Unusued fields - Below this line are fields not used from the CAPI Machine.
 
@@ -79,8 +89,26 @@ func fromCAPIMachineSetToMAPIMachineSet(capiMachineSet *capiv1.MachineSet) (*map
 
 	if len(errs) > 0 {
 		// Return the mapiMachine so that the logic continues and collects all possible conversion errors.
-		return mapiMachineSet, errs.ToAggregate()
+		return mapiMachineSet, errorsx.UnsupportedFields(errs.ToAggregate())
 	}
 
 	return mapiMachineSet, nil
 }
+
+// mapiMachineSetTemplateLabels returns the labels of the MAPI MachineSet template, from the labels of the MAPI Machine
+// converted from the CAPI MachineSet template, with the machine set label back-filled so that the Machines the
+// MachineSet creates are matched by the tooling keyed on the MAPI labels.
+func mapiMachineSetTemplateLabels(capiMachineSet *capiv1.MachineSet, mapiMachineLabels map[string]string) map[string]string {
+	return conversionutil.BackfillMAPIMachineLabels(mapiMachineLabels, capiMachineSet.Spec.ClusterName, capiMachineSet.Name)
+}
+
+// convertCAPIMachineSetDeletePolicyToMAPI converts the CAPI MachineSet delete policy to its MAPI equivalent.
+// The policies share the same values in both APIs, but the Random policy, to which the conversion to CAPI defaults an
+// empty MAPI policy, is converted back to an empty policy, so that the MAPI MachineSet round trips.
+func convertCAPIMachineSetDeletePolicyToMAPI(policy string) string {
+	if policy == string(capiv1.RandomMachineSetDeletePolicy) {
+		return ""
+	}
+
+	return policy
+}
@@ -20,9 +20,14 @@ import (
 	. "github.com/onsi/gomega"
 	capibuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/core/v1beta1"
 	capabuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/infrastructure/v1beta2"
+	configbuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/config/v1"
+	machinebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	"github.com/openshift/cluster-capi-operator/pkg/conversion/mapi2capi"
 	"github.com/openshift/cluster-capi-operator/pkg/conversion/test/matchers"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capav1 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
+	capiv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
 var _ = Describe("capi2mapi MachineSet conversion", func() {
@@ -60,5 +65,156 @@ var _ = Describe("capi2mapi MachineSet conversion", func() {
 			expectedErrors:    []string{"metadata.ownerReferences: Invalid value: []v1.OwnerReference{v1.OwnerReference{APIVersion:\"\", Kind:\"\", Name:\"a\", UID:\"\", Controller:(*bool)(nil), BlockOwnerDeletion:(*bool)(nil)}}: ownerReferences are not supported"},
 			expectedWarnings:  []string{},
 		}),
+		Entry("With unsupported DeletePolicy", capi2MAPIMachinesetConversionInput{
+			machineSetBuilder: capiMachineSetBase.WithDeletePolicy("Unknown"),
+			expectedErrors:    []string{"spec.deletePolicy: Unsupported value: \"Unknown\": supported values: \"Random\", \"Newest\", \"Oldest\""},
+			expectedWarnings:  []string{},
+		}),
+		Entry("With an invalid cluster autoscaler memory annotation", capi2MAPIMachinesetConversionInput{
+			machineSetBuilder: capiMachineSetBase.WithAnnotations(map[string]string{"capacity.cluster-autoscaler.kubernetes.io/memory": "lots"}),
+			expectedErrors:    []string{"metadata.annotations[capacity.cluster-autoscaler.kubernetes.io/memory]: Invalid value: \"lots\": failed to parse memory"},
+			expectedWarnings:  []string{},
+		}),
 	)
+
+	DescribeTable("capi2mapi convert CAPI MachineSet cluster autoscaler annotations",
+		func(annotations, expectedAnnotations map[string]string) {
+			capiMachineSet := capiMachineSetBase.WithAnnotations(annotations).Build()
+
+			mapiMachineSet, _, err := FromMachineSetAndAWSMachineTemplateAndAWSCluster(
+				capiMachineSet,
+				capabuilder.AWSMachineTemplate().Build(),
+				capabuilder.AWSCluster().Build(),
+			).ToMachineSet()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mapiMachineSet.Annotations).To(Equal(expectedAnnotations))
+			Expect(capiMachineSet.Annotations).To(Equal(annotations), "should not modify the CAPI MachineSet annotations")
+		},
+		Entry("With no annotations", nil, nil),
+		Entry("With the node group size annotations",
+			map[string]string{
+				"cluster.x-k8s.io/cluster-api-autoscaler-node-group-min-size": "1",
+				"cluster.x-k8s.io/cluster-api-autoscaler-node-group-max-size": "5",
+				"foo": "bar",
+			},
+			map[string]string{
+				"machine.openshift.io/cluster-api-autoscaler-node-group-min-size": "1",
+				"machine.openshift.io/cluster-api-autoscaler-node-group-max-size": "5",
+				"foo": "bar",
+			},
+		),
+		Entry("With the scale from zero annotations",
+			map[string]string{
+				"capacity.cluster-autoscaler.kubernetes.io/cpu":       "4",
+				"capacity.cluster-autoscaler.kubernetes.io/memory":    "16384Mi",
+				"capacity.cluster-autoscaler.kubernetes.io/gpu-count": "1",
+				"capacity.cluster-autoscaler.kubernetes.io/maxPods":   "250",
+			},
+			map[string]string{
+				"machine.openshift.io/vCPU":     "4",
+				"machine.openshift.io/memoryMb": "16384",
+				"machine.openshift.io/GPU":      "1",
+				"machine.openshift.io/maxPods":  "250",
+			},
+		),
+		Entry("With the default GPU type, implied by the MAPI GPU annotation",
+			map[string]string{
+				"capacity.cluster-autoscaler.kubernetes.io/gpu-count": "1",
+				"capacity.cluster-autoscaler.kubernetes.io/gpu-type":  "nvidia.com/gpu",
+			},
+			map[string]string{"machine.openshift.io/GPU": "1"},
+		),
+		Entry("With another GPU type, kept as is",
+			map[string]string{
+				"capacity.cluster-autoscaler.kubernetes.io/gpu-count": "1",
+				"capacity.cluster-autoscaler.kubernetes.io/gpu-type":  "amd.com/gpu",
+			},
+			map[string]string{
+				"machine.openshift.io/GPU":                           "1",
+				"capacity.cluster-autoscaler.kubernetes.io/gpu-type": "amd.com/gpu",
+			},
+		),
+		Entry("With a memory quantity in another unit, rounded up to the next MiB",
+			map[string]string{"capacity.cluster-autoscaler.kubernetes.io/memory": "16G"},
+			map[string]string{"machine.openshift.io/memoryMb": "15259"},
+		),
+	)
+
+	It("should preserve the delete policy", func() {
+		mapiMachineSet, _, err := FromMachineSetAndAWSMachineTemplateAndAWSCluster(
+			capiMachineSetBase.WithDeletePolicy("Oldest").Build(),
+			capabuilder.AWSMachineTemplate().Build(),
+			capabuilder.AWSCluster().Build(),
+		).ToMachineSet()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mapiMachineSet.Spec.DeletePolicy).To(Equal("Oldest"))
+	})
+
+	DescribeTable("capi2mapi convert CAPI MachineSet delete policy",
+		func(deletePolicy, expectedDeletePolicy string) {
+			mapiMachineSet, _, err := FromMachineSetAndAWSMachineTemplateAndAWSCluster(
+				capiMachineSetBase.WithDeletePolicy(deletePolicy).Build(),
+				capabuilder.AWSMachineTemplate().Build(),
+				capabuilder.AWSCluster().Build(),
+			).ToMachineSet()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mapiMachineSet.Spec.DeletePolicy).To(Equal(expectedDeletePolicy))
+		},
+		Entry("With the Random delete policy, converts back to an empty policy", "Random", ""),
+		Entry("With the Newest delete policy", "Newest", "Newest"),
+		Entry("With the Oldest delete policy", "Oldest", "Oldest"),
+	)
+
+	DescribeTable("capi2mapi round trip MAPI MachineSet delete policy",
+		func(deletePolicy string) {
+			capiMachineSet, awsMachineTemplate, _, err := mapi2capi.FromAWSMachineSetAndInfra(
+				machinebuilder.MachineSet().
+					WithProviderSpecBuilder(machinebuilder.AWSProviderSpec().WithLoadBalancers(nil).WithRegion("eu-west-2")).
+					WithDeletePolicy(deletePolicy).
+					Build(),
+				configbuilder.Infrastructure().AsAWS("test", "eu-west-2").Build(),
+			).ToMachineSetAndMachineTemplate()
+			Expect(err).ToNot(HaveOccurred())
+
+			mapiMachineSet, _, err := FromMachineSetAndAWSMachineTemplateAndAWSCluster(
+				capiMachineSet,
+				awsMachineTemplate.(*capav1.AWSMachineTemplate), //nolint:forcetypeassert
+				capabuilder.AWSCluster().Build(),
+			).ToMachineSet()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mapiMachineSet.Spec.DeletePolicy).To(Equal(deletePolicy))
+		},
+		Entry("With an empty delete policy", ""),
+		Entry("With the Newest delete policy", "Newest"),
+		Entry("With the Oldest delete policy", "Oldest"),
+	)
+
+	It("should back-fill the MAPI machine labels of the MachineSet and its template", func() {
+		mapiMachineSet, _, err := FromMachineSetAndAWSMachineTemplateAndAWSCluster(
+			capiMachineSetBase.
+				WithName("worker-us-east-1a").
+				WithClusterName("cluster-abc12").
+				WithTemplate(capiv1.MachineTemplateSpec{
+					ObjectMeta: capiv1.ObjectMeta{
+						Labels: map[string]string{
+							"machine.openshift.io/cluster-api-machine-type": "highmem",
+							"node-role.kubernetes.io/worker":                "",
+						},
+					},
+				}).Build(),
+			capabuilder.AWSMachineTemplate().Build(),
+			capabuilder.AWSCluster().Build(),
+		).ToMachineSet()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mapiMachineSet.Labels).To(Equal(map[string]string{
+			"machine.openshift.io/cluster-api-cluster": "cluster-abc12",
+		}))
+		Expect(mapiMachineSet.Spec.Template.Labels).To(Equal(map[string]string{
+			"machine.openshift.io/cluster-api-cluster":      "cluster-abc12",
+			"machine.openshift.io/cluster-api-machineset":   "worker-us-east-1a",
+			"machine.openshift.io/cluster-api-machine-role": "worker",
+			"machine.openshift.io/cluster-api-machine-type": "highmem",
+		}))
+		Expect(mapiMachineSet.Spec.Template.Spec.ObjectMeta.Labels).To(HaveKey("node-role.kubernetes.io/worker"))
+	})
 })
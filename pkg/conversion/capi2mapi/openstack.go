@@ -0,0 +1,578 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capi2mapi
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	mapiv1alpha1 "github.com/openshift/api/machine/v1alpha1"
+	mapiv1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-capi-operator/pkg/errorsx"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	capov1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1beta1"
+	capiv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+const (
+	// openStackCloudsSecretName and openStackCloudName reference the cloud credentials of the cluster,
+	// used by the Machine API machines.
+	openStackCloudsSecretName = "openstack-cloud-credentials"
+	openStackCloudName        = "openstack"
+
+	openStackProfileCapabilitiesKey       = "capabilities"
+	openStackProfileSwitchdevCapabilities = `["switchdev"]`
+	openStackProfileTrustedKey            = "trusted"
+)
+
+var (
+	errCAPIMachineOpenStackMachineOpenStackClusterCannotBeNil            = errors.New("provided Machine, OpenStackMachine and OpenStackCluster can not be nil")
+	errCAPIMachineSetOpenStackMachineTemplateOpenStackClusterCannotBeNil = errors.New("provided MachineSet, OpenStackMachineTemplate and OpenStackCluster can not be nil")
+)
+
+// machineAndOpenStackMachineAndOpenStackCluster stores the details of a Cluster API Machine and OpenStackMachine and OpenStackCluster.
+type machineAndOpenStackMachineAndOpenStackCluster struct {
+	machine          *capiv1.Machine
+	openStackMachine *capov1.OpenStackMachine
+	openStackCluster *capov1.OpenStackCluster
+}
+
+// machineSetAndOpenStackMachineTemplateAndOpenStackCluster stores the details of a Cluster API MachineSet and OpenStackMachineTemplate and OpenStackCluster.
+type machineSetAndOpenStackMachineTemplateAndOpenStackCluster struct {
+	machineSet       *capiv1.MachineSet
+	template         *capov1.OpenStackMachineTemplate
+	openStackCluster *capov1.OpenStackCluster
+	*machineAndOpenStackMachineAndOpenStackCluster
+}
+
+// FromMachineAndOpenStackMachineAndOpenStackCluster wraps a CAPI Machine and CAPO OpenStackMachine and CAPO OpenStackCluster into a capi2mapi MachineAndInfrastructureMachine.
+func FromMachineAndOpenStackMachineAndOpenStackCluster(m *capiv1.Machine, om *capov1.OpenStackMachine, oc *capov1.OpenStackCluster) MachineAndInfrastructureMachine {
+	return &machineAndOpenStackMachineAndOpenStackCluster{machine: m, openStackMachine: om, openStackCluster: oc}
+}
+
+// FromMachineSetAndOpenStackMachineTemplateAndOpenStackCluster wraps a CAPI MachineSet and CAPO OpenStackMachineTemplate and CAPO OpenStackCluster into a capi2mapi MachineSetAndMachineTemplate.
+func FromMachineSetAndOpenStackMachineTemplateAndOpenStackCluster(ms *capiv1.MachineSet, mts *capov1.OpenStackMachineTemplate, oc *capov1.OpenStackCluster) MachineSetAndMachineTemplate {
+	return &machineSetAndOpenStackMachineTemplateAndOpenStackCluster{
+		machineSet:       ms,
+		template:         mts,
+		openStackCluster: oc,
+		machineAndOpenStackMachineAndOpenStackCluster: &machineAndOpenStackMachineAndOpenStackCluster{
+			machine: &capiv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      ms.Spec.Template.ObjectMeta.Labels,
+					Annotations: ms.Spec.Template.ObjectMeta.Annotations,
+				},
+				Spec: ms.Spec.Template.Spec,
+			},
+			openStackMachine: &capov1.OpenStackMachine{
+				Spec: mts.Spec.Template.Spec,
+			},
+			openStackCluster: oc,
+		},
+	}
+}
+
+// ToMachine converts a capi2mapi MachineAndOpenStackMachineAndOpenStackCluster into a MAPI Machine.
+func (m machineAndOpenStackMachineAndOpenStackCluster) ToMachine() (*mapiv1beta1.Machine, []string, error) {
+	if m.machine == nil || m.openStackMachine == nil || m.openStackCluster == nil {
+		return nil, nil, errCAPIMachineOpenStackMachineOpenStackClusterCannotBeNil
+	}
+
+	var (
+		errors   field.ErrorList
+		warnings []string
+	)
+
+	mapoSpec, err := m.toProviderSpec()
+	if err != nil {
+		errors = append(errors, err...)
+	}
+
+	openStackRawExt, errRaw := RawExtensionFromProviderSpec(mapoSpec)
+	if errRaw != nil {
+		return nil, nil, fmt.Errorf("unable to convert OpenStack providerSpec to raw extension: %w", errRaw)
+	}
+
+	mapiMachine, err := fromCAPIMachineToMAPIMachine(m.machine)
+	if err != nil {
+		errors = append(errors, err...)
+	}
+
+	mapiMachine.Spec.ProviderSpec.Value = openStackRawExt
+
+	if len(errors) > 0 {
+		return nil, warnings, errorsx.UnsupportedFields(errors.ToAggregate())
+	}
+
+	return mapiMachine, warnings, nil
+}
+
+// ToMachineSet converts a capi2mapi MachineSetAndOpenStackMachineTemplateAndOpenStackCluster into a MAPI MachineSet.
+//
+//nolint:dupl
+func (m machineSetAndOpenStackMachineTemplateAndOpenStackCluster) ToMachineSet() (*mapiv1beta1.MachineSet, []string, error) {
+	if m.machineSet == nil || m.template == nil || m.openStackCluster == nil || m.machineAndOpenStackMachineAndOpenStackCluster == nil {
+		return nil, nil, errCAPIMachineSetOpenStackMachineTemplateOpenStackClusterCannotBeNil
+	}
+
+	var (
+		errs     []error
+		warnings []string
+	)
+
+	// Run the full ToMachine conversion so that we can check for
+	// any Machine level conversion errors in the spec translation.
+	mapiOpenStackMachine, warn, err := m.ToMachine()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	warnings = append(warnings, warn...)
+
+	mapiMachineSet, err := fromCAPIMachineSetToMAPIMachineSet(m.machineSet)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return nil, warnings, errorsx.UnsupportedFields(utilerrors.NewAggregate(errs))
+	}
+
+	mapiMachineSet.Spec.Template.Spec = mapiOpenStackMachine.Spec
+
+	// Copy the labels and annotations from the Machine to the template.
+	mapiMachineSet.Spec.Template.ObjectMeta.Annotations = mapiOpenStackMachine.ObjectMeta.Annotations
+	mapiMachineSet.Spec.Template.ObjectMeta.Labels = mapiMachineSetTemplateLabels(m.machineSet, mapiOpenStackMachine.ObjectMeta.Labels)
+
+	return mapiMachineSet, warnings, nil
+}
+
+// toProviderSpec converts a capi2mapi machineAndOpenStackMachineAndOpenStackCluster into a MAPI OpenstackProviderSpec.
+//
+//nolint:funlen
+func (m machineAndOpenStackMachineAndOpenStackCluster) toProviderSpec() (*mapiv1alpha1.OpenstackProviderSpec, field.ErrorList) {
+	var errs field.ErrorList
+
+	fldPath := field.NewPath("spec")
+	spec := m.openStackMachine.Spec
+
+	networks, ports, portErrs := convertCAPOOpenStackPortsToMAPO(fldPath.Child("ports"), spec.Ports)
+	errs = append(errs, portErrs...)
+
+	var securityGroups []mapiv1alpha1.SecurityGroupParam
+
+	for _, securityGroup := range spec.SecurityGroups {
+		securityGroups = append(securityGroups, convertCAPOOpenStackSecurityGroupToMAPO(securityGroup))
+	}
+
+	image, imageErrs := convertCAPOOpenStackImageToMAPO(fldPath.Child("image"), spec.Image)
+	errs = append(errs, imageErrs...)
+
+	cloudsSecret, cloudName, identityErrs := convertCAPOOpenStackIdentityRefToMAPO(fldPath.Child("identityRef"), spec.IdentityRef)
+	errs = append(errs, identityErrs...)
+
+	mapoProviderConfig := mapiv1alpha1.OpenstackProviderSpec{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "OpenstackProviderSpec",
+			APIVersion: "machine.openshift.io/v1alpha1",
+		},
+		CloudsSecret:           cloudsSecret,
+		CloudName:              cloudName,
+		Flavor:                 ptr.Deref(spec.Flavor, ""),
+		Image:                  image,
+		KeyName:                spec.SSHKeyName,
+		Networks:               networks,
+		Ports:                  ports,
+		AvailabilityZone:       ptr.Deref(m.machine.Spec.FailureDomain, ""),
+		SecurityGroups:         securityGroups,
+		Trunk:                  spec.Trunk,
+		Tags:                   spec.Tags,
+		ServerMetadata:         convertCAPOOpenStackServerMetadataToMAPO(spec.ServerMetadata),
+		ConfigDrive:            spec.ConfigDrive,
+		RootVolume:             convertCAPOOpenStackRootVolumeToMAPO(spec.RootVolume, m.machine.Spec.FailureDomain),
+		AdditionalBlockDevices: convertCAPOOpenStackAdditionalBlockDevicesToMAPO(spec.AdditionalBlockDevices, m.machine.Spec.FailureDomain),
+		PrimarySubnet:          openStackPrimarySubnet(spec.Ports),
+	}
+
+	if spec.ServerGroup != nil {
+		mapoProviderConfig.ServerGroupID = ptr.Deref(spec.ServerGroup.ID, "")
+
+		if spec.ServerGroup.Filter != nil {
+			mapoProviderConfig.ServerGroupName = ptr.Deref(spec.ServerGroup.Filter.Name, "")
+		}
+	}
+
+	userDataSecretName := ptr.Deref(m.machine.Spec.Bootstrap.DataSecretName, "")
+	if userDataSecretName != "" {
+		mapoProviderConfig.UserDataSecret = &corev1.SecretReference{
+			Name: userDataSecretName,
+		}
+	}
+
+	// Below this line are fields not used from the CAPI OpenStackMachine.
+
+	// ProviderID - Populated at a different level.
+
+	if spec.FlavorID != nil {
+		errs = append(errs, field.Invalid(fldPath.Child("flavorID"), *spec.FlavorID, "flavorID is not supported, use flavor instead"))
+	}
+
+	if spec.FloatingIPPoolRef != nil {
+		errs = append(errs, field.Invalid(fldPath.Child("floatingIPPoolRef"), spec.FloatingIPPoolRef, "floatingIPPoolRef is not supported"))
+	}
+
+	if len(spec.SchedulerHintAdditionalProperties) > 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("schedulerHintAdditionalProperties"), spec.SchedulerHintAdditionalProperties, "schedulerHintAdditionalProperties are not supported"))
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return &mapoProviderConfig, nil
+}
+
+// Conversion helpers.
+
+// convertCAPOOpenStackPortsToMAPO converts the ports into Machine API networks when they can be represented as such,
+// and into Machine API ports otherwise. As the Machine API creates the ports of the networks first, a port that
+// follows a Machine API port must be a Machine API port as well.
+func convertCAPOOpenStackPortsToMAPO(fldPath *field.Path, ports []capov1.PortOpts) ([]mapiv1alpha1.NetworkParam, []mapiv1alpha1.PortOpts, field.ErrorList) {
+	var (
+		errs      field.ErrorList
+		networks  []mapiv1alpha1.NetworkParam
+		mapoPorts []mapiv1alpha1.PortOpts
+	)
+
+	for i, port := range ports {
+		portFldPath := fldPath.Index(i)
+
+		if len(mapoPorts) == 0 && isOpenStackNetworkPort(port) {
+			networks = append(networks, convertCAPOOpenStackPortToMAPONetwork(port))
+			continue
+		}
+
+		mapoPort, portErrs := convertCAPOOpenStackPortToMAPO(portFldPath, port)
+		errs = append(errs, portErrs...)
+		mapoPorts = append(mapoPorts, mapoPort)
+	}
+
+	return networks, mapoPorts, errs
+}
+
+// isOpenStackNetworkPort returns whether the port can be represented as a Machine API network, that is whether it
+// only selects a network and its subnets, and sets the options of the ports of the networks.
+func isOpenStackNetworkPort(port capov1.PortOpts) bool {
+	if port.Network == nil || port.NameSuffix != nil || port.Description != nil || port.SecurityGroups != nil || port.Trunk != nil ||
+		port.AdminStateUp != nil || port.MACAddress != nil || port.AllowedAddressPairs != nil || port.HostID != nil || port.PropagateUplinkStatus != nil || port.ValueSpecs != nil {
+		return false
+	}
+
+	for _, fixedIP := range port.FixedIPs {
+		if fixedIP.Subnet == nil || fixedIP.IPAddress != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// convertCAPOOpenStackPortToMAPONetwork converts a port into a Machine API network, with one subnet per fixed IP.
+func convertCAPOOpenStackPortToMAPONetwork(port capov1.PortOpts) mapiv1alpha1.NetworkParam {
+	network := mapiv1alpha1.NetworkParam{
+		UUID:     ptr.Deref(port.Network.ID, ""),
+		PortTags: port.Tags,
+		VNICType: ptr.Deref(port.VNICType, ""),
+		Profile:  convertCAPOOpenStackBindingProfileToMAPO(port.Profile),
+	}
+
+	if port.Network.Filter != nil {
+		network.Filter = mapiv1alpha1.Filter{
+			Name:        port.Network.Filter.Name,
+			Description: port.Network.Filter.Description,
+			ProjectID:   port.Network.Filter.ProjectID,
+		}
+		network.Filter.Tags, network.Filter.TagsAny, network.Filter.NotTags, network.Filter.NotTagsAny = joinOpenStackNeutronTags(port.Network.Filter.FilterByNeutronTags)
+	}
+
+	for _, fixedIP := range port.FixedIPs {
+		network.Subnets = append(network.Subnets, convertCAPOOpenStackSubnetToMAPO(*fixedIP.Subnet))
+	}
+
+	if port.DisablePortSecurity != nil {
+		network.PortSecurity = ptr.To(!*port.DisablePortSecurity)
+	}
+
+	return network
+}
+
+func convertCAPOOpenStackSubnetToMAPO(subnet capov1.SubnetParam) mapiv1alpha1.SubnetParam {
+	mapoSubnet := mapiv1alpha1.SubnetParam{
+		UUID: ptr.Deref(subnet.ID, ""),
+	}
+
+	if subnet.Filter != nil {
+		mapoSubnet.Filter = mapiv1alpha1.SubnetFilter{
+			Name:            subnet.Filter.Name,
+			Description:     subnet.Filter.Description,
+			ProjectID:       subnet.Filter.ProjectID,
+			IPVersion:       subnet.Filter.IPVersion,
+			GatewayIP:       subnet.Filter.GatewayIP,
+			CIDR:            subnet.Filter.CIDR,
+			IPv6AddressMode: subnet.Filter.IPv6AddressMode,
+			IPv6RAMode:      subnet.Filter.IPv6RAMode,
+		}
+		mapoSubnet.Filter.Tags, mapoSubnet.Filter.TagsAny, mapoSubnet.Filter.NotTags, mapoSubnet.Filter.NotTagsAny = joinOpenStackNeutronTags(subnet.Filter.FilterByNeutronTags)
+	}
+
+	return mapoSubnet
+}
+
+// convertCAPOOpenStackPortToMAPO converts a port into a Machine API port, which only references its network and
+// subnets by ID.
+func convertCAPOOpenStackPortToMAPO(fldPath *field.Path, port capov1.PortOpts) (mapiv1alpha1.PortOpts, field.ErrorList) {
+	var errs field.ErrorList
+
+	mapoPort := mapiv1alpha1.PortOpts{
+		NameSuffix:   ptr.Deref(port.NameSuffix, ""),
+		Description:  ptr.Deref(port.Description, ""),
+		AdminStateUp: port.AdminStateUp,
+		MACAddress:   ptr.Deref(port.MACAddress, ""),
+		Tags:         port.Tags,
+		VNICType:     ptr.Deref(port.VNICType, ""),
+		Profile:      convertCAPOOpenStackBindingProfileToMAPO(port.Profile),
+		Trunk:        port.Trunk,
+		// DeprecatedHostID is still honoured by the Machine API.
+		DeprecatedHostID: ptr.Deref(port.HostID, ""),
+	}
+
+	if port.Network == nil || port.Network.ID == nil {
+		errs = append(errs, field.Invalid(fldPath.Child("network"), port.Network, "the network of a port must be referenced by ID when the port cannot be represented as a Machine API network"))
+	} else {
+		mapoPort.NetworkID = *port.Network.ID
+	}
+
+	for i, fixedIP := range port.FixedIPs {
+		if fixedIP.Subnet == nil || fixedIP.Subnet.ID == nil {
+			errs = append(errs, field.Invalid(fldPath.Child("fixedIPs").Index(i).Child("subnet"), fixedIP.Subnet, "the subnet of a fixed IP must be referenced by ID when the port cannot be represented as a Machine API network"))
+			continue
+		}
+
+		mapoPort.FixedIPs = append(mapoPort.FixedIPs, mapiv1alpha1.FixedIPs{
+			SubnetID:  *fixedIP.Subnet.ID,
+			IPAddress: ptr.Deref(fixedIP.IPAddress, ""),
+		})
+	}
+
+	if port.SecurityGroups != nil {
+		securityGroupIDs := []string{}
+
+		for i, securityGroup := range port.SecurityGroups {
+			if securityGroup.ID == nil {
+				errs = append(errs, field.Invalid(fldPath.Child("securityGroups").Index(i), securityGroup, "the security groups of a port must be referenced by ID"))
+				continue
+			}
+
+			securityGroupIDs = append(securityGroupIDs, *securityGroup.ID)
+		}
+
+		mapoPort.SecurityGroups = &securityGroupIDs
+	}
+
+	for _, pair := range port.AllowedAddressPairs {
+		mapoPort.AllowedAddressPairs = append(mapoPort.AllowedAddressPairs, mapiv1alpha1.AddressPair{
+			IPAddress:  pair.IPAddress,
+			MACAddress: ptr.Deref(pair.MACAddress, ""),
+		})
+	}
+
+	if port.DisablePortSecurity != nil {
+		mapoPort.PortSecurity = ptr.To(!*port.DisablePortSecurity)
+	}
+
+	if port.PropagateUplinkStatus != nil {
+		errs = append(errs, field.Invalid(fldPath.Child("propagateUplinkStatus"), *port.PropagateUplinkStatus, "propagateUplinkStatus is not supported"))
+	}
+
+	if len(port.ValueSpecs) > 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("valueSpecs"), port.ValueSpecs, "valueSpecs are not supported"))
+	}
+
+	return mapoPort, errs
+}
+
+// convertCAPOOpenStackBindingProfileToMAPO converts the binding profile of a port, such as the one of SR-IOV ports.
+func convertCAPOOpenStackBindingProfileToMAPO(profile *capov1.BindingProfile) map[string]string {
+	if profile == nil {
+		return nil
+	}
+
+	mapoProfile := map[string]string{}
+
+	if ptr.Deref(profile.OVSHWOffload, false) {
+		mapoProfile[openStackProfileCapabilitiesKey] = openStackProfileSwitchdevCapabilities
+	}
+
+	if profile.TrustedVF != nil {
+		mapoProfile[openStackProfileTrustedKey] = strconv.FormatBool(*profile.TrustedVF)
+	}
+
+	if len(mapoProfile) == 0 {
+		return nil
+	}
+
+	return mapoProfile
+}
+
+func convertCAPOOpenStackSecurityGroupToMAPO(securityGroup capov1.SecurityGroupParam) mapiv1alpha1.SecurityGroupParam {
+	mapoSecurityGroup := mapiv1alpha1.SecurityGroupParam{
+		UUID: ptr.Deref(securityGroup.ID, ""),
+	}
+
+	if securityGroup.Filter != nil {
+		mapoSecurityGroup.Filter = mapiv1alpha1.SecurityGroupFilter{
+			Name:        securityGroup.Filter.Name,
+			Description: securityGroup.Filter.Description,
+			ProjectID:   securityGroup.Filter.ProjectID,
+		}
+		mapoSecurityGroup.Filter.Tags, mapoSecurityGroup.Filter.TagsAny, mapoSecurityGroup.Filter.NotTags, mapoSecurityGroup.Filter.NotTagsAny = joinOpenStackNeutronTags(securityGroup.Filter.FilterByNeutronTags)
+	}
+
+	return mapoSecurityGroup
+}
+
+// joinOpenStackNeutronTags converts the tags of a filter into the comma separated tags of the Machine API.
+func joinOpenStackNeutronTags(tags capov1.FilterByNeutronTags) (string, string, string, string) {
+	join := func(neutronTags []capov1.NeutronTag) string {
+		tags := make([]string, 0, len(neutronTags))
+		for _, tag := range neutronTags {
+			tags = append(tags, string(tag))
+		}
+
+		return strings.Join(tags, ",")
+	}
+
+	return join(tags.Tags), join(tags.TagsAny), join(tags.NotTags), join(tags.NotTagsAny)
+}
+
+// openStackPrimarySubnet returns the subnet of the first fixed IP of the first port, used by Cluster API for the
+// addresses of the machine.
+func openStackPrimarySubnet(ports []capov1.PortOpts) string {
+	if len(ports) == 0 || len(ports[0].FixedIPs) == 0 || ports[0].FixedIPs[0].Subnet == nil {
+		return ""
+	}
+
+	return ptr.Deref(ports[0].FixedIPs[0].Subnet.ID, "")
+}
+
+// convertCAPOOpenStackImageToMAPO converts the image, which the Machine API looks up by name.
+func convertCAPOOpenStackImageToMAPO(fldPath *field.Path, image capov1.ImageParam) (string, field.ErrorList) {
+	if image.ID != nil || image.ImageRef != nil || image.Filter == nil || image.Filter.Name == nil || len(image.Filter.Tags) > 0 {
+		return "", field.ErrorList{field.Invalid(fldPath, image, "the image must be referenced by name only")}
+	}
+
+	return *image.Filter.Name, nil
+}
+
+func convertCAPOOpenStackRootVolumeToMAPO(rootVolume *capov1.RootVolume, failureDomain *string) *mapiv1alpha1.RootVolume {
+	if rootVolume == nil {
+		return nil
+	}
+
+	return &mapiv1alpha1.RootVolume{
+		Size:       rootVolume.SizeGiB,
+		VolumeType: rootVolume.Type,
+		Zone:       convertCAPOOpenStackVolumeAvailabilityZoneToMAPO(rootVolume.AvailabilityZone, failureDomain),
+	}
+}
+
+func convertCAPOOpenStackAdditionalBlockDevicesToMAPO(blockDevices []capov1.AdditionalBlockDevice, failureDomain *string) []mapiv1alpha1.AdditionalBlockDevice {
+	var mapoBlockDevices []mapiv1alpha1.AdditionalBlockDevice
+
+	for _, blockDevice := range blockDevices {
+		mapoBlockDevice := mapiv1alpha1.AdditionalBlockDevice{
+			Name:    blockDevice.Name,
+			SizeGiB: blockDevice.SizeGiB,
+			Storage: mapiv1alpha1.BlockDeviceStorage{
+				Type: mapiv1alpha1.BlockDeviceType(blockDevice.Storage.Type),
+			},
+		}
+
+		if blockDevice.Storage.Volume != nil {
+			mapoBlockDevice.Storage.Volume = &mapiv1alpha1.BlockDeviceVolume{
+				Type:             blockDevice.Storage.Volume.Type,
+				AvailabilityZone: convertCAPOOpenStackVolumeAvailabilityZoneToMAPO(blockDevice.Storage.Volume.AvailabilityZone, failureDomain),
+			}
+		}
+
+		mapoBlockDevices = append(mapoBlockDevices, mapoBlockDevice)
+	}
+
+	return mapoBlockDevices
+}
+
+// convertCAPOOpenStackVolumeAvailabilityZoneToMAPO converts the availability zone of a volume, the Machine API
+// only supports naming it.
+func convertCAPOOpenStackVolumeAvailabilityZoneToMAPO(zone *capov1.VolumeAvailabilityZone, failureDomain *string) string {
+	if zone == nil {
+		return ""
+	}
+
+	if zone.From == capov1.VolumeAZFromMachine {
+		return ptr.Deref(failureDomain, "")
+	}
+
+	return string(ptr.Deref(zone.Name, ""))
+}
+
+// convertCAPOOpenStackIdentityRefToMAPO converts the identityRef of the OpenStackMachine to the clouds.yaml secret
+// of the Machine API namespace, and the cloud selected in it. The machines without identityRef use the credentials of
+// the cluster. The region overrides have no Machine API equivalent.
+func convertCAPOOpenStackIdentityRefToMAPO(fldPath *field.Path, identityRef *capov1.OpenStackIdentityReference) (*corev1.SecretReference, string, field.ErrorList) {
+	if identityRef == nil {
+		return &corev1.SecretReference{Name: openStackCloudsSecretName, Namespace: mapiNamespace}, openStackCloudName, nil
+	}
+
+	var errs field.ErrorList
+
+	if identityRef.Region != "" {
+		errs = append(errs, field.Invalid(fldPath.Child("region"), identityRef.Region, "region is not supported"))
+	}
+
+	return &corev1.SecretReference{Name: identityRef.Name, Namespace: mapiNamespace}, identityRef.CloudName, errs
+}
+
+// convertCAPOOpenStackServerMetadataToMAPO converts the server metadata.
+func convertCAPOOpenStackServerMetadataToMAPO(serverMetadata []capov1.ServerMetadata) map[string]string {
+	if len(serverMetadata) == 0 {
+		return nil
+	}
+
+	mapoServerMetadata := map[string]string{}
+	for _, metadata := range serverMetadata {
+		mapoServerMetadata[metadata.Key] = metadata.Value
+	}
+
+	return mapoServerMetadata
+}
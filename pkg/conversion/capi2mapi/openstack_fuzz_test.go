@@ -0,0 +1,324 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capi2mapi_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	fuzz "github.com/google/gofuzz"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-capi-operator/pkg/conversion/capi2mapi"
+	"github.com/openshift/cluster-capi-operator/pkg/conversion/mapi2capi"
+	conversiontest "github.com/openshift/cluster-capi-operator/pkg/conversion/test/fuzz"
+
+	runtimeserializer "k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/utils/ptr"
+	capov1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1beta1"
+	capiv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	openStackMachineKind  = "OpenStackMachine"
+	openStackTemplateKind = "OpenStackMachineTemplate"
+)
+
+var _ = Describe("OpenStack Fuzz (capi2mapi)", func() {
+	infra := &configv1.Infrastructure{
+		Spec: configv1.InfrastructureSpec{},
+		Status: configv1.InfrastructureStatus{
+			InfrastructureName: "sample-cluster-name",
+		},
+	}
+
+	infraCluster := &capov1.OpenStackCluster{}
+
+	Context("OpenStackMachine Conversion", func() {
+		fromMachineAndOpenStackMachineAndOpenStackCluster := func(machine *capiv1.Machine, infraMachine client.Object, infraCluster client.Object) capi2mapi.MachineAndInfrastructureMachine {
+			openStackMachine, ok := infraMachine.(*capov1.OpenStackMachine)
+			Expect(ok).To(BeTrue(), "input infra machine should be of type %T, got %T", &capov1.OpenStackMachine{}, infraMachine)
+
+			openStackCluster, ok := infraCluster.(*capov1.OpenStackCluster)
+			Expect(ok).To(BeTrue(), "input infra cluster should be of type %T, got %T", &capov1.OpenStackCluster{}, infraCluster)
+
+			return capi2mapi.FromMachineAndOpenStackMachineAndOpenStackCluster(machine, openStackMachine, openStackCluster)
+		}
+
+		conversiontest.CAPI2MAPIMachineRoundTripFuzzTest(
+			scheme,
+			infra,
+			infraCluster,
+			&capov1.OpenStackMachine{},
+			mapi2capi.FromOpenStackMachineAndInfra,
+			fromMachineAndOpenStackMachineAndOpenStackCluster,
+			conversiontest.ObjectMetaFuzzerFuncs(capiNamespace),
+			conversiontest.CAPIMachineFuzzerFuncs(openStackProviderIDFuzzer, openStackMachineKind, capov1.SchemeGroupVersion.String(), infra.Status.InfrastructureName),
+			openStackMachineFuzzerFuncs,
+		)
+	})
+
+	Context("OpenStackMachineSet Conversion", func() {
+		fromMachineSetAndOpenStackMachineTemplateAndOpenStackCluster := func(machineSet *capiv1.MachineSet, infraMachineTemplate client.Object, infraCluster client.Object) capi2mapi.MachineSetAndMachineTemplate {
+			openStackMachineTemplate, ok := infraMachineTemplate.(*capov1.OpenStackMachineTemplate)
+			Expect(ok).To(BeTrue(), "input infra machine template should be of type %T, got %T", &capov1.OpenStackMachineTemplate{}, infraMachineTemplate)
+
+			openStackCluster, ok := infraCluster.(*capov1.OpenStackCluster)
+			Expect(ok).To(BeTrue(), "input infra cluster should be of type %T, got %T", &capov1.OpenStackCluster{}, infraCluster)
+
+			return capi2mapi.FromMachineSetAndOpenStackMachineTemplateAndOpenStackCluster(machineSet, openStackMachineTemplate, openStackCluster)
+		}
+
+		conversiontest.CAPI2MAPIMachineSetRoundTripFuzzTest(
+			scheme,
+			infra,
+			infraCluster,
+			&capov1.OpenStackMachineTemplate{},
+			mapi2capi.FromOpenStackMachineSetAndInfra,
+			fromMachineSetAndOpenStackMachineTemplateAndOpenStackCluster,
+			conversiontest.ObjectMetaFuzzerFuncs(capiNamespace),
+			conversiontest.CAPIMachineFuzzerFuncs(openStackProviderIDFuzzer, openStackTemplateKind, capov1.SchemeGroupVersion.String(), infra.Status.InfrastructureName),
+			conversiontest.CAPIMachineSetFuzzerFuncs(openStackTemplateKind, capov1.SchemeGroupVersion.String(), infra.Status.InfrastructureName),
+			openStackMachineFuzzerFuncs,
+			openStackMachineTemplateFuzzerFuncs,
+		)
+	})
+})
+
+func openStackProviderIDFuzzer(c fuzz.Continue) string {
+	return "openstack:///" + strings.ReplaceAll(c.RandString(), "/", "")
+}
+
+//nolint:funlen
+func openStackMachineFuzzerFuncs(codecs runtimeserializer.CodecFactory) []interface{} {
+	return []interface{}{
+		func(tag *capov1.NeutronTag, c fuzz.Continue) {
+			// The tags of the Machine API filters are comma separated, and empty tags are dropped.
+			*tag = capov1.NeutronTag(strings.ReplaceAll(fuzzOpenStackNonEmptyString(c), ",", "-"))
+		},
+		func(network *capov1.NetworkParam, c fuzz.Continue) {
+			// A network is selected either by ID or by filter.
+			if c.RandBool() {
+				*network = capov1.NetworkParam{ID: ptr.To(fuzzOpenStackNonEmptyString(c))}
+
+				return
+			}
+
+			filter := &capov1.NetworkFilter{}
+			c.Fuzz(filter)
+			filter.Name = fuzzOpenStackNonEmptyString(c)
+
+			*network = capov1.NetworkParam{Filter: filter}
+		},
+		func(subnet *capov1.SubnetParam, c fuzz.Continue) {
+			// A subnet is selected either by ID or by filter.
+			if c.RandBool() {
+				*subnet = capov1.SubnetParam{ID: ptr.To(fuzzOpenStackNonEmptyString(c))}
+
+				return
+			}
+
+			filter := &capov1.SubnetFilter{}
+			c.Fuzz(filter)
+			filter.Name = fuzzOpenStackNonEmptyString(c)
+
+			*subnet = capov1.SubnetParam{Filter: filter}
+		},
+		func(securityGroup *capov1.SecurityGroupParam, c fuzz.Continue) {
+			// A security group is selected either by ID or by filter.
+			if c.RandBool() {
+				*securityGroup = capov1.SecurityGroupParam{ID: ptr.To(fuzzOpenStackNonEmptyString(c))}
+
+				return
+			}
+
+			filter := &capov1.SecurityGroupFilter{}
+			c.Fuzz(filter)
+			filter.Name = fuzzOpenStackNonEmptyString(c)
+
+			*securityGroup = capov1.SecurityGroupParam{Filter: filter}
+		},
+		func(port *capov1.PortOpts, c fuzz.Continue) {
+			if c.RandBool() {
+				fuzzOpenStackNetworkPort(port, c)
+
+				return
+			}
+
+			c.FuzzNoCustom(port)
+
+			// A port which is not a Machine API network references its network and subnets by ID.
+			port.Network = &capov1.NetworkParam{ID: ptr.To(fuzzOpenStackNonEmptyString(c))}
+			port.NameSuffix = ptr.To(fuzzOpenStackNonEmptyString(c))
+
+			for i := range port.FixedIPs {
+				port.FixedIPs[i].Subnet = &capov1.SubnetParam{ID: ptr.To(fuzzOpenStackNonEmptyString(c))}
+			}
+
+			// The security groups of a port are referenced by ID, and a port has at least one when they are set.
+			port.SecurityGroups = nil
+			for range c.Intn(3) {
+				port.SecurityGroups = append(port.SecurityGroups, capov1.SecurityGroupParam{ID: ptr.To(fuzzOpenStackNonEmptyString(c))})
+			}
+
+			// Clear fields that are not supported on the ports.
+			port.PropagateUplinkStatus = nil
+			port.ValueSpecs = nil
+
+			// Clear the empty slices, which the conversion doesn't initialise.
+			if len(port.FixedIPs) == 0 {
+				port.FixedIPs = nil
+			}
+
+			if len(port.AllowedAddressPairs) == 0 {
+				port.AllowedAddressPairs = nil
+			}
+
+			port.Profile = fuzzOpenStackBindingProfile(c)
+		},
+		func(image *capov1.ImageParam, c fuzz.Continue) {
+			// The Machine API looks up the image by name only.
+			*image = capov1.ImageParam{Filter: &capov1.ImageFilter{Name: ptr.To(fuzzOpenStackNonEmptyString(c))}}
+		},
+		func(serverGroup *capov1.ServerGroupParam, c fuzz.Continue) {
+			// A server group is selected either by ID or by name.
+			if c.RandBool() {
+				*serverGroup = capov1.ServerGroupParam{ID: ptr.To(fuzzOpenStackNonEmptyString(c))}
+			} else {
+				*serverGroup = capov1.ServerGroupParam{Filter: &capov1.ServerGroupFilter{Name: ptr.To(fuzzOpenStackNonEmptyString(c))}}
+			}
+		},
+		func(zone *capov1.VolumeAvailabilityZone, c fuzz.Continue) {
+			// The Machine API only supports naming the availability zone of the volumes.
+			*zone = capov1.VolumeAvailabilityZone{
+				From: capov1.VolumeAZFromName,
+				Name: ptr.To(capov1.VolumeAZName(fuzzOpenStackNonEmptyString(c))),
+			}
+		},
+		func(identityRef *capov1.OpenStackIdentityReference, c fuzz.Continue) {
+			// The region overrides have no Machine API equivalent.
+			*identityRef = capov1.OpenStackIdentityReference{
+				Name:      fuzzOpenStackNonEmptyString(c),
+				CloudName: fuzzOpenStackNonEmptyString(c),
+			}
+		},
+		func(spec *capov1.OpenStackMachineSpec, c fuzz.Continue) {
+			c.FuzzNoCustom(spec)
+
+			// The flavor is always set by the Machine API.
+			if spec.Flavor == nil {
+				spec.Flavor = ptr.To("")
+			}
+
+			// Clear fields that are not supported in the machine spec.
+			spec.FlavorID = nil
+			spec.FloatingIPPoolRef = nil
+			spec.SchedulerHintAdditionalProperties = nil
+
+			// Clear the empty slices, which the conversion doesn't initialise.
+			if len(spec.Ports) == 0 {
+				spec.Ports = nil
+			}
+
+			if len(spec.SecurityGroups) == 0 {
+				spec.SecurityGroups = nil
+			}
+
+			if len(spec.ServerMetadata) == 0 {
+				spec.ServerMetadata = nil
+			}
+
+			if len(spec.AdditionalBlockDevices) == 0 {
+				spec.AdditionalBlockDevices = nil
+			}
+		},
+		func(m *capov1.OpenStackMachine, c fuzz.Continue) {
+			c.FuzzNoCustom(m)
+
+			// Ensure the type meta is set correctly.
+			m.TypeMeta.APIVersion = capov1.SchemeGroupVersion.String()
+			m.TypeMeta.Kind = openStackMachineKind
+		},
+	}
+}
+
+func openStackMachineTemplateFuzzerFuncs(codecs runtimeserializer.CodecFactory) []interface{} {
+	return []interface{}{
+		func(m *capov1.OpenStackMachineTemplate, c fuzz.Continue) {
+			c.FuzzNoCustom(m)
+
+			// Ensure the type meta is set correctly.
+			m.TypeMeta.APIVersion = capov1.SchemeGroupVersion.String()
+			m.TypeMeta.Kind = openStackTemplateKind
+		},
+	}
+}
+
+// fuzzOpenStackNetworkPort fuzzes a port which is converted to a Machine API network, as it only selects a network
+// and its subnets.
+func fuzzOpenStackNetworkPort(port *capov1.PortOpts, c fuzz.Continue) {
+	*port = capov1.PortOpts{
+		Network: &capov1.NetworkParam{},
+	}
+
+	c.Fuzz(port.Network)
+	c.Fuzz(&port.Tags)
+
+	for range c.Intn(3) {
+		subnet := &capov1.SubnetParam{}
+		c.Fuzz(subnet)
+
+		port.FixedIPs = append(port.FixedIPs, capov1.FixedIP{Subnet: subnet})
+	}
+
+	c.Fuzz(&port.VNICType)
+	c.Fuzz(&port.DisablePortSecurity)
+	port.Profile = fuzzOpenStackBindingProfile(c)
+
+	// Clear the empty slices, which the conversion doesn't initialise.
+	if len(port.Tags) == 0 {
+		port.Tags = nil
+	}
+}
+
+// fuzzOpenStackBindingProfile returns a binding profile, the Machine API only enabling the OVS hardware offload.
+func fuzzOpenStackBindingProfile(c fuzz.Continue) *capov1.BindingProfile {
+	profile := &capov1.BindingProfile{}
+
+	if c.RandBool() {
+		profile.OVSHWOffload = ptr.To(true)
+	}
+
+	c.Fuzz(&profile.TrustedVF)
+
+	if profile.OVSHWOffload == nil && profile.TrustedVF == nil {
+		return nil
+	}
+
+	return profile
+}
+
+// fuzzOpenStackNonEmptyString returns a random string which is not empty, for the fields the conversion requires.
+func fuzzOpenStackNonEmptyString(c fuzz.Continue) string {
+	for {
+		if s := c.RandString(); s != "" {
+			return s
+		}
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capi2mapi_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	mapiv1alpha1 "github.com/openshift/api/machine/v1alpha1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	capibuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/core/v1beta1"
+	machinebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+
+	"github.com/openshift/cluster-capi-operator/pkg/conversion/capi2mapi"
+	"github.com/openshift/cluster-capi-operator/pkg/conversion/mapi2capi"
+	"github.com/openshift/cluster-capi-operator/pkg/conversion/test/matchers"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	capov1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1beta1"
+	"sigs.k8s.io/yaml"
+)
+
+var _ = Describe("capi2mapi OpenStack conversion", func() {
+	infra := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{InfrastructureName: "sample-cluster-name"},
+	}
+
+	openStackCluster := &capov1.OpenStackCluster{}
+
+	// A provider spec with the shapes the conversion produces, so that it is converted back unchanged.
+	baseProviderSpec := func() *mapiv1alpha1.OpenstackProviderSpec {
+		spec := machinebuilder.OpenStackProviderSpec().Build()
+		spec.Networks[0].Subnets[0] = mapiv1alpha1.SubnetParam{UUID: "810c3d97-98c2-4cf3-b0f6-8977b6e0b4b2"}
+
+		return spec
+	}
+
+	Context("when converting a MAPI MachineSet to CAPI and back", func() {
+		DescribeTable("should convert the networks and ports unchanged",
+			func(modify func(*mapiv1alpha1.OpenstackProviderSpec)) {
+				spec := baseProviderSpec()
+				modify(spec)
+
+				raw, err := json.Marshal(spec)
+				Expect(err).ToNot(HaveOccurred())
+
+				mapiMachineSet := machinebuilder.MachineSet().WithName("foo").
+					WithProviderSpec(machinev1beta1.ProviderSpec{Value: &runtime.RawExtension{Raw: raw}}).Build()
+
+				capiMachineSet, capoTemplate, _, err := mapi2capi.FromOpenStackMachineSetAndInfra(mapiMachineSet, infra).ToMachineSetAndMachineTemplate()
+				Expect(err).ToNot(HaveOccurred())
+
+				roundTripMachineSet, _, err := capi2mapi.FromMachineSetAndOpenStackMachineTemplateAndOpenStackCluster(
+					capiMachineSet, capoTemplate.(*capov1.OpenStackMachineTemplate), openStackCluster,
+				).ToMachineSet()
+				Expect(err).ToNot(HaveOccurred())
+
+				roundTripSpec := &mapiv1alpha1.OpenstackProviderSpec{}
+				Expect(yaml.Unmarshal(roundTripMachineSet.Spec.Template.Spec.ProviderSpec.Value.Raw, roundTripSpec)).To(Succeed())
+				Expect(roundTripSpec).To(Equal(spec))
+			},
+			Entry("with a single network", func(*mapiv1alpha1.OpenstackProviderSpec) {}),
+			Entry("with a dual-NIC configuration", func(spec *mapiv1alpha1.OpenstackProviderSpec) {
+				spec.Networks = append(spec.Networks, mapiv1alpha1.NetworkParam{
+					Filter: mapiv1alpha1.Filter{Name: "storage", Tags: "openshiftClusterID=test-cluster,storage"},
+					Subnets: []mapiv1alpha1.SubnetParam{{
+						Filter: mapiv1alpha1.SubnetFilter{Name: "storage-subnet", IPVersion: 4},
+					}},
+					PortTags: []string{"secondary"},
+				})
+			}),
+			Entry("with an SR-IOV port", func(spec *mapiv1alpha1.OpenstackProviderSpec) {
+				spec.Trunk = false
+				spec.Ports = []mapiv1alpha1.PortOpts{{
+					NetworkID:    "2a5b8a6e-8f3b-4c6e-9e0a-2c1f8c3f5d11",
+					NameSuffix:   "sriov",
+					FixedIPs:     []mapiv1alpha1.FixedIPs{{SubnetID: "5f1c0a9e-5d5b-4d3e-8f2a-6b9c7d0e1f23", IPAddress: "192.168.10.5"}},
+					Tags:         []string{"sriov"},
+					VNICType:     "direct",
+					Profile:      map[string]string{"trusted": "true", "capabilities": `["switchdev"]`},
+					PortSecurity: ptr.To(false),
+				}}
+			}),
+			Entry("with another clouds secret", func(spec *mapiv1alpha1.OpenstackProviderSpec) {
+				spec.CloudsSecret.Name = "tenant-credentials"
+				spec.CloudName = "tenant"
+			}),
+		)
+	})
+
+	DescribeTable("capi2mapi OpenStack convert CAPI MachineSet/InfraMachineTemplate/InfraCluster to a MAPI MachineSet",
+		func(ports []capov1.PortOpts, expectedErrors []string) {
+			capiMachineSet := capibuilder.MachineSet().WithName("foo").WithClusterName("sample-cluster-name").Build()
+			capoTemplate := &capov1.OpenStackMachineTemplate{
+				Spec: capov1.OpenStackMachineTemplateSpec{
+					Template: capov1.OpenStackMachineTemplateResource{
+						Spec: capov1.OpenStackMachineSpec{
+							Flavor: ptr.To("m1.large"),
+							Image:  capov1.ImageParam{Filter: &capov1.ImageFilter{Name: ptr.To("rhcos")}},
+							Ports:  ports,
+						},
+					},
+				},
+			}
+
+			_, _, err := capi2mapi.FromMachineSetAndOpenStackMachineTemplateAndOpenStackCluster(capiMachineSet, capoTemplate, openStackCluster).ToMachineSet()
+			Expect(err).To(matchers.ConsistOfMatchErrorSubstrings(expectedErrors))
+		},
+		Entry("with a port on a network selected by filter after a port", []capov1.PortOpts{
+			{Network: &capov1.NetworkParam{ID: ptr.To("network")}, NameSuffix: ptr.To("first")},
+			{Network: &capov1.NetworkParam{Filter: &capov1.NetworkFilter{Name: "storage"}}},
+		}, []string{
+			"the network of a port must be referenced by ID when the port cannot be represented as a Machine API network",
+		}),
+		Entry("with a port propagating its uplink status", []capov1.PortOpts{
+			{
+				Network:                &capov1.NetworkParam{ID: ptr.To("network")},
+				ResolvedPortSpecFields: capov1.ResolvedPortSpecFields{PropagateUplinkStatus: ptr.To(true)},
+			},
+		}, []string{
+			"spec.ports[0].propagateUplinkStatus: Invalid value: true: propagateUplinkStatus is not supported",
+		}),
+		Entry("with a port with value specs", []capov1.PortOpts{
+			{
+				Network:                &capov1.NetworkParam{ID: ptr.To("network")},
+				ResolvedPortSpecFields: capov1.ResolvedPortSpecFields{ValueSpecs: []capov1.ValueSpec{{Name: "foo", Key: "bar", Value: "baz"}}},
+			},
+		}, []string{
+			"spec.ports[0].valueSpecs: Invalid value: []v1beta1.ValueSpec{v1beta1.ValueSpec{Name:\"foo\", Key:\"bar\", Value:\"baz\"}}: valueSpecs are not supported",
+		}),
+	)
+
+	It("should reject the identityRef overriding the region", func() {
+		capiMachineSet := capibuilder.MachineSet().WithName("foo").WithClusterName("sample-cluster-name").Build()
+		capoTemplate := &capov1.OpenStackMachineTemplate{
+			Spec: capov1.OpenStackMachineTemplateSpec{
+				Template: capov1.OpenStackMachineTemplateResource{
+					Spec: capov1.OpenStackMachineSpec{
+						Flavor:      ptr.To("m1.large"),
+						Image:       capov1.ImageParam{Filter: &capov1.ImageFilter{Name: ptr.To("rhcos")}},
+						IdentityRef: &capov1.OpenStackIdentityReference{Name: "tenant-credentials", CloudName: "tenant", Region: "regionTwo"},
+					},
+				},
+			},
+		}
+
+		_, _, err := capi2mapi.FromMachineSetAndOpenStackMachineTemplateAndOpenStackCluster(capiMachineSet, capoTemplate, openStackCluster).ToMachineSet()
+		Expect(err).To(matchers.ConsistOfMatchErrorSubstrings([]string{
+			"spec.identityRef.region: Invalid value: \"regionTwo\": region is not supported",
+		}))
+	})
+})
@@ -0,0 +1,125 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capi2mapi
+
+import (
+	"fmt"
+
+	mapiv1 "github.com/openshift/api/machine/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	capiv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+const (
+	machineSetKind        = "MachineSet"
+	machineDeploymentKind = "MachineDeployment"
+	machinePoolKind       = "MachinePool"
+
+	// controlPlaneGroup is the API group of the CAPI control plane providers, such as the KubeadmControlPlane.
+	controlPlaneGroup = "controlplane.cluster.x-k8s.io"
+)
+
+// MachineOwners are the MachineSets a CAPI Machine owned by a CAPI MachineSet is mirrored with. The owner references
+// are converted across the CAPI and MAPI namespaces, and so have to be resolved to the UID of the MAPI MachineSet.
+type MachineOwners struct {
+	// CAPIMachineSet is the CAPI MachineSet the Machine references as its owner, nil when it does not exist.
+	CAPIMachineSet *capiv1.MachineSet
+	// MAPIMachineSet is the MAPI MachineSet of the same name, nil when the CAPI MachineSet is not mirrored.
+	MAPIMachineSet *mapiv1.MachineSet
+}
+
+// ConvertCAPIMachineOwnerReferencesToMAPI converts the owner references of a CAPI Machine to those of its MAPI mirror.
+//
+// The CAPI MachineSet owning the Machine is converted to the MAPI MachineSet of the same name. The owners which no
+// longer exist, or which have no MAPI mirror, are orphaned: their references are dropped with a warning, as the
+// garbage collector would otherwise delete the MAPI Machine. The owners which have no MAPI equivalent, such as the
+// MachineDeployments and the control planes, are rejected.
+func ConvertCAPIMachineOwnerReferencesToMAPI(fldPath *field.Path, ownerReferences []metav1.OwnerReference, owners MachineOwners) ([]metav1.OwnerReference, []string, field.ErrorList) {
+	var (
+		mapiOwnerReferences []metav1.OwnerReference
+		warnings            []string
+		errs                field.ErrorList
+	)
+
+	for i, ref := range ownerReferences {
+		refPath := fldPath.Index(i)
+
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil {
+			errs = append(errs, field.Invalid(refPath.Child("apiVersion"), ref.APIVersion, err.Error()))
+			continue
+		}
+
+		switch {
+		case gv.Group == capiv1.GroupVersion.Group && ref.Kind == machineSetKind:
+			mapiRef, warning, err := convertCAPIMachineSetOwnerReferenceToMAPI(refPath, ref, owners)
+			if err != nil {
+				errs = append(errs, err)
+			} else if warning != "" {
+				warnings = append(warnings, warning)
+			} else {
+				mapiOwnerReferences = append(mapiOwnerReferences, mapiRef)
+			}
+		case gv.Group == capiv1.GroupVersion.Group && ref.Kind == machineDeploymentKind:
+			errs = append(errs, field.Forbidden(refPath, fmt.Sprintf("Machines owned by the MachineDeployment %s cannot be converted, "+
+				"MachineDeployments have no Machine API equivalent", ref.Name)))
+		case gv.Group == capiv1.GroupVersion.Group && ref.Kind == machinePoolKind:
+			errs = append(errs, field.Forbidden(refPath, fmt.Sprintf("Machines owned by the MachinePool %s cannot be converted, "+
+				"MachinePools have no Machine API equivalent", ref.Name)))
+		case gv.Group == controlPlaneGroup:
+			errs = append(errs, field.Forbidden(refPath, fmt.Sprintf("Machines owned by the %s %s cannot be converted, "+
+				"control plane Machines are not managed through the Machine API MachineSets", ref.Kind, ref.Name)))
+		default:
+			errs = append(errs, field.NotSupported(refPath.Child("kind"), fmt.Sprintf("%s, %s", ref.APIVersion, ref.Kind),
+				[]string{fmt.Sprintf("%s, %s", capiv1.GroupVersion.String(), machineSetKind)}))
+		}
+	}
+
+	return mapiOwnerReferences, warnings, errs
+}
+
+// convertCAPIMachineSetOwnerReferenceToMAPI converts the reference to the CAPI MachineSet owning a Machine to the
+// reference to its MAPI mirror. It returns a warning when the owner is orphaned, and the reference dropped.
+func convertCAPIMachineSetOwnerReferenceToMAPI(fldPath *field.Path, ref metav1.OwnerReference, owners MachineOwners) (metav1.OwnerReference, string, *field.Error) {
+	capiMachineSet := owners.CAPIMachineSet
+	if capiMachineSet == nil || capiMachineSet.Name != ref.Name || capiMachineSet.UID != ref.UID {
+		return metav1.OwnerReference{}, field.Invalid(fldPath, ref.Name, "the owning MachineSet no longer exists, the owner reference is dropped").Error(), nil
+	}
+
+	// The MachineSets of a MachineDeployment are not mirrored, their Machines are owned by the MachineDeployment.
+	for _, machineSetRef := range capiMachineSet.OwnerReferences {
+		if gv, err := schema.ParseGroupVersion(machineSetRef.APIVersion); err == nil && gv.Group == capiv1.GroupVersion.Group && machineSetRef.Kind == machineDeploymentKind {
+			return metav1.OwnerReference{}, "", field.Forbidden(fldPath, fmt.Sprintf("Machines of the MachineSet %s, owned by the MachineDeployment %s, cannot be converted, "+
+				"MachineDeployments have no Machine API equivalent", capiMachineSet.Name, machineSetRef.Name))
+		}
+	}
+
+	mapiMachineSet := owners.MAPIMachineSet
+	if mapiMachineSet == nil || mapiMachineSet.Name != ref.Name {
+		return metav1.OwnerReference{}, field.Invalid(fldPath, ref.Name, "the owning MachineSet has no Machine API mirror, the owner reference is dropped").Error(), nil
+	}
+
+	return metav1.OwnerReference{
+		APIVersion:         mapiv1.GroupVersion.String(),
+		Kind:               machineSetKind,
+		Name:               mapiMachineSet.Name,
+		UID:                mapiMachineSet.UID,
+		Controller:         ref.Controller,
+		BlockOwnerDeletion: ref.BlockOwnerDeletion,
+	}, "", nil
+}
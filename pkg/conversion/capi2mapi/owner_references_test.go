@@ -0,0 +1,160 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capi2mapi
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	mapiv1 "github.com/openshift/api/machine/v1beta1"
+	capibuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/core/v1beta1"
+	mapibuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	"github.com/openshift/cluster-capi-operator/pkg/conversion/test/matchers"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	capiv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+var _ = Describe("capi2mapi Machine owner references conversion", func() {
+	const (
+		capiMachineSetUID types.UID = "capi-machine-set-uid"
+		mapiMachineSetUID types.UID = "mapi-machine-set-uid"
+	)
+
+	capiMachineSet := func() *capiv1.MachineSet {
+		machineSet := capibuilder.MachineSet().WithName("worker").Build()
+		machineSet.UID = capiMachineSetUID
+
+		return machineSet
+	}
+
+	mapiMachineSet := func() *mapiv1.MachineSet {
+		machineSet := mapibuilder.MachineSet().WithName("worker").Build()
+		machineSet.UID = mapiMachineSetUID
+
+		return machineSet
+	}
+
+	ownedByMachineDeployment := func() *capiv1.MachineSet {
+		machineSet := capiMachineSet()
+		machineSet.OwnerReferences = []metav1.OwnerReference{{APIVersion: capiv1.GroupVersion.String(), Kind: "MachineDeployment", Name: "worker-md"}}
+
+		return machineSet
+	}
+
+	machineSetOwner := metav1.OwnerReference{
+		APIVersion:         capiv1.GroupVersion.String(),
+		Kind:               "MachineSet",
+		Name:               "worker",
+		UID:                capiMachineSetUID,
+		Controller:         ptr.To(true),
+		BlockOwnerDeletion: ptr.To(true),
+	}
+
+	type ownerReferencesConversionInput struct {
+		ownerReferences []metav1.OwnerReference
+		owners          MachineOwners
+		expected        []metav1.OwnerReference
+		expectedErrors  []string
+		expectedWarns   []string
+	}
+
+	DescribeTable("should convert the owner references of a CAPI Machine",
+		func(in ownerReferencesConversionInput) {
+			ownerReferences, warns, errs := ConvertCAPIMachineOwnerReferencesToMAPI(field.NewPath("metadata", "ownerReferences"), in.ownerReferences, in.owners)
+
+			Expect(ownerReferences).To(Equal(in.expected))
+			Expect(errs.ToAggregate()).To(matchers.ConsistOfMatchErrorSubstrings(in.expectedErrors))
+			Expect(warns).To(matchers.ConsistOfSubstrings(in.expectedWarns))
+		},
+		Entry("Without owners", ownerReferencesConversionInput{}),
+		Entry("With a mirrored MachineSet", ownerReferencesConversionInput{
+			ownerReferences: []metav1.OwnerReference{machineSetOwner},
+			owners:          MachineOwners{CAPIMachineSet: capiMachineSet(), MAPIMachineSet: mapiMachineSet()},
+			expected: []metav1.OwnerReference{{
+				APIVersion:         mapiv1.GroupVersion.String(),
+				Kind:               "MachineSet",
+				Name:               "worker",
+				UID:                mapiMachineSetUID,
+				Controller:         ptr.To(true),
+				BlockOwnerDeletion: ptr.To(true),
+			}},
+		}),
+		Entry("With a MachineSet which is not mirrored", ownerReferencesConversionInput{
+			ownerReferences: []metav1.OwnerReference{machineSetOwner},
+			owners:          MachineOwners{CAPIMachineSet: capiMachineSet()},
+			expectedWarns:   []string{"metadata.ownerReferences[0]: Invalid value: \"worker\": the owning MachineSet has no Machine API mirror, the owner reference is dropped"},
+		}),
+		Entry("With a MachineSet which no longer exists", ownerReferencesConversionInput{
+			ownerReferences: []metav1.OwnerReference{machineSetOwner},
+			owners:          MachineOwners{MAPIMachineSet: mapiMachineSet()},
+			expectedWarns:   []string{"metadata.ownerReferences[0]: Invalid value: \"worker\": the owning MachineSet no longer exists, the owner reference is dropped"},
+		}),
+		Entry("With a MachineSet which was recreated under the same name", ownerReferencesConversionInput{
+			ownerReferences: []metav1.OwnerReference{{APIVersion: capiv1.GroupVersion.String(), Kind: "MachineSet", Name: "worker", UID: "previous-uid"}},
+			owners:          MachineOwners{CAPIMachineSet: capiMachineSet(), MAPIMachineSet: mapiMachineSet()},
+			expectedWarns:   []string{"metadata.ownerReferences[0]: Invalid value: \"worker\": the owning MachineSet no longer exists, the owner reference is dropped"},
+		}),
+		Entry("With a MachineSet owned by a MachineDeployment", ownerReferencesConversionInput{
+			ownerReferences: []metav1.OwnerReference{machineSetOwner},
+			owners:          MachineOwners{CAPIMachineSet: ownedByMachineDeployment(), MAPIMachineSet: mapiMachineSet()},
+			expectedErrors:  []string{"metadata.ownerReferences[0]: Forbidden: Machines of the MachineSet worker, owned by the MachineDeployment worker-md, cannot be converted"},
+		}),
+		Entry("With a MachineDeployment", ownerReferencesConversionInput{
+			ownerReferences: []metav1.OwnerReference{{APIVersion: capiv1.GroupVersion.String(), Kind: "MachineDeployment", Name: "worker-md"}},
+			expectedErrors:  []string{"metadata.ownerReferences[0]: Forbidden: Machines owned by the MachineDeployment worker-md cannot be converted"},
+		}),
+		Entry("With a MachinePool", ownerReferencesConversionInput{
+			ownerReferences: []metav1.OwnerReference{{APIVersion: "cluster.x-k8s.io/v1beta1", Kind: "MachinePool", Name: "worker-mp"}},
+			expectedErrors:  []string{"metadata.ownerReferences[0]: Forbidden: Machines owned by the MachinePool worker-mp cannot be converted"},
+		}),
+		Entry("With a KubeadmControlPlane", ownerReferencesConversionInput{
+			ownerReferences: []metav1.OwnerReference{{APIVersion: "controlplane.cluster.x-k8s.io/v1beta1", Kind: "KubeadmControlPlane", Name: "control-plane"}},
+			expectedErrors:  []string{"metadata.ownerReferences[0]: Forbidden: Machines owned by the KubeadmControlPlane control-plane cannot be converted"},
+		}),
+		Entry("With another control plane provider", ownerReferencesConversionInput{
+			ownerReferences: []metav1.OwnerReference{{APIVersion: "controlplane.cluster.x-k8s.io/v1beta2", Kind: "AWSManagedControlPlane", Name: "control-plane"}},
+			expectedErrors:  []string{"metadata.ownerReferences[0]: Forbidden: Machines owned by the AWSManagedControlPlane control-plane cannot be converted"},
+		}),
+		Entry("With a MAPI MachineSet", ownerReferencesConversionInput{
+			ownerReferences: []metav1.OwnerReference{{APIVersion: mapiv1.GroupVersion.String(), Kind: "MachineSet", Name: "worker"}},
+			expectedErrors:  []string{"metadata.ownerReferences[0].kind: Unsupported value: \"machine.openshift.io/v1beta1, MachineSet\""},
+		}),
+		Entry("With an unknown owner", ownerReferencesConversionInput{
+			ownerReferences: []metav1.OwnerReference{{APIVersion: "v1", Kind: "ConfigMap", Name: "foo"}},
+			expectedErrors:  []string{"metadata.ownerReferences[0].kind: Unsupported value: \"v1, ConfigMap\""},
+		}),
+		Entry("With an invalid API version", ownerReferencesConversionInput{
+			ownerReferences: []metav1.OwnerReference{{APIVersion: "a/b/c", Kind: "MachineSet", Name: "worker"}},
+			expectedErrors:  []string{"metadata.ownerReferences[0].apiVersion: Invalid value: \"a/b/c\""},
+		}),
+		Entry("With a mirrored MachineSet and an unknown owner", ownerReferencesConversionInput{
+			ownerReferences: []metav1.OwnerReference{machineSetOwner, {APIVersion: "v1", Kind: "ConfigMap", Name: "foo"}},
+			owners:          MachineOwners{CAPIMachineSet: capiMachineSet(), MAPIMachineSet: mapiMachineSet()},
+			expected: []metav1.OwnerReference{{
+				APIVersion:         mapiv1.GroupVersion.String(),
+				Kind:               "MachineSet",
+				Name:               "worker",
+				UID:                mapiMachineSetUID,
+				Controller:         ptr.To(true),
+				BlockOwnerDeletion: ptr.To(true),
+			}},
+			expectedErrors: []string{"metadata.ownerReferences[1].kind: Unsupported value: \"v1, ConfigMap\""},
+		}),
+	)
+})
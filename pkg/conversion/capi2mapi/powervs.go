@@ -22,6 +22,7 @@ import (
 
 	mapiv1 "github.com/openshift/api/machine/v1"
 	mapiv1beta1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-capi-operator/pkg/errorsx"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -44,7 +45,7 @@ type machineAndPowerVSMachineAndPowerVSCluster struct {
 	powerVSCluster *capibmv1.IBMPowerVSCluster
 }
 
-// machineSetAndPowerVSMachineTemplateAndPowerVSCluster stores the details of a Cluster API MachineSet and PowerVSMachineTemplate and AWSCluster.
+// machineSetAndPowerVSMachineTemplateAndPowerVSCluster stores the details of a Cluster API MachineSet and PowerVSMachineTemplate and PowerVSCluster.
 type machineSetAndPowerVSMachineTemplateAndPowerVSCluster struct {
 	machineSet     *capiv1.MachineSet
 	template       *capibmv1.IBMPowerVSMachineTemplate
@@ -57,7 +58,7 @@ func FromMachineAndPowerVSMachineAndPowerVSCluster(m *capiv1.Machine, pm *capibm
 	return &machineAndPowerVSMachineAndPowerVSCluster{machine: m, powerVSMachine: pm, powerVSCluster: pc}
 }
 
-// FromMachineSetAndPowerVSMachineTemplateAndPowerVSCluster wraps a CAPI MachineSet and CAPIBM PowerVSMachineTemplate and CAPIBM PowerVSCluster into a capi2mapi MachineSetAndAWSMachineTemplateAndAWSCluster.
+// FromMachineSetAndPowerVSMachineTemplateAndPowerVSCluster wraps a CAPI MachineSet and CAPIBM PowerVSMachineTemplate and CAPIBM PowerVSCluster into a capi2mapi MachineSetAndMachineTemplate.
 func FromMachineSetAndPowerVSMachineTemplateAndPowerVSCluster(ms *capiv1.MachineSet, mts *capibmv1.IBMPowerVSMachineTemplate, pc *capibmv1.IBMPowerVSCluster) MachineSetAndMachineTemplate {
 	return machineSetAndPowerVSMachineTemplateAndPowerVSCluster{
 		machineSet:     ms,
@@ -108,7 +109,7 @@ func (m machineAndPowerVSMachineAndPowerVSCluster) ToMachine() (*mapiv1beta1.Mac
 	mapiMachine.Spec.ProviderSpec.Value = powerVSRawExt
 
 	if len(errors) > 0 {
-		return nil, warnings, errors.ToAggregate()
+		return nil, warnings, errorsx.UnsupportedFields(errors.ToAggregate())
 	}
 
 	return mapiMachine, warnings, nil
@@ -145,10 +146,10 @@ func (m machineSetAndPowerVSMachineTemplateAndPowerVSCluster) ToMachineSet() (*m
 
 	// Copy the labels and annotations from the Machine to the template.
 	mapiMachineSet.Spec.Template.ObjectMeta.Annotations = mapiPowerVSMachine.ObjectMeta.Annotations
-	mapiMachineSet.Spec.Template.ObjectMeta.Labels = mapiPowerVSMachine.ObjectMeta.Labels
+	mapiMachineSet.Spec.Template.ObjectMeta.Labels = mapiMachineSetTemplateLabels(m.machineSet, mapiPowerVSMachine.ObjectMeta.Labels)
 
 	if len(errs) > 0 {
-		return nil, warnings, utilerrors.NewAggregate(errs)
+		return nil, warnings, errorsx.UnsupportedFields(utilerrors.NewAggregate(errs))
 	}
 
 	return mapiMachineSet, warnings, nil
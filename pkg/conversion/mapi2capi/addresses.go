@@ -0,0 +1,54 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mapi2capi
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	capiv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// mapiToCAPIAddressTypes maps the MAPI Machine address types, which are the Node address types, to the CAPI ones.
+var mapiToCAPIAddressTypes = map[corev1.NodeAddressType]capiv1.MachineAddressType{ //nolint:gochecknoglobals
+	corev1.NodeHostName:    capiv1.MachineHostName,
+	corev1.NodeExternalIP:  capiv1.MachineExternalIP,
+	corev1.NodeInternalIP:  capiv1.MachineInternalIP,
+	corev1.NodeExternalDNS: capiv1.MachineExternalDNS,
+	corev1.NodeInternalDNS: capiv1.MachineInternalDNS,
+}
+
+// convertMAPIMachineAddressesToCAPI translates the MAPI Machine addresses to CAPI Machine addresses.
+// Every address is kept, in the same order, so that the IPv4 and IPv6 addresses of a dual-stack machine keep the
+// order the provider reported them in, the first address of a type being the primary one. The address types without
+// a CAPI equivalent are kept as is.
+func convertMAPIMachineAddressesToCAPI(mapiAddresses []corev1.NodeAddress) capiv1.MachineAddresses {
+	if len(mapiAddresses) == 0 {
+		return nil
+	}
+
+	capiAddresses := make(capiv1.MachineAddresses, 0, len(mapiAddresses))
+
+	for _, mapiAddress := range mapiAddresses {
+		addressType, ok := mapiToCAPIAddressTypes[mapiAddress.Type]
+		if !ok {
+			addressType = capiv1.MachineAddressType(mapiAddress.Type)
+		}
+
+		capiAddresses = append(capiAddresses, capiv1.MachineAddress{Type: addressType, Address: mapiAddress.Address})
+	}
+
+	return capiAddresses
+}
@@ -24,6 +24,8 @@ import (
 
 	configv1 "github.com/openshift/api/config/v1"
 	mapiv1 "github.com/openshift/api/machine/v1beta1"
+	conversionutil "github.com/openshift/cluster-capi-operator/pkg/conversion/util"
+	"github.com/openshift/cluster-capi-operator/pkg/errorsx"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -36,8 +38,14 @@ import (
 	"sigs.k8s.io/yaml"
 )
 
+// awsCredentialsSecretName is the credentials secret of the cluster, used by the Machine API machines.
+const awsCredentialsSecretName = "aws-cloud-credentials"
+
 var (
 	errUnexpectedObjectTypeForMachine = errors.New("unexpected type for capaMachineObj")
+
+	// awsSpotMaxPriceRegex matches the spot max prices accepted by CAPA.
+	awsSpotMaxPriceRegex = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
 )
 
 // awsMachineAndInfra stores the details of a Machine API AWSMachine and Infra.
@@ -65,7 +73,9 @@ func FromAWSMachineSetAndInfra(m *mapiv1.MachineSet, i *configv1.Infrastructure)
 		infrastructure: i,
 		awsMachineAndInfra: &awsMachineAndInfra{
 			machine: &mapiv1.Machine{
-				Spec: m.Spec.Template.Spec,
+				// The Machines of the MachineSet have the template labels, which the labels back-filled on the Machine must not override.
+				ObjectMeta: metav1.ObjectMeta{Labels: m.Spec.Template.Labels},
+				Spec:       m.Spec.Template.Spec,
 			},
 			infrastructure: i,
 		},
@@ -78,7 +88,7 @@ func (m *awsMachineAndInfra) ToMachineAndInfrastructureMachine() (*capiv1.Machin
 	capiMachine, capaMachine, warnings, errs := m.toMachineAndInfrastructureMachine()
 
 	if len(errs) > 0 {
-		return nil, nil, warnings, errs.ToAggregate()
+		return nil, nil, warnings, errorsx.UnsupportedFields(errs.ToAggregate())
 	}
 
 	return capiMachine, capaMachine, warnings, nil
@@ -133,6 +143,15 @@ func (m *awsMachineAndInfra) toMachineAndInfrastructureMachine() (*capiv1.Machin
 		errs = append(errs, field.Invalid(field.NewPath("infrastructure", "status", "infrastructureName"), m.infrastructure.Status.InfrastructureName, "infrastructure cannot be nil and infrastructure.Status.InfrastructureName cannot be empty"))
 	} else {
 		capiMachine.Spec.ClusterName = m.infrastructure.Status.InfrastructureName
+		// The machine.openshift.io labels the MAPI Machine lacks are back-filled, so that MAPI tooling keeps matching it.
+		capiMachine.Labels = conversionutil.BackfillMAPIMachineLabels(capiMachine.Labels, capiMachine.Spec.ClusterName, "")
+	}
+
+	// TODO(OCPCLOUD-2708): Convert the network interface type to the AWSMachine spec once CAPA is bumped to a version supporting EFA.
+	if awsProviderConfig.NetworkInterfaceType == mapiv1.AWSEFANetworkInterfaceType {
+		capiMachine.Annotations = mergeMaps(capiMachine.Annotations, map[string]string{
+			conversionutil.AWSNetworkInterfaceTypeAnnotation: string(mapiv1.AWSEFANetworkInterfaceType),
+		})
 	}
 
 	// The InfraMachine should always have the same labels and annotations as the Machine.
@@ -187,10 +206,12 @@ func (m *awsMachineSetAndInfra) ToMachineSetAndMachineTemplate() (*capiv1.Machin
 	} else {
 		capiMachineSet.Spec.Template.Spec.ClusterName = m.infrastructure.Status.InfrastructureName
 		capiMachineSet.Spec.ClusterName = m.infrastructure.Status.InfrastructureName
+
+		backfillCAPIMachineSetLabels(capiMachineSet)
 	}
 
 	if len(errs) > 0 {
-		return nil, nil, warnings, utilerrors.NewAggregate(errs)
+		return nil, nil, warnings, errorsx.UnsupportedFields(utilerrors.NewAggregate(errs))
 	}
 
 	return capiMachineSet, capaMachineTemplate, warnings, nil
@@ -225,6 +246,27 @@ func (m *awsMachineAndInfra) toAWSMachine(providerSpec mapiv1.AWSMachineProvider
 		errs = append(errs, err)
 	}
 
+	tenancy, err := convertAWSTenancyToCAPI(fldPath.Child("placement", "tenancy"), providerSpec.Placement.Tenancy)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	placementGroupPartition, err := convertAWSPlacementGroupPartitionToCAPI(fldPath.Child("placementGroupPartition"), providerSpec.PlacementGroupName, providerSpec.PlacementGroupPartition)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	spotMarketOptions, err := convertAWSSpotMarketOptionsToCAPI(fldPath.Child("spotMarketOptions"), providerSpec.SpotMarketOptions)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	warnings = append(warnings, ignoredAWSResourceReferenceARNWarnings(fldPath.Child("subnet"), providerSpec.Subnet)...)
+
+	for i, securityGroup := range providerSpec.SecurityGroups {
+		warnings = append(warnings, ignoredAWSResourceReferenceARNWarnings(fldPath.Child("securityGroups").Index(i), securityGroup)...)
+	}
+
 	spec := capav1.AWSMachineSpec{
 		AMI:                      capiAWSAMIReference,
 		AdditionalSecurityGroups: convertAWSSecurityGroupstoCAPI(providerSpec.SecurityGroups),
@@ -245,15 +287,15 @@ func (m *awsMachineAndInfra) toAWSMachine(providerSpec mapiv1.AWSMachineProvider
 		InstanceType:            providerSpec.InstanceType,
 		NonRootVolumes:          nonRootVolumes,
 		PlacementGroupName:      providerSpec.PlacementGroupName,
-		PlacementGroupPartition: int64(ptr.Deref(providerSpec.PlacementGroupPartition, 0)),
+		PlacementGroupPartition: placementGroupPartition,
 		// ProviderID. This is populated when this is called in higher level funcs (ToMachine(), ToMachineSet()).
 		// InstanceID. This is populated when this is called in higher level funcs (ToMachine(), ToMachineSet()).
 		PublicIP:          providerSpec.PublicIP,
 		RootVolume:        rootVolume,
 		SSHKeyName:        providerSpec.KeyName,
-		SpotMarketOptions: convertAWSSpotMarketOptionsToCAPI(providerSpec.SpotMarketOptions),
+		SpotMarketOptions: spotMarketOptions,
 		Subnet:            convertAWSResourceReferenceToCAPI(providerSpec.Subnet),
-		Tenancy:           string(providerSpec.Placement.Tenancy),
+		Tenancy:           tenancy,
 		// UncompressedUserData: Not used in OpenShift.
 	}
 
@@ -264,7 +306,12 @@ func (m *awsMachineAndInfra) toAWSMachine(providerSpec mapiv1.AWSMachineProvider
 	// Unused fields - Below this line are fields not used from the MAPI AWSMachineProviderConfig.
 
 	// TypeMeta - Only for the purpose of the raw extension, not used for any functionality.
-	// CredentialsSecret - TODO(OCPCLOUD-2713): Work out what needs to happen regarding credentials secrets.
+
+	if providerSpec.CredentialsSecret != nil && providerSpec.CredentialsSecret.Name != awsCredentialsSecretName {
+		// The AWSMachines use the identity of the AWSCluster, the credentials cannot be selected per machine.
+		errs = append(errs, field.Invalid(fldPath.Child("credentialsSecret", "name"), providerSpec.CredentialsSecret.Name,
+			fmt.Sprintf("credentialsSecret must be %q, the machines use the credentials of the cluster", awsCredentialsSecretName)))
+	}
 
 	if m.infrastructure.Status.PlatformStatus != nil &&
 		m.infrastructure.Status.PlatformStatus.AWS != nil &&
@@ -287,9 +334,11 @@ func (m *awsMachineAndInfra) toAWSMachine(providerSpec mapiv1.AWSMachineProvider
 		errs = append(errs, field.Invalid(fldPath.Child("deviceIndex"), providerSpec.DeviceIndex, "deviceIndex must be 0 or unset"))
 	}
 
-	if providerSpec.NetworkInterfaceType != "" && providerSpec.NetworkInterfaceType != mapiv1.AWSENANetworkInterfaceType {
-		// TODO(OCPCLOUD-2708): We need to upstream the network interface choice to allow the elastic fabric adapter.
-		errs = append(errs, field.Invalid(fldPath.Child("networkInterfaceType"), providerSpec.NetworkInterfaceType, "networkInterface type must be one of ENA or omitted, unsupported value"))
+	switch providerSpec.NetworkInterfaceType {
+	case "", mapiv1.AWSENANetworkInterfaceType, mapiv1.AWSEFANetworkInterfaceType:
+		// EFA is carried by an annotation of the CAPI Machine, set by toMachineAndInfrastructureMachine.
+	default:
+		errs = append(errs, field.Invalid(fldPath.Child("networkInterfaceType"), providerSpec.NetworkInterfaceType, "networkInterface type must be one of ENA, EFA or omitted, unsupported value"))
 	}
 
 	if len(providerSpec.LoadBalancers) > 0 {
@@ -360,6 +409,35 @@ func convertAWSAMIResourceReferenceToCAPI(fldPath *field.Path, amiRef mapiv1.AWS
 	return capav1.AMIReference{}, field.Invalid(fldPath, amiRef, "unable to find a valid AMI resource reference")
 }
 
+// convertAWSTenancyToCAPI converts the MAPI instance tenancy to its CAPA equivalent, the values are the same in both APIs.
+// The host tenancy runs the instance on a dedicated host, which is picked by AWS in both APIs.
+func convertAWSTenancyToCAPI(fldPath *field.Path, mapiTenancy mapiv1.InstanceTenancy) (string, *field.Error) {
+	switch mapiTenancy {
+	case mapiv1.DefaultTenancy, mapiv1.DedicatedTenancy, mapiv1.HostTenancy, "":
+		return string(mapiTenancy), nil
+	default:
+		return "", field.NotSupported(fldPath, mapiTenancy, []string{string(mapiv1.DefaultTenancy), string(mapiv1.DedicatedTenancy), string(mapiv1.HostTenancy)})
+	}
+}
+
+// convertAWSPlacementGroupPartitionToCAPI converts the MAPI placement group partition, which is only valid
+// within a partition placement group, to its CAPA equivalent.
+func convertAWSPlacementGroupPartitionToCAPI(fldPath *field.Path, placementGroupName string, partition *int32) (int64, *field.Error) {
+	if partition == nil {
+		return 0, nil
+	}
+
+	if placementGroupName == "" {
+		return 0, field.Invalid(fldPath, *partition, "placementGroupPartition requires placementGroupName to be set")
+	}
+
+	if *partition < conversionutil.AWSMinPlacementGroupPartition || *partition > conversionutil.AWSMaxPlacementGroupPartition {
+		return 0, field.Invalid(fldPath, *partition, fmt.Sprintf("placementGroupPartition must be between %d and %d", conversionutil.AWSMinPlacementGroupPartition, conversionutil.AWSMaxPlacementGroupPartition))
+	}
+
+	return int64(*partition), nil
+}
+
 func convertAWSTagsToCAPI(mapiTags []mapiv1.TagSpecification) capav1.Tags {
 	capiTags := map[string]string{}
 	for _, tag := range mapiTags {
@@ -401,14 +479,25 @@ func convertIAMInstanceProfiletoCAPI(mapiIAM *mapiv1.AWSResourceReference) strin
 	return *mapiIAM.ID
 }
 
-func convertAWSSpotMarketOptionsToCAPI(mapiSpotMarketOptions *mapiv1.SpotMarketOptions) *capav1.SpotMarketOptions {
+// convertAWSSpotMarketOptionsToCAPI converts the MAPI spot market options to their CAPA equivalent.
+// An empty max price defaults to the on-demand price in MAPA, as an unset one does in CAPA.
+func convertAWSSpotMarketOptionsToCAPI(fldPath *field.Path, mapiSpotMarketOptions *mapiv1.SpotMarketOptions) (*capav1.SpotMarketOptions, *field.Error) {
 	if mapiSpotMarketOptions == nil {
-		return nil
+		return nil, nil
 	}
 
-	return &capav1.SpotMarketOptions{
-		MaxPrice: mapiSpotMarketOptions.MaxPrice,
+	maxPrice := ptr.Deref(mapiSpotMarketOptions.MaxPrice, "")
+	if maxPrice == "" {
+		return &capav1.SpotMarketOptions{}, nil
+	}
+
+	if !awsSpotMaxPriceRegex.MatchString(maxPrice) {
+		return nil, field.Invalid(fldPath.Child("maxPrice"), maxPrice, "maxPrice must be a decimal number, e.g. \"0.25\"")
 	}
+
+	return &capav1.SpotMarketOptions{
+		MaxPrice: ptr.To(maxPrice),
+	}, nil
 }
 
 func convertAWSSecurityGroupstoCAPI(sgs []mapiv1.AWSResourceReference) []capav1.AWSResourceReference {
@@ -516,6 +605,17 @@ func convertKMSKeyToCAPI(kmsKey mapiv1.AWSResourceReference) string {
 	return ""
 }
 
+// ignoredAWSResourceReferenceARNWarnings reports the ARN of a MAPI subnet or security group reference, which is
+// dropped as CAPA cannot reference these resources by ARN. MAPA ignores these ARNs too, selecting the resources by
+// their ID or filters only, so that dropping it does not change where the machine is placed, e.g. on an Outposts subnet.
+func ignoredAWSResourceReferenceARNWarnings(fldPath *field.Path, mapiReference mapiv1.AWSResourceReference) []string {
+	if mapiReference.ARN == nil {
+		return nil
+	}
+
+	return []string{field.Invalid(fldPath.Child("arn"), *mapiReference.ARN, "arn is ignored by the Machine API and not supported by CAPI, reference the resource by id or filters instead").Error()}
+}
+
 func convertAWSResourceReferenceToCAPI(mapiReference mapiv1.AWSResourceReference) *capav1.AWSResourceReference {
 	return &capav1.AWSResourceReference{
 		ID:      mapiReference.ID,
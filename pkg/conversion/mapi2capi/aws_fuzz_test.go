@@ -16,7 +16,9 @@ limitations under the License.
 package mapi2capi_test
 
 import (
+	"fmt"
 	"strings"
+	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -29,6 +31,7 @@ import (
 	"github.com/openshift/cluster-capi-operator/pkg/conversion/mapi2capi"
 	conversiontest "github.com/openshift/cluster-capi-operator/pkg/conversion/test/fuzz"
 
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtimeserializer "k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/utils/ptr"
@@ -75,7 +78,7 @@ var _ = Describe("AWS Fuzz (mapi2capi)", func() {
 			mapi2capi.FromAWSMachineAndInfra,
 			fromMachineAndAWSMachineAndAWSCluster,
 			conversiontest.ObjectMetaFuzzerFuncs(mapiNamespace),
-			conversiontest.MAPIMachineFuzzerFuncs(&mapiv1.AWSMachineProviderConfig{}, awsProviderIDFuzzer),
+			conversiontest.MAPIMachineFuzzerFuncs(&mapiv1.AWSMachineProviderConfig{}, awsProviderIDFuzzer, infra.Status.InfrastructureName),
 			awsProviderSpecFuzzerFuncs,
 		)
 	})
@@ -98,13 +101,43 @@ var _ = Describe("AWS Fuzz (mapi2capi)", func() {
 			mapi2capi.FromAWSMachineSetAndInfra,
 			fromMachineSetAndAWSMachineTemplateAndAWSCluster,
 			conversiontest.ObjectMetaFuzzerFuncs(mapiNamespace),
-			conversiontest.MAPIMachineFuzzerFuncs(&mapiv1.AWSMachineProviderConfig{}, awsProviderIDFuzzer),
-			conversiontest.MAPIMachineSetFuzzerFuncs(),
+			conversiontest.MAPIMachineFuzzerFuncs(&mapiv1.AWSMachineProviderConfig{}, awsProviderIDFuzzer, infra.Status.InfrastructureName),
+			conversiontest.MAPIMachineSetFuzzerFuncs(infra.Status.InfrastructureName),
 			awsProviderSpecFuzzerFuncs,
 		)
 	})
 })
 
+// FuzzAWSMachineRoundTrip round trips fuzzed AWS Machines from MAPI to CAPI, replaying the seed corpus in
+// testdata/fuzz/FuzzAWSMachineRoundTrip on every run. Explore new inputs with:
+//
+//	go test ./pkg/conversion/mapi2capi -run '^$' -fuzz FuzzAWSMachineRoundTrip
+func FuzzAWSMachineRoundTrip(f *testing.F) {
+	infra := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			InfrastructureName: "sample-cluster-name",
+		},
+	}
+
+	conversiontest.MAPI2CAPIMachineRoundTripFuzzTarget(f, conversiontest.MAPI2CAPIMachineRoundTripTarget{
+		Scheme:        scheme,
+		Infra:         infra,
+		InfraCluster:  &capav1.AWSCluster{Spec: capav1.AWSClusterSpec{Region: "us-east-1"}},
+		MAPIConverter: mapi2capi.FromAWSMachineAndInfra,
+		CAPIConverter: func(machine *capiv1.Machine, infraMachine client.Object, infraCluster client.Object) capi2mapi.MachineAndInfrastructureMachine {
+			return capi2mapi.FromMachineAndAWSMachineAndAWSCluster(machine, infraMachine.(*capav1.AWSMachine), infraCluster.(*capav1.AWSCluster)) //nolint:forcetypeassert
+		},
+		FuzzerFuncs: []fuzzer.FuzzerFuncs{
+			conversiontest.ObjectMetaFuzzerFuncs(mapiNamespace),
+			conversiontest.MAPIMachineFuzzerFuncs(&mapiv1.AWSMachineProviderConfig{}, awsProviderIDFuzzer, infra.Status.InfrastructureName),
+			awsProviderSpecFuzzerFuncs,
+		},
+		Losses: []conversiontest.Loss{
+			{Field: "credentialsSecret", Reason: "CAPA reads the credentials of the AWSCluster, TODO(OCPCLOUD-2713)"},
+		},
+	})
+}
+
 func awsProviderIDFuzzer(c fuzz.Continue) string {
 	return "aws:///us-west-2a/i-" + strings.ReplaceAll(c.RandString(), "/", "")
 }
@@ -113,10 +146,8 @@ func awsProviderIDFuzzer(c fuzz.Continue) string {
 func awsProviderSpecFuzzerFuncs(codecs runtimeserializer.CodecFactory) []interface{} {
 	return []interface{}{
 		func(nit *mapiv1.AWSNetworkInterfaceType, c fuzz.Continue) {
-			// Use this value always as this field doesn't currently get converted.
-			// The default value is always true in CAPI.
-			// TODO(OCPCLOUD-2708): Make this randomly choose between the three valid values.
-			*nit = mapiv1.AWSENANetworkInterfaceType
+			// An omitted value is converted to the ENA default, so it doesn't round trip.
+			*nit = []mapiv1.AWSNetworkInterfaceType{mapiv1.AWSENANetworkInterfaceType, mapiv1.AWSEFANetworkInterfaceType}[c.Intn(2)]
 		},
 		func(amiRef *mapiv1.AWSResourceReference, c fuzz.Continue) {
 			var amiID string
@@ -172,6 +203,13 @@ func awsProviderSpecFuzzerFuncs(codecs runtimeserializer.CodecFactory) []interfa
 				*tenancy = ""
 			}
 		},
+		func(smo *mapiv1.SpotMarketOptions, c fuzz.Continue) {
+			// The max price is either unset or a decimal number, an empty max price is converted to an unset one.
+			smo.MaxPrice = nil
+			if c.RandBool() {
+				smo.MaxPrice = ptr.To(fmt.Sprintf("%d.%d", c.Intn(10), c.Intn(1000)))
+			}
+		},
 		func(msa *mapiv1.MetadataServiceAuthentication, c fuzz.Continue) {
 			switch c.Intn(3) {
 			case 0:
@@ -192,6 +230,13 @@ func awsProviderSpecFuzzerFuncs(codecs runtimeserializer.CodecFactory) []interfa
 			// region must match the input AWSCluster so force it here.
 			ps.Placement.Region = "us-east-1"
 
+			// The partition is only valid within a placement group, where it is between 1 and 7, or unset.
+			if ps.PlacementGroupName == "" || c.RandBool() {
+				ps.PlacementGroupPartition = nil
+			} else {
+				ps.PlacementGroupPartition = ptr.To(c.Int31n(7) + 1)
+			}
+
 			// Clear fields that are not supported in the provider spec.
 			ps.DeviceIndex = 0
 			ps.LoadBalancers = nil
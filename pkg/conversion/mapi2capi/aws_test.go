@@ -21,9 +21,12 @@ import (
 
 	mapiv1 "github.com/openshift/api/machine/v1beta1"
 	"github.com/openshift/cluster-capi-operator/pkg/conversion/test/matchers"
+	conversionutil "github.com/openshift/cluster-capi-operator/pkg/conversion/util"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/utils/ptr"
+	capav1 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -78,6 +81,13 @@ var _ = Describe("mapi2capi AWS conversion", func() {
 		}
 	}
 
+	var awsMAPIMachineWithPlacementGroup = func(name string, partition int32) machinebuilder.MachineBuilder {
+		spec := awsBaseProviderSpec.WithPlacementGroupName(name).Build()
+		spec.PlacementGroupPartition = ptr.To(partition)
+
+		return awsMAPIMachineBase.WithProviderSpec(mapiv1.ProviderSpec{Value: mustConvertAWSProviderSpecToRawExtension(spec)})
+	}
+
 	var _ = DescribeTable("mapi2capi AWS convert MAPI Machine",
 		func(in awsMAPI2CAPIConversionInput) {
 			_, _, warns, err := FromAWSMachineAndInfra(in.machineBuilder.Build(), in.infra).ToMachineAndInfrastructureMachine()
@@ -116,6 +126,16 @@ var _ = Describe("mapi2capi AWS conversion", func() {
 			},
 			expectedWarnings: []string{},
 		}),
+		Entry("With another credentials secret", awsMAPI2CAPIConversionInput{
+			machineBuilder: awsMAPIMachineBase.WithProviderSpecBuilder(
+				awsBaseProviderSpec.WithCredentialsSecret(&corev1.LocalObjectReference{Name: "tenant-credentials"}),
+			),
+			infra: infra,
+			expectedErrors: []string{
+				"spec.providerSpec.value.credentialsSecret.name: Invalid value: \"tenant-credentials\": credentialsSecret must be \"aws-cloud-credentials\", the machines use the credentials of the cluster",
+			},
+			expectedWarnings: []string{},
+		}),
 		Entry("With mismatched region", awsMAPI2CAPIConversionInput{
 			machineBuilder: awsMAPIMachineBase.WithProviderSpecBuilder(
 				awsBaseProviderSpec.WithRegion("us-west-2"),
@@ -143,13 +163,49 @@ var _ = Describe("mapi2capi AWS conversion", func() {
 			},
 			expectedWarnings: []string{},
 		}),
+		Entry("With unsupported tenancy", awsMAPI2CAPIConversionInput{
+			machineBuilder: awsMAPIMachineBase.WithProviderSpecBuilder(
+				awsBaseProviderSpec.WithPlacement(mapiv1.Placement{Tenancy: "shared"}),
+			),
+			infra: infra,
+			expectedErrors: []string{
+				"spec.providerSpec.value.placement.tenancy: Unsupported value: \"shared\": supported values: \"default\", \"dedicated\", \"host\"",
+			},
+			expectedWarnings: []string{},
+		}),
+		Entry("With placement group partition without placement group name", awsMAPI2CAPIConversionInput{
+			machineBuilder: awsMAPIMachineWithPlacementGroup("", 2),
+			infra:          infra,
+			expectedErrors: []string{
+				"spec.providerSpec.value.placementGroupPartition: Invalid value: 2: placementGroupPartition requires placementGroupName to be set",
+			},
+			expectedWarnings: []string{},
+		}),
+		Entry("With placement group partition out of range", awsMAPI2CAPIConversionInput{
+			machineBuilder: awsMAPIMachineWithPlacementGroup("pg", 8),
+			infra:          infra,
+			expectedErrors: []string{
+				"spec.providerSpec.value.placementGroupPartition: Invalid value: 8: placementGroupPartition must be between 1 and 7",
+			},
+			expectedWarnings: []string{},
+		}),
+		Entry("With invalid spot max price", awsMAPI2CAPIConversionInput{
+			machineBuilder: awsMAPIMachineBase.WithProviderSpecBuilder(
+				awsBaseProviderSpec.WithSpotMarketOptions(&mapiv1.SpotMarketOptions{MaxPrice: ptr.To("$1")}),
+			),
+			infra: infra,
+			expectedErrors: []string{
+				"spec.providerSpec.value.spotMarketOptions.maxPrice: Invalid value: \"$1\": maxPrice must be a decimal number, e.g. \"0.25\"",
+			},
+			expectedWarnings: []string{},
+		}),
 		Entry("With unsupported network interface type", awsMAPI2CAPIConversionInput{
 			machineBuilder: awsMAPIMachineBase.WithProviderSpecBuilder(
 				awsBaseProviderSpec.WithNetworkInterfaceType("unsupported-value"),
 			),
 			infra: infra,
 			expectedErrors: []string{
-				"spec.providerSpec.value.networkInterfaceType: Invalid value: \"unsupported-value\": networkInterface type must be one of ENA or omitted, unsupported value",
+				"spec.providerSpec.value.networkInterfaceType: Invalid value: \"unsupported-value\": networkInterface type must be one of ENA, EFA or omitted, unsupported value",
 			},
 			expectedWarnings: []string{},
 		}),
@@ -165,6 +221,32 @@ var _ = Describe("mapi2capi AWS conversion", func() {
 			},
 			expectedWarnings: []string{},
 		}),
+		Entry("With an Outposts subnet ARN reference", awsMAPI2CAPIConversionInput{
+			machineBuilder: awsMAPIMachineBase.WithProviderSpecBuilder(
+				awsBaseProviderSpec.WithSubnet(mapiv1.AWSResourceReference{
+					ID:  ptr.To("subnet-0123456789abcdef0"),
+					ARN: ptr.To("arn:aws:ec2:us-east-1:123456789012:subnet/subnet-0123456789abcdef0"),
+				}),
+			),
+			infra:          infra,
+			expectedErrors: []string{},
+			expectedWarnings: []string{
+				"spec.providerSpec.value.subnet.arn: Invalid value: \"arn:aws:ec2:us-east-1:123456789012:subnet/subnet-0123456789abcdef0\": arn is ignored by the Machine API and not supported by CAPI, reference the resource by id or filters instead",
+			},
+		}),
+		Entry("With a security group ARN reference", awsMAPI2CAPIConversionInput{
+			machineBuilder: awsMAPIMachineBase.WithProviderSpecBuilder(
+				awsBaseProviderSpec.WithSecurityGroups([]mapiv1.AWSResourceReference{
+					{ID: ptr.To("sg-0123456789abcdef0")},
+					{ARN: ptr.To("arn:aws:ec2:us-east-1:123456789012:security-group/sg-0123456789abcdef1")},
+				}),
+			),
+			infra:          infra,
+			expectedErrors: []string{},
+			expectedWarnings: []string{
+				"spec.providerSpec.value.securityGroups[1].arn: Invalid value: \"arn:aws:ec2:us-east-1:123456789012:security-group/sg-0123456789abcdef1\": arn is ignored by the Machine API and not supported by CAPI, reference the resource by id or filters instead",
+			},
+		}),
 		Entry("With AMI filters", awsMAPI2CAPIConversionInput{
 			machineBuilder: awsMAPIMachineBase.WithProviderSpecBuilder(
 				awsBaseProviderSpec.WithAMI(mapiv1.AWSResourceReference{
@@ -337,4 +419,109 @@ var _ = Describe("mapi2capi AWS conversion", func() {
 		}),
 	)
 
+	It("should bootstrap the machines of a machine set with the user data secret it references", func() {
+		// Heterogeneous clusters use a user data secret per architecture.
+		capiMachineSet, _, warns, err := FromAWSMachineSetAndInfra(
+			awsMAPIMachineSetBase.WithProviderSpecBuilder(
+				awsBaseProviderSpec.WithUserDataSecret(&corev1.LocalObjectReference{Name: "worker-arm64-user-data"}),
+			).Build(),
+			infra,
+		).ToMachineSetAndMachineTemplate()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(warns).To(BeEmpty())
+		Expect(capiMachineSet).To(HaveField("Spec.Template.Spec.Bootstrap.DataSecretName", Equal(ptr.To("worker-arm64-user-data"))))
+	})
+
+	It("should convert the placement group and the dedicated host tenancy", func() {
+		spec := awsBaseProviderSpec.WithPlacementGroupName("pg").WithPlacement(mapiv1.Placement{Tenancy: mapiv1.HostTenancy}).Build()
+		spec.PlacementGroupPartition = ptr.To(int32(3))
+
+		_, awsMachine, warns, err := FromAWSMachineAndInfra(
+			awsMAPIMachineBase.WithProviderSpec(mapiv1.ProviderSpec{Value: mustConvertAWSProviderSpecToRawExtension(spec)}).Build(),
+			infra,
+		).ToMachineAndInfrastructureMachine()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(warns).To(BeEmpty())
+		Expect(awsMachine).To(SatisfyAll(
+			HaveField("Spec.PlacementGroupName", Equal("pg")),
+			HaveField("Spec.PlacementGroupPartition", Equal(int64(3))),
+			HaveField("Spec.Tenancy", Equal("host")),
+		))
+	})
+
+	It("should convert the spot market options and the capacity reservation", func() {
+		spec := awsBaseProviderSpec.WithSpotMarketOptions(&mapiv1.SpotMarketOptions{MaxPrice: ptr.To("0.25")}).Build()
+		spec.CapacityReservationID = "cr-0123456789abcdef0"
+
+		_, awsMachine, warns, err := FromAWSMachineAndInfra(
+			awsMAPIMachineBase.WithProviderSpec(mapiv1.ProviderSpec{Value: mustConvertAWSProviderSpecToRawExtension(spec)}).Build(),
+			infra,
+		).ToMachineAndInfrastructureMachine()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(warns).To(BeEmpty())
+		Expect(awsMachine).To(SatisfyAll(
+			HaveField("Spec.SpotMarketOptions", Equal(&capav1.SpotMarketOptions{MaxPrice: ptr.To("0.25")})),
+			HaveField("Spec.CapacityReservationID", Equal(ptr.To("cr-0123456789abcdef0"))),
+		))
+	})
+
+	It("should carry the EFA network interface type in an annotation of the machines", func() {
+		capiMachineSet, _, warns, err := FromAWSMachineSetAndInfra(
+			awsMAPIMachineSetBase.WithProviderSpecBuilder(
+				awsBaseProviderSpec.WithNetworkInterfaceType(mapiv1.AWSEFANetworkInterfaceType),
+			).Build(),
+			infra,
+		).ToMachineSetAndMachineTemplate()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(warns).To(BeEmpty())
+		Expect(capiMachineSet.Spec.Template.Annotations).To(HaveKeyWithValue(conversionutil.AWSNetworkInterfaceTypeAnnotation, "EFA"))
+	})
+
+	It("should not annotate machines with the ENA network interface type", func() {
+		capiMachine, awsMachine, warns, err := FromAWSMachineAndInfra(
+			awsMAPIMachineBase.WithProviderSpecBuilder(
+				awsBaseProviderSpec.WithNetworkInterfaceType(mapiv1.AWSENANetworkInterfaceType),
+			).Build(),
+			infra,
+		).ToMachineAndInfrastructureMachine()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(warns).To(BeEmpty())
+		Expect(capiMachine.Annotations).ToNot(HaveKey(conversionutil.AWSNetworkInterfaceTypeAnnotation))
+		Expect(awsMachine.GetAnnotations()).ToNot(HaveKey(conversionutil.AWSNetworkInterfaceTypeAnnotation))
+	})
+
+	DescribeTable("should place machines in edge zones",
+		func(zone string, subnetName string, publicIP *bool) {
+			subnet := mapiv1.AWSResourceReference{Filters: []mapiv1.Filter{{Name: "tag:Name", Values: []string{subnetName}}}}
+
+			capiMachine, awsMachine, warns, err := FromAWSMachineAndInfra(
+				awsMAPIMachineBase.WithProviderSpecBuilder(
+					awsBaseProviderSpec.WithPlacement(mapiv1.Placement{AvailabilityZone: zone}).WithSubnet(subnet).WithPublicIP(publicIP),
+				).Build(),
+				infra,
+			).ToMachineAndInfrastructureMachine()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warns).To(BeEmpty())
+			Expect(capiMachine.Spec.FailureDomain).To(Equal(ptr.To(zone)))
+			Expect(awsMachine).To(SatisfyAll(
+				HaveField("Spec.Subnet", Equal(&capav1.AWSResourceReference{Filters: []capav1.Filter{{Name: "tag:Name", Values: []string{subnetName}}}})),
+				HaveField("Spec.PublicIP", Equal(publicIP)),
+			))
+		},
+		Entry("in a Local Zone", "us-east-1-nyc-1a", "sample-cluster-name-subnet-public-us-east-1-nyc-1a", ptr.To(true)),
+		Entry("in a Wavelength Zone, with a carrier IP", "us-east-1-wl1-bos-wlz-1", "sample-cluster-name-subnet-public-us-east-1-wl1-bos-wlz-1", ptr.To(true)),
+		Entry("in a Wavelength Zone, without a carrier IP", "us-east-1-wl1-bos-wlz-1", "sample-cluster-name-subnet-private-us-east-1-wl1-bos-wlz-1", nil),
+	)
+
+	It("should convert an empty spot max price to the on-demand price", func() {
+		_, awsMachine, _, err := FromAWSMachineAndInfra(
+			awsMAPIMachineBase.WithProviderSpecBuilder(
+				awsBaseProviderSpec.WithSpotMarketOptions(&mapiv1.SpotMarketOptions{MaxPrice: ptr.To("")}),
+			).Build(),
+			infra,
+		).ToMachineAndInfrastructureMachine()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(awsMachine).To(HaveField("Spec.SpotMarketOptions", Equal(&capav1.SpotMarketOptions{})))
+	})
+
 })
@@ -17,14 +17,18 @@ limitations under the License.
 package mapi2capi
 
 import (
+	"encoding/json"
 	"fmt"
 
 	mapiv1 "github.com/openshift/api/machine/v1beta1"
 	conversionutil "github.com/openshift/cluster-capi-operator/pkg/conversion/util"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	capav1 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
 	capibmv1 "sigs.k8s.io/cluster-api-provider-ibmcloud/api/v1beta2"
+	capov1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1beta1"
 	capiv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
@@ -35,38 +39,114 @@ const (
 	awsMachineTemplateKind   = "AWSMachineTemplate"
 	ibmPowerVSMachineKind    = "IBMPowerVSMachine"
 	ibmPowerVSTemplateKind   = "IBMPowerVSMachineTemplate"
+	openStackMachineKind     = "OpenStackMachine"
+	openStackTemplateKind    = "OpenStackMachineTemplate"
 )
 
 var (
 	// awsMachineAPIVersion is the API version for the AWSMachine API.
 	// Source it from the API group version so that it is always up to date.
-	awsMachineAPIVersion        = capav1.GroupVersion.String()   //nolint:gochecknoglobals
-	ibmPowerVSMachineAPIVersion = capibmv1.GroupVersion.String() //nolint:gochecknoglobals
+	awsMachineAPIVersion        = capav1.GroupVersion.String()       //nolint:gochecknoglobals
+	ibmPowerVSMachineAPIVersion = capibmv1.GroupVersion.String()     //nolint:gochecknoglobals
+	openStackMachineAPIVersion  = capov1.SchemeGroupVersion.String() //nolint:gochecknoglobals
 )
 
-func setMAPINodeLabelsToCAPIManagedNodeLabels(fldPath *field.Path, mapiNodeLabels map[string]string, capiNodeLabels map[string]string) field.ErrorList {
+// setMAPINodeLabelsToCAPIManagedNodeLabels copies the MAPI node labels that Cluster API propagates to the Node onto the CAPI Machine labels.
+// The remaining node labels are returned, so that they can be carried over by the node metadata annotations.
+func setMAPINodeLabelsToCAPIManagedNodeLabels(mapiNodeLabels map[string]string, capiNodeLabels map[string]string) map[string]string {
 	if len(mapiNodeLabels) == 0 {
-		return field.ErrorList{}
+		return nil
 	}
 
-	if capiNodeLabels == nil {
-		capiNodeLabels = map[string]string{}
-	}
-
-	errs := field.ErrorList{}
+	unmanagedLabels := map[string]string{}
 
-	// TODO(OCPCLOUD-2680): Not all the labels on the CAPI Machine are propagated down to the corresponding CAPI Node, only the "CAPI Managed ones" are.
+	// Not all the labels on the CAPI Machine are propagated down to the corresponding CAPI Node, only the "CAPI Managed ones" are.
 	// These are those prefix by "node-role.kubernetes.io" or in the domains of "node-restriction.kubernetes.io" and "node.cluster.x-k8s.io".
 	// See: https://github.com/kubernetes-sigs/cluster-api/pull/7173
 	// and: https://github.com/fabriziopandini/cluster-api/blob/main/docs/proposals/20220927-label-sync-between-machine-and-nodes.md
 	for k, v := range mapiNodeLabels {
 		if !conversionutil.IsCAPIManagedLabel(k) {
-			errs = append(errs, field.Invalid(fldPath.Key(k), v, "label propagation is not currently supported for this label"))
+			unmanagedLabels[k] = v
+			continue
 		}
 
 		capiNodeLabels[k] = v
 	}
 
+	return unmanagedLabels
+}
+
+// getCAPINodeMetadataAnnotations returns the annotations that should be added to a CAPI Machine to carry the MAPI node metadata
+// that has no equivalent on CAPI Machines: the node labels that Cluster API does not propagate, the node annotations and the node taints.
+// These are applied to the Node by the MAPI node link controller, so they are kept on the CAPI Machine to survive a migration.
+func getCAPINodeMetadataAnnotations(fldPath *field.Path, unmanagedLabels map[string]string, spec mapiv1.MachineSpec) (map[string]string, field.ErrorList) {
+	annotations := map[string]string{}
+	errs := field.ErrorList{}
+
+	errs = append(errs, validateMAPITaints(fldPath.Child("taints"), spec.Taints)...)
+
+	if len(unmanagedLabels) > 0 {
+		errs = append(errs, setJSONAnnotation(fldPath.Child("metadata", "labels"), annotations, conversionutil.NodeLabelsAnnotation, unmanagedLabels)...)
+	}
+
+	if len(spec.ObjectMeta.Annotations) > 0 {
+		errs = append(errs, setJSONAnnotation(fldPath.Child("metadata", "annotations"), annotations, conversionutil.NodeAnnotationsAnnotation, spec.ObjectMeta.Annotations)...)
+	}
+
+	if len(spec.Taints) > 0 {
+		errs = append(errs, setJSONAnnotation(fldPath.Child("taints"), annotations, conversionutil.NodeTaintsAnnotation, spec.Taints)...)
+	}
+
+	return annotations, errs
+}
+
+// setJSONAnnotation sets the annotation to the JSON encoding of the value.
+func setJSONAnnotation(fldPath *field.Path, annotations map[string]string, annotation string, value interface{}) field.ErrorList {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return field.ErrorList{field.InternalError(fldPath, fmt.Errorf("failed to encode %s annotation: %w", annotation, err))}
+	}
+
+	annotations[annotation] = string(encoded)
+
+	return nil
+}
+
+// validateMAPITaints checks that the MAPI node taints could be applied to a Node.
+func validateMAPITaints(fldPath *field.Path, taints []corev1.Taint) field.ErrorList {
+	errs := field.ErrorList{}
+	seen := map[corev1.Taint]struct{}{}
+
+	for i, taint := range taints {
+		idxPath := fldPath.Index(i)
+
+		for _, msg := range validation.IsQualifiedName(taint.Key) {
+			errs = append(errs, field.Invalid(idxPath.Child("key"), taint.Key, msg))
+		}
+
+		if taint.Value != "" {
+			for _, msg := range validation.IsValidLabelValue(taint.Value) {
+				errs = append(errs, field.Invalid(idxPath.Child("value"), taint.Value, msg))
+			}
+		}
+
+		switch taint.Effect {
+		case corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+		default:
+			errs = append(errs, field.NotSupported(idxPath.Child("effect"), taint.Effect, []string{
+				string(corev1.TaintEffectNoSchedule), string(corev1.TaintEffectPreferNoSchedule), string(corev1.TaintEffectNoExecute),
+			}))
+		}
+
+		// A Node can only have one taint per key and effect.
+		key := corev1.Taint{Key: taint.Key, Effect: taint.Effect}
+		if _, ok := seen[key]; ok {
+			errs = append(errs, field.Duplicate(idxPath, taint))
+		}
+
+		seen[key] = struct{}{}
+	}
+
 	return errs
 }
 
@@ -85,6 +165,27 @@ func getCAPILifecycleHookAnnotations(hooks mapiv1.LifecycleHooks) map[string]str
 	return annotations
 }
 
+// convertMAPIDeleteMachineAnnotationsToCAPI returns a copy of the MAPI Machine annotations where the MAPI delete machine annotations,
+// used to prioritise a Machine for deletion on MachineSet scale down, are replaced by the CAPI delete machine annotation.
+func convertMAPIDeleteMachineAnnotationsToCAPI(mapiAnnotations map[string]string) map[string]string {
+	if mapiAnnotations == nil {
+		return nil
+	}
+
+	capiAnnotations := make(map[string]string, len(mapiAnnotations))
+
+	for k, v := range mapiAnnotations {
+		switch k {
+		case conversionutil.MAPIDeleteMachineAnnotation, conversionutil.MAPIDeprecatedDeleteMachineAnnotation:
+			capiAnnotations[capiv1.DeleteMachineAnnotation] = v
+		default:
+			capiAnnotations[k] = v
+		}
+	}
+
+	return capiAnnotations
+}
+
 // handleUnsupportedMachineFields checks for fields that are not supported by CAPI and returns a list of errors.
 func handleUnsupportedMachineFields(spec mapiv1.MachineSpec) field.ErrorList {
 	var errs field.ErrorList
@@ -93,11 +194,6 @@ func handleUnsupportedMachineFields(spec mapiv1.MachineSpec) field.ErrorList {
 
 	errs = append(errs, handleUnsupportedMAPIObjectMetaFields(fldPath.Child("metadata"), spec.ObjectMeta)...)
 
-	// TODO(OCPCLOUD-2680): Taints are not supported by CAPI. add support for them via CAPI BootstrapConfig + minimal bootstrap controller.
-	if len(spec.Taints) > 0 {
-		errs = append(errs, field.Invalid(fldPath.Child("taints"), spec.Taints, "taints are not currently supported"))
-	}
-
 	return errs
 }
 
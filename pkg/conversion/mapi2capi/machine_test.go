@@ -17,6 +17,8 @@ limitations under the License.
 package mapi2capi
 
 import (
+	"maps"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	mapiv1 "github.com/openshift/api/machine/v1beta1"
@@ -25,6 +27,9 @@ import (
 	"github.com/openshift/cluster-capi-operator/pkg/conversion/test/matchers"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	capiv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	capierrors "sigs.k8s.io/cluster-api/errors"
 )
 
 var _ = Describe("mapi2capi Machine conversion", func() {
@@ -83,14 +88,14 @@ var _ = Describe("mapi2capi Machine conversion", func() {
 			expectedWarnings: []string{},
 		}),
 
-		Entry("With unsupported non-CAPI managed labels", mapi2CAPIMachineConversionInput{
+		Entry("With non-CAPI managed labels", mapi2CAPIMachineConversionInput{
 			infraBuilder: infraBase,
 			machineBuilder: mapiMachineBase.WithMachineSpecObjectMeta(mapiv1.ObjectMeta{
 				Labels: map[string]string{
 					"custom.domain/label": "value",
 				},
 			}),
-			expectedErrors:   []string{"spec.metadata.labels[custom.domain/label]: Invalid value: \"value\": label propagation is not currently supported for this label"},
+			expectedErrors:   []string{},
 			expectedWarnings: []string{},
 		}),
 
@@ -135,15 +140,217 @@ var _ = Describe("mapi2capi Machine conversion", func() {
 			expectedWarnings: []string{},
 		}),
 
-		Entry("With unsupported spec.taints set", mapi2CAPIMachineConversionInput{
+		Entry("With spec.taints set", mapi2CAPIMachineConversionInput{
 			infraBuilder: infraBase,
 			machineBuilder: mapiMachineBase.WithTaints([]corev1.Taint{{
 				Key:    "key1",
 				Value:  "value1",
 				Effect: corev1.TaintEffectNoSchedule,
 			}}),
-			expectedErrors:   []string{"spec.taints: Invalid value: []v1.Taint{v1.Taint{Key:\"key1\", Value:\"value1\", Effect:\"NoSchedule\", TimeAdded:<nil>}}: taints are not currently supported"},
+			expectedErrors:   []string{},
 			expectedWarnings: []string{},
 		}),
+
+		Entry("With invalid spec.taints set", mapi2CAPIMachineConversionInput{
+			infraBuilder: infraBase,
+			machineBuilder: mapiMachineBase.WithTaints([]corev1.Taint{
+				{Key: "", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "key1", Effect: "Invalid"},
+				{Key: "key2", Value: "value1", Effect: corev1.TaintEffectNoExecute},
+				{Key: "key2", Value: "value2", Effect: corev1.TaintEffectNoExecute},
+			}),
+			expectedErrors: []string{
+				"spec.taints[0].key: Invalid value: \"\": name part must be non-empty",
+				"spec.taints[0].key: Invalid value: \"\": name part must consist of alphanumeric characters",
+				"spec.taints[1].effect: Unsupported value: \"Invalid\": supported values: \"NoSchedule\", \"PreferNoSchedule\", \"NoExecute\"",
+				"spec.taints[3]: Duplicate value",
+			},
+			expectedWarnings: []string{},
+		}),
+	)
+
+	DescribeTable("mapi2capi convert MAPI node metadata",
+		func(nodeMetadata mapiv1.ObjectMeta, taints []corev1.Taint, expectedLabels, expectedAnnotations map[string]string) {
+			capiMachine, _, _, err := FromAWSMachineAndInfra(
+				mapiMachineBase.WithMachineSpecObjectMeta(nodeMetadata).WithTaints(taints).Build(),
+				infraBase.Build(),
+			).ToMachineAndInfrastructureMachine()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(capiMachine.Labels).To(Equal(expectedLabels))
+			Expect(capiMachine.Annotations).To(Equal(expectedAnnotations))
+		},
+		Entry("With CAPI managed labels",
+			mapiv1.ObjectMeta{Labels: map[string]string{"node-role.kubernetes.io/worker": ""}},
+			nil,
+			map[string]string{
+				"node-role.kubernetes.io/worker":                "",
+				"machine.openshift.io/cluster-api-cluster":      "test",
+				"machine.openshift.io/cluster-api-machine-role": "worker",
+				"machine.openshift.io/cluster-api-machine-type": "worker",
+			},
+			map[string]string{},
+		),
+		Entry("With non-CAPI managed labels",
+			mapiv1.ObjectMeta{Labels: map[string]string{"node-role.kubernetes.io/worker": "", "custom.domain/label": "value"}},
+			nil,
+			map[string]string{
+				"node-role.kubernetes.io/worker":                "",
+				"machine.openshift.io/cluster-api-cluster":      "test",
+				"machine.openshift.io/cluster-api-machine-role": "worker",
+				"machine.openshift.io/cluster-api-machine-type": "worker",
+			},
+			map[string]string{"cluster-api.openshift.io/node-labels": `{"custom.domain/label":"value"}`},
+		),
+		Entry("With node annotations and taints",
+			mapiv1.ObjectMeta{Annotations: map[string]string{"foo": "bar"}},
+			[]corev1.Taint{{Key: "key1", Value: "value1", Effect: corev1.TaintEffectNoSchedule}},
+			map[string]string{"machine.openshift.io/cluster-api-cluster": "test"},
+			map[string]string{
+				"cluster-api.openshift.io/node-annotations": `{"foo":"bar"}`,
+				"cluster-api.openshift.io/node-taints":      `[{"key":"key1","value":"value1","effect":"NoSchedule"}]`,
+			},
+		),
+	)
+
+	DescribeTable("mapi2capi back-fill the MAPI machine labels",
+		func(labels, nodeLabels, expectedLabels map[string]string) {
+			mapiMachine := mapiMachineBase.WithMachineSpecObjectMeta(mapiv1.ObjectMeta{Labels: nodeLabels}).Build()
+			// The builder always sets the cluster label, so replace the labels once built.
+			mapiMachine.Labels = maps.Clone(labels)
+
+			capiMachine, capaMachine, _, err := FromAWSMachineAndInfra(mapiMachine, infraBase.Build()).ToMachineAndInfrastructureMachine()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(capiMachine.Labels).To(Equal(expectedLabels))
+			Expect(capaMachine.GetLabels()).To(Equal(expectedLabels))
+			Expect(mapiMachine.Labels).To(Equal(labels), "should leave the MAPI Machine untouched")
+		},
+		Entry("With no labels",
+			nil,
+			nil,
+			map[string]string{"machine.openshift.io/cluster-api-cluster": "test"},
+		),
+		Entry("With a single node role",
+			map[string]string{"machine.openshift.io/cluster-api-machineset": "worker-a"},
+			map[string]string{"node-role.kubernetes.io/infra": ""},
+			map[string]string{
+				"node-role.kubernetes.io/infra":                 "",
+				"machine.openshift.io/cluster-api-cluster":      "test",
+				"machine.openshift.io/cluster-api-machineset":   "worker-a",
+				"machine.openshift.io/cluster-api-machine-role": "infra",
+				"machine.openshift.io/cluster-api-machine-type": "infra",
+			},
+		),
+		Entry("With several node roles",
+			nil,
+			map[string]string{"node-role.kubernetes.io/infra": "", "node-role.kubernetes.io/worker": ""},
+			map[string]string{
+				"node-role.kubernetes.io/infra":            "",
+				"node-role.kubernetes.io/worker":           "",
+				"machine.openshift.io/cluster-api-cluster": "test",
+			},
+		),
+		Entry("With the labels already set on the MAPI Machine",
+			map[string]string{
+				"machine.openshift.io/cluster-api-cluster":      "other",
+				"machine.openshift.io/cluster-api-machine-role": "worker",
+				"machine.openshift.io/cluster-api-machine-type": "custom",
+			},
+			map[string]string{"node-role.kubernetes.io/infra": ""},
+			map[string]string{
+				"node-role.kubernetes.io/infra":                 "",
+				"machine.openshift.io/cluster-api-cluster":      "other",
+				"machine.openshift.io/cluster-api-machine-role": "worker",
+				"machine.openshift.io/cluster-api-machine-type": "custom",
+			},
+		),
+	)
+
+	DescribeTable("mapi2capi convert MAPI delete machine annotations",
+		func(annotations, expectedAnnotations map[string]string) {
+			capiMachine, _, _, err := FromAWSMachineAndInfra(
+				mapiMachineBase.WithAnnotations(annotations).Build(),
+				infraBase.Build(),
+			).ToMachineAndInfrastructureMachine()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(capiMachine.Annotations).To(Equal(expectedAnnotations))
+		},
+		Entry("With the MAPI delete machine annotation",
+			map[string]string{"machine.openshift.io/delete-machine": "yes", "foo": "bar"},
+			map[string]string{"cluster.x-k8s.io/delete-machine": "yes", "foo": "bar"},
+		),
+		Entry("With the deprecated MAPI delete machine annotation",
+			map[string]string{"cluster.k8s.io/delete-machine": "yes"},
+			map[string]string{"cluster.x-k8s.io/delete-machine": "yes"},
+		),
+	)
+
+	It("should convert the phase and the error of a Failed MAPI Machine", func() {
+		capiMachine, _, _, err := FromAWSMachineAndInfra(
+			mapiMachineBase.
+				WithPhase(mapiv1.PhaseFailed).
+				WithErrorReason(mapiv1.InvalidConfigurationMachineError).
+				WithErrorMessage("invalid instance type").
+				Build(),
+			infraBase.Build(),
+		).ToMachineAndInfrastructureMachine()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(capiMachine.Status.Phase).To(Equal(string(capiv1.MachinePhaseFailed)))
+		Expect(capiMachine.Status.FailureReason).To(Equal(ptr.To(capierrors.InvalidConfigurationMachineError)))
+		Expect(capiMachine.Status.FailureMessage).To(Equal(ptr.To("invalid instance type")))
+	})
+
+	DescribeTable("mapi2capi convert MAPI Machine addresses",
+		func(addresses []corev1.NodeAddress, expectedAddresses capiv1.MachineAddresses) {
+			capiMachine, _, _, err := FromAWSMachineAndInfra(
+				mapiMachineBase.WithAddresses(addresses).Build(),
+				infraBase.Build(),
+			).ToMachineAndInfrastructureMachine()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(capiMachine.Status.Addresses).To(Equal(expectedAddresses))
+		},
+		Entry("Without addresses", nil, nil),
+		Entry("With IPv4 addresses",
+			[]corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: corev1.NodeInternalDNS, Address: "ip-10-0-0-1.ec2.internal"},
+				{Type: corev1.NodeHostName, Address: "ip-10-0-0-1.ec2.internal"},
+			},
+			capiv1.MachineAddresses{
+				{Type: capiv1.MachineInternalIP, Address: "10.0.0.1"},
+				{Type: capiv1.MachineInternalDNS, Address: "ip-10-0-0-1.ec2.internal"},
+				{Type: capiv1.MachineHostName, Address: "ip-10-0-0-1.ec2.internal"},
+			},
+		),
+		Entry("With dual-stack addresses, IPv4 first",
+			[]corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: corev1.NodeInternalIP, Address: "fd00:10::1"},
+				{Type: corev1.NodeExternalIP, Address: "203.0.113.1"},
+				{Type: corev1.NodeExternalIP, Address: "2001:db8::1"},
+				{Type: corev1.NodeExternalDNS, Address: "ec2-203-0-113-1.compute.amazonaws.com"},
+			},
+			capiv1.MachineAddresses{
+				{Type: capiv1.MachineInternalIP, Address: "10.0.0.1"},
+				{Type: capiv1.MachineInternalIP, Address: "fd00:10::1"},
+				{Type: capiv1.MachineExternalIP, Address: "203.0.113.1"},
+				{Type: capiv1.MachineExternalIP, Address: "2001:db8::1"},
+				{Type: capiv1.MachineExternalDNS, Address: "ec2-203-0-113-1.compute.amazonaws.com"},
+			},
+		),
+		Entry("With dual-stack addresses, IPv6 first",
+			[]corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "fd00:10::1"},
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+			},
+			capiv1.MachineAddresses{
+				{Type: capiv1.MachineInternalIP, Address: "fd00:10::1"},
+				{Type: capiv1.MachineInternalIP, Address: "10.0.0.1"},
+			},
+		),
+		Entry("With an unknown address type",
+			[]corev1.NodeAddress{{Type: "Custom", Address: "fd00:10::1"}},
+			capiv1.MachineAddresses{{Type: "Custom", Address: "fd00:10::1"}},
+		),
 	)
 })
@@ -0,0 +1,98 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package mapi2capi
+
+import (
+	configv1 "github.com/openshift/api/config/v1"
+	mapiv1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-capi-operator/pkg/errorsx"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	capiv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// FromMachineHealthCheckAndInfra converts a MAPI MachineHealthCheck to a CAPI MachineHealthCheck of the cluster named
+// after the infrastructure. The conversion does not depend on the platform. It returns no warnings, as the fields it
+// converts are identical in both APIs.
+func FromMachineHealthCheckAndInfra(mapiMHC *mapiv1.MachineHealthCheck, infra *configv1.Infrastructure) (*capiv1.MachineHealthCheck, []string, error) {
+	var errs field.ErrorList
+
+	capiMHC := &capiv1.MachineHealthCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        mapiMHC.Name,
+			Namespace:   mapiMHC.Namespace,
+			Labels:      util.MergeMaps(mapiMHC.Labels, nil),
+			Annotations: mapiMHC.Annotations,
+		},
+		Spec: capiv1.MachineHealthCheckSpec{
+			// ClusterName // Populated below from the infrastructure.
+			Selector:            mapiMHC.Spec.Selector,
+			UnhealthyConditions: convertMAPIUnhealthyConditionsToCAPI(mapiMHC.Spec.UnhealthyConditions),
+			MaxUnhealthy:        mapiMHC.Spec.MaxUnhealthy,
+			NodeStartupTimeout:  mapiMHC.Spec.NodeStartupTimeout,
+		},
+	}
+
+	if infra == nil || infra.Status.InfrastructureName == "" {
+		errs = append(errs, field.Invalid(field.NewPath("infrastructure", "status", "infrastructureName"), "", "infrastructure cannot be nil and infrastructure.Status.InfrastructureName cannot be empty"))
+	} else {
+		capiMHC.Spec.ClusterName = infra.Status.InfrastructureName
+		// The CAPI defaulting webhook labels the MachineHealthChecks with their cluster, so set it to keep the
+		// converted MachineHealthCheck stable.
+		capiMHC.Labels[capiv1.ClusterNameLabel] = infra.Status.InfrastructureName
+	}
+
+	if len(mapiMHC.Spec.Selector.MatchLabels) == 0 && len(mapiMHC.Spec.Selector.MatchExpressions) == 0 {
+		// An empty MAPI selector selects all the Machines, while CAPI rejects it.
+		errs = append(errs, field.Invalid(field.NewPath("spec", "selector"), mapiMHC.Spec.Selector, "an empty selector is not supported"))
+	}
+
+	if mapiMHC.Spec.RemediationTemplate != nil {
+		// The remediation templates are provider specific resources of the MAPI namespace, which have no CAPI equivalent.
+		errs = append(errs, field.Forbidden(field.NewPath("spec", "remediationTemplate"), "remediationTemplate is not supported"))
+	}
+
+	if len(mapiMHC.OwnerReferences) > 0 {
+		errs = append(errs, field.Invalid(field.NewPath("metadata", "ownerReferences"), mapiMHC.OwnerReferences, "ownerReferences are not supported"))
+	}
+
+	if len(errs) > 0 {
+		return nil, nil, errorsx.UnsupportedFields(errs.ToAggregate())
+	}
+
+	return capiMHC, nil, nil
+}
+
+// convertMAPIUnhealthyConditionsToCAPI converts the MAPI unhealthy conditions to their CAPI equivalent.
+func convertMAPIUnhealthyConditionsToCAPI(mapiConditions []mapiv1.UnhealthyCondition) []capiv1.UnhealthyCondition {
+	if mapiConditions == nil {
+		return nil
+	}
+
+	capiConditions := make([]capiv1.UnhealthyCondition, 0, len(mapiConditions))
+
+	for _, condition := range mapiConditions {
+		capiConditions = append(capiConditions, capiv1.UnhealthyCondition{
+			Type:    condition.Type,
+			Status:  condition.Status,
+			Timeout: condition.Timeout,
+		})
+	}
+
+	return capiConditions
+}
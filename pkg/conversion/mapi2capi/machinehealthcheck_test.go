@@ -0,0 +1,103 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package mapi2capi
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	mapiv1 "github.com/openshift/api/machine/v1beta1"
+	configbuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/config/v1"
+
+	"github.com/openshift/cluster-capi-operator/pkg/conversion/test/matchers"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	capiv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+var _ = Describe("mapi2capi MachineHealthCheck conversion", func() {
+	infraBase := configbuilder.Infrastructure().AsAWS("test", "eu-west-2")
+
+	newMachineHealthCheck := func() *mapiv1.MachineHealthCheck {
+		return &mapiv1.MachineHealthCheck{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "worker",
+				Namespace: "openshift-machine-api",
+				Labels:    map[string]string{"foo": "bar"},
+			},
+			Spec: mapiv1.MachineHealthCheckSpec{
+				Selector: metav1.LabelSelector{MatchLabels: map[string]string{mapiv1.MachineClusterIDLabel: "test"}},
+				UnhealthyConditions: []mapiv1.UnhealthyCondition{{
+					Type:    corev1.NodeReady,
+					Status:  corev1.ConditionUnknown,
+					Timeout: metav1.Duration{Duration: 5 * time.Minute},
+				}},
+				MaxUnhealthy:       &intstr.IntOrString{Type: intstr.String, StrVal: "40%"},
+				NodeStartupTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+			},
+		}
+	}
+
+	It("should convert the MachineHealthCheck for the cluster of the infrastructure", func() {
+		mapiMHC := newMachineHealthCheck()
+
+		capiMHC, warnings, err := FromMachineHealthCheckAndInfra(mapiMHC, infraBase.Build())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(warnings).To(BeEmpty())
+
+		Expect(capiMHC.Labels).To(Equal(map[string]string{"foo": "bar", capiv1.ClusterNameLabel: "test"}))
+		Expect(mapiMHC.Labels).ToNot(HaveKey(capiv1.ClusterNameLabel), "should leave the MAPI MachineHealthCheck untouched")
+
+		Expect(capiMHC.Spec).To(Equal(capiv1.MachineHealthCheckSpec{
+			ClusterName: "test",
+			Selector:    mapiMHC.Spec.Selector,
+			UnhealthyConditions: []capiv1.UnhealthyCondition{{
+				Type:    corev1.NodeReady,
+				Status:  corev1.ConditionUnknown,
+				Timeout: metav1.Duration{Duration: 5 * time.Minute},
+			}},
+			MaxUnhealthy:       mapiMHC.Spec.MaxUnhealthy,
+			NodeStartupTimeout: mapiMHC.Spec.NodeStartupTimeout,
+		}))
+	})
+
+	DescribeTable("should reject the fields CAPI does not support",
+		func(mutate func(*mapiv1.MachineHealthCheck), expectedErrors []string) {
+			mapiMHC := newMachineHealthCheck()
+			mutate(mapiMHC)
+
+			_, _, err := FromMachineHealthCheckAndInfra(mapiMHC, infraBase.Build())
+			Expect(err).To(matchers.ConsistOfMatchErrorSubstrings(expectedErrors))
+		},
+		Entry("With an empty selector", func(mhc *mapiv1.MachineHealthCheck) {
+			mhc.Spec.Selector = metav1.LabelSelector{}
+		}, []string{"spec.selector: Invalid value: v1.LabelSelector{MatchLabels:map[string]string(nil), MatchExpressions:[]v1.LabelSelectorRequirement(nil)}: an empty selector is not supported"}),
+		Entry("With a remediation template", func(mhc *mapiv1.MachineHealthCheck) {
+			mhc.Spec.RemediationTemplate = &corev1.ObjectReference{Kind: "Metal3RemediationTemplate", Name: "foo"}
+		}, []string{"spec.remediationTemplate: Forbidden: remediationTemplate is not supported"}),
+		Entry("With owner references", func(mhc *mapiv1.MachineHealthCheck) {
+			mhc.OwnerReferences = []metav1.OwnerReference{{Name: "a"}}
+		}, []string{"metadata.ownerReferences: Invalid value: []v1.OwnerReference{v1.OwnerReference{APIVersion:\"\", Kind:\"\", Name:\"a\", UID:\"\", Controller:(*bool)(nil), BlockOwnerDeletion:(*bool)(nil)}}: ownerReferences are not supported"}),
+	)
+
+	It("should reject an infrastructure without a name", func() {
+		_, _, err := FromMachineHealthCheckAndInfra(newMachineHealthCheck(), configbuilder.Infrastructure().Build())
+		Expect(err).To(MatchError(ContainSubstring("infrastructure.status.infrastructureName")))
+	})
+})
@@ -16,7 +16,11 @@ limitations under the License.
 package mapi2capi
 
 import (
+	"maps"
+
 	mapiv1 "github.com/openshift/api/machine/v1beta1"
+	conversionutil "github.com/openshift/cluster-capi-operator/pkg/conversion/util"
+	"github.com/openshift/cluster-capi-operator/pkg/errorsx"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
@@ -33,7 +37,7 @@ func fromMAPIMachineSetToCAPIMachineSet(mapiMachineSet *mapiv1.MachineSet) (*cap
 			Name:        mapiMachineSet.Name,
 			Namespace:   mapiMachineSet.Namespace,
 			Labels:      mapiMachineSet.Labels,
-			Annotations: mapiMachineSet.Annotations,
+			Annotations: conversionutil.ConvertMAPIAutoscalerAnnotationsToCAPI(mapiMachineSet.Annotations),
 			// OwnerReferences - There shouldn't be any ownerreferences on a MachineSet.
 		},
 		Spec: capiv1.MachineSetSpec{
@@ -41,11 +45,13 @@ func fromMAPIMachineSetToCAPIMachineSet(mapiMachineSet *mapiv1.MachineSet) (*cap
 			Replicas: mapiMachineSet.Spec.Replicas,
 			// ClusterName // populated by higher level functions
 			MinReadySeconds: mapiMachineSet.Spec.MinReadySeconds,
-			DeletePolicy:    mapiMachineSet.Spec.DeletePolicy,
+			DeletePolicy:    convertMAPIMachineSetDeletePolicyToCAPI(mapiMachineSet.Spec.DeletePolicy),
 			Template: capiv1.MachineTemplateSpec{
 				ObjectMeta: capiv1.ObjectMeta{
-					Labels:      mapiMachineSet.Spec.Template.Labels,
-					Annotations: mapiMachineSet.Spec.Template.Annotations,
+					// The Machine labels and annotations, such as the CAPI managed node labels and the node metadata annotations,
+					// are merged into the template ones, so copy them to leave the MAPI MachineSet untouched.
+					Labels:      maps.Clone(mapiMachineSet.Spec.Template.Labels),
+					Annotations: maps.Clone(mapiMachineSet.Spec.Template.Annotations),
 				},
 				// Spec // Populated by higher level functions.
 			},
@@ -57,11 +63,35 @@ func fromMAPIMachineSetToCAPIMachineSet(mapiMachineSet *mapiv1.MachineSet) (*cap
 		errs = append(errs, field.Invalid(field.NewPath("metadata", "ownerReferences"), mapiMachineSet.OwnerReferences, "ownerReferences are not supported"))
 	}
 
+	if !conversionutil.IsSupportedMachineSetDeletePolicy(capiMachineSet.Spec.DeletePolicy) {
+		errs = append(errs, field.NotSupported(field.NewPath("spec", "deletePolicy"), mapiMachineSet.Spec.DeletePolicy, conversionutil.SupportedMachineSetDeletePolicies()))
+	}
+
 	// Unused fields - Below this line are fields not used from the MAPI MachineSet.
 
 	errs = append(errs, handleUnsupportedMAPIObjectMetaFields(field.NewPath("spec", "template", "metadata"), mapiMachineSet.Spec.Template.ObjectMeta)...)
 
 	// AuthoritativeAPI - Ignore, this is part of the conversion mechanism.
 
-	return capiMachineSet, errs.ToAggregate()
+	return capiMachineSet, errorsx.UnsupportedFields(errs.ToAggregate())
+}
+
+// convertMAPIMachineSetDeletePolicyToCAPI converts the MAPI MachineSet delete policy to its CAPI equivalent.
+// The policies share the same values in both APIs, but an empty MAPI policy is defaulted to Random
+// to match the value the CAPI defaulting webhook would otherwise set, so that the converted MachineSet is stable.
+// The conversion to MAPI converts Random back to an empty policy.
+func convertMAPIMachineSetDeletePolicyToCAPI(policy string) string {
+	if policy == "" {
+		return string(capiv1.RandomMachineSetDeletePolicy)
+	}
+
+	return policy
+}
+
+// backfillCAPIMachineSetLabels back-fills the machine.openshift.io labels the MAPI MachineSet lacks onto the converted
+// CAPI MachineSet, and onto its template with the machine set label, so that MAPI tooling keeps matching the
+// MachineSet and the Machines it creates. It must be called once the cluster name is set.
+func backfillCAPIMachineSetLabels(capiMachineSet *capiv1.MachineSet) {
+	capiMachineSet.Labels = conversionutil.BackfillMAPIMachineLabels(capiMachineSet.Labels, capiMachineSet.Spec.ClusterName, "")
+	capiMachineSet.Spec.Template.Labels = conversionutil.BackfillMAPIMachineLabels(capiMachineSet.Spec.Template.Labels, capiMachineSet.Spec.ClusterName, capiMachineSet.Name)
 }
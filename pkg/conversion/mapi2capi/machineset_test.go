@@ -109,5 +109,111 @@ var _ = Describe("mapi2capi MachineSet conversion", func() {
 			expectedErrors:   []string{"spec.metadata.ownerReferences: Invalid value: []v1.OwnerReference{v1.OwnerReference{APIVersion:\"v1\", Kind:\"Pod\", Name:\"test-pod\", UID:\"test-uid\", Controller:(*bool)(nil), BlockOwnerDeletion:(*bool)(nil)}}: ownerReferences are not supported"},
 			expectedWarnings: []string{},
 		}),
+
+		Entry("With unsupported spec.deletePolicy set", mapi2CAPIMachinesetConversionInput{
+			infraBuilder:      infraBase,
+			machineSetBuilder: mapiMachineSetBase.WithDeletePolicy("Unknown"),
+			expectedErrors:    []string{"spec.deletePolicy: Unsupported value: \"Unknown\": supported values: \"Random\", \"Newest\", \"Oldest\""},
+			expectedWarnings:  []string{},
+		}),
 	)
+
+	DescribeTable("mapi2capi convert MAPI MachineSet delete policy",
+		func(deletePolicy, expectedDeletePolicy string) {
+			capiMachineSet, _, _, err := FromAWSMachineSetAndInfra(
+				mapiMachineSetBase.WithDeletePolicy(deletePolicy).Build(),
+				infraBase.Build(),
+			).ToMachineSetAndMachineTemplate()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(capiMachineSet.Spec.DeletePolicy).To(Equal(expectedDeletePolicy))
+		},
+		Entry("With an empty delete policy, defaults to Random", "", "Random"),
+		Entry("With the Random delete policy", "Random", "Random"),
+		Entry("With the Newest delete policy", "Newest", "Newest"),
+		Entry("With the Oldest delete policy", "Oldest", "Oldest"),
+	)
+
+	DescribeTable("mapi2capi convert MAPI MachineSet cluster autoscaler annotations",
+		func(annotations, expectedAnnotations map[string]string) {
+			mapiMachineSet := mapiMachineSetBase.WithAnnotations(annotations).Build()
+
+			capiMachineSet, _, _, err := FromAWSMachineSetAndInfra(mapiMachineSet, infraBase.Build()).ToMachineSetAndMachineTemplate()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(capiMachineSet.Annotations).To(Equal(expectedAnnotations))
+			Expect(mapiMachineSet.Annotations).To(Equal(annotations), "should not modify the MAPI MachineSet annotations")
+		},
+		Entry("With no annotations", nil, nil),
+		Entry("With the node group size annotations",
+			map[string]string{
+				"machine.openshift.io/cluster-api-autoscaler-node-group-min-size": "1",
+				"machine.openshift.io/cluster-api-autoscaler-node-group-max-size": "5",
+				"foo": "bar",
+			},
+			map[string]string{
+				"cluster.x-k8s.io/cluster-api-autoscaler-node-group-min-size": "1",
+				"cluster.x-k8s.io/cluster-api-autoscaler-node-group-max-size": "5",
+				"foo": "bar",
+			},
+		),
+		Entry("With the scale from zero annotations",
+			map[string]string{
+				"machine.openshift.io/vCPU":     "4",
+				"machine.openshift.io/memoryMb": "16384",
+				"machine.openshift.io/GPU":      "1",
+				"machine.openshift.io/maxPods":  "250",
+			},
+			map[string]string{
+				"capacity.cluster-autoscaler.kubernetes.io/cpu":       "4",
+				"capacity.cluster-autoscaler.kubernetes.io/memory":    "16384Mi",
+				"capacity.cluster-autoscaler.kubernetes.io/gpu-count": "1",
+				"capacity.cluster-autoscaler.kubernetes.io/gpu-type":  "nvidia.com/gpu",
+				"capacity.cluster-autoscaler.kubernetes.io/maxPods":   "250",
+			},
+		),
+		Entry("With a GPU type already set, the GPU type is kept",
+			map[string]string{
+				"machine.openshift.io/GPU":                           "2",
+				"capacity.cluster-autoscaler.kubernetes.io/gpu-type": "amd.com/gpu",
+			},
+			map[string]string{
+				"capacity.cluster-autoscaler.kubernetes.io/gpu-count": "2",
+				"capacity.cluster-autoscaler.kubernetes.io/gpu-type":  "amd.com/gpu",
+			},
+		),
+		Entry("Without GPUs, no GPU type is set",
+			map[string]string{"machine.openshift.io/GPU": "0"},
+			map[string]string{"capacity.cluster-autoscaler.kubernetes.io/gpu-count": "0"},
+		),
+		Entry("With both the MAPI and the CAPI annotations, the MAPI annotation wins",
+			map[string]string{
+				"machine.openshift.io/cluster-api-autoscaler-node-group-max-size": "5",
+				"cluster.x-k8s.io/cluster-api-autoscaler-node-group-max-size":     "3",
+			},
+			map[string]string{
+				"cluster.x-k8s.io/cluster-api-autoscaler-node-group-max-size": "5",
+			},
+		),
+	)
+
+	It("should back-fill the MAPI machine labels of the MachineSet and its template", func() {
+		mapiMachineSet := mapiMachineSetBase.WithName("worker-eu-west-2a").Build()
+		// The builder sets default labels, so replace them once built.
+		mapiMachineSet.Labels = nil
+		mapiMachineSet.Spec.Template.Labels = map[string]string{"machine.openshift.io/cluster-api-machine-type": "highmem"}
+		mapiMachineSet.Spec.Template.Spec.ObjectMeta.Labels = map[string]string{"node-role.kubernetes.io/worker": ""}
+
+		capiMachineSet, _, _, err := FromAWSMachineSetAndInfra(mapiMachineSet, infraBase.Build()).ToMachineSetAndMachineTemplate()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(capiMachineSet.Labels).To(Equal(map[string]string{"machine.openshift.io/cluster-api-cluster": "test"}))
+		Expect(capiMachineSet.Spec.Template.Labels).To(Equal(map[string]string{
+			"node-role.kubernetes.io/worker":                "",
+			"machine.openshift.io/cluster-api-cluster":      "test",
+			"machine.openshift.io/cluster-api-machineset":   "worker-eu-west-2a",
+			"machine.openshift.io/cluster-api-machine-role": "worker",
+			"machine.openshift.io/cluster-api-machine-type": "highmem",
+		}))
+		Expect(mapiMachineSet.Spec.Template.Labels).To(Equal(map[string]string{"machine.openshift.io/cluster-api-machine-type": "highmem"}),
+			"should leave the MAPI MachineSet untouched")
+	})
 })
@@ -0,0 +1,818 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mapi2capi
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	mapiv1alpha1 "github.com/openshift/api/machine/v1alpha1"
+	mapiv1beta1 "github.com/openshift/api/machine/v1beta1"
+	conversionutil "github.com/openshift/cluster-capi-operator/pkg/conversion/util"
+	"github.com/openshift/cluster-capi-operator/pkg/errorsx"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	capov1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1beta1"
+	capiv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// openStackProfileCapabilitiesKey is the binding profile key enabling OVS hardware offload when set to
+	// openStackProfileSwitchdevCapabilities.
+	openStackProfileCapabilitiesKey       = "capabilities"
+	openStackProfileSwitchdevCapabilities = `["switchdev"]`
+
+	// openStackProfileTrustedKey is the binding profile key marking an SR-IOV virtual function as trusted.
+	openStackProfileTrustedKey = "trusted"
+
+	// openStackCloudsSecretName and openStackCloudName reference the cloud credentials of the cluster, also used by
+	// the OpenStackCluster, the machines using them have no identityRef.
+	openStackCloudsSecretName = "openstack-cloud-credentials"
+	openStackCloudName        = "openstack"
+)
+
+// openStackMachineAndInfra stores the details of a Machine API OpenStack Machine and Infra.
+type openStackMachineAndInfra struct {
+	machine        *mapiv1beta1.Machine
+	infrastructure *configv1.Infrastructure
+}
+
+// openStackMachineSetAndInfra stores the details of a Machine API OpenStack MachineSet and Infra.
+type openStackMachineSetAndInfra struct {
+	machineSet     *mapiv1beta1.MachineSet
+	infrastructure *configv1.Infrastructure
+	*openStackMachineAndInfra
+}
+
+// FromOpenStackMachineAndInfra wraps a Machine API Machine for OpenStack and the OCP Infrastructure object into a mapi2capi OpenStackProviderSpec.
+func FromOpenStackMachineAndInfra(m *mapiv1beta1.Machine, i *configv1.Infrastructure) Machine {
+	return &openStackMachineAndInfra{machine: m, infrastructure: i}
+}
+
+// FromOpenStackMachineSetAndInfra wraps a Machine API MachineSet for OpenStack and the OCP Infrastructure object into a mapi2capi OpenStackProviderSpec.
+func FromOpenStackMachineSetAndInfra(m *mapiv1beta1.MachineSet, i *configv1.Infrastructure) MachineSet {
+	return &openStackMachineSetAndInfra{
+		machineSet:     m,
+		infrastructure: i,
+		openStackMachineAndInfra: &openStackMachineAndInfra{
+			machine: &mapiv1beta1.Machine{
+				// The Machines of the MachineSet have the template labels, which the labels back-filled on the Machine must not override.
+				ObjectMeta: metav1.ObjectMeta{Labels: m.Spec.Template.Labels},
+				Spec:       m.Spec.Template.Spec,
+			},
+			infrastructure: i,
+		},
+	}
+}
+
+// ToMachineAndInfrastructureMachine is used to generate a CAPI Machine and the corresponding InfrastructureMachine
+// from the stored MAPI Machine and Infrastructure objects.
+func (m *openStackMachineAndInfra) ToMachineAndInfrastructureMachine() (*capiv1.Machine, client.Object, []string, error) {
+	capiMachine, openStackMachine, warnings, errs := m.toMachineAndInfrastructureMachine()
+
+	if len(errs) > 0 {
+		return nil, nil, warnings, errorsx.UnsupportedFields(errs.ToAggregate())
+	}
+
+	return capiMachine, openStackMachine, warnings, nil
+}
+
+func (m *openStackMachineAndInfra) toMachineAndInfrastructureMachine() (*capiv1.Machine, client.Object, []string, field.ErrorList) {
+	var (
+		errs     field.ErrorList
+		warnings []string
+	)
+
+	openStackProviderConfig, err := openStackProviderSpecFromRawExtension(m.machine.Spec.ProviderSpec.Value)
+	if err != nil {
+		return nil, nil, nil, field.ErrorList{field.Invalid(field.NewPath("spec", "providerSpec", "value"), m.machine.Spec.ProviderSpec.Value, err.Error())}
+	}
+
+	capoMachine, machineErrs := m.toOpenStackMachine(openStackProviderConfig)
+	if machineErrs != nil {
+		errs = append(errs, machineErrs...)
+	}
+
+	capiMachine, machineErrs := fromMAPIMachineToCAPIMachine(m.machine, openStackMachineAPIVersion, openStackMachineKind)
+	if machineErrs != nil {
+		errs = append(errs, machineErrs...)
+	}
+
+	if openStackProviderConfig.UserDataSecret != nil && openStackProviderConfig.UserDataSecret.Name != "" {
+		capiMachine.Spec.Bootstrap = capiv1.Bootstrap{
+			DataSecretName: &openStackProviderConfig.UserDataSecret.Name,
+		}
+	}
+
+	if openStackProviderConfig.AvailabilityZone != "" {
+		capiMachine.Spec.FailureDomain = ptr.To(openStackProviderConfig.AvailabilityZone)
+	}
+
+	// Populate the CAPI Machine ClusterName from the OCP Infrastructure object.
+	if m.infrastructure == nil || m.infrastructure.Status.InfrastructureName == "" {
+		errs = append(errs, field.Invalid(field.NewPath("infrastructure", "status", "infrastructureName"), m.infrastructure.Status.InfrastructureName, "infrastructure cannot be nil and infrastructure.Status.InfrastructureName cannot be empty"))
+	} else {
+		capiMachine.Spec.ClusterName = m.infrastructure.Status.InfrastructureName
+		// The machine.openshift.io labels the MAPI Machine lacks are back-filled, so that MAPI tooling keeps matching it.
+		capiMachine.Labels = conversionutil.BackfillMAPIMachineLabels(capiMachine.Labels, capiMachine.Spec.ClusterName, "")
+	}
+
+	// The InfraMachine should always have the same labels and annotations as the Machine.
+	// See https://github.com/kubernetes-sigs/cluster-api/blob/f88d7ae5155700c2cc367b31ddcc151c9ad579e4/internal/controllers/machineset/machineset_controller.go#L578-L579
+	capoMachine.SetAnnotations(capiMachine.GetAnnotations())
+	capoMachine.SetLabels(capiMachine.GetLabels())
+
+	return capiMachine, capoMachine, warnings, errs
+}
+
+// ToMachineSetAndMachineTemplate converts a mapi2capi OpenStackMachineSetAndInfra into a CAPI MachineSet and CAPO OpenStackMachineTemplate.
+//
+//nolint:dupl
+func (m *openStackMachineSetAndInfra) ToMachineSetAndMachineTemplate() (*capiv1.MachineSet, client.Object, []string, error) {
+	var (
+		errs     []error
+		warnings []string
+	)
+
+	capiMachine, capoMachineObj, warn, err := m.toMachineAndInfrastructureMachine()
+	if err != nil {
+		errs = append(errs, err.ToAggregate().Errors()...)
+	}
+
+	warnings = append(warnings, warn...)
+
+	capoMachine, ok := capoMachineObj.(*capov1.OpenStackMachine)
+	if !ok {
+		panic(fmt.Errorf("%w: %T", errUnexpectedObjectTypeForMachine, capoMachineObj))
+	}
+
+	capoMachineTemplate := openStackMachineToOpenStackMachineTemplate(capoMachine, m.machineSet.Name, capiNamespace)
+
+	capiMachineSet, machineSetErrs := fromMAPIMachineSetToCAPIMachineSet(m.machineSet)
+	if machineSetErrs != nil {
+		errs = append(errs, machineSetErrs.Errors()...)
+	}
+
+	capiMachineSet.Spec.Template.Spec = capiMachine.Spec
+
+	// We have to merge these two maps so that labels and annotations added to the template objectmeta are persisted
+	// along with the labels and annotations from the machine objectmeta.
+	capiMachineSet.Spec.Template.ObjectMeta.Labels = mergeMaps(capiMachineSet.Spec.Template.ObjectMeta.Labels, capiMachine.Labels)
+	capiMachineSet.Spec.Template.ObjectMeta.Annotations = mergeMaps(capiMachineSet.Spec.Template.ObjectMeta.Annotations, capiMachine.Annotations)
+
+	// Override the reference so that it matches the OpenStackMachineTemplate.
+	capiMachineSet.Spec.Template.Spec.InfrastructureRef.Kind = openStackTemplateKind
+	capiMachineSet.Spec.Template.Spec.InfrastructureRef.Name = capoMachineTemplate.Name
+
+	if m.infrastructure == nil || m.infrastructure.Status.InfrastructureName == "" {
+		errs = append(errs, field.Invalid(field.NewPath("infrastructure", "status", "infrastructureName"), m.infrastructure.Status.InfrastructureName, "infrastructure cannot be nil and infrastructure.Status.InfrastructureName cannot be empty"))
+	} else {
+		capiMachineSet.Spec.Template.Spec.ClusterName = m.infrastructure.Status.InfrastructureName
+		capiMachineSet.Spec.ClusterName = m.infrastructure.Status.InfrastructureName
+
+		backfillCAPIMachineSetLabels(capiMachineSet)
+	}
+
+	if len(errs) > 0 {
+		return nil, nil, warnings, errorsx.UnsupportedFields(utilerrors.NewAggregate(errs))
+	}
+
+	return capiMachineSet, capoMachineTemplate, warnings, nil
+}
+
+// convertMAPOOpenStackCloudsSecretToCAPO converts the clouds.yaml secret, and the cloud selected in it, to the
+// identityRef of the OpenStackMachine. The machines using the credentials of the cluster have no identityRef, as the
+// credentials of the OpenStackCluster are used. The secret is copied to the Cluster API namespace by the machine set
+// synchronization, under the same name.
+func convertMAPOOpenStackCloudsSecretToCAPO(cloudsSecret *corev1.SecretReference, cloudName string) *capov1.OpenStackIdentityReference {
+	secretName := openStackCloudsSecretName
+	if cloudsSecret != nil && cloudsSecret.Name != "" {
+		secretName = cloudsSecret.Name
+	}
+
+	if cloudName == "" {
+		cloudName = openStackCloudName
+	}
+
+	if secretName == openStackCloudsSecretName && cloudName == openStackCloudName {
+		return nil
+	}
+
+	return &capov1.OpenStackIdentityReference{
+		Name:      secretName,
+		CloudName: cloudName,
+	}
+}
+
+// openStackProviderSpecFromRawExtension unmarshalls a raw extension into an OpenstackProviderSpec type.
+func openStackProviderSpecFromRawExtension(rawExtension *runtime.RawExtension) (mapiv1alpha1.OpenstackProviderSpec, error) {
+	if rawExtension == nil {
+		return mapiv1alpha1.OpenstackProviderSpec{}, nil
+	}
+
+	spec := mapiv1alpha1.OpenstackProviderSpec{}
+	if err := yaml.Unmarshal(rawExtension.Raw, &spec); err != nil {
+		return mapiv1alpha1.OpenstackProviderSpec{}, fmt.Errorf("error unmarshalling providerSpec: %w", err)
+	}
+
+	return spec, nil
+}
+
+// toOpenStackMachine converts an OpenstackProviderSpec to an OpenStackMachine.
+//
+//nolint:funlen
+func (m *openStackMachineAndInfra) toOpenStackMachine(providerSpec mapiv1alpha1.OpenstackProviderSpec) (*capov1.OpenStackMachine, field.ErrorList) {
+	fldPath := field.NewPath("spec", "providerSpec", "value")
+
+	var errs field.ErrorList
+
+	// Machine API creates the ports of the networks first, then the ports listed explicitly.
+	ports := []capov1.PortOpts{}
+
+	for i, network := range providerSpec.Networks {
+		port, portErrs := convertMAPOOpenStackNetworkToCAPOPort(fldPath.Child("networks").Index(i), network)
+		errs = append(errs, portErrs...)
+		ports = append(ports, port)
+	}
+
+	for i, portOpts := range providerSpec.Ports {
+		port, portErrs := convertMAPOOpenStackPortToCAPO(fldPath.Child("ports").Index(i), portOpts)
+		errs = append(errs, portErrs...)
+		ports = append(ports, port)
+	}
+
+	securityGroups := []capov1.SecurityGroupParam{}
+
+	for i, securityGroup := range providerSpec.SecurityGroups {
+		capoSecurityGroup, sgErrs := convertMAPOOpenStackSecurityGroupToCAPO(fldPath.Child("securityGroups").Index(i), securityGroup)
+		errs = append(errs, sgErrs...)
+		securityGroups = append(securityGroups, capoSecurityGroup)
+	}
+
+	errs = append(errs, validateMAPOOpenStackPrimarySubnet(fldPath.Child("primarySubnet"), providerSpec.PrimarySubnet, ports)...)
+
+	image, imageErrs := convertMAPOOpenStackImageToCAPO(fldPath, providerSpec)
+	errs = append(errs, imageErrs...)
+
+	rootVolume, rootVolumeErrs := convertMAPOOpenStackRootVolumeToCAPO(fldPath.Child("rootVolume"), providerSpec.RootVolume)
+	errs = append(errs, rootVolumeErrs...)
+
+	spec := capov1.OpenStackMachineSpec{
+		// ProviderID: This is populated when this is called in higher level funcs (ToMachine(), ToMachineSet()).
+		Flavor:                 ptr.To(providerSpec.Flavor),
+		Image:                  image,
+		SSHKeyName:             providerSpec.KeyName,
+		Ports:                  ports,
+		SecurityGroups:         securityGroups,
+		Trunk:                  providerSpec.Trunk,
+		Tags:                   providerSpec.Tags,
+		ServerMetadata:         convertMAPOOpenStackServerMetadataToCAPO(providerSpec.ServerMetadata),
+		ConfigDrive:            providerSpec.ConfigDrive,
+		RootVolume:             rootVolume,
+		AdditionalBlockDevices: convertMAPOOpenStackAdditionalBlockDevicesToCAPO(providerSpec.AdditionalBlockDevices),
+		ServerGroup:            convertMAPOOpenStackServerGroupToCAPO(providerSpec.ServerGroupID, providerSpec.ServerGroupName),
+		IdentityRef:            convertMAPOOpenStackCloudsSecretToCAPO(providerSpec.CloudsSecret, providerSpec.CloudName),
+	}
+
+	if len(spec.Ports) == 0 {
+		spec.Ports = nil
+	}
+
+	if len(spec.SecurityGroups) == 0 {
+		spec.SecurityGroups = nil
+	}
+
+	if !reflect.DeepEqual(providerSpec.ObjectMeta, metav1.ObjectMeta{}) {
+		// We don't support setting the object metadata in the provider spec.
+		// It's only present for the purpose of the raw extension and doesn't have any functionality.
+		errs = append(errs, field.Invalid(fldPath.Child("metadata"), providerSpec.ObjectMeta, "metadata is not supported"))
+	}
+
+	if providerSpec.FloatingIP != "" {
+		// Floating IPs are only assigned to the bastion by Cluster API, or from an IPAddress pool.
+		errs = append(errs, field.Invalid(fldPath.Child("floatingIP"), providerSpec.FloatingIP, "floatingIP is not supported"))
+	}
+
+	// SshUserName - Ignore as it is not used by the Machine API.
+
+	return &capov1.OpenStackMachine{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: capov1.SchemeGroupVersion.String(),
+			Kind:       openStackMachineKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.machine.Name,
+			Namespace: capiNamespace,
+		},
+		Spec: spec,
+	}, errs
+}
+
+func openStackMachineToOpenStackMachineTemplate(capoMachine *capov1.OpenStackMachine, name string, namespace string) *capov1.OpenStackMachineTemplate {
+	return &capov1.OpenStackMachineTemplate{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: capov1.SchemeGroupVersion.String(),
+			Kind:       openStackTemplateKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: capov1.OpenStackMachineTemplateSpec{
+			Template: capov1.OpenStackMachineTemplateResource{
+				Spec: capoMachine.Spec,
+			},
+		},
+	}
+}
+
+// convertMAPOOpenStackNetworkToCAPOPort converts a Machine API network into the port the Machine API creates for it,
+// with a fixed IP on each of its subnets.
+func convertMAPOOpenStackNetworkToCAPOPort(fldPath *field.Path, network mapiv1alpha1.NetworkParam) (capov1.PortOpts, field.ErrorList) {
+	var errs field.ErrorList
+
+	port := capov1.PortOpts{
+		Tags: network.PortTags,
+	}
+
+	networkParam, networkErrs := convertMAPOOpenStackNetworkParamToCAPO(fldPath, network.UUID, network.Filter)
+	errs = append(errs, networkErrs...)
+	port.Network = networkParam
+
+	for i, subnet := range network.Subnets {
+		subnetFldPath := fldPath.Child("subnets").Index(i)
+
+		subnetParam, subnetErrs := convertMAPOOpenStackSubnetParamToCAPO(subnetFldPath, subnet.UUID, subnet.Filter)
+		errs = append(errs, subnetErrs...)
+		port.FixedIPs = append(port.FixedIPs, capov1.FixedIP{Subnet: subnetParam})
+
+		// The port of a network is shared by its subnets, their port options are merged into it.
+		port.Tags = append(port.Tags, subnet.PortTags...)
+
+		if subnet.PortSecurity != nil && network.PortSecurity != nil && *subnet.PortSecurity != *network.PortSecurity {
+			errs = append(errs, field.Invalid(subnetFldPath.Child("portSecurity"), *subnet.PortSecurity, "portSecurity must match the portSecurity of the network"))
+		} else if subnet.PortSecurity != nil {
+			network.PortSecurity = subnet.PortSecurity
+		}
+	}
+
+	if network.FixedIp != "" {
+		errs = append(errs, field.Invalid(fldPath.Child("fixedIp"), network.FixedIp, "fixedIp is not supported, use ports with fixedIPs instead"))
+	}
+
+	// NoAllowedAddressPairs - Ignore as Cluster API never adds allowed address pairs to the ports of the networks.
+
+	resolvedFields, resolvedErrs := convertMAPOOpenStackResolvedPortFieldsToCAPO(fldPath, network.VNICType, network.Profile, network.PortSecurity)
+	errs = append(errs, resolvedErrs...)
+	port.ResolvedPortSpecFields = resolvedFields
+
+	return port, errs
+}
+
+// convertMAPOOpenStackPortToCAPO converts a port listed explicitly in the Machine API provider spec.
+func convertMAPOOpenStackPortToCAPO(fldPath *field.Path, portOpts mapiv1alpha1.PortOpts) (capov1.PortOpts, field.ErrorList) {
+	var errs field.ErrorList
+
+	port := capov1.PortOpts{
+		Tags:  portOpts.Tags,
+		Trunk: portOpts.Trunk,
+	}
+
+	if portOpts.NetworkID != "" {
+		port.Network = &capov1.NetworkParam{ID: ptr.To(portOpts.NetworkID)}
+	}
+
+	if portOpts.NameSuffix != "" {
+		port.NameSuffix = ptr.To(portOpts.NameSuffix)
+	}
+
+	if portOpts.Description != "" {
+		port.Description = ptr.To(portOpts.Description)
+	}
+
+	for i, fixedIP := range portOpts.FixedIPs {
+		capoFixedIP := capov1.FixedIP{
+			Subnet: &capov1.SubnetParam{ID: ptr.To(fixedIP.SubnetID)},
+		}
+
+		if fixedIP.SubnetID == "" {
+			errs = append(errs, field.Required(fldPath.Child("fixedIPs").Index(i).Child("subnetID"), "subnetID is required"))
+		}
+
+		if fixedIP.IPAddress != "" {
+			capoFixedIP.IPAddress = ptr.To(fixedIP.IPAddress)
+		}
+
+		port.FixedIPs = append(port.FixedIPs, capoFixedIP)
+	}
+
+	if portOpts.SecurityGroups != nil {
+		if len(*portOpts.SecurityGroups) == 0 {
+			// Cluster API falls back to the security groups of the machine when the port has none.
+			errs = append(errs, field.Invalid(fldPath.Child("securityGroups"), *portOpts.SecurityGroups, "a port without security groups is not supported, disable portSecurity instead"))
+		}
+
+		for _, securityGroupID := range *portOpts.SecurityGroups {
+			port.SecurityGroups = append(port.SecurityGroups, capov1.SecurityGroupParam{ID: ptr.To(securityGroupID)})
+		}
+	}
+
+	if portOpts.TenantID != "" {
+		errs = append(errs, field.Invalid(fldPath.Child("tenantID"), portOpts.TenantID, "tenantID is not supported"))
+	}
+
+	if portOpts.ProjectID != "" {
+		errs = append(errs, field.Invalid(fldPath.Child("projectID"), portOpts.ProjectID, "projectID is not supported"))
+	}
+
+	resolvedFields, resolvedErrs := convertMAPOOpenStackResolvedPortFieldsToCAPO(fldPath, portOpts.VNICType, portOpts.Profile, portOpts.PortSecurity)
+	errs = append(errs, resolvedErrs...)
+	port.ResolvedPortSpecFields = resolvedFields
+
+	port.AdminStateUp = portOpts.AdminStateUp
+
+	if portOpts.MACAddress != "" {
+		port.MACAddress = ptr.To(portOpts.MACAddress)
+	}
+
+	for _, pair := range portOpts.AllowedAddressPairs {
+		capoPair := capov1.AddressPair{IPAddress: pair.IPAddress}
+
+		if pair.MACAddress != "" {
+			capoPair.MACAddress = ptr.To(pair.MACAddress)
+		}
+
+		port.AllowedAddressPairs = append(port.AllowedAddressPairs, capoPair)
+	}
+
+	if portOpts.DeprecatedHostID != "" {
+		port.HostID = ptr.To(portOpts.DeprecatedHostID)
+	}
+
+	return port, errs
+}
+
+// convertMAPOOpenStackResolvedPortFieldsToCAPO converts the binding options shared by networks and ports,
+// such as the ones of SR-IOV ports.
+func convertMAPOOpenStackResolvedPortFieldsToCAPO(fldPath *field.Path, vnicType string, profile map[string]string, portSecurity *bool) (capov1.ResolvedPortSpecFields, field.ErrorList) {
+	var errs field.ErrorList
+
+	fields := capov1.ResolvedPortSpecFields{}
+
+	if vnicType != "" {
+		fields.VNICType = ptr.To(vnicType)
+	}
+
+	if portSecurity != nil {
+		fields.DisablePortSecurity = ptr.To(!*portSecurity)
+	}
+
+	if len(profile) == 0 {
+		return fields, errs
+	}
+
+	bindingProfile := &capov1.BindingProfile{}
+
+	// Sort the keys so that the errors are stable.
+	keys := make([]string, 0, len(profile))
+	for key := range profile {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		value := profile[key]
+
+		switch {
+		case key == openStackProfileCapabilitiesKey && value == openStackProfileSwitchdevCapabilities:
+			bindingProfile.OVSHWOffload = ptr.To(true)
+		case key == openStackProfileTrustedKey && (value == "true" || value == "false"):
+			bindingProfile.TrustedVF = ptr.To(value == "true")
+		default:
+			errs = append(errs, field.Invalid(fldPath.Child("profile").Key(key), value,
+				fmt.Sprintf("only %s: %s and %s: true|false are supported", openStackProfileCapabilitiesKey, openStackProfileSwitchdevCapabilities, openStackProfileTrustedKey)))
+		}
+	}
+
+	fields.Profile = bindingProfile
+
+	return fields, errs
+}
+
+// convertMAPOOpenStackNetworkParamToCAPO converts the reference to a network, by ID or by filter.
+func convertMAPOOpenStackNetworkParamToCAPO(fldPath *field.Path, uuid string, filter mapiv1alpha1.Filter) (*capov1.NetworkParam, field.ErrorList) {
+	var errs field.ErrorList
+
+	id := uuid
+	if id == "" {
+		id = filter.ID
+	} else if filter.ID != "" && filter.ID != uuid {
+		errs = append(errs, field.Invalid(fldPath.Child("filter", "id"), filter.ID, "filter.id must match uuid"))
+	}
+
+	projectID, projectErrs := convertMAPOOpenStackProjectIDToCAPO(fldPath.Child("filter"), filter.TenantID, filter.ProjectID)
+	errs = append(errs, projectErrs...)
+
+	if filter.DeprecatedStatus != "" || filter.DeprecatedAdminStateUp != nil || filter.DeprecatedShared != nil ||
+		filter.DeprecatedMarker != "" || filter.DeprecatedLimit != 0 || filter.DeprecatedSortKey != "" || filter.DeprecatedSortDir != "" {
+		errs = append(errs, field.Invalid(fldPath.Child("filter"), filter, "deprecated network filter fields are not supported"))
+	}
+
+	capoFilter := &capov1.NetworkFilter{
+		Name:                filter.Name,
+		Description:         filter.Description,
+		ProjectID:           projectID,
+		FilterByNeutronTags: convertMAPOOpenStackNeutronTagsToCAPO(filter.Tags, filter.TagsAny, filter.NotTags, filter.NotTagsAny),
+	}
+
+	if id != "" {
+		if !capoFilter.IsZero() {
+			errs = append(errs, field.Invalid(fldPath.Child("filter"), filter, "a network cannot be selected by both ID and filter"))
+		}
+
+		return &capov1.NetworkParam{ID: ptr.To(id)}, errs
+	}
+
+	if capoFilter.IsZero() {
+		return nil, append(errs, field.Required(fldPath.Child("uuid"), "a network must be selected by uuid or filter"))
+	}
+
+	return &capov1.NetworkParam{Filter: capoFilter}, errs
+}
+
+// convertMAPOOpenStackSubnetParamToCAPO converts the reference to a subnet, by ID or by filter.
+func convertMAPOOpenStackSubnetParamToCAPO(fldPath *field.Path, uuid string, filter mapiv1alpha1.SubnetFilter) (*capov1.SubnetParam, field.ErrorList) {
+	var errs field.ErrorList
+
+	id := uuid
+	if id == "" {
+		id = filter.ID
+	} else if filter.ID != "" && filter.ID != uuid {
+		errs = append(errs, field.Invalid(fldPath.Child("filter", "id"), filter.ID, "filter.id must match uuid"))
+	}
+
+	projectID, projectErrs := convertMAPOOpenStackProjectIDToCAPO(fldPath.Child("filter"), filter.TenantID, filter.ProjectID)
+	errs = append(errs, projectErrs...)
+
+	if filter.NetworkID != "" {
+		// The subnets are always looked up in the network of the port.
+		errs = append(errs, field.Invalid(fldPath.Child("filter", "networkId"), filter.NetworkID, "networkId is not supported"))
+	}
+
+	if filter.SubnetPoolID != "" {
+		errs = append(errs, field.Invalid(fldPath.Child("filter", "subnetpoolId"), filter.SubnetPoolID, "subnetpoolId is not supported"))
+	}
+
+	if filter.DeprecatedEnableDHCP != nil || filter.DeprecatedMarker != "" || filter.DeprecatedLimit != 0 ||
+		filter.DeprecatedSortKey != "" || filter.DeprecatedSortDir != "" {
+		errs = append(errs, field.Invalid(fldPath.Child("filter"), filter, "deprecated subnet filter fields are not supported"))
+	}
+
+	capoFilter := &capov1.SubnetFilter{
+		Name:                filter.Name,
+		Description:         filter.Description,
+		ProjectID:           projectID,
+		IPVersion:           filter.IPVersion,
+		GatewayIP:           filter.GatewayIP,
+		CIDR:                filter.CIDR,
+		IPv6AddressMode:     filter.IPv6AddressMode,
+		IPv6RAMode:          filter.IPv6RAMode,
+		FilterByNeutronTags: convertMAPOOpenStackNeutronTagsToCAPO(filter.Tags, filter.TagsAny, filter.NotTags, filter.NotTagsAny),
+	}
+
+	if id != "" {
+		if !capoFilter.IsZero() {
+			errs = append(errs, field.Invalid(fldPath.Child("filter"), filter, "a subnet cannot be selected by both ID and filter"))
+		}
+
+		return &capov1.SubnetParam{ID: ptr.To(id)}, errs
+	}
+
+	if capoFilter.IsZero() {
+		return nil, append(errs, field.Required(fldPath.Child("uuid"), "a subnet must be selected by uuid or filter"))
+	}
+
+	return &capov1.SubnetParam{Filter: capoFilter}, errs
+}
+
+// convertMAPOOpenStackSecurityGroupToCAPO converts the reference to a security group, by ID, name or filter.
+func convertMAPOOpenStackSecurityGroupToCAPO(fldPath *field.Path, securityGroup mapiv1alpha1.SecurityGroupParam) (capov1.SecurityGroupParam, field.ErrorList) {
+	var errs field.ErrorList
+
+	filter := securityGroup.Filter
+
+	id := securityGroup.UUID
+	if id == "" {
+		id = filter.ID
+	} else if filter.ID != "" && filter.ID != id {
+		errs = append(errs, field.Invalid(fldPath.Child("filter", "id"), filter.ID, "filter.id must match uuid"))
+	}
+
+	name := securityGroup.Name
+	if name == "" {
+		name = filter.Name
+	} else if filter.Name != "" && filter.Name != name {
+		errs = append(errs, field.Invalid(fldPath.Child("filter", "name"), filter.Name, "filter.name must match name"))
+	}
+
+	projectID, projectErrs := convertMAPOOpenStackProjectIDToCAPO(fldPath.Child("filter"), filter.TenantID, filter.ProjectID)
+	errs = append(errs, projectErrs...)
+
+	if filter.DeprecatedLimit != 0 || filter.DeprecatedMarker != "" || filter.DeprecatedSortKey != "" || filter.DeprecatedSortDir != "" {
+		errs = append(errs, field.Invalid(fldPath.Child("filter"), filter, "deprecated security group filter fields are not supported"))
+	}
+
+	capoFilter := &capov1.SecurityGroupFilter{
+		Name:                name,
+		Description:         filter.Description,
+		ProjectID:           projectID,
+		FilterByNeutronTags: convertMAPOOpenStackNeutronTagsToCAPO(filter.Tags, filter.TagsAny, filter.NotTags, filter.NotTagsAny),
+	}
+
+	if id != "" {
+		if !capoFilter.IsZero() {
+			errs = append(errs, field.Invalid(fldPath, securityGroup, "a security group cannot be selected by both ID and name or filter"))
+		}
+
+		return capov1.SecurityGroupParam{ID: ptr.To(id)}, errs
+	}
+
+	if capoFilter.IsZero() {
+		return capov1.SecurityGroupParam{}, append(errs, field.Required(fldPath.Child("uuid"), "a security group must be selected by uuid, name or filter"))
+	}
+
+	return capov1.SecurityGroupParam{Filter: capoFilter}, errs
+}
+
+// convertMAPOOpenStackProjectIDToCAPO returns the project of a filter, tenantId being the former name of projectId.
+func convertMAPOOpenStackProjectIDToCAPO(fldPath *field.Path, tenantID, projectID string) (string, field.ErrorList) {
+	if tenantID != "" && projectID != "" && tenantID != projectID {
+		return projectID, field.ErrorList{field.Invalid(fldPath.Child("tenantId"), tenantID, "tenantId must match projectId")}
+	}
+
+	if projectID != "" {
+		return projectID, nil
+	}
+
+	return tenantID, nil
+}
+
+// convertMAPOOpenStackNeutronTagsToCAPO converts the comma separated tags of a filter.
+func convertMAPOOpenStackNeutronTagsToCAPO(tags, tagsAny, notTags, notTagsAny string) capov1.FilterByNeutronTags {
+	return capov1.FilterByNeutronTags{
+		Tags:       splitOpenStackNeutronTags(tags),
+		TagsAny:    splitOpenStackNeutronTags(tagsAny),
+		NotTags:    splitOpenStackNeutronTags(notTags),
+		NotTagsAny: splitOpenStackNeutronTags(notTagsAny),
+	}
+}
+
+func splitOpenStackNeutronTags(tags string) []capov1.NeutronTag {
+	var neutronTags []capov1.NeutronTag
+
+	for _, tag := range strings.Split(tags, ",") {
+		if tag != "" {
+			neutronTags = append(neutronTags, capov1.NeutronTag(tag))
+		}
+	}
+
+	return neutronTags
+}
+
+// validateMAPOOpenStackPrimarySubnet ensures the primary subnet is the first subnet of the first port,
+// as Cluster API always uses it for the addresses of the machine.
+func validateMAPOOpenStackPrimarySubnet(fldPath *field.Path, primarySubnet string, ports []capov1.PortOpts) field.ErrorList {
+	if primarySubnet == "" {
+		return nil
+	}
+
+	if len(ports) > 0 && len(ports[0].FixedIPs) > 0 && ports[0].FixedIPs[0].Subnet != nil &&
+		ptr.Deref(ports[0].FixedIPs[0].Subnet.ID, "") == primarySubnet {
+		return nil
+	}
+
+	return field.ErrorList{field.Invalid(fldPath, primarySubnet, "primarySubnet must be the ID of the first subnet of the first network or port")}
+}
+
+// convertMAPOOpenStackImageToCAPO converts the image, looked up by name by the Machine API.
+func convertMAPOOpenStackImageToCAPO(fldPath *field.Path, providerSpec mapiv1alpha1.OpenstackProviderSpec) (capov1.ImageParam, field.ErrorList) {
+	image := providerSpec.Image
+
+	// The source of the root volume is the deprecated way of setting the image, and is ignored when the image is set.
+	if image == "" && providerSpec.RootVolume != nil {
+		image = providerSpec.RootVolume.SourceUUID
+	}
+
+	if image == "" {
+		return capov1.ImageParam{}, field.ErrorList{field.Required(fldPath.Child("image"), "image is required")}
+	}
+
+	return capov1.ImageParam{Filter: &capov1.ImageFilter{Name: ptr.To(image)}}, nil
+}
+
+func convertMAPOOpenStackRootVolumeToCAPO(fldPath *field.Path, rootVolume *mapiv1alpha1.RootVolume) (*capov1.RootVolume, field.ErrorList) {
+	if rootVolume == nil {
+		return nil, nil
+	}
+
+	// SourceUUID - Converted to the image of the machine.
+	// DeprecatedSourceType, DeprecatedDeviceType - Ignore as they are ignored by the Machine API.
+
+	return &capov1.RootVolume{
+		SizeGiB: rootVolume.Size,
+		BlockDeviceVolume: capov1.BlockDeviceVolume{
+			Type:             rootVolume.VolumeType,
+			AvailabilityZone: convertMAPOOpenStackVolumeAvailabilityZoneToCAPO(rootVolume.Zone),
+		},
+	}, nil
+}
+
+func convertMAPOOpenStackAdditionalBlockDevicesToCAPO(blockDevices []mapiv1alpha1.AdditionalBlockDevice) []capov1.AdditionalBlockDevice {
+	var capoBlockDevices []capov1.AdditionalBlockDevice
+
+	for _, blockDevice := range blockDevices {
+		capoBlockDevice := capov1.AdditionalBlockDevice{
+			Name:    blockDevice.Name,
+			SizeGiB: blockDevice.SizeGiB,
+			Storage: capov1.BlockDeviceStorage{
+				Type: capov1.BlockDeviceType(blockDevice.Storage.Type),
+			},
+		}
+
+		if blockDevice.Storage.Volume != nil {
+			capoBlockDevice.Storage.Volume = &capov1.BlockDeviceVolume{
+				Type:             blockDevice.Storage.Volume.Type,
+				AvailabilityZone: convertMAPOOpenStackVolumeAvailabilityZoneToCAPO(blockDevice.Storage.Volume.AvailabilityZone),
+			}
+		}
+
+		capoBlockDevices = append(capoBlockDevices, capoBlockDevice)
+	}
+
+	return capoBlockDevices
+}
+
+// convertMAPOOpenStackVolumeAvailabilityZoneToCAPO converts the availability zone of a volume.
+// The volume is created without an explicit availability zone when it is not set.
+func convertMAPOOpenStackVolumeAvailabilityZoneToCAPO(zone string) *capov1.VolumeAvailabilityZone {
+	if zone == "" {
+		return nil
+	}
+
+	return &capov1.VolumeAvailabilityZone{
+		From: capov1.VolumeAZFromName,
+		Name: ptr.To(capov1.VolumeAZName(zone)),
+	}
+}
+
+func convertMAPOOpenStackServerGroupToCAPO(serverGroupID, serverGroupName string) *capov1.ServerGroupParam {
+	// The Machine API ignores the name of the server group when its ID is set.
+	switch {
+	case serverGroupID != "":
+		return &capov1.ServerGroupParam{ID: ptr.To(serverGroupID)}
+	case serverGroupName != "":
+		return &capov1.ServerGroupParam{Filter: &capov1.ServerGroupFilter{Name: ptr.To(serverGroupName)}}
+	default:
+		return nil
+	}
+}
+
+// convertMAPOOpenStackServerMetadataToCAPO converts the server metadata, sorted by key so that the conversion is stable.
+func convertMAPOOpenStackServerMetadataToCAPO(serverMetadata map[string]string) []capov1.ServerMetadata {
+	var capoServerMetadata []capov1.ServerMetadata
+
+	for key, value := range serverMetadata {
+		capoServerMetadata = append(capoServerMetadata, capov1.ServerMetadata{Key: key, Value: value})
+	}
+
+	slices.SortFunc(capoServerMetadata, func(a, b capov1.ServerMetadata) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	return capoServerMetadata
+}
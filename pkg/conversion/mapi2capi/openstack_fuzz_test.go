@@ -0,0 +1,326 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package mapi2capi_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	fuzz "github.com/google/gofuzz"
+
+	configv1 "github.com/openshift/api/config/v1"
+	mapiv1alpha1 "github.com/openshift/api/machine/v1alpha1"
+	"github.com/openshift/cluster-capi-operator/pkg/conversion/capi2mapi"
+	"github.com/openshift/cluster-capi-operator/pkg/conversion/mapi2capi"
+	conversiontest "github.com/openshift/cluster-capi-operator/pkg/conversion/test/fuzz"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtimeserializer "k8s.io/apimachinery/pkg/runtime/serializer"
+	capov1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1beta1"
+	capiv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("OpenStack Fuzz (mapi2capi)", func() {
+	infra := &configv1.Infrastructure{
+		Spec: configv1.InfrastructureSpec{},
+		Status: configv1.InfrastructureStatus{
+			InfrastructureName: "sample-cluster-name",
+		},
+	}
+
+	infraCluster := &capov1.OpenStackCluster{}
+
+	Context("OpenStackMachine Conversion", func() {
+		fromMachineAndOpenStackMachineAndOpenStackCluster := func(machine *capiv1.Machine, infraMachine client.Object, infraCluster client.Object) capi2mapi.MachineAndInfrastructureMachine {
+			openStackMachine, ok := infraMachine.(*capov1.OpenStackMachine)
+			Expect(ok).To(BeTrue(), "input infra machine should be of type %T, got %T", &capov1.OpenStackMachine{}, infraMachine)
+
+			openStackCluster, ok := infraCluster.(*capov1.OpenStackCluster)
+			Expect(ok).To(BeTrue(), "input infra cluster should be of type %T, got %T", &capov1.OpenStackCluster{}, infraCluster)
+
+			return capi2mapi.FromMachineAndOpenStackMachineAndOpenStackCluster(machine, openStackMachine, openStackCluster)
+		}
+
+		conversiontest.MAPI2CAPIMachineRoundTripFuzzTest(
+			scheme,
+			infra,
+			infraCluster,
+			mapi2capi.FromOpenStackMachineAndInfra,
+			fromMachineAndOpenStackMachineAndOpenStackCluster,
+			conversiontest.ObjectMetaFuzzerFuncs(mapiNamespace),
+			conversiontest.MAPIMachineFuzzerFuncs(&mapiv1alpha1.OpenstackProviderSpec{}, openStackProviderIDFuzzer, infra.Status.InfrastructureName),
+			openStackProviderSpecFuzzerFuncs,
+		)
+	})
+
+	Context("OpenStackMachineSet Conversion", func() {
+		fromMachineSetAndOpenStackMachineTemplateAndOpenStackCluster := func(machineSet *capiv1.MachineSet, infraMachineTemplate client.Object, infraCluster client.Object) capi2mapi.MachineSetAndMachineTemplate {
+			openStackMachineTemplate, ok := infraMachineTemplate.(*capov1.OpenStackMachineTemplate)
+			Expect(ok).To(BeTrue(), "input infra machine template should be of type %T, got %T", &capov1.OpenStackMachineTemplate{}, infraMachineTemplate)
+
+			openStackCluster, ok := infraCluster.(*capov1.OpenStackCluster)
+			Expect(ok).To(BeTrue(), "input infra cluster should be of type %T, got %T", &capov1.OpenStackCluster{}, infraCluster)
+
+			return capi2mapi.FromMachineSetAndOpenStackMachineTemplateAndOpenStackCluster(machineSet, openStackMachineTemplate, openStackCluster)
+		}
+
+		conversiontest.MAPI2CAPIMachineSetRoundTripFuzzTest(
+			scheme,
+			infra,
+			infraCluster,
+			mapi2capi.FromOpenStackMachineSetAndInfra,
+			fromMachineSetAndOpenStackMachineTemplateAndOpenStackCluster,
+			conversiontest.ObjectMetaFuzzerFuncs(mapiNamespace),
+			conversiontest.MAPIMachineFuzzerFuncs(&mapiv1alpha1.OpenstackProviderSpec{}, openStackProviderIDFuzzer, infra.Status.InfrastructureName),
+			conversiontest.MAPIMachineSetFuzzerFuncs(infra.Status.InfrastructureName),
+			openStackProviderSpecFuzzerFuncs,
+		)
+	})
+})
+
+// FuzzOpenStackMachineRoundTrip round trips fuzzed OpenStack Machines from MAPI to CAPI, replaying the seed corpus in
+// testdata/fuzz/FuzzOpenStackMachineRoundTrip on every run. Explore new inputs with:
+//
+//	go test ./pkg/conversion/mapi2capi -run '^$' -fuzz FuzzOpenStackMachineRoundTrip
+func FuzzOpenStackMachineRoundTrip(f *testing.F) {
+	infra := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			InfrastructureName: "sample-cluster-name",
+		},
+	}
+
+	conversiontest.MAPI2CAPIMachineRoundTripFuzzTarget(f, conversiontest.MAPI2CAPIMachineRoundTripTarget{
+		Scheme:        scheme,
+		Infra:         infra,
+		InfraCluster:  &capov1.OpenStackCluster{},
+		MAPIConverter: mapi2capi.FromOpenStackMachineAndInfra,
+		CAPIConverter: func(machine *capiv1.Machine, infraMachine client.Object, infraCluster client.Object) capi2mapi.MachineAndInfrastructureMachine {
+			return capi2mapi.FromMachineAndOpenStackMachineAndOpenStackCluster(machine, infraMachine.(*capov1.OpenStackMachine), infraCluster.(*capov1.OpenStackCluster)) //nolint:forcetypeassert
+		},
+		FuzzerFuncs: []fuzzer.FuzzerFuncs{
+			conversiontest.ObjectMetaFuzzerFuncs(mapiNamespace),
+			conversiontest.MAPIMachineFuzzerFuncs(&mapiv1alpha1.OpenstackProviderSpec{}, openStackProviderIDFuzzer, infra.Status.InfrastructureName),
+			openStackProviderSpecFuzzerFuncs,
+		},
+	})
+}
+
+func openStackProviderIDFuzzer(c fuzz.Continue) string {
+	return "openstack:///" + strings.ReplaceAll(c.RandString(), "/", "")
+}
+
+//nolint:funlen
+func openStackProviderSpecFuzzerFuncs(codecs runtimeserializer.CodecFactory) []interface{} {
+	return []interface{}{
+		func(network *mapiv1alpha1.NetworkParam, c fuzz.Continue) {
+			c.FuzzNoCustom(network)
+
+			// A network is selected either by UUID or by filter.
+			network.UUID = ""
+			network.Filter = mapiv1alpha1.Filter{}
+
+			if c.RandBool() {
+				network.UUID = fuzzOpenStackNonEmptyString(c)
+			} else {
+				network.Filter = mapiv1alpha1.Filter{
+					Name:        fuzzOpenStackNonEmptyString(c),
+					Description: c.RandString(),
+					ProjectID:   c.RandString(),
+					Tags:        fuzzOpenStackNeutronTags(c),
+					TagsAny:     fuzzOpenStackNeutronTags(c),
+					NotTags:     fuzzOpenStackNeutronTags(c),
+					NotTagsAny:  fuzzOpenStackNeutronTags(c),
+				}
+			}
+
+			// Fixed IPs are only supported on ports, and the allowed address pairs are never added to the ports
+			// of the networks by Cluster API.
+			network.FixedIp = ""
+			network.NoAllowedAddressPairs = false
+
+			network.Profile = fuzzOpenStackProfile(c)
+		},
+		func(subnet *mapiv1alpha1.SubnetParam, c fuzz.Continue) {
+			// A subnet is selected either by UUID or by filter.
+			// The port options of the subnets are merged into the port of their network, so they are left unset.
+			if c.RandBool() {
+				*subnet = mapiv1alpha1.SubnetParam{UUID: fuzzOpenStackNonEmptyString(c)}
+
+				return
+			}
+
+			*subnet = mapiv1alpha1.SubnetParam{
+				Filter: mapiv1alpha1.SubnetFilter{
+					Name:            fuzzOpenStackNonEmptyString(c),
+					Description:     c.RandString(),
+					ProjectID:       c.RandString(),
+					IPVersion:       c.Intn(7),
+					GatewayIP:       c.RandString(),
+					CIDR:            c.RandString(),
+					IPv6AddressMode: c.RandString(),
+					IPv6RAMode:      c.RandString(),
+					Tags:            fuzzOpenStackNeutronTags(c),
+					TagsAny:         fuzzOpenStackNeutronTags(c),
+					NotTags:         fuzzOpenStackNeutronTags(c),
+					NotTagsAny:      fuzzOpenStackNeutronTags(c),
+				},
+			}
+		},
+		func(port *mapiv1alpha1.PortOpts, c fuzz.Continue) {
+			c.FuzzNoCustom(port)
+
+			port.NetworkID = fuzzOpenStackNonEmptyString(c)
+
+			// A port only selecting its network and subnets is converted back to a network, the name suffix
+			// keeps it a port.
+			port.NameSuffix = fuzzOpenStackNonEmptyString(c)
+
+			for i := range port.FixedIPs {
+				port.FixedIPs[i].SubnetID = fuzzOpenStackNonEmptyString(c)
+			}
+
+			// Clear fields that are not supported on the ports.
+			port.TenantID = ""
+			port.ProjectID = ""
+
+			// A port without security groups is not supported.
+			if port.SecurityGroups != nil && len(*port.SecurityGroups) == 0 {
+				port.SecurityGroups = nil
+			}
+
+			port.Profile = fuzzOpenStackProfile(c)
+		},
+		func(securityGroup *mapiv1alpha1.SecurityGroupParam, c fuzz.Continue) {
+			// A security group is selected either by UUID or by filter, the name being converted back to the one
+			// of the filter.
+			if c.RandBool() {
+				*securityGroup = mapiv1alpha1.SecurityGroupParam{UUID: fuzzOpenStackNonEmptyString(c)}
+
+				return
+			}
+
+			*securityGroup = mapiv1alpha1.SecurityGroupParam{
+				Filter: mapiv1alpha1.SecurityGroupFilter{
+					Name:        fuzzOpenStackNonEmptyString(c),
+					Description: c.RandString(),
+					ProjectID:   c.RandString(),
+					Tags:        fuzzOpenStackNeutronTags(c),
+					TagsAny:     fuzzOpenStackNeutronTags(c),
+					NotTags:     fuzzOpenStackNeutronTags(c),
+					NotTagsAny:  fuzzOpenStackNeutronTags(c),
+				},
+			}
+		},
+		func(rootVolume *mapiv1alpha1.RootVolume, c fuzz.Continue) {
+			c.FuzzNoCustom(rootVolume)
+
+			// The source of the root volume is the deprecated way of setting the image, and the deprecated types
+			// are ignored by the Machine API.
+			rootVolume.SourceUUID = ""
+			rootVolume.DeprecatedSourceType = ""
+			rootVolume.DeprecatedDeviceType = ""
+		},
+		func(ps *mapiv1alpha1.OpenstackProviderSpec, c fuzz.Continue) {
+			c.FuzzNoCustom(ps)
+
+			// The type meta is always set to these values by the conversion.
+			ps.Kind = "OpenstackProviderSpec"
+			ps.APIVersion = "machine.openshift.io/v1alpha1"
+
+			// The clouds secret is always in the Machine API namespace, and the default secret and cloud are set
+			// when omitted.
+			ps.CloudsSecret = &corev1.SecretReference{Name: fuzzOpenStackNonEmptyString(c), Namespace: mapiNamespace}
+			ps.CloudName = fuzzOpenStackNonEmptyString(c)
+
+			ps.Image = fuzzOpenStackNonEmptyString(c)
+
+			// The name of the server group is ignored when its ID is set.
+			if ps.ServerGroupID != "" {
+				ps.ServerGroupName = ""
+			}
+
+			// The primary subnet must be the first subnet of the first network or port.
+			ps.PrimarySubnet = ""
+
+			switch {
+			case len(ps.Networks) > 0:
+				if len(ps.Networks[0].Subnets) > 0 {
+					ps.PrimarySubnet = ps.Networks[0].Subnets[0].UUID
+				}
+			case len(ps.Ports) > 0:
+				if len(ps.Ports[0].FixedIPs) > 0 {
+					ps.PrimarySubnet = ps.Ports[0].FixedIPs[0].SubnetID
+				}
+			}
+
+			// Clear fields that are not supported in the provider spec.
+			ps.ObjectMeta = metav1.ObjectMeta{}
+			ps.FloatingIP = ""
+			ps.SshUserName = ""
+
+			// Clear pointers to empty structs, the user data secret is always in the namespace of the machine.
+			if ps.UserDataSecret != nil && ps.UserDataSecret.Name == "" {
+				ps.UserDataSecret = nil
+			} else if ps.UserDataSecret != nil {
+				ps.UserDataSecret.Namespace = ""
+			}
+		},
+	}
+}
+
+// fuzzOpenStackNonEmptyString returns a random string which is not empty, for the fields the conversion requires.
+func fuzzOpenStackNonEmptyString(c fuzz.Continue) string {
+	for {
+		if s := c.RandString(); s != "" {
+			return s
+		}
+	}
+}
+
+// fuzzOpenStackNeutronTags returns the comma separated tags of a filter, without empty tags which are dropped by
+// the conversion.
+func fuzzOpenStackNeutronTags(c fuzz.Continue) string {
+	tags := []string{}
+
+	for range c.Intn(3) {
+		if tag := strings.ReplaceAll(c.RandString(), ",", ""); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return strings.Join(tags, ",")
+}
+
+// fuzzOpenStackProfile returns a binding profile, which only supports the OVS hardware offload and trusted VF keys.
+func fuzzOpenStackProfile(c fuzz.Continue) map[string]string {
+	profile := map[string]string{}
+
+	if c.RandBool() {
+		profile["capabilities"] = `["switchdev"]`
+	}
+
+	if c.RandBool() {
+		profile["trusted"] = []string{"true", "false"}[c.Intn(2)]
+	}
+
+	return profile
+}
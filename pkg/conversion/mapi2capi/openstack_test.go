@@ -0,0 +1,231 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package mapi2capi
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	mapiv1alpha1 "github.com/openshift/api/machine/v1alpha1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	machinebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	capov1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1beta1"
+
+	"github.com/openshift/cluster-capi-operator/pkg/conversion/test/matchers"
+)
+
+var _ = Describe("mapi2capi OpenStack conversion", func() {
+	var (
+		infra = &configv1.Infrastructure{
+			Spec:   configv1.InfrastructureSpec{},
+			Status: configv1.InfrastructureStatus{InfrastructureName: "sample-cluster-name"},
+		}
+
+		openStackProviderSpec = func(modify func(*mapiv1alpha1.OpenstackProviderSpec)) machinev1beta1.ProviderSpec {
+			spec := machinebuilder.OpenStackProviderSpec().Build()
+			modify(spec)
+
+			rawBytes, err := json.Marshal(spec)
+			if err != nil {
+				panic(fmt.Sprintf("unable to convert (marshal) test OpenstackProviderSpec to runtime.RawExtension: %v", err))
+			}
+
+			return machinev1beta1.ProviderSpec{Value: &runtime.RawExtension{Raw: rawBytes}}
+		}
+
+		// A machine with a second NIC on a network selected by filter, in a subnet selected by filter.
+		dualNICProviderSpec = func(spec *mapiv1alpha1.OpenstackProviderSpec) {
+			spec.Networks = append(spec.Networks, mapiv1alpha1.NetworkParam{
+				Filter: mapiv1alpha1.Filter{Name: "storage", Tags: "openshiftClusterID=test-cluster,storage"},
+				Subnets: []mapiv1alpha1.SubnetParam{{
+					Filter:   mapiv1alpha1.SubnetFilter{Name: "storage-subnet", IPVersion: 4},
+					PortTags: []string{"storage"},
+				}},
+				PortTags: []string{"secondary"},
+			})
+		}
+
+		// A machine with an SR-IOV port, which cannot be trunked nor have port security.
+		sriovProviderSpec = func(spec *mapiv1alpha1.OpenstackProviderSpec) {
+			spec.Ports = []mapiv1alpha1.PortOpts{{
+				NetworkID:    "2a5b8a6e-8f3b-4c6e-9e0a-2c1f8c3f5d11",
+				NameSuffix:   "sriov",
+				FixedIPs:     []mapiv1alpha1.FixedIPs{{SubnetID: "5f1c0a9e-5d5b-4d3e-8f2a-6b9c7d0e1f23"}},
+				Tags:         []string{"sriov"},
+				VNICType:     "direct",
+				Profile:      map[string]string{"trusted": "true", "capabilities": `["switchdev"]`},
+				PortSecurity: ptr.To(false),
+				Trunk:        ptr.To(false),
+			}}
+		}
+	)
+
+	type openStackMAPI2CAPIConversionInput struct {
+		providerSpec   machinev1beta1.ProviderSpec
+		infra          *configv1.Infrastructure
+		expectedPorts  []capov1.PortOpts
+		expectedErrors []string
+	}
+
+	var _ = DescribeTable("mapi2capi OpenStack convert MAPI Machine",
+		func(in openStackMAPI2CAPIConversionInput) {
+			_, capoMachine, _, err := FromOpenStackMachineAndInfra(machinebuilder.Machine().WithProviderSpec(in.providerSpec).Build(), in.infra).ToMachineAndInfrastructureMachine()
+			Expect(err).To(matchers.ConsistOfMatchErrorSubstrings(in.expectedErrors), "should match expected errors while converting an OpenStack MAPI Machine to CAPI")
+
+			if in.expectedPorts != nil {
+				Expect(capoMachine).To(BeAssignableToTypeOf(&capov1.OpenStackMachine{}))
+				Expect(capoMachine.(*capov1.OpenStackMachine).Spec.Ports).To(Equal(in.expectedPorts))
+			}
+		},
+
+		Entry("With a Base configuration", openStackMAPI2CAPIConversionInput{
+			providerSpec: openStackProviderSpec(func(*mapiv1alpha1.OpenstackProviderSpec) {}),
+			infra:        infra,
+			expectedPorts: []capov1.PortOpts{{
+				Network:  &capov1.NetworkParam{ID: ptr.To("d06af90b-1677-4b35-a7fb-3ae023dc8f62")},
+				FixedIPs: []capov1.FixedIP{{Subnet: &capov1.SubnetParam{ID: ptr.To("810c3d97-98c2-4cf3-b0f6-8977b6e0b4b2")}}},
+			}},
+			expectedErrors: []string{},
+		}),
+
+		Entry("With a dual-NIC configuration", openStackMAPI2CAPIConversionInput{
+			providerSpec: openStackProviderSpec(dualNICProviderSpec),
+			infra:        infra,
+			expectedPorts: []capov1.PortOpts{
+				{
+					Network:  &capov1.NetworkParam{ID: ptr.To("d06af90b-1677-4b35-a7fb-3ae023dc8f62")},
+					FixedIPs: []capov1.FixedIP{{Subnet: &capov1.SubnetParam{ID: ptr.To("810c3d97-98c2-4cf3-b0f6-8977b6e0b4b2")}}},
+				},
+				{
+					Network: &capov1.NetworkParam{Filter: &capov1.NetworkFilter{
+						Name:                "storage",
+						FilterByNeutronTags: capov1.FilterByNeutronTags{Tags: []capov1.NeutronTag{"openshiftClusterID=test-cluster", "storage"}},
+					}},
+					FixedIPs: []capov1.FixedIP{{Subnet: &capov1.SubnetParam{Filter: &capov1.SubnetFilter{Name: "storage-subnet", IPVersion: 4}}}},
+					Tags:     []string{"secondary", "storage"},
+				},
+			},
+			expectedErrors: []string{},
+		}),
+
+		Entry("With an SR-IOV port", openStackMAPI2CAPIConversionInput{
+			providerSpec: openStackProviderSpec(sriovProviderSpec),
+			infra:        infra,
+			expectedPorts: []capov1.PortOpts{
+				{
+					Network:  &capov1.NetworkParam{ID: ptr.To("d06af90b-1677-4b35-a7fb-3ae023dc8f62")},
+					FixedIPs: []capov1.FixedIP{{Subnet: &capov1.SubnetParam{ID: ptr.To("810c3d97-98c2-4cf3-b0f6-8977b6e0b4b2")}}},
+				},
+				{
+					Network:    &capov1.NetworkParam{ID: ptr.To("2a5b8a6e-8f3b-4c6e-9e0a-2c1f8c3f5d11")},
+					NameSuffix: ptr.To("sriov"),
+					FixedIPs:   []capov1.FixedIP{{Subnet: &capov1.SubnetParam{ID: ptr.To("5f1c0a9e-5d5b-4d3e-8f2a-6b9c7d0e1f23")}}},
+					Tags:       []string{"sriov"},
+					Trunk:      ptr.To(false),
+					ResolvedPortSpecFields: capov1.ResolvedPortSpecFields{
+						VNICType:            ptr.To("direct"),
+						Profile:             &capov1.BindingProfile{OVSHWOffload: ptr.To(true), TrustedVF: ptr.To(true)},
+						DisablePortSecurity: ptr.To(true),
+					},
+				},
+			},
+			expectedErrors: []string{},
+		}),
+
+		Entry("With an unsupported binding profile", openStackMAPI2CAPIConversionInput{
+			providerSpec: openStackProviderSpec(func(spec *mapiv1alpha1.OpenstackProviderSpec) {
+				spec.Networks[0].Profile = map[string]string{"foo": "bar"}
+			}),
+			infra: infra,
+			expectedErrors: []string{
+				"spec.providerSpec.value.networks[0].profile[foo]: Invalid value: \"bar\": only capabilities: [\"switchdev\"] and trusted: true|false are supported",
+			},
+		}),
+
+		Entry("With a port without security groups", openStackMAPI2CAPIConversionInput{
+			providerSpec: openStackProviderSpec(func(spec *mapiv1alpha1.OpenstackProviderSpec) {
+				sriovProviderSpec(spec)
+				spec.Ports[0].SecurityGroups = &[]string{}
+			}),
+			infra: infra,
+			expectedErrors: []string{
+				"spec.providerSpec.value.ports[0].securityGroups: Invalid value: []string{}: a port without security groups is not supported, disable portSecurity instead",
+			},
+		}),
+
+		Entry("With a primary subnet which is not the first subnet", openStackMAPI2CAPIConversionInput{
+			providerSpec: openStackProviderSpec(func(spec *mapiv1alpha1.OpenstackProviderSpec) {
+				spec.PrimarySubnet = "5f1c0a9e-5d5b-4d3e-8f2a-6b9c7d0e1f23"
+			}),
+			infra: infra,
+			expectedErrors: []string{
+				"spec.providerSpec.value.primarySubnet: Invalid value: \"5f1c0a9e-5d5b-4d3e-8f2a-6b9c7d0e1f23\": primarySubnet must be the ID of the first subnet of the first network or port",
+			},
+		}),
+
+		Entry("With a floating IP", openStackMAPI2CAPIConversionInput{
+			providerSpec: openStackProviderSpec(func(spec *mapiv1alpha1.OpenstackProviderSpec) {
+				spec.FloatingIP = "10.0.0.1"
+			}),
+			infra: infra,
+			expectedErrors: []string{
+				"spec.providerSpec.value.floatingIP: Invalid value: \"10.0.0.1\": floatingIP is not supported",
+			},
+		}),
+	)
+
+	var _ = DescribeTable("mapi2capi OpenStack convert MAPI MachineSet",
+		func(in openStackMAPI2CAPIConversionInput) {
+			_, _, _, err := FromOpenStackMachineSetAndInfra(machinebuilder.MachineSet().WithProviderSpec(in.providerSpec).Build(), in.infra).ToMachineSetAndMachineTemplate()
+			Expect(err).To(matchers.ConsistOfMatchErrorSubstrings(in.expectedErrors), "should match expected errors while converting an OpenStack MAPI MachineSet to CAPI")
+		},
+
+		Entry("With a Base configuration", openStackMAPI2CAPIConversionInput{
+			providerSpec:   openStackProviderSpec(func(*mapiv1alpha1.OpenstackProviderSpec) {}),
+			infra:          infra,
+			expectedErrors: []string{},
+		}),
+	)
+
+	DescribeTable("should convert the clouds secret to the identityRef",
+		func(modify func(*mapiv1alpha1.OpenstackProviderSpec), expectedIdentityRef *capov1.OpenStackIdentityReference) {
+			_, capoMachine, _, err := FromOpenStackMachineAndInfra(machinebuilder.Machine().WithProviderSpec(openStackProviderSpec(modify)).Build(), infra).ToMachineAndInfrastructureMachine()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(capoMachine).To(BeAssignableToTypeOf(&capov1.OpenStackMachine{}))
+			Expect(capoMachine.(*capov1.OpenStackMachine).Spec.IdentityRef).To(Equal(expectedIdentityRef))
+		},
+		Entry("With the credentials of the cluster", func(*mapiv1alpha1.OpenstackProviderSpec) {}, nil),
+		Entry("With another clouds secret",
+			func(spec *mapiv1alpha1.OpenstackProviderSpec) {
+				spec.CloudsSecret.Name = "tenant-credentials"
+			},
+			&capov1.OpenStackIdentityReference{Name: "tenant-credentials", CloudName: "openstack"},
+		),
+		Entry("With another cloud of the clouds secret of the cluster",
+			func(spec *mapiv1alpha1.OpenstackProviderSpec) {
+				spec.CloudName = "tenant"
+			},
+			&capov1.OpenStackIdentityReference{Name: "openstack-cloud-credentials", CloudName: "tenant"},
+		),
+	)
+})
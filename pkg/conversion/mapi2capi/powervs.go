@@ -23,6 +23,8 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	mapiv1 "github.com/openshift/api/machine/v1"
 	mapiv1beta1 "github.com/openshift/api/machine/v1beta1"
+	conversionutil "github.com/openshift/cluster-capi-operator/pkg/conversion/util"
+	"github.com/openshift/cluster-capi-operator/pkg/errorsx"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -59,7 +61,9 @@ func FromPowerVSMachineSetAndInfra(m *mapiv1beta1.MachineSet, i *configv1.Infras
 		infrastructure: i,
 		powerVSMachineAndInfra: &powerVSMachineAndInfra{
 			machine: &mapiv1beta1.Machine{
-				Spec: m.Spec.Template.Spec,
+				// The Machines of the MachineSet have the template labels, which the labels back-filled on the Machine must not override.
+				ObjectMeta: metav1.ObjectMeta{Labels: m.Spec.Template.Labels},
+				Spec:       m.Spec.Template.Spec,
 			},
 			infrastructure: i,
 		},
@@ -72,7 +76,7 @@ func (m *powerVSMachineAndInfra) ToMachineAndInfrastructureMachine() (*capiv1.Ma
 	capiMachine, powerVSMachine, warnings, errs := m.toMachineAndInfrastructureMachine()
 
 	if len(errs) > 0 {
-		return nil, nil, warnings, errs.ToAggregate()
+		return nil, nil, warnings, errorsx.UnsupportedFields(errs.ToAggregate())
 	}
 
 	return capiMachine, powerVSMachine, warnings, nil
@@ -113,6 +117,8 @@ func (m *powerVSMachineAndInfra) toMachineAndInfrastructureMachine() (*capiv1.Ma
 		errs = append(errs, field.Invalid(field.NewPath("infrastructure", "status", "infrastructureName"), m.infrastructure.Status.InfrastructureName, "infrastructure cannot be nil and infrastructure.Status.InfrastructureName cannot be empty"))
 	} else {
 		capiMachine.Spec.ClusterName = m.infrastructure.Status.InfrastructureName
+		// The machine.openshift.io labels the MAPI Machine lacks are back-filled, so that MAPI tooling keeps matching it.
+		capiMachine.Labels = conversionutil.BackfillMAPIMachineLabels(capiMachine.Labels, capiMachine.Spec.ClusterName, "")
 	}
 
 	// The InfraMachine should always have the same labels and annotations as the Machine.
@@ -158,7 +164,7 @@ func (m *powerVSMachineSetAndInfra) ToMachineSetAndMachineTemplate() (*capiv1.Ma
 	powerVSMachineSet.Spec.Template.ObjectMeta.Labels = mergeMaps(powerVSMachineSet.Spec.Template.ObjectMeta.Labels, capiMachine.Labels)
 	powerVSMachineSet.Spec.Template.ObjectMeta.Annotations = mergeMaps(powerVSMachineSet.Spec.Template.ObjectMeta.Annotations, capiMachine.Annotations)
 
-	// Override the reference so that it matches the AWSMachineTemplate.
+	// Override the reference so that it matches the IBMPowerVSMachineTemplate.
 	powerVSMachineSet.Spec.Template.Spec.InfrastructureRef.Kind = ibmPowerVSTemplateKind
 	powerVSMachineSet.Spec.Template.Spec.InfrastructureRef.Name = powerVSMachineTemplate.Name
 
@@ -167,10 +173,12 @@ func (m *powerVSMachineSetAndInfra) ToMachineSetAndMachineTemplate() (*capiv1.Ma
 	} else {
 		powerVSMachineSet.Spec.Template.Spec.ClusterName = m.infrastructure.Status.InfrastructureName
 		powerVSMachineSet.Spec.ClusterName = m.infrastructure.Status.InfrastructureName
+
+		backfillCAPIMachineSetLabels(powerVSMachineSet)
 	}
 
 	if len(errs) > 0 {
-		return nil, nil, warnings, utilerrors.NewAggregate(errs)
+		return nil, nil, warnings, errorsx.UnsupportedFields(utilerrors.NewAggregate(errs))
 	}
 
 	return powerVSMachineSet, powerVSMachineTemplate, warnings, nil
@@ -18,6 +18,7 @@ package mapi2capi_test
 import (
 	"fmt"
 	"strings"
+	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -30,6 +31,7 @@ import (
 	"github.com/openshift/cluster-capi-operator/pkg/conversion/mapi2capi"
 	conversiontest "github.com/openshift/cluster-capi-operator/pkg/conversion/test/fuzz"
 
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtimeserializer "k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/utils/ptr"
@@ -71,7 +73,7 @@ var _ = Describe("PowerVS Fuzz (mapi2capi)", func() {
 			mapi2capi.FromPowerVSMachineAndInfra,
 			fromMachineAndPowerVSMachineAndPowerVSCluster,
 			conversiontest.ObjectMetaFuzzerFuncs(mapiNamespace),
-			conversiontest.MAPIMachineFuzzerFuncs(&mapiv1.PowerVSMachineProviderConfig{}, powerVSProviderIDFuzzer),
+			conversiontest.MAPIMachineFuzzerFuncs(&mapiv1.PowerVSMachineProviderConfig{}, powerVSProviderIDFuzzer, infra.Status.InfrastructureName),
 			powerVSProviderSpecFuzzerFuncs,
 		)
 	})
@@ -94,13 +96,48 @@ var _ = Describe("PowerVS Fuzz (mapi2capi)", func() {
 			mapi2capi.FromPowerVSMachineSetAndInfra,
 			fromMachineSetAndPowerVSMachineTemplateAndPowerVSCluster,
 			conversiontest.ObjectMetaFuzzerFuncs(mapiNamespace),
-			conversiontest.MAPIMachineFuzzerFuncs(&mapiv1.PowerVSMachineProviderConfig{}, powerVSProviderIDFuzzer),
-			conversiontest.MAPIMachineSetFuzzerFuncs(),
+			conversiontest.MAPIMachineFuzzerFuncs(&mapiv1.PowerVSMachineProviderConfig{}, powerVSProviderIDFuzzer, infra.Status.InfrastructureName),
+			conversiontest.MAPIMachineSetFuzzerFuncs(infra.Status.InfrastructureName),
 			powerVSProviderSpecFuzzerFuncs,
 		)
 	})
 })
 
+// FuzzPowerVSMachineRoundTrip round trips fuzzed PowerVS Machines from MAPI to CAPI, replaying the seed corpus in
+// testdata/fuzz/FuzzPowerVSMachineRoundTrip on every run. Explore new inputs with:
+//
+//	go test ./pkg/conversion/mapi2capi -run '^$' -fuzz FuzzPowerVSMachineRoundTrip
+func FuzzPowerVSMachineRoundTrip(f *testing.F) {
+	infra := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			InfrastructureName: "sample-cluster-name",
+		},
+	}
+
+	conversiontest.MAPI2CAPIMachineRoundTripFuzzTarget(f, conversiontest.MAPI2CAPIMachineRoundTripTarget{
+		Scheme: scheme,
+		Infra:  infra,
+		InfraCluster: &capibmv1.IBMPowerVSCluster{
+			Spec: capibmv1.IBMPowerVSClusterSpec{
+				ServiceInstance: &capibmv1.IBMPowerVSResourceReference{Name: ptr.To("serviceInstance")},
+				Zone:            ptr.To("test-zone"),
+			},
+		},
+		MAPIConverter: mapi2capi.FromPowerVSMachineAndInfra,
+		CAPIConverter: func(machine *capiv1.Machine, infraMachine client.Object, infraCluster client.Object) capi2mapi.MachineAndInfrastructureMachine {
+			return capi2mapi.FromMachineAndPowerVSMachineAndPowerVSCluster(machine, infraMachine.(*capibmv1.IBMPowerVSMachine), infraCluster.(*capibmv1.IBMPowerVSCluster)) //nolint:forcetypeassert
+		},
+		FuzzerFuncs: []fuzzer.FuzzerFuncs{
+			conversiontest.ObjectMetaFuzzerFuncs(mapiNamespace),
+			conversiontest.MAPIMachineFuzzerFuncs(&mapiv1.PowerVSMachineProviderConfig{}, powerVSProviderIDFuzzer, infra.Status.InfrastructureName),
+			powerVSProviderSpecFuzzerFuncs,
+		},
+		Losses: []conversiontest.Loss{
+			{Field: "credentialsSecret", Reason: "CAPIBM reads the credentials of the IBMPowerVSCluster, TODO(MULTIARCH-5195)"},
+		},
+	})
+}
+
 func powerVSProviderIDFuzzer(c fuzz.Continue) string {
 	// Power VS provider id format: ibmpowervs://<region>/<zone>/<service_instance_id>/<instance_id>
 	return fmt.Sprintf("ibmpowervs://tok/tok04/%s/%s", strings.ReplaceAll(c.RandString(), "/", ""), strings.ReplaceAll(c.RandString(), "/", ""))
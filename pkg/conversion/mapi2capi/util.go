@@ -17,7 +17,10 @@ limitations under the License.
 package mapi2capi
 
 import (
+	"maps"
+
 	mapiv1beta1 "github.com/openshift/api/machine/v1beta1"
+	conversionutil "github.com/openshift/cluster-capi-operator/pkg/conversion/util"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -31,10 +34,11 @@ func fromMAPIMachineToCAPIMachine(mapiMachine *mapiv1beta1.Machine, apiVersion,
 
 	capiMachine := &capiv1.Machine{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        mapiMachine.Name,
-			Namespace:   capiNamespace,
-			Labels:      mapiMachine.Labels,
-			Annotations: mapiMachine.Annotations,
+			Name:      mapiMachine.Name,
+			Namespace: capiNamespace,
+			// The CAPI managed node labels are added to the labels, so copy them to leave the MAPI Machine untouched.
+			Labels:      maps.Clone(mapiMachine.Labels),
+			Annotations: convertMAPIDeleteMachineAnnotationsToCAPI(mapiMachine.Annotations),
 			// OwnerReferences: TODO(OCPCLOUD-2716): These need to be converted so that any MachineSet owning a Machine is represented with the correct owner reference between the two APIs.
 		},
 		Spec: capiv1.MachineSpec{
@@ -54,8 +58,17 @@ func fromMAPIMachineToCAPIMachine(mapiMachine *mapiv1beta1.Machine, apiVersion,
 			// NodeVolumeDetachTimeout: ,
 			// NodeDeletionTimeout: ,
 		},
+		Status: capiv1.MachineStatus{
+			// The addresses and the phase are carried over so that the CAPI Machine reflects the machine when MAPI is authoritative.
+			Addresses: convertMAPIMachineAddressesToCAPI(mapiMachine.Status.Addresses),
+			Phase:     conversionutil.ConvertMAPIMachinePhaseToCAPI(mapiMachine.Status.Phase),
+		},
 	}
 
+	// The terminal error of a Failed machine explains why it failed.
+	capiMachine.Status.FailureReason, capiMachine.Status.FailureMessage = conversionutil.ConvertMAPIMachineErrorToCAPI(
+		mapiMachine.Status.ErrorReason, mapiMachine.Status.ErrorMessage)
+
 	// lifecycleHooks are handled via an annotation in Cluster API.
 	lifecycleAnnotations := getCAPILifecycleHookAnnotations(mapiMachine.Spec.LifecycleHooks)
 	if capiMachine.Annotations == nil {
@@ -70,7 +83,15 @@ func fromMAPIMachineToCAPIMachine(mapiMachine *mapiv1beta1.Machine, apiVersion,
 		capiMachine.Labels = map[string]string{}
 	}
 
-	errs = append(errs, setMAPINodeLabelsToCAPIManagedNodeLabels(field.NewPath("spec", "metadata", "labels"), mapiMachine.Spec.ObjectMeta.Labels, capiMachine.Labels)...)
+	unmanagedNodeLabels := setMAPINodeLabelsToCAPIManagedNodeLabels(mapiMachine.Spec.ObjectMeta.Labels, capiMachine.Labels)
+
+	// Node metadata that CAPI Machines cannot represent is carried by annotations.
+	nodeMetadataAnnotations, nodeMetadataErrs := getCAPINodeMetadataAnnotations(field.NewPath("spec"), unmanagedNodeLabels, mapiMachine.Spec)
+	errs = append(errs, nodeMetadataErrs...)
+
+	for key, value := range nodeMetadataAnnotations {
+		capiMachine.Annotations[key] = value
+	}
 
 	// Unused fields - Below this line are fields not used from the MAPI Machine.
 
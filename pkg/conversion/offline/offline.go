@@ -0,0 +1,340 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package offline converts exported Machine API Machines and MachineSets to the Cluster API objects a migration would
+// create, without cluster access, so that administrators can review them before migrating.
+package offline
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	configv1 "github.com/openshift/api/config/v1"
+	mapiv1beta1 "github.com/openshift/api/machine/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	capav1beta2 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
+	capibmv1 "sigs.k8s.io/cluster-api-provider-ibmcloud/api/v1beta2"
+	capov1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1beta1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/cluster-capi-operator/pkg/conversion/mapi2capi"
+)
+
+const (
+	machineKind        = "Machine"
+	machineSetKind     = "MachineSet"
+	infrastructureKind = "Infrastructure"
+
+	// decoderBufferSize is the number of bytes the decoder reads ahead to detect whether a document is YAML or JSON.
+	decoderBufferSize = 4096
+)
+
+var (
+	errMissingInfrastructure = errors.New("no Infrastructure given, export it with `oc get infrastructure cluster -o yaml`")
+	errMissingPlatform       = errors.New("the Infrastructure has no platform")
+	errPlatformNotSupported  = errors.New("platform not supported")
+	errUnsupportedKind       = errors.New("unsupported kind, expected a Machine API Machine or MachineSet")
+)
+
+// Options configure the conversion.
+type Options struct {
+	// Infra is the Infrastructure of the cluster the resources were exported from. When nil, the Infrastructure
+	// found in the inputs is used.
+	Infra *configv1.Infrastructure
+
+	// Platform overrides the platform of the Infrastructure.
+	Platform configv1.PlatformType
+
+	// CAPINamespace is the namespace the Cluster API objects are created in.
+	CAPINamespace string
+}
+
+// conversion is the outcome of the conversion of a Machine API resource.
+type conversion struct {
+	source   string
+	objects  []client.Object
+	warnings []string
+}
+
+// Run converts the Machine API Machines and MachineSets read from the inputs, as exported by `oc get -o yaml`, and
+// writes the Cluster API objects they convert to as a YAML stream. The warnings of each conversion are written as
+// comments before its objects. The resources which fail to convert are reported by the returned error, the others
+// are written nonetheless.
+func Run(inputs []io.Reader, opts Options, out io.Writer) error {
+	var objs []unstructured.Unstructured
+
+	for _, in := range inputs {
+		decoded, err := decode(in)
+		if err != nil {
+			return err
+		}
+
+		objs = append(objs, decoded...)
+	}
+
+	infra, platform, err := resolveInfrastructure(objs, opts)
+	if err != nil {
+		return err
+	}
+
+	scheme, err := newScheme()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for _, obj := range objs {
+		if isInfrastructure(obj) {
+			continue
+		}
+
+		result, err := convert(obj, infra, platform, opts.CAPINamespace)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if err := write(out, scheme, result); err != nil {
+			return err
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// decode reads the objects of a YAML, or JSON, stream. The items of the lists are returned as separate objects.
+func decode(in io.Reader) ([]unstructured.Unstructured, error) {
+	var objs []unstructured.Unstructured
+
+	decoder := utilyaml.NewYAMLOrJSONDecoder(in, decoderBufferSize)
+
+	for {
+		obj := unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); errors.Is(err, io.EOF) {
+			return objs, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to decode input: %w", err)
+		}
+
+		// Empty documents, such as a leading document separator, decode to nothing.
+		if len(obj.Object) == 0 {
+			continue
+		}
+
+		if !obj.IsList() {
+			objs = append(objs, obj)
+			continue
+		}
+
+		if err := obj.EachListItem(func(item runtime.Object) error {
+			objs = append(objs, *item.(*unstructured.Unstructured)) //nolint:forcetypeassert
+
+			return nil
+		}); err != nil {
+			return nil, fmt.Errorf("failed to decode the items of the input list: %w", err)
+		}
+	}
+}
+
+// resolveInfrastructure returns the Infrastructure of the options, or the first one of the inputs, and its platform.
+func resolveInfrastructure(objs []unstructured.Unstructured, opts Options) (*configv1.Infrastructure, configv1.PlatformType, error) {
+	infra := opts.Infra
+
+	for i := 0; infra == nil && i < len(objs); i++ {
+		if !isInfrastructure(objs[i]) {
+			continue
+		}
+
+		infra = &configv1.Infrastructure{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(objs[i].Object, infra); err != nil {
+			return nil, "", fmt.Errorf("failed to decode Infrastructure %s: %w", objs[i].GetName(), err)
+		}
+	}
+
+	if infra == nil {
+		return nil, "", errMissingInfrastructure
+	}
+
+	platform := opts.Platform
+	if platform == "" && infra.Status.PlatformStatus != nil {
+		platform = infra.Status.PlatformStatus.Type
+	}
+
+	if platform == "" {
+		return nil, "", errMissingPlatform
+	}
+
+	return infra, platform, nil
+}
+
+func isInfrastructure(obj unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+
+	return gvk.Group == configv1.GroupName && gvk.Kind == infrastructureKind
+}
+
+// convert converts a Machine API Machine or MachineSet, and moves the Cluster API objects to the CAPI namespace as
+// the sync controllers do.
+func convert(obj unstructured.Unstructured, infra *configv1.Infrastructure, platform configv1.PlatformType, capiNamespace string) (conversion, error) {
+	gvk := obj.GroupVersionKind()
+	source := fmt.Sprintf("%s %s/%s", gvk.Kind, obj.GetNamespace(), obj.GetName())
+
+	if gvk.GroupVersion() != mapiv1beta1.GroupVersion || (gvk.Kind != machineKind && gvk.Kind != machineSetKind) {
+		return conversion{}, fmt.Errorf("%s: %w, got %s", source, errUnsupportedKind, gvk)
+	}
+
+	var (
+		capiObj, infraObj client.Object
+		warnings          []string
+		err               error
+	)
+
+	if gvk.Kind == machineSetKind {
+		capiObj, infraObj, warnings, err = convertMachineSet(obj, infra, platform, capiNamespace)
+	} else {
+		capiObj, infraObj, warnings, err = convertMachine(obj, infra, platform, capiNamespace)
+	}
+
+	if err != nil {
+		return conversion{}, fmt.Errorf("%s: %w", source, err)
+	}
+
+	infraObj.SetNamespace(capiNamespace)
+
+	return conversion{source: source, objects: []client.Object{capiObj, infraObj}, warnings: warnings}, nil
+}
+
+func convertMachineSet(obj unstructured.Unstructured, infra *configv1.Infrastructure, platform configv1.PlatformType, capiNamespace string) (client.Object, client.Object, []string, error) {
+	mapiMachineSet := &mapiv1beta1.MachineSet{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, mapiMachineSet); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to decode MachineSet: %w", err)
+	}
+
+	var converter mapi2capi.MachineSet
+
+	switch platform {
+	case configv1.AWSPlatformType:
+		converter = mapi2capi.FromAWSMachineSetAndInfra(mapiMachineSet, infra)
+	case configv1.PowerVSPlatformType:
+		converter = mapi2capi.FromPowerVSMachineSetAndInfra(mapiMachineSet, infra)
+	case configv1.OpenStackPlatformType:
+		converter = mapi2capi.FromOpenStackMachineSetAndInfra(mapiMachineSet, infra)
+	default:
+		return nil, nil, nil, fmt.Errorf("%w: %s", errPlatformNotSupported, platform)
+	}
+
+	capiMachineSet, infraMachineTemplate, warnings, err := converter.ToMachineSetAndMachineTemplate()
+	if err != nil {
+		return nil, nil, warnings, fmt.Errorf("failed to convert MachineSet: %w", err)
+	}
+
+	capiMachineSet.SetNamespace(capiNamespace)
+	capiMachineSet.Spec.Template.Spec.InfrastructureRef.Namespace = capiNamespace
+
+	return capiMachineSet, infraMachineTemplate, warnings, nil
+}
+
+func convertMachine(obj unstructured.Unstructured, infra *configv1.Infrastructure, platform configv1.PlatformType, capiNamespace string) (client.Object, client.Object, []string, error) {
+	mapiMachine := &mapiv1beta1.Machine{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, mapiMachine); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to decode Machine: %w", err)
+	}
+
+	var converter mapi2capi.Machine
+
+	switch platform {
+	case configv1.AWSPlatformType:
+		converter = mapi2capi.FromAWSMachineAndInfra(mapiMachine, infra)
+	case configv1.PowerVSPlatformType:
+		converter = mapi2capi.FromPowerVSMachineAndInfra(mapiMachine, infra)
+	case configv1.OpenStackPlatformType:
+		converter = mapi2capi.FromOpenStackMachineAndInfra(mapiMachine, infra)
+	default:
+		return nil, nil, nil, fmt.Errorf("%w: %s", errPlatformNotSupported, platform)
+	}
+
+	capiMachine, infraMachine, warnings, err := converter.ToMachineAndInfrastructureMachine()
+	if err != nil {
+		return nil, nil, warnings, fmt.Errorf("failed to convert Machine: %w", err)
+	}
+
+	capiMachine.SetNamespace(capiNamespace)
+	capiMachine.Spec.InfrastructureRef.Namespace = capiNamespace
+
+	return capiMachine, infraMachine, warnings, nil
+}
+
+// write writes the objects of the conversion as YAML documents, preceded by the warnings of the conversion.
+func write(out io.Writer, scheme *runtime.Scheme, result conversion) error {
+	if _, err := fmt.Fprintf(out, "---\n# Converted from %s\n", result.source); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	for _, warning := range result.warnings {
+		if _, err := fmt.Fprintf(out, "# Warning: %s\n", warning); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+
+	for i, obj := range result.objects {
+		gvk, err := apiutil.GVKForObject(obj, scheme)
+		if err != nil {
+			return fmt.Errorf("failed to get the kind of %s: %w", obj.GetName(), err)
+		}
+
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s %s: %w", gvk.Kind, obj.GetName(), err)
+		}
+
+		if i > 0 {
+			data = append([]byte("---\n"), data...)
+		}
+
+		if _, err := out.Write(data); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// newScheme returns the scheme of the Cluster API objects the conversions return.
+func newScheme() (*runtime.Scheme, error) {
+	scheme := runtime.NewScheme()
+
+	for _, addToScheme := range []func(*runtime.Scheme) error{
+		capiv1beta1.AddToScheme,
+		capav1beta2.AddToScheme,
+		capibmv1.AddToScheme,
+		capov1.AddToScheme,
+	} {
+		if err := addToScheme(scheme); err != nil {
+			return nil, fmt.Errorf("failed to build scheme: %w", err)
+		}
+	}
+
+	return scheme, nil
+}
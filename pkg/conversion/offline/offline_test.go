@@ -0,0 +1,144 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package offline
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	configv1 "github.com/openshift/api/config/v1"
+	mapiv1beta1 "github.com/openshift/api/machine/v1beta1"
+	configbuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/config/v1"
+	machinebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	testMAPINamespace = "openshift-machine-api"
+	testCAPINamespace = "openshift-cluster-api"
+)
+
+var _ = Describe("Offline conversion", func() {
+	var (
+		infra      *configv1.Infrastructure
+		machineSet *mapiv1beta1.MachineSet
+		machine    *mapiv1beta1.Machine
+	)
+
+	toYAML := func(obj client.Object, gvk schema.GroupVersionKind) string {
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+		data, err := yaml.Marshal(obj)
+		Expect(err).NotTo(HaveOccurred())
+
+		return string(data)
+	}
+
+	run := func(opts Options, inputs ...string) ([]string, error) {
+		readers := []io.Reader{}
+		for _, input := range inputs {
+			readers = append(readers, strings.NewReader(input))
+		}
+
+		out := &bytes.Buffer{}
+		err := Run(readers, opts, out)
+
+		converted, decodeErr := decode(out)
+		Expect(decodeErr).NotTo(HaveOccurred())
+
+		objects := []string{}
+		for _, obj := range converted {
+			Expect(obj.GetNamespace()).To(Equal(testCAPINamespace))
+			objects = append(objects, obj.GetKind()+"/"+obj.GetName())
+		}
+
+		return objects, err
+	}
+
+	BeforeEach(func() {
+		infra = configbuilder.Infrastructure().WithName("cluster").AsAWS("test-cluster", "us-east-1").Build()
+
+		providerSpec := machinebuilder.AWSProviderSpec().WithLoadBalancers(nil)
+		machineSet = machinebuilder.MachineSet().WithName("worker-a").WithNamespace(testMAPINamespace).WithProviderSpecBuilder(providerSpec).Build()
+		machine = machinebuilder.Machine().WithName("worker-a-1").WithNamespace(testMAPINamespace).WithProviderSpecBuilder(providerSpec).Build()
+	})
+
+	It("should convert the MachineSets and Machines with the Infrastructure of the inputs", func() {
+		objects, err := run(Options{CAPINamespace: testCAPINamespace},
+			toYAML(infra, configv1.GroupVersion.WithKind(infrastructureKind)),
+			toYAML(machineSet, mapiv1beta1.GroupVersion.WithKind(machineSetKind))+"---\n"+toYAML(machine, mapiv1beta1.GroupVersion.WithKind(machineKind)),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objects).To(Equal([]string{
+			"MachineSet/worker-a", "AWSMachineTemplate/worker-a",
+			"Machine/worker-a-1", "AWSMachine/worker-a-1",
+		}))
+	})
+
+	It("should convert the items of the exported lists", func() {
+		list := "apiVersion: v1\nkind: List\nitems:\n- " + strings.ReplaceAll(
+			strings.TrimSpace(toYAML(machineSet, mapiv1beta1.GroupVersion.WithKind(machineSetKind))), "\n", "\n  ")
+
+		objects, err := run(Options{Infra: infra, CAPINamespace: testCAPINamespace}, list)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objects).To(Equal([]string{"MachineSet/worker-a", "AWSMachineTemplate/worker-a"}))
+	})
+
+	It("should write the source of each conversion as a comment", func() {
+		out := &bytes.Buffer{}
+		Expect(Run([]io.Reader{strings.NewReader(toYAML(machineSet, mapiv1beta1.GroupVersion.WithKind(machineSetKind)))},
+			Options{Infra: infra, CAPINamespace: testCAPINamespace}, out)).To(Succeed())
+
+		Expect(out.String()).To(HavePrefix("---\n# Converted from MachineSet openshift-machine-api/worker-a\n"))
+	})
+
+	It("should write the warnings of the conversion as comments before its objects", func() {
+		scheme, err := newScheme()
+		Expect(err).NotTo(HaveOccurred())
+
+		out := &bytes.Buffer{}
+		Expect(write(out, scheme, conversion{source: "MachineSet openshift-machine-api/worker-a", warnings: []string{"foo is ignored"}})).To(Succeed())
+
+		Expect(out.String()).To(Equal("---\n# Converted from MachineSet openshift-machine-api/worker-a\n# Warning: foo is ignored\n"))
+	})
+
+	It("should require the Infrastructure", func() {
+		_, err := run(Options{CAPINamespace: testCAPINamespace}, toYAML(machineSet, mapiv1beta1.GroupVersion.WithKind(machineSetKind)))
+		Expect(err).To(MatchError(errMissingInfrastructure))
+	})
+
+	It("should report the unsupported platforms", func() {
+		_, err := run(Options{Infra: infra, Platform: configv1.GCPPlatformType, CAPINamespace: testCAPINamespace},
+			toYAML(machineSet, mapiv1beta1.GroupVersion.WithKind(machineSetKind)))
+		Expect(err).To(MatchError(errPlatformNotSupported))
+	})
+
+	It("should report the unsupported kinds and convert the other resources", func() {
+		objects, err := run(Options{Infra: infra, CAPINamespace: testCAPINamespace},
+			"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: foo\n  namespace: openshift-machine-api\n",
+			toYAML(machineSet, mapiv1beta1.GroupVersion.WithKind(machineSetKind)),
+		)
+		Expect(err).To(MatchError(errUnsupportedKind))
+		Expect(err).To(MatchError(ContainSubstring("ConfigMap openshift-machine-api/foo")))
+		Expect(objects).To(Equal([]string{"MachineSet/worker-a", "AWSMachineTemplate/worker-a"}))
+	})
+})
@@ -31,6 +31,7 @@ import (
 	"github.com/openshift/cluster-api-actuator-pkg/testutils"
 	"github.com/openshift/cluster-capi-operator/pkg/conversion/capi2mapi"
 	"github.com/openshift/cluster-capi-operator/pkg/conversion/mapi2capi"
+	conversionutil "github.com/openshift/cluster-capi-operator/pkg/conversion/util"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	corev1 "k8s.io/api/core/v1"
@@ -329,13 +330,18 @@ func MAPI2CAPIMachineSetRoundTripFuzzTest(scheme *runtime.Scheme, infra *configv
 
 // getFuzzer returns a new fuzzer to be used for testing.
 func getFuzzer(scheme *runtime.Scheme, funcs ...fuzzer.FuzzerFuncs) *fuzz.Fuzzer {
+	return getFuzzerWithSource(rand.NewSource(rand.Int63()), scheme, funcs...) //nolint:gosec
+}
+
+// getFuzzerWithSource returns a new fuzzer to be used for testing, drawing its randomness from the given source.
+func getFuzzerWithSource(src rand.Source, scheme *runtime.Scheme, funcs ...fuzzer.FuzzerFuncs) *fuzz.Fuzzer {
 	funcs = append([]fuzzer.FuzzerFuncs{
 		metafuzzer.Funcs,
 	}, funcs...)
 
 	return fuzzer.FuzzerFor(
 		fuzzer.MergeFuzzerFuncs(funcs...),
-		rand.NewSource(rand.Int63()), //nolint:gosec
+		src,
 		runtimeserializer.NewCodecFactory(scheme),
 	)
 }
@@ -450,6 +456,9 @@ func CAPIMachineFuzzerFuncs(providerIDFuzz StringFuzzer, infraKind, infraAPIVers
 					Name:       m.Name,
 					Namespace:  m.Namespace,
 				}
+
+				// The conversion back-fills the MAPI cluster label, so the Machines converted from MAPI always have it.
+				m.Labels[conversionutil.MAPIClusterIDLabel] = clusterName
 			},
 		}
 	}
@@ -474,6 +483,9 @@ func CAPIMachineSetFuzzerFuncs(infraTemplateKind, infraAPIVersion, clusterName s
 				c.FuzzNoCustom(m)
 
 				m.ClusterName = clusterName
+
+				// Only the delete policies common to MAPI and CAPI can be converted.
+				m.DeletePolicy = fuzzMachineSetDeletePolicy(c)
 			},
 			func(m *capiv1.MachineSet, c fuzz.Continue) {
 				c.FuzzNoCustom(m)
@@ -489,6 +501,11 @@ func CAPIMachineSetFuzzerFuncs(infraTemplateKind, infraAPIVersion, clusterName s
 					Name:       m.Name,
 					Namespace:  m.Namespace,
 				}
+
+				// The conversion back-fills the MAPI cluster and machine set labels, so the MachineSets converted from MAPI always have them.
+				m.Labels[conversionutil.MAPIClusterIDLabel] = clusterName
+				m.Spec.Template.Labels[conversionutil.MAPIClusterIDLabel] = clusterName
+				m.Spec.Template.Labels[conversionutil.MAPIMachineSetLabel] = m.Name
 			},
 		}
 	}
@@ -498,9 +515,19 @@ func CAPIMachineSetFuzzerFuncs(infraTemplateKind, infraAPIVersion, clusterName s
 // The providerSpec should be a pointer to a providerSpec type for the platform being tested.
 // This will be fuzzed and then injected into the MachineSpec as a RawExtension.
 // The providerIDFuzz function should be a function that returns a valid providerID for the platform being tested.
-func MAPIMachineFuzzerFuncs(providerSpec runtime.Object, providerIDFuzz StringFuzzer) fuzzer.FuzzerFuncs {
+// The clusterName should be the infrastructure name the conversion sets as the CAPI cluster name.
+func MAPIMachineFuzzerFuncs(providerSpec runtime.Object, providerIDFuzz StringFuzzer, clusterName string) fuzzer.FuzzerFuncs {
 	return func(codecs runtimeserializer.CodecFactory) []interface{} {
 		return []interface{}{
+			func(m *mapiv1.Machine, c fuzz.Continue) {
+				c.FuzzNoCustom(m)
+
+				// The MAPI labels derived from the CAPI Machine are back-filled by the conversion, so set them as MAPI would.
+				// The role and type are those of the node role label set below.
+				m.Labels[conversionutil.MAPIClusterIDLabel] = clusterName
+				m.Labels[conversionutil.MAPIMachineRoleLabel] = "worker"
+				m.Labels[conversionutil.MAPIMachineTypeLabel] = "worker"
+			},
 			// MAPI to CAPI conversion functions.
 			func(m *mapiv1.MachineSpec, c fuzz.Continue) {
 				c.FuzzNoCustom(m)
@@ -523,10 +550,8 @@ func MAPIMachineFuzzerFuncs(providerSpec runtime.Object, providerIDFuzz StringFu
 				m.ObjectMeta.OwnerReferences = nil
 				m.AuthoritativeAPI = ""
 
-				// Clear fields that are not yet supported in the conversion.
-				// TODO(OCPCLOUD-2680): For taints and annotations.
-				m.ObjectMeta.Annotations = nil
-				m.Taints = nil
+				// Taints have to be valid to be applied to a Node.
+				m.Taints = fuzzTaints(c)
 
 				// Set the providerID to a valid providerID that will at least pass through the conversion.
 				m.ProviderID = ptr.To(providerIDFuzz(c))
@@ -538,6 +563,8 @@ func MAPIMachineFuzzerFuncs(providerSpec runtime.Object, providerIDFuzz StringFu
 					"node.cluster.x-k8s.io/" + strings.ReplaceAll(c.RandString(), "/", ""):          c.RandString(),
 					strings.ReplaceAll(c.RandString(), "/", "") + ".node-restriction.kubernetes.io": c.RandString(),
 					strings.ReplaceAll(c.RandString(), "/", "") + ".node.cluster.x-k8s.io":          c.RandString(),
+					// Labels that are not propagated to the node by CAPI are carried by an annotation.
+					"custom.domain/" + strings.ReplaceAll(c.RandString(), "/", ""): c.RandString(),
 				}
 			},
 			func(hooks *mapiv1.LifecycleHooks, c fuzz.Continue) {
@@ -559,9 +586,25 @@ func MAPIMachineFuzzerFuncs(providerSpec runtime.Object, providerIDFuzz StringFu
 
 // MAPIMachineSetFuzzerFuncs returns a set of fuzzer functions that can be used to fuzz MachineSetSpec objects.
 // This function relies on the MachineSpec fuzzer functions to fuzz the MachineTemplateSpec.
-func MAPIMachineSetFuzzerFuncs() fuzzer.FuzzerFuncs {
+// The clusterName should be the infrastructure name the conversion sets as the CAPI cluster name.
+func MAPIMachineSetFuzzerFuncs(clusterName string) fuzzer.FuzzerFuncs {
 	return func(codecs runtimeserializer.CodecFactory) []interface{} {
 		return []interface{}{
+			func(m *mapiv1.MachineSet, c fuzz.Continue) {
+				c.FuzzNoCustom(m)
+
+				// The MAPI labels derived from the CAPI MachineSet are back-filled by the conversion, so set them as MAPI would.
+				// The role and type are those of the node role label set by the MachineSpec fuzzer functions.
+				if m.Spec.Template.Labels == nil {
+					m.Spec.Template.Labels = map[string]string{}
+				}
+
+				m.Labels[conversionutil.MAPIClusterIDLabel] = clusterName
+				m.Spec.Template.Labels[conversionutil.MAPIClusterIDLabel] = clusterName
+				m.Spec.Template.Labels[conversionutil.MAPIMachineSetLabel] = m.Name
+				m.Spec.Template.Labels[conversionutil.MAPIMachineRoleLabel] = "worker"
+				m.Spec.Template.Labels[conversionutil.MAPIMachineTypeLabel] = "worker"
+			},
 			// MAPI to CAPI conversion functions.
 			func(m *mapiv1.MachineSetSpec, c fuzz.Continue) {
 				c.FuzzNoCustom(m)
@@ -574,7 +617,45 @@ func MAPIMachineSetFuzzerFuncs() fuzzer.FuzzerFuncs {
 
 				// Clear the authoritative API since that's not relevant for conversion.
 				m.AuthoritativeAPI = ""
+
+				// Only the delete policies common to MAPI and CAPI can be converted.
+				// The Random policy is converted back to an empty policy, which is equivalent, so it is not expected to round trip.
+				m.DeletePolicy = fuzzMAPIMachineSetDeletePolicy(c)
 			},
 		}
 	}
 }
+
+// fuzzMachineSetDeletePolicy returns one of the MachineSet delete policies supported by both MAPI and CAPI.
+func fuzzMachineSetDeletePolicy(c fuzz.Continue) string {
+	policies := conversionutil.SupportedMachineSetDeletePolicies()
+
+	return policies[c.Intn(len(policies))]
+}
+
+// fuzzMAPIMachineSetDeletePolicy returns one of the MAPI MachineSet delete policies which round trip, the empty
+// policy in place of the Random one.
+func fuzzMAPIMachineSetDeletePolicy(c fuzz.Continue) string {
+	if policy := fuzzMachineSetDeletePolicy(c); policy != string(capiv1.RandomMachineSetDeletePolicy) {
+		return policy
+	}
+
+	return ""
+}
+
+// fuzzTaints returns a list of node taints that could be applied to a Node, with unique keys and a supported effect.
+func fuzzTaints(c fuzz.Continue) []corev1.Taint {
+	effects := []corev1.TaintEffect{corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute}
+
+	var taints []corev1.Taint
+
+	for i := 0; i < c.Intn(3); i++ {
+		taints = append(taints, corev1.Taint{
+			Key:    fmt.Sprintf("example.com/taint-%d", i),
+			Value:  fmt.Sprintf("value-%d", c.Intn(100)),
+			Effect: effects[c.Intn(len(effects))],
+		})
+	}
+
+	return taints
+}
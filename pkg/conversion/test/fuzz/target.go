@@ -0,0 +1,102 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package fuzz
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/google/gofuzz/bytesource"
+
+	configv1 "github.com/openshift/api/config/v1"
+	mapiv1 "github.com/openshift/api/machine/v1beta1"
+	"github.com/openshift/cluster-api-actuator-pkg/testutils"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Loss is a field of the MAPI providerSpec that the conversion knowingly does not round trip.
+type Loss struct {
+	// Field is the dot separated path of the field in the providerSpec, e.g. credentialsSecret.
+	Field string
+	// Reason documents why the field is lost, with the issue tracking its conversion when there is one.
+	Reason string
+}
+
+// MAPI2CAPIMachineRoundTripTarget configures a MAPI to CAPI Machine round trip fuzz target.
+type MAPI2CAPIMachineRoundTripTarget struct {
+	Scheme        *runtime.Scheme
+	Infra         *configv1.Infrastructure
+	InfraCluster  client.Object
+	MAPIConverter MAPI2CAPIMachineConverterConstructor
+	CAPIConverter CAPI2MAPIMachineConverterConstructor
+	FuzzerFuncs   []fuzzer.FuzzerFuncs
+
+	// Losses are the providerSpec fields that are not compared, as the conversion is known to drop them.
+	Losses []Loss
+}
+
+// MAPI2CAPIMachineRoundTripFuzzTarget runs a native Go fuzz target converting MAPI Machines to CAPI and back.
+// The fuzzing engine input is the source of randomness of the fuzzer, so the inputs of the seed corpus, in
+// testdata/fuzz/<FuzzTarget>, are replayed on every go test run, and go test -fuzz explores new ones, writing
+// those that fail to the corpus.
+// A Machine the conversion rejects, or converts with warnings, loses data explicitly and is skipped. Any other
+// Machine must round trip, apart from the documented losses of its providerSpec.
+func MAPI2CAPIMachineRoundTripFuzzTarget(f *testing.F, target MAPI2CAPIMachineRoundTripTarget) {
+	f.Helper()
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		g := NewWithT(t)
+
+		machine := &mapiv1.Machine{}
+		getFuzzerWithSource(bytesource.New(data), target.Scheme, target.FuzzerFuncs...).Fuzz(machine)
+
+		capiMachine, infraMachine, warnings, err := target.MAPIConverter(machine, target.Infra).ToMachineAndInfrastructureMachine()
+		if err != nil || len(warnings) > 0 {
+			t.Skipf("conversion rejected the machine, warnings: %v, error: %v", warnings, err)
+		}
+
+		mapiMachine, warnings, err := target.CAPIConverter(capiMachine, infraMachine, target.InfraCluster).ToMachine()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(warnings).To(BeEmpty())
+
+		g.Expect(mapiMachine.TypeMeta).To(Equal(machine.TypeMeta))
+		g.Expect(mapiMachine.ObjectMeta).To(Equal(machine.ObjectMeta))
+		g.Expect(mapiMachine.Spec).To(WithTransform(ignoreMachineProviderSpec, testutils.MatchViaJSON(ignoreMachineProviderSpec(machine.Spec))))
+		g.Expect(withoutLosses(g, mapiMachine.Spec.ProviderSpec.Value.Raw, target.Losses)).To(MatchJSON(withoutLosses(g, machine.Spec.ProviderSpec.Value.Raw, target.Losses)))
+	})
+}
+
+// withoutLosses returns the raw providerSpec with the lost fields removed.
+func withoutLosses(g Gomega, raw []byte, losses []Loss) []byte {
+	providerSpec := map[string]interface{}{}
+	g.Expect(json.Unmarshal(raw, &providerSpec)).To(Succeed())
+
+	for _, loss := range losses {
+		unstructured.RemoveNestedField(providerSpec, strings.Split(loss.Field, ".")...)
+	}
+
+	out, err := json.Marshal(providerSpec)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	return out
+}
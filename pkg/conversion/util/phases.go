@@ -0,0 +1,123 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package util
+
+import (
+	mapiv1beta1 "github.com/openshift/api/machine/v1beta1"
+	"k8s.io/utils/ptr"
+	capiv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+)
+
+// machinePhases maps the phases of the MAPI Machines to the phases of the CAPI Machines, in both directions.
+//
+//	| MAPI         | CAPI         | Meaning                                                        |
+//	|--------------|--------------|----------------------------------------------------------------|
+//	| Provisioning | Provisioning | the instance is being created                                  |
+//	| Provisioned  | Provisioned  | the instance exists, its Node has not joined the cluster yet   |
+//	| Running      | Running      | the Node of the instance has joined the cluster                |
+//	| Deleting     | Deleting     | the Machine is being deleted                                   |
+//	| Failed       | Failed       | the Machine hit a terminal error, see its error/failure fields |
+var machinePhases = []struct { //nolint:gochecknoglobals
+	mapi string
+	capi capiv1.MachinePhase
+}{
+	{mapi: mapiv1beta1.PhaseProvisioning, capi: capiv1.MachinePhaseProvisioning},
+	{mapi: mapiv1beta1.PhaseProvisioned, capi: capiv1.MachinePhaseProvisioned},
+	{mapi: mapiv1beta1.PhaseRunning, capi: capiv1.MachinePhaseRunning},
+	{mapi: mapiv1beta1.PhaseDeleting, capi: capiv1.MachinePhaseDeleting},
+	{mapi: mapiv1beta1.PhaseFailed, capi: capiv1.MachinePhaseFailed},
+}
+
+// capiOnlyMachinePhases maps the phases of the CAPI Machines MAPI has no equivalent of to the closest MAPI phase:
+//
+//	| CAPI    | MAPI         | Reason                                                                  |
+//	|---------|--------------|-------------------------------------------------------------------------|
+//	| Pending | Provisioning | MAPI has no phase before the instance is being created                  |
+//	| Deleted | Deleting     | MAPI Machines are removed once deleted, they have no phase after that   |
+//
+// The Unknown phase is not mapped, the phase of the MAPI Machine is left unset instead.
+var capiOnlyMachinePhases = map[capiv1.MachinePhase]string{ //nolint:gochecknoglobals
+	capiv1.MachinePhasePending: mapiv1beta1.PhaseProvisioning,
+	capiv1.MachinePhaseDeleted: mapiv1beta1.PhaseDeleting,
+}
+
+// ConvertMAPIMachinePhaseToCAPI returns the phase of the CAPI Machine equivalent to the phase of a MAPI Machine.
+// An unset or unknown phase is converted to an unset phase, which the CAPI Machine controller sets once it
+// reconciles the Machine.
+func ConvertMAPIMachinePhaseToCAPI(phase *string) string {
+	if phase == nil {
+		return ""
+	}
+
+	for _, mapping := range machinePhases {
+		if mapping.mapi == *phase {
+			return string(mapping.capi)
+		}
+	}
+
+	return ""
+}
+
+// ConvertCAPIMachinePhaseToMAPI returns the phase of the MAPI Machine equivalent to the phase of a CAPI Machine.
+// The CAPI phases MAPI has no equivalent of are converted to the closest MAPI phase, and an unset, Unknown or
+// unknown phase to nil.
+func ConvertCAPIMachinePhaseToMAPI(phase string) *string {
+	for _, mapping := range machinePhases {
+		if string(mapping.capi) == phase {
+			return ptr.To(mapping.mapi)
+		}
+	}
+
+	if mapiPhase, ok := capiOnlyMachinePhases[capiv1.MachinePhase(phase)]; ok {
+		return ptr.To(mapiPhase)
+	}
+
+	return nil
+}
+
+// ConvertMAPIMachineErrorToCAPI returns the failure reason and message of the CAPI Machine equivalent to the error
+// reason and message of a MAPI Machine. Both APIs share the same terminal error reasons, the unknown reasons are
+// kept as is so that the error is not lost.
+func ConvertMAPIMachineErrorToCAPI(reason *mapiv1beta1.MachineStatusError, message *string) (*capierrors.MachineStatusError, *string) {
+	var failureReason *capierrors.MachineStatusError
+	if reason != nil {
+		failureReason = ptr.To(capierrors.MachineStatusError(*reason))
+	}
+
+	return failureReason, copyString(message)
+}
+
+// ConvertCAPIMachineFailureToMAPI returns the error reason and message of the MAPI Machine equivalent to the failure
+// reason and message of a CAPI Machine. Both APIs share the same terminal error reasons, the unknown reasons are
+// kept as is so that the failure is not lost.
+func ConvertCAPIMachineFailureToMAPI(reason *capierrors.MachineStatusError, message *string) (*mapiv1beta1.MachineStatusError, *string) {
+	var errorReason *mapiv1beta1.MachineStatusError
+	if reason != nil {
+		errorReason = ptr.To(mapiv1beta1.MachineStatusError(*reason))
+	}
+
+	return errorReason, copyString(message)
+}
+
+// copyString returns a copy of the string pointer, so that the converted object does not share it with its source.
+func copyString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+
+	return ptr.To(*s)
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package util
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	mapiv1beta1 "github.com/openshift/api/machine/v1beta1"
+	"k8s.io/utils/ptr"
+	capiv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+)
+
+var _ = Describe("Machine phase conversion", func() {
+	DescribeTable("should convert the MAPI Machine phases to CAPI",
+		func(phase *string, expected capiv1.MachinePhase) {
+			Expect(ConvertMAPIMachinePhaseToCAPI(phase)).To(Equal(string(expected)))
+		},
+		Entry("with an unset phase", nil, capiv1.MachinePhase("")),
+		Entry("with an empty phase", ptr.To(""), capiv1.MachinePhase("")),
+		Entry("with the Provisioning phase", ptr.To(mapiv1beta1.PhaseProvisioning), capiv1.MachinePhaseProvisioning),
+		Entry("with the Provisioned phase", ptr.To(mapiv1beta1.PhaseProvisioned), capiv1.MachinePhaseProvisioned),
+		Entry("with the Running phase", ptr.To(mapiv1beta1.PhaseRunning), capiv1.MachinePhaseRunning),
+		Entry("with the Deleting phase", ptr.To(mapiv1beta1.PhaseDeleting), capiv1.MachinePhaseDeleting),
+		Entry("with the Failed phase", ptr.To(mapiv1beta1.PhaseFailed), capiv1.MachinePhaseFailed),
+		Entry("with an unknown phase", ptr.To("Hibernating"), capiv1.MachinePhase("")),
+		Entry("with a phase of a different case", ptr.To("running"), capiv1.MachinePhase("")),
+	)
+
+	DescribeTable("should convert the CAPI Machine phases to MAPI",
+		func(phase capiv1.MachinePhase, expected *string) {
+			Expect(ConvertCAPIMachinePhaseToMAPI(string(phase))).To(Equal(expected))
+		},
+		Entry("with an unset phase", capiv1.MachinePhase(""), nil),
+		Entry("with the Pending phase", capiv1.MachinePhasePending, ptr.To(mapiv1beta1.PhaseProvisioning)),
+		Entry("with the Provisioning phase", capiv1.MachinePhaseProvisioning, ptr.To(mapiv1beta1.PhaseProvisioning)),
+		Entry("with the Provisioned phase", capiv1.MachinePhaseProvisioned, ptr.To(mapiv1beta1.PhaseProvisioned)),
+		Entry("with the Running phase", capiv1.MachinePhaseRunning, ptr.To(mapiv1beta1.PhaseRunning)),
+		Entry("with the Deleting phase", capiv1.MachinePhaseDeleting, ptr.To(mapiv1beta1.PhaseDeleting)),
+		Entry("with the Deleted phase", capiv1.MachinePhaseDeleted, ptr.To(mapiv1beta1.PhaseDeleting)),
+		Entry("with the Failed phase", capiv1.MachinePhaseFailed, ptr.To(mapiv1beta1.PhaseFailed)),
+		Entry("with the Unknown phase", capiv1.MachinePhaseUnknown, nil),
+		Entry("with an unknown phase", capiv1.MachinePhase("Hibernating"), nil),
+	)
+
+	It("should round trip the phases both APIs have", func() {
+		for _, mapping := range machinePhases {
+			Expect(ConvertCAPIMachinePhaseToMAPI(ConvertMAPIMachinePhaseToCAPI(ptr.To(mapping.mapi)))).To(Equal(ptr.To(mapping.mapi)))
+			Expect(ConvertMAPIMachinePhaseToCAPI(ConvertCAPIMachinePhaseToMAPI(string(mapping.capi)))).To(Equal(string(mapping.capi)))
+		}
+	})
+
+	It("should map every CAPI phase but Unknown", func() {
+		for _, phase := range []capiv1.MachinePhase{
+			capiv1.MachinePhasePending,
+			capiv1.MachinePhaseProvisioning,
+			capiv1.MachinePhaseProvisioned,
+			capiv1.MachinePhaseRunning,
+			capiv1.MachinePhaseDeleting,
+			capiv1.MachinePhaseDeleted,
+			capiv1.MachinePhaseFailed,
+		} {
+			Expect(ConvertCAPIMachinePhaseToMAPI(string(phase))).NotTo(BeNil(), "phase %s", phase)
+		}
+	})
+})
+
+var _ = Describe("Machine failure conversion", func() {
+	DescribeTable("should convert the MAPI Machine errors to CAPI failures",
+		func(reason *mapiv1beta1.MachineStatusError, message *string, expectedReason *capierrors.MachineStatusError) {
+			failureReason, failureMessage := ConvertMAPIMachineErrorToCAPI(reason, message)
+
+			Expect(failureReason).To(Equal(expectedReason))
+			Expect(failureMessage).To(Equal(message))
+
+			if message != nil {
+				Expect(failureMessage).NotTo(BeIdenticalTo(message), "the message should be copied")
+			}
+		},
+		Entry("without error", nil, nil, nil),
+		Entry("with an InvalidConfiguration error", ptr.To(mapiv1beta1.InvalidConfigurationMachineError), ptr.To("invalid instance type"),
+			ptr.To(capierrors.InvalidConfigurationMachineError)),
+		Entry("with an UnsupportedChange error", ptr.To(mapiv1beta1.UnsupportedChangeMachineError), ptr.To("cannot change the zone"),
+			ptr.To(capierrors.UnsupportedChangeMachineError)),
+		Entry("with an InsufficientResources error", ptr.To(mapiv1beta1.InsufficientResourcesMachineError), ptr.To("quota exceeded"),
+			ptr.To(capierrors.InsufficientResourcesMachineError)),
+		Entry("with a CreateError", ptr.To(mapiv1beta1.CreateMachineError), ptr.To("failed to launch instance"),
+			ptr.To(capierrors.CreateMachineError)),
+		Entry("with an UpdateError", ptr.To(mapiv1beta1.UpdateMachineError), ptr.To("failed to update instance"),
+			ptr.To(capierrors.UpdateMachineError)),
+		Entry("with a DeleteError", ptr.To(mapiv1beta1.DeleteMachineError), ptr.To("failed to terminate instance"),
+			ptr.To(capierrors.DeleteMachineError)),
+		Entry("with an unknown error", ptr.To(mapiv1beta1.MachineStatusError("OutOfCapacity")), ptr.To("no capacity"),
+			ptr.To(capierrors.MachineStatusError("OutOfCapacity"))),
+		Entry("with a message only", nil, ptr.To("instance terminated"), nil),
+		Entry("with a reason only", ptr.To(mapiv1beta1.CreateMachineError), nil, ptr.To(capierrors.CreateMachineError)),
+	)
+
+	DescribeTable("should convert the CAPI Machine failures to MAPI errors",
+		func(reason *capierrors.MachineStatusError, message *string, expectedReason *mapiv1beta1.MachineStatusError) {
+			errorReason, errorMessage := ConvertCAPIMachineFailureToMAPI(reason, message)
+
+			Expect(errorReason).To(Equal(expectedReason))
+			Expect(errorMessage).To(Equal(message))
+
+			if message != nil {
+				Expect(errorMessage).NotTo(BeIdenticalTo(message), "the message should be copied")
+			}
+		},
+		Entry("without failure", nil, nil, nil),
+		Entry("with an InvalidConfiguration failure", ptr.To(capierrors.InvalidConfigurationMachineError), ptr.To("invalid instance type"),
+			ptr.To(mapiv1beta1.InvalidConfigurationMachineError)),
+		Entry("with an UnsupportedChange failure", ptr.To(capierrors.UnsupportedChangeMachineError), ptr.To("cannot change the zone"),
+			ptr.To(mapiv1beta1.UnsupportedChangeMachineError)),
+		Entry("with an InsufficientResources failure", ptr.To(capierrors.InsufficientResourcesMachineError), ptr.To("quota exceeded"),
+			ptr.To(mapiv1beta1.InsufficientResourcesMachineError)),
+		Entry("with a CreateError", ptr.To(capierrors.CreateMachineError), ptr.To("failed to launch instance"),
+			ptr.To(mapiv1beta1.CreateMachineError)),
+		Entry("with an UpdateError", ptr.To(capierrors.UpdateMachineError), ptr.To("failed to update instance"),
+			ptr.To(mapiv1beta1.UpdateMachineError)),
+		Entry("with a DeleteError", ptr.To(capierrors.DeleteMachineError), ptr.To("failed to terminate instance"),
+			ptr.To(mapiv1beta1.DeleteMachineError)),
+		Entry("with an unknown failure", ptr.To(capierrors.MachineStatusError("OutOfCapacity")), ptr.To("no capacity"),
+			ptr.To(mapiv1beta1.MachineStatusError("OutOfCapacity"))),
+		Entry("with a message only", nil, ptr.To("instance terminated"), nil),
+		Entry("with a reason only", ptr.To(capierrors.CreateMachineError), nil, ptr.To(mapiv1beta1.CreateMachineError)),
+	)
+})
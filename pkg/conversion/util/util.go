@@ -16,8 +16,14 @@ limitations under the License.
 package util
 
 import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	capiv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
@@ -30,3 +36,323 @@ func IsCAPIManagedLabel(key string) bool {
 		dnsSubdomainOrName == capiv1.NodeRestrictionLabelDomain || strings.HasSuffix(dnsSubdomainOrName, "."+capiv1.NodeRestrictionLabelDomain) ||
 		dnsSubdomainOrName == capiv1.ManagedNodeLabelDomain || strings.HasSuffix(dnsSubdomainOrName, "."+capiv1.ManagedNodeLabelDomain)
 }
+
+const (
+	// MAPIDeleteMachineAnnotation marks a MAPI Machine to be prioritised for deletion when its MachineSet is scaled down.
+	// It is the MAPI equivalent of the CAPI "cluster.x-k8s.io/delete-machine" annotation.
+	MAPIDeleteMachineAnnotation = "machine.openshift.io/delete-machine"
+
+	// MAPIDeprecatedDeleteMachineAnnotation is the legacy form of the MAPI delete machine annotation,
+	// which is still honoured by the MAPI MachineSet controller.
+	MAPIDeprecatedDeleteMachineAnnotation = "cluster.k8s.io/delete-machine"
+)
+
+// IsSupportedMachineSetDeletePolicy determines if the given delete policy is supported by both MAPI and CAPI.
+// The supported policies share the same values in both APIs, so no translation is required.
+func IsSupportedMachineSetDeletePolicy(policy string) bool {
+	switch capiv1.MachineSetDeletePolicy(policy) {
+	case capiv1.RandomMachineSetDeletePolicy, capiv1.NewestMachineSetDeletePolicy, capiv1.OldestMachineSetDeletePolicy:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportedMachineSetDeletePolicies returns the list of delete policies supported by both MAPI and CAPI.
+func SupportedMachineSetDeletePolicies() []string {
+	return []string{
+		string(capiv1.RandomMachineSetDeletePolicy),
+		string(capiv1.NewestMachineSetDeletePolicy),
+		string(capiv1.OldestMachineSetDeletePolicy),
+	}
+}
+
+const (
+	// NodeLabelsAnnotation carries, as a JSON object, the MAPI Machine node labels that Cluster API does not propagate to the Node.
+	// It lets the labels survive a round trip through a CAPI Machine, so they are still applied by the MAPI node link controller.
+	NodeLabelsAnnotation = "cluster-api.openshift.io/node-labels"
+
+	// NodeAnnotationsAnnotation carries, as a JSON object, the MAPI Machine node annotations.
+	NodeAnnotationsAnnotation = "cluster-api.openshift.io/node-annotations"
+
+	// NodeTaintsAnnotation carries, as a JSON list, the MAPI Machine node taints.
+	// Cluster API Machines have no taints field, taints are applied to the Node by the MAPI node link controller.
+	NodeTaintsAnnotation = "cluster-api.openshift.io/node-taints"
+)
+
+const (
+	// MAPIMachineLabelPrefix is the prefix of the labels Machine API sets on its Machines and MachineSets.
+	// Tooling such as node selectors and the monitoring dashboards selects Machines by these labels.
+	MAPIMachineLabelPrefix = "machine.openshift.io/"
+
+	// MAPIClusterIDLabel is the infrastructure name of the cluster a MAPI Machine belongs to.
+	MAPIClusterIDLabel = MAPIMachineLabelPrefix + "cluster-api-cluster"
+	// MAPIMachineSetLabel is the name of the MAPI MachineSet a MAPI Machine belongs to.
+	MAPIMachineSetLabel = MAPIMachineLabelPrefix + "cluster-api-machineset"
+	// MAPIMachineRoleLabel is the role of the Node of a MAPI Machine, e.g. worker or infra.
+	MAPIMachineRoleLabel = MAPIMachineLabelPrefix + "cluster-api-machine-role"
+	// MAPIMachineTypeLabel is the type of the Node of a MAPI Machine, usually the same as its role.
+	MAPIMachineTypeLabel = MAPIMachineLabelPrefix + "cluster-api-machine-type"
+)
+
+// IsMAPIMachineLabel determines if a label is one of the labels Machine API sets on its Machines and MachineSets.
+func IsMAPIMachineLabel(key string) bool {
+	return strings.HasPrefix(key, MAPIMachineLabelPrefix)
+}
+
+// BackfillMAPIMachineLabels returns a copy of the labels of a converted Machine, MachineSet or MachineSet template,
+// with the machine.openshift.io/cluster-api-* labels they lack, so that MAPI tooling keeps matching the Machines
+// whichever API is authoritative.
+// The cluster is the CAPI cluster name, the machine set is the given name, if any, and the machine role and type are
+// the role of the node role label, when there is exactly one.
+// The labels already present are left untouched: they come from the authoritative resource.
+func BackfillMAPIMachineLabels(labels map[string]string, clusterName, machineSetName string) map[string]string {
+	derived := map[string]string{
+		MAPIClusterIDLabel:  clusterName,
+		MAPIMachineSetLabel: machineSetName,
+	}
+
+	if role := nodeRole(labels); role != "" {
+		derived[MAPIMachineRoleLabel] = role
+		derived[MAPIMachineTypeLabel] = role
+	}
+
+	backfilled := maps.Clone(labels)
+
+	for key, value := range derived {
+		if _, ok := labels[key]; ok || value == "" {
+			continue
+		}
+
+		if backfilled == nil {
+			backfilled = map[string]string{}
+		}
+
+		backfilled[key] = value
+	}
+
+	return backfilled
+}
+
+// nodeRole returns the role of the node role label, e.g. worker for "node-role.kubernetes.io/worker".
+// It returns an empty string when there is no such label or when there are several of them.
+func nodeRole(labels map[string]string) string {
+	role := ""
+
+	for key := range labels {
+		prefix, name, ok := strings.Cut(key, "/")
+		if !ok || prefix != capiv1.NodeRoleLabelPrefix || name == "" {
+			continue
+		}
+
+		if role != "" {
+			return ""
+		}
+
+		role = name
+	}
+
+	return role
+}
+
+// MergeMAPIMachineLabels returns the labels of the authoritative resource, with the machine.openshift.io labels of the
+// existing non-authoritative copy it does not set, such as the instance type, region and zone labels set by the MAPI
+// actuators. The authoritative labels take precedence on conflicts.
+func MergeMAPIMachineLabels(existing, authoritative map[string]string) map[string]string {
+	merged := maps.Clone(authoritative)
+
+	for key, value := range existing {
+		if _, ok := authoritative[key]; ok || !IsMAPIMachineLabel(key) {
+			continue
+		}
+
+		if merged == nil {
+			merged = map[string]string{}
+		}
+
+		merged[key] = value
+	}
+
+	return merged
+}
+
+const (
+	// MAPIAutoscalerMinSizeAnnotation sets the minimum size of a MAPI MachineSet when scaled by the cluster autoscaler.
+	// It is the MAPI equivalent of the CAPI "cluster.x-k8s.io/cluster-api-autoscaler-node-group-min-size" annotation.
+	MAPIAutoscalerMinSizeAnnotation = "machine.openshift.io/cluster-api-autoscaler-node-group-min-size"
+
+	// MAPIAutoscalerMaxSizeAnnotation sets the maximum size of a MAPI MachineSet when scaled by the cluster autoscaler.
+	// It is the MAPI equivalent of the CAPI "cluster.x-k8s.io/cluster-api-autoscaler-node-group-max-size" annotation.
+	MAPIAutoscalerMaxSizeAnnotation = "machine.openshift.io/cluster-api-autoscaler-node-group-max-size"
+
+	// MAPIAutoscalerCPUAnnotation is the number of vCPUs of the Machines of a MAPI MachineSet, used to scale it from zero.
+	MAPIAutoscalerCPUAnnotation = "machine.openshift.io/vCPU"
+	// MAPIAutoscalerMemoryAnnotation is the memory, in MiB, of the Machines of a MAPI MachineSet, used to scale it from zero.
+	MAPIAutoscalerMemoryAnnotation = "machine.openshift.io/memoryMb"
+	// MAPIAutoscalerGPUAnnotation is the number of GPUs of the Machines of a MAPI MachineSet, used to scale it from zero.
+	MAPIAutoscalerGPUAnnotation = "machine.openshift.io/GPU"
+	// MAPIAutoscalerMaxPodsAnnotation is the maximum number of pods on the Machines of a MAPI MachineSet, used to scale it from zero.
+	MAPIAutoscalerMaxPodsAnnotation = "machine.openshift.io/maxPods"
+
+	// CAPIAutoscalerCPUAnnotation is the CAPI equivalent of the MAPI "machine.openshift.io/vCPU" annotation.
+	CAPIAutoscalerCPUAnnotation = "capacity.cluster-autoscaler.kubernetes.io/cpu"
+	// CAPIAutoscalerMemoryAnnotation is the CAPI equivalent of the MAPI "machine.openshift.io/memoryMb" annotation.
+	// Its value is a resource quantity, rather than a number of MiB.
+	CAPIAutoscalerMemoryAnnotation = "capacity.cluster-autoscaler.kubernetes.io/memory"
+	// CAPIAutoscalerGPUAnnotation is the CAPI equivalent of the MAPI "machine.openshift.io/GPU" annotation.
+	CAPIAutoscalerGPUAnnotation = "capacity.cluster-autoscaler.kubernetes.io/gpu-count"
+	// CAPIAutoscalerMaxPodsAnnotation is the CAPI equivalent of the MAPI "machine.openshift.io/maxPods" annotation.
+	CAPIAutoscalerMaxPodsAnnotation = "capacity.cluster-autoscaler.kubernetes.io/maxPods"
+	// CAPIAutoscalerGPUTypeAnnotation is the resource name of the GPUs of the Machines of a CAPI MachineSet, used to scale
+	// it from zero. MAPI has no equivalent, the GPUs of the "machine.openshift.io/GPU" annotation are DefaultAutoscalerGPUType.
+	CAPIAutoscalerGPUTypeAnnotation = "capacity.cluster-autoscaler.kubernetes.io/gpu-type"
+
+	// DefaultAutoscalerGPUType is the resource name of the GPUs counted by the MAPI "machine.openshift.io/GPU" annotation.
+	DefaultAutoscalerGPUType = "nvidia.com/gpu"
+)
+
+// mapiToCAPIAutoscalerAnnotations maps the MAPI MachineSet annotations read by the cluster autoscaler to their CAPI equivalents.
+var mapiToCAPIAutoscalerAnnotations = map[string]string{
+	MAPIAutoscalerMinSizeAnnotation: capiv1.AutoscalerMinSizeAnnotation,
+	MAPIAutoscalerMaxSizeAnnotation: capiv1.AutoscalerMaxSizeAnnotation,
+	MAPIAutoscalerCPUAnnotation:     CAPIAutoscalerCPUAnnotation,
+	MAPIAutoscalerMemoryAnnotation:  CAPIAutoscalerMemoryAnnotation,
+	MAPIAutoscalerGPUAnnotation:     CAPIAutoscalerGPUAnnotation,
+	MAPIAutoscalerMaxPodsAnnotation: CAPIAutoscalerMaxPodsAnnotation,
+}
+
+// IsMAPIAutoscalerAnnotation determines if the MAPI MachineSet annotation is read by the cluster autoscaler.
+func IsMAPIAutoscalerAnnotation(key string) bool {
+	_, ok := mapiToCAPIAutoscalerAnnotations[key]
+
+	return ok
+}
+
+// IsCAPIAutoscalerAnnotation determines if the CAPI MachineSet annotation is read by the cluster autoscaler.
+func IsCAPIAutoscalerAnnotation(key string) bool {
+	if key == CAPIAutoscalerGPUTypeAnnotation {
+		return true
+	}
+
+	for _, capiKey := range mapiToCAPIAutoscalerAnnotations {
+		if key == capiKey {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ConvertMAPIAutoscalerAnnotationsToCAPI returns a copy of the MAPI MachineSet annotations where the annotations read by the
+// cluster autoscaler, to size the MachineSet and to scale it from zero, are replaced by their CAPI equivalents.
+// The MAPI annotations take precedence over CAPI annotations already present. The type of the GPUs, implied in MAPI,
+// is set explicitly, unless already present, so that GPU pools keep scaling from zero whatever the autoscaler default.
+func ConvertMAPIAutoscalerAnnotationsToCAPI(mapiAnnotations map[string]string) map[string]string {
+	if mapiAnnotations == nil {
+		return nil
+	}
+
+	capiAnnotations := maps.Clone(mapiAnnotations)
+
+	for mapiKey, capiKey := range mapiToCAPIAutoscalerAnnotations {
+		value, ok := mapiAnnotations[mapiKey]
+		if !ok {
+			continue
+		}
+
+		delete(capiAnnotations, mapiKey)
+
+		if mapiKey == MAPIAutoscalerMemoryAnnotation {
+			// The memory is a number of MiB in MAPI, but a quantity in CAPI.
+			value += "Mi"
+		}
+
+		capiAnnotations[capiKey] = value
+	}
+
+	if _, ok := capiAnnotations[CAPIAutoscalerGPUTypeAnnotation]; !ok && hasGPUs(mapiAnnotations[MAPIAutoscalerGPUAnnotation]) {
+		capiAnnotations[CAPIAutoscalerGPUTypeAnnotation] = DefaultAutoscalerGPUType
+	}
+
+	return capiAnnotations
+}
+
+// ConvertCAPIAutoscalerAnnotationsToMAPI returns a copy of the CAPI MachineSet annotations where the annotations read by the
+// cluster autoscaler, to size the MachineSet and to scale it from zero, are replaced by their MAPI equivalents.
+// The CAPI annotations take precedence over MAPI annotations already present. The default GPU type is implied in MAPI
+// and dropped, other GPU types have no MAPI equivalent and are kept as is.
+func ConvertCAPIAutoscalerAnnotationsToMAPI(fldPath *field.Path, capiAnnotations map[string]string) (map[string]string, field.ErrorList) {
+	if capiAnnotations == nil {
+		return nil, nil
+	}
+
+	var errs field.ErrorList
+
+	mapiAnnotations := maps.Clone(capiAnnotations)
+
+	for mapiKey, capiKey := range mapiToCAPIAutoscalerAnnotations {
+		value, ok := capiAnnotations[capiKey]
+		if !ok {
+			continue
+		}
+
+		delete(mapiAnnotations, capiKey)
+
+		if capiKey == CAPIAutoscalerMemoryAnnotation {
+			memory, err := resource.ParseQuantity(value)
+			if err != nil {
+				errs = append(errs, field.Invalid(fldPath.Key(capiKey), value, fmt.Sprintf("failed to parse memory: %v", err)))
+				continue
+			}
+
+			// MAPI only supports whole MiB, round up so that the autoscaler never underestimates the Machine memory.
+			value = strconv.FormatInt((memory.Value()+mebibyte-1)/mebibyte, 10)
+		}
+
+		mapiAnnotations[mapiKey] = value
+	}
+
+	if capiAnnotations[CAPIAutoscalerGPUTypeAnnotation] == DefaultAutoscalerGPUType && hasGPUs(capiAnnotations[CAPIAutoscalerGPUAnnotation]) {
+		delete(mapiAnnotations, CAPIAutoscalerGPUTypeAnnotation)
+	}
+
+	return mapiAnnotations, errs
+}
+
+// hasGPUs returns true when the GPU count of a scale from zero annotation is positive.
+func hasGPUs(count string) bool {
+	gpus, err := strconv.Atoi(count)
+
+	return err == nil && gpus > 0
+}
+
+// mebibyte is the number of bytes in a MiB.
+const mebibyte = 1024 * 1024
+
+const (
+	// AWSMinPlacementGroupPartition is the lowest partition number of an AWS partition placement group.
+	AWSMinPlacementGroupPartition = 1
+	// AWSMaxPlacementGroupPartition is the highest partition number of an AWS partition placement group.
+	AWSMaxPlacementGroupPartition = 7
+
+	// AWSNetworkInterfaceTypeAnnotation carries the MAPI AWS network interface type of the instance, when it is not
+	// the default ENA type, e.g. EFA for HPC machines. The CAPA AWSMachine API has no network interface type, so the
+	// annotation is set on the CAPI Machine and its AWSMachine, and restored when converting back.
+	AWSNetworkInterfaceTypeAnnotation = "cluster-api.openshift.io/aws-network-interface-type"
+)
+
+// LossyFieldPaths returns the sorted and deduplicated paths of the fields reported by the conversion warnings.
+// Conversion warnings are field errors, reported when a field is dropped or modified, so that the path is the
+// part of the warning before the first ": ". Warnings not carrying a path are returned as is.
+func LossyFieldPaths(warnings []string) []string {
+	paths := []string{}
+
+	for _, warning := range warnings {
+		path, _, _ := strings.Cut(warning, ": ")
+		paths = append(paths, path)
+	}
+
+	slices.Sort(paths)
+
+	return slices.Compact(paths)
+}
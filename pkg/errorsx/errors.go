@@ -0,0 +1,122 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package errorsx categorizes the errors of the conversions and of the synchronization controllers, so that their
+// callers can tell the failures worth retrying from the ones which are not resolved until the resources change.
+// The categories are matched with errors.Is, through any wrapping of the errors.
+package errorsx
+
+import (
+	"errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+var (
+	// ErrUnsupportedField categorizes the errors of the fields a conversion cannot convert.
+	// Retrying does not help, the resource must be changed.
+	ErrUnsupportedField = errors.New("unsupported field")
+
+	// ErrNotFoundCounterpart categorizes the errors of the resources whose counterpart, or a resource their
+	// counterpart references, does not exist yet. The counterpart may be created later, so it is worth retrying.
+	ErrNotFoundCounterpart = errors.New("counterpart not found")
+
+	// ErrAuthorityConflict categorizes the errors of the resources whose copies disagree in a way which prevents
+	// the authority from being handed over. Retrying does not help, the copies must be reconciled first.
+	ErrAuthorityConflict = errors.New("authority conflict")
+
+	// ErrTransientAPI categorizes the errors which are expected to resolve on their own, such as those of the API
+	// server or those of resources which have not converged yet. They are worth retrying.
+	ErrTransientAPI = errors.New("transient API error")
+)
+
+// categorizedError is an error of a category. Its message is the one of the error, so that categorizing an error
+// does not change how it is reported.
+type categorizedError struct {
+	category error
+	err      error
+}
+
+func (e *categorizedError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns both the category and the error, so that errors.Is and errors.As match either of them.
+func (e *categorizedError) Unwrap() []error {
+	return []error{e.category, e.err}
+}
+
+// UnsupportedField categorizes the error as an ErrUnsupportedField. It returns nil when the error is nil.
+func UnsupportedField(err error) error {
+	return categorize(ErrUnsupportedField, err)
+}
+
+// NotFoundCounterpart categorizes the error as an ErrNotFoundCounterpart. It returns nil when the error is nil.
+func NotFoundCounterpart(err error) error {
+	return categorize(ErrNotFoundCounterpart, err)
+}
+
+// AuthorityConflict categorizes the error as an ErrAuthorityConflict. It returns nil when the error is nil.
+func AuthorityConflict(err error) error {
+	return categorize(ErrAuthorityConflict, err)
+}
+
+// TransientAPI categorizes the error as an ErrTransientAPI. It returns nil when the error is nil.
+func TransientAPI(err error) error {
+	return categorize(ErrTransientAPI, err)
+}
+
+// UnsupportedFields categorizes each error of the aggregate as an ErrUnsupportedField. The aggregate is kept, rather
+// than wrapped, so that its errors can still be listed. It returns nil when the aggregate is nil.
+func UnsupportedFields(agg utilerrors.Aggregate) utilerrors.Aggregate {
+	if agg == nil {
+		return nil
+	}
+
+	errs := make([]error, 0, len(agg.Errors()))
+	for _, err := range agg.Errors() {
+		errs = append(errs, UnsupportedField(err))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// IsTerminal returns whether retrying does not resolve the error, because it is an ErrUnsupportedField or an
+// ErrAuthorityConflict. Such errors are only resolved once the resources change.
+func IsTerminal(err error) bool {
+	return errors.Is(err, ErrUnsupportedField) || errors.Is(err, ErrAuthorityConflict)
+}
+
+// IsTransient returns whether the error is expected to resolve on its own: an ErrTransientAPI, or an error of the API
+// server reporting a conflict, a timeout, throttling or an unavailable server.
+func IsTransient(err error) bool {
+	return errors.Is(err, ErrTransientAPI) ||
+		apierrors.IsConflict(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsInternalError(err)
+}
+
+func categorize(category, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &categorizedError{category: category, err: err}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package errorsx
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var errTest = errors.New("test error")
+
+var _ = Describe("Errors", func() {
+	It("should match the category and the error through wrapping", func() {
+		err := fmt.Errorf("failed to convert: %w", AuthorityConflict(errTest))
+
+		Expect(err).To(MatchError(ErrAuthorityConflict))
+		Expect(err).To(MatchError(errTest))
+		Expect(err).NotTo(MatchError(ErrUnsupportedField))
+		Expect(err.Error()).To(Equal("failed to convert: test error"), "categorizing should not change the message")
+	})
+
+	It("should return nil when categorizing a nil error", func() {
+		Expect(UnsupportedField(nil)).To(BeNil())
+		Expect(NotFoundCounterpart(nil)).To(BeNil())
+		Expect(AuthorityConflict(nil)).To(BeNil())
+		Expect(TransientAPI(nil)).To(BeNil())
+		Expect(UnsupportedFields(nil)).To(BeNil())
+	})
+
+	It("should categorize each error of an aggregate", func() {
+		agg := UnsupportedFields(field.ErrorList{
+			field.Invalid(field.NewPath("spec", "foo"), "foo", "foo is not supported"),
+			field.Invalid(field.NewPath("spec", "bar"), "bar", "bar is not supported"),
+		}.ToAggregate())
+
+		Expect(agg.Errors()).To(HaveLen(2))
+		Expect(agg.Errors()).To(HaveEach(MatchError(ErrUnsupportedField)))
+		Expect(agg).To(MatchError(ErrUnsupportedField))
+		Expect(IsTerminal(fmt.Errorf("failed to convert: %w", agg))).To(BeTrue())
+	})
+
+	DescribeTable("should tell the terminal and transient errors",
+		func(err error, terminal, transient bool) {
+			Expect(IsTerminal(err)).To(Equal(terminal))
+			Expect(IsTransient(err)).To(Equal(transient))
+		},
+		Entry("unsupported field", UnsupportedField(errTest), true, false),
+		Entry("authority conflict", AuthorityConflict(errTest), true, false),
+		Entry("counterpart not found", NotFoundCounterpart(errTest), false, false),
+		Entry("transient API error", TransientAPI(errTest), false, true),
+		Entry("API conflict", apierrors.NewConflict(schema.GroupResource{Resource: "machines"}, "foo", errTest), false, true),
+		Entry("API timeout", apierrors.NewServerTimeout(schema.GroupResource{Resource: "machines"}, "get", 1), false, true),
+		Entry("uncategorized error", utilerrors.NewAggregate([]error{errTest}), false, false),
+	)
+})
@@ -0,0 +1,299 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package inspect dumps the state of paired MAPI and CAPI Machines and MachineSets,
+// to help debugging the synchronization between the two APIs during migrations.
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/machinesetsync"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+)
+
+const (
+	machineSetKind = "MachineSet"
+	machineKind    = "Machine"
+
+	// notApplicable is printed for columns that have no value for a given resource.
+	notApplicable = "-"
+)
+
+// Options configures which resources are inspected.
+type Options struct {
+	MAPINamespace string
+	CAPINamespace string
+}
+
+// ResourceState is the state of a pair of MAPI and CAPI resources sharing the same name.
+type ResourceState struct {
+	Kind             string
+	Name             string
+	MAPI             bool
+	CAPI             bool
+	AuthoritativeAPI string
+	Synchronized     string
+	Paused           string
+	PendingChanges   string
+}
+
+// Run inspects the MAPI and CAPI MachineSets and Machines and prints their paired state as a table to out.
+func Run(ctx context.Context, cl client.Client, opts Options, out io.Writer) error {
+	states, err := Inspect(ctx, cl, opts)
+	if err != nil {
+		return err
+	}
+
+	return printStates(out, states)
+}
+
+// Inspect returns the paired state of the MAPI and CAPI MachineSets and Machines.
+func Inspect(ctx context.Context, cl client.Client, opts Options) ([]ResourceState, error) {
+	machineSetStates, err := inspectMachineSets(ctx, cl, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	machineStates, err := inspectMachines(ctx, cl, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(machineSetStates, machineStates...), nil
+}
+
+// inspectMachineSets pairs MAPI and CAPI MachineSets and computes the pending changes using the MachineSet sync controller.
+func inspectMachineSets(ctx context.Context, cl client.Client, opts Options) ([]ResourceState, error) {
+	mapiMachineSets := &machinev1beta1.MachineSetList{}
+	if err := cl.List(ctx, mapiMachineSets, client.InNamespace(opts.MAPINamespace)); err != nil {
+		return nil, fmt.Errorf("failed to list MAPI machine sets: %w", err)
+	}
+
+	capiMachineSets := &capiv1beta1.MachineSetList{}
+	if err := cl.List(ctx, capiMachineSets, client.InNamespace(opts.CAPINamespace)); err != nil {
+		return nil, fmt.Errorf("failed to list CAPI machine sets: %w", err)
+	}
+
+	infra, err := util.GetInfra(ctx, cl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get infrastructure: %w", err)
+	}
+
+	platform, err := util.GetPlatform(ctx, infra)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get platform: %w", err)
+	}
+
+	// The reconciler is only used to compute the pending changes, it never writes to the cluster here.
+	reconciler := &machinesetsync.MachineSetSyncReconciler{
+		Client:        cl,
+		Infra:         infra,
+		Platform:      platform,
+		MAPINamespace: opts.MAPINamespace,
+		CAPINamespace: opts.CAPINamespace,
+	}
+
+	capiByName := map[string]*capiv1beta1.MachineSet{}
+	for i := range capiMachineSets.Items {
+		capiByName[capiMachineSets.Items[i].Name] = &capiMachineSets.Items[i]
+	}
+
+	states := []ResourceState{}
+
+	for i := range mapiMachineSets.Items {
+		mapiMachineSet := &mapiMachineSets.Items[i]
+		capiMachineSet := capiByName[mapiMachineSet.Name]
+
+		delete(capiByName, mapiMachineSet.Name)
+
+		state := ResourceState{
+			Kind:             machineSetKind,
+			Name:             mapiMachineSet.Name,
+			MAPI:             true,
+			CAPI:             capiMachineSet != nil,
+			AuthoritativeAPI: authoritativeAPI(mapiMachineSet.Spec.AuthoritativeAPI, mapiMachineSet.Status.AuthoritativeAPI),
+			Synchronized:     synchronizedCondition(mapiMachineSet.Status.Conditions, mapiMachineSet.Status.SynchronizedGeneration),
+			Paused:           notApplicable,
+		}
+
+		if capiMachineSet != nil {
+			state.Paused = paused(capiMachineSet)
+		}
+
+		changes, err := reconciler.PendingChanges(ctx, mapiMachineSet, capiMachineSet)
+		if err != nil {
+			state.PendingChanges = fmt.Sprintf("unknown: %v", err)
+		} else {
+			state.PendingChanges = pendingChanges(changes)
+		}
+
+		states = append(states, state)
+	}
+
+	for name, capiMachineSet := range capiByName {
+		states = append(states, capiOnlyState(machineSetKind, name, capiMachineSet))
+	}
+
+	sortStates(states)
+
+	return states, nil
+}
+
+// inspectMachines pairs MAPI and CAPI Machines.
+func inspectMachines(ctx context.Context, cl client.Client, opts Options) ([]ResourceState, error) {
+	mapiMachines := &machinev1beta1.MachineList{}
+	if err := cl.List(ctx, mapiMachines, client.InNamespace(opts.MAPINamespace)); err != nil {
+		return nil, fmt.Errorf("failed to list MAPI machines: %w", err)
+	}
+
+	capiMachines := &capiv1beta1.MachineList{}
+	if err := cl.List(ctx, capiMachines, client.InNamespace(opts.CAPINamespace)); err != nil {
+		return nil, fmt.Errorf("failed to list CAPI machines: %w", err)
+	}
+
+	capiByName := map[string]*capiv1beta1.Machine{}
+	for i := range capiMachines.Items {
+		capiByName[capiMachines.Items[i].Name] = &capiMachines.Items[i]
+	}
+
+	states := []ResourceState{}
+
+	for i := range mapiMachines.Items {
+		mapiMachine := &mapiMachines.Items[i]
+		capiMachine := capiByName[mapiMachine.Name]
+
+		delete(capiByName, mapiMachine.Name)
+
+		state := ResourceState{
+			Kind:             machineKind,
+			Name:             mapiMachine.Name,
+			MAPI:             true,
+			CAPI:             capiMachine != nil,
+			AuthoritativeAPI: authoritativeAPI(mapiMachine.Spec.AuthoritativeAPI, mapiMachine.Status.AuthoritativeAPI),
+			Synchronized:     synchronizedCondition(mapiMachine.Status.Conditions, mapiMachine.Status.SynchronizedGeneration),
+			Paused:           notApplicable,
+			// The Machine sync controller does not convert Machines yet, so there is nothing to compare.
+			PendingChanges: notApplicable,
+		}
+
+		if capiMachine != nil {
+			state.Paused = paused(capiMachine)
+		}
+
+		states = append(states, state)
+	}
+
+	for name, capiMachine := range capiByName {
+		states = append(states, capiOnlyState(machineKind, name, capiMachine))
+	}
+
+	sortStates(states)
+
+	return states, nil
+}
+
+// capiOnlyState returns the state of a CAPI resource that has no MAPI counterpart.
+func capiOnlyState(kind, name string, obj client.Object) ResourceState {
+	return ResourceState{
+		Kind:             kind,
+		Name:             name,
+		CAPI:             true,
+		AuthoritativeAPI: notApplicable,
+		Synchronized:     notApplicable,
+		Paused:           paused(obj),
+		PendingChanges:   notApplicable,
+	}
+}
+
+// authoritativeAPI formats the desired and observed authoritative APIs, highlighting an in progress migration.
+func authoritativeAPI(desired, observed machinev1beta1.MachineAuthority) string {
+	if desired == observed || desired == "" {
+		return string(observed)
+	}
+
+	return fmt.Sprintf("%s -> %s", observed, desired)
+}
+
+// synchronizedCondition formats the Synchronized condition of a MAPI resource.
+func synchronizedCondition(conditions []machinev1beta1.Condition, synchronizedGeneration int64) string {
+	for _, condition := range conditions {
+		if condition.Type != controllers.SynchronizedCondition {
+			continue
+		}
+
+		if condition.Reason == "" {
+			return fmt.Sprintf("%s (generation %d)", condition.Status, synchronizedGeneration)
+		}
+
+		return fmt.Sprintf("%s/%s (generation %d)", condition.Status, condition.Reason, synchronizedGeneration)
+	}
+
+	return "Unknown"
+}
+
+// paused reports whether reconciliation of a CAPI resource is paused.
+func paused(obj client.Object) string {
+	if _, ok := obj.GetAnnotations()[capiv1beta1.PausedAnnotation]; ok {
+		return "True"
+	}
+
+	return "False"
+}
+
+// pendingChanges formats the pending changes computed by the sync controllers.
+func pendingChanges(changes []string) string {
+	if len(changes) == 0 {
+		return "None"
+	}
+
+	return strings.Join(changes, ", ")
+}
+
+// sortStates sorts the states by name, so the output is stable.
+func sortStates(states []ResourceState) {
+	sort.SliceStable(states, func(i, j int) bool {
+		return states[i].Name < states[j].Name
+	})
+}
+
+// printStates prints the states as a human readable table.
+func printStates(out io.Writer, states []ResourceState) error {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+
+	fmt.Fprintln(w, "KIND\tNAME\tMAPI\tCAPI\tAUTHORITATIVE API\tSYNCHRONIZED\tPAUSED\tPENDING CHANGES")
+
+	for _, s := range states {
+		fmt.Fprintf(w, "%s\t%s\t%t\t%t\t%s\t%s\t%s\t%s\n",
+			s.Kind, s.Name, s.MAPI, s.CAPI, s.AuthoritativeAPI, s.Synchronized, s.Paused, s.PendingChanges)
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return nil
+}
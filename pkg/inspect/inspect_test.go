@@ -0,0 +1,133 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inspect
+
+import (
+	"bytes"
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	awsv1 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1 "github.com/openshift/api/config/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	capibuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/cluster-api/core/v1beta1"
+	configbuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/config/v1"
+	machinebuilder "github.com/openshift/cluster-api-actuator-pkg/testutils/resourcebuilder/machine/v1beta1"
+)
+
+const (
+	mapiNamespace = "openshift-machine-api"
+	capiNamespace = "openshift-cluster-api"
+)
+
+var _ = Describe("Inspect", func() {
+	var cl client.Client
+
+	ctx := context.Background()
+	opts := Options{MAPINamespace: mapiNamespace, CAPINamespace: capiNamespace}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(configv1.AddToScheme(scheme)).To(Succeed())
+		Expect(machinev1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(capiv1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(awsv1.AddToScheme(scheme)).To(Succeed())
+
+		mapiMachineSet := machinebuilder.MachineSet().
+			WithName("worker").
+			WithNamespace(mapiNamespace).
+			WithProviderSpecBuilder(machinebuilder.AWSProviderSpec().WithLoadBalancers(nil).WithRegion("eu-west-2")).
+			Build()
+		mapiMachineSet.Status.AuthoritativeAPI = machinev1beta1.MachineAuthorityMachineAPI
+
+		mapiMachine := machinebuilder.Machine().
+			WithName("worker-a").
+			WithNamespace(mapiNamespace).
+			WithAuthoritativeAPI(machinev1beta1.MachineAuthorityClusterAPI).
+			Build()
+		mapiMachine.Status.AuthoritativeAPI = machinev1beta1.MachineAuthorityMachineAPI
+
+		capiMachine := capibuilder.Machine().
+			WithName("worker-a").
+			WithNamespace(capiNamespace).
+			WithAnnotations(map[string]string{capiv1beta1.PausedAnnotation: ""}).
+			Build()
+
+		capiOnlyMachine := capibuilder.Machine().
+			WithName("worker-b").
+			WithNamespace(capiNamespace).
+			Build()
+
+		cl = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			configbuilder.Infrastructure().AsAWS("cluster", "eu-west-2").Build(),
+			mapiMachineSet,
+			mapiMachine,
+			capiMachine,
+			capiOnlyMachine,
+		).Build()
+	})
+
+	It("should pair the MAPI and CAPI resources", func() {
+		states, err := Inspect(ctx, cl, opts)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(states).To(Equal([]ResourceState{
+			{
+				Kind:             machineSetKind,
+				Name:             "worker",
+				MAPI:             true,
+				AuthoritativeAPI: "MachineAPI",
+				Synchronized:     "Unknown",
+				Paused:           notApplicable,
+				PendingChanges:   "create CAPI infrastructure machine template, create CAPI machine set",
+			},
+			{
+				Kind:             machineKind,
+				Name:             "worker-a",
+				MAPI:             true,
+				CAPI:             true,
+				AuthoritativeAPI: "MachineAPI -> ClusterAPI",
+				Synchronized:     "Unknown",
+				Paused:           "True",
+				PendingChanges:   notApplicable,
+			},
+			{
+				Kind:             machineKind,
+				Name:             "worker-b",
+				CAPI:             true,
+				AuthoritativeAPI: notApplicable,
+				Synchronized:     notApplicable,
+				Paused:           "False",
+				PendingChanges:   notApplicable,
+			},
+		}))
+	})
+
+	It("should print the paired resources as a table", func() {
+		out := &bytes.Buffer{}
+		Expect(Run(ctx, cl, opts, out)).To(Succeed())
+
+		Expect(out.String()).To(ContainSubstring("KIND"))
+		Expect(out.String()).To(MatchRegexp(`Machine\s+worker-a\s+true\s+true\s+MachineAPI -> ClusterAPI\s+Unknown\s+True\s+-`))
+	})
+})
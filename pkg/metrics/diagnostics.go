@@ -0,0 +1,130 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/rest"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+)
+
+var errInvalidAuthorizationVerb = errors.New("invalid diagnostics authorization verb")
+
+// DiagnosticsOptions configure the diagnostics endpoint of the managers, which serves the metrics, pprof and log
+// level endpoints with the controller-runtime authentication and authorization filter instead of a kube-rbac-proxy
+// sidecar.
+type DiagnosticsOptions struct {
+	// CertDir is the directory containing the tls.crt and tls.key serving the diagnostics endpoint.
+	// The metrics server watches the files and reloads the certificate when it is rotated.
+	// A self-signed certificate is generated when empty.
+	CertDir string
+
+	// AuthorizationVerbs maps HTTP methods to the RBAC verb the clients must be granted on the requested path.
+	// The requests using other methods are authorized with the lower case method, e.g. get for GET.
+	AuthorizationVerbs map[string]string
+}
+
+// AddFlags adds the diagnostics flags to the flag set.
+func (o *DiagnosticsOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.CertDir, "diagnostics-cert-dir", "",
+		"Directory containing the tls.crt and tls.key used to serve the diagnostics endpoint. "+
+			"The certificate is reloaded when it changes. A self-signed certificate is generated when empty.")
+
+	fs.StringToStringVar(&o.AuthorizationVerbs, "diagnostics-authorization-verbs", nil,
+		"Comma-separated list of HTTP method=RBAC verb pairs, e.g. GET=get,PUT=update, setting the verb clients must be granted "+
+			"on the non-resource URL of a diagnostics request. Other methods require their lower case name as verb.")
+}
+
+// Apply configures the metrics server options built from the Cluster API manager flags.
+// The verbs only apply to the secure diagnostics endpoint, as the insecure one does not authorize the requests.
+func (o DiagnosticsOptions) Apply(metricsOpts *metricsserver.Options) error {
+	if o.CertDir != "" {
+		metricsOpts.CertDir = o.CertDir
+	}
+
+	if len(o.AuthorizationVerbs) == 0 || metricsOpts.FilterProvider == nil {
+		return nil
+	}
+
+	verbs := make(map[string]string, len(o.AuthorizationVerbs))
+
+	for method, verb := range o.AuthorizationVerbs {
+		if method == "" || verb == "" {
+			return fmt.Errorf("%w: %q=%q", errInvalidAuthorizationVerb, method, verb)
+		}
+
+		verbs[strings.ToUpper(method)] = strings.ToLower(verb)
+	}
+
+	metricsOpts.FilterProvider = withAuthorizationVerbs(metricsOpts.FilterProvider, verbs)
+
+	return nil
+}
+
+// originalMethodKey is the context key of the HTTP method of a request authorized with another verb.
+type originalMethodKey struct{}
+
+// withAuthorizationVerbs wraps a metrics filter provider, such as the controller-runtime
+// WithAuthenticationAndAuthorization one, so that the requests are authorized with the verb their method maps to.
+// The filter authorizes the requests with their method, so it is replaced by the verb while the request is
+// authorized and restored before the request is served.
+func withAuthorizationVerbs(
+	filterProvider func(*rest.Config, *http.Client) (metricsserver.Filter, error),
+	verbs map[string]string,
+) func(*rest.Config, *http.Client) (metricsserver.Filter, error) {
+	return func(config *rest.Config, httpClient *http.Client) (metricsserver.Filter, error) {
+		filter, err := filterProvider(config, httpClient)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(log logr.Logger, handler http.Handler) (http.Handler, error) {
+			restored := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if method, ok := req.Context().Value(originalMethodKey{}).(string); ok {
+					req = req.WithContext(req.Context())
+					req.Method = method
+				}
+
+				handler.ServeHTTP(w, req)
+			})
+
+			authorized, err := filter(log, restored)
+			if err != nil {
+				return nil, err
+			}
+
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				verb, ok := verbs[req.Method]
+				if !ok {
+					authorized.ServeHTTP(w, req)
+					return
+				}
+
+				authReq := req.WithContext(context.WithValue(req.Context(), originalMethodKey{}, req.Method))
+				authReq.Method = verb
+
+				authorized.ServeHTTP(w, authReq)
+			}), nil
+		}, nil
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/rest"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+)
+
+var _ = Describe("Diagnostics options", func() {
+	var (
+		authorizedVerb string
+		servedMethod   string
+	)
+
+	// fakeFilterProvider records the verb the requests are authorized with, as the controller-runtime filter does
+	// with the lower case method of the request.
+	fakeFilterProvider := func(*rest.Config, *http.Client) (metricsserver.Filter, error) {
+		return func(_ logr.Logger, handler http.Handler) (http.Handler, error) {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				authorizedVerb = req.Method
+				handler.ServeHTTP(w, req)
+			}), nil
+		}, nil
+	}
+
+	serve := func(metricsOpts metricsserver.Options, method string) {
+		filter, err := metricsOpts.FilterProvider(nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		handler, err := filter(logr.Discard(), http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+			servedMethod = req.Method
+		}))
+		Expect(err).ToNot(HaveOccurred())
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/debug/flags/v", nil))
+	}
+
+	BeforeEach(func() {
+		authorizedVerb = ""
+		servedMethod = ""
+	})
+
+	It("should set the certificate directory", func() {
+		metricsOpts := metricsserver.Options{CertDir: "/tmp/k8s-metrics-server/serving-certs"}
+
+		Expect(DiagnosticsOptions{CertDir: "/etc/diagnostics"}.Apply(&metricsOpts)).To(Succeed())
+		Expect(metricsOpts.CertDir).To(Equal("/etc/diagnostics"))
+
+		Expect(DiagnosticsOptions{}.Apply(&metricsOpts)).To(Succeed())
+		Expect(metricsOpts.CertDir).To(Equal("/etc/diagnostics"))
+	})
+
+	It("should authorize the requests with the verb their method maps to", func() {
+		metricsOpts := metricsserver.Options{FilterProvider: fakeFilterProvider}
+
+		Expect(DiagnosticsOptions{AuthorizationVerbs: map[string]string{"put": "Update"}}.Apply(&metricsOpts)).To(Succeed())
+
+		serve(metricsOpts, http.MethodPut)
+		Expect(authorizedVerb).To(Equal("update"))
+		Expect(servedMethod).To(Equal(http.MethodPut))
+
+		serve(metricsOpts, http.MethodGet)
+		Expect(authorizedVerb).To(Equal(http.MethodGet))
+		Expect(servedMethod).To(Equal(http.MethodGet))
+	})
+
+	It("should not add a filter to the insecure endpoint", func() {
+		metricsOpts := metricsserver.Options{}
+
+		Expect(DiagnosticsOptions{AuthorizationVerbs: map[string]string{"GET": "get"}}.Apply(&metricsOpts)).To(Succeed())
+		Expect(metricsOpts.FilterProvider).To(BeNil())
+	})
+
+	It("should reject empty verbs", func() {
+		metricsOpts := metricsserver.Options{FilterProvider: fakeFilterProvider}
+
+		Expect(DiagnosticsOptions{AuthorizationVerbs: map[string]string{"GET": ""}}.Apply(&metricsOpts)).To(MatchError(errInvalidAuthorizationVerb))
+	})
+})
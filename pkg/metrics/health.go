@@ -0,0 +1,124 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	workQueueDepthMetric                   = ctrlmetrics.WorkQueueSubsystem + "_" + ctrlmetrics.DepthKey
+	workQueueLongestRunningProcessorMetric = ctrlmetrics.WorkQueueSubsystem + "_" + ctrlmetrics.LongestRunningProcessorKey
+
+	workQueueNameLabel = "name"
+)
+
+var errWorkQueueStalled = errors.New("work queue is stalled")
+
+// WorkQueueThresholds are the limits above which a controller work queue is considered stalled.
+// A zero value disables the corresponding check.
+type WorkQueueThresholds struct {
+	// MaxDepth is the maximum number of items waiting in a work queue.
+	MaxDepth int
+	// MaxProcessingTime is the maximum time a single item can be processed for,
+	// which catches reconciles that silently hang.
+	MaxProcessingTime time.Duration
+}
+
+// WorkQueueHealthCheck returns a healthz.Checker failing when the work queue of any controller exceeds the thresholds.
+// It reads the controller-runtime work queue metrics, so it covers every controller started by the manager.
+func WorkQueueHealthCheck(thresholds WorkQueueThresholds) healthz.Checker {
+	return workQueueHealthCheck(ctrlmetrics.Registry, thresholds)
+}
+
+// workQueueHealthCheck is WorkQueueHealthCheck reading the metrics from the given gatherer.
+func workQueueHealthCheck(gatherer prometheus.Gatherer, thresholds WorkQueueThresholds) healthz.Checker {
+	return func(_ *http.Request) error {
+		families, err := gatherer.Gather()
+		if err != nil {
+			return fmt.Errorf("failed to gather work queue metrics: %w", err)
+		}
+
+		var errs error
+
+		for _, family := range families {
+			switch family.GetName() {
+			case workQueueDepthMetric:
+				if thresholds.MaxDepth <= 0 {
+					continue
+				}
+
+				depths := gaugesByQueue(family)
+				for _, queue := range sortedQueues(depths) {
+					if depth := depths[queue]; depth > float64(thresholds.MaxDepth) {
+						errs = errors.Join(errs, fmt.Errorf("%w: %s has %d items queued, above the limit of %d",
+							errWorkQueueStalled, queue, int(depth), thresholds.MaxDepth))
+					}
+				}
+			case workQueueLongestRunningProcessorMetric:
+				if thresholds.MaxProcessingTime <= 0 {
+					continue
+				}
+
+				processingTimes := gaugesByQueue(family)
+				for _, queue := range sortedQueues(processingTimes) {
+					if processing := time.Duration(processingTimes[queue] * float64(time.Second)); processing > thresholds.MaxProcessingTime {
+						errs = errors.Join(errs, fmt.Errorf("%w: %s has been processing an item for %s, above the limit of %s",
+							errWorkQueueStalled, queue, processing.Round(time.Second), thresholds.MaxProcessingTime))
+					}
+				}
+			}
+		}
+
+		return errs
+	}
+}
+
+// gaugesByQueue returns the gauge values of a work queue metric family, keyed by the work queue name.
+func gaugesByQueue(family *dto.MetricFamily) map[string]float64 {
+	values := map[string]float64{}
+
+	for _, metric := range family.GetMetric() {
+		for _, label := range metric.GetLabel() {
+			if label.GetName() == workQueueNameLabel {
+				values[label.GetValue()] = metric.GetGauge().GetValue()
+			}
+		}
+	}
+
+	return values
+}
+
+// sortedQueues returns the work queue names in a stable order.
+func sortedQueues(values map[string]float64) []string {
+	queues := make([]string, 0, len(values))
+	for queue := range values {
+		queues = append(queues, queue)
+	}
+
+	sort.Strings(queues)
+
+	return queues
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package metrics
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var _ = Describe("Work queue health check", func() {
+	var (
+		registry         *prometheus.Registry
+		depth            *prometheus.GaugeVec
+		longestProcessor *prometheus.GaugeVec
+	)
+
+	BeforeEach(func() {
+		registry = prometheus.NewRegistry()
+
+		depth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Subsystem: ctrlmetrics.WorkQueueSubsystem,
+			Name:      ctrlmetrics.DepthKey,
+		}, []string{"name", "controller"})
+		longestProcessor = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Subsystem: ctrlmetrics.WorkQueueSubsystem,
+			Name:      ctrlmetrics.LongestRunningProcessorKey,
+		}, []string{"name", "controller"})
+
+		registry.MustRegister(depth, longestProcessor)
+	})
+
+	thresholds := WorkQueueThresholds{MaxDepth: 100, MaxProcessingTime: 10 * time.Minute}
+
+	It("should be healthy when the work queues are within the thresholds", func() {
+		depth.WithLabelValues("machinesync", "machinesync").Set(10)
+		longestProcessor.WithLabelValues("machinesync", "machinesync").Set(30)
+
+		Expect(workQueueHealthCheck(registry, thresholds)(nil)).To(Succeed())
+	})
+
+	It("should be unhealthy when a work queue is too deep", func() {
+		depth.WithLabelValues("machinesync", "machinesync").Set(101)
+
+		err := workQueueHealthCheck(registry, thresholds)(nil)
+		Expect(err).To(MatchError(errWorkQueueStalled))
+		Expect(err).To(MatchError(ContainSubstring("machinesync has 101 items queued")))
+	})
+
+	It("should be unhealthy when an item has been processed for too long", func() {
+		longestProcessor.WithLabelValues("machinesetsync", "machinesetsync").Set((11 * time.Minute).Seconds())
+
+		err := workQueueHealthCheck(registry, thresholds)(nil)
+		Expect(err).To(MatchError(errWorkQueueStalled))
+		Expect(err).To(MatchError(ContainSubstring("machinesetsync has been processing an item for 11m0s")))
+	})
+
+	It("should ignore the disabled thresholds", func() {
+		depth.WithLabelValues("machinesync", "machinesync").Set(1000)
+		longestProcessor.WithLabelValues("machinesync", "machinesync").Set((time.Hour).Seconds())
+
+		Expect(workQueueHealthCheck(registry, WorkQueueThresholds{})(nil)).To(Succeed())
+	})
+})
@@ -0,0 +1,137 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics holds the operator level Prometheus metrics.
+// All the metrics are registered with the controller-runtime metrics registry,
+// so they are served from the manager's diagnostics endpoint alongside the controller-runtime ones.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+const (
+	metricsNamespace = "capi_operator"
+
+	providerLabel   = "provider"
+	platformLabel   = "platform"
+	conditionLabel  = "condition"
+	machineSetLabel = "machineset"
+	fieldLabel      = "field"
+)
+
+var (
+	providerApplyFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "installer",
+		Name:      "apply_failures_total",
+		Help:      "Number of times applying the components of a CAPI provider has failed.",
+	}, []string{providerLabel})
+
+	providerRolloutDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "installer",
+		Name:      "provider_rollout_duration_seconds",
+		Help:      "Time taken to successfully apply the components of a CAPI provider.",
+		Buckets:   []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	}, []string{providerLabel})
+
+	providerLastSuccessfulApply = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "installer",
+		Name:      "provider_last_successful_apply_timestamp_seconds",
+		Help:      "Unix timestamp of the last successful apply of the components of a CAPI provider.",
+	}, []string{providerLabel})
+
+	infraClusterReady = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "infracluster",
+		Name:      "ready",
+		Help:      "Whether the InfraCluster managed by the operator is ready (1) or not (0).",
+	}, []string{platformLabel})
+
+	clusterOperatorCondition = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "clusteroperator",
+		Name:      "condition",
+		Help:      "Status of each condition set by the operator on the cluster-api ClusterOperator, 1 when True and 0 otherwise.",
+	}, []string{conditionLabel})
+
+	machineSetSyncDrift = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "machinesetsync",
+		Name:      "drift",
+		Help:      "Fields of a MachineSet whose MAPI and CAPI copies differ, 1 while drifted. Series are removed once the copies are back in sync.",
+	}, []string{machineSetLabel, fieldLabel})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		providerApplyFailures,
+		providerRolloutDuration,
+		providerLastSuccessfulApply,
+		infraClusterReady,
+		clusterOperatorCondition,
+		machineSetSyncDrift,
+	)
+}
+
+// RecordProviderApplyFailure records a failed attempt at applying the components of the given provider.
+func RecordProviderApplyFailure(provider string) {
+	providerApplyFailures.WithLabelValues(provider).Inc()
+}
+
+// RecordProviderApplySuccess records a successful apply of the components of the given provider,
+// along with the time it took to apply them.
+func RecordProviderApplySuccess(provider string, duration time.Duration) {
+	providerRolloutDuration.WithLabelValues(provider).Observe(duration.Seconds())
+	providerLastSuccessfulApply.WithLabelValues(provider).SetToCurrentTime()
+}
+
+// SetInfraClusterReady records the readiness of the InfraCluster for the given platform.
+func SetInfraClusterReady(platform configv1.PlatformType, ready bool) {
+	infraClusterReady.WithLabelValues(string(platform)).Set(boolToFloat(ready))
+}
+
+// SetClusterOperatorConditions records the status of the given ClusterOperator conditions.
+func SetClusterOperatorConditions(conds []configv1.ClusterOperatorStatusCondition) {
+	for _, c := range conds {
+		clusterOperatorCondition.WithLabelValues(string(c.Type)).Set(boolToFloat(c.Status == configv1.ConditionTrue))
+	}
+}
+
+// SetMachineSetDrift records the fields of the given MachineSet whose MAPI and CAPI copies differ,
+// replacing the previously recorded ones. No fields means the copies are in sync.
+func SetMachineSetDrift(machineSet string, fields []string) {
+	machineSetSyncDrift.DeletePartialMatch(prometheus.Labels{machineSetLabel: machineSet})
+
+	for _, field := range fields {
+		machineSetSyncDrift.WithLabelValues(machineSet, field).Set(1)
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package metrics
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+var _ = Describe("Operator metrics", func() {
+	It("should count provider apply failures per provider", func() {
+		before := testutil.ToFloat64(providerApplyFailures.WithLabelValues("aws"))
+
+		RecordProviderApplyFailure("aws")
+		RecordProviderApplyFailure("aws")
+
+		Expect(testutil.ToFloat64(providerApplyFailures.WithLabelValues("aws"))).To(Equal(before + 2))
+	})
+
+	It("should record the last successful apply of a provider", func() {
+		RecordProviderApplySuccess("cluster-api", 3*time.Second)
+
+		Expect(testutil.ToFloat64(providerLastSuccessfulApply.WithLabelValues("cluster-api"))).To(BeNumerically(">", 0))
+		Expect(testutil.CollectAndCount(providerRolloutDuration)).To(BeNumerically(">=", 1))
+	})
+
+	It("should reflect ClusterOperator condition statuses", func() {
+		SetClusterOperatorConditions([]configv1.ClusterOperatorStatusCondition{
+			{Type: "CapiInstallerControllerDegraded", Status: configv1.ConditionTrue},
+			{Type: "CapiInstallerControllerAvailable", Status: configv1.ConditionFalse},
+		})
+
+		Expect(testutil.ToFloat64(clusterOperatorCondition.WithLabelValues("CapiInstallerControllerDegraded"))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(clusterOperatorCondition.WithLabelValues("CapiInstallerControllerAvailable"))).To(Equal(0.0))
+	})
+
+	It("should reflect InfraCluster readiness", func() {
+		SetInfraClusterReady(configv1.AWSPlatformType, true)
+		Expect(testutil.ToFloat64(infraClusterReady.WithLabelValues(string(configv1.AWSPlatformType)))).To(Equal(1.0))
+
+		SetInfraClusterReady(configv1.AWSPlatformType, false)
+		Expect(testutil.ToFloat64(infraClusterReady.WithLabelValues(string(configv1.AWSPlatformType)))).To(Equal(0.0))
+	})
+
+	It("should replace the drifted fields of a machine set", func() {
+		SetMachineSetDrift("worker-a", []string{"replicas", "template"})
+		SetMachineSetDrift("worker-b", []string{"selector"})
+
+		Expect(testutil.ToFloat64(machineSetSyncDrift.WithLabelValues("worker-a", "replicas"))).To(Equal(1.0))
+		Expect(testutil.CollectAndCount(machineSetSyncDrift)).To(Equal(3))
+
+		SetMachineSetDrift("worker-a", []string{"template"})
+		Expect(testutil.CollectAndCount(machineSetSyncDrift)).To(Equal(2))
+
+		SetMachineSetDrift("worker-a", nil)
+		SetMachineSetDrift("worker-b", nil)
+		Expect(testutil.CollectAndCount(machineSetSyncDrift)).To(Equal(0))
+	})
+})
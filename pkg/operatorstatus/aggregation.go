@@ -0,0 +1,135 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package operatorstatus
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
+)
+
+// DefaultDegradedErrorBudget is how long a controller may report itself degraded before the operator is reported Degraded.
+// Transient errors, such as a short API server outage, are retried by the controllers well within the budget.
+const DefaultDegradedErrorBudget = 2 * time.Minute
+
+// controllerDegradedSuffix is the suffix of the Degraded conditions reported by the individual controllers.
+const controllerDegradedSuffix = "Degraded"
+
+// ControllerDegradedConditionType returns the type of the Degraded condition reported by the given controller.
+func ControllerDegradedConditionType(controllerName string) configv1.ClusterStatusConditionType {
+	return configv1.ClusterStatusConditionType(controllerName + controllerDegradedSuffix)
+}
+
+// AggregateDegraded rolls the Degraded conditions reported by the individual controllers up into the operator Degraded condition.
+// A controller only degrades the operator once it has been degraded for longer than the error budget, so that transient errors
+// do not make the condition flap. The message names every failing controller, when it started failing and its most recent error.
+// It also returns how long until the next controller within its budget exhausts it, or zero if there is none.
+func AggregateDegraded(conditions []configv1.ClusterOperatorStatusCondition, now time.Time, budget time.Duration) (configv1.ClusterOperatorStatusCondition, time.Duration) {
+	var (
+		failing      []string
+		requeueAfter time.Duration
+	)
+
+	for _, cond := range conditions {
+		controllerName, ok := strings.CutSuffix(string(cond.Type), controllerDegradedSuffix)
+		if !ok || controllerName == "" || cond.Status != configv1.ConditionTrue {
+			continue
+		}
+
+		if remaining := cond.LastTransitionTime.Add(budget).Sub(now); remaining > 0 {
+			if requeueAfter == 0 || remaining < requeueAfter {
+				requeueAfter = remaining
+			}
+
+			continue
+		}
+
+		failing = append(failing, fmt.Sprintf("%s degraded since %s: %s",
+			controllerName, cond.LastTransitionTime.UTC().Format(time.RFC3339), cond.Message))
+	}
+
+	if len(failing) == 0 {
+		return NewClusterOperatorStatusCondition(configv1.OperatorDegraded, configv1.ConditionFalse, ReasonAsExpected, ""), requeueAfter
+	}
+
+	slices.Sort(failing)
+
+	return NewClusterOperatorStatusCondition(configv1.OperatorDegraded, configv1.ConditionTrue, ReasonControllersDegraded,
+		strings.Join(failing, "; ")), requeueAfter
+}
+
+// DegradedRequeueAfter returns how long until a controller degraded within the error budget exhausts it, or zero if there is none.
+func (r *ClusterOperatorStatusClient) DegradedRequeueAfter(co *configv1.ClusterOperator) time.Duration {
+	_, requeueAfter := AggregateDegraded(co.Status.Conditions, time.Now(), r.degradedErrorBudget())
+
+	return requeueAfter
+}
+
+// SetControllerDegraded sets the Degraded condition of the given controller to True, with the reconcile error as message.
+// The operator Degraded condition is rolled up from the controller ones by SetStatusAvailable.
+func (r *ClusterOperatorStatusClient) SetControllerDegraded(ctx context.Context, controllerName string, reconcileErr error) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	co, err := r.GetOrCreateClusterOperator(ctx)
+	if err != nil {
+		log.Error(err, "unable to set controller status degraded", "controller", controllerName)
+		return err
+	}
+
+	conditionType := ControllerDegradedConditionType(controllerName)
+
+	current := v1helpers.FindStatusCondition(co.Status.Conditions, conditionType)
+	if current != nil && current.Status == configv1.ConditionTrue && current.Message == reconcileErr.Error() {
+		return nil
+	}
+
+	r.Recorder.Eventf(co, corev1.EventTypeWarning, "ControllerDegraded", "%s: %v", controllerName, reconcileErr)
+	log.V(2).Info("syncing status: controller degraded", "controller", controllerName, "message", reconcileErr.Error())
+
+	return r.SyncStatus(ctx, co, []configv1.ClusterOperatorStatusCondition{
+		NewClusterOperatorStatusCondition(conditionType, configv1.ConditionTrue, ReasonSyncFailed, reconcileErr.Error()),
+	})
+}
+
+// ClearControllerDegraded reverts the Degraded condition set by SetControllerDegraded.
+func (r *ClusterOperatorStatusClient) ClearControllerDegraded(ctx context.Context, controllerName string) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	co, err := r.GetOrCreateClusterOperator(ctx)
+	if err != nil {
+		log.Error(err, "unable to clear controller status degraded", "controller", controllerName)
+		return err
+	}
+
+	conditionType := ControllerDegradedConditionType(controllerName)
+	if v1helpers.IsStatusConditionFalse(co.Status.Conditions, conditionType) {
+		return nil
+	}
+
+	log.V(2).Info("syncing status: controller recovered", "controller", controllerName)
+
+	return r.SyncStatus(ctx, co, []configv1.ClusterOperatorStatusCondition{
+		NewClusterOperatorStatusCondition(conditionType, configv1.ConditionFalse, ReasonAsExpected, ""),
+	})
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package operatorstatus
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+var _ = Describe("AggregateDegraded", func() {
+	const budget = 2 * time.Minute
+
+	now := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	condition := func(conditionType string, status configv1.ConditionStatus, since time.Duration, message string) configv1.ClusterOperatorStatusCondition {
+		return configv1.ClusterOperatorStatusCondition{
+			Type:               configv1.ClusterStatusConditionType(conditionType),
+			Status:             status,
+			LastTransitionTime: metav1.NewTime(now.Add(-since)),
+			Message:            message,
+		}
+	}
+
+	It("should not be degraded when no controller is degraded", func() {
+		degraded, requeueAfter := AggregateDegraded([]configv1.ClusterOperatorStatusCondition{
+			condition("Available", configv1.ConditionTrue, time.Hour, ""),
+			condition("SecretSyncControllerDegraded", configv1.ConditionFalse, time.Hour, ""),
+		}, now, budget)
+
+		Expect(degraded.Type).To(Equal(configv1.OperatorDegraded))
+		Expect(degraded.Status).To(Equal(configv1.ConditionFalse))
+		Expect(degraded.Reason).To(Equal(ReasonAsExpected))
+		Expect(requeueAfter).To(BeZero())
+	})
+
+	It("should not be degraded while the failing controllers are within the error budget", func() {
+		degraded, requeueAfter := AggregateDegraded([]configv1.ClusterOperatorStatusCondition{
+			condition("SecretSyncControllerDegraded", configv1.ConditionTrue, 30*time.Second, "connection refused"),
+			condition("KubeconfigControllerDegraded", configv1.ConditionTrue, time.Minute, "connection refused"),
+		}, now, budget)
+
+		Expect(degraded.Status).To(Equal(configv1.ConditionFalse))
+		Expect(requeueAfter).To(Equal(time.Minute))
+	})
+
+	It("should be degraded with the failing controllers and their errors once the budget is exhausted", func() {
+		degraded, requeueAfter := AggregateDegraded([]configv1.ClusterOperatorStatusCondition{
+			condition("SecretSyncControllerDegraded", configv1.ConditionTrue, 5*time.Minute, "secret not found"),
+			condition("KubeconfigControllerDegraded", configv1.ConditionTrue, 3*time.Minute, "token expired"),
+			condition("BootstrapSecretControllerDegraded", configv1.ConditionTrue, 10*time.Second, "conflict"),
+		}, now, budget)
+
+		Expect(degraded.Status).To(Equal(configv1.ConditionTrue))
+		Expect(degraded.Reason).To(Equal(ReasonControllersDegraded))
+		Expect(degraded.Message).To(Equal(
+			"KubeconfigController degraded since 2024-06-01T11:57:00Z: token expired; " +
+				"SecretSyncController degraded since 2024-06-01T11:55:00Z: secret not found"))
+		Expect(requeueAfter).To(Equal(110 * time.Second))
+	})
+
+	It("should ignore the operator Degraded condition", func() {
+		degraded, _ := AggregateDegraded([]configv1.ClusterOperatorStatusCondition{
+			condition("Degraded", configv1.ConditionTrue, time.Hour, "previously degraded"),
+		}, now, budget)
+
+		Expect(degraded.Status).To(Equal(configv1.ConditionFalse))
+	})
+})
+
+var _ = Describe("ControllerDegradedConditionType", func() {
+	It("should suffix the controller name", func() {
+		Expect(ControllerDegradedConditionType("KubeconfigController")).To(Equal(configv1.ClusterStatusConditionType("KubeconfigControllerDegraded")))
+	})
+})
@@ -0,0 +1,76 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package operatorstatus
+
+import (
+	"context"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
+)
+
+const (
+	// controlPlaneTopologySuffix is the suffix of the conditions describing the mode the individual controllers run in
+	// for the control plane topology of the cluster.
+	controlPlaneTopologySuffix = "ExternalControlPlane"
+
+	// ReasonExternalControlPlane is the reason of the ExternalControlPlane conditions when the control plane of the
+	// cluster is external, and the controller runs in the external mode.
+	ReasonExternalControlPlane = "ExternalControlPlaneTopology"
+
+	// ReasonSelfHostedControlPlane is the reason of the ExternalControlPlane conditions when the control plane of the
+	// cluster runs on its own nodes.
+	ReasonSelfHostedControlPlane = "SelfHostedControlPlaneTopology"
+)
+
+// ControlPlaneTopologyConditionType returns the type of the ExternalControlPlane condition reported by the given
+// controller.
+func ControlPlaneTopologyConditionType(controllerName string) configv1.ClusterStatusConditionType {
+	return configv1.ClusterStatusConditionType(controllerName + controlPlaneTopologySuffix)
+}
+
+// SetControlPlaneTopologyMode sets the ExternalControlPlane condition of the given controller, True when the control
+// plane of the cluster is external, with the given message describing the mode the controller chose.
+// It does not modify any other condition.
+func (r *ClusterOperatorStatusClient) SetControlPlaneTopologyMode(ctx context.Context, controllerName string, external bool, message string) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	co, err := r.GetOrCreateClusterOperator(ctx)
+	if err != nil {
+		log.Error(err, "unable to set controller control plane topology mode", "controller", controllerName)
+		return err
+	}
+
+	status, reason := configv1.ConditionFalse, ReasonSelfHostedControlPlane
+	if external {
+		status, reason = configv1.ConditionTrue, ReasonExternalControlPlane
+	}
+
+	conditionType := ControlPlaneTopologyConditionType(controllerName)
+
+	current := v1helpers.FindStatusCondition(co.Status.Conditions, conditionType)
+	if current != nil && current.Status == status && current.Reason == reason && current.Message == message {
+		return nil
+	}
+
+	log.V(2).Info("syncing status: control plane topology mode", "controller", controllerName, "external", external, "message", message)
+
+	return r.SyncStatus(ctx, co, []configv1.ClusterOperatorStatusCondition{
+		NewClusterOperatorStatusCondition(conditionType, status, reason, message),
+	})
+}
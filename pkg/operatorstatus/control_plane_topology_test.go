@@ -0,0 +1,79 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package operatorstatus
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
+)
+
+var _ = Describe("SetControlPlaneTopologyMode", func() {
+	const controllerName = "KubeconfigController"
+
+	ctx := context.Background()
+
+	var (
+		cl           client.Client
+		statusClient *ClusterOperatorStatusClient
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(configv1.AddToScheme(scheme)).To(Succeed())
+
+		cl = fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&configv1.ClusterOperator{}).Build()
+		statusClient = &ClusterOperatorStatusClient{Client: cl}
+	})
+
+	getCondition := func() *configv1.ClusterOperatorStatusCondition {
+		co := &configv1.ClusterOperator{}
+		Expect(cl.Get(ctx, client.ObjectKey{Name: controllers.ClusterOperatorName}, co)).To(Succeed())
+
+		return v1helpers.FindStatusCondition(co.Status.Conditions, ControlPlaneTopologyConditionType(controllerName))
+	}
+
+	It("should report the external mode of the controller", func() {
+		Expect(statusClient.SetControlPlaneTopologyMode(ctx, controllerName, true, "external mode")).To(Succeed())
+
+		cond := getCondition()
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Type).To(BeEquivalentTo("KubeconfigControllerExternalControlPlane"))
+		Expect(cond.Status).To(Equal(configv1.ConditionTrue))
+		Expect(cond.Reason).To(Equal(ReasonExternalControlPlane))
+		Expect(cond.Message).To(Equal("external mode"))
+	})
+
+	It("should report the self hosted mode of the controller", func() {
+		Expect(statusClient.SetControlPlaneTopologyMode(ctx, controllerName, true, "external mode")).To(Succeed())
+		Expect(statusClient.SetControlPlaneTopologyMode(ctx, controllerName, false, "self hosted mode")).To(Succeed())
+
+		cond := getCondition()
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(configv1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(ReasonSelfHostedControlPlane))
+		Expect(cond.Message).To(Equal("self hosted mode"))
+	})
+})
@@ -18,10 +18,11 @@ package operatorstatus
 import (
 	"context"
 	"fmt"
-	"reflect"
-	"strings"
+	"slices"
+	"time"
 
-	corev1 "k8s.io/api/core/v1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -30,7 +31,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	configv1 "github.com/openshift/api/config/v1"
+	configv1alpha1 "github.com/openshift/cluster-capi-operator/pkg/apis/config/v1alpha1"
 	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/admissionpolicy"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/managednamespace"
+	"github.com/openshift/cluster-capi-operator/pkg/metrics"
 	"github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
 )
 
@@ -46,6 +51,22 @@ const (
 
 	// ReasonSyncFailed is the reason for the condition when the operator failed to sync resources.
 	ReasonSyncFailed = "SyncingFailed"
+
+	// ReasonMigrationInProgress is the reason for the Upgradeable condition when Machine API resources are being
+	// migrated to or from Cluster API, and an upgrade could leave them half migrated.
+	ReasonMigrationInProgress = "MigrationInProgress"
+
+	// ReasonCRDsMissing is the reason for the condition when a controller is stopped because the CRDs of the
+	// resources it watches were removed.
+	ReasonCRDsMissing = "CRDsMissing"
+
+	// ReasonProviderVersionSkew is the reason for the Progressing condition when the CAPI providers deployed in the
+	// cluster do not match the release payload the cluster is at, or is being updated to.
+	ReasonProviderVersionSkew = "ProviderVersionSkew"
+
+	// ReasonControllersDegraded is the reason for the Degraded condition when one or more controllers have been degraded
+	// for longer than the error budget.
+	ReasonControllersDegraded = "ControllersDegraded"
 )
 
 // ClusterOperatorStatusClient is a client for managing the status of the ClusterOperator object.
@@ -54,10 +75,17 @@ type ClusterOperatorStatusClient struct {
 	Recorder         record.EventRecorder
 	ManagedNamespace string
 	ReleaseVersion   string
+
+	// DegradedErrorBudget is how long a controller may be degraded before the operator is reported Degraded.
+	// Defaults to DefaultDegradedErrorBudget.
+	DegradedErrorBudget time.Duration
 }
 
 // SetStatusAvailable sets the Available condition to True, with the given reason
-// and message, and sets both the Progressing and Degraded conditions to False.
+// and message, and sets the Progressing condition to False.
+// Progressing is left untouched while the providers are skewed from the release payload.
+// Degraded is rolled up from the controller Degraded conditions, see AggregateDegraded,
+// and upgrades are blocked while it is True.
 func (r *ClusterOperatorStatusClient) SetStatusAvailable(ctx context.Context, availableConditionMsg string) error {
 	log := ctrl.LoggerFrom(ctx)
 
@@ -71,14 +99,28 @@ func (r *ClusterOperatorStatusClient) SetStatusAvailable(ctx context.Context, av
 		availableConditionMsg = fmt.Sprintf("Cluster CAPI Operator is available at %s", r.ReleaseVersion)
 	}
 
+	degraded, _ := AggregateDegraded(co.Status.Conditions, time.Now(), r.degradedErrorBudget())
+
 	conds := []configv1.ClusterOperatorStatusCondition{
 		NewClusterOperatorStatusCondition(configv1.OperatorAvailable, configv1.ConditionTrue, ReasonAsExpected, availableConditionMsg),
-		NewClusterOperatorStatusCondition(configv1.OperatorProgressing, configv1.ConditionFalse, ReasonAsExpected, ""),
-		NewClusterOperatorStatusCondition(configv1.OperatorDegraded, configv1.ConditionFalse, ReasonAsExpected, ""),
-		NewClusterOperatorStatusCondition(configv1.OperatorUpgradeable, configv1.ConditionTrue, ReasonAsExpected, ""),
+		degraded,
+	}
+
+	// A provider version skew is only cleared by ClearProviderVersionSkew, once the providers match the release payload.
+	if !IsProviderVersionSkewed(co) {
+		conds = append(conds, NewClusterOperatorStatusCondition(configv1.OperatorProgressing, configv1.ConditionFalse, ReasonAsExpected, ""))
+	}
+
+	// Upgrades blocked by an ongoing migration are only unblocked by ClearUpgradeBlocked, once the migration is over.
+	switch {
+	case IsUpgradeBlocked(co):
+	case degraded.Status == configv1.ConditionTrue:
+		conds = append(conds, NewClusterOperatorStatusCondition(configv1.OperatorUpgradeable, configv1.ConditionFalse, ReasonControllersDegraded, ""))
+	default:
+		conds = append(conds, NewClusterOperatorStatusCondition(configv1.OperatorUpgradeable, configv1.ConditionTrue, ReasonAsExpected, ""))
 	}
 
-	if co, shouldUpdate := clusterObjectNeedsUpdating(co, conds, r.operandVersions(), r.relatedObjects()); shouldUpdate {
+	if co, shouldUpdate := clusterObjectNeedsUpdating(co, conds, r.OperandVersions(), r.RelatedObjects()); shouldUpdate {
 		log.V(2).Info("syncing status: available")
 		return r.SyncStatus(ctx, co, conds)
 	}
@@ -86,45 +128,122 @@ func (r *ClusterOperatorStatusClient) SetStatusAvailable(ctx context.Context, av
 	return nil
 }
 
-// SetStatusDegraded sets the Degraded condition to True, with the given reason and
-// message, and sets the upgradeable condition.  It does not modify any existing
-// Available or Progressing conditions.
-func (r *ClusterOperatorStatusClient) SetStatusDegraded(ctx context.Context, reconcileErr error) error {
+// degradedErrorBudget returns the configured error budget, or DefaultDegradedErrorBudget if none is set.
+func (r *ClusterOperatorStatusClient) degradedErrorBudget() time.Duration {
+	if r.DegradedErrorBudget == 0 {
+		return DefaultDegradedErrorBudget
+	}
+
+	return r.DegradedErrorBudget
+}
+
+// SetUpgradeBlocked sets the Upgradeable condition to False, with the ReasonMigrationInProgress reason and the given message.
+// It does not modify any other condition.
+func (r *ClusterOperatorStatusClient) SetUpgradeBlocked(ctx context.Context, message string) error {
 	log := ctrl.LoggerFrom(ctx)
 
 	co, err := r.GetOrCreateClusterOperator(ctx)
 	if err != nil {
-		log.Error(err, "unable to set cluster operator status degraded")
+		log.Error(err, "unable to set cluster operator status not upgradeable")
 		return err
 	}
 
-	desiredVersions := r.operandVersions()
-	currentVersions := co.Status.Versions
+	current := v1helpers.FindStatusCondition(co.Status.Conditions, configv1.OperatorUpgradeable)
+	if IsUpgradeBlocked(co) && current.Message == message {
+		return nil
+	}
+
+	log.V(2).Info("syncing status: upgrade blocked", "message", message)
 
-	var message string
-	if !reflect.DeepEqual(desiredVersions, currentVersions) {
-		message = fmt.Sprintf("Failed when progressing towards %s because %e", printOperandVersions(desiredVersions), reconcileErr)
-	} else {
-		message = fmt.Sprintf("Failed to resync for %s because %e", printOperandVersions(desiredVersions), reconcileErr)
+	return r.SyncStatus(ctx, co, []configv1.ClusterOperatorStatusCondition{
+		NewClusterOperatorStatusCondition(configv1.OperatorUpgradeable, configv1.ConditionFalse, ReasonMigrationInProgress, message),
+	})
+}
+
+// ClearUpgradeBlocked reverts the Upgradeable condition set by SetUpgradeBlocked.
+// Upgrades stay blocked while the operator is degraded, as they are when the operator becomes degraded.
+func (r *ClusterOperatorStatusClient) ClearUpgradeBlocked(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	co, err := r.GetOrCreateClusterOperator(ctx)
+	if err != nil {
+		log.Error(err, "unable to clear cluster operator status not upgradeable")
+		return err
 	}
 
-	conds := []configv1.ClusterOperatorStatusCondition{
-		NewClusterOperatorStatusCondition(configv1.OperatorDegraded, configv1.ConditionTrue,
-			ReasonSyncFailed, message),
-		NewClusterOperatorStatusCondition(configv1.OperatorUpgradeable, configv1.ConditionFalse, ReasonAsExpected, ""),
+	if !IsUpgradeBlocked(co) {
+		return nil
 	}
 
-	// Update cluster conditions only if they have been changed
-	for _, cond := range conds {
-		if !v1helpers.IsStatusConditionPresentAndEqual(co.Status.Conditions, cond.Type, cond.Status) {
-			r.Recorder.Eventf(co, corev1.EventTypeWarning, "Status degraded", reconcileErr.Error())
-			log.V(2).Info("syncing status: degraded", "message", message)
+	status := configv1.ConditionTrue
+	if v1helpers.IsStatusConditionTrue(co.Status.Conditions, configv1.OperatorDegraded) {
+		status = configv1.ConditionFalse
+	}
 
-			return r.SyncStatus(ctx, co, conds)
-		}
+	log.V(2).Info("syncing status: upgrade unblocked")
+
+	return r.SyncStatus(ctx, co, []configv1.ClusterOperatorStatusCondition{
+		NewClusterOperatorStatusCondition(configv1.OperatorUpgradeable, status, ReasonAsExpected, ""),
+	})
+}
+
+// IsUpgradeBlocked returns whether upgrades of the cluster are blocked by an ongoing migration.
+func IsUpgradeBlocked(co *configv1.ClusterOperator) bool {
+	cond := v1helpers.FindStatusCondition(co.Status.Conditions, configv1.OperatorUpgradeable)
+
+	return cond != nil && cond.Status == configv1.ConditionFalse && cond.Reason == ReasonMigrationInProgress
+}
+
+// SetProviderVersionSkew sets the Progressing condition to True, with the ReasonProviderVersionSkew reason and the given message.
+// It does not modify any other condition.
+func (r *ClusterOperatorStatusClient) SetProviderVersionSkew(ctx context.Context, message string) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	co, err := r.GetOrCreateClusterOperator(ctx)
+	if err != nil {
+		log.Error(err, "unable to set cluster operator status progressing")
+		return err
 	}
 
-	return nil
+	current := v1helpers.FindStatusCondition(co.Status.Conditions, configv1.OperatorProgressing)
+	if IsProviderVersionSkewed(co) && current.Message == message {
+		return nil
+	}
+
+	log.V(2).Info("syncing status: provider version skew", "message", message)
+
+	return r.SyncStatus(ctx, co, []configv1.ClusterOperatorStatusCondition{
+		NewClusterOperatorStatusCondition(configv1.OperatorProgressing, configv1.ConditionTrue, ReasonProviderVersionSkew, message),
+	})
+}
+
+// ClearProviderVersionSkew reverts the Progressing condition set by SetProviderVersionSkew.
+func (r *ClusterOperatorStatusClient) ClearProviderVersionSkew(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	co, err := r.GetOrCreateClusterOperator(ctx)
+	if err != nil {
+		log.Error(err, "unable to clear cluster operator status progressing")
+		return err
+	}
+
+	if !IsProviderVersionSkewed(co) {
+		return nil
+	}
+
+	log.V(2).Info("syncing status: provider version skew resolved")
+
+	return r.SyncStatus(ctx, co, []configv1.ClusterOperatorStatusCondition{
+		NewClusterOperatorStatusCondition(configv1.OperatorProgressing, configv1.ConditionFalse, ReasonAsExpected, ""),
+	})
+}
+
+// IsProviderVersionSkewed returns whether the operator is progressing because the deployed CAPI providers do not match
+// the release payload.
+func IsProviderVersionSkewed(co *configv1.ClusterOperator) bool {
+	cond := v1helpers.FindStatusCondition(co.Status.Conditions, configv1.OperatorProgressing)
+
+	return cond != nil && cond.Status == configv1.ConditionTrue && cond.Reason == ReasonProviderVersionSkew
 }
 
 // GetOrCreateClusterOperator is responsible for fetching the cluster operator should it exist,
@@ -167,22 +286,82 @@ func (r *ClusterOperatorStatusClient) SyncStatus(ctx context.Context, co *config
 		return fmt.Errorf("failed to update cluster operator status: %w", err)
 	}
 
+	metrics.SetClusterOperatorConditions(conds)
+
 	return nil
 }
 
-func (r *ClusterOperatorStatusClient) relatedObjects() []configv1.ObjectReference {
+// RelatedObjects returns the related objects of the operator itself, followed by the given provider related objects.
+// The operator related objects are always reported, provider ones are added by the controller installing the providers.
+func (r *ClusterOperatorStatusClient) RelatedObjects(providerObjects ...configv1.ObjectReference) []configv1.ObjectReference {
 	// TBD: Add an actual set of object references from getResources method
-	return []configv1.ObjectReference{
+	relatedObjects := []configv1.ObjectReference{
 		{Resource: "namespaces", Name: controllers.DefaultManagedNamespace},
 		{Group: configv1.GroupName, Resource: "clusteroperators", Name: controllers.ClusterOperatorName},
 		{Resource: "namespaces", Name: r.ManagedNamespace},
 		{Group: "", Resource: "serviceaccounts", Name: "cluster-capi-operator"},
 		{Group: "", Resource: "configmaps", Name: "cluster-capi-operator-images"},
 		{Group: "apps", Resource: "deployments", Name: "cluster-capi-operator"},
+		{Group: "", Resource: "configmaps", Namespace: r.ManagedNamespace, Name: controllers.MigrationSummaryConfigMapName},
+		{Group: "", Resource: "configmaps", Namespace: r.ManagedNamespace, Name: controllers.ConversionReportConfigMapName},
+		{Group: configv1alpha1.GroupVersion.Group, Resource: "clusterapioperatorconfigs", Namespace: r.ManagedNamespace, Name: configv1alpha1.ClusterAPIOperatorConfigName},
+	}
+
+	for _, name := range append(slices.Clone(admissionpolicy.PolicyNames), admissionpolicy.MachinePoolPolicyName, admissionpolicy.ManagedResourcesPolicyName) {
+		relatedObjects = append(relatedObjects,
+			configv1.ObjectReference{Group: admissionregistrationv1.GroupName, Resource: "validatingadmissionpolicies", Name: name},
+			configv1.ObjectReference{Group: admissionregistrationv1.GroupName, Resource: "validatingadmissionpolicybindings", Name: name},
+		)
+	}
+
+	for _, name := range managednamespace.NetworkPolicyNames {
+		relatedObjects = append(relatedObjects,
+			configv1.ObjectReference{Group: networkingv1.GroupName, Resource: "networkpolicies", Namespace: r.ManagedNamespace, Name: name},
+		)
 	}
+
+	return mergeRelatedObjects(relatedObjects, providerObjects)
+}
+
+// OperandVersions returns the version of the operator, followed by the given provider versions.
+// The operator version is always reported, provider ones are added by the controller installing the providers.
+func (r *ClusterOperatorStatusClient) OperandVersions(providerVersions ...configv1.OperandVersion) []configv1.OperandVersion {
+	return mergeOperandVersions([]configv1.OperandVersion{{Name: controllers.OperatorVersionKey, Version: r.ReleaseVersion}}, providerVersions)
 }
-func (r *ClusterOperatorStatusClient) operandVersions() []configv1.OperandVersion {
-	return []configv1.OperandVersion{{Name: controllers.OperatorVersionKey, Version: r.ReleaseVersion}}
+
+// SetOperatorVersion sets the operator version in the ClusterOperator status, preserving the other operand versions.
+func (r *ClusterOperatorStatusClient) SetOperatorVersion(co *configv1.ClusterOperator) {
+	co.Status.Versions = mergeOperandVersions(co.Status.Versions, r.OperandVersions())
+}
+
+// mergeOperandVersions returns the current versions with the desired versions added or updated, keeping the order of the current versions.
+func mergeOperandVersions(current, desired []configv1.OperandVersion) []configv1.OperandVersion {
+	merged := append([]configv1.OperandVersion{}, current...)
+
+	for _, d := range desired {
+		i := slices.IndexFunc(merged, func(v configv1.OperandVersion) bool { return v.Name == d.Name })
+		if i < 0 {
+			merged = append(merged, d)
+			continue
+		}
+
+		merged[i] = d
+	}
+
+	return merged
+}
+
+// mergeRelatedObjects returns the current related objects with the desired ones added, keeping the order of the current related objects.
+func mergeRelatedObjects(current, desired []configv1.ObjectReference) []configv1.ObjectReference {
+	merged := append([]configv1.ObjectReference{}, current...)
+
+	for _, d := range desired {
+		if !slices.Contains(merged, d) {
+			merged = append(merged, d)
+		}
+	}
+
+	return merged
 }
 
 // NewClusterOperatorStatusCondition creates a new ClusterOperatorStatusCondition.
@@ -198,31 +377,25 @@ func NewClusterOperatorStatusCondition(conditionType configv1.ClusterStatusCondi
 	}
 }
 
-func printOperandVersions(versions []configv1.OperandVersion) string {
-	versionsOutput := []string{}
-	for _, operand := range versions {
-		versionsOutput = append(versionsOutput, fmt.Sprintf("%s: %s", operand.Name, operand.Version))
-	}
-
-	return strings.Join(versionsOutput, ", ")
-}
-
 func clusterObjectNeedsUpdating(co *configv1.ClusterOperator, conds []configv1.ClusterOperatorStatusCondition, desiredVersions []configv1.OperandVersion, desiredRelatedObjects []configv1.ObjectReference) (*configv1.ClusterOperator, bool) {
 	shouldUpdate := false
 
+	// The reason and message are compared as well, the rolled up Degraded condition changes its message when other controllers fail.
 	for _, cond := range conds {
-		if !v1helpers.IsStatusConditionPresentAndEqual(co.Status.Conditions, cond.Type, cond.Status) {
+		current := v1helpers.FindStatusCondition(co.Status.Conditions, cond.Type)
+		if current == nil || current.Status != cond.Status || current.Reason != cond.Reason || current.Message != cond.Message {
 			shouldUpdate = true
 		}
 	}
 
-	if !equality.Semantic.DeepEqual(co.Status.Versions, desiredVersions) {
-		co.Status.Versions = desiredVersions
+	// Versions and related objects reported by other controllers, such as the provider ones, are preserved.
+	if versions := mergeOperandVersions(co.Status.Versions, desiredVersions); !equality.Semantic.DeepEqual(co.Status.Versions, versions) {
+		co.Status.Versions = versions
 		shouldUpdate = true
 	}
 
-	if !equality.Semantic.DeepEqual(co.Status.RelatedObjects, desiredRelatedObjects) {
-		co.Status.RelatedObjects = desiredRelatedObjects
+	if relatedObjects := mergeRelatedObjects(co.Status.RelatedObjects, desiredRelatedObjects); !equality.Semantic.DeepEqual(co.Status.RelatedObjects, relatedObjects) {
+		co.Status.RelatedObjects = relatedObjects
 		shouldUpdate = true
 	}
 
@@ -76,6 +76,30 @@ var (
 	// fakeAWSMachineTemplateCRD is a fake AWSMachineTemplate CRD.
 	fakeAWSMachineTemplateCRD = generateCRD(v1beta2InfrastructureGroupVersion.WithKind(fakeAWSMachineTemplateKind))
 
+	// fakeAWSMachineKind is the kind for the AWSMachine.
+	fakeAWSMachineKind = "AWSMachine"
+
+	// fakeAWSMachineCRD is a fake AWSMachine CRD.
+	fakeAWSMachineCRD = generateCRD(v1beta2InfrastructureGroupVersion.WithKind(fakeAWSMachineKind))
+
+	// fakeIBMPowerVSClusterKind is the Kind for the IBMPowerVSCluster.
+	fakeIBMPowerVSClusterKind = "IBMPowerVSCluster"
+
+	// fakeIBMPowerVSClusterCRD is a fake IBMPowerVSCluster CRD.
+	fakeIBMPowerVSClusterCRD = generateCRD(v1beta2InfrastructureGroupVersion.WithKind(fakeIBMPowerVSClusterKind))
+
+	// fakeIBMPowerVSMachineKind is the kind for the IBMPowerVSMachine.
+	fakeIBMPowerVSMachineKind = "IBMPowerVSMachine"
+
+	// fakeIBMPowerVSMachineCRD is a fake IBMPowerVSMachine CRD.
+	fakeIBMPowerVSMachineCRD = generateCRD(v1beta2InfrastructureGroupVersion.WithKind(fakeIBMPowerVSMachineKind))
+
+	// fakeIBMPowerVSMachineTemplateKind is the kind for the IBMPowerVSMachineTemplate.
+	fakeIBMPowerVSMachineTemplateKind = "IBMPowerVSMachineTemplate"
+
+	// fakeIBMPowerVSMachineTemplateCRD is a fake IBMPowerVSMachineTemplate CRD.
+	fakeIBMPowerVSMachineTemplateCRD = generateCRD(v1beta2InfrastructureGroupVersion.WithKind(fakeIBMPowerVSMachineTemplateKind))
+
 	// fakeAzureClusterKind is the Kind for the AWSCluster.
 	fakeAzureClusterKind = "AzureCluster"
 
@@ -0,0 +1,70 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package test
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// manifestsDir is the directory of the manifests applied by the cluster version operator, relative to this package.
+const manifestsDir = "../../manifests"
+
+var _ = Describe("Manifests", func() {
+	It("should only contain valid Kubernetes objects", func() {
+		files, err := filepath.Glob(filepath.Join(manifestsDir, "*.yaml"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).ToNot(BeEmpty())
+
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			Expect(err).ToNot(HaveOccurred())
+
+			reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+
+			for {
+				document, err := reader.Read()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+
+				Expect(err).ToNot(HaveOccurred(), "failed to read a document of %s", file)
+
+				if len(bytes.TrimSpace(document)) == 0 {
+					continue
+				}
+
+				json, err := yaml.YAMLToJSONStrict(document)
+				Expect(err).ToNot(HaveOccurred(), "failed to parse a document of %s", file)
+
+				obj := &unstructured.Unstructured{}
+				Expect(obj.UnmarshalJSON(json)).To(Succeed(), "failed to decode a document of %s", file)
+				Expect(obj.GetAPIVersion()).ToNot(BeEmpty(), "a document of %s has no apiVersion", file)
+				Expect(obj.GetKind()).ToNot(BeEmpty(), "a document of %s has no kind", file)
+			}
+		}
+	})
+})
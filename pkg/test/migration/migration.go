@@ -0,0 +1,287 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Package migration provides helpers to author Machine API migration scenarios against a cluster running the
+// cluster-capi-operator, such as flipping the authority of machines and waiting for the migration to converge.
+//
+// The helpers do not depend on a test framework. The waiting helpers return a function that reports why the expected
+// state is not reached yet, to be polled, e.g. with gomega:
+//
+//	Eventually(c.MachineConverged(ctx, name, machinev1beta1.MachineAuthorityClusterAPI)).Should(Succeed())
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/synccommon"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+)
+
+var (
+	// errNotConverged is returned by the waiting helpers while the expected state is not reached.
+	errNotConverged = errors.New("not converged")
+
+	// errDuplicateInstance is returned when an instance is backed by several machines.
+	errDuplicateInstance = errors.New("duplicate instance")
+)
+
+// Cluster gives access to the Machine API resources of a cluster and their Cluster API mirrors.
+type Cluster struct {
+	Client client.Client
+
+	// MAPINamespace and CAPINamespace are the namespaces of the Machine API and Cluster API resources.
+	MAPINamespace string
+	CAPINamespace string
+}
+
+// New returns a Cluster using the given client, with the namespaces the operator manages by default.
+func New(cl client.Client) *Cluster {
+	return &Cluster{
+		Client:        cl,
+		MAPINamespace: consts.DefaultMAPIManagedNamespace,
+		CAPINamespace: consts.DefaultManagedNamespace,
+	}
+}
+
+// SetMachineAuthority sets the spec.authoritativeAPI of the MAPI machine with the given name.
+func (c *Cluster) SetMachineAuthority(ctx context.Context, name string, authority machinev1beta1.MachineAuthority) error {
+	return c.setAuthority(ctx, &machinev1beta1.Machine{}, name, authority)
+}
+
+// SetMachineSetAuthority sets the spec.authoritativeAPI of the MAPI machine set with the given name.
+func (c *Cluster) SetMachineSetAuthority(ctx context.Context, name string, authority machinev1beta1.MachineAuthority) error {
+	return c.setAuthority(ctx, &machinev1beta1.MachineSet{}, name, authority)
+}
+
+func (c *Cluster) setAuthority(ctx context.Context, obj client.Object, name string, authority machinev1beta1.MachineAuthority) error {
+	obj.SetNamespace(c.MAPINamespace)
+	obj.SetName(name)
+
+	patch := client.RawPatch(types.MergePatchType, []byte(fmt.Sprintf(`{"spec":{"authoritativeAPI":%q}}`, authority)))
+	if err := c.Client.Patch(ctx, obj, patch); err != nil {
+		return fmt.Errorf("failed to set the authoritative API of %T %q to %s: %w", obj, name, authority, err)
+	}
+
+	return nil
+}
+
+// MachineConverged returns a function reporting whether the MAPI machine with the given name reports the given
+// authority, with its non-authoritative copy synchronized.
+func (c *Cluster) MachineConverged(ctx context.Context, name string, authority machinev1beta1.MachineAuthority) func() error {
+	return func() error {
+		machine := &machinev1beta1.Machine{}
+		if err := c.Client.Get(ctx, client.ObjectKey{Namespace: c.MAPINamespace, Name: name}, machine); err != nil {
+			return fmt.Errorf("failed to get MAPI machine %q: %w", name, err)
+		}
+
+		return converged("machine", name, authority, machine.Status.AuthoritativeAPI, machine.Status.Conditions)
+	}
+}
+
+// MachineSetConverged returns a function reporting whether the MAPI machine set with the given name reports the given
+// authority, with its non-authoritative copy synchronized.
+func (c *Cluster) MachineSetConverged(ctx context.Context, name string, authority machinev1beta1.MachineAuthority) func() error {
+	return func() error {
+		machineSet := &machinev1beta1.MachineSet{}
+		if err := c.Client.Get(ctx, client.ObjectKey{Namespace: c.MAPINamespace, Name: name}, machineSet); err != nil {
+			return fmt.Errorf("failed to get MAPI machine set %q: %w", name, err)
+		}
+
+		return converged("machine set", name, authority, machineSet.Status.AuthoritativeAPI, machineSet.Status.Conditions)
+	}
+}
+
+func converged(kind, name string, authority, current machinev1beta1.MachineAuthority, conditions []machinev1beta1.Condition) error {
+	if current != authority {
+		return fmt.Errorf("%w: %s %q has authority %q instead of %q", errNotConverged, kind, name, current, authority)
+	}
+
+	if status := conditionStatus(conditions, consts.SynchronizedCondition); status != corev1.ConditionTrue {
+		return fmt.Errorf("%w: %s %q has its %s condition %q", errNotConverged, kind, name, consts.SynchronizedCondition, status)
+	}
+
+	return nil
+}
+
+// PauseSync pauses the synchronization of the MAPI resources with their CAPI mirrors, with the util.SyncPausedAnnotation
+// of the MAPI namespace.
+func (c *Cluster) PauseSync(ctx context.Context) error {
+	return c.setSyncPaused(ctx, true)
+}
+
+// ResumeSync resumes the synchronization paused by PauseSync.
+func (c *Cluster) ResumeSync(ctx context.Context) error {
+	return c.setSyncPaused(ctx, false)
+}
+
+func (c *Cluster) setSyncPaused(ctx context.Context, paused bool) error {
+	ns := &corev1.Namespace{}
+	ns.SetName(c.MAPINamespace)
+
+	patch := client.RawPatch(types.MergePatchType,
+		[]byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, util.SyncPausedAnnotation, strconv.FormatBool(paused))))
+	if err := c.Client.Patch(ctx, ns, patch); err != nil {
+		return fmt.Errorf("failed to set the synchronization of namespace %s paused to %t: %w", c.MAPINamespace, paused, err)
+	}
+
+	return nil
+}
+
+// MachineSyncPaused returns a function reporting whether the MAPI machine with the given name reports its
+// synchronization paused, or resumed, with its Paused condition.
+func (c *Cluster) MachineSyncPaused(ctx context.Context, name string, paused bool) func() error {
+	return func() error {
+		machine := &machinev1beta1.Machine{}
+		if err := c.Client.Get(ctx, client.ObjectKey{Namespace: c.MAPINamespace, Name: name}, machine); err != nil {
+			return fmt.Errorf("failed to get MAPI machine %q: %w", name, err)
+		}
+
+		status := conditionStatus(machine.Status.Conditions, consts.PausedCondition)
+
+		// A machine whose synchronization was never paused may not report the condition at all.
+		if (status == corev1.ConditionTrue) != paused {
+			return fmt.Errorf("%w: machine %q has its %s condition %q", errNotConverged, name, consts.PausedCondition, status)
+		}
+
+		return nil
+	}
+}
+
+// NoSyncFinalizersLeft returns a function reporting whether a MAPI or CAPI machine is being deleted but still has
+// the sync finalizer, which the sync controllers remove once the deletion is propagated to the other copy.
+func (c *Cluster) NoSyncFinalizersLeft(ctx context.Context) func() error {
+	return func() error {
+		mapiMachines := &machinev1beta1.MachineList{}
+		if err := c.Client.List(ctx, mapiMachines, client.InNamespace(c.MAPINamespace)); err != nil {
+			return fmt.Errorf("failed to list MAPI machines: %w", err)
+		}
+
+		for i := range mapiMachines.Items {
+			if isStuckBehindSyncFinalizer(&mapiMachines.Items[i]) {
+				return fmt.Errorf("%w: MAPI machine %q is being deleted but still has the sync finalizer", errNotConverged, mapiMachines.Items[i].Name)
+			}
+		}
+
+		capiMachines := &clusterv1.MachineList{}
+		if err := c.Client.List(ctx, capiMachines, client.InNamespace(c.CAPINamespace)); err != nil {
+			return fmt.Errorf("failed to list CAPI machines: %w", err)
+		}
+
+		for i := range capiMachines.Items {
+			if isStuckBehindSyncFinalizer(&capiMachines.Items[i]) {
+				return fmt.Errorf("%w: CAPI machine %q is being deleted but still has the sync finalizer", errNotConverged, capiMachines.Items[i].Name)
+			}
+		}
+
+		return nil
+	}
+}
+
+// CheckNoDuplicateInstances returns an error when an instance is backed by two machines of the same API, or by a MAPI
+// machine and a CAPI machine that is not its mirror.
+func (c *Cluster) CheckNoDuplicateInstances(ctx context.Context) error {
+	mapiMachines := &machinev1beta1.MachineList{}
+	if err := c.Client.List(ctx, mapiMachines, client.InNamespace(c.MAPINamespace)); err != nil {
+		return fmt.Errorf("failed to list MAPI machines: %w", err)
+	}
+
+	mapiProviderIDs := map[string]string{}
+
+	for _, machine := range mapiMachines.Items {
+		providerID := ptr.Deref(machine.Spec.ProviderID, "")
+		if providerID == "" {
+			continue
+		}
+
+		if other, ok := mapiProviderIDs[providerID]; ok {
+			return fmt.Errorf("%w: instance %s backs MAPI machines %q and %q", errDuplicateInstance, providerID, other, machine.Name)
+		}
+
+		mapiProviderIDs[providerID] = machine.Name
+	}
+
+	capiMachines := &clusterv1.MachineList{}
+	if err := c.Client.List(ctx, capiMachines, client.InNamespace(c.CAPINamespace)); err != nil {
+		return fmt.Errorf("failed to list CAPI machines: %w", err)
+	}
+
+	capiProviderIDs := map[string]string{}
+
+	for _, machine := range capiMachines.Items {
+		providerID := ptr.Deref(machine.Spec.ProviderID, "")
+		if providerID == "" {
+			continue
+		}
+
+		if other, ok := capiProviderIDs[providerID]; ok {
+			return fmt.Errorf("%w: instance %s backs CAPI machines %q and %q", errDuplicateInstance, providerID, other, machine.Name)
+		}
+
+		capiProviderIDs[providerID] = machine.Name
+
+		if mapiName, ok := mapiProviderIDs[providerID]; ok && mapiName != machine.Name {
+			return fmt.Errorf("%w: instance %s backs MAPI machine %q and unrelated CAPI machine %q", errDuplicateInstance, providerID, mapiName, machine.Name)
+		}
+	}
+
+	return nil
+}
+
+// MachinesDeleted returns a function reporting whether the MAPI machines with the given names, and their CAPI mirrors,
+// are deleted.
+func (c *Cluster) MachinesDeleted(ctx context.Context, names ...string) func() error {
+	return func() error {
+		for _, name := range names {
+			if err := c.Client.Get(ctx, client.ObjectKey{Namespace: c.MAPINamespace, Name: name}, &machinev1beta1.Machine{}); err == nil {
+				return fmt.Errorf("%w: MAPI machine %q still exists", errNotConverged, name)
+			} else if !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to get MAPI machine %q: %w", name, err)
+			}
+
+			if err := c.Client.Get(ctx, client.ObjectKey{Namespace: c.CAPINamespace, Name: name}, &clusterv1.Machine{}); err == nil {
+				return fmt.Errorf("%w: CAPI machine %q still exists", errNotConverged, name)
+			} else if !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to get CAPI machine %q: %w", name, err)
+			}
+		}
+
+		return nil
+	}
+}
+
+func isStuckBehindSyncFinalizer(obj client.Object) bool {
+	return !obj.GetDeletionTimestamp().IsZero() && slices.Contains(obj.GetFinalizers(), consts.SyncFinalizer)
+}
+
+func conditionStatus(conditions []machinev1beta1.Condition, conditionType machinev1beta1.ConditionType) corev1.ConditionStatus {
+	if condition := synccommon.FindCondition(conditions, conditionType); condition != nil {
+		return condition.Status
+	}
+
+	return ""
+}
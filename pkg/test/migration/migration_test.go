@@ -0,0 +1,185 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package migration
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+)
+
+var _ = Describe("Migration helpers", func() {
+	var scheme *runtime.Scheme
+
+	newCluster := func(objs ...client.Object) *Cluster {
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).WithStatusSubresource(&machinev1beta1.Machine{}).Build()
+
+		return New(cl)
+	}
+
+	mapiMachine := func(name string, providerID string) *machinev1beta1.Machine {
+		return &machinev1beta1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: consts.DefaultMAPIManagedNamespace},
+			Spec:       machinev1beta1.MachineSpec{ProviderID: ptr.To(providerID)},
+		}
+	}
+
+	capiMachine := func(name string, providerID string) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: consts.DefaultManagedNamespace},
+			Spec:       clusterv1.MachineSpec{ProviderID: ptr.To(providerID)},
+		}
+	}
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(machinev1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	Context("SetMachineAuthority", func() {
+		It("sets the authoritative API of the machine", func() {
+			c := newCluster(mapiMachine("worker", ""))
+			Expect(c.SetMachineAuthority(ctx, "worker", machinev1beta1.MachineAuthorityClusterAPI)).To(Succeed())
+
+			machine := &machinev1beta1.Machine{}
+			Expect(c.Client.Get(ctx, client.ObjectKey{Namespace: c.MAPINamespace, Name: "worker"}, machine)).To(Succeed())
+			Expect(machine.Spec.AuthoritativeAPI).To(Equal(machinev1beta1.MachineAuthorityClusterAPI))
+		})
+
+		It("returns an error when the machine does not exist", func() {
+			c := newCluster()
+			Expect(c.SetMachineAuthority(ctx, "worker", machinev1beta1.MachineAuthorityClusterAPI)).To(HaveOccurred())
+		})
+	})
+
+	Context("MachineConverged", func() {
+		It("reports a machine that has not switched authority yet", func() {
+			machine := mapiMachine("worker", "")
+			machine.Status.AuthoritativeAPI = machinev1beta1.MachineAuthorityMigrating
+			c := newCluster(machine)
+
+			Expect(c.MachineConverged(ctx, "worker", machinev1beta1.MachineAuthorityClusterAPI)()).To(MatchError(errNotConverged))
+		})
+
+		It("reports a machine that is not synchronized yet", func() {
+			machine := mapiMachine("worker", "")
+			machine.Status.AuthoritativeAPI = machinev1beta1.MachineAuthorityClusterAPI
+			machine.Status.Conditions = []machinev1beta1.Condition{{Type: consts.SynchronizedCondition, Status: corev1.ConditionFalse}}
+			c := newCluster(machine)
+
+			Expect(c.MachineConverged(ctx, "worker", machinev1beta1.MachineAuthorityClusterAPI)()).To(MatchError(errNotConverged))
+		})
+
+		It("succeeds once the machine is synchronized with the new authority", func() {
+			machine := mapiMachine("worker", "")
+			machine.Status.AuthoritativeAPI = machinev1beta1.MachineAuthorityClusterAPI
+			machine.Status.Conditions = []machinev1beta1.Condition{{Type: consts.SynchronizedCondition, Status: corev1.ConditionTrue}}
+			c := newCluster(machine)
+
+			Expect(c.MachineConverged(ctx, "worker", machinev1beta1.MachineAuthorityClusterAPI)()).To(Succeed())
+		})
+	})
+
+	Context("PauseSync", func() {
+		It("annotates the MAPI namespace", func() {
+			c := newCluster(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: consts.DefaultMAPIManagedNamespace}})
+
+			ns := &corev1.Namespace{}
+			Expect(c.PauseSync(ctx)).To(Succeed())
+			Expect(c.Client.Get(ctx, client.ObjectKey{Name: c.MAPINamespace}, ns)).To(Succeed())
+			Expect(ns.Annotations).To(HaveKeyWithValue(util.SyncPausedAnnotation, "true"))
+
+			Expect(c.ResumeSync(ctx)).To(Succeed())
+			Expect(c.Client.Get(ctx, client.ObjectKey{Name: c.MAPINamespace}, ns)).To(Succeed())
+			Expect(ns.Annotations).To(HaveKeyWithValue(util.SyncPausedAnnotation, "false"))
+		})
+	})
+
+	Context("MachineSyncPaused", func() {
+		It("treats a machine without the Paused condition as not paused", func() {
+			c := newCluster(mapiMachine("worker", ""))
+
+			Expect(c.MachineSyncPaused(ctx, "worker", false)()).To(Succeed())
+			Expect(c.MachineSyncPaused(ctx, "worker", true)()).To(MatchError(errNotConverged))
+		})
+	})
+
+	Context("NoSyncFinalizersLeft", func() {
+		It("reports a machine being deleted with the sync finalizer", func() {
+			machine := capiMachine("worker", "")
+			machine.Finalizers = []string{consts.SyncFinalizer}
+			machine.DeletionTimestamp = ptr.To(metav1.Now())
+			c := newCluster(machine)
+
+			Expect(c.NoSyncFinalizersLeft(ctx)()).To(MatchError(ContainSubstring(`CAPI machine "worker"`)))
+		})
+
+		It("ignores machines that are not being deleted", func() {
+			machine := mapiMachine("worker", "")
+			machine.Finalizers = []string{consts.SyncFinalizer}
+			c := newCluster(machine)
+
+			Expect(c.NoSyncFinalizersLeft(ctx)()).To(Succeed())
+		})
+	})
+
+	Context("CheckNoDuplicateInstances", func() {
+		It("accepts a MAPI machine and its CAPI mirror", func() {
+			c := newCluster(mapiMachine("worker", "aws:///us-east-1a/i-1"), capiMachine("worker", "aws:///us-east-1a/i-1"))
+
+			Expect(c.CheckNoDuplicateInstances(ctx)).To(Succeed())
+		})
+
+		It("reports an instance backed by two MAPI machines", func() {
+			c := newCluster(mapiMachine("worker-a", "aws:///us-east-1a/i-1"), mapiMachine("worker-b", "aws:///us-east-1a/i-1"))
+
+			Expect(c.CheckNoDuplicateInstances(ctx)).To(MatchError(errDuplicateInstance))
+		})
+
+		It("reports an instance backed by an unrelated CAPI machine", func() {
+			c := newCluster(mapiMachine("worker-a", "aws:///us-east-1a/i-1"), capiMachine("worker-b", "aws:///us-east-1a/i-1"))
+
+			Expect(c.CheckNoDuplicateInstances(ctx)).To(MatchError(errDuplicateInstance))
+		})
+	})
+
+	Context("MachinesDeleted", func() {
+		It("reports a CAPI mirror left behind", func() {
+			c := newCluster(capiMachine("worker", ""))
+
+			Expect(c.MachinesDeleted(ctx, "worker")()).To(MatchError(errNotConverged))
+		})
+
+		It("succeeds once both copies are gone", func() {
+			c := newCluster()
+
+			Expect(c.MachinesDeleted(ctx, "worker")()).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,297 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Package scale provides helpers to measure the sync controllers at scale against envtest, in Go benchmarks.
+//
+// A benchmark creates Size resources, starts its controller with a client instrumented by a RequestCounter, waits
+// for the controller to synchronize them, and then measures the requests the controller keeps sending to the API
+// server once synchronized, for the SteadyState duration. The measurements are reported as benchmark metrics by
+// Result.Report, so that they can be compared between releases, see hack/scale-test.sh.
+package scale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	goruntime "runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/openshift/cluster-capi-operator/pkg/test"
+)
+
+const (
+	// SizeEnv is the environment variable setting the number of resources the benchmarks are run with.
+	SizeEnv = "SCALE_TEST_SIZE"
+
+	// SteadyStateEnv is the environment variable setting how long the requests of the controllers are measured once
+	// they synchronized the resources, as a duration, e.g. 1m.
+	SteadyStateEnv = "SCALE_TEST_STEADY_STATE"
+
+	// DefaultSize is the number of resources the benchmarks are run with by default, small enough to run them
+	// alongside the unit tests.
+	DefaultSize = 100
+
+	// DefaultSteadyState is how long the requests of the controllers are measured by default.
+	DefaultSteadyState = 10 * time.Second
+
+	// createWorkers is the number of resources created concurrently by ForEach.
+	createWorkers = 16
+
+	// pollInterval is how often WaitFor checks whether the resources are synchronized.
+	pollInterval = 100 * time.Millisecond
+)
+
+// Size returns the number of resources to run the benchmarks with, from the SizeEnv environment variable.
+func Size() (int, error) {
+	value := os.Getenv(SizeEnv)
+	if value == "" {
+		return DefaultSize, nil
+	}
+
+	size, err := strconv.Atoi(value)
+	if err != nil || size <= 0 {
+		return 0, fmt.Errorf("invalid %s %q, expected a positive number of resources", SizeEnv, value)
+	}
+
+	return size, nil
+}
+
+// SteadyState returns how long to measure the requests of the controllers once synchronized, from the SteadyStateEnv
+// environment variable.
+func SteadyState() (time.Duration, error) {
+	value := os.Getenv(SteadyStateEnv)
+	if value == "" {
+		return DefaultSteadyState, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("invalid %s %q, expected a positive duration", SteadyStateEnv, value)
+	}
+
+	return duration, nil
+}
+
+// StartEnvTest starts a test environment for the benchmark, stopped when it completes, and returns its config and a
+// client whose requests are not rate limited, to create the resources quickly. The logs of the controllers are
+// discarded, so that they do not skew the measurements.
+func StartEnvTest(b *testing.B) (*rest.Config, client.Client) {
+	b.Helper()
+
+	logf.SetLogger(logr.Discard())
+
+	testEnv := &envtest.Environment{}
+
+	cfg, _, err := test.StartEnvTest(testEnv)
+	if err != nil {
+		b.Fatalf("failed to start the test environment: %v", err)
+	}
+
+	b.Cleanup(func() {
+		if err := test.StopEnvTest(testEnv); err != nil {
+			b.Errorf("failed to stop the test environment: %v", err)
+		}
+	})
+
+	unlimited := rest.CopyConfig(cfg)
+	unlimited.QPS = -1
+
+	cl, err := client.New(unlimited, client.Options{})
+	if err != nil {
+		b.Fatalf("failed to create the client: %v", err)
+	}
+
+	return cfg, cl
+}
+
+// ForEach calls fn with the indexes from 0 to n-1, concurrently, e.g. to create the resources of a benchmark.
+// It returns the errors of the calls.
+func ForEach(ctx context.Context, n int, fn func(ctx context.Context, i int) error) error {
+	indexes := make(chan int)
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+
+	for range createWorkers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				errs[i] = fn(ctx, i)
+			}
+		}()
+	}
+
+	for i := range n {
+		indexes <- i
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// WaitFor polls done until it returns true, an error, or the timeout expires.
+func WaitFor(ctx context.Context, timeout time.Duration, done func(ctx context.Context) (bool, error)) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if ok, err := done(ctx); err != nil {
+			return err
+		} else if ok {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("resources not synchronized: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// RequestCounter counts the requests sent to the API server with the configs it instruments.
+type RequestCounter struct {
+	count atomic.Int64
+}
+
+// Instrument returns a copy of the config whose requests are counted.
+func (c *RequestCounter) Instrument(cfg *rest.Config) *rest.Config {
+	instrumented := rest.CopyConfig(cfg)
+	instrumented.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			c.count.Add(1)
+			return rt.RoundTrip(req)
+		})
+	})
+
+	return instrumented
+}
+
+// Reset returns the number of requests counted, and restarts counting from zero.
+func (c *RequestCounter) Reset() int64 {
+	return c.count.Swap(0)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// ReconcileTotal returns the number of reconciles of the named controller, from the controller-runtime metrics.
+func ReconcileTotal(controller string) (float64, error) {
+	return gatherMetric("controller_runtime_reconcile_total", "controller", controller)
+}
+
+// QueueDepth returns the number of requests waiting in the work queue of the named controller, from the
+// controller-runtime metrics.
+func QueueDepth(controller string) (float64, error) {
+	return gatherMetric("workqueue_depth", "name", controller)
+}
+
+// gatherMetric sums the values of the counter or gauge with the given name, for the series with the given label.
+func gatherMetric(name, labelName, labelValue string) (float64, error) {
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		return 0, fmt.Errorf("failed to gather the controller metrics: %w", err)
+	}
+
+	var sum float64
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			if !hasLabel(metric, labelName, labelValue) {
+				continue
+			}
+
+			sum += metric.GetCounter().GetValue() + metric.GetGauge().GetValue()
+		}
+	}
+
+	return sum, nil
+}
+
+func hasLabel(metric *dto.Metric, name, value string) bool {
+	for _, label := range metric.GetLabel() {
+		if label.GetName() == name && label.GetValue() == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HeapInUse returns the bytes of the heap in use, after a garbage collection.
+func HeapInUse() uint64 {
+	goruntime.GC()
+
+	var stats goruntime.MemStats
+	goruntime.ReadMemStats(&stats)
+
+	return stats.HeapInuse
+}
+
+// Result holds the measurements of a benchmark run.
+type Result struct {
+	// Resources is the number of resources synchronized.
+	Resources int
+	// SyncDuration is how long the controller took to synchronize the resources.
+	SyncDuration time.Duration
+	// Reconciles is the number of reconciles the controller needed to synchronize the resources.
+	Reconciles float64
+	// HeapGrowth is the growth of the heap in use while the controller synchronized the resources, including its
+	// caches.
+	HeapGrowth int64
+	// SteadyStateRequests is the number of requests the controller sent to the API server during SteadyStateDuration,
+	// once the resources were synchronized.
+	SteadyStateRequests int64
+	SteadyStateDuration time.Duration
+}
+
+// Report reports the measurements as metrics of the benchmark. All of them are better when lower, but resources/s.
+func (r Result) Report(b *testing.B) {
+	b.Helper()
+
+	b.ReportMetric(float64(r.Resources)/r.SyncDuration.Seconds(), "resources/s")
+	b.ReportMetric(r.Reconciles/float64(r.Resources), "reconciles/resource")
+	b.ReportMetric(float64(r.HeapGrowth)/float64(r.Resources), "heap-B/resource")
+	b.ReportMetric(float64(r.SteadyStateRequests)/r.SteadyStateDuration.Seconds(), "steady-state-req/s")
+}
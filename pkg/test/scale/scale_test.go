@@ -0,0 +1,113 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package scale
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("Size", func() {
+	It("should default to DefaultSize", func() {
+		GinkgoT().Setenv(SizeEnv, "")
+
+		Expect(Size()).To(Equal(DefaultSize))
+	})
+
+	It("should read the size from the environment", func() {
+		GinkgoT().Setenv(SizeEnv, "2000")
+
+		Expect(Size()).To(Equal(2000))
+	})
+
+	It("should reject an invalid size", func() {
+		GinkgoT().Setenv(SizeEnv, "-1")
+
+		_, err := Size()
+		Expect(err).To(MatchError(ContainSubstring(SizeEnv)))
+	})
+})
+
+var _ = Describe("SteadyState", func() {
+	It("should default to DefaultSteadyState", func() {
+		GinkgoT().Setenv(SteadyStateEnv, "")
+
+		Expect(SteadyState()).To(Equal(DefaultSteadyState))
+	})
+
+	It("should read the duration from the environment", func() {
+		GinkgoT().Setenv(SteadyStateEnv, "1m")
+
+		Expect(SteadyState()).To(Equal(time.Minute))
+	})
+})
+
+var _ = Describe("ForEach", func() {
+	It("should call the function with every index", func() {
+		var calls atomic.Int64
+
+		Expect(ForEach(context.Background(), 100, func(_ context.Context, i int) error {
+			calls.Add(int64(i))
+			return nil
+		})).To(Succeed())
+
+		Expect(calls.Load()).To(BeEquivalentTo(99 * 100 / 2))
+	})
+
+	It("should return the errors of the calls", func() {
+		errFailed := errors.New("failed")
+
+		Expect(ForEach(context.Background(), 10, func(_ context.Context, i int) error {
+			if i == 5 {
+				return errFailed
+			}
+
+			return nil
+		})).To(MatchError(errFailed))
+	})
+})
+
+var _ = Describe("RequestCounter", func() {
+	It("should count the requests sent with the instrumented config", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		DeferCleanup(server.Close)
+
+		counter := &RequestCounter{}
+		cfg := counter.Instrument(&rest.Config{Host: server.URL})
+
+		httpClient, err := rest.HTTPClientFor(cfg)
+		Expect(err).ToNot(HaveOccurred())
+
+		for range 3 {
+			resp, err := httpClient.Get(server.URL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.Body.Close()).To(Succeed())
+		}
+
+		Expect(counter.Reset()).To(BeEquivalentTo(3))
+		Expect(counter.Reset()).To(BeZero())
+	})
+})
@@ -0,0 +1,122 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tracing
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName = "github.com/openshift/cluster-capi-operator"
+
+	// PhaseFetch is the span of a reconcile fetching the resources it acts on.
+	PhaseFetch = "fetch"
+	// PhaseConvert is the span of a reconcile converting resources between the Machine API and Cluster API.
+	PhaseConvert = "convert"
+	// PhaseDiff is the span of a reconcile comparing the desired resources with the existing ones.
+	PhaseDiff = "diff"
+	// PhaseApply is the span of a reconcile writing the resources.
+	PhaseApply = "apply"
+)
+
+// Options configures the export of the traces.
+type Options struct {
+	// Endpoint is the host:port of the OTLP gRPC collector the traces are exported to.
+	// Tracing is disabled when it is empty.
+	Endpoint string
+	// Insecure disables the transport security of the connection to the collector.
+	Insecure bool
+}
+
+// AddFlags registers the tracing flags on the given flag set.
+func (o *Options) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.Endpoint, "otlp-tracing-endpoint", "",
+		"The host:port of the OTLP gRPC collector the reconcile traces are exported to. Tracing is disabled when empty.")
+	fs.BoolVar(&o.Insecure, "otlp-tracing-insecure", false,
+		"Connect to the OTLP collector without transport security.")
+}
+
+// Setup installs the global tracer provider exporting the traces of the given service to the configured collector.
+// It returns a function flushing the pending spans and stopping the export, to be called before exiting.
+// When tracing is disabled the default no-op tracer provider is kept, so spans cost next to nothing.
+func Setup(ctx context.Context, opts Options, serviceName string) (func(context.Context) error, error) {
+	if opts.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporterOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(opts.Endpoint)}
+	if opts.Insecure {
+		exporterOpts = append(exporterOpts, otlptracegrpc.WithInsecure())
+	}
+
+	exporter, err := otlptracegrpc.New(ctx, exporterOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(sdkresource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName))),
+	)
+
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Start starts a span with the given name as a child of the span in the context, if any.
+// Reconcile phases use the Phase constants as names, so that they can be compared across controllers.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends the span, recording the error and marking the span as failed when it is not nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// ObjectAttributes returns the attributes identifying the object a span acts on.
+func ObjectAttributes(namespace, name string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("k8s.namespace.name", namespace),
+		attribute.String("k8s.object.name", name),
+	}
+}
+
+// Trace runs fn within a span with the given name and attributes, and ends the span with the error fn returns.
+func Trace(ctx context.Context, name string, fn func(context.Context) error, attrs ...attribute.KeyValue) error {
+	ctx, span := Start(ctx, name, attrs...)
+	err := fn(ctx)
+	End(span, err)
+
+	return err
+}
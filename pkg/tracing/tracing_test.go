@@ -0,0 +1,113 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+var errApply = errors.New("apply failed")
+
+// recordingExporter keeps the exported spans in memory.
+type recordingExporter struct {
+	mu    sync.Mutex
+	spans []sdktrace.ReadOnlySpan
+}
+
+func (e *recordingExporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = append(e.spans, spans...)
+
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func (e *recordingExporter) spanByName(name string) sdktrace.ReadOnlySpan {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, span := range e.spans {
+		if span.Name() == name {
+			return span
+		}
+	}
+
+	return nil
+}
+
+var _ = Describe("Tracing", func() {
+	var (
+		exporter *recordingExporter
+		provider *sdktrace.TracerProvider
+	)
+
+	BeforeEach(func() {
+		exporter = &recordingExporter{}
+		provider = sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+		previous := otel.GetTracerProvider()
+		otel.SetTracerProvider(provider)
+
+		DeferCleanup(func() {
+			otel.SetTracerProvider(previous)
+		})
+	})
+
+	It("should nest the phase spans in the reconcile span", func() {
+		ctx, span := Start(context.Background(), "MachineSync", ObjectAttributes("ns", "foo")...)
+		Expect(Trace(ctx, PhaseFetch, func(context.Context) error { return nil })).To(Succeed())
+		End(span, nil)
+
+		reconcileSpan := exporter.spanByName("MachineSync")
+		Expect(reconcileSpan).ToNot(BeNil())
+		Expect(reconcileSpan.Attributes()).To(ContainElements(ObjectAttributes("ns", "foo")))
+		Expect(reconcileSpan.Status().Code).To(Equal(codes.Unset))
+
+		fetchSpan := exporter.spanByName(PhaseFetch)
+		Expect(fetchSpan).ToNot(BeNil())
+		Expect(fetchSpan.Parent().SpanID()).To(Equal(reconcileSpan.SpanContext().SpanID()))
+	})
+
+	It("should record the error of a failed phase", func() {
+		Expect(Trace(context.Background(), PhaseApply, func(context.Context) error { return errApply })).To(MatchError(errApply))
+
+		applySpan := exporter.spanByName(PhaseApply)
+		Expect(applySpan).ToNot(BeNil())
+		Expect(applySpan.Status().Code).To(Equal(codes.Error))
+		Expect(applySpan.Status().Description).To(Equal(errApply.Error()))
+		Expect(applySpan.Events()).To(HaveLen(1))
+	})
+
+	It("should leave the tracer provider alone when no endpoint is configured", func() {
+		shutdown, err := Setup(context.Background(), Options{}, "test")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(otel.GetTracerProvider()).To(BeIdenticalTo(provider))
+		Expect(shutdown(context.Background())).To(Succeed())
+	})
+})
@@ -0,0 +1,104 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package util
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// APIServerEndpointOverrideAnnotation is set on the cluster-api ClusterOperator to override the endpoint the control
+// plane of the CAPI Cluster and InfraCluster is reached at. It holds a host:port pair, for private clusters exposing the
+// API server through a load balancer that is not recorded in the Infrastructure status.
+// The endpoint is only used when the Cluster and InfraCluster are created, it is immutable afterwards.
+const APIServerEndpointOverrideAnnotation = "cluster-api.openshift.io/api-server-endpoint"
+
+var (
+	// ErrInvalidAPIServerEndpointOverride is returned when the APIServerEndpointOverrideAnnotation is malformed.
+	ErrInvalidAPIServerEndpointOverride = errors.New("invalid API server endpoint override")
+)
+
+// APIServerEndpoint is the host and port the control plane of the CAPI clusters is reached at.
+type APIServerEndpoint struct {
+	Host string
+	Port int32
+}
+
+// GetAPIServerEndpoint returns the endpoint set by the APIServerEndpointOverrideAnnotation in the given annotations,
+// or the API server URL of the Infrastructure, see GetAPIServerURL, when the annotation is not set.
+func GetAPIServerEndpoint(annotations map[string]string, infra *configv1.Infrastructure) (APIServerEndpoint, error) {
+	if endpoint, found, err := GetAPIServerEndpointOverride(annotations); err != nil || found {
+		return endpoint, err
+	}
+
+	apiURL, err := url.Parse(GetAPIServerURL(infra))
+	if err != nil {
+		return APIServerEndpoint{}, fmt.Errorf("failed to parse apiURL: %w", err)
+	}
+
+	port, err := strconv.ParseInt(apiURL.Port(), 10, 32)
+	if err != nil {
+		return APIServerEndpoint{}, fmt.Errorf("failed to parse apiURL port: %w", err)
+	}
+
+	return APIServerEndpoint{Host: apiURL.Hostname(), Port: int32(port)}, nil
+}
+
+// GetAPIServerEndpointOverride returns the endpoint set by the APIServerEndpointOverrideAnnotation in the given
+// annotations, and whether it is set. An error wrapping ErrInvalidAPIServerEndpointOverride is returned when it is malformed.
+func GetAPIServerEndpointOverride(annotations map[string]string) (APIServerEndpoint, bool, error) {
+	override, ok := annotations[APIServerEndpointOverrideAnnotation]
+	if !ok {
+		return APIServerEndpoint{}, false, nil
+	}
+
+	endpoint, err := parseAPIServerEndpointOverride(override)
+	if err != nil {
+		return APIServerEndpoint{}, false, err
+	}
+
+	return endpoint, true, nil
+}
+
+// parseAPIServerEndpointOverride parses a host:port override, where host is a DNS name or an IP address.
+func parseAPIServerEndpointOverride(override string) (APIServerEndpoint, error) {
+	host, rawPort, err := net.SplitHostPort(strings.TrimSpace(override))
+	if err != nil {
+		return APIServerEndpoint{}, fmt.Errorf("%w: %q must be in the host:port form: %w", ErrInvalidAPIServerEndpointOverride, override, err)
+	}
+
+	if net.ParseIP(host) == nil {
+		if errs := validation.IsDNS1123Subdomain(host); len(errs) > 0 {
+			return APIServerEndpoint{}, fmt.Errorf("%w: host %q is neither an IP address nor a DNS name: %s",
+				ErrInvalidAPIServerEndpointOverride, host, strings.Join(errs, ", "))
+		}
+	}
+
+	port, err := strconv.ParseUint(rawPort, 10, 16)
+	if err != nil || port == 0 {
+		return APIServerEndpoint{}, fmt.Errorf("%w: port %q must be between 1 and 65535", ErrInvalidAPIServerEndpointOverride, rawPort)
+	}
+
+	return APIServerEndpoint{Host: host, Port: int32(port)}, nil
+}
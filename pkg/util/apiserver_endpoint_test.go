@@ -0,0 +1,65 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package util
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+var _ = Describe("GetAPIServerEndpoint", func() {
+	infra := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			APIServerInternalURL: "https://api-int.example.com:6443",
+		},
+	}
+
+	It("returns the internal API server URL of the Infrastructure when there is no override", func() {
+		Expect(GetAPIServerEndpoint(nil, infra)).To(Equal(APIServerEndpoint{Host: "api-int.example.com", Port: 6443}))
+	})
+
+	It("returns the API server URL of the Infrastructure when the control plane is external", func() {
+		externalInfra := infra.DeepCopy()
+		externalInfra.Status.APIServerURL = "https://api.hosted.example.com:443"
+		externalInfra.Status.ControlPlaneTopology = configv1.ExternalTopologyMode
+
+		Expect(GetAPIServerEndpoint(nil, externalInfra)).To(Equal(APIServerEndpoint{Host: "api.hosted.example.com", Port: 443}))
+	})
+
+	DescribeTable("returns the override when it is well formed",
+		func(override string, expected APIServerEndpoint) {
+			Expect(GetAPIServerEndpoint(map[string]string{APIServerEndpointOverrideAnnotation: override}, infra)).To(Equal(expected))
+		},
+		Entry("with a DNS name", "api.private.example.com:443", APIServerEndpoint{Host: "api.private.example.com", Port: 443}),
+		Entry("with an IPv4 address", "10.0.0.10:6443", APIServerEndpoint{Host: "10.0.0.10", Port: 6443}),
+		Entry("with an IPv6 address", "[fd00::10]:6443", APIServerEndpoint{Host: "fd00::10", Port: 6443}),
+	)
+
+	DescribeTable("reports a malformed override",
+		func(override string) {
+			_, err := GetAPIServerEndpoint(map[string]string{APIServerEndpointOverrideAnnotation: override}, infra)
+			Expect(err).To(MatchError(ErrInvalidAPIServerEndpointOverride))
+		},
+		Entry("without a port", "api.private.example.com"),
+		Entry("with a URL", "https://api.private.example.com:6443"),
+		Entry("with an invalid host", "api_private:6443"),
+		Entry("with a port out of range", "api.private.example.com:65536"),
+		Entry("with a zero port", "api.private.example.com:0"),
+		Entry("when empty", ""),
+	)
+})
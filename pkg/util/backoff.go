@@ -0,0 +1,141 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package util
+
+import (
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/openshift/cluster-capi-operator/pkg/errorsx"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// DefaultBackoffConfig is the backoff used by the sync controllers when none is configured.
+//
+//nolint:gochecknoglobals
+var DefaultBackoffConfig = BackoffConfig{
+	BaseDelay:   time.Second,
+	MaxDelay:    5 * time.Minute,
+	Jitter:      0.1,
+	RetryBudget: 15,
+}
+
+// BackoffConfig configures the delays between the retries of a resource, and how many times it is retried.
+type BackoffConfig struct {
+	// BaseDelay is the delay before the first retry, it doubles on each subsequent retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between two retries.
+	MaxDelay time.Duration
+	// Jitter is the maximum fraction of the delay randomly added to it,
+	// so that the resources failing at the same time are not all retried at once.
+	Jitter float64
+	// RetryBudget is the number of consecutive retries of a resource after which it is no longer requeued.
+	// The retries of transient errors are not counted.
+	// The resources are retried forever when it is 0.
+	RetryBudget int
+}
+
+// Backoff delays the retries of the resources a sync controller failed to reconcile, and keeps track of their
+// consecutive failures against the retry budget. Resources are identified by their name.
+// It is safe for concurrent use.
+type Backoff struct {
+	config BackoffConfig
+
+	mu       sync.Mutex
+	failures map[string]int
+	// spent counts the consecutive failures of a resource which consumed its retry budget.
+	spent map[string]int
+}
+
+// NewBackoff returns a Backoff with the given configuration.
+func NewBackoff(config BackoffConfig) *Backoff {
+	return &Backoff{
+		config:   config,
+		failures: map[string]int{},
+		spent:    map[string]int{},
+	}
+}
+
+// Result returns the result a reconciliation should end with, given its outcome.
+// Failed reconciliations are requeued after the backoff delay without returning the error, so that the delay is
+// controlled by the Backoff rather than by the controller rate limiter. The caller is responsible for logging the error.
+// exhausted is true when the failure exhausted the retry budget of the resource, in which case it is not requeued
+// and is only reconciled again when it changes. Terminal errors, which retrying does not resolve, exhaust it at once,
+// while transient errors and missing counterparts, which are expected to resolve, are retried without consuming it.
+func (b *Backoff) Result(name string, result ctrl.Result, err error) (ctrl.Result, bool) {
+	if err == nil {
+		b.Forget(name)
+		return result, false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures[name]++
+	failures := b.failures[name]
+
+	switch {
+	case errorsx.IsTerminal(err):
+		return ctrl.Result{}, true
+	case errorsx.IsTransient(err) || errors.Is(err, errorsx.ErrNotFoundCounterpart):
+		return ctrl.Result{RequeueAfter: b.delay(failures)}, false
+	}
+
+	b.spent[name]++
+
+	if b.config.RetryBudget > 0 && b.spent[name] > b.config.RetryBudget {
+		return ctrl.Result{}, true
+	}
+
+	return ctrl.Result{RequeueAfter: b.delay(failures)}, false
+}
+
+// Failures returns the number of consecutive failures recorded for the resource.
+func (b *Backoff) Failures(name string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.failures[name]
+}
+
+// Forget resets the failures of the resource, it must be called once the resource is reconciled successfully or is gone.
+func (b *Backoff) Forget(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.failures, name)
+	delete(b.spent, name)
+}
+
+// delay returns the jittered delay before the given retry, starting from 1.
+func (b *Backoff) delay(retry int) time.Duration {
+	delay := b.config.BaseDelay
+	for i := 1; i < retry && delay < b.config.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	if b.config.MaxDelay > 0 && delay > b.config.MaxDelay {
+		delay = b.config.MaxDelay
+	}
+
+	if b.config.Jitter > 0 {
+		delay += time.Duration(rand.Float64() * b.config.Jitter * float64(delay)) //nolint:gosec
+	}
+
+	return delay
+}
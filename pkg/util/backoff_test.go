@@ -0,0 +1,115 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package util
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/cluster-capi-operator/pkg/errorsx"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var errReconcile = errors.New("reconcile failed")
+
+var _ = Describe("Backoff", func() {
+	It("should double the delay on each failure up to the max delay", func() {
+		backoff := NewBackoff(BackoffConfig{BaseDelay: time.Second, MaxDelay: 5 * time.Second})
+
+		delays := []time.Duration{}
+
+		for range 5 {
+			result, exhausted := backoff.Result("foo", ctrl.Result{}, errReconcile)
+			Expect(exhausted).To(BeFalse())
+
+			delays = append(delays, result.RequeueAfter)
+		}
+
+		Expect(delays).To(Equal([]time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}))
+	})
+
+	It("should add at most the jitter fraction of the delay", func() {
+		backoff := NewBackoff(BackoffConfig{BaseDelay: time.Second, MaxDelay: time.Minute, Jitter: 0.5})
+
+		result, _ := backoff.Result("foo", ctrl.Result{}, errReconcile)
+		Expect(result.RequeueAfter).To(BeNumerically(">=", time.Second))
+		Expect(result.RequeueAfter).To(BeNumerically("<=", 1500*time.Millisecond))
+	})
+
+	It("should stop requeuing once the retry budget is exhausted", func() {
+		backoff := NewBackoff(BackoffConfig{BaseDelay: time.Second, MaxDelay: time.Minute, RetryBudget: 2})
+
+		for range 2 {
+			_, exhausted := backoff.Result("foo", ctrl.Result{}, errReconcile)
+			Expect(exhausted).To(BeFalse())
+		}
+
+		result, exhausted := backoff.Result("foo", ctrl.Result{}, errReconcile)
+		Expect(exhausted).To(BeTrue())
+		Expect(result).To(Equal(ctrl.Result{}))
+
+		By("Keeping the failures of the other resources separate")
+		_, exhausted = backoff.Result("bar", ctrl.Result{}, errReconcile)
+		Expect(exhausted).To(BeFalse())
+	})
+
+	It("should stop requeuing at once on a terminal error", func() {
+		backoff := NewBackoff(BackoffConfig{BaseDelay: time.Second, MaxDelay: time.Minute, RetryBudget: 2})
+
+		result, exhausted := backoff.Result("foo", ctrl.Result{}, errorsx.UnsupportedField(errReconcile))
+		Expect(exhausted).To(BeTrue())
+		Expect(result).To(Equal(ctrl.Result{}))
+
+		By("Still retrying the transient errors")
+		_, exhausted = backoff.Result("bar", ctrl.Result{}, errorsx.TransientAPI(errReconcile))
+		Expect(exhausted).To(BeFalse())
+	})
+
+	It("should retry the transient errors and the missing counterparts without consuming the retry budget", func() {
+		backoff := NewBackoff(BackoffConfig{BaseDelay: time.Second, MaxDelay: 5 * time.Second, RetryBudget: 1})
+
+		for _, err := range []error{errorsx.TransientAPI(errReconcile), errorsx.NotFoundCounterpart(errReconcile), errorsx.TransientAPI(errReconcile)} {
+			result, exhausted := backoff.Result("foo", ctrl.Result{}, err)
+			Expect(exhausted).To(BeFalse())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+		}
+
+		Expect(backoff.Failures("foo")).To(Equal(3), "the transient failures should still grow the delay")
+
+		_, exhausted := backoff.Result("foo", ctrl.Result{}, errReconcile)
+		Expect(exhausted).To(BeFalse(), "the first failure consuming the budget should be retried")
+
+		_, exhausted = backoff.Result("foo", ctrl.Result{}, errReconcile)
+		Expect(exhausted).To(BeTrue())
+	})
+
+	It("should reset the failures once the resource is reconciled successfully", func() {
+		backoff := NewBackoff(BackoffConfig{BaseDelay: time.Second, MaxDelay: time.Minute, RetryBudget: 1})
+
+		_, _ = backoff.Result("foo", ctrl.Result{}, errReconcile)
+
+		result, exhausted := backoff.Result("foo", ctrl.Result{RequeueAfter: time.Hour}, nil)
+		Expect(exhausted).To(BeFalse())
+		Expect(result).To(Equal(ctrl.Result{RequeueAfter: time.Hour}), "the result of a successful reconciliation should be kept")
+		Expect(backoff.Failures("foo")).To(BeZero())
+
+		result, exhausted = backoff.Result("foo", ctrl.Result{}, errReconcile)
+		Expect(exhausted).To(BeFalse())
+		Expect(result.RequeueAfter).To(Equal(time.Second))
+	})
+})
@@ -0,0 +1,37 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package util
+
+import (
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// IsExternalControlPlaneTopology returns whether the control plane of the cluster runs outside of it, as on HyperShift
+// hosted clusters. Such clusters have no control plane Machines, nor Machine API, and their API server is only
+// reached through its external URL.
+func IsExternalControlPlaneTopology(infra *configv1.Infrastructure) bool {
+	return infra != nil && infra.Status.ControlPlaneTopology == configv1.ExternalTopologyMode
+}
+
+// GetAPIServerURL returns the URL the cluster components reach the API server at: the internal API server URL of the
+// Infrastructure, or its API server URL when the control plane is external.
+func GetAPIServerURL(infra *configv1.Infrastructure) string {
+	if IsExternalControlPlaneTopology(infra) {
+		return infra.Status.APIServerURL
+	}
+
+	return infra.Status.APIServerInternalURL
+}
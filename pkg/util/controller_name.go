@@ -0,0 +1,31 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package util
+
+import (
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+)
+
+// NamespacedControllerName returns the name of a controller reconciling the given MAPI namespace.
+// Controllers of the default namespace keep their name, those of additional namespace pairs are suffixed
+// with their namespace so that their metrics and logs can be told apart.
+func NamespacedControllerName(name, mapiNamespace string) string {
+	if mapiNamespace == "" || mapiNamespace == consts.DefaultMAPIManagedNamespace {
+		return name
+	}
+
+	return name + "-" + mapiNamespace
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package util
+
+import (
+	"reflect"
+	"strings"
+)
+
+// ChangedFields returns the paths of the top level fields that differ between a and b,
+// using the json names of the fields prefixed with the given path.
+// a and b must be structs, or pointers to structs, of the same type.
+func ChangedFields(path string, a, b interface{}) []string {
+	changed := []string{}
+
+	va := reflect.Indirect(reflect.ValueOf(a))
+	vb := reflect.Indirect(reflect.ValueOf(b))
+
+	if va.Kind() != reflect.Struct || va.Type() != vb.Type() {
+		if !reflect.DeepEqual(a, b) {
+			changed = append(changed, path)
+		}
+
+		return changed
+	}
+
+	for i := range va.NumField() {
+		field := va.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			continue
+		}
+
+		changed = append(changed, path+"."+jsonFieldName(field))
+	}
+
+	return changed
+}
+
+// jsonFieldName returns the name of the field when serialised to json.
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+
+	return name
+}
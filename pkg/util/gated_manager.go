@@ -0,0 +1,104 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package util
+
+import (
+	"context"
+	"errors"
+
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/config"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// ErrControllerStopped is returned by GatedManager.Run when a runnable stops while the others are still running.
+var ErrControllerStopped = errors.New("controller stopped unexpectedly")
+
+// GatedManager captures the runnables added by a controller setup, so that a gate controls their lifecycle
+// rather than the manager. The controllers can be set up again with a new GatedManager once stopped.
+type GatedManager struct {
+	manager.Manager
+
+	runnables []manager.Runnable
+}
+
+// NewGatedManager returns a GatedManager capturing the runnables added to mgr.
+func NewGatedManager(mgr manager.Manager) *GatedManager {
+	return &GatedManager{Manager: mgr}
+}
+
+// Add captures the runnable instead of adding it to the manager.
+func (m *GatedManager) Add(r manager.Runnable) error {
+	m.runnables = append(m.runnables, r)
+
+	return nil
+}
+
+// GetControllerOptions allows the gated controllers to be set up again under the same name.
+func (m *GatedManager) GetControllerOptions() config.Controller {
+	opts := m.Manager.GetControllerOptions()
+	opts.SkipNameValidation = ptr.To(true)
+
+	return opts
+}
+
+// Run runs the captured runnables until the context is cancelled or one of them stops.
+func (m *GatedManager) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make(chan error, len(m.runnables))
+
+	for _, r := range m.runnables {
+		go func(r manager.Runnable) {
+			errs <- r.Start(ctx)
+		}(r)
+	}
+
+	var err error
+
+	for range m.runnables {
+		if runErr := <-errs; runErr != nil && err == nil {
+			err = runErr
+		} else if runErr == nil && err == nil && ctx.Err() == nil {
+			err = ErrControllerStopped
+		}
+
+		// Stop the other runnables as soon as one of them stops.
+		cancel()
+	}
+
+	return err
+}
+
+// StartGatedControllers sets the gated controllers up with a new GatedManager and starts them.
+// They stop when the returned function is called, and the result of their run is sent on the returned channel.
+func StartGatedControllers(ctx context.Context, mgr manager.Manager, setup func(manager.Manager) error) (context.CancelFunc, <-chan error, error) {
+	gatedMgr := NewGatedManager(mgr)
+
+	if err := setup(gatedMgr); err != nil {
+		return nil, nil, err
+	}
+
+	ctrlCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- gatedMgr.Run(ctrlCtx)
+	}()
+
+	return cancel, done, nil
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package util
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
+)
+
+var (
+	errEmptyImageReference   = errors.New("image reference is empty")
+	errInvalidImageReference = errors.New("invalid image reference")
+
+	// imageReferenceRegexp loosely matches a [host[:port]/]path[:tag][@sha256:digest] image reference.
+	imageReferenceRegexp = regexp.MustCompile(
+		`^[a-zA-Z0-9]+(?:[._-][a-zA-Z0-9]+)*(?::[0-9]+)?(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*(?::\w[\w.-]{0,127})?(?:@sha256:[a-f0-9]{64})?$`,
+	)
+)
+
+// ImageMirrorConfig holds the cluster wide image mirroring configuration.
+type ImageMirrorConfig struct {
+	ImageDigestMirrorSets      []configv1.ImageDigestMirrorSet
+	ImageTagMirrorSets         []configv1.ImageTagMirrorSet
+	ImageContentSourcePolicies []operatorv1alpha1.ImageContentSourcePolicy
+}
+
+// ValidateImageReference checks the image reference is well formed.
+func ValidateImageReference(image string) error {
+	if image == "" {
+		return errEmptyImageReference
+	}
+
+	if !imageReferenceRegexp.MatchString(image) {
+		return fmt.Errorf("%w: %q", errInvalidImageReference, image)
+	}
+
+	return nil
+}
+
+// ImageMirrors returns the mirrored image references the container runtime may pull the image from, in priority order.
+// Digest references are mirrored by ImageDigestMirrorSets and ImageContentSourcePolicies,
+// tag references are only mirrored by ImageTagMirrorSets.
+func ImageMirrors(image string, config ImageMirrorConfig) []string {
+	repository, digest, _ := strings.Cut(image, "@")
+
+	suffix := "@" + digest
+	if digest == "" {
+		// Only strip the tag when it is after the last path component, so a registry port is not mistaken for a tag.
+		if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+			repository, suffix = repository[:i], repository[i:]
+		} else {
+			suffix = ""
+		}
+	}
+
+	mirrors := []string{}
+	addMirrors := func(source string, sourceMirrors []string) {
+		remainder, ok := matchImageSource(repository, source)
+		if !ok {
+			return
+		}
+
+		for _, mirror := range sourceMirrors {
+			if ref := mirror + remainder + suffix; !slices.Contains(mirrors, ref) {
+				mirrors = append(mirrors, ref)
+			}
+		}
+	}
+
+	if digest != "" {
+		for _, idms := range config.ImageDigestMirrorSets {
+			for _, m := range idms.Spec.ImageDigestMirrors {
+				addMirrors(m.Source, imageMirrorsToStrings(m.Mirrors))
+			}
+		}
+
+		for _, icsp := range config.ImageContentSourcePolicies {
+			for _, m := range icsp.Spec.RepositoryDigestMirrors {
+				addMirrors(m.Source, m.Mirrors)
+			}
+		}
+
+		return mirrors
+	}
+
+	for _, itms := range config.ImageTagMirrorSets {
+		for _, m := range itms.Spec.ImageTagMirrors {
+			addMirrors(m.Source, imageMirrorsToStrings(m.Mirrors))
+		}
+	}
+
+	return mirrors
+}
+
+// matchImageSource returns the part of the repository following the mirror source, if the source matches the repository.
+// Sources are either a repository prefix, or a wildcard host such as *.redhat.io which replaces the whole host.
+func matchImageSource(repository, source string) (string, bool) {
+	if strings.HasPrefix(source, "*.") {
+		host, _, _ := strings.Cut(repository, "/")
+		if !strings.HasSuffix(host, source[1:]) {
+			return "", false
+		}
+
+		return repository[len(host):], true
+	}
+
+	if repository == source || strings.HasPrefix(repository, source+"/") {
+		return repository[len(source):], true
+	}
+
+	return "", false
+}
+
+// imageMirrorsToStrings converts a list of ImageMirror to a list of strings.
+func imageMirrorsToStrings(mirrors []configv1.ImageMirror) []string {
+	out := make([]string, 0, len(mirrors))
+	for _, m := range mirrors {
+		out = append(out, string(m))
+	}
+
+	return out
+}
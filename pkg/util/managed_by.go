@@ -0,0 +1,39 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package util
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	consts "github.com/openshift/cluster-capi-operator/pkg/controllers"
+)
+
+// SetManagedByLabel labels the object as managed by the operator, so that the resources of the operator can be
+// listed together, such as by the must-gather collection script.
+func SetManagedByLabel(obj client.Object) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	labels[consts.ManagedByLabel] = consts.ManagedByLabelValue
+	obj.SetLabels(labels)
+}
+
+// HasManagedByLabel returns whether the object is labeled as managed by the operator.
+func HasManagedByLabel(obj client.Object) bool {
+	return obj.GetLabels()[consts.ManagedByLabel] == consts.ManagedByLabelValue
+}
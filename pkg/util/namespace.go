@@ -0,0 +1,46 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package util
+
+import (
+	"os"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// serviceAccountNamespaceFile holds the namespace of the pod, it is mounted along with the service account token.
+const serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace" //nolint:gosec
+
+// DiscoverOperatorNamespace returns the namespace the operator runs in, so that it manages the namespace it is
+// deployed to. The fallback namespace is returned when not running in a pod, for instance in local development.
+func DiscoverOperatorNamespace(fallback string) string {
+	return discoverNamespace(serviceAccountNamespaceFile, fallback)
+}
+
+func discoverNamespace(namespaceFile, fallback string) string {
+	content, err := os.ReadFile(namespaceFile)
+	if err != nil {
+		klog.V(2).Infof("Unable to discover the operator namespace, defaulting to %s: %v", fallback, err)
+		return fallback
+	}
+
+	if namespace := strings.TrimSpace(string(content)); namespace != "" {
+		return namespace
+	}
+
+	return fallback
+}
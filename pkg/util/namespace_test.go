@@ -0,0 +1,48 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package util
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("discoverNamespace", func() {
+	var namespaceFile string
+
+	BeforeEach(func() {
+		namespaceFile = filepath.Join(GinkgoT().TempDir(), "namespace")
+	})
+
+	It("should return the namespace of the service account", func() {
+		Expect(os.WriteFile(namespaceFile, []byte("custom-cluster-api\n"), 0o600)).To(Succeed())
+
+		Expect(discoverNamespace(namespaceFile, "openshift-cluster-api")).To(Equal("custom-cluster-api"))
+	})
+
+	It("should fall back when not running in a pod", func() {
+		Expect(discoverNamespace(namespaceFile, "openshift-cluster-api")).To(Equal("openshift-cluster-api"))
+	})
+
+	It("should fall back when the namespace is empty", func() {
+		Expect(os.WriteFile(namespaceFile, nil, 0o600)).To(Succeed())
+
+		Expect(discoverNamespace(namespaceFile, "openshift-cluster-api")).To(Equal("openshift-cluster-api"))
+	})
+})
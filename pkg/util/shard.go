@@ -0,0 +1,75 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package util
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+)
+
+var errInvalidShard = errors.New("invalid shard")
+
+// Shard identifies the subset of the resources a replica of the sync controllers is responsible for.
+// Resources are assigned to a shard by hashing their name, MAPI and CAPI mirrors share the same name
+// so they are always handled by the same shard.
+// The zero value disables sharding.
+type Shard struct {
+	// Index is the shard owned by this replica, in the range [0, Count).
+	Index int
+	// Count is the total number of shards. Sharding is disabled when Count is lower than 2.
+	Count int
+}
+
+// Enabled returns true when the resources are split across more than one shard.
+func (s Shard) Enabled() bool {
+	return s.Count > 1
+}
+
+// Validate checks the shard index is within the shard count.
+func (s Shard) Validate() error {
+	if s.Count < 0 {
+		return fmt.Errorf("%w: shard count must not be negative, got %d", errInvalidShard, s.Count)
+	}
+
+	if s.Enabled() && (s.Index < 0 || s.Index >= s.Count) {
+		return fmt.Errorf("%w: shard index must be in the range [0, %d), got %d", errInvalidShard, s.Count, s.Index)
+	}
+
+	return nil
+}
+
+// Owns returns true if the resource with the given name belongs to the shard.
+func (s Shard) Owns(name string) bool {
+	if !s.Enabled() {
+		return true
+	}
+
+	h := fnv.New32a()
+	// Writing to a hash never returns an error.
+	_, _ = h.Write([]byte(name))
+
+	return h.Sum32()%uint32(s.Count) == uint32(s.Index) //nolint:gosec
+}
+
+// String returns a suffix identifying the shard, used to make per shard resource names such as leader election leases.
+func (s Shard) String() string {
+	if !s.Enabled() {
+		return ""
+	}
+
+	return fmt.Sprintf("shard-%d-of-%d", s.Index, s.Count)
+}
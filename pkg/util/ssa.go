@@ -17,9 +17,15 @@ limitations under the License.
 package util
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/csaupgrade"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -44,3 +50,28 @@ func ApplyConfigPatch(applyConfig interface{}) client.Patch {
 		applyConfig: applyConfig,
 	}
 }
+
+// UpgradeManagedFields hands the fields of an existing object managed by the updates of a legacy field manager over to
+// the field owner of its server-side applies, before it is applied for the first time. Otherwise the fields dropped
+// from the applied configuration would be kept by the legacy field manager rather than pruned by the apply.
+// The object is read as unstructured, bypassing the cache which strips the managed fields, and is left untouched when
+// it does not exist or has nothing to hand over.
+func UpgradeManagedFields(ctx context.Context, cl client.Client, obj client.Object, legacyFieldManager, fieldOwner string) error {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GetObjectKind().GroupVersionKind())
+
+	if err := cl.Get(ctx, client.ObjectKeyFromObject(obj), existing); apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(existing, sets.New(legacyFieldManager), fieldOwner)
+	if err != nil {
+		return fmt.Errorf("failed to upgrade managed fields of %s: %w", client.ObjectKeyFromObject(obj), err)
+	} else if patch == nil {
+		return nil
+	}
+
+	return cl.Patch(ctx, existing, client.RawPatch(types.JSONPatchType, patch)) //nolint:wrapcheck
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// SyncPausedAnnotation is set to true on the MAPI namespace to pause the synchronization of the MAPI resources with
+// their CAPI counterparts, without scaling down the controllers. It is meant for emergencies, when the synchronization
+// misbehaves. The resources are synchronized again once the annotation is removed or set to false.
+const SyncPausedAnnotation = "sync.machine.openshift.io/paused"
+
+// IsSyncPaused returns whether the synchronization of the resources of the given namespace is paused.
+func IsSyncPaused(ctx context.Context, cl client.Reader, namespace string) (bool, error) {
+	ns := &corev1.Namespace{}
+	if err := cl.Get(ctx, client.ObjectKey{Name: namespace}, ns); apierrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to get namespace %s: %w", namespace, err)
+	}
+
+	return hasSyncPausedAnnotation(ns), nil
+}
+
+// FilterSyncPauseChanges filters the events of the given namespace down to the ones pausing or resuming the
+// synchronization, so that the resources of the namespace can be requeued when it is resumed.
+func FilterSyncPauseChanges(namespace string) predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectNew.GetName() == namespace && hasSyncPausedAnnotation(e.ObjectOld) != hasSyncPausedAnnotation(e.ObjectNew)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// hasSyncPausedAnnotation returns whether the SyncPausedAnnotation of the object is set to true.
+// Malformed values do not pause the synchronization.
+func hasSyncPausedAnnotation(obj client.Object) bool {
+	paused, err := strconv.ParseBool(obj.GetAnnotations()[SyncPausedAnnotation])
+
+	return err == nil && paused
+}
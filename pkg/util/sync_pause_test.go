@@ -0,0 +1,79 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package util
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+const testSyncNamespace = "openshift-machine-api"
+
+func newSyncNamespace(paused string) *corev1.Namespace {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: testSyncNamespace}}
+	if paused != "" {
+		ns.Annotations = map[string]string{SyncPausedAnnotation: paused}
+	}
+
+	return ns
+}
+
+var _ = Describe("IsSyncPaused", func() {
+	ctx := context.Background()
+
+	DescribeTable("reports whether the synchronization is paused",
+		func(paused string, expected bool) {
+			cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(newSyncNamespace(paused)).Build()
+			Expect(IsSyncPaused(ctx, cl, testSyncNamespace)).To(Equal(expected))
+		},
+		Entry("when the annotation is not set", "", false),
+		Entry("when the annotation is true", "true", true),
+		Entry("when the annotation is false", "false", false),
+		Entry("when the annotation is malformed", "yes please", false),
+	)
+
+	It("does not pause the synchronization when the namespace does not exist", func() {
+		cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		Expect(IsSyncPaused(ctx, cl, testSyncNamespace)).To(BeFalse())
+	})
+})
+
+var _ = Describe("FilterSyncPauseChanges", func() {
+	filter := FilterSyncPauseChanges(testSyncNamespace)
+
+	It("accepts updates pausing or resuming the synchronization", func() {
+		Expect(filter.Update(event.UpdateEvent{ObjectOld: newSyncNamespace(""), ObjectNew: newSyncNamespace("true")})).To(BeTrue())
+		Expect(filter.Update(event.UpdateEvent{ObjectOld: newSyncNamespace("true"), ObjectNew: newSyncNamespace("false")})).To(BeTrue())
+	})
+
+	It("rejects updates leaving the synchronization as it was", func() {
+		Expect(filter.Update(event.UpdateEvent{ObjectOld: newSyncNamespace(""), ObjectNew: newSyncNamespace("false")})).To(BeFalse())
+	})
+
+	It("rejects updates of other namespaces", func() {
+		other := newSyncNamespace("true")
+		other.Name = "default"
+
+		Expect(filter.Update(event.UpdateEvent{ObjectOld: newSyncNamespace(""), ObjectNew: other})).To(BeFalse())
+	})
+})
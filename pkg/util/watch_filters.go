@@ -91,3 +91,11 @@ func FilterNamespace(namespace string) predicate.Predicate {
 		return obj.GetNamespace() == namespace
 	})
 }
+
+// FilterShard filters a client.Object request, ensuring the object belongs to the shard provided.
+// It must only be used for objects named after the resource being reconciled.
+func FilterShard(shard Shard) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return shard.Owns(obj.GetName())
+	})
+}
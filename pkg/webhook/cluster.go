@@ -32,6 +32,7 @@ import (
 
 const (
 	openshiftCAPINamespace = "openshift-cluster-api"
+	openshiftMAPINamespace = "openshift-machine-api"
 )
 
 var (
@@ -61,24 +62,35 @@ func (r *ClusterWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
 var _ webhook.CustomValidator = &ClusterWebhook{}
 
 // fetchInfrastructureObject fetches the Infrastructure object from the cluster.
-func (r *ClusterWebhook) fetchInfrastructureObject(ctx context.Context) (*configv1.Infrastructure, error) {
-	infrastructureObjectKey := client.ObjectKey{Name: "cluster", Namespace: "default"}
+func fetchInfrastructureObject(ctx context.Context, cl client.Client) (*configv1.Infrastructure, error) {
+	infrastructureObjectKey := client.ObjectKey{Name: "cluster"}
 
 	infrastructureObject := configv1.Infrastructure{}
-	if err := r.client.Get(ctx, infrastructureObjectKey, &infrastructureObject); err != nil {
+	if err := cl.Get(ctx, infrastructureObjectKey, &infrastructureObject); err != nil {
 		return nil, fmt.Errorf("failed to fetch Infrastructure object: %w", err)
 	}
 
 	return &infrastructureObject, nil
 }
 
+// getInfrastructureName returns the infrastructure name of the cluster, which is the name
+// of the Cluster object in the openshift-cluster-api namespace.
+func getInfrastructureName(ctx context.Context, cl client.Client) (string, error) {
+	infrastructureObject, err := fetchInfrastructureObject(ctx, cl)
+	if err != nil {
+		return "", err
+	}
+
+	return infrastructureObject.Status.InfrastructureName, nil
+}
+
 // In openshift-cluster-api allow only one Cluster object to be created. This Cluster manages the cluster we are running on.
 func (r *ClusterWebhook) validateClusterName(ctx context.Context, cluster *v1beta1.Cluster) error {
 	if cluster.Namespace != openshiftCAPINamespace {
 		return nil
 	}
 
-	infrastructureObject, err := r.fetchInfrastructureObject(ctx)
+	infrastructureObject, err := fetchInfrastructureObject(ctx, r.client)
 	if err != nil {
 		return fmt.Errorf("cluster in %s namespace must be named <infrastructure_id>. Failed to obtain name from Infrastructure object for validation: %w", openshiftCAPINamespace, err)
 	}
@@ -91,6 +103,17 @@ func (r *ClusterWebhook) validateClusterName(ctx context.Context, cluster *v1bet
 	return nil
 }
 
+// validateClusterTopology rejects the ClusterClass topology of the Cluster in openshift-cluster-api. This Cluster is
+// managed by the operator, it describes the cluster we are running on and is not created from a ClusterClass.
+func validateClusterTopology(cluster *v1beta1.Cluster) error {
+	if cluster.Namespace != openshiftCAPINamespace || cluster.Spec.Topology == nil {
+		return nil
+	}
+
+	return field.Forbidden(field.NewPath("spec", "topology"), fmt.Sprintf("ClusterClass topologies are not supported in %s namespace, "+
+		"the cluster is managed by the operator and cannot be created from the ClusterClass %q", openshiftCAPINamespace, cluster.Spec.Topology.Class))
+}
+
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
 func (r *ClusterWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
 	cluster, ok := obj.(*v1beta1.Cluster)
@@ -112,7 +135,7 @@ func (r *ClusterWebhook) ValidateCreate(ctx context.Context, obj runtime.Object)
 			cluster.Spec.InfrastructureRef.Kind, []string{"AWSCluster", "AzureCluster", "GCPCluster", "IBMPowerVSCluster", "OpenStackCluster", "VSphereCluster"}))
 	}
 
-	errs = append(errs, r.validateClusterName(ctx, cluster))
+	errs = append(errs, r.validateClusterName(ctx, cluster), validateClusterTopology(cluster))
 
 	if len(errs) > 0 {
 		return nil, utilerrors.NewAggregate(errs)
@@ -144,6 +167,10 @@ func (r *ClusterWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runt
 		return nil, field.NotSupported(field.NewPath("spec", "infrastructureRef", "kind"), newCluster.Spec.InfrastructureRef.Kind, []string{"AWSCluster", "AzureCluster", "GCPCluster", "IBMPowerVSCluster", "OpenStackCluster", "VSphereCluster"})
 	}
 
+	if err := validateClusterTopology(newCluster); err != nil {
+		return nil, err
+	}
+
 	return nil, nil
 }
 
@@ -0,0 +1,102 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package webhook
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func newTestCluster() *clusterv1.Cluster {
+	return &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testInfrastructureName,
+			Namespace: openshiftCAPINamespace,
+		},
+		Spec: clusterv1.ClusterSpec{
+			InfrastructureRef: &corev1.ObjectReference{
+				Kind: "AWSCluster",
+				Name: testInfrastructureName,
+			},
+		},
+	}
+}
+
+var _ = Describe("Cluster webhook", func() {
+	var wh *ClusterWebhook
+
+	ctx := context.Background()
+	topology := &clusterv1.Topology{Class: "quick-start", Version: "v1.30.0"}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(configv1.AddToScheme(scheme)).To(Succeed())
+
+		infra := &configv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Status:     configv1.InfrastructureStatus{InfrastructureName: testInfrastructureName},
+		}
+
+		wh = &ClusterWebhook{client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(infra).Build()}
+	})
+
+	It("should allow the managed Cluster without a topology", func() {
+		_, err := wh.ValidateCreate(ctx, newTestCluster())
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should reject the creation of the managed Cluster with a topology", func() {
+		cluster := newTestCluster()
+		cluster.Spec.Topology = topology
+
+		_, err := wh.ValidateCreate(ctx, cluster)
+		Expect(err).To(MatchError(ContainSubstring(`spec.topology: Forbidden: ClusterClass topologies are not supported in openshift-cluster-api namespace, ` +
+			`the cluster is managed by the operator and cannot be created from the ClusterClass "quick-start"`)))
+	})
+
+	It("should reject setting a topology on the managed Cluster", func() {
+		cluster := newTestCluster()
+		updated := cluster.DeepCopy()
+		updated.Spec.Topology = topology
+
+		_, err := wh.ValidateUpdate(ctx, cluster, updated)
+		Expect(err).To(MatchError(ContainSubstring("spec.topology: Forbidden")))
+	})
+
+	It("should allow removing the topology of the managed Cluster", func() {
+		cluster := newTestCluster()
+		cluster.Spec.Topology = topology
+
+		_, err := wh.ValidateUpdate(ctx, cluster, newTestCluster())
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should allow topologies outside of the openshift-cluster-api namespace", func() {
+		cluster := newTestCluster()
+		cluster.Namespace = "default"
+		cluster.Spec.Topology = topology
+
+		_, err := wh.ValidateCreate(ctx, cluster)
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
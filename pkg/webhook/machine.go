@@ -0,0 +1,209 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+)
+
+const (
+	// defaultUserDataSecretName is the name of the user data secret synced by the secret sync controller.
+	defaultUserDataSecretName = "worker-user-data"
+)
+
+var (
+	supportedInfraMachineKinds = []string{
+		"AWSMachine", "AzureMachine", "GCPMachine", "IBMPowerVSMachine", "OpenStackMachine", "VSphereMachine",
+	}
+)
+
+// MachineWebhook defaults and validates the Machine object.
+type MachineWebhook struct {
+	client client.Client
+}
+
+// SetupWebhookWithManager sets up the webhook with the manager.
+func (r *MachineWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	r.client = mgr.GetClient()
+
+	if err := ctrl.NewWebhookManagedBy(mgr).
+		WithDefaulter(r).
+		WithValidator(r).
+		For(&clusterv1.Machine{}).
+		Complete(); err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	return nil
+}
+
+var _ webhook.CustomDefaulter = &MachineWebhook{}
+var _ webhook.CustomValidator = &MachineWebhook{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the type.
+func (r *MachineWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	machine, ok := obj.(*clusterv1.Machine)
+	if !ok {
+		panic("expected to get an of object of type v1beta1.Machine")
+	}
+
+	if machine.Namespace != openshiftCAPINamespace {
+		return nil
+	}
+
+	infrastructureName, err := getInfrastructureName(ctx, r.client)
+	if err != nil {
+		return err
+	}
+
+	defaultMachineSpec(&machine.Spec, machine.Namespace, infrastructureName)
+
+	return nil
+}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
+func (r *MachineWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	machine, ok := obj.(*clusterv1.Machine)
+	if !ok {
+		panic("expected to get an of object of type v1beta1.Machine")
+	}
+
+	errs, err := r.validate(ctx, machine)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, errs.ToAggregate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
+// Only the fields changed by the update are validated, so that the Machines created before the webhook, or before one
+// of its rules, can still be updated, e.g. to be labelled or deleted.
+func (r *MachineWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldMachine, ok := oldObj.(*clusterv1.Machine)
+	if !ok {
+		panic("expected to get an of object of type v1beta1.Machine")
+	}
+
+	machine, ok := newObj.(*clusterv1.Machine)
+	if !ok {
+		panic("expected to get an of object of type v1beta1.Machine")
+	}
+
+	errs, err := r.validate(ctx, machine)
+	if err != nil {
+		return nil, err
+	}
+
+	oldErrs, err := r.validate(ctx, oldMachine)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, withoutUnchangedErrors(errs, oldErrs).ToAggregate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
+func (r *MachineWebhook) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate returns the fields of the Machine which are not supported on OpenShift.
+func (r *MachineWebhook) validate(ctx context.Context, machine *clusterv1.Machine) (field.ErrorList, error) {
+	if machine.Namespace != openshiftCAPINamespace {
+		return nil, nil
+	}
+
+	infrastructureName, err := getInfrastructureName(ctx, r.client)
+	if err != nil {
+		return nil, err
+	}
+
+	return validateMachineSpec(field.NewPath("spec"), &machine.Spec, machine.Namespace, infrastructureName, supportedInfraMachineKinds), nil
+}
+
+// withoutUnchangedErrors returns the errors of the updated object which the object already had before the update,
+// for the same field and value, left out. Those fields were not changed by the update, so they are not rejected.
+func withoutUnchangedErrors(errs, oldErrs field.ErrorList) field.ErrorList {
+	changed := field.ErrorList{}
+
+	for _, err := range errs {
+		if !slices.ContainsFunc(oldErrs, func(oldErr *field.Error) bool {
+			return oldErr.Type == err.Type && oldErr.Field == err.Field && reflect.DeepEqual(oldErr.BadValue, err.BadValue)
+		}) {
+			changed = append(changed, err)
+		}
+	}
+
+	return changed
+}
+
+// defaultMachineSpec sets the OpenShift defaults on a Machine spec, this is shared by Machines and MachineSet templates.
+func defaultMachineSpec(spec *clusterv1.MachineSpec, namespace, infrastructureName string) {
+	if spec.ClusterName == "" {
+		spec.ClusterName = infrastructureName
+	}
+
+	if spec.InfrastructureRef.Namespace == "" {
+		spec.InfrastructureRef.Namespace = namespace
+	}
+
+	if spec.Bootstrap.ConfigRef == nil && spec.Bootstrap.DataSecretName == nil {
+		spec.Bootstrap.DataSecretName = ptr.To(defaultUserDataSecretName)
+	}
+}
+
+// validateMachineSpec rejects Machine spec configurations that are not supported on OpenShift.
+func validateMachineSpec(fldPath *field.Path, spec *clusterv1.MachineSpec, namespace, infrastructureName string, supportedInfraKinds []string) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if spec.ClusterName != infrastructureName {
+		errs = append(errs, field.Invalid(fldPath.Child("clusterName"), spec.ClusterName,
+			fmt.Sprintf("clusterName must be %s in %s namespace", infrastructureName, openshiftCAPINamespace)))
+	}
+
+	if spec.Bootstrap.ConfigRef != nil {
+		errs = append(errs, field.Forbidden(fldPath.Child("bootstrap", "configRef"),
+			"bootstrap providers are not supported, use bootstrap.dataSecretName instead"))
+	}
+
+	if spec.Bootstrap.DataSecretName == nil || *spec.Bootstrap.DataSecretName == "" {
+		errs = append(errs, field.Required(fldPath.Child("bootstrap", "dataSecretName"), "dataSecretName is required"))
+	}
+
+	if spec.InfrastructureRef.Namespace != namespace {
+		errs = append(errs, field.Invalid(fldPath.Child("infrastructureRef", "namespace"), spec.InfrastructureRef.Namespace,
+			"infrastructureRef must be in the same namespace"))
+	}
+
+	if !util.ContainsString(supportedInfraKinds, spec.InfrastructureRef.Kind) {
+		errs = append(errs, field.NotSupported(fldPath.Child("infrastructureRef", "kind"), spec.InfrastructureRef.Kind, supportedInfraKinds))
+	}
+
+	return errs
+}
@@ -0,0 +1,155 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package webhook
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func newTestMachine() *clusterv1.Machine {
+	return &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-machine",
+			Namespace: openshiftCAPINamespace,
+		},
+		Spec: clusterv1.MachineSpec{
+			InfrastructureRef: corev1.ObjectReference{
+				Kind: "AWSMachine",
+				Name: "test-machine",
+			},
+		},
+	}
+}
+
+var _ = Describe("Machine webhook", func() {
+	var wh *MachineWebhook
+
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(configv1.AddToScheme(scheme)).To(Succeed())
+
+		infra := &configv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Status:     configv1.InfrastructureStatus{InfrastructureName: testInfrastructureName},
+		}
+
+		wh = &MachineWebhook{client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(infra).Build()}
+	})
+
+	It("should default the cluster name, infrastructureRef namespace and bootstrap data secret", func() {
+		machine := newTestMachine()
+
+		Expect(wh.Default(ctx, machine)).To(Succeed())
+
+		Expect(machine.Spec.ClusterName).To(Equal(testInfrastructureName))
+		Expect(machine.Spec.InfrastructureRef.Namespace).To(Equal(openshiftCAPINamespace))
+		Expect(machine.Spec.Bootstrap.DataSecretName).To(HaveValue(Equal(defaultUserDataSecretName)))
+
+		_, err := wh.ValidateCreate(ctx, machine)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should reject custom bootstrap providers on creation", func() {
+		machine := newTestMachine()
+		machine.Spec.Bootstrap.ConfigRef = &corev1.ObjectReference{Kind: "KubeadmConfig", Name: "kubeadm"}
+
+		Expect(wh.Default(ctx, machine)).To(Succeed())
+
+		_, err := wh.ValidateCreate(ctx, machine)
+		Expect(err).To(MatchError(ContainSubstring("spec.bootstrap.configRef")))
+	})
+
+	It("should ignore Machines outside of the openshift-cluster-api namespace", func() {
+		machine := newTestMachine()
+		machine.Namespace = "default"
+
+		Expect(wh.Default(ctx, machine)).To(Succeed())
+		Expect(machine.Spec.ClusterName).To(BeEmpty())
+
+		_, err := wh.ValidateCreate(ctx, machine)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Context("on update", func() {
+		var oldMachine *clusterv1.Machine
+
+		BeforeEach(func() {
+			oldMachine = newTestMachine()
+			Expect(wh.Default(ctx, oldMachine)).To(Succeed())
+		})
+
+		It("should allow updating a valid Machine", func() {
+			machine := oldMachine.DeepCopy()
+			machine.Labels = map[string]string{"foo": "bar"}
+
+			_, err := wh.ValidateUpdate(ctx, oldMachine, machine)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should allow updating the other fields of a Machine created with unsupported fields", func() {
+			// E.g. a Machine created before the webhook, with a bootstrap provider and for another cluster.
+			oldMachine.Spec.ClusterName = "other-cluster"
+			oldMachine.Spec.Bootstrap.ConfigRef = &corev1.ObjectReference{Kind: "KubeadmConfig", Name: "kubeadm"}
+
+			machine := oldMachine.DeepCopy()
+			machine.Labels = map[string]string{"foo": "bar"}
+			machine.Spec.InfrastructureRef.Name = "other-machine"
+
+			_, err := wh.ValidateUpdate(ctx, oldMachine, machine)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should reject the changed fields which are not supported", func() {
+			oldMachine.Spec.ClusterName = "other-cluster"
+
+			machine := oldMachine.DeepCopy()
+			machine.Spec.Bootstrap.ConfigRef = &corev1.ObjectReference{Kind: "KubeadmConfig", Name: "kubeadm"}
+
+			_, err := wh.ValidateUpdate(ctx, oldMachine, machine)
+			Expect(err).To(MatchError(ContainSubstring("spec.bootstrap.configRef")))
+			Expect(err).ToNot(MatchError(ContainSubstring("spec.clusterName")))
+		})
+
+		It("should reject changing an unsupported field to another unsupported value", func() {
+			oldMachine.Spec.ClusterName = "other-cluster"
+
+			machine := oldMachine.DeepCopy()
+			machine.Spec.ClusterName = "yet-another-cluster"
+
+			_, err := wh.ValidateUpdate(ctx, oldMachine, machine)
+			Expect(err).To(MatchError(ContainSubstring("spec.clusterName")))
+		})
+
+		It("should reject unsetting the bootstrap data secret", func() {
+			machine := oldMachine.DeepCopy()
+			machine.Spec.Bootstrap.DataSecretName = ptr.To("")
+
+			_, err := wh.ValidateUpdate(ctx, oldMachine, machine)
+			Expect(err).To(MatchError(ContainSubstring("spec.bootstrap.dataSecretName")))
+		})
+	})
+})
@@ -0,0 +1,134 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/bootstrapsecret"
+)
+
+// MachineDeploymentWebhook defaults and validates the MachineDeployment object.
+// The MachineSets spawned by a MachineDeployment are created from its template, so the template
+// is held to the same rules as MachineSet templates, rather than failing later when the MachineSets are created.
+type MachineDeploymentWebhook struct {
+	client client.Client
+}
+
+// SetupWebhookWithManager sets up the webhook with the manager.
+func (r *MachineDeploymentWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	r.client = mgr.GetClient()
+
+	if err := ctrl.NewWebhookManagedBy(mgr).
+		WithDefaulter(r).
+		WithValidator(r).
+		For(&clusterv1.MachineDeployment{}).
+		Complete(); err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	return nil
+}
+
+var _ webhook.CustomDefaulter = &MachineDeploymentWebhook{}
+var _ webhook.CustomValidator = &MachineDeploymentWebhook{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the type.
+func (r *MachineDeploymentWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	machineDeployment, ok := obj.(*clusterv1.MachineDeployment)
+	if !ok {
+		panic("expected to get an of object of type v1beta1.MachineDeployment")
+	}
+
+	if machineDeployment.Namespace != openshiftCAPINamespace {
+		return nil
+	}
+
+	infrastructureName, err := getInfrastructureName(ctx, r.client)
+	if err != nil {
+		return err
+	}
+
+	if machineDeployment.Spec.ClusterName == "" {
+		machineDeployment.Spec.ClusterName = infrastructureName
+	}
+
+	defaultMachineSpec(&machineDeployment.Spec.Template.Spec, machineDeployment.Namespace, machineDeployment.Spec.ClusterName)
+
+	return nil
+}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
+func (r *MachineDeploymentWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	machineDeployment, ok := obj.(*clusterv1.MachineDeployment)
+	if !ok {
+		panic("expected to get an of object of type v1beta1.MachineDeployment")
+	}
+
+	return nil, r.validate(ctx, machineDeployment)
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
+func (r *MachineDeploymentWebhook) ValidateUpdate(ctx context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	machineDeployment, ok := newObj.(*clusterv1.MachineDeployment)
+	if !ok {
+		panic("expected to get an of object of type v1beta1.MachineDeployment")
+	}
+
+	return nil, r.validate(ctx, machineDeployment)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
+func (r *MachineDeploymentWebhook) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (r *MachineDeploymentWebhook) validate(ctx context.Context, machineDeployment *clusterv1.MachineDeployment) error {
+	if machineDeployment.Namespace != openshiftCAPINamespace {
+		return nil
+	}
+
+	infrastructureName, err := getInfrastructureName(ctx, r.client)
+	if err != nil {
+		return err
+	}
+
+	errs := field.ErrorList{}
+
+	if machineDeployment.Spec.ClusterName != infrastructureName {
+		errs = append(errs, field.Invalid(field.NewPath("spec", "clusterName"), machineDeployment.Spec.ClusterName,
+			fmt.Sprintf("clusterName must be %s in %s namespace", infrastructureName, openshiftCAPINamespace)))
+	}
+
+	// The generated bootstrap data secrets are named after the MachineSets, and the names of the
+	// MachineSets spawned by a MachineDeployment are only known once they are created.
+	if _, ok := machineDeployment.Annotations[bootstrapsecret.MachineConfigPoolAnnotation]; ok {
+		errs = append(errs, field.Forbidden(field.NewPath("metadata", "annotations").Key(bootstrapsecret.MachineConfigPoolAnnotation),
+			"machine config pools are not supported on MachineDeployments, use a MachineSet instead"))
+	}
+
+	errs = append(errs, validateMachineSpec(field.NewPath("spec", "template", "spec"), &machineDeployment.Spec.Template.Spec,
+		machineDeployment.Namespace, infrastructureName, supportedInfraMachineTemplateKinds)...)
+
+	return errs.ToAggregate()
+}
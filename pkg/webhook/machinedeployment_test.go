@@ -0,0 +1,132 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package webhook
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/bootstrapsecret"
+)
+
+func newTestMachineDeployment() *clusterv1.MachineDeployment {
+	return &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-machinedeployment",
+			Namespace: openshiftCAPINamespace,
+		},
+		Spec: clusterv1.MachineDeploymentSpec{
+			Template: clusterv1.MachineTemplateSpec{
+				Spec: clusterv1.MachineSpec{
+					InfrastructureRef: corev1.ObjectReference{
+						Kind: "AWSMachineTemplate",
+						Name: "test-template",
+					},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("MachineDeployment webhook", func() {
+	var wh *MachineDeploymentWebhook
+
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(configv1.AddToScheme(scheme)).To(Succeed())
+
+		infra := &configv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Status:     configv1.InfrastructureStatus{InfrastructureName: testInfrastructureName},
+		}
+
+		wh = &MachineDeploymentWebhook{client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(infra).Build()}
+	})
+
+	It("should default the cluster name, infrastructureRef namespace and bootstrap data secret", func() {
+		machineDeployment := newTestMachineDeployment()
+
+		Expect(wh.Default(ctx, machineDeployment)).To(Succeed())
+
+		Expect(machineDeployment.Spec.ClusterName).To(Equal(testInfrastructureName))
+		Expect(machineDeployment.Spec.Template.Spec.ClusterName).To(Equal(testInfrastructureName))
+		Expect(machineDeployment.Spec.Template.Spec.InfrastructureRef.Namespace).To(Equal(openshiftCAPINamespace))
+		Expect(machineDeployment.Spec.Template.Spec.Bootstrap.DataSecretName).To(HaveValue(Equal(defaultUserDataSecretName)))
+
+		_, err := wh.ValidateCreate(ctx, machineDeployment)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should reject custom bootstrap providers", func() {
+		machineDeployment := newTestMachineDeployment()
+		machineDeployment.Spec.Template.Spec.Bootstrap.ConfigRef = &corev1.ObjectReference{Kind: "KubeadmConfigTemplate", Name: "kubeadm"}
+
+		Expect(wh.Default(ctx, machineDeployment)).To(Succeed())
+
+		_, err := wh.ValidateCreate(ctx, machineDeployment)
+		Expect(err).To(MatchError(ContainSubstring("spec.template.spec.bootstrap.configRef")))
+	})
+
+	It("should reject machine config pools", func() {
+		machineDeployment := newTestMachineDeployment()
+		machineDeployment.Annotations = map[string]string{bootstrapsecret.MachineConfigPoolAnnotation: "infra"}
+
+		Expect(wh.Default(ctx, machineDeployment)).To(Succeed())
+
+		_, err := wh.ValidateCreate(ctx, machineDeployment)
+		Expect(err).To(MatchError(ContainSubstring("metadata.annotations[cluster-api.openshift.io/machine-config-pool]")))
+	})
+
+	It("should reject an unexpected cluster name", func() {
+		machineDeployment := newTestMachineDeployment()
+		machineDeployment.Spec.ClusterName = "other-cluster"
+
+		Expect(wh.Default(ctx, machineDeployment)).To(Succeed())
+
+		_, err := wh.ValidateUpdate(ctx, nil, machineDeployment)
+		Expect(err).To(MatchError(ContainSubstring("spec.clusterName")))
+	})
+
+	It("should reject unsupported infrastructure template kinds", func() {
+		machineDeployment := newTestMachineDeployment()
+		machineDeployment.Spec.Template.Spec.InfrastructureRef.Kind = "DockerMachineTemplate"
+
+		Expect(wh.Default(ctx, machineDeployment)).To(Succeed())
+
+		_, err := wh.ValidateCreate(ctx, machineDeployment)
+		Expect(err).To(MatchError(ContainSubstring("spec.template.spec.infrastructureRef.kind")))
+	})
+
+	It("should ignore MachineDeployments outside of the openshift-cluster-api namespace", func() {
+		machineDeployment := newTestMachineDeployment()
+		machineDeployment.Namespace = "default"
+
+		Expect(wh.Default(ctx, machineDeployment)).To(Succeed())
+		Expect(machineDeployment.Spec.ClusterName).To(BeEmpty())
+
+		_, err := wh.ValidateCreate(ctx, machineDeployment)
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
@@ -0,0 +1,167 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/bootstrapsecret"
+)
+
+var (
+	supportedInfraMachineTemplateKinds = []string{
+		"AWSMachineTemplate", "AzureMachineTemplate", "GCPMachineTemplate", "IBMPowerVSMachineTemplate", "OpenStackMachineTemplate", "VSphereMachineTemplate",
+	}
+)
+
+// MachineSetWebhook defaults and validates the MachineSet object.
+type MachineSetWebhook struct {
+	client client.Client
+}
+
+// SetupWebhookWithManager sets up the webhook with the manager.
+func (r *MachineSetWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	r.client = mgr.GetClient()
+
+	if err := ctrl.NewWebhookManagedBy(mgr).
+		WithDefaulter(r).
+		WithValidator(r).
+		For(&clusterv1.MachineSet{}).
+		Complete(); err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	return nil
+}
+
+var _ webhook.CustomDefaulter = &MachineSetWebhook{}
+var _ webhook.CustomValidator = &MachineSetWebhook{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the type.
+func (r *MachineSetWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	machineSet, ok := obj.(*clusterv1.MachineSet)
+	if !ok {
+		panic("expected to get an of object of type v1beta1.MachineSet")
+	}
+
+	if machineSet.Namespace != openshiftCAPINamespace {
+		return nil
+	}
+
+	infrastructureName, err := getInfrastructureName(ctx, r.client)
+	if err != nil {
+		return err
+	}
+
+	if machineSet.Spec.ClusterName == "" {
+		machineSet.Spec.ClusterName = infrastructureName
+	}
+
+	// MachineSets requesting a generated bootstrap data secret use it rather than the worker user data.
+	bootstrap := &machineSet.Spec.Template.Spec.Bootstrap
+	if _, ok := machineSet.Annotations[bootstrapsecret.MachineConfigPoolAnnotation]; ok && machineSet.Name != "" &&
+		bootstrap.ConfigRef == nil && bootstrap.DataSecretName == nil {
+		bootstrap.DataSecretName = ptr.To(bootstrapsecret.UserDataSecretName(machineSet.Name))
+	}
+
+	defaultMachineSpec(&machineSet.Spec.Template.Spec, machineSet.Namespace, machineSet.Spec.ClusterName)
+
+	return nil
+}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
+func (r *MachineSetWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	machineSet, ok := obj.(*clusterv1.MachineSet)
+	if !ok {
+		panic("expected to get an of object of type v1beta1.MachineSet")
+	}
+
+	errs, err := r.validate(ctx, machineSet)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, errs.ToAggregate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
+// Only the fields changed by the update are validated, as for Machines.
+func (r *MachineSetWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldMachineSet, ok := oldObj.(*clusterv1.MachineSet)
+	if !ok {
+		panic("expected to get an of object of type v1beta1.MachineSet")
+	}
+
+	machineSet, ok := newObj.(*clusterv1.MachineSet)
+	if !ok {
+		panic("expected to get an of object of type v1beta1.MachineSet")
+	}
+
+	errs, err := r.validate(ctx, machineSet)
+	if err != nil {
+		return nil, err
+	}
+
+	oldErrs, err := r.validate(ctx, oldMachineSet)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, withoutUnchangedErrors(errs, oldErrs).ToAggregate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
+func (r *MachineSetWebhook) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate returns the fields of the MachineSet which are not supported on OpenShift.
+func (r *MachineSetWebhook) validate(ctx context.Context, machineSet *clusterv1.MachineSet) (field.ErrorList, error) {
+	if machineSet.Namespace != openshiftCAPINamespace {
+		return nil, nil
+	}
+
+	infrastructureName, err := getInfrastructureName(ctx, r.client)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := field.ErrorList{}
+
+	if machineSet.Spec.ClusterName != infrastructureName {
+		errs = append(errs, field.Invalid(field.NewPath("spec", "clusterName"), machineSet.Spec.ClusterName,
+			fmt.Sprintf("clusterName must be %s in %s namespace", infrastructureName, openshiftCAPINamespace)))
+	}
+
+	// The generated bootstrap data secret is named after the MachineSet, which is not known yet when using generateName.
+	if _, ok := machineSet.Annotations[bootstrapsecret.MachineConfigPoolAnnotation]; ok && machineSet.Name == "" {
+		errs = append(errs, field.Required(field.NewPath("metadata", "name"),
+			fmt.Sprintf("name is required when using the %s annotation", bootstrapsecret.MachineConfigPoolAnnotation)))
+	}
+
+	errs = append(errs, validateMachineSpec(field.NewPath("spec", "template", "spec"), &machineSet.Spec.Template.Spec,
+		machineSet.Namespace, infrastructureName, supportedInfraMachineTemplateKinds)...)
+
+	return errs, nil
+}
@@ -0,0 +1,174 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package webhook
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/bootstrapsecret"
+)
+
+const testInfrastructureName = "test-cluster-abcde"
+
+func newTestMachineSet() *clusterv1.MachineSet {
+	return &clusterv1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-machineset",
+			Namespace: openshiftCAPINamespace,
+		},
+		Spec: clusterv1.MachineSetSpec{
+			Template: clusterv1.MachineTemplateSpec{
+				Spec: clusterv1.MachineSpec{
+					InfrastructureRef: corev1.ObjectReference{
+						Kind: "AWSMachineTemplate",
+						Name: "test-template",
+					},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("MachineSet webhook", func() {
+	var wh *MachineSetWebhook
+
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(configv1.AddToScheme(scheme)).To(Succeed())
+
+		infra := &configv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Status:     configv1.InfrastructureStatus{InfrastructureName: testInfrastructureName},
+		}
+
+		wh = &MachineSetWebhook{client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(infra).Build()}
+	})
+
+	It("should default the cluster name, infrastructureRef namespace and bootstrap data secret", func() {
+		machineSet := newTestMachineSet()
+
+		Expect(wh.Default(ctx, machineSet)).To(Succeed())
+
+		Expect(machineSet.Spec.ClusterName).To(Equal(testInfrastructureName))
+		Expect(machineSet.Spec.Template.Spec.ClusterName).To(Equal(testInfrastructureName))
+		Expect(machineSet.Spec.Template.Spec.InfrastructureRef.Namespace).To(Equal(openshiftCAPINamespace))
+		Expect(machineSet.Spec.Template.Spec.Bootstrap.DataSecretName).To(HaveValue(Equal(defaultUserDataSecretName)))
+
+		_, err := wh.ValidateCreate(ctx, machineSet)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should not override values set by the user", func() {
+		machineSet := newTestMachineSet()
+		machineSet.Spec.Template.Spec.Bootstrap.DataSecretName = ptr.To("custom-user-data")
+
+		Expect(wh.Default(ctx, machineSet)).To(Succeed())
+		Expect(machineSet.Spec.Template.Spec.Bootstrap.DataSecretName).To(HaveValue(Equal("custom-user-data")))
+	})
+
+	It("should default the bootstrap data secret to the generated one when a machine config pool is requested", func() {
+		machineSet := newTestMachineSet()
+		machineSet.Annotations = map[string]string{bootstrapsecret.MachineConfigPoolAnnotation: "infra"}
+
+		Expect(wh.Default(ctx, machineSet)).To(Succeed())
+		Expect(machineSet.Spec.Template.Spec.Bootstrap.DataSecretName).To(HaveValue(Equal("test-machineset-user-data")))
+
+		_, err := wh.ValidateCreate(ctx, machineSet)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should reject a generated name when a machine config pool is requested", func() {
+		machineSet := newTestMachineSet()
+		machineSet.Name = ""
+		machineSet.GenerateName = "test-machineset-"
+		machineSet.Annotations = map[string]string{bootstrapsecret.MachineConfigPoolAnnotation: "infra"}
+
+		Expect(wh.Default(ctx, machineSet)).To(Succeed())
+
+		_, err := wh.ValidateCreate(ctx, machineSet)
+		Expect(err).To(MatchError(ContainSubstring("metadata.name")))
+	})
+
+	It("should reject custom bootstrap providers", func() {
+		machineSet := newTestMachineSet()
+		machineSet.Spec.Template.Spec.Bootstrap.ConfigRef = &corev1.ObjectReference{Kind: "KubeadmConfigTemplate", Name: "kubeadm"}
+
+		Expect(wh.Default(ctx, machineSet)).To(Succeed())
+
+		_, err := wh.ValidateCreate(ctx, machineSet)
+		Expect(err).To(MatchError(ContainSubstring("spec.template.spec.bootstrap.configRef")))
+	})
+
+	It("should reject an unexpected cluster name", func() {
+		machineSet := newTestMachineSet()
+		machineSet.Spec.ClusterName = "other-cluster"
+
+		Expect(wh.Default(ctx, machineSet)).To(Succeed())
+
+		_, err := wh.ValidateCreate(ctx, machineSet)
+		Expect(err).To(MatchError(ContainSubstring("spec.clusterName")))
+	})
+
+	It("should reject unsupported infrastructure template kinds", func() {
+		machineSet := newTestMachineSet()
+		machineSet.Spec.Template.Spec.InfrastructureRef.Kind = "DockerMachineTemplate"
+
+		Expect(wh.Default(ctx, machineSet)).To(Succeed())
+
+		_, err := wh.ValidateCreate(ctx, machineSet)
+		Expect(err).To(MatchError(ContainSubstring("spec.template.spec.infrastructureRef.kind")))
+	})
+
+	It("should only reject the fields changed by an update", func() {
+		oldMachineSet := newTestMachineSet()
+		oldMachineSet.Spec.Template.Spec.InfrastructureRef.Kind = "DockerMachineTemplate"
+		Expect(wh.Default(ctx, oldMachineSet)).To(Succeed())
+
+		machineSet := oldMachineSet.DeepCopy()
+		machineSet.Spec.Replicas = ptr.To[int32](3)
+
+		_, err := wh.ValidateUpdate(ctx, oldMachineSet, machineSet)
+		Expect(err).ToNot(HaveOccurred())
+
+		machineSet.Spec.ClusterName = "other-cluster"
+
+		_, err = wh.ValidateUpdate(ctx, oldMachineSet, machineSet)
+		Expect(err).To(MatchError(ContainSubstring("spec.clusterName")))
+		Expect(err).ToNot(MatchError(ContainSubstring("spec.template.spec.infrastructureRef.kind")))
+	})
+
+	It("should ignore MachineSets outside of the openshift-cluster-api namespace", func() {
+		machineSet := newTestMachineSet()
+		machineSet.Namespace = "default"
+
+		Expect(wh.Default(ctx, machineSet)).To(Succeed())
+		Expect(machineSet.Spec.ClusterName).To(BeEmpty())
+
+		_, err := wh.ValidateCreate(ctx, machineSet)
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
@@ -0,0 +1,185 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/api/features"
+	mapiv1beta1 "github.com/openshift/api/machine/v1beta1"
+
+	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers/operatorconfig"
+)
+
+const featureGateName = "cluster"
+
+// MAPIAuthorityWebhook defaults the authoritative API of new MAPI Machines and MachineSets according to the
+// migration.defaultAuthoritativeAPI of the ClusterAPIOperatorConfig.
+// The Machine API CRDs default the authoritative API to MachineAPI, which is indistinguishable from an explicit
+// MachineAPI, so the policy applies to every new resource. Machines controlled by a MachineSet follow its template instead.
+type MAPIAuthorityWebhook struct {
+	// OperatorNamespace is the namespace of the ClusterAPIOperatorConfig, defaults to the openshift-cluster-api namespace.
+	OperatorNamespace string
+
+	// MAPINamespace is the namespace of the Machine API resources the policy applies to, defaults to the
+	// openshift-machine-api namespace.
+	MAPINamespace string
+
+	// ReleaseVersion is the version of the release payload, whose MachineAPIMigration feature gate enables the
+	// ClusterAPI policy.
+	ReleaseVersion string
+
+	client client.Client
+}
+
+// SetupWebhookWithManager sets up the webhook with the manager.
+func (r *MAPIAuthorityWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	r.client = mgr.GetClient()
+
+	if r.OperatorNamespace == "" {
+		r.OperatorNamespace = controllers.DefaultManagedNamespace
+	}
+
+	if r.MAPINamespace == "" {
+		r.MAPINamespace = openshiftMAPINamespace
+	}
+
+	for _, obj := range []runtime.Object{&mapiv1beta1.MachineSet{}, &mapiv1beta1.Machine{}} {
+		if err := ctrl.NewWebhookManagedBy(mgr).
+			WithDefaulter(r).
+			For(obj).
+			Complete(); err != nil {
+			return fmt.Errorf("failed to create webhook: %w", err)
+		}
+	}
+
+	return nil
+}
+
+var _ webhook.CustomDefaulter = &MAPIAuthorityWebhook{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the type.
+func (r *MAPIAuthorityWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get admission request: %w", err)
+	}
+
+	// The authoritative API of existing resources is only changed by the migration.
+	if req.Operation != admissionv1.Create {
+		return nil
+	}
+
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return fmt.Errorf("failed to access object metadata: %w", err)
+	}
+
+	if accessor.GetNamespace() != r.MAPINamespace {
+		return nil
+	}
+
+	authority, err := r.getDefaultAuthoritativeAPI(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Explicit ClusterAPI authorities are kept whatever the policy, as they cannot come from the CRD default.
+	if authority != mapiv1beta1.MachineAuthorityClusterAPI {
+		return nil
+	}
+
+	switch o := obj.(type) {
+	case *mapiv1beta1.MachineSet:
+		o.Spec.AuthoritativeAPI = authority
+		o.Spec.Template.Spec.AuthoritativeAPI = authority
+	case *mapiv1beta1.Machine:
+		if metav1.GetControllerOf(o) != nil {
+			return nil
+		}
+
+		o.Spec.AuthoritativeAPI = authority
+	default:
+		panic(fmt.Sprintf("expected to get an object of type v1beta1.MachineSet or v1beta1.Machine, got %T", obj))
+	}
+
+	return nil
+}
+
+// getDefaultAuthoritativeAPI returns the authoritative API configured by the ClusterAPIOperatorConfig, or an empty
+// authority when it is not set. ClusterAPI is ignored while the MachineAPIMigration feature gate is disabled, as
+// nothing would synchronize the resources it is applied to.
+func (r *MAPIAuthorityWebhook) getDefaultAuthoritativeAPI(ctx context.Context) (mapiv1beta1.MachineAuthority, error) {
+	config, err := operatorconfig.Get(ctx, r.client, r.OperatorNamespace)
+	if err != nil {
+		return "", err //nolint:wrapcheck
+	}
+
+	authority := config.Migration.DefaultAuthoritativeAPI
+	if authority != mapiv1beta1.MachineAuthorityClusterAPI {
+		return authority, nil
+	}
+
+	enabled, err := r.isMachineAPIMigrationEnabled(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if !enabled {
+		ctrl.LoggerFrom(ctx).Info("Ignoring the default authoritative API while the feature gate is disabled",
+			"featureGate", features.FeatureGateMachineAPIMigration, "authority", authority)
+
+		return "", nil
+	}
+
+	return authority, nil
+}
+
+// isMachineAPIMigrationEnabled returns whether the MachineAPIMigration feature gate is enabled for the release
+// payload version. It is disabled when the FeatureGate is not available, or not rendered for the version yet.
+func (r *MAPIAuthorityWebhook) isMachineAPIMigrationEnabled(ctx context.Context) (bool, error) {
+	featureGate := &configv1.FeatureGate{}
+	if err := r.client.Get(ctx, client.ObjectKey{Name: featureGateName}, featureGate); apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to get FeatureGate: %w", err)
+	}
+
+	for _, details := range featureGate.Status.FeatureGates {
+		if details.Version != r.ReleaseVersion {
+			continue
+		}
+
+		for _, gate := range details.Enabled {
+			if gate.Name == features.FeatureGateMachineAPIMigration {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
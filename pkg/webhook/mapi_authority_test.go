@@ -0,0 +1,213 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package webhook
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/api/features"
+	mapiv1beta1 "github.com/openshift/api/machine/v1beta1"
+
+	configv1alpha1 "github.com/openshift/cluster-capi-operator/pkg/apis/config/v1alpha1"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+)
+
+const testReleaseVersion = "4.17.0"
+
+func newTestMAPIMachineSet() *mapiv1beta1.MachineSet {
+	return &mapiv1beta1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-machineset",
+			Namespace: openshiftMAPINamespace,
+		},
+		Spec: mapiv1beta1.MachineSetSpec{
+			AuthoritativeAPI: mapiv1beta1.MachineAuthorityMachineAPI,
+			Template: mapiv1beta1.MachineTemplateSpec{
+				Spec: mapiv1beta1.MachineSpec{
+					AuthoritativeAPI: mapiv1beta1.MachineAuthorityMachineAPI,
+				},
+			},
+		},
+	}
+}
+
+func newTestMAPIMachine() *mapiv1beta1.Machine {
+	return &mapiv1beta1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-machine",
+			Namespace: openshiftMAPINamespace,
+		},
+		Spec: mapiv1beta1.MachineSpec{
+			AuthoritativeAPI: mapiv1beta1.MachineAuthorityMachineAPI,
+		},
+	}
+}
+
+func newTestFeatureGate(version string, enabled ...configv1.FeatureGateName) *configv1.FeatureGate {
+	details := configv1.FeatureGateDetails{Version: version}
+	for _, name := range enabled {
+		details.Enabled = append(details.Enabled, configv1.FeatureGateAttributes{Name: name})
+	}
+
+	return &configv1.FeatureGate{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status:     configv1.FeatureGateStatus{FeatureGates: []configv1.FeatureGateDetails{details}},
+	}
+}
+
+func newAdmissionContext(operation admissionv1.Operation) context.Context {
+	return admission.NewContextWithRequest(context.Background(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{Operation: operation},
+	})
+}
+
+var _ = Describe("MAPI authority webhook", func() {
+	var wh *MAPIAuthorityWebhook
+
+	createCtx := newAdmissionContext(admissionv1.Create)
+
+	newScheme := func() *runtime.Scheme {
+		scheme := runtime.NewScheme()
+		Expect(configv1.AddToScheme(scheme)).To(Succeed())
+		Expect(configv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		return scheme
+	}
+
+	newWebhookWithFeatureGate := func(policy mapiv1beta1.MachineAuthority, featureGate *configv1.FeatureGate) *MAPIAuthorityWebhook {
+		config := &configv1alpha1.ClusterAPIOperatorConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: configv1alpha1.ClusterAPIOperatorConfigName, Namespace: controllers.DefaultManagedNamespace},
+			Spec: configv1alpha1.ClusterAPIOperatorConfigSpec{
+				Migration: configv1alpha1.MigrationPolicy{DefaultAuthoritativeAPI: policy},
+			},
+		}
+
+		builder := fake.NewClientBuilder().WithScheme(newScheme()).WithObjects(config)
+		if featureGate != nil {
+			builder = builder.WithObjects(featureGate)
+		}
+
+		return &MAPIAuthorityWebhook{
+			OperatorNamespace: controllers.DefaultManagedNamespace,
+			MAPINamespace:     openshiftMAPINamespace,
+			ReleaseVersion:    testReleaseVersion,
+			client:            builder.Build(),
+		}
+	}
+
+	Context("with a ClusterAPI policy", func() {
+		BeforeEach(func() {
+			wh = newWebhookWithFeatureGate(mapiv1beta1.MachineAuthorityClusterAPI,
+				newTestFeatureGate(testReleaseVersion, features.FeatureGateMachineAPIMigration))
+		})
+
+		It("should default the authoritative API of new MachineSets and their template", func() {
+			machineSet := newTestMAPIMachineSet()
+
+			Expect(wh.Default(createCtx, machineSet)).To(Succeed())
+			Expect(machineSet.Spec.AuthoritativeAPI).To(Equal(mapiv1beta1.MachineAuthorityClusterAPI))
+			Expect(machineSet.Spec.Template.Spec.AuthoritativeAPI).To(Equal(mapiv1beta1.MachineAuthorityClusterAPI))
+		})
+
+		It("should default the authoritative API of new standalone Machines", func() {
+			machine := newTestMAPIMachine()
+
+			Expect(wh.Default(createCtx, machine)).To(Succeed())
+			Expect(machine.Spec.AuthoritativeAPI).To(Equal(mapiv1beta1.MachineAuthorityClusterAPI))
+		})
+
+		It("should leave Machines controlled by a MachineSet to its template", func() {
+			machine := newTestMAPIMachine()
+			machine.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: mapiv1beta1.GroupVersion.String(),
+				Kind:       "MachineSet",
+				Name:       "test-machineset",
+				Controller: ptr.To(true),
+			}}
+
+			Expect(wh.Default(createCtx, machine)).To(Succeed())
+			Expect(machine.Spec.AuthoritativeAPI).To(Equal(mapiv1beta1.MachineAuthorityMachineAPI))
+		})
+
+		It("should not change the authoritative API on update", func() {
+			machine := newTestMAPIMachine()
+
+			Expect(wh.Default(newAdmissionContext(admissionv1.Update), machine)).To(Succeed())
+			Expect(machine.Spec.AuthoritativeAPI).To(Equal(mapiv1beta1.MachineAuthorityMachineAPI))
+		})
+
+		It("should ignore Machines outside of the openshift-machine-api namespace", func() {
+			machine := newTestMAPIMachine()
+			machine.Namespace = "default"
+
+			Expect(wh.Default(createCtx, machine)).To(Succeed())
+			Expect(machine.Spec.AuthoritativeAPI).To(Equal(mapiv1beta1.MachineAuthorityMachineAPI))
+		})
+
+		It("should follow the configured Machine API namespace", func() {
+			wh.MAPINamespace = "custom-machine-api"
+
+			machine := newTestMAPIMachine()
+			Expect(wh.Default(createCtx, machine)).To(Succeed())
+			Expect(machine.Spec.AuthoritativeAPI).To(Equal(mapiv1beta1.MachineAuthorityMachineAPI))
+
+			machine.Namespace = "custom-machine-api"
+			Expect(wh.Default(createCtx, machine)).To(Succeed())
+			Expect(machine.Spec.AuthoritativeAPI).To(Equal(mapiv1beta1.MachineAuthorityClusterAPI))
+		})
+	})
+
+	DescribeTable("should keep the authoritative API",
+		func(policy mapiv1beta1.MachineAuthority, featureGate *configv1.FeatureGate) {
+			wh = newWebhookWithFeatureGate(policy, featureGate)
+			machineSet := newTestMAPIMachineSet()
+
+			Expect(wh.Default(createCtx, machineSet)).To(Succeed())
+			Expect(machineSet.Spec.AuthoritativeAPI).To(Equal(mapiv1beta1.MachineAuthorityMachineAPI))
+			Expect(machineSet.Spec.Template.Spec.AuthoritativeAPI).To(Equal(mapiv1beta1.MachineAuthorityMachineAPI))
+		},
+		Entry("without a policy", mapiv1beta1.MachineAuthority(""),
+			newTestFeatureGate(testReleaseVersion, features.FeatureGateMachineAPIMigration)),
+		Entry("with a MachineAPI policy", mapiv1beta1.MachineAuthorityMachineAPI,
+			newTestFeatureGate(testReleaseVersion, features.FeatureGateMachineAPIMigration)),
+		Entry("with a ClusterAPI policy and no FeatureGate", mapiv1beta1.MachineAuthorityClusterAPI, nil),
+		Entry("with a ClusterAPI policy and the MachineAPIMigration feature gate disabled", mapiv1beta1.MachineAuthorityClusterAPI,
+			newTestFeatureGate(testReleaseVersion)),
+		Entry("with a ClusterAPI policy and the MachineAPIMigration feature gate enabled for another version", mapiv1beta1.MachineAuthorityClusterAPI,
+			newTestFeatureGate("4.0.0", features.FeatureGateMachineAPIMigration)),
+	)
+
+	It("should not fail when the ClusterAPIOperatorConfig does not exist", func() {
+		wh = &MAPIAuthorityWebhook{
+			OperatorNamespace: controllers.DefaultManagedNamespace,
+			MAPINamespace:     openshiftMAPINamespace,
+			ReleaseVersion:    testReleaseVersion,
+			client:            fake.NewClientBuilder().WithScheme(newScheme()).Build(),
+		}
+		machine := newTestMAPIMachine()
+
+		Expect(wh.Default(createCtx, machine)).To(Succeed())
+		Expect(machine.Spec.AuthoritativeAPI).To(Equal(mapiv1beta1.MachineAuthorityMachineAPI))
+	})
+})